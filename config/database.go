@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"gorm.io/driver/postgres"
@@ -87,8 +88,9 @@ func ConnectDatabase() (*Database, error) {
 	sqlDB.SetConnMaxLifetime(config.ConnMaxLifetime)
 	sqlDB.SetConnMaxIdleTime(config.ConnMaxIdleTime)
 
-	// Auto migrate the schema
-	err = db.AutoMigrate(&models.Specialty{}, &models.Doctor{}, &models.Appointment{})
+	// Auto migrate the schema. This creates doctor_schedules (weekday hours as jsonb columns)
+	// and doctor_breaks on databases that predate them; see models.DoctorSchedule
+	err = db.AutoMigrate(&models.Location{}, &models.Specialty{}, &models.Doctor{}, &models.Appointment{}, &models.TimeSlot{}, &models.DoctorSchedule{}, &models.DoctorBreak{}, &models.AppointmentAudit{}, &models.NotificationLog{}, &models.User{}, &models.AdminAudit{}, &models.WaitlistEntry{}, &models.WaitlistOffer{}, &models.Holiday{}, &models.Review{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
@@ -99,6 +101,12 @@ func ConnectDatabase() (*Database, error) {
 		return nil, fmt.Errorf("failed to create database indexes: %w", err)
 	}
 
+	// Enforce appointment invariants in the database as well as in the application
+	err = createCheckConstraints(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create check constraints: %w", err)
+	}
+
 	log.Println("Database connected, migrated, and optimized successfully")
 
 	return &Database{DB: db}, nil
@@ -160,6 +168,20 @@ func createDatabaseIndexes(db *gorm.DB) error {
 		"CREATE INDEX IF NOT EXISTS idx_specialties_name ON specialties(name);",
 	}
 
+	// Names that differ only by case would make the unique index fail, so report them and fall
+	// back to a plain index until they are merged by hand
+	duplicates, err := duplicateSpecialtyNames(db)
+	if err != nil {
+		return err
+	}
+	if len(duplicates) == 0 {
+		indexes = append(indexes, "CREATE UNIQUE INDEX IF NOT EXISTS idx_specialties_name_lower ON specialties(LOWER(name));")
+	} else {
+		log.Printf("Warning: specialty names differ only by case, skipping unique index idx_specialties_name_lower: %s",
+			strings.Join(duplicates, ", "))
+		indexes = append(indexes, "CREATE INDEX IF NOT EXISTS idx_specialties_name_lower_lookup ON specialties(LOWER(name));")
+	}
+
 	for _, indexSQL := range indexes {
 		if err := db.Exec(indexSQL).Error; err != nil {
 			log.Printf("Warning: Failed to create index: %s - %v", indexSQL, err)
@@ -170,3 +192,60 @@ func createDatabaseIndexes(db *gorm.DB) error {
 	log.Println("Database indexes created successfully")
 	return nil
 }
+
+// duplicateSpecialtyNames lists the lowercased specialty names held by more than one row,
+// soft-deleted rows included since the unique index covers them too
+func duplicateSpecialtyNames(db *gorm.DB) ([]string, error) {
+	var names []string
+	err := db.Raw("SELECT LOWER(name) FROM specialties GROUP BY LOWER(name) HAVING COUNT(*) > 1 ORDER BY LOWER(name)").
+		Scan(&names).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for duplicate specialty names: %w", err)
+	}
+	return names, nil
+}
+
+// createCheckConstraints (re)creates the CHECK constraints on appointments. Each is dropped and
+// added again so a changed definition, such as a new status, replaces the old one. They are added
+// NOT VALID so existing rows are left alone while every new write is checked.
+func createCheckConstraints(db *gorm.DB) error {
+	statuses := quoteList([]string{
+		string(models.StatusScheduled), string(models.StatusCompleted), string(models.StatusCancelled),
+		string(models.StatusNoShow), string(models.StatusRescheduled), string(models.StatusConfirmed),
+		string(models.StatusPendingPayment),
+	})
+	types := quoteList([]string{
+		string(models.TypeConsultation), string(models.TypeFollowUp), string(models.TypeCheckup), string(models.TypeEmergency),
+	})
+
+	constraints := []struct {
+		name  string
+		check string
+	}{
+		{"chk_appointments_duration", "duration BETWEEN 15 AND 180"},
+		{"chk_appointments_end_after_start", "end_time > appointment_time"},
+		{"chk_appointments_reminder_time", "reminder_time >= 0"},
+		{"chk_appointments_status", "status IN (" + statuses + ")"},
+		{"chk_appointments_type", "type IN (" + types + ")"},
+	}
+
+	for _, constraint := range constraints {
+		sql := fmt.Sprintf("ALTER TABLE appointments DROP CONSTRAINT IF EXISTS %s, ADD CONSTRAINT %s CHECK (%s) NOT VALID;",
+			constraint.name, constraint.name, constraint.check)
+		if err := db.Exec(sql).Error; err != nil {
+			return fmt.Errorf("failed to add %s: %w", constraint.name, err)
+		}
+	}
+
+	log.Println("Database check constraints created successfully")
+	return nil
+}
+
+// quoteList renders values as a comma-separated list of SQL string literals
+func quoteList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"testing"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository/repotest"
+)
+
+func TestCreateDatabaseIndexesToleratesCaseDuplicateSpecialties(t *testing.T) {
+	db := repotest.Open(t)
+	repotest.MustCreate(t, db,
+		&models.Specialty{Name: "Cardiology"},
+		&models.Specialty{Name: "cardiology"},
+		&models.Specialty{Name: "Dermatology"},
+	)
+
+	duplicates, err := duplicateSpecialtyNames(db)
+	if err != nil {
+		t.Fatalf("duplicateSpecialtyNames returned error: %v", err)
+	}
+	if len(duplicates) != 1 || duplicates[0] != "cardiology" {
+		t.Errorf("expected cardiology to be reported as a duplicate, got %v", duplicates)
+	}
+
+	if err := createDatabaseIndexes(db); err != nil {
+		t.Fatalf("createDatabaseIndexes returned error: %v", err)
+	}
+	// Without the unique index another casing of an existing name is still accepted
+	if err := db.Create(&models.Specialty{Name: "DERMATOLOGY"}).Error; err != nil {
+		t.Errorf("expected the duplicate-tolerant index to accept DERMATOLOGY, got %v", err)
+	}
+}
+
+func TestCreateDatabaseIndexesEnforcesCaseInsensitiveSpecialtyNames(t *testing.T) {
+	db := repotest.Open(t)
+	repotest.MustCreate(t, db, &models.Specialty{Name: "Cardiology"})
+
+	if err := createDatabaseIndexes(db); err != nil {
+		t.Fatalf("createDatabaseIndexes returned error: %v", err)
+	}
+	if err := db.Create(&models.Specialty{Name: "CARDIOLOGY"}).Error; err == nil {
+		t.Error("expected the unique index to reject CARDIOLOGY")
+	}
+}
@@ -0,0 +1,236 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/utils"
+)
+
+// CheckSeverity represents the outcome of a single startup check
+type CheckSeverity string
+
+const (
+	CheckPassed CheckSeverity = "ok"
+	CheckWarn   CheckSeverity = "warn"
+	CheckFatal  CheckSeverity = "fatal"
+)
+
+// minJWTSecretLength is the shortest JWT secret accepted without a warning
+const minJWTSecretLength = 32
+
+// CheckResult is the outcome of one startup check
+type CheckResult struct {
+	Name     string
+	Severity CheckSeverity
+	Message  string
+}
+
+// SelfCheckReport collects the outcome of every startup check
+type SelfCheckReport struct {
+	Results []CheckResult
+}
+
+// Fatal reports whether any check failed in a way the server cannot run with
+func (r *SelfCheckReport) Fatal() bool {
+	for _, result := range r.Results {
+		if result.Severity == CheckFatal {
+			return true
+		}
+	}
+	return false
+}
+
+// Log writes a consolidated readiness summary, one line per check
+func (r *SelfCheckReport) Log() {
+	for _, result := range r.Results {
+		fields := map[string]interface{}{
+			"component": "selfcheck",
+			"check":     result.Name,
+		}
+		switch result.Severity {
+		case CheckFatal:
+			utils.LogError(fmt.Errorf("%s", result.Message), "Startup check failed", fields)
+		case CheckWarn:
+			fields["detail"] = result.Message
+			utils.LogWarn("Startup check passed with warnings", fields)
+		default:
+			fields["detail"] = result.Message
+			utils.LogInfo("Startup check passed", fields)
+		}
+	}
+
+	summary := "ready"
+	if r.Fatal() {
+		summary = "not ready"
+	}
+	utils.LogInfo("Startup self-check complete", map[string]interface{}{
+		"component": "selfcheck",
+		"status":    summary,
+		"checks":    len(r.Results),
+	})
+}
+
+func (r *SelfCheckReport) add(name string, severity CheckSeverity, format string, args ...interface{}) {
+	r.Results = append(r.Results, CheckResult{Name: name, Severity: severity, Message: fmt.Sprintf(format, args...)})
+}
+
+// SelfCheckOptions configures the startup self-check
+type SelfCheckOptions struct {
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+	// CacheRequired makes an unreachable Redis fatal instead of a warning
+	CacheRequired bool
+	JWTSecret     string
+	// RequiredSpecialties lists specialty names that must exist before the server accepts bookings
+	RequiredSpecialties []string
+	Timeout             time.Duration
+}
+
+// SelfCheckOptionsFromEnv reads the self-check options from environment variables
+func SelfCheckOptionsFromEnv() SelfCheckOptions {
+	var required []string
+	for _, name := range strings.Split(getEnv("REQUIRED_SPECIALTIES", ""), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			required = append(required, name)
+		}
+	}
+
+	// An explicitly empty REDIS_ADDR selects the in-memory cache
+	redisAddr, ok := os.LookupEnv("REDIS_ADDR")
+	if !ok {
+		redisAddr = "localhost:6379"
+	}
+
+	return SelfCheckOptions{
+		RedisAddr:           redisAddr,
+		RedisPassword:       getEnv("REDIS_PASSWORD", ""),
+		RedisDB:             getEnvInt("REDIS_DB", 0),
+		CacheRequired:       getEnv("CACHE_REQUIRED", "false") == "true",
+		JWTSecret:           getEnv("JWT_SECRET", ""),
+		RequiredSpecialties: required,
+		Timeout:             getEnvDuration("SELF_CHECK_TIMEOUT", "5s"),
+	}
+}
+
+// SelfCheck verifies the database, Redis, the JWT secret and the required specialties before the
+// server starts. Callers should log the report and abort when it is fatal.
+func SelfCheck(db *gorm.DB, opts SelfCheckOptions) *SelfCheckReport {
+	report := &SelfCheckReport{}
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+
+	databaseReachable := checkDatabase(ctx, db, report)
+	checkRedis(ctx, opts, report)
+	checkJWTSecret(opts.JWTSecret, report)
+	if databaseReachable {
+		checkRequiredSpecialties(ctx, db, opts.RequiredSpecialties, report)
+	}
+
+	return report
+}
+
+// checkDatabase pings the database, returning whether it is reachable
+func checkDatabase(ctx context.Context, db *gorm.DB, report *SelfCheckReport) bool {
+	if db == nil {
+		report.add("database", CheckFatal, "no database connection configured")
+		return false
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		report.add("database", CheckFatal, "failed to get database handle: %v", err)
+		return false
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		report.add("database", CheckFatal, "database unreachable: %v", err)
+		return false
+	}
+
+	report.add("database", CheckPassed, "database reachable")
+	return true
+}
+
+// checkRedis pings Redis; failure is fatal only when the cache is required
+func checkRedis(ctx context.Context, opts SelfCheckOptions, report *SelfCheckReport) {
+	if opts.RedisAddr == "" {
+		if opts.CacheRequired {
+			report.add("redis", CheckFatal, "REDIS_ADDR is empty but CACHE_REQUIRED is set")
+			return
+		}
+		report.add("redis", CheckPassed, "redis not configured, using the in-memory cache")
+		return
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     opts.RedisAddr,
+		Password: opts.RedisPassword,
+		DB:       opts.RedisDB,
+	})
+	defer client.Close()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		severity := CheckWarn
+		if opts.CacheRequired {
+			severity = CheckFatal
+		}
+		report.add("redis", severity, "redis unreachable at %s: %v", opts.RedisAddr, err)
+		return
+	}
+
+	report.add("redis", CheckPassed, "redis reachable at %s", opts.RedisAddr)
+}
+
+// checkJWTSecret requires a JWT secret and warns when it is shorter than recommended
+func checkJWTSecret(secret string, report *SelfCheckReport) {
+	switch {
+	case secret == "":
+		report.add("jwt_secret", CheckFatal, "JWT_SECRET is not set")
+	case len(secret) < minJWTSecretLength:
+		report.add("jwt_secret", CheckWarn, "JWT_SECRET is shorter than %d characters", minJWTSecretLength)
+	default:
+		report.add("jwt_secret", CheckPassed, "JWT secret configured")
+	}
+}
+
+// checkRequiredSpecialties verifies every required specialty exists and is active
+func checkRequiredSpecialties(ctx context.Context, db *gorm.DB, required []string, report *SelfCheckReport) {
+	if len(required) == 0 {
+		report.add("specialties", CheckPassed, "no required specialties configured")
+		return
+	}
+
+	var found []string
+	err := db.WithContext(ctx).Model(&models.Specialty{}).
+		Where("name IN ? AND is_active = ?", required, true).
+		Pluck("name", &found).Error
+	if err != nil {
+		report.add("specialties", CheckFatal, "failed to load specialties: %v", err)
+		return
+	}
+
+	present := make(map[string]bool, len(found))
+	for _, name := range found {
+		present[name] = true
+	}
+	var missing []string
+	for _, name := range required {
+		if !present[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		report.add("specialties", CheckFatal, "required specialties missing: %s", strings.Join(missing, ", "))
+		return
+	}
+
+	report.add("specialties", CheckPassed, "all %d required specialties present", len(required))
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository/repotest"
+)
+
+// validSelfCheckOptions passes every check against a database holding the Cardiology specialty
+func validSelfCheckOptions() SelfCheckOptions {
+	return SelfCheckOptions{
+		JWTSecret:           strings.Repeat("s", minJWTSecretLength),
+		RequiredSpecialties: []string{"Cardiology"},
+		Timeout:             2 * time.Second,
+	}
+}
+
+// checkResult returns the named check's result from report
+func checkResult(t *testing.T, report *SelfCheckReport, name string) CheckResult {
+	t.Helper()
+	for _, result := range report.Results {
+		if result.Name == name {
+			return result
+		}
+	}
+	t.Fatalf("expected a %s check in the report, got %+v", name, report.Results)
+	return CheckResult{}
+}
+
+func TestSelfCheckPassesWhenConfigured(t *testing.T) {
+	db := repotest.Open(t)
+	repotest.MustCreate(t, db, &models.Specialty{Name: "Cardiology"})
+
+	report := SelfCheck(db, validSelfCheckOptions())
+	if report.Fatal() {
+		t.Fatalf("expected a ready report, got %+v", report.Results)
+	}
+	for _, result := range report.Results {
+		if result.Severity != CheckPassed {
+			t.Errorf("expected the %s check to pass, got %s: %s", result.Name, result.Severity, result.Message)
+		}
+	}
+}
+
+func TestSelfCheckReportsFailedPreconditions(t *testing.T) {
+	// Port 1 refuses connections, standing in for a Redis server that is down
+	const unreachableRedis = "127.0.0.1:1"
+
+	tests := []struct {
+		name     string
+		closeDB  bool
+		nilDB    bool
+		modify   func(*SelfCheckOptions)
+		check    string
+		severity CheckSeverity
+	}{
+		{name: "no database", nilDB: true, check: "database", severity: CheckFatal},
+		{name: "database unreachable", closeDB: true, check: "database", severity: CheckFatal},
+		{name: "optional redis unreachable", modify: func(o *SelfCheckOptions) { o.RedisAddr = unreachableRedis },
+			check: "redis", severity: CheckWarn},
+		{name: "required redis unreachable", modify: func(o *SelfCheckOptions) { o.RedisAddr = unreachableRedis; o.CacheRequired = true },
+			check: "redis", severity: CheckFatal},
+		{name: "required redis not configured", modify: func(o *SelfCheckOptions) { o.CacheRequired = true },
+			check: "redis", severity: CheckFatal},
+		{name: "missing JWT secret", modify: func(o *SelfCheckOptions) { o.JWTSecret = "" },
+			check: "jwt_secret", severity: CheckFatal},
+		{name: "short JWT secret", modify: func(o *SelfCheckOptions) { o.JWTSecret = "short" },
+			check: "jwt_secret", severity: CheckWarn},
+		{name: "missing specialty", modify: func(o *SelfCheckOptions) { o.RequiredSpecialties = append(o.RequiredSpecialties, "Neurology") },
+			check: "specialties", severity: CheckFatal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var db *gorm.DB
+			if !tt.nilDB {
+				db = repotest.Open(t)
+				repotest.MustCreate(t, db, &models.Specialty{Name: "Cardiology"})
+			}
+			if tt.closeDB {
+				sqlDB, err := db.DB()
+				if err != nil {
+					t.Fatalf("failed to get database handle: %v", err)
+				}
+				sqlDB.Close()
+			}
+			opts := validSelfCheckOptions()
+			if tt.modify != nil {
+				tt.modify(&opts)
+			}
+
+			report := SelfCheck(db, opts)
+			result := checkResult(t, report, tt.check)
+			if result.Severity != tt.severity {
+				t.Errorf("expected the %s check to be %s, got %s: %s", tt.check, tt.severity, result.Severity, result.Message)
+			}
+			if report.Fatal() != (tt.severity == CheckFatal) {
+				t.Errorf("expected Fatal() to be %v, got %+v", tt.severity == CheckFatal, report.Results)
+			}
+		})
+	}
+}
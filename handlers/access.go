@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/services"
+	"smart-doctor-booking-app/utils"
+)
+
+// isAssignedDoctor reports whether the caller is a doctor account acting for doctorID
+func isAssignedDoctor(c *gin.Context, doctorID uint) bool {
+	return c.GetString("role") == "doctor" && doctorID != 0 && c.GetUint("doctor_id") == doctorID
+}
+
+// authorizeAppointment loads the appointment named by the :id path parameter and checks that the
+// caller may access it. Every per-appointment endpoint, cancelling and rescheduling included, uses
+// the same rule: the patient who owns the appointment, an admin, or the doctor it is assigned to;
+// other doctors are refused. Some routes narrow it further by role. On failure it writes the error
+// response and returns false.
+func authorizeAppointment(c *gin.Context, schedulingService services.SchedulingService) (*models.Appointment, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User authentication required",
+		})
+		return nil, false
+	}
+
+	appointmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid appointment ID",
+			Message: "Appointment ID must be a valid number",
+		})
+		return nil, false
+	}
+
+	appointment, err := schedulingService.GetAppointment(uint(appointmentID))
+	if err != nil {
+		if respondIfUnavailable(c, err) {
+			return nil, false
+		}
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Appointment not found",
+				Message: "The requested appointment does not exist",
+			})
+			return nil, false
+		}
+		utils.LogErrorContext(c.Request.Context(), err, "Failed to get appointment", map[string]interface{}{
+			"appointment_id": appointmentID,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get appointment",
+			Message: "Unable to retrieve appointment. Please try again.",
+		})
+		return nil, false
+	}
+
+	if appointment.UserID != userID.(uint) && c.GetString("role") != "admin" && !isAssignedDoctor(c, appointment.DoctorID) {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "Forbidden",
+			Message: "You do not have access to this appointment",
+		})
+		return nil, false
+	}
+
+	return appointment, true
+}
+
+// authorizeDoctor parses the doctor named by the :id path parameter and checks that the caller may
+// manage that doctor's schedule: an admin or the doctor themselves; other doctors are refused. On
+// failure it writes the error response and returns false.
+func authorizeDoctor(c *gin.Context) (uint, bool) {
+	doctorID, ok := parseDoctorID(c)
+	if !ok {
+		return 0, false
+	}
+
+	if c.GetString("role") != "admin" && !isAssignedDoctor(c, doctorID) {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "Forbidden",
+			Message: "You do not have access to this doctor's schedule",
+		})
+		return 0, false
+	}
+
+	return doctorID, true
+}
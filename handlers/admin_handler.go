@@ -0,0 +1,418 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/services"
+	"smart-doctor-booking-app/utils"
+)
+
+// AdminHandler handles administrative HTTP requests
+type AdminHandler struct {
+	featureFlags    services.FeatureFlags
+	auditRepo       repository.AdminAuditRepository
+	doctorRepo      repository.DoctorRepository
+	appointmentRepo repository.AppointmentRepository
+	notificationLog repository.NotificationLogRepository
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(
+	featureFlags services.FeatureFlags,
+	auditRepo repository.AdminAuditRepository,
+	doctorRepo repository.DoctorRepository,
+	appointmentRepo repository.AppointmentRepository,
+	notificationLog repository.NotificationLogRepository,
+) *AdminHandler {
+	return &AdminHandler{
+		featureFlags:    featureFlags,
+		auditRepo:       auditRepo,
+		doctorRepo:      doctorRepo,
+		appointmentRepo: appointmentRepo,
+		notificationLog: notificationLog,
+	}
+}
+
+// FeatureFlagsResponse represents the current state of every feature flag
+type FeatureFlagsResponse struct {
+	Success bool                          `json:"success"`
+	Flags   map[services.FeatureFlag]bool `json:"flags"`
+}
+
+// UpdateFeatureFlagRequest represents the request body for toggling a feature flag
+type UpdateFeatureFlagRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// AdminAuditResponse represents a page of admin audit entries
+type AdminAuditResponse struct {
+	Success bool                `json:"success"`
+	Audits  []models.AdminAudit `json:"audits"`
+	Total   int64               `json:"total"`
+	Limit   int                 `json:"limit"`
+	Offset  int                 `json:"offset"`
+}
+
+// DueRemindersResponse lists the reminders that will fire within a window
+type DueRemindersResponse struct {
+	Success   bool                 `json:"success"`
+	Message   string               `json:"message"`
+	Until     time.Time            `json:"until"`
+	Reminders []models.DueReminder `json:"reminders"`
+}
+
+// PurgeResponse reports how many soft-deleted records were permanently removed
+type PurgeResponse struct {
+	Success bool      `json:"success"`
+	Message string    `json:"message"`
+	Entity  string    `json:"entity"`
+	Cutoff  time.Time `json:"cutoff"`
+	Purged  int64     `json:"purged"`
+}
+
+// GetFeatureFlags handles GET /api/v1/admin/flags
+// @Summary List feature flags
+// @Description Get the current state of every feature flag
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} FeatureFlagsResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/admin/flags [get]
+func (h *AdminHandler) GetFeatureFlags(c *gin.Context) {
+	c.JSON(http.StatusOK, FeatureFlagsResponse{
+		Success: true,
+		Flags:   h.featureFlags.All(c.Request.Context()),
+	})
+}
+
+// UpdateFeatureFlag handles PUT /api/v1/admin/flags/:name
+// @Summary Toggle a feature flag
+// @Description Enable or disable a feature at runtime
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param name path string true "Flag name"
+// @Param flag body UpdateFeatureFlagRequest true "Flag state"
+// @Success 200 {object} FeatureFlagsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/admin/flags/{name} [put]
+func (h *AdminHandler) UpdateFeatureFlag(c *gin.Context) {
+	flag := services.FeatureFlag(c.Param("name"))
+	if !flag.IsKnown() {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Unknown feature flag",
+			Message: "No feature flag named " + string(flag),
+		})
+		return
+	}
+
+	var request UpdateFeatureFlagRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.featureFlags.SetEnabled(c.Request.Context(), flag, *request.Enabled); err != nil {
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to update feature flag",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, FeatureFlagsResponse{
+		Success: true,
+		Flags:   h.featureFlags.All(c.Request.Context()),
+	})
+}
+
+// GetAuditLog handles GET /api/v1/admin/audit
+// @Summary List admin audit entries
+// @Description Get the write operations performed by administrators, newest first
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param limit query int false "Maximum entries to return (default 50, max 200)"
+// @Param offset query int false "Number of entries to skip"
+// @Success 200 {object} AdminAuditResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/admin/audit [get]
+func (h *AdminHandler) GetAuditLog(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	audits, total, err := h.auditRepo.GetAudits(limit, offset)
+	if err != nil {
+		utils.LogError(err, "Failed to get admin audit log", nil)
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get audit log",
+			Message: "Unable to retrieve admin audit entries. Please try again.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AdminAuditResponse{
+		Success: true,
+		Audits:  audits,
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+	})
+}
+
+// notificationExportHeader is the header row of the notification log CSV export
+var notificationExportHeader = []string{
+	"id", "created_at", "appointment_id", "user_id", "kind", "channel", "status", "escalated", "error", "request_id",
+}
+
+// ExportNotificationLogs handles GET /api/v1/admin/notifications/export
+// @Summary Export notification delivery records as CSV
+// @Description Stream every notification delivery attempt created between from and to (inclusive), oldest first, optionally only those with the given status
+// @Tags admin
+// @Produce text/csv
+// @Param Authorization header string true "Bearer token"
+// @Param from query string false "First day (YYYY-MM-DD, default 30 days ago)"
+// @Param to query string false "Last day (YYYY-MM-DD, default today)"
+// @Param status query string false "Delivery status (SENT or FAILED)"
+// @Success 200 {file} file
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/admin/notifications/export [get]
+func (h *AdminHandler) ExportNotificationLogs(c *gin.Context) {
+	from, to, ok := parseDateRange(c, 30)
+	if !ok {
+		return
+	}
+
+	status := models.NotificationStatus(strings.ToUpper(c.Query("status")))
+	if status != "" && status != models.NotificationSent && status != models.NotificationFailed {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid status",
+			Message: "status must be SENT or FAILED",
+		})
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"notifications-%s-%s.csv\"",
+		from.Format("2006-01-02"), to.Format("2006-01-02")))
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	if err := writer.Write(notificationExportHeader); err != nil {
+		return
+	}
+
+	err := h.notificationLog.ExportLogs(from, to.AddDate(0, 0, 1), status, func(logs []models.NotificationLog) error {
+		for _, entry := range logs {
+			if err := writer.Write([]string{
+				strconv.FormatUint(uint64(entry.ID), 10),
+				entry.CreatedAt.UTC().Format(time.RFC3339),
+				strconv.FormatUint(uint64(entry.AppointmentID), 10),
+				strconv.FormatUint(uint64(entry.UserID), 10),
+				string(entry.Kind),
+				string(entry.Channel),
+				string(entry.Status),
+				strconv.FormatBool(entry.Escalated),
+				entry.Error,
+				entry.RequestID,
+			}); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	})
+	writer.Flush()
+
+	// The response is already under way, so a failure can only cut the file short
+	if err != nil {
+		utils.LogError(err, "Failed to export notification logs", map[string]interface{}{
+			"from":   from,
+			"to":     to,
+			"status": status,
+		})
+	}
+}
+
+// GetDueReminders handles GET /api/v1/admin/reminders/due
+// @Summary List reminders about to fire
+// @Description Get the unsent appointment reminders that fall due within the window, soonest first, including overdue ones the dispatcher has not delivered yet
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param within query string false "Window from now, e.g. 2h or 1d (default 1h, max 7d)"
+// @Success 200 {object} DueRemindersResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/admin/reminders/due [get]
+func (h *AdminHandler) GetDueReminders(c *gin.Context) {
+	within, err := parseAge(c.DefaultQuery("within", "1h"))
+	if err != nil || within <= 0 || within > 7*24*time.Hour {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid within",
+			Message: "within must be a positive window of at most 7d, such as 2h or 1d",
+		})
+		return
+	}
+
+	now := time.Now()
+	until := now.Add(within)
+	appointments, err := h.appointmentRepo.GetRemindersDueBy(now, until)
+	if err != nil {
+		utils.LogError(err, "Failed to get due reminders", map[string]interface{}{
+			"within": within.String(),
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get due reminders",
+			Message: "Unable to retrieve upcoming reminders. Please try again.",
+		})
+		return
+	}
+
+	reminders := make([]models.DueReminder, 0, len(appointments))
+	for _, appointment := range appointments {
+		remindAt := appointment.AppointmentTime.Add(-time.Duration(appointment.ReminderTime) * time.Minute)
+		reminders = append(reminders, models.DueReminder{
+			AppointmentID:   appointment.ID,
+			UserID:          appointment.UserID,
+			DoctorID:        appointment.DoctorID,
+			AppointmentTime: appointment.AppointmentTime,
+			ReminderType:    appointment.ReminderType,
+			RemindAt:        remindAt,
+			Overdue:         remindAt.Before(now),
+		})
+	}
+	sort.SliceStable(reminders, func(i, j int) bool {
+		return reminders[i].RemindAt.Before(reminders[j].RemindAt)
+	})
+
+	c.JSON(http.StatusOK, DueRemindersResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("%d reminders due within %s", len(reminders), within),
+		Until:     until,
+		Reminders: reminders,
+	})
+}
+
+// PurgeDeleted handles POST /api/v1/admin/purge
+// @Summary Permanently delete soft-deleted records
+// @Description Hard-delete doctors or appointments that were soft-deleted longer ago than older_than. This cannot be undone, so confirm=true is required.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param entity query string true "Records to purge (doctors or appointments)"
+// @Param older_than query string false "Minimum age since deletion, e.g. 90d or 720h (default 90d)"
+// @Param confirm query bool true "Must be true to perform the purge"
+// @Success 200 {object} PurgeResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/admin/purge [post]
+func (h *AdminHandler) PurgeDeleted(c *gin.Context) {
+	entity := c.Query("entity")
+	if entity != "doctors" && entity != "appointments" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid entity",
+			Message: "entity must be doctors or appointments",
+		})
+		return
+	}
+
+	olderThan, err := parseAge(c.DefaultQuery("older_than", "90d"))
+	if err != nil || olderThan <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid older_than",
+			Message: "older_than must be a positive age such as 90d or 720h",
+		})
+		return
+	}
+
+	if confirm, _ := strconv.ParseBool(c.Query("confirm")); !confirm {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Confirmation required",
+			Message: "Purging permanently deletes records; repeat the request with confirm=true",
+		})
+		return
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	var purged int64
+	if entity == "doctors" {
+		purged, err = h.doctorRepo.PurgeDeletedDoctors(cutoff)
+	} else {
+		purged, err = h.appointmentRepo.PurgeDeletedAppointments(cutoff)
+	}
+	if err != nil {
+		utils.LogError(err, "Failed to purge soft-deleted records", map[string]interface{}{
+			"entity": entity,
+			"cutoff": cutoff,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Purge failed",
+			Message: "Unable to purge deleted records. Please try again.",
+		})
+		return
+	}
+
+	utils.LogInfo("Purged soft-deleted records", map[string]interface{}{
+		"entity": entity,
+		"cutoff": cutoff,
+		"purged": purged,
+	})
+
+	c.JSON(http.StatusOK, PurgeResponse{
+		Success: true,
+		Message: fmt.Sprintf("Purged %d deleted %s", purged, entity),
+		Entity:  entity,
+		Cutoff:  cutoff,
+		Purged:  purged,
+	})
+}
+
+// parseAge parses a duration that may also be given in whole days, e.g. "90d"
+func parseAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number of days %q", days)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/repository/repotest"
+)
+
+func TestPurgeDeletedRemovesOnlyOldSoftDeletedRows(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := repotest.Open(t)
+	repotest.MustCreate(t, db, &models.Specialty{ID: 1, Name: "General Practice"})
+	longAgo := gorm.DeletedAt{Time: time.Now().AddDate(0, 0, -100), Valid: true}
+	recently := gorm.DeletedAt{Time: time.Now().AddDate(0, 0, -10), Valid: true}
+	for i, deletedAt := range []gorm.DeletedAt{longAgo, recently, {}} {
+		id := uint(i + 1)
+		doctor := &models.Doctor{ID: id, Name: "Doctor", SpecialtyID: 1, IsActive: true, DeletedAt: deletedAt}
+		appointment := repotest.Appointment(1, id, repotest.Day(i).Add(9*time.Hour), 30, models.StatusCancelled)
+		appointment.DeletedAt = deletedAt
+		repotest.MustCreate(t, db, doctor, appointment)
+	}
+	handler := NewAdminHandler(nil, nil, repository.NewDoctorRepository(db), repository.NewAppointmentRepository(db), nil)
+
+	router := gin.New()
+	router.POST("/admin/purge", handler.PurgeDeleted)
+	purge := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/purge?"+query, nil))
+		return w
+	}
+
+	if w := purge("entity=doctors&older_than=90d"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected an unconfirmed purge to be rejected with 400, got %d", w.Code)
+	}
+	if w := purge("entity=users&older_than=90d&confirm=true"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected an unknown entity to be rejected with 400, got %d", w.Code)
+	}
+
+	for _, entity := range []string{"doctors", "appointments"} {
+		w := purge("entity=" + entity + "&older_than=90d&confirm=true")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 purging %s, got %d: %s", entity, w.Code, w.Body.String())
+		}
+		var response PurgeResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if response.Purged != 1 {
+			t.Errorf("expected 1 %s purged, got %d", entity, response.Purged)
+		}
+	}
+
+	for _, table := range []string{"doctors", "appointments"} {
+		var remaining []uint
+		if err := db.Table(table).Order("id").Pluck("id", &remaining).Error; err != nil {
+			t.Fatalf("failed to list %s: %v", table, err)
+		}
+		if len(remaining) != 2 || remaining[0] != 2 || remaining[1] != 3 {
+			t.Errorf("expected the recently deleted and live %s to remain, got IDs %v", table, remaining)
+		}
+	}
+}
+
+func TestExportNotificationLogsWritesFilteredCSV(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := repotest.Open(t)
+	at := func(offset, hour int) time.Time { return repotest.Day(offset).Add(time.Duration(hour) * time.Hour) }
+	repotest.MustCreate(t, db,
+		&models.NotificationLog{AppointmentID: 1, UserID: 1, Kind: models.NotificationReminder, Channel: models.ReminderSMS,
+			Status: models.NotificationSent, CreatedAt: at(0, 9)},
+		&models.NotificationLog{AppointmentID: 2, UserID: 2, Kind: models.NotificationReminder, Channel: models.ReminderEmail,
+			Status: models.NotificationFailed, Error: "mailbox full, try later", RequestID: "req-7", CreatedAt: at(1, 23)},
+		&models.NotificationLog{AppointmentID: 3, UserID: 3, Kind: models.NotificationConfirmation, Channel: models.ReminderSMS,
+			Status: models.NotificationFailed, Escalated: true, CreatedAt: at(0, 10)},
+		&models.NotificationLog{AppointmentID: 4, UserID: 4, Kind: models.NotificationReminder, Channel: models.ReminderSMS,
+			Status: models.NotificationFailed, CreatedAt: at(2, 0)}, // the day after the range
+	)
+	handler := NewAdminHandler(nil, nil, nil, nil, repository.NewNotificationLogRepository(db))
+
+	router := gin.New()
+	router.GET("/admin/notifications/export", handler.ExportNotificationLogs)
+	export := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/notifications/export?"+query, nil))
+		return w
+	}
+
+	if w := export("from=2031-03-03&to=2031-03-04&status=pending"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected an unknown status to be rejected with 400, got %d", w.Code)
+	}
+
+	w := export("from=2031-03-03&to=2031-03-04&status=failed")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Type"); got != "text/csv; charset=utf-8" {
+		t.Errorf("expected a CSV response, got %q", got)
+	}
+
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse CSV: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected a header and 2 failed rows, got %d rows: %v", len(rows), rows)
+	}
+	if got := strings.Join(rows[0], ","); got != "id,created_at,appointment_id,user_id,kind,channel,status,escalated,error,request_id" {
+		t.Errorf("unexpected header %q", got)
+	}
+	for i, want := range [][]string{
+		{"2", "2031-03-04T23:00:00Z", "2", "2", "REMINDER", "EMAIL", "FAILED", "false", "mailbox full, try later", "req-7"},
+		{"3", "2031-03-03T10:00:00Z", "3", "3", "CONFIRMATION", "SMS", "FAILED", "true", "", ""},
+	} {
+		if got := rows[i+1]; strings.Join(got, "|") != strings.Join(want, "|") {
+			t.Errorf("row %d: expected %v, got %v", i+1, want, got)
+		}
+	}
+}
+
+func TestGetDueRemindersListsOnlyUnsentRemindersInWindow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := repotest.Open(t)
+	now := time.Now().Truncate(time.Minute)
+	appointment := func(userID uint, in time.Duration, reminderTime int, status models.AppointmentStatus) *models.Appointment {
+		a := repotest.Appointment(userID, 1, now.Add(in), 30, status)
+		a.ReminderTime = reminderTime
+		return a
+	}
+	inWindow := appointment(1, 90*time.Minute, 60, models.StatusScheduled)
+	overdue := appointment(2, 30*time.Minute, 60, models.StatusConfirmed)
+	sent := appointment(3, time.Hour, 30, models.StatusScheduled)
+	sent.ReminderSent = true
+	disabled := appointment(4, time.Hour, 30, models.StatusScheduled)
+	repotest.MustCreate(t, db,
+		inWindow,
+		overdue,
+		sent,
+		disabled,
+		appointment(5, 5*time.Hour, 60, models.StatusScheduled), // reminder fires after the window
+		appointment(6, time.Hour, 30, models.StatusCancelled),
+		appointment(7, -time.Hour, 30, models.StatusScheduled), // already started
+	)
+	if err := db.Model(disabled).Update("reminder_enabled", false).Error; err != nil {
+		t.Fatalf("failed to disable reminder: %v", err)
+	}
+	handler := NewAdminHandler(nil, nil, nil, repository.NewAppointmentRepository(db), nil)
+
+	router := gin.New()
+	router.GET("/admin/reminders/due", handler.GetDueReminders)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/reminders/due?within=2h", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response DueRemindersResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Reminders) != 2 {
+		t.Fatalf("expected 2 due reminders, got %+v", response.Reminders)
+	}
+	// Ordered by when they fire, so the overdue reminder comes first
+	first, second := response.Reminders[0], response.Reminders[1]
+	if first.AppointmentID != overdue.ID || !first.Overdue || !first.RemindAt.Equal(now.Add(-30*time.Minute)) {
+		t.Errorf("expected the overdue reminder for appointment %d first, got %+v", overdue.ID, first)
+	}
+	if second.AppointmentID != inWindow.ID || second.Overdue || !second.RemindAt.Equal(now.Add(30*time.Minute)) {
+		t.Errorf("expected the reminder for appointment %d in 30 minutes, got %+v", inWindow.ID, second)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/reminders/due?within=30d", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected a window over 7 days to be rejected with 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"smart-doctor-booking-app/services"
+	"smart-doctor-booking-app/utils"
+)
+
+// AICallbackSecretHeader carries the shared secret the AI service signs its callbacks with
+const AICallbackSecretHeader = "X-AI-Callback-Secret"
+
+// AIHandler handles requests made by the external AI service
+type AIHandler struct {
+	schedulingService services.SchedulingService
+	callbackSecret    string // Empty disables the callback endpoint
+}
+
+// NewAIHandler creates a new AI handler that accepts callbacks carrying callbackSecret
+func NewAIHandler(schedulingService services.SchedulingService, callbackSecret string) *AIHandler {
+	return &AIHandler{
+		schedulingService: schedulingService,
+		callbackSecret:    callbackSecret,
+	}
+}
+
+// AICallbackRequest represents an asynchronous classification posted back by the AI service
+type AICallbackRequest struct {
+	RequestID   string  `json:"request_id" binding:"required"`
+	SpecialtyID uint    `json:"specialty_id" binding:"required,min=1"`
+	Confidence  float64 `json:"confidence" binding:"min=0,max=1"`
+}
+
+// ClassificationCallback handles POST /api/v1/ai/callback
+// @Summary Receive an asynchronous AI specialty classification
+// @Description Called by the AI service when a classification it accepted asynchronously is ready. The request_id is matched to the pending classification and the specialty and confidence are stored on the waiting appointment. Authenticated with the shared secret in the X-AI-Callback-Secret header.
+// @Tags ai
+// @Accept json
+// @Produce json
+// @Param X-AI-Callback-Secret header string true "Shared callback secret"
+// @Param request body AICallbackRequest true "Classification result"
+// @Success 200 {object} BookingResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/ai/callback [post]
+func (h *AIHandler) ClassificationCallback(c *gin.Context) {
+	if h.callbackSecret == "" {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+			Error:   "Callbacks disabled",
+			Message: "AI callbacks are not configured",
+		})
+		return
+	}
+
+	secret := c.GetHeader(AICallbackSecretHeader)
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(h.callbackSecret)) != 1 {
+		utils.LogSecurityEvent("ai_callback_rejected", "", c.ClientIP(), "invalid callback secret")
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid callback secret",
+		})
+		return
+	}
+
+	var request AICallbackRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	appointment, err := h.schedulingService.ResolveClassification(request.RequestID, request.SpecialtyID, request.Confidence)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid request",
+				Message: err.Error(),
+			})
+		case errors.Is(err, services.ErrUnknownClassificationRequest):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Unknown request",
+				Message: "No classification is pending for this request_id",
+			})
+		case strings.Contains(err.Error(), "not found"):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Appointment not found",
+				Message: "The appointment waiting for this classification no longer exists",
+			})
+		default:
+			utils.LogErrorContext(c.Request.Context(), err, "Failed to resolve AI classification", map[string]interface{}{
+				"request_id": request.RequestID,
+			})
+			respondServerError(c, err, ErrorResponse{
+				Error:   "Failed to store classification",
+				Message: "Unable to store the classification. Please retry the callback.",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, BookingResponse{
+		Success:     true,
+		Message:     "Classification stored successfully",
+		Appointment: appointment,
+	})
+}
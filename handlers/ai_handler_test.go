@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/repository/repotest"
+	"smart-doctor-booking-app/services"
+)
+
+// asyncClassifier accepts every symptom for later classification under the same request ID
+type asyncClassifier struct {
+	requestID string
+}
+
+func (c *asyncClassifier) Classify(symptom string) (*services.ClassificationResponse, error) {
+	return &services.ClassificationResponse{RequestID: c.requestID, Pending: true}, nil
+}
+
+func TestClassificationCallbackResolvesPendingRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := repotest.Open(t)
+	repotest.MustCreate(t, db,
+		&models.Specialty{ID: 1, Name: "General Practice"},
+		&models.Specialty{ID: 3, Name: "Dermatology"},
+		&models.Doctor{ID: 1, Name: "Doctor", SpecialtyID: 1, IsActive: true},
+	)
+	appointment := repotest.Appointment(5, 1, repotest.Day(0).Add(9*time.Hour), 30, models.StatusScheduled)
+	appointment.Symptom = "itchy rash on both arms"
+	repotest.MustCreate(t, db, appointment)
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	service := services.NewSchedulingServiceWithConfig(
+		repository.NewAppointmentRepository(db),
+		repository.NewTimeSlotRepository(db),
+		services.NewNotificationService(),
+		services.NewMemoryCacheService(services.CacheConfig{DefaultTTL: time.Hour}, logger),
+		services.DefaultSchedulingConfig(),
+	)
+	service.SetSpecialtyClassifier(&asyncClassifier{requestID: "req-rash-1"})
+	if _, err := service.ClassifyAppointment(appointment.ID); !errors.Is(err, services.ErrClassificationPending) {
+		t.Fatalf("expected the classification to be pending, got %v", err)
+	}
+
+	router := gin.New()
+	router.POST("/ai/callback", NewAIHandler(service, "callback-secret").ClassificationCallback)
+	callback := func(secret, requestID string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		body := `{"request_id": "` + requestID + `", "specialty_id": 3, "confidence": 0.92}`
+		req := httptest.NewRequest(http.MethodPost, "/ai/callback", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(AICallbackSecretHeader, secret)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := callback("wrong-secret", "req-rash-1"); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected a callback with the wrong secret to be rejected with 401, got %d", w.Code)
+	}
+	if w := callback("callback-secret", "req-unknown"); w.Code != http.StatusNotFound {
+		t.Errorf("expected an unknown request_id to return 404, got %d", w.Code)
+	}
+
+	if w := callback("callback-secret", "req-rash-1"); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var stored models.Appointment
+	if err := db.First(&stored, appointment.ID).Error; err != nil {
+		t.Fatalf("failed to load appointment: %v", err)
+	}
+	if stored.AISpecialtyID == nil || *stored.AISpecialtyID != 3 || stored.AIConfidence == nil || *stored.AIConfidence != 0.92 {
+		t.Errorf("expected specialty 3 at confidence 0.92 stored, got %v at %v", stored.AISpecialtyID, stored.AIConfidence)
+	}
+
+	if w := callback("callback-secret", "req-rash-1"); w.Code != http.StatusNotFound {
+		t.Errorf("expected a resolved request to return 404 when posted again, got %d", w.Code)
+	}
+}
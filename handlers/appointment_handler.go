@@ -1,8 +1,15 @@
 package handlers
 
 import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -30,8 +37,12 @@ type BookingRequest struct {
 	Duration        int                    `json:"duration" binding:"required,min=15,max=180"`
 	AppointmentType models.AppointmentType `json:"appointment_type"`
 	Notes           string                 `json:"notes"`
-	ReminderType    models.ReminderType    `json:"reminder_type"`
-	ReminderTime    int                    `json:"reminder_time" binding:"min=5,max=1440"` // 5 minutes to 24 hours
+	Tags            []string               `json:"tags" binding:"omitempty,max=10"`
+	Symptom         string                 `json:"symptom" binding:"omitempty,max=500"`              // Optional; classified by the AI service for specialty analysis
+	ReminderType    models.ReminderType    `json:"reminder_type"`                                    // Defaults to the clinic's reminder type
+	ReminderTime    int                    `json:"reminder_time" binding:"omitempty,min=5,max=1440"` // 5 minutes to 24 hours; defaults to the clinic's reminder time
+	ContactPhone    string                 `json:"contact_phone"`
+	LocationID      *uint                  `json:"location_id"` // Optional; defaults to where the doctor is at that time
 }
 
 // RescheduleRequest represents the request body for rescheduling an appointment
@@ -42,23 +53,38 @@ type RescheduleRequest struct {
 
 // CancellationRequest represents the request body for cancelling an appointment
 type CancellationRequest struct {
-	Reason string `json:"reason" binding:"required"`
+	ReasonCode models.CancellationReason `json:"reason_code"` // PATIENT_REQUEST, DOCTOR_UNAVAILABLE, CLINIC_CLOSURE, NO_SHOW or OTHER
+	Detail     string                    `json:"detail"`      // Optional free-text detail
+	Reason     string                    `json:"reason"`      // Deprecated free-text reason, mapped to a code when reason_code is omitted
 }
 
 // AvailabilityRequest represents the request for checking doctor availability
 type AvailabilityRequest struct {
-	DoctorID  uint   `form:"doctor_id" binding:"required"`
-	Date      string `form:"date" binding:"required"`
-	StartDate string `form:"start_date"`
-	EndDate   string `form:"end_date"`
+	DoctorID           uint   `form:"doctor_id" binding:"required"`
+	Date               string `form:"date" binding:"required"`
+	StartDate          string `form:"start_date"`
+	EndDate            string `form:"end_date"`
+	TimeOfDay          string `form:"time_of_day"`          // Optional morning, afternoon or evening
+	LocationID         uint   `form:"location_id"`          // Optional; only slots held at this location
+	ExcludeMyConflicts bool   `form:"exclude_my_conflicts"` // Optional; hide slots clashing with the caller's own appointments
+}
+
+// AvailabilityRangeRequest represents the query for the streamed availability range
+type AvailabilityRangeRequest struct {
+	DoctorID   uint   `form:"doctor_id" binding:"required"`
+	StartDate  string `form:"start_date" binding:"required"`
+	EndDate    string `form:"end_date" binding:"required"`
+	TimeOfDay  string `form:"time_of_day"` // Optional morning, afternoon or evening
+	LocationID uint   `form:"location_id"` // Optional; only slots held at this location
 }
 
 // API Response structures
 type BookingResponse struct {
-	Success      bool                `json:"success"`
-	Message      string              `json:"message"`
-	Appointment  *models.Appointment `json:"appointment,omitempty"`
-	Alternatives []models.TimeSlot   `json:"alternatives,omitempty"`
+	Success         bool                `json:"success"`
+	Message         string              `json:"message"`
+	DepositRequired bool                `json:"deposit_required"`
+	Appointment     *models.Appointment `json:"appointment,omitempty"`
+	Alternatives    []models.TimeSlot   `json:"alternatives,omitempty"`
 }
 
 type AvailabilityResponse struct {
@@ -66,6 +92,14 @@ type AvailabilityResponse struct {
 	Message      string                                  `json:"message"`
 	Availability *models.AvailabilityResponse            `json:"availability,omitempty"`
 	Range        map[string]*models.AvailabilityResponse `json:"range,omitempty"`
+	Partial      bool                                    `json:"partial,omitempty"`      // Some dates in the range could not be computed
+	FailedDates  []string                                `json:"failed_dates,omitempty"` // Dates (YYYY-MM-DD) missing from range
+}
+
+type MultiAvailabilityResponse struct {
+	Success      bool                                  `json:"success"`
+	Message      string                                `json:"message"`
+	Availability map[uint]*models.AvailabilityResponse `json:"availability"`
 }
 
 type AppointmentsResponse struct {
@@ -95,13 +129,14 @@ type SuccessResponse struct {
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 409 {object} BookingResponse "Conflict with alternatives"
+// @Failure 429 {object} ErrorResponse "Too many booking attempts for this doctor"
 // @Failure 500 {object} ErrorResponse
 // @Router /api/appointments/book [post]
 func (h *AppointmentHandler) BookAppointment(c *gin.Context) {
 	// Get user ID from JWT token
 	userID, exists := c.Get("user_id")
 	if !exists {
-		utils.LogError(nil, "User ID not found in context", map[string]interface{}{
+		utils.LogErrorContext(c.Request.Context(), nil, "User ID not found in context", map[string]interface{}{
 			"endpoint": "BookAppointment",
 		})
 		c.JSON(http.StatusUnauthorized, ErrorResponse{
@@ -113,8 +148,7 @@ func (h *AppointmentHandler) BookAppointment(c *gin.Context) {
 
 	var request BookingRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		utils.LogError(err, "Invalid booking request", map[string]interface{}{
-			"user_id": userID,
+		utils.LogErrorContext(c.Request.Context(), err, "Invalid booking request", map[string]interface{}{
 			"request": request,
 		})
 		c.JSON(http.StatusBadRequest, ErrorResponse{
@@ -125,15 +159,14 @@ func (h *AppointmentHandler) BookAppointment(c *gin.Context) {
 	}
 
 	// Parse appointment time
-	appointmentTime, err := time.Parse("2006-01-02T15:04:05Z07:00", request.AppointmentTime)
+	appointmentTime, err := utils.ParseAppointmentTime(request.AppointmentTime)
 	if err != nil {
-		utils.LogError(err, "Invalid appointment time format", map[string]interface{}{
-			"user_id":          userID,
+		utils.LogErrorContext(c.Request.Context(), err, "Invalid appointment time format", map[string]interface{}{
 			"appointment_time": request.AppointmentTime,
 		})
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:   "Invalid time format",
-			Message: "Please use ISO 8601 format (YYYY-MM-DDTHH:MM:SSZ)",
+			Message: err.Error(),
 		})
 		return
 	}
@@ -148,19 +181,91 @@ func (h *AppointmentHandler) BookAppointment(c *gin.Context) {
 		Notes:           request.Notes,
 		ReminderType:    request.ReminderType,
 		ReminderTime:    request.ReminderTime,
+		ContactPhone:    request.ContactPhone,
+		LocationID:      request.LocationID,
+		Symptom:         request.Symptom,
+		Tags:            request.Tags,
+		BypassLimits:    c.GetString("role") == "admin",
+		Context:         c.Request.Context(),
 	}
 
 	// Book the appointment
 	appointment, err := h.schedulingService.BookAppointment(bookingReq)
 	if err != nil {
+		var limitErr *services.ActiveAppointmentLimitError
+		if errors.As(err, &limitErr) {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "Booking limit reached",
+				Message: limitErr.Error(),
+				Details: map[string]interface{}{"max_active_appointments": limitErr.Limit},
+			})
+			return
+		}
+
+		if errors.Is(err, services.ErrDoctorBookingRateLimited) {
+			c.Header("Retry-After", "1")
+			c.JSON(http.StatusTooManyRequests, ErrorResponse{
+				Error:   "Too many booking attempts",
+				Message: err.Error(),
+			})
+			return
+		}
+
+		if errors.Is(err, services.ErrClinicClosed) {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "Clinic closed",
+				Message: err.Error(),
+			})
+			return
+		}
+
+		if errors.Is(err, services.ErrTimeBlocked) {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "Doctor unavailable",
+				Message: err.Error(),
+			})
+			return
+		}
+
+		if errors.Is(err, models.ErrLocationMismatch) {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "Wrong location",
+				Message: err.Error(),
+			})
+			return
+		}
+
+		if errors.Is(err, models.ErrDoctorNotAcceptingBookings) {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "Bookings paused",
+				Message: "This doctor is not accepting new bookings at the moment. Please try again later or choose another doctor.",
+			})
+			return
+		}
+
+		if errors.Is(err, utils.ErrInvalidPhone) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid phone number",
+				Message: err.Error(),
+			})
+			return
+		}
+
+		if errors.Is(err, utils.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid appointment",
+				Message: err.Error(),
+			})
+			return
+		}
+
 		// Check if error contains alternatives
 		if appointment == nil {
 			// Try to get alternative slots
 			alternatives, _ := h.schedulingService.SuggestAlternativeSlots(
 				request.DoctorID, appointmentTime, request.Duration)
 
-			utils.LogError(err, "Failed to book appointment", map[string]interface{}{
-				"user_id":            userID,
+			utils.LogErrorContext(c.Request.Context(), err, "Failed to book appointment", map[string]interface{}{
 				"doctor_id":          request.DoctorID,
 				"appointment_time":   appointmentTime,
 				"alternatives_count": len(alternatives),
@@ -174,23 +279,31 @@ func (h *AppointmentHandler) BookAppointment(c *gin.Context) {
 			return
 		}
 
-		utils.LogError(err, "Failed to book appointment", map[string]interface{}{
-			"user_id":   userID,
+		utils.LogErrorContext(c.Request.Context(), err, "Failed to book appointment", map[string]interface{}{
 			"doctor_id": request.DoctorID,
 		})
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
+		respondServerError(c, err, ErrorResponse{
 			Error:   "Booking failed",
 			Message: "Unable to book appointment. Please try again.",
 		})
 		return
 	}
 
-	utils.LogInfo("Appointment booked successfully", map[string]interface{}{
+	utils.LogInfoContext(c.Request.Context(), "Appointment booked successfully", map[string]interface{}{
 		"appointment_id": appointment.ID,
-		"user_id":        userID,
 		"doctor_id":      request.DoctorID,
 	})
 
+	if appointment.DepositRequired {
+		c.JSON(http.StatusAccepted, BookingResponse{
+			Success:         true,
+			Message:         "Appointment held pending deposit payment",
+			DepositRequired: true,
+			Appointment:     appointment,
+		})
+		return
+	}
+
 	c.JSON(http.StatusCreated, BookingResponse{
 		Success:     true,
 		Message:     "Appointment booked successfully",
@@ -198,9 +311,290 @@ func (h *AppointmentHandler) BookAppointment(c *gin.Context) {
 	})
 }
 
+// ConfirmDeposit handles POST /api/v1/appointments/:id/deposit
+// @Summary Confirm a deposit for a held appointment
+// @Description Commit an appointment held pending payment once the deposit has been paid
+// @Tags appointments
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Appointment ID"
+// @Success 200 {object} BookingResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/appointments/{id}/deposit [post]
+func (h *AppointmentHandler) ConfirmDeposit(c *gin.Context) {
+	existing, ok := authorizeAppointment(c, h.schedulingService)
+	if !ok {
+		return
+	}
+
+	appointment, err := h.schedulingService.ConfirmDeposit(c.Request.Context(), existing.ID)
+	if err != nil {
+		utils.LogErrorContext(c.Request.Context(), err, "Failed to confirm deposit", map[string]interface{}{
+			"appointment_id": existing.ID,
+		})
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "Deposit confirmation failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, BookingResponse{
+		Success:     true,
+		Message:     "Deposit received and appointment confirmed",
+		Appointment: appointment,
+	})
+}
+
+// ResendConfirmation handles POST /api/v1/appointments/:id/resend-confirmation
+// @Summary Resend an appointment confirmation
+// @Description Send the appointment's confirmation notification again. Patients can resend their own; doctors and admins any. Resends are limited per appointment.
+// @Tags appointments
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Appointment ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /api/v1/appointments/{id}/resend-confirmation [post]
+func (h *AppointmentHandler) ResendConfirmation(c *gin.Context) {
+	appointment, ok := authorizeAppointment(c, h.schedulingService)
+	if !ok {
+		return
+	}
+
+	if err := h.schedulingService.ResendConfirmation(c.Request.Context(), appointment.ID); err != nil {
+		var throttledErr *services.ResendThrottledError
+		switch {
+		case errors.As(err, &throttledErr):
+			c.Header("Retry-After", strconv.Itoa(int(throttledErr.RetryAfter.Seconds())+1))
+			c.JSON(http.StatusTooManyRequests, ErrorResponse{
+				Error:   "Too many resends",
+				Message: throttledErr.Error(),
+			})
+		case errors.Is(err, services.ErrAppointmentNotActive):
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "Appointment not active",
+				Message: err.Error(),
+			})
+		default:
+			utils.LogErrorContext(c.Request.Context(), err, "Failed to resend confirmation", map[string]interface{}{
+				"appointment_id": appointment.ID,
+			})
+			respondServerError(c, err, ErrorResponse{
+				Error:   "Resend failed",
+				Message: "Unable to resend the confirmation. Please try again.",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Message: "Confirmation resent successfully",
+	})
+}
+
+// GetAppointment handles GET /api/v1/appointments/:id
+// @Summary Get an appointment
+// @Description Get a single appointment with its doctor. Patients can only view their own appointments.
+// @Tags appointments
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Appointment ID"
+// @Success 200 {object} BookingResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/appointments/{id} [get]
+func (h *AppointmentHandler) GetAppointment(c *gin.Context) {
+	appointment, ok := authorizeAppointment(c, h.schedulingService)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, BookingResponse{
+		Success:     true,
+		Message:     "Appointment retrieved successfully",
+		Appointment: appointment,
+	})
+}
+
+// AppointmentRemindersResponse represents the reminders planned for an appointment
+type AppointmentRemindersResponse struct {
+	Success       bool                       `json:"success"`
+	AppointmentID uint                       `json:"appointment_id"`
+	Reminders     []models.ScheduledReminder `json:"reminders"`
+}
+
+// GetAppointmentReminders handles GET /api/v1/appointments/:id/reminders
+// @Summary List an appointment's reminders
+// @Description List the reminders planned for an appointment with their offset, channel and whether they have been sent. Patients can only view their own appointments.
+// @Tags appointments
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Appointment ID"
+// @Success 200 {object} AppointmentRemindersResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/appointments/{id}/reminders [get]
+func (h *AppointmentHandler) GetAppointmentReminders(c *gin.Context) {
+	appointment, ok := authorizeAppointment(c, h.schedulingService)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, AppointmentRemindersResponse{
+		Success:       true,
+		AppointmentID: appointment.ID,
+		Reminders:     appointment.ReminderSchedule(),
+	})
+}
+
+// ReminderOverrideRequest represents the request body for changing one appointment's reminder.
+// Omitted fields keep their current value.
+type ReminderOverrideRequest struct {
+	Type          models.ReminderType `json:"type"`                                              // SMS, EMAIL, PUSH or VOICE
+	OffsetMinutes *int                `json:"offset_minutes" binding:"omitempty,min=5,max=1440"` // 5 minutes to 24 hours before the appointment
+	Enabled       *bool               `json:"enabled"`
+}
+
+// OverrideReminder handles PATCH /api/v1/appointments/:id/reminder
+// @Summary Change an appointment's reminder
+// @Description Change the reminder channel, offset or enabled flag of one appointment without touching the patient's other appointments. Moving a reminder to a time still ahead sends it again at that time. Patients can only change their own appointments.
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Appointment ID"
+// @Param request body ReminderOverrideRequest true "Reminder settings"
+// @Success 200 {object} AppointmentRemindersResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/appointments/{id}/reminder [patch]
+func (h *AppointmentHandler) OverrideReminder(c *gin.Context) {
+	existing, ok := authorizeAppointment(c, h.schedulingService)
+	if !ok {
+		return
+	}
+
+	var request ReminderOverrideRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	appointment, err := h.schedulingService.OverrideReminder(existing.ID, services.ReminderOverride{
+		Type:          request.Type,
+		OffsetMinutes: request.OffsetMinutes,
+		Enabled:       request.Enabled,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid request",
+				Message: err.Error(),
+			})
+		case errors.Is(err, services.ErrAppointmentNotActive):
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "Appointment not active",
+				Message: err.Error(),
+			})
+		case strings.Contains(err.Error(), "not found"):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Appointment not found",
+				Message: "The requested appointment does not exist",
+			})
+		default:
+			utils.LogErrorContext(c.Request.Context(), err, "Failed to override appointment reminder", map[string]interface{}{
+				"appointment_id": existing.ID,
+			})
+			respondServerError(c, err, ErrorResponse{
+				Error:   "Failed to update reminder",
+				Message: "Unable to update the appointment reminder. Please try again.",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, AppointmentRemindersResponse{
+		Success:       true,
+		AppointmentID: appointment.ID,
+		Reminders:     appointment.ReminderSchedule(),
+	})
+}
+
+// GetRescheduleChain handles GET /api/v1/appointments/:id/chain
+// @Summary Get an appointment's reschedule history
+// @Description Follow the rescheduled-from and rescheduled-to links from any appointment in a chain and return every booking in it, from the original to the latest. Patients can only view their own appointments.
+// @Tags appointments
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Appointment ID"
+// @Success 200 {object} AppointmentsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/appointments/{id}/chain [get]
+func (h *AppointmentHandler) GetRescheduleChain(c *gin.Context) {
+	appointment, ok := authorizeAppointment(c, h.schedulingService)
+	if !ok {
+		return
+	}
+
+	chain, err := h.schedulingService.GetRescheduleChain(appointment.ID)
+	if err != nil {
+		if respondIfUnavailable(c, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Appointment not found",
+				Message: "The requested appointment does not exist",
+			})
+			return
+		}
+		utils.LogErrorContext(c.Request.Context(), err, "Failed to get reschedule chain", map[string]interface{}{
+			"appointment_id": appointment.ID,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get reschedule history",
+			Message: "Unable to retrieve the reschedule history. Please try again.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AppointmentsResponse{
+		Success:      true,
+		Message:      "Reschedule history retrieved successfully",
+		Appointments: chain,
+		Total:        len(chain),
+	})
+}
+
 // CancelAppointment handles DELETE /api/appointments/:id/cancel
 // @Summary Cancel an appointment
-// @Description Cancel an existing appointment
+// @Description Cancel an existing appointment. Patients can only cancel their own appointments, and doctors only those assigned to them.
 // @Tags appointments
 // @Accept json
 // @Produce json
@@ -210,59 +604,70 @@ func (h *AppointmentHandler) BookAppointment(c *gin.Context) {
 // @Success 200 {object} SuccessResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/appointments/{id}/cancel [delete]
 func (h *AppointmentHandler) CancelAppointment(c *gin.Context) {
-	// Get user ID from JWT token
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{
-		Error:   "Unauthorized",
-		Message: "User authentication required",
-	})
+	appointment, ok := authorizeAppointment(c, h.schedulingService)
+	if !ok {
 		return
 	}
 
-	// Get appointment ID from URL parameter
-	appointmentIDStr := c.Param("id")
-	appointmentID, err := strconv.ParseUint(appointmentIDStr, 10, 32)
-	if err != nil {
+	var request CancellationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid appointment ID",
-			Message: "Appointment ID must be a valid number",
+			Error:   "Invalid request",
+			Message: err.Error(),
 		})
 		return
 	}
-
-	var request CancellationRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
+	if request.ReasonCode == "" && request.Reason == "" {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:   "Invalid request",
-			Message: err.Error(),
+			Message: "reason_code is required",
 		})
 		return
 	}
 
+	// Legacy clients send only free text; keep it as the detail under a mapped code
+	reasonCode, detail := request.ReasonCode, request.Detail
+	if reasonCode == "" {
+		reasonCode = models.CancellationReasonFromText(request.Reason)
+		if detail == "" && reasonCode == models.CancellationOther {
+			detail = request.Reason
+		}
+	}
+
 	// Cancel the appointment
-	cancelledBy := "patient" // In a real app, determine if cancelled by patient or doctor
-	if err := h.schedulingService.CancelAppointment(uint(appointmentID), cancelledBy, request.Reason); err != nil {
-		utils.LogError(err, "Failed to cancel appointment", map[string]interface{}{
-			"appointment_id": appointmentID,
-			"user_id":        userID,
+	// Doctors' cancellations come with suggested replacement slots for the patient
+	cancelledBy := "patient"
+	if role := c.GetString("role"); role == "doctor" || role == "admin" {
+		cancelledBy = role
+	}
+	if err := h.schedulingService.CancelAppointment(c.Request.Context(), appointment.ID, cancelledBy, reasonCode, detail); err != nil {
+		if errors.Is(err, services.ErrInvalidCancellationReason) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid reason code",
+				Message: "reason_code must be one of PATIENT_REQUEST, DOCTOR_UNAVAILABLE, CLINIC_CLOSURE, NO_SHOW, OTHER",
+			})
+			return
+		}
+
+		utils.LogErrorContext(c.Request.Context(), err, "Failed to cancel appointment", map[string]interface{}{
+			"appointment_id": appointment.ID,
 			"cancelled_by":   cancelledBy,
 		})
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
+		respondServerError(c, err, ErrorResponse{
 			Error:   "Cancellation failed",
 			Message: "Unable to cancel appointment. Please try again.",
 		})
 		return
 	}
 
-	utils.LogInfo("Appointment cancelled successfully", map[string]interface{}{
-		"appointment_id": appointmentID,
-		"user_id":        userID,
-		"reason":         request.Reason,
+	utils.LogInfoContext(c.Request.Context(), "Appointment cancelled successfully", map[string]interface{}{
+		"appointment_id": appointment.ID,
+		"reason":         reasonCode,
 	})
 
 	c.JSON(http.StatusOK, SuccessResponse{
@@ -273,7 +678,7 @@ func (h *AppointmentHandler) CancelAppointment(c *gin.Context) {
 
 // RescheduleAppointment handles PUT /api/appointments/:id/reschedule
 // @Summary Reschedule an appointment
-// @Description Reschedule an existing appointment to a new time
+// @Description Reschedule an existing appointment to a new time. Patients can only reschedule their own appointments, and doctors only those assigned to them.
 // @Tags appointments
 // @Accept json
 // @Produce json
@@ -283,11 +688,1118 @@ func (h *AppointmentHandler) CancelAppointment(c *gin.Context) {
 // @Success 200 {object} BookingResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
 // @Failure 409 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/appointments/{id}/reschedule [put]
 func (h *AppointmentHandler) RescheduleAppointment(c *gin.Context) {
+	existing, ok := authorizeAppointment(c, h.schedulingService)
+	if !ok {
+		return
+	}
+
+	var request RescheduleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Parse new appointment time
+	newAppointmentTime, err := utils.ParseAppointmentTime(request.NewAppointmentTime)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid time format",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Calculate new end time
+	newEndTime := newAppointmentTime.Add(time.Duration(request.Duration) * time.Minute)
+
+	// Reschedule the appointment
+	newAppointment, err := h.schedulingService.RescheduleAppointment(c.Request.Context(), existing.ID, newAppointmentTime, newEndTime)
+	if err != nil {
+		utils.LogErrorContext(c.Request.Context(), err, "Failed to reschedule appointment", map[string]interface{}{
+			"appointment_id":       existing.ID,
+			"new_appointment_time": newAppointmentTime,
+		})
+		status := http.StatusConflict
+		if errors.Is(err, models.ErrEndTimeMismatch) || errors.Is(err, utils.ErrInvalidInput) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, ErrorResponse{
+			Error:   "Reschedule failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	utils.LogInfoContext(c.Request.Context(), "Appointment rescheduled successfully", map[string]interface{}{
+		"appointment_id":     existing.ID,
+		"new_appointment_id": newAppointment.ID,
+	})
+
+	c.JSON(http.StatusOK, BookingResponse{
+		Success:     true,
+		Message:     "Appointment rescheduled successfully",
+		Appointment: newAppointment,
+	})
+}
+
+// ChangeAppointmentTypeRequest represents the request body for changing an appointment's type
+type ChangeAppointmentTypeRequest struct {
+	Type models.AppointmentType `json:"type" binding:"required"`
+}
+
+// ConfirmBatchRequest represents the request body for confirming several appointments at once
+type ConfirmBatchRequest struct {
+	IDs []uint `json:"ids" binding:"required,min=1,max=100,dive,min=1"`
+}
+
+// ConfirmBatchResponse reports the outcome for each appointment in a batch confirmation
+type ConfirmBatchResponse struct {
+	Success   bool                        `json:"success"`
+	Message   string                      `json:"message"`
+	Confirmed int                         `json:"confirmed"`
+	Failed    int                         `json:"failed"`
+	Results   []models.ConfirmationResult `json:"results"`
+}
+
+// ConfirmAppointments handles POST /api/v1/appointments/confirm-batch
+// @Summary Confirm several appointments at once
+// @Description Confirm each scheduled appointment in the list. Appointments that are already confirmed, no longer active or missing are reported as failures without stopping the rest. Doctors and admins only.
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body ConfirmBatchRequest true "Appointment IDs (at most 100)"
+// @Success 200 {object} ConfirmBatchResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/appointments/confirm-batch [post]
+func (h *AppointmentHandler) ConfirmAppointments(c *gin.Context) {
+	var request ConfirmBatchRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	results := h.schedulingService.ConfirmAppointments(request.IDs, c.GetString("role"))
+
+	confirmed := 0
+	for _, result := range results {
+		if result.Success {
+			confirmed++
+		}
+	}
+
+	c.JSON(http.StatusOK, ConfirmBatchResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Confirmed %d of %d appointments", confirmed, len(results)),
+		Confirmed: confirmed,
+		Failed:    len(results) - confirmed,
+		Results:   results,
+	})
+}
+
+// UpdateTagsRequest represents the request body for replacing an appointment's tags
+type UpdateTagsRequest struct {
+	Tags []string `json:"tags" binding:"max=10"` // Replaces every existing tag; an empty list clears them
+}
+
+// SetAppointmentTags handles PATCH /api/v1/appointments/:id/tags
+// @Summary Replace an appointment's tags
+// @Description Replace the labels on an appointment, such as first-visit or insurance-pending. Tags are lowercased; each is 1-32 letters, digits or hyphens, at most 10 per appointment. Admins and the doctor the appointment is assigned to only.
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Appointment ID"
+// @Param request body UpdateTagsRequest true "New tags"
+// @Success 200 {object} BookingResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/appointments/{id}/tags [patch]
+func (h *AppointmentHandler) SetAppointmentTags(c *gin.Context) {
+	existing, ok := authorizeAppointment(c, h.schedulingService)
+	if !ok {
+		return
+	}
+
+	var request UpdateTagsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	appointment, err := h.schedulingService.SetAppointmentTags(existing.ID, request.Tags)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid tags",
+				Message: err.Error(),
+			})
+		case strings.Contains(err.Error(), "not found"):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Appointment not found",
+				Message: "The requested appointment does not exist",
+			})
+		default:
+			utils.LogErrorContext(c.Request.Context(), err, "Failed to update appointment tags", map[string]interface{}{
+				"appointment_id": existing.ID,
+			})
+			respondServerError(c, err, ErrorResponse{
+				Error:   "Failed to update tags",
+				Message: "Unable to update the appointment tags. Please try again.",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, BookingResponse{
+		Success:     true,
+		Message:     "Appointment tags updated successfully",
+		Appointment: appointment,
+	})
+}
+
+// ChangeAppointmentType handles PATCH /api/v1/appointments/:id/type
+// @Summary Change an appointment's type
+// @Description Change the type of a booked appointment, for example from a consultation to a follow-up. The booked duration must fit the new type's limits. Admins and the doctor the appointment is assigned to only.
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Appointment ID"
+// @Param request body ChangeAppointmentTypeRequest true "New type"
+// @Success 200 {object} BookingResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Router /api/v1/appointments/{id}/type [patch]
+func (h *AppointmentHandler) ChangeAppointmentType(c *gin.Context) {
+	existing, ok := authorizeAppointment(c, h.schedulingService)
+	if !ok {
+		return
+	}
+
+	var request ChangeAppointmentTypeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	appointment, err := h.schedulingService.ChangeAppointmentType(existing.ID, request.Type, c.GetString("role"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidAppointmentType):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid appointment type",
+				Message: err.Error(),
+			})
+		case errors.Is(err, services.ErrTypeDurationMismatch):
+			c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+				Error:   "Duration mismatch",
+				Message: err.Error(),
+			})
+		case errors.Is(err, services.ErrAppointmentNotActive):
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "Appointment not active",
+				Message: err.Error(),
+			})
+		case strings.Contains(err.Error(), "not found"):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Appointment not found",
+				Message: "The requested appointment does not exist",
+			})
+		default:
+			utils.LogErrorContext(c.Request.Context(), err, "Failed to change appointment type", map[string]interface{}{
+				"appointment_id": existing.ID,
+				"type":           request.Type,
+			})
+			respondServerError(c, err, ErrorResponse{
+				Error:   "Failed to change appointment type",
+				Message: "Unable to change the appointment type. Please try again.",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, BookingResponse{
+		Success:     true,
+		Message:     "Appointment type changed successfully",
+		Appointment: appointment,
+	})
+}
+
+// Availability and Viewing Endpoints
+
+// GetDoctorAvailability handles GET /api/appointments/availability
+// @Summary Get doctor's available time slots
+// @Description Get available time slots for a doctor on a specific date or date range. When some dates in a range cannot be computed, the response is marked partial and lists them in failed_dates.
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param doctor_id query int true "Doctor ID"
+// @Param date query string false "Specific date (YYYY-MM-DD)"
+// @Param start_date query string false "Start date for range (YYYY-MM-DD)"
+// @Param end_date query string false "End date for range (YYYY-MM-DD)"
+// @Param time_of_day query string false "Only return slots starting in this part of the day (morning, afternoon, evening)"
+// @Param location_id query int false "Only return slots held at this location"
+// @Param exclude_my_conflicts query bool false "Hide slots that overlap the caller's own appointments with any doctor"
+// @Success 200 {object} AvailabilityResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/appointments/availability [get]
+func (h *AppointmentHandler) GetDoctorAvailability(c *gin.Context) {
+	var request AvailabilityRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	timeOfDay, ok := bindTimeOfDay(c, request.TimeOfDay)
+	if !ok {
+		return
+	}
+
+	// Check if it's a date range request
+	if request.StartDate != "" && request.EndDate != "" {
+		// Parse date range
+		startDate, err := time.Parse("2006-01-02", request.StartDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid start date format",
+				Message: "Please use YYYY-MM-DD format",
+			})
+			return
+		}
+
+		endDate, err := time.Parse("2006-01-02", request.EndDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid end date format",
+				Message: "Please use YYYY-MM-DD format",
+			})
+			return
+		}
+
+		// Get availability range
+		availabilityRange, failedDates, err := h.schedulingService.GetDoctorAvailabilityRange(request.DoctorID, startDate, endDate)
+		if err != nil {
+			utils.LogErrorContext(c.Request.Context(), err, "Failed to get doctor availability range", map[string]interface{}{
+				"doctor_id":  request.DoctorID,
+				"start_date": startDate,
+				"end_date":   endDate,
+			})
+			respondServerError(c, err, ErrorResponse{
+				Error:   "Failed to get availability",
+				Message: "Unable to retrieve doctor availability. Please try again.",
+			})
+			return
+		}
+
+		if timeOfDay != "" {
+			for day, availability := range availabilityRange {
+				availabilityRange[day] = h.schedulingService.FilterByTimeOfDay(availability, timeOfDay)
+			}
+		}
+		if request.LocationID != 0 {
+			for day, availability := range availabilityRange {
+				availabilityRange[day] = h.schedulingService.FilterByLocation(availability, request.LocationID)
+			}
+		}
+		if request.ExcludeMyConflicts {
+			commitments, ok := h.getCallerCommitments(c, startDate, endDate.AddDate(0, 0, 1))
+			if !ok {
+				return
+			}
+			for day, availability := range availabilityRange {
+				availabilityRange[day] = h.schedulingService.FilterPatientConflicts(availability, commitments)
+			}
+		}
+
+		message := "Doctor availability retrieved successfully"
+		if len(failedDates) > 0 {
+			message = fmt.Sprintf("Doctor availability retrieved, except for %d dates that could not be computed", len(failedDates))
+		}
+
+		c.JSON(http.StatusOK, AvailabilityResponse{
+			Success:     true,
+			Message:     message,
+			Range:       availabilityRange,
+			Partial:     len(failedDates) > 0,
+			FailedDates: failedDates,
+		})
+		return
+	}
+
+	// Single date request
+	if request.Date == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Missing date parameter",
+			Message: "Please provide either 'date' or both 'start_date' and 'end_date'",
+		})
+		return
+	}
+
+	// Parse single date
+	date, err := time.Parse("2006-01-02", request.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid date format",
+			Message: "Please use YYYY-MM-DD format",
+		})
+		return
+	}
+
+	// Get availability for single date
+	availability, err := h.schedulingService.GetDoctorAvailability(request.DoctorID, date)
+	if err != nil {
+		utils.LogErrorContext(c.Request.Context(), err, "Failed to get doctor availability", map[string]interface{}{
+			"doctor_id": request.DoctorID,
+			"date":      date,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get availability",
+			Message: "Unable to retrieve doctor availability. Please try again.",
+		})
+		return
+	}
+
+	if timeOfDay != "" {
+		availability = h.schedulingService.FilterByTimeOfDay(availability, timeOfDay)
+	}
+	if request.LocationID != 0 {
+		availability = h.schedulingService.FilterByLocation(availability, request.LocationID)
+	}
+	if request.ExcludeMyConflicts {
+		commitments, ok := h.getCallerCommitments(c, date, date.AddDate(0, 0, 1))
+		if !ok {
+			return
+		}
+		availability = h.schedulingService.FilterPatientConflicts(availability, commitments)
+	}
+
+	c.JSON(http.StatusOK, AvailabilityResponse{
+		Success:      true,
+		Message:      "Doctor availability retrieved successfully",
+		Availability: availability,
+	})
+}
+
+// getCallerCommitments loads the authenticated user's appointments overlapping [from, to),
+// responding with an error and returning false when they cannot be loaded
+func (h *AppointmentHandler) getCallerCommitments(c *gin.Context, from, to time.Time) ([]models.Appointment, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User authentication required",
+		})
+		return nil, false
+	}
+
+	commitments, err := h.schedulingService.GetPatientCommitments(userID.(uint), from, to)
+	if err != nil {
+		utils.LogErrorContext(c.Request.Context(), err, "Failed to get patient appointments for availability", map[string]interface{}{
+			"user_id": userID,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get availability",
+			Message: "Unable to check your existing appointments. Please try again.",
+		})
+		return nil, false
+	}
+	return commitments, true
+}
+
+// bindTimeOfDay parses the optional time_of_day query value, responding with 400 when it is not
+// a known part of the day. An empty value means no filtering.
+func bindTimeOfDay(c *gin.Context, value string) (services.TimeOfDay, bool) {
+	if value == "" {
+		return "", true
+	}
+
+	timeOfDay, err := services.ParseTimeOfDay(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid time of day",
+			Message: "time_of_day must be one of morning, afternoon or evening",
+		})
+		return "", false
+	}
+	return timeOfDay, true
+}
+
+// StreamDoctorAvailability handles GET /api/appointments/availability/stream
+// @Summary Stream a doctor's availability over a date range
+// @Description Streams the same structure as the range form of the availability endpoint, writing each day as soon as it is computed so long ranges are never held in memory. If a backing service fails part way through, the document is closed with success=false and an error field.
+// @Tags appointments
+// @Produce json
+// @Param doctor_id query int true "Doctor ID"
+// @Param start_date query string true "Start date (YYYY-MM-DD)"
+// @Param end_date query string true "End date (YYYY-MM-DD)"
+// @Param time_of_day query string false "Only return slots starting in this part of the day (morning, afternoon, evening)"
+// @Param location_id query int false "Only return slots held at this location"
+// @Success 200 {object} AvailabilityResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/appointments/availability/stream [get]
+func (h *AppointmentHandler) StreamDoctorAvailability(c *gin.Context) {
+	var request AvailabilityRangeRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	timeOfDay, ok := bindTimeOfDay(c, request.TimeOfDay)
+	if !ok {
+		return
+	}
+
+	startDate, err := time.Parse("2006-01-02", request.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid start date format",
+			Message: "Please use YYYY-MM-DD format",
+		})
+		return
+	}
+
+	endDate, err := time.Parse("2006-01-02", request.EndDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid end date format",
+			Message: "Please use YYYY-MM-DD format",
+		})
+		return
+	}
+
+	if endDate.Before(startDate) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid date range",
+			Message: "end_date must not be before start_date",
+		})
+		return
+	}
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	// The document is written as {"range":{"<date>":{...},...},"success":...,"message":...} so it
+	// decodes into AvailabilityResponse exactly like the buffered range response
+	currentDate := startDate
+	opened := false
+	wroteDay := false
+	c.Stream(func(w io.Writer) bool {
+		if !opened {
+			opened = true
+			_, err := io.WriteString(w, `{"range":{`)
+			return err == nil
+		}
+
+		if currentDate.After(endDate) {
+			writeAvailabilityStreamTrailer(w, true, "Doctor availability retrieved successfully", "")
+			return false
+		}
+
+		date := currentDate
+		currentDate = currentDate.AddDate(0, 0, 1)
+
+		availability, err := h.schedulingService.GetDoctorAvailability(request.DoctorID, date)
+		if err != nil {
+			utils.LogErrorContext(c.Request.Context(), err, "Failed to stream availability for date", map[string]interface{}{
+				"doctor_id": request.DoctorID,
+				"date":      date,
+			})
+			if utils.IsTransientError(err) {
+				// The status line has already been sent, so report the failure inside the document
+				writeAvailabilityStreamTrailer(w, false, "Availability is incomplete", "Service temporarily unavailable")
+				return false
+			}
+			// Skip the day, as the buffered range endpoint does
+			return true
+		}
+		if timeOfDay != "" {
+			availability = h.schedulingService.FilterByTimeOfDay(availability, timeOfDay)
+		}
+		if request.LocationID != 0 {
+			availability = h.schedulingService.FilterByLocation(availability, request.LocationID)
+		}
+
+		key, _ := json.Marshal(date.Format("2006-01-02"))
+		value, err := json.Marshal(availability)
+		if err != nil {
+			utils.LogErrorContext(c.Request.Context(), err, "Failed to encode availability for date", map[string]interface{}{
+				"doctor_id": request.DoctorID,
+				"date":      date,
+			})
+			return true
+		}
+
+		var chunk bytes.Buffer
+		if wroteDay {
+			chunk.WriteByte(',')
+		}
+		chunk.Write(key)
+		chunk.WriteByte(':')
+		chunk.Write(value)
+		if _, err := w.Write(chunk.Bytes()); err != nil {
+			return false
+		}
+		wroteDay = true
+		return true
+	})
+}
+
+// writeAvailabilityStreamTrailer closes the range object and the streamed availability document
+func writeAvailabilityStreamTrailer(w io.Writer, success bool, message, errMessage string) {
+	trailer, _ := json.Marshal(struct {
+		Success bool   `json:"success"`
+		Message string `json:"message"`
+		Error   string `json:"error,omitempty"`
+	}{success, message, errMessage})
+
+	// Splice the trailer's fields after the range object: `}` + `,"success":...}`
+	trailer[0] = ','
+	_, _ = io.WriteString(w, "}")
+	_, _ = w.Write(trailer)
+}
+
+// GetMultiDoctorAvailability handles GET /api/appointments/availability/multi
+// @Summary Get availability for several doctors
+// @Description Get available time slots for multiple doctors on a date, keyed by doctor ID
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param doctor_ids query string true "Comma-separated doctor IDs (max 10)"
+// @Param date query string true "Date (YYYY-MM-DD)"
+// @Success 200 {object} MultiAvailabilityResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/appointments/availability/multi [get]
+func (h *AppointmentHandler) GetMultiDoctorAvailability(c *gin.Context) {
+	doctorIDsStr := c.Query("doctor_ids")
+	if doctorIDsStr == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Missing doctor_ids parameter",
+			Message: "Please provide a comma-separated list of doctor IDs",
+		})
+		return
+	}
+
+	var doctorIDs []uint
+	seen := make(map[uint]bool)
+	for _, idStr := range strings.Split(doctorIDsStr, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(idStr), 10, 32)
+		if err != nil || id == 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid doctor ID",
+				Message: "Doctor IDs must be valid numbers",
+			})
+			return
+		}
+		if !seen[uint(id)] {
+			seen[uint(id)] = true
+			doctorIDs = append(doctorIDs, uint(id))
+		}
+	}
+
+	if len(doctorIDs) > services.MaxDoctorsPerAvailabilityRequest {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Too many doctors",
+			Message: "Please request availability for at most " + strconv.Itoa(services.MaxDoctorsPerAvailabilityRequest) + " doctors",
+		})
+		return
+	}
+
+	dateStr := c.Query("date")
+	if dateStr == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Missing date parameter",
+			Message: "Please provide a date in YYYY-MM-DD format",
+		})
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid date format",
+			Message: "Please use YYYY-MM-DD format",
+		})
+		return
+	}
+
+	availability, err := h.schedulingService.GetMultiDoctorAvailability(doctorIDs, date)
+	if err != nil {
+		utils.LogErrorContext(c.Request.Context(), err, "Failed to get multi-doctor availability", map[string]interface{}{
+			"doctor_ids": doctorIDs,
+			"date":       date,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get availability",
+			Message: "Unable to retrieve doctor availability. Please try again.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, MultiAvailabilityResponse{
+		Success:      true,
+		Message:      "Doctor availability retrieved successfully",
+		Availability: availability,
+	})
+}
+
+// AlternativeDoctorsResponse represents doctors free for a requested window
+type AlternativeDoctorsResponse struct {
+	Success bool            `json:"success"`
+	Message string          `json:"message"`
+	Doctors []models.Doctor `json:"doctors"`
+	Total   int             `json:"total"`
+}
+
+// GetAlternativeDoctors handles GET /api/v1/appointments/alternative-doctors
+// @Summary Find other doctors free at a time
+// @Description Get active doctors in a specialty with an available slot and no conflicting appointment for the window
+// @Tags appointments
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param specialty_id query int true "Specialty ID"
+// @Param start query string true "Window start (RFC 3339)"
+// @Param end query string true "Window end (RFC 3339)"
+// @Success 200 {object} AlternativeDoctorsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/appointments/alternative-doctors [get]
+func (h *AppointmentHandler) GetAlternativeDoctors(c *gin.Context) {
+	specialtyID, err := strconv.ParseUint(c.Query("specialty_id"), 10, 32)
+	if err != nil || specialtyID == 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid specialty ID",
+			Message: "specialty_id must be a valid number",
+		})
+		return
+	}
+
+	startTime, err := utils.ParseAppointmentTime(c.Query("start"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid start time",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	endTime, err := utils.ParseAppointmentTime(c.Query("end"))
+	if err != nil || !endTime.After(startTime) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid end time",
+			Message: "end must be an ISO 8601 time after start",
+		})
+		return
+	}
+
+	doctors, err := h.schedulingService.GetAlternativeDoctors(uint(specialtyID), startTime, endTime)
+	if err != nil {
+		utils.LogErrorContext(c.Request.Context(), err, "Failed to get alternative doctors", map[string]interface{}{
+			"specialty_id": specialtyID,
+			"start":        startTime,
+			"end":          endTime,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get doctors",
+			Message: "Unable to find alternative doctors. Please try again.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AlternativeDoctorsResponse{
+		Success: true,
+		Message: "Alternative doctors retrieved successfully",
+		Doctors: doctors,
+		Total:   len(doctors),
+	})
+}
+
+// GetPatientAppointments handles GET /api/appointments/patient
+// @Summary Get patient's appointments
+// @Description Get all appointments for the authenticated patient
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param status query string false "Filter by status (scheduled, confirmed, cancelled, completed)"
+// @Param tag query string false "Only appointments carrying this tag"
+// @Success 200 {object} AppointmentsResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/appointments/patient [get]
+func (h *AppointmentHandler) GetPatientAppointments(c *gin.Context) {
+	// Get user ID from JWT token
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User authentication required",
+		})
+		return
+	}
+
+	// Get optional status filter
+	status := c.Query("status")
+
+	// Get patient appointments
+	appointments, err := h.schedulingService.GetPatientAppointments(userID.(uint), status)
+	if err != nil {
+		utils.LogErrorContext(c.Request.Context(), err, "Failed to get patient appointments", map[string]interface{}{
+			"status": status,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get appointments",
+			Message: "Unable to retrieve appointments. Please try again.",
+		})
+		return
+	}
+	appointments = models.FilterAppointmentsByTag(appointments, c.Query("tag"))
+
+	c.JSON(http.StatusOK, AppointmentsResponse{
+		Success:      true,
+		Message:      "Appointments retrieved successfully",
+		Appointments: appointments,
+		Total:        len(appointments),
+	})
+}
+
+// GetReviewEligibleAppointments handles GET /api/v1/reviews/eligible
+// @Summary List appointments the patient can review
+// @Description Get the authenticated patient's completed appointments that have not been reviewed yet, most recent first.
+// @Tags reviews
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} AppointmentsResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/reviews/eligible [get]
+func (h *AppointmentHandler) GetReviewEligibleAppointments(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User authentication required",
+		})
+		return
+	}
+
+	appointments, err := h.schedulingService.GetReviewEligibleAppointments(userID.(uint))
+	if err != nil {
+		utils.LogErrorContext(c.Request.Context(), err, "Failed to get review-eligible appointments", nil)
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get appointments",
+			Message: "Unable to retrieve appointments. Please try again.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AppointmentsResponse{
+		Success:      true,
+		Message:      "Review-eligible appointments retrieved successfully",
+		Appointments: appointments,
+		Total:        len(appointments),
+	})
+}
+
+// ReviewRequest represents the request body for reviewing a completed appointment
+type ReviewRequest struct {
+	AppointmentID uint   `json:"appointment_id" binding:"required,min=1"`
+	Rating        int    `json:"rating" binding:"required,min=1,max=5"`
+	Comment       string `json:"comment" binding:"max=2000"`
+}
+
+// ReviewResponse represents a saved review
+type ReviewResponse struct {
+	Success bool           `json:"success"`
+	Message string         `json:"message"`
+	Review  *models.Review `json:"review"`
+}
+
+// SubmitReview handles POST /api/v1/reviews
+// @Summary Review a completed appointment
+// @Description Rate one of the authenticated patient's completed appointments from 1 to 5, with an optional comment. Each appointment can be reviewed once.
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body ReviewRequest true "Review"
+// @Success 201 {object} ReviewResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/reviews [post]
+func (h *AppointmentHandler) SubmitReview(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User authentication required",
+		})
+		return
+	}
+
+	var request ReviewRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	review := &models.Review{
+		AppointmentID: request.AppointmentID,
+		UserID:        userID.(uint),
+		Rating:        request.Rating,
+		Comment:       utils.SanitizeString(request.Comment),
+	}
+	if err := h.schedulingService.SubmitReview(review); err != nil {
+		switch {
+		case errors.Is(err, utils.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid review",
+				Message: err.Error(),
+			})
+		case errors.Is(err, models.ErrAlreadyReviewed):
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "Already reviewed",
+				Message: err.Error(),
+			})
+		case errors.Is(err, models.ErrNotReviewable):
+			c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+				Error:   "Not reviewable",
+				Message: err.Error(),
+			})
+		case strings.Contains(err.Error(), "not found"):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Appointment not found",
+				Message: "The requested appointment does not exist",
+			})
+		default:
+			utils.LogErrorContext(c.Request.Context(), err, "Failed to submit review", map[string]interface{}{
+				"appointment_id": request.AppointmentID,
+			})
+			respondServerError(c, err, ErrorResponse{
+				Error:   "Failed to submit review",
+				Message: "Unable to save your review. Please try again.",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, ReviewResponse{
+		Success: true,
+		Message: "Review submitted successfully",
+		Review:  review,
+	})
+}
+
+// AppointmentHistoryRequest represents the query for a doctor-patient visit history
+type AppointmentHistoryRequest struct {
+	DoctorID uint `form:"doctor_id" binding:"required"`
+	UserID   uint `form:"user_id" binding:"required"`
+}
+
+// GetAppointmentHistory handles GET /api/v1/appointments/history
+// @Summary Get a patient's visit history with a doctor
+// @Description Get every past appointment between one doctor and one patient, oldest first, including notes. Doctors and admins only.
+// @Tags appointments
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param doctor_id query int true "Doctor ID"
+// @Param user_id query int true "Patient user ID"
+// @Success 200 {object} AppointmentsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/appointments/history [get]
+func (h *AppointmentHandler) GetAppointmentHistory(c *gin.Context) {
+	var request AppointmentHistoryRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	appointments, err := h.schedulingService.GetAppointmentHistory(request.DoctorID, request.UserID, time.Now())
+	if err != nil {
+		utils.LogErrorContext(c.Request.Context(), err, "Failed to get appointment history", map[string]interface{}{
+			"doctor_id": request.DoctorID,
+			"user_id":   request.UserID,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get appointment history",
+			Message: "Unable to retrieve the appointment history. Please try again.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AppointmentsResponse{
+		Success:      true,
+		Message:      "Appointment history retrieved successfully",
+		Appointments: appointments,
+		Total:        len(appointments),
+	})
+}
+
+// AppointmentChangesRequest represents the query for incrementally syncing appointments
+type AppointmentChangesRequest struct {
+	Since string `form:"since" binding:"required"`                // RFC 3339 timestamp; pass the previous response's next_since
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=500"` // Defaults to 100
+}
+
+// AppointmentChangesResponse lists appointments changed since a cursor, oldest change first
+type AppointmentChangesResponse struct {
+	Success   bool                       `json:"success"`
+	Changes   []models.AppointmentChange `json:"changes"`
+	Total     int                        `json:"total"`
+	NextSince string                     `json:"next_since"` // Cursor for the next request
+	HasMore   bool                       `json:"has_more"`   // More changes are waiting after next_since
+}
+
+// GetAppointmentChanges handles GET /api/v1/appointments/changes
+// @Summary Get appointments changed since a timestamp
+// @Description Incrementally sync appointments: returns appointments created, updated or deleted after since, oldest change first. Deleted appointments are included and flagged. Patients get their own appointments; admins get everyone's. Keep requesting with next_since while has_more is true.
+// @Tags appointments
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param since query string true "RFC 3339 timestamp"
+// @Param limit query int false "Maximum changes to return (1-500, default 100)"
+// @Success 200 {object} AppointmentChangesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/appointments/changes [get]
+func (h *AppointmentHandler) GetAppointmentChanges(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User authentication required",
+		})
+		return
+	}
+
+	var request AppointmentChangesRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	since, err := time.Parse(time.RFC3339Nano, request.Since)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid since",
+			Message: "since must be an RFC 3339 timestamp, e.g. 2024-01-15T09:00:00Z",
+		})
+		return
+	}
+
+	var patientID *uint
+	if c.GetString("role") != "admin" {
+		id := userID.(uint)
+		patientID = &id
+	}
+
+	limit := request.Limit
+	if limit == 0 {
+		limit = services.DefaultAppointmentChangesLimit
+	}
+
+	changes, err := h.schedulingService.GetAppointmentChanges(patientID, since, limit)
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid request",
+				Message: err.Error(),
+			})
+			return
+		}
+		utils.LogErrorContext(c.Request.Context(), err, "Failed to get appointment changes", map[string]interface{}{
+			"since": since,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get appointment changes",
+			Message: "Unable to retrieve appointment changes. Please try again.",
+		})
+		return
+	}
+
+	nextSince := since
+	if len(changes) > 0 {
+		nextSince = changes[len(changes)-1].ChangedAt
+	}
+
+	c.JSON(http.StatusOK, AppointmentChangesResponse{
+		Success:   true,
+		Changes:   changes,
+		Total:     len(changes),
+		NextSince: nextSince.UTC().Format(time.RFC3339Nano),
+		HasMore:   len(changes) == limit,
+	})
+}
+
+// UpcomingAppointmentsRequest represents the query for a patient's upcoming appointments
+type UpcomingAppointmentsRequest struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=50"` // Defaults to 3
+}
+
+// GetUpcomingAppointments handles GET /api/appointments/upcoming
+// @Summary Get patient's upcoming appointments
+// @Description Get the soonest upcoming appointments for the authenticated patient, in ascending time order
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param limit query int false "Maximum appointments to return (1-50, default 3)"
+// @Success 200 {object} AppointmentsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/appointments/upcoming [get]
+func (h *AppointmentHandler) GetUpcomingAppointments(c *gin.Context) {
 	// Get user ID from JWT token
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -298,86 +1810,67 @@ func (h *AppointmentHandler) RescheduleAppointment(c *gin.Context) {
 		return
 	}
 
-	// Get appointment ID from URL parameter
-	appointmentIDStr := c.Param("id")
-	appointmentID, err := strconv.ParseUint(appointmentIDStr, 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid appointment ID",
-			Message: "Appointment ID must be a valid number",
-		})
-		return
-	}
-
-	var request RescheduleRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
+	var request UpcomingAppointmentsRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:   "Invalid request",
 			Message: err.Error(),
 		})
 		return
 	}
-
-	// Parse new appointment time
-	newAppointmentTime, err := time.Parse("2006-01-02T15:04:05Z07:00", request.NewAppointmentTime)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid time format",
-			Message: "Please use ISO 8601 format (YYYY-MM-DDTHH:MM:SSZ)",
-		})
-		return
+	limit := request.Limit
+	if limit == 0 {
+		limit = services.DefaultUpcomingAppointmentsLimit
 	}
 
-	// Calculate new end time
-	newEndTime := newAppointmentTime.Add(time.Duration(request.Duration) * time.Minute)
-
-	// Reschedule the appointment
-	newAppointment, err := h.schedulingService.RescheduleAppointment(uint(appointmentID), newAppointmentTime, newEndTime)
+	// Get upcoming appointments
+	appointments, err := h.schedulingService.GetUpcomingAppointments(userID.(uint), limit)
 	if err != nil {
-		utils.LogError(err, "Failed to reschedule appointment", map[string]interface{}{
-			"appointment_id":       appointmentID,
-			"user_id":              userID,
-			"new_appointment_time": newAppointmentTime,
-		})
-		c.JSON(http.StatusConflict, ErrorResponse{
-			Error:   "Reschedule failed",
-			Message: err.Error(),
+		utils.LogErrorContext(c.Request.Context(), err, "Failed to get upcoming appointments", nil)
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get appointments",
+			Message: "Unable to retrieve upcoming appointments. Please try again.",
 		})
 		return
 	}
 
-	utils.LogInfo("Appointment rescheduled successfully", map[string]interface{}{
-		"appointment_id":     appointmentID,
-		"new_appointment_id": newAppointment.ID,
-		"user_id":            userID,
+	c.JSON(http.StatusOK, AppointmentsResponse{
+		Success:      true,
+		Message:      "Upcoming appointments retrieved successfully",
+		Appointments: appointments,
+		Total:        len(appointments),
 	})
+}
 
-	c.JSON(http.StatusOK, BookingResponse{
-		Success:     true,
-		Message:     "Appointment rescheduled successfully",
-		Appointment: newAppointment,
-	})
+// UpcomingBatchRequest represents the request body for loading several patients' upcoming appointments
+type UpcomingBatchRequest struct {
+	UserIDs []uint `json:"user_ids" binding:"required,min=1,max=50,dive,min=1"`
 }
 
-// Availability and Viewing Endpoints
+// UpcomingBatchResponse maps each requested patient to their upcoming appointments
+type UpcomingBatchResponse struct {
+	Success      bool                          `json:"success"`
+	Message      string                        `json:"message"`
+	Appointments map[uint][]models.Appointment `json:"appointments"`
+}
 
-// GetDoctorAvailability handles GET /api/appointments/availability
-// @Summary Get doctor's available time slots
-// @Description Get available time slots for a doctor on a specific date or date range
+// GetUpcomingAppointmentsBatch handles POST /api/v1/appointments/upcoming/batch
+// @Summary Get several patients' upcoming appointments
+// @Description Load the upcoming appointments of up to 50 patients in one request, keyed by patient ID. Patients without upcoming appointments map to an empty list. Doctors and admins only.
 // @Tags appointments
 // @Accept json
 // @Produce json
-// @Param doctor_id query int true "Doctor ID"
-// @Param date query string false "Specific date (YYYY-MM-DD)"
-// @Param start_date query string false "Start date for range (YYYY-MM-DD)"
-// @Param end_date query string false "End date for range (YYYY-MM-DD)"
-// @Success 200 {object} AvailabilityResponse
+// @Param Authorization header string true "Bearer token"
+// @Param request body UpcomingBatchRequest true "Patient IDs (at most 50)"
+// @Success 200 {object} UpcomingBatchResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
-// @Router /api/appointments/availability [get]
-func (h *AppointmentHandler) GetDoctorAvailability(c *gin.Context) {
-	var request AvailabilityRequest
-	if err := c.ShouldBindQuery(&request); err != nil {
+// @Router /api/v1/appointments/upcoming/batch [post]
+func (h *AppointmentHandler) GetUpcomingAppointmentsBatch(c *gin.Context) {
+	var request UpcomingBatchRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:   "Invalid request",
 			Message: err.Error(),
@@ -385,104 +1878,111 @@ func (h *AppointmentHandler) GetDoctorAvailability(c *gin.Context) {
 		return
 	}
 
-	// Check if it's a date range request
-	if request.StartDate != "" && request.EndDate != "" {
-		// Parse date range
-		startDate, err := time.Parse("2006-01-02", request.StartDate)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, ErrorResponse{
-				Error:   "Invalid start date format",
-				Message: "Please use YYYY-MM-DD format",
-			})
-			return
-		}
-
-		endDate, err := time.Parse("2006-01-02", request.EndDate)
-		if err != nil {
+	appointments, err := h.schedulingService.GetUpcomingAppointmentsForUsers(request.UserIDs)
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidInput) {
 			c.JSON(http.StatusBadRequest, ErrorResponse{
-				Error:   "Invalid end date format",
-				Message: "Please use YYYY-MM-DD format",
+				Error:   "Invalid request",
+				Message: err.Error(),
 			})
 			return
 		}
-
-		// Get availability range
-		availabilityRange, err := h.schedulingService.GetDoctorAvailabilityRange(request.DoctorID, startDate, endDate)
-		if err != nil {
-			utils.LogError(err, "Failed to get doctor availability range", map[string]interface{}{
-			"doctor_id":  request.DoctorID,
-			"start_date": startDate,
-			"end_date":   endDate,
+		utils.LogErrorContext(c.Request.Context(), err, "Failed to get upcoming appointments batch", map[string]interface{}{
+			"patients": len(request.UserIDs),
 		})
-			c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Error:   "Failed to get availability",
-				Message: "Unable to retrieve doctor availability. Please try again.",
-			})
-			return
-		}
-
-		c.JSON(http.StatusOK, AvailabilityResponse{
-			Success: true,
-			Message: "Doctor availability retrieved successfully",
-			Range:   availabilityRange,
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get appointments",
+			Message: "Unable to retrieve upcoming appointments. Please try again.",
 		})
 		return
 	}
 
-	// Single date request
-	if request.Date == "" {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Missing date parameter",
-			Message: "Please provide either 'date' or both 'start_date' and 'end_date'",
-		})
-		return
-	}
+	c.JSON(http.StatusOK, UpcomingBatchResponse{
+		Success:      true,
+		Message:      fmt.Sprintf("Upcoming appointments retrieved for %d patients", len(appointments)),
+		Appointments: appointments,
+	})
+}
 
-	// Parse single date
-	date, err := time.Parse("2006-01-02", request.Date)
+// ClassifyAppointment handles POST /api/v1/appointments/:id/classify
+// @Summary Re-run AI specialty classification
+// @Description Classify the symptom an appointment was booked with again and store the suggested specialty and confidence on the appointment. Admins only.
+// @Tags appointments
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Appointment ID"
+// @Success 200 {object} BookingResponse
+// @Success 202 {object} BookingResponse "The AI service will post the classification back later"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/appointments/{id}/classify [post]
+func (h *AppointmentHandler) ClassifyAppointment(c *gin.Context) {
+	appointmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid date format",
-			Message: "Please use YYYY-MM-DD format",
+			Error:   "Invalid appointment ID",
+			Message: "Appointment ID must be a valid number",
 		})
 		return
 	}
 
-	// Get availability for single date
-	availability, err := h.schedulingService.GetDoctorAvailability(request.DoctorID, date)
+	appointment, err := h.schedulingService.ClassifyAppointment(uint(appointmentID))
 	if err != nil {
-		utils.LogError(err, "Failed to get doctor availability", map[string]interface{}{
-			"doctor_id": request.DoctorID,
-			"date":      date,
-		})
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "Failed to get availability",
-			Message: "Unable to retrieve doctor availability. Please try again.",
-		})
+		switch {
+		case errors.Is(err, utils.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid request",
+				Message: err.Error(),
+			})
+		case errors.Is(err, services.ErrClassificationPending):
+			c.JSON(http.StatusAccepted, BookingResponse{
+				Success: true,
+				Message: "Classification requested; the result will be stored when the AI service responds",
+			})
+		case errors.Is(err, services.ErrClassifierUnavailable):
+			c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+				Error:   "Classification unavailable",
+				Message: "The AI classification service is unavailable. Please try again later.",
+			})
+		case strings.Contains(err.Error(), "not found"):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Appointment not found",
+				Message: "The requested appointment does not exist",
+			})
+		default:
+			utils.LogErrorContext(c.Request.Context(), err, "Failed to classify appointment", map[string]interface{}{
+				"appointment_id": appointmentID,
+			})
+			respondServerError(c, err, ErrorResponse{
+				Error:   "Failed to classify appointment",
+				Message: "Unable to classify the appointment. Please try again.",
+			})
+		}
 		return
 	}
 
-	c.JSON(http.StatusOK, AvailabilityResponse{
-		Success:      true,
-		Message:      "Doctor availability retrieved successfully",
-		Availability: availability,
+	c.JSON(http.StatusOK, BookingResponse{
+		Success:     true,
+		Message:     "Appointment classified successfully",
+		Appointment: appointment,
 	})
 }
 
-// GetPatientAppointments handles GET /api/appointments/patient
-// @Summary Get patient's appointments
-// @Description Get all appointments for the authenticated patient
+// GetMyAppointmentsICS handles GET /api/v1/appointments/my.ics
+// @Summary Download the patient's upcoming appointments as iCalendar
+// @Description Export every upcoming appointment of the authenticated patient, across all doctors, as one calendar file with an event per appointment
 // @Tags appointments
-// @Accept json
-// @Produce json
+// @Produce text/calendar
 // @Param Authorization header string true "Bearer token"
-// @Param status query string false "Filter by status (scheduled, confirmed, cancelled, completed)"
-// @Success 200 {object} AppointmentsResponse
+// @Success 200 {file} file
 // @Failure 401 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
-// @Router /api/appointments/patient [get]
-func (h *AppointmentHandler) GetPatientAppointments(c *gin.Context) {
-	// Get user ID from JWT token
+// @Router /api/v1/appointments/my.ics [get]
+func (h *AppointmentHandler) GetMyAppointmentsICS(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, ErrorResponse{
@@ -492,71 +1992,86 @@ func (h *AppointmentHandler) GetPatientAppointments(c *gin.Context) {
 		return
 	}
 
-	// Get optional status filter
-	status := c.Query("status")
-
-	// Get patient appointments
-	appointments, err := h.schedulingService.GetPatientAppointments(userID.(uint), status)
-	if err != nil {
-		utils.LogError(err, "Failed to get patient appointments", map[string]interface{}{
-			"user_id": userID,
-			"status":  status,
-		})
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
+	appointments, err := h.schedulingService.GetUpcomingAppointments(userID.(uint), 0)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		utils.LogErrorContext(c.Request.Context(), err, "Failed to get upcoming appointments for ICS export", nil)
+		respondServerError(c, err, ErrorResponse{
 			Error:   "Failed to get appointments",
-			Message: "Unable to retrieve appointments. Please try again.",
+			Message: "Unable to retrieve upcoming appointments. Please try again.",
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, AppointmentsResponse{
-		Success:      true,
-		Message:      "Appointments retrieved successfully",
-		Appointments: appointments,
-		Total:        len(appointments),
-	})
+	calendar := utils.NewICSCalendar("My appointments")
+	for _, appointment := range appointments {
+		summary := "Appointment"
+		if appointment.Doctor.Name != "" {
+			summary = "Appointment with " + appointment.Doctor.Name
+		}
+
+		description := fmt.Sprintf("Type: %s", appointment.Type)
+		if appointment.Doctor.Specialty.Name != "" {
+			description += fmt.Sprintf("\nSpecialty: %s", appointment.Doctor.Specialty.Name)
+		}
+
+		calendar.AddEvent(utils.ICSEvent{
+			UID:         fmt.Sprintf("appointment-%d@smart-doctor-booking", appointment.ID),
+			Summary:     summary,
+			Description: description,
+			Start:       appointment.AppointmentTime,
+			End:         appointment.EndTime,
+		})
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=\"my-appointments.ics\"")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(calendar.String()))
 }
 
-// GetUpcomingAppointments handles GET /api/appointments/upcoming
-// @Summary Get patient's upcoming appointments
-// @Description Get upcoming appointments for the authenticated patient
-// @Tags appointments
-// @Accept json
+// AttendanceStatsResponse represents a patient's no-show history
+type AttendanceStatsResponse struct {
+	Success bool                    `json:"success"`
+	Message string                  `json:"message"`
+	Stats   *models.AttendanceStats `json:"stats"`
+}
+
+// GetNoShowStats handles GET /api/v1/users/:id/no-show-stats
+// @Summary Get a patient's no-show history
+// @Description Get counts of completed, no-show and late-cancelled appointments with a reliability percentage
+// @Tags users
 // @Produce json
 // @Param Authorization header string true "Bearer token"
-// @Success 200 {object} AppointmentsResponse
-// @Failure 401 {object} ErrorResponse
+// @Param id path int true "User ID"
+// @Success 200 {object} AttendanceStatsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
-// @Router /api/appointments/upcoming [get]
-func (h *AppointmentHandler) GetUpcomingAppointments(c *gin.Context) {
-	// Get user ID from JWT token
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{
-			Error:   "Unauthorized",
-			Message: "User authentication required",
+// @Router /api/v1/users/{id}/no-show-stats [get]
+func (h *AppointmentHandler) GetNoShowStats(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || userID == 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid user ID",
+			Message: "User ID must be a valid number",
 		})
 		return
 	}
 
-	// Get upcoming appointments
-	appointments, err := h.schedulingService.GetUpcomingAppointments(userID.(uint))
+	stats, err := h.schedulingService.GetAttendanceStats(uint(userID))
 	if err != nil {
-		utils.LogError(err, "Failed to get upcoming appointments", map[string]interface{}{
-			"user_id": userID,
+		utils.LogErrorContext(c.Request.Context(), err, "Failed to get attendance stats", map[string]interface{}{
+			"patient_id": userID,
 		})
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "Failed to get appointments",
-			Message: "Unable to retrieve upcoming appointments. Please try again.",
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get stats",
+			Message: "Unable to retrieve no-show history. Please try again.",
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, AppointmentsResponse{
-		Success:      true,
-		Message:      "Upcoming appointments retrieved successfully",
-		Appointments: appointments,
-		Total:        len(appointments),
+	c.JSON(http.StatusOK, AttendanceStatsResponse{
+		Success: true,
+		Message: "No-show history retrieved successfully",
+		Stats:   stats,
 	})
 }
 
@@ -568,6 +2083,7 @@ func (h *AppointmentHandler) GetUpcomingAppointments(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Doctor ID"
 // @Param date query string true "Date (YYYY-MM-DD)"
+// @Param tag query string false "Only appointments carrying this tag"
 // @Success 200 {object} AppointmentsResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -607,16 +2123,17 @@ func (h *AppointmentHandler) GetDoctorAppointments(c *gin.Context) {
 	// Get doctor appointments
 	appointments, err := h.schedulingService.GetDoctorAppointments(uint(doctorID), date)
 	if err != nil {
-		utils.LogError(err, "Failed to get doctor appointments", map[string]interface{}{
+		utils.LogErrorContext(c.Request.Context(), err, "Failed to get doctor appointments", map[string]interface{}{
 			"doctor_id": doctorID,
 			"date":      date,
 		})
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
+		respondServerError(c, err, ErrorResponse{
 			Error:   "Failed to get appointments",
 			Message: "Unable to retrieve doctor appointments. Please try again.",
 		})
 		return
 	}
+	appointments = models.FilterAppointmentsByTag(appointments, c.Query("tag"))
 
 	c.JSON(http.StatusOK, AppointmentsResponse{
 		Success:      true,
@@ -663,32 +2180,32 @@ func (h *AppointmentHandler) CheckTimeSlotAvailability(c *gin.Context) {
 		return
 	}
 
-	startTime, err := time.Parse("2006-01-02T15:04:05Z07:00", startTimeStr)
+	startTime, err := utils.ParseAppointmentTime(startTimeStr)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:   "Invalid start time format",
-			Message: "Please use ISO 8601 format (YYYY-MM-DDTHH:MM:SSZ)",
+			Message: err.Error(),
 		})
 		return
 	}
 
-	endTime, err := time.Parse("2006-01-02T15:04:05Z07:00", endTimeStr)
+	endTime, err := utils.ParseAppointmentTime(endTimeStr)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:   "Invalid end time format",
-			Message: "Please use ISO 8601 format (YYYY-MM-DDTHH:MM:SSZ)",
+			Message: err.Error(),
 		})
 		return
 	}
 
 	available, err := h.schedulingService.CheckTimeSlotAvailability(uint(doctorID), startTime, endTime)
 	if err != nil {
-		utils.LogError(err, "Failed to check time slot availability", map[string]interface{}{
+		utils.LogErrorContext(c.Request.Context(), err, "Failed to check time slot availability", map[string]interface{}{
 			"doctor_id":  doctorID,
 			"start_time": startTime,
 			"end_time":   endTime,
 		})
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
+		respondServerError(c, err, ErrorResponse{
 			Error:   "Failed to check availability",
 			Message: "Unable to check time slot availability. Please try again.",
 		})
@@ -709,3 +2226,121 @@ func (h *AppointmentHandler) CheckTimeSlotAvailability(c *gin.Context) {
 		"end_time":   endTime,
 	})
 }
+
+// BestSlotRequest represents the constraints for finding the best appointment time
+type BestSlotRequest struct {
+	DoctorID       uint     `json:"doctor_id" binding:"required,min=1"`
+	Duration       int      `json:"duration" binding:"required,min=15,max=180"`
+	Earliest       string   `json:"earliest" binding:"required"`                // ISO 8601 with a timezone
+	Latest         string   `json:"latest" binding:"required"`                  // ISO 8601 with a timezone
+	PreferredTimes []string `json:"preferred_times" binding:"omitempty,max=10"` // Times of day as HH:MM in earliest's timezone
+}
+
+// BestSlotResponse represents the best-fitting appointment time found for a patient's constraints
+type BestSlotResponse struct {
+	Success  bool               `json:"success"`
+	Message  string             `json:"message"`
+	DoctorID uint               `json:"doctor_id"`
+	Slot     *models.SlotChoice `json:"slot"`
+}
+
+// FindBestSlot handles POST /api/v1/appointments/best-slot
+// @Summary Find the best appointment time for a patient's constraints
+// @Description Return the single open time with the doctor that lasts duration minutes, starts no earlier than earliest, ends no later than latest, and is as close as possible to one of the preferred times of day. Ties go to the earliest time.
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body BestSlotRequest true "Constraints"
+// @Success 200 {object} BestSlotResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/appointments/best-slot [post]
+func (h *AppointmentHandler) FindBestSlot(c *gin.Context) {
+	var request BestSlotRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	earliest, err := utils.ParseAppointmentTime(request.Earliest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid earliest time",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	latest, err := utils.ParseAppointmentTime(request.Latest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid latest time",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	preferred := make([]time.Duration, 0, len(request.PreferredTimes))
+	for _, value := range request.PreferredTimes {
+		clock, err := time.Parse("15:04", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid preferred time",
+				Message: fmt.Sprintf("preferred time %q must be in HH:MM format", value),
+			})
+			return
+		}
+		preferred = append(preferred, time.Duration(clock.Hour())*time.Hour+time.Duration(clock.Minute())*time.Minute)
+	}
+
+	slot, err := h.schedulingService.FindBestSlot(&services.BestSlotRequest{
+		DoctorID:       request.DoctorID,
+		Duration:       request.Duration,
+		Earliest:       earliest,
+		Latest:         latest,
+		PreferredTimes: preferred,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid request",
+				Message: err.Error(),
+			})
+		case errors.Is(err, services.ErrNoSlotFits):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "No slot found",
+				Message: "No available time fits these constraints. Try widening the time range.",
+			})
+		case errors.Is(err, models.ErrDoctorNotAcceptingBookings):
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "Bookings paused",
+				Message: "This doctor is not accepting new bookings at the moment. Please try again later or choose another doctor.",
+			})
+		default:
+			utils.LogErrorContext(c.Request.Context(), err, "Failed to find best slot", map[string]interface{}{
+				"doctor_id": request.DoctorID,
+				"earliest":  earliest,
+				"latest":    latest,
+			})
+			respondServerError(c, err, ErrorResponse{
+				Error:   "Failed to find slot",
+				Message: "Unable to search for an appointment time. Please try again.",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, BestSlotResponse{
+		Success:  true,
+		Message:  "Best slot found",
+		DoctorID: request.DoctorID,
+		Slot:     slot,
+	})
+}
@@ -0,0 +1,499 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/repository/repotest"
+	"smart-doctor-booking-app/services"
+)
+
+// newTestSchedulingService returns a scheduling service over repositories backed by db, without a cache
+func newTestSchedulingService(db *gorm.DB) services.SchedulingService {
+	return services.NewSchedulingServiceWithConfig(
+		repository.NewAppointmentRepository(db),
+		repository.NewTimeSlotRepository(db),
+		services.NewNotificationService(),
+		nil,
+		services.DefaultSchedulingConfig(),
+	)
+}
+
+func TestStreamDoctorAvailabilityMatchesBufferedRange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := repotest.Open(t)
+	repotest.MustCreate(t, db,
+		&models.Specialty{ID: 1, Name: "General Practice"},
+		&models.Doctor{ID: 1, Name: "Doctor", SpecialtyID: 1, IsActive: true},
+	)
+	for offset := 0; offset < 3; offset++ {
+		day := repotest.Day(offset)
+		repotest.MustCreate(t, db,
+			repotest.Slot(1, day, 9, 0, 30, models.SlotAvailable),
+			repotest.Slot(1, day, 9, 30, 30, models.SlotBooked),
+			repotest.Slot(1, day, 14, 0, 30, models.SlotAvailable),
+		)
+	}
+	handler := NewAppointmentHandler(newTestSchedulingService(db))
+
+	router := gin.New()
+	router.GET("/availability", handler.GetDoctorAvailability)
+	router.GET("/availability/stream", handler.StreamDoctorAvailability)
+	// A real server, since streaming needs a connection that can report the client going away
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	get := func(path string) AvailabilityResponse {
+		t.Helper()
+		resp, err := http.Get(server.URL + path + "?doctor_id=1&date=2031-03-03&start_date=2031-03-03&end_date=2031-03-05")
+		if err != nil {
+			t.Fatalf("GET %s returned error: %v", path, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200 from %s, got %d", path, resp.StatusCode)
+		}
+		var response AvailabilityResponse
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode %s response: %v", path, err)
+		}
+		return response
+	}
+
+	buffered := get("/availability")
+	streamed := get("/availability/stream")
+
+	if len(streamed.Range) != 3 {
+		t.Fatalf("expected 3 streamed days, got %d", len(streamed.Range))
+	}
+	if !streamed.Success || streamed.Message != buffered.Message {
+		t.Errorf("expected a successful stream with message %q, got success=%v %q", buffered.Message, streamed.Success, streamed.Message)
+	}
+	if !reflect.DeepEqual(streamed.Range, buffered.Range) {
+		t.Errorf("expected the streamed range to match the buffered one\nstreamed: %+v\nbuffered: %+v", streamed.Range, buffered.Range)
+	}
+}
+
+func TestGetAppointmentRemindersShowsDefaultReminderPending(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := repotest.Open(t)
+	day := repotest.Day(0)
+	repotest.MustCreate(t, db,
+		&models.Specialty{ID: 1, Name: "General Practice"},
+		&models.Doctor{ID: 1, Name: "Doctor", SpecialtyID: 1, IsActive: true},
+		repotest.Slot(1, day, 9, 0, 30, models.SlotAvailable),
+	)
+	service := newTestSchedulingService(db)
+	appointment, err := service.BookAppointment(&services.BookingRequest{
+		UserID: 5, DoctorID: 1, AppointmentTime: day.Add(9 * time.Hour), Duration: 30, AppointmentType: models.TypeConsultation,
+	})
+	if err != nil {
+		t.Fatalf("BookAppointment returned error: %v", err)
+	}
+	handler := NewAppointmentHandler(service)
+
+	get := func(caller gin.HandlerFunc) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET("/appointments/:id/reminders", caller, handler.GetAppointmentReminders)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/appointments/%d/reminders", appointment.ID), nil))
+		return w
+	}
+	asDoctor := func(doctorID uint) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set("doctor_id", doctorID)
+			withUser(20+doctorID, "doctor")(c)
+		}
+	}
+
+	w := get(withUser(5, "user"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for the patient, got %d: %s", w.Code, w.Body.String())
+	}
+	var body AppointmentRemindersResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(body.Reminders) != 1 {
+		t.Fatalf("expected a single reminder, got %d", len(body.Reminders))
+	}
+	reminder := body.Reminders[0]
+	config := services.DefaultSchedulingConfig()
+	if reminder.Status != models.ReminderPending || reminder.Channel != config.DefaultReminderType ||
+		reminder.OffsetMinutes != config.DefaultReminderTime || reminder.SentAt != nil {
+		t.Errorf("expected the default %s reminder %d minutes ahead pending, got %+v",
+			config.DefaultReminderType, config.DefaultReminderTime, reminder)
+	}
+	if want := day.Add(9*time.Hour - time.Duration(config.DefaultReminderTime)*time.Minute); !reminder.ScheduledFor.Equal(want) {
+		t.Errorf("expected the reminder scheduled for %v, got %v", want, reminder.ScheduledFor)
+	}
+
+	if w := get(asDoctor(1)); w.Code != http.StatusOK {
+		t.Errorf("expected 200 for the assigned doctor, got %d", w.Code)
+	}
+	if w := get(withUser(6, "user")); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for another patient, got %d", w.Code)
+	}
+	if w := get(asDoctor(2)); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a doctor not assigned to the appointment, got %d", w.Code)
+	}
+}
+
+func TestGetDoctorAvailabilityFiltersToMorning(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := repotest.Open(t)
+	day := repotest.Day(0)
+	repotest.MustCreate(t, db,
+		&models.Specialty{ID: 1, Name: "General Practice"},
+		&models.Doctor{ID: 1, Name: "Doctor", SpecialtyID: 1, IsActive: true},
+		repotest.Slot(1, day, 8, 0, 30, models.SlotAvailable),
+		repotest.Slot(1, day, 11, 30, 30, models.SlotAvailable),
+		repotest.Slot(1, day, 12, 0, 30, models.SlotAvailable),
+		repotest.Slot(1, day, 15, 0, 30, models.SlotAvailable),
+		repotest.Slot(1, day, 18, 0, 30, models.SlotAvailable),
+	)
+	handler := NewAppointmentHandler(newTestSchedulingService(db))
+
+	router := gin.New()
+	router.GET("/availability", handler.GetDoctorAvailability)
+	get := func(timeOfDay string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/availability?doctor_id=1&date=2031-03-03&time_of_day="+timeOfDay, nil))
+		return w
+	}
+
+	w := get("morning")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body AvailabilityResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.Availability == nil || len(body.Availability.AvailableSlots) != 2 {
+		t.Fatalf("expected the 8:00 and 11:30 slots, got %+v", body.Availability)
+	}
+	for _, slot := range body.Availability.AvailableSlots {
+		if slot.StartTime.Hour() >= 12 {
+			t.Errorf("expected only morning slots, got one at %v", slot.StartTime)
+		}
+	}
+
+	if w := get("night"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected an unknown part of the day to be rejected with 400, got %d", w.Code)
+	}
+}
+
+func TestGetMyAppointmentsICSHasEventPerUpcomingAppointment(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := repotest.Open(t)
+	repotest.MustCreate(t, db,
+		&models.Specialty{ID: 1, Name: "General Practice"},
+		&models.Doctor{ID: 1, Name: "Dr. One", SpecialtyID: 1, IsActive: true},
+		&models.Doctor{ID: 2, Name: "Dr. Two", SpecialtyID: 1, IsActive: true},
+	)
+	first := repotest.Appointment(1, 1, repotest.Day(0).Add(9*time.Hour), 30, models.StatusScheduled)
+	second := repotest.Appointment(1, 2, repotest.Day(1).Add(14*time.Hour), 30, models.StatusScheduled)
+	repotest.MustCreate(t, db,
+		first,
+		second,
+		repotest.Appointment(1, 1, repotest.Day(2).Add(9*time.Hour), 30, models.StatusCancelled),
+		repotest.Appointment(2, 1, repotest.Day(0).Add(10*time.Hour), 30, models.StatusScheduled),
+	)
+	handler := NewAppointmentHandler(newTestSchedulingService(db))
+
+	router := gin.New()
+	router.GET("/appointments/my.ics", withUser(1, "user"), handler.GetMyAppointmentsICS)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/appointments/my.ics", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/calendar") {
+		t.Errorf("expected a text/calendar response, got %q", got)
+	}
+
+	body := w.Body.String()
+	if got := strings.Count(body, "BEGIN:VEVENT"); got != 2 {
+		t.Fatalf("expected 2 events, got %d:\n%s", got, body)
+	}
+	for _, want := range []string{
+		fmt.Sprintf("UID:appointment-%d@smart-doctor-booking", first.ID),
+		fmt.Sprintf("UID:appointment-%d@smart-doctor-booking", second.ID),
+		"SUMMARY:Appointment with Dr. One",
+		"SUMMARY:Appointment with Dr. Two",
+		"DTSTART:20310303T090000Z",
+		"DTSTART:20310304T140000Z",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected the calendar to contain %q", want)
+		}
+	}
+}
+
+func TestAppointmentTagsSetAtBookingAndFilterList(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := repotest.Open(t)
+	day := repotest.Day(0)
+	repotest.MustCreate(t, db,
+		&models.Specialty{ID: 1, Name: "General Practice"},
+		&models.Doctor{ID: 1, Name: "Doctor", SpecialtyID: 1, IsActive: true},
+		repotest.Slot(1, day, 9, 0, 30, models.SlotAvailable),
+		repotest.Slot(1, day, 10, 0, 30, models.SlotAvailable),
+	)
+	service := newTestSchedulingService(db)
+	first, err := service.BookAppointment(&services.BookingRequest{
+		UserID: 5, DoctorID: 1, AppointmentTime: day.Add(9 * time.Hour), Duration: 30, AppointmentType: models.TypeConsultation,
+		Tags: []string{"First-Visit", " insurance-pending", "first-visit"},
+	})
+	if err != nil {
+		t.Fatalf("BookAppointment returned error: %v", err)
+	}
+	if !reflect.DeepEqual(first.Tags, []string{"first-visit", "insurance-pending"}) {
+		t.Errorf("expected the tags normalized and deduplicated, got %v", first.Tags)
+	}
+	second, err := service.BookAppointment(&services.BookingRequest{
+		UserID: 5, DoctorID: 1, AppointmentTime: day.Add(10 * time.Hour), Duration: 30, AppointmentType: models.TypeConsultation,
+	})
+	if err != nil {
+		t.Fatalf("BookAppointment returned error: %v", err)
+	}
+
+	handler := NewAppointmentHandler(service)
+	router := gin.New()
+	router.GET("/appointments/my", withUser(5, "user"), handler.GetPatientAppointments)
+
+	patch := func(caller gin.HandlerFunc, id uint, body string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.PATCH("/appointments/:id/tags", caller, handler.SetAppointmentTags)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/appointments/%d/tags", id), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+	if w := patch(withDoctor(21, 1), second.ID, `{"tags": ["Insurance-Pending"]}`); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := patch(withDoctor(21, 1), second.ID, `{"tags": ["needs review!"]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("expected an invalid tag to be rejected with 400, got %d", w.Code)
+	}
+	if w := patch(withDoctor(22, 2), first.ID, `{"tags": []}`); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a doctor not assigned to the appointment, got %d", w.Code)
+	}
+	if w := patch(withDoctor(21, 1), 999, `{"tags": []}`); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a missing appointment, got %d", w.Code)
+	}
+
+	list := func(tag string) []uint {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/appointments/my?tag="+tag, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var body AppointmentsResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		ids := make([]uint, len(body.Appointments))
+		for i, appointment := range body.Appointments {
+			ids[i] = appointment.ID
+		}
+		return ids
+	}
+	tests := []struct {
+		tag  string
+		want int
+	}{
+		{"insurance-pending", 2},
+		{"first-visit", 1},
+		{"follow-up", 0},
+		{"", 2},
+	}
+	for _, tt := range tests {
+		if got := list(tt.tag); len(got) != tt.want {
+			t.Errorf("tag %q: expected %d appointments, got %v", tt.tag, tt.want, got)
+		}
+	}
+	if got := list("first-visit"); len(got) == 1 && got[0] != first.ID {
+		t.Errorf("expected the first-visit appointment %d, got %d", first.ID, got[0])
+	}
+}
+
+func TestGetDoctorAvailabilityExcludesCallersClashingSlots(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := repotest.Open(t)
+	day := repotest.Day(0)
+	repotest.MustCreate(t, db,
+		&models.Specialty{ID: 1, Name: "General Practice"},
+		&models.Doctor{ID: 1, Name: "Doctor", SpecialtyID: 1, IsActive: true},
+		&models.Doctor{ID: 2, Name: "Dentist", SpecialtyID: 1, IsActive: true},
+		repotest.Slot(1, day, 9, 0, 30, models.SlotAvailable),
+		repotest.Slot(1, day, 9, 30, 30, models.SlotAvailable),
+		repotest.Slot(1, day, 10, 0, 30, models.SlotAvailable),
+		// The patient already sees another doctor from 9:15 to 9:45, and had a 10:00 visit cancelled
+		repotest.Appointment(5, 2, day.Add(9*time.Hour+15*time.Minute), 30, models.StatusScheduled),
+		repotest.Appointment(5, 2, day.Add(10*time.Hour), 30, models.StatusCancelled),
+	)
+	handler := NewAppointmentHandler(newTestSchedulingService(db))
+
+	router := gin.New()
+	router.GET("/availability", withUser(5, "user"), handler.GetDoctorAvailability)
+	get := func(query string) []time.Time {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/availability?doctor_id=1&date=2031-03-03"+query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var body AvailabilityResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		var starts []time.Time
+		for _, slot := range body.Availability.AvailableSlots {
+			starts = append(starts, slot.StartTime)
+		}
+		return starts
+	}
+
+	if got := get(""); len(got) != 3 {
+		t.Errorf("expected every slot without the filter, got %v", got)
+	}
+	got := get("&exclude_my_conflicts=true")
+	if len(got) != 1 || !got[0].Equal(day.Add(10*time.Hour)) {
+		t.Errorf("expected only the 10:00 slot clear of the patient's appointment, got %v", got)
+	}
+}
+
+func TestChangeAppointmentTypeOnlyByAssignedDoctorOrAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := repotest.Open(t)
+	day := repotest.Day(0)
+	repotest.MustCreate(t, db,
+		&models.Specialty{ID: 1, Name: "General Practice"},
+		&models.Doctor{ID: 1, Name: "Doctor", SpecialtyID: 1, IsActive: true},
+		&models.Doctor{ID: 2, Name: "Other Doctor", SpecialtyID: 1, IsActive: true},
+		repotest.Slot(1, day, 9, 0, 30, models.SlotAvailable),
+	)
+	service := newTestSchedulingService(db)
+	appointment, err := service.BookAppointment(&services.BookingRequest{
+		UserID: 5, DoctorID: 1, AppointmentTime: day.Add(9 * time.Hour), Duration: 30, AppointmentType: models.TypeConsultation,
+	})
+	if err != nil {
+		t.Fatalf("BookAppointment returned error: %v", err)
+	}
+	handler := NewAppointmentHandler(service)
+
+	change := func(caller gin.HandlerFunc, appointmentType models.AppointmentType) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.PATCH("/appointments/:id/type", caller, handler.ChangeAppointmentType)
+		w := httptest.NewRecorder()
+		body := fmt.Sprintf(`{"type": %q}`, appointmentType)
+		req := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/appointments/%d/type", appointment.ID), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+	storedType := func() models.AppointmentType {
+		var stored models.Appointment
+		if err := db.First(&stored, appointment.ID).Error; err != nil {
+			t.Fatalf("failed to load appointment: %v", err)
+		}
+		return stored.Type
+	}
+
+	if w := change(withDoctor(22, 2), models.TypeFollowUp); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a doctor not assigned to the appointment, got %d", w.Code)
+	}
+	if got := storedType(); got != models.TypeConsultation {
+		t.Errorf("expected the refused change to leave the type alone, got %s", got)
+	}
+
+	if w := change(withDoctor(21, 1), models.TypeFollowUp); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for the assigned doctor, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := storedType(); got != models.TypeFollowUp {
+		t.Errorf("expected the type changed to %s, got %s", models.TypeFollowUp, got)
+	}
+	if w := change(withUser(1, "admin"), models.TypeConsultation); w.Code != http.StatusOK {
+		t.Errorf("expected 200 for an admin, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCancelAndRescheduleRefuseCallersWithoutAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := repotest.Open(t)
+	day := repotest.Day(0)
+	repotest.MustCreate(t, db,
+		&models.Specialty{ID: 1, Name: "General Practice"},
+		&models.Doctor{ID: 1, Name: "Doctor", SpecialtyID: 1, IsActive: true},
+		&models.Doctor{ID: 2, Name: "Other Doctor", SpecialtyID: 1, IsActive: true},
+		repotest.Slot(1, day, 9, 0, 30, models.SlotAvailable),
+		repotest.Slot(1, day, 10, 0, 30, models.SlotAvailable),
+	)
+	service := newTestSchedulingService(db)
+	appointment, err := service.BookAppointment(&services.BookingRequest{
+		UserID: 5, DoctorID: 1, AppointmentTime: day.Add(9 * time.Hour), Duration: 30, AppointmentType: models.TypeConsultation,
+	})
+	if err != nil {
+		t.Fatalf("BookAppointment returned error: %v", err)
+	}
+	handler := NewAppointmentHandler(service)
+
+	send := func(caller gin.HandlerFunc, method, action, body string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.DELETE("/appointments/:id/cancel", caller, handler.CancelAppointment)
+		router.PUT("/appointments/:id/reschedule", caller, handler.RescheduleAppointment)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, fmt.Sprintf("/appointments/%d/%s", appointment.ID, action), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+	cancel := func(caller gin.HandlerFunc) *httptest.ResponseRecorder {
+		return send(caller, http.MethodDelete, "cancel", `{"reason_code": "PATIENT_REQUEST"}`)
+	}
+	reschedule := func(caller gin.HandlerFunc) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"new_appointment_time": %q, "duration": 30}`, day.Add(10*time.Hour).Format(time.RFC3339))
+		return send(caller, http.MethodPut, "reschedule", body)
+	}
+
+	tests := []struct {
+		name   string
+		caller gin.HandlerFunc
+	}{
+		{"another patient", withUser(6, "user")},
+		{"a doctor not assigned", withDoctor(22, 2)},
+	}
+	for _, tt := range tests {
+		if w := reschedule(tt.caller); w.Code != http.StatusForbidden {
+			t.Errorf("%s: expected reschedule to be refused with 403, got %d", tt.name, w.Code)
+		}
+		if w := cancel(tt.caller); w.Code != http.StatusForbidden {
+			t.Errorf("%s: expected cancel to be refused with 403, got %d", tt.name, w.Code)
+		}
+	}
+	var stored models.Appointment
+	if err := db.First(&stored, appointment.ID).Error; err != nil {
+		t.Fatalf("failed to load appointment: %v", err)
+	}
+	if stored.Status != models.StatusScheduled || !stored.AppointmentTime.Equal(day.Add(9*time.Hour)) {
+		t.Errorf("expected the refused calls to leave the appointment alone, got %s at %v", stored.Status, stored.AppointmentTime)
+	}
+
+	if w := cancel(withUser(5, "user")); w.Code != http.StatusOK {
+		t.Errorf("expected the owning patient to cancel, got %d: %s", w.Code, w.Body.String())
+	}
+}
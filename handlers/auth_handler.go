@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -9,6 +11,7 @@ import (
 	"golang.org/x/crypto/bcrypt"
 
 	"smart-doctor-booking-app/middleware"
+	"smart-doctor-booking-app/services"
 )
 
 // ErrorResponse represents an error response
@@ -20,13 +23,15 @@ type ErrorResponse struct {
 
 // AuthHandler handles authentication operations
 type AuthHandler struct {
-	validator *validator.Validate
+	validator  *validator.Validate
+	loginGuard *services.LoginGuard // Optional; nil disables failed-login lockout
 }
 
 // NewAuthHandler creates a new AuthHandler instance
-func NewAuthHandler() *AuthHandler {
+func NewAuthHandler(loginGuard *services.LoginGuard) *AuthHandler {
 	return &AuthHandler{
-		validator: validator.New(),
+		validator:  validator.New(),
+		loginGuard: loginGuard,
 	}
 }
 
@@ -71,10 +76,20 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	username := strings.TrimSpace(req.Username)
 	password := req.Password
 
+	// Refuse logins while the username is locked out from this IP
+	clientIP := c.ClientIP()
+	if h.loginGuard != nil {
+		if err := h.loginGuard.Check(c.Request.Context(), username, clientIP); err != nil {
+			h.respondLocked(c, err)
+			return
+		}
+	}
+
 	// For demo purposes, we'll use hardcoded credentials
 	// In production, this should query a user database
 	var userID uint
 	var role string
+	var doctorID uint
 	var hashedPassword string
 
 	// Demo users (in production, fetch from database)
@@ -87,6 +102,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	case "doctor":
 		userID = 2
 		role = "doctor"
+		doctorID = 1 // The demo doctor account acts for doctor 1
 		// Password: "doctor123" (bcrypt hash)
 		hashedPassword = "$2a$10$92IXUNpkjO0rOQ5byMi.Ye4oKoEa3Ro9llC/.og/at2.uheWG/igi"
 	case "user":
@@ -95,25 +111,23 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		// Password: "user123" (bcrypt hash)
 		hashedPassword = "$2a$10$92IXUNpkjO0rOQ5byMi.Ye4oKoEa3Ro9llC/.og/at2.uheWG/igi"
 	default:
-		c.JSON(http.StatusUnauthorized, ErrorResponse{
-			Error:   "Authentication Failed",
-			Message: "Invalid credentials",
-		})
+		h.respondLoginFailed(c, username, clientIP)
 		return
 	}
 
 	// Verify password
 	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{
-			Error:   "Authentication Failed",
-			Message: "Invalid credentials",
-		})
+		h.respondLoginFailed(c, username, clientIP)
 		return
 	}
 
+	if h.loginGuard != nil {
+		h.loginGuard.Reset(c.Request.Context(), username, clientIP)
+	}
+
 	// Generate JWT token
-	token, err := middleware.GenerateToken(userID, username, role)
+	token, err := middleware.GenerateToken(userID, username, role, doctorID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "Internal Server Error",
@@ -132,6 +146,36 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	})
 }
 
+// respondLoginFailed records a failed login and responds 401, or 429 if the failure locks the
+// username out
+func (h *AuthHandler) respondLoginFailed(c *gin.Context, username, clientIP string) {
+	if h.loginGuard != nil {
+		if err := h.loginGuard.RecordFailure(c.Request.Context(), username, clientIP); err != nil {
+			h.respondLocked(c, err)
+			return
+		}
+	}
+
+	c.JSON(http.StatusUnauthorized, ErrorResponse{
+		Error:   "Authentication Failed",
+		Message: "Invalid credentials",
+	})
+}
+
+// respondLocked responds 429 with a Retry-After header for a login lockout
+func (h *AuthHandler) respondLocked(c *gin.Context, err error) {
+	var lockedErr *services.LoginLockedError
+	if !errors.As(err, &lockedErr) {
+		return
+	}
+
+	c.Header("Retry-After", strconv.Itoa(int(lockedErr.RetryAfter.Seconds())+1))
+	c.JSON(http.StatusTooManyRequests, ErrorResponse{
+		Error:   "Too many failed logins",
+		Message: lockedErr.Error(),
+	})
+}
+
 // ValidateToken handles GET /auth/validate - validates JWT token
 func (h *AuthHandler) ValidateToken(c *gin.Context) {
 	// Get user info from context (set by auth middleware)
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"smart-doctor-booking-app/services"
+)
+
+func TestLoginLocksOutRepeatedFailuresAndResetsOnSuccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", "auth-handler-test-secret-0123456789")
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	guard := services.NewLoginGuard(
+		services.NewMemoryCacheService(services.CacheConfig{DefaultTTL: time.Hour}, logger),
+		services.LoginGuardConfig{MaxFailures: 2, BaseLockout: time.Minute, MaxLockout: time.Hour},
+	)
+
+	router := gin.New()
+	router.POST("/login", NewAuthHandler(guard).Login)
+	login := func(password, ip string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"username": "user", "password": "`+password+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = ip + ":40000"
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// A success after failures short of the limit starts the count over
+	for i := 0; i < 2; i++ {
+		if w := login("wrong-password", "10.0.0.1"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("failure %d: expected 401, got %d", i+1, w.Code)
+		}
+	}
+	if w := login("password", "10.0.0.1"); w.Code != http.StatusOK {
+		t.Fatalf("expected the correct password to log in, got %d: %s", w.Code, w.Body.String())
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := login("wrong-password", "10.0.0.1"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("failure %d after reset: expected 401, got %d", i+1, w.Code)
+		}
+	}
+	w := login("wrong-password", "10.0.0.1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected the failure past the limit to lock out with 429, got %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "61" {
+		t.Errorf("expected Retry-After 61, got %q", got)
+	}
+
+	// The lockout holds even for the right password, but only from the failing IP
+	if w := login("password", "10.0.0.1"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected a locked-out login to be refused, got %d", w.Code)
+	}
+	if w := login("password", "10.0.0.2"); w.Code != http.StatusOK {
+		t.Errorf("expected a login from another IP to succeed, got %d", w.Code)
+	}
+}
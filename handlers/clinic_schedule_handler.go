@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/services"
+	"smart-doctor-booking-app/utils"
+)
+
+// ClinicScheduleHandler handles clinic-wide schedule HTTP requests
+type ClinicScheduleHandler struct {
+	doctorRepo        repository.DoctorRepository
+	schedulingService services.SchedulingService
+}
+
+// NewClinicScheduleHandler creates a new clinic schedule handler
+func NewClinicScheduleHandler(doctorRepo repository.DoctorRepository, schedulingService services.SchedulingService) *ClinicScheduleHandler {
+	return &ClinicScheduleHandler{
+		doctorRepo:        doctorRepo,
+		schedulingService: schedulingService,
+	}
+}
+
+// ClinicDayResponse represents every active doctor's schedule on one date
+type ClinicDayResponse struct {
+	Success bool                       `json:"success"`
+	Message string                     `json:"message"`
+	Date    string                     `json:"date"`
+	Doctors []models.DoctorDaySchedule `json:"doctors"`
+}
+
+// GetClinicDay handles GET /api/v1/schedule/day
+// @Summary Get the clinic's schedule for a day
+// @Description Get every active doctor with their appointments and slot utilization on the date
+// @Tags schedule
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param date query string true "Date (YYYY-MM-DD)"
+// @Success 200 {object} ClinicDayResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/schedule/day [get]
+func (h *ClinicScheduleHandler) GetClinicDay(c *gin.Context) {
+	date, ok := parseRequiredDate(c, "date")
+	if !ok {
+		return
+	}
+
+	doctors, err := h.doctorRepo.GetAllDoctors()
+	if err != nil {
+		utils.LogError(err, "Failed to get doctors for clinic schedule", nil)
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get clinic schedule",
+			Message: "Unable to retrieve the clinic schedule. Please try again.",
+		})
+		return
+	}
+
+	active := make([]models.Doctor, 0, len(doctors))
+	for _, doctor := range doctors {
+		if doctor.IsActive {
+			active = append(active, doctor)
+		}
+	}
+
+	schedules, err := h.schedulingService.GetClinicDaySchedule(active, date)
+	if err != nil {
+		utils.LogError(err, "Failed to get clinic schedule", map[string]interface{}{
+			"date": date,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get clinic schedule",
+			Message: "Unable to retrieve the clinic schedule. Please try again.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ClinicDayResponse{
+		Success: true,
+		Message: fmt.Sprintf("Schedule retrieved for %d doctors", len(schedules)),
+		Date:    date.Format("2006-01-02"),
+		Doctors: schedules,
+	})
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/repository/repotest"
+)
+
+func TestGetClinicDayListsEachActiveDoctorsAppointments(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := repotest.Open(t)
+	day := repotest.Day(0)
+	repotest.MustCreate(t, db,
+		&models.Specialty{ID: 1, Name: "General Practice"},
+		&models.Doctor{ID: 1, Name: "Dr. One", SpecialtyID: 1, IsActive: true},
+		&models.Doctor{ID: 2, Name: "Dr. Two", SpecialtyID: 1, IsActive: true},
+		&models.Doctor{ID: 3, Name: "Dr. Away", SpecialtyID: 1, IsActive: true},
+		repotest.Appointment(1, 1, day.Add(10*time.Hour), 30, models.StatusConfirmed),
+		repotest.Appointment(2, 1, day.Add(9*time.Hour), 30, models.StatusScheduled),
+		repotest.Appointment(3, 1, day.Add(11*time.Hour), 30, models.StatusCancelled),
+		repotest.Appointment(4, 2, day.Add(14*time.Hour), 30, models.StatusScheduled),
+		// The next day's appointment is not part of this day's schedule
+		repotest.Appointment(5, 2, day.Add(33*time.Hour), 30, models.StatusScheduled),
+		repotest.Appointment(6, 3, day.Add(9*time.Hour), 30, models.StatusScheduled),
+		repotest.Slot(2, day, 15, 0, 30, models.SlotAvailable),
+	)
+	if err := db.Model(&models.Doctor{}).Where("id = ?", 3).Update("is_active", false).Error; err != nil {
+		t.Fatalf("failed to deactivate doctor: %v", err)
+	}
+
+	handler := NewClinicScheduleHandler(repository.NewDoctorRepository(db), newTestSchedulingService(db))
+	router := gin.New()
+	router.GET("/schedule/day", handler.GetClinicDay)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/schedule/day?date=2031-03-03", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var body ClinicDayResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(body.Doctors) != 2 {
+		t.Fatalf("expected the 2 active doctors, got %d", len(body.Doctors))
+	}
+
+	want := map[uint][]uint{1: {2, 1}, 2: {4}}
+	for _, schedule := range body.Doctors {
+		users, ok := want[schedule.Doctor.ID]
+		if !ok {
+			t.Errorf("unexpected doctor %d in the schedule", schedule.Doctor.ID)
+			continue
+		}
+		if len(schedule.Appointments) != len(users) {
+			t.Errorf("doctor %d: expected %d appointments, got %d", schedule.Doctor.ID, len(users), len(schedule.Appointments))
+			continue
+		}
+		for i, userID := range users {
+			if schedule.Appointments[i].UserID != userID {
+				t.Errorf("doctor %d: expected user %d's appointment at position %d, got user %d", schedule.Doctor.ID, userID, i, schedule.Appointments[i].UserID)
+			}
+		}
+		if schedule.Doctor.ID == 2 && schedule.AvailableSlots != 1 {
+			t.Errorf("expected doctor 2 to have 1 open slot, got %d", schedule.AvailableSlots)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/schedule/day", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected a missing date to be rejected with 400, got %d", w.Code)
+	}
+}
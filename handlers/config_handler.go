@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"smart-doctor-booking-app/services"
+)
+
+// publicConfigMaxAge is how long clients and proxies may cache the public configuration, in seconds
+const publicConfigMaxAge = "300"
+
+// ConfigHandler serves the public client configuration
+type ConfigHandler struct {
+	config services.PublicConfig
+}
+
+// NewConfigHandler creates a new config handler. The configuration is fixed at startup.
+func NewConfigHandler(config services.PublicConfig) *ConfigHandler {
+	return &ConfigHandler{
+		config: config,
+	}
+}
+
+// PublicConfigResponse represents the public client configuration
+type PublicConfigResponse struct {
+	Success bool                  `json:"success"`
+	Config  services.PublicConfig `json:"config"`
+}
+
+// GetPublicConfig handles GET /api/v1/config
+// @Summary Get client configuration
+// @Description Get the booking limits and options clients need to build forms: duration limits, appointment types, reminder channels and timing, and supported languages. Sensitive settings are never included.
+// @Tags config
+// @Produce json
+// @Success 200 {object} PublicConfigResponse
+// @Router /api/v1/config [get]
+func (h *ConfigHandler) GetPublicConfig(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age="+publicConfigMaxAge)
+	c.JSON(http.StatusOK, PublicConfigResponse{
+		Success: true,
+		Config:  h.config,
+	})
+}
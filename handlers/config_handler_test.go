@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/services"
+)
+
+func TestGetPublicConfigExposesLimitsButNoSecrets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const jwtSecret, dbPassword = "jwt-secret-do-not-leak-0123456789", "db-password-do-not-leak"
+	t.Setenv("JWT_SECRET", jwtSecret)
+	t.Setenv("DB_PASSWORD", dbPassword)
+	t.Setenv("DB_USER", "db-user-do-not-leak")
+
+	handler := NewConfigHandler(services.NewPublicConfig(services.DefaultSchedulingConfig(), services.NewLocalizer()))
+	router := gin.New()
+	router.GET("/config", handler.GetPublicConfig)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/config", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Cache-Control"); got != "public, max-age=300" {
+		t.Errorf("expected the response to be cacheable, got Cache-Control %q", got)
+	}
+
+	body := w.Body.String()
+	for _, secret := range []string{jwtSecret, dbPassword, "db-user-do-not-leak", "secret", "password", "dsn"} {
+		if strings.Contains(strings.ToLower(body), secret) {
+			t.Errorf("expected the public config not to mention %q, got %s", secret, body)
+		}
+	}
+
+	var response PublicConfigResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	config := response.Config
+	if config.Duration.Min != services.MinAppointmentDuration || config.Duration.Max != services.MaxAppointmentDuration {
+		t.Errorf("expected duration limits %d-%d, got %+v", services.MinAppointmentDuration, services.MaxAppointmentDuration, config.Duration)
+	}
+	if config.ReminderTime.Min != services.MinReminderTime || config.ReminderTime.Max != services.MaxReminderTime {
+		t.Errorf("expected reminder time limits, got %+v", config.ReminderTime)
+	}
+	if len(config.AppointmentTypes) != 4 || config.AppointmentTypes[models.TypeFollowUp].Max == 0 {
+		t.Errorf("expected limits for each appointment type, got %+v", config.AppointmentTypes)
+	}
+	if len(config.ReminderChannels) == 0 || len(config.Languages) < 2 {
+		t.Errorf("expected reminder channels and languages, got %v and %v", config.ReminderChannels, config.Languages)
+	}
+}
@@ -73,9 +73,10 @@ func (h *DoctorHandler) CreateDoctor(c *gin.Context) {
 
 	// Create doctor model
 	doctor := &models.Doctor{
-		Name:        sanitizedName,
-		SpecialtyID: req.SpecialtyID,
-		IsActive:    true,
+		Name:              sanitizedName,
+		SpecialtyID:       req.SpecialtyID,
+		IsActive:          true,
+		AcceptingBookings: true,
 	}
 
 	// Save doctor using repository
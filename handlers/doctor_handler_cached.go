@@ -8,13 +8,20 @@
 //    - Key pattern: "doctor:{id}" (e.g., "doctor:123")
 //    - Used for single doctor lookups
 //    - Invalidated only when that specific doctor is modified or deleted
+//    - "doctor:{id}:summary" holds the profile aggregates for five minutes
 //
 // 2. SPECIALTY LIST CACHING:
 //    - Key pattern: "doctors:specialty:{id}" (e.g., "doctors:specialty:5")
 //    - Contains lists of doctors filtered by specialty
 //    - Invalidated when doctors in that specialty are created, updated, or deleted
+//    - "doctors:specialty:{id}:earliest" holds the list sorted by earliest availability for one minute
 //
-// 3. GENERAL LIST CACHING:
+// 3. BOOKABLE SPECIALTIES CACHING:
+//    - Key: "specialties:bookable"
+//    - Specialties with at least one active doctor, with the count
+//    - Invalidated alongside the specialty lists whenever a doctor changes
+//
+// 4. GENERAL LIST CACHING:
 //    - Key pattern: "doctors:all"
 //    - Contains the complete list of doctors
 //    - Invalidated when any doctor is created, updated, or deleted
@@ -23,6 +30,7 @@
 // - CreateDoctor: Immediately caches new doctor + invalidates specialty/general lists
 // - UpdateDoctor: Invalidates specific doctor + old/new specialty lists + re-caches doctor
 // - DeleteDoctor: Invalidates specific doctor + specialty/general lists
+// - BulkUpdateDoctorStatus: Invalidates each doctor + each affected specialty list once
 // - GetDoctor: Uses individual doctor cache with fallback to database + cache population
 //
 // This approach prevents over-invalidation (e.g., removing hundreds of valid doctor records
@@ -35,6 +43,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -101,15 +110,16 @@ func (h *CachedDoctorHandler) CreateDoctor(c *gin.Context) {
 
 	// Create doctor model
 	doctor := &models.Doctor{
-		Name:        req.Name,
-		SpecialtyID: req.SpecialtyID,
-		IsActive:    true,
+		Name:              req.Name,
+		SpecialtyID:       req.SpecialtyID,
+		IsActive:          true,
+		AcceptingBookings: true,
 	}
 
 	// Create doctor in database
 	if err := h.doctorRepo.CreateDoctor(doctor); err != nil {
 		h.logger.Error("Failed to create doctor", "error", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
+		respondServerError(c, err, ErrorResponse{
 			Error:   "Database error",
 			Message: "Failed to create doctor",
 		})
@@ -174,6 +184,9 @@ func (h *CachedDoctorHandler) UpdateDoctor(c *gin.Context) {
 	existingDoctor, err := h.doctorRepo.GetDoctorByID(doctorID)
 	if err != nil {
 		h.logger.Error("Failed to retrieve existing doctor", "doctorID", doctorID, "error", err)
+		if respondIfUnavailable(c, err) {
+			return
+		}
 		c.JSON(http.StatusNotFound, ErrorResponse{
 			Error:   "Doctor not found",
 			Message: "The requested doctor does not exist",
@@ -184,18 +197,19 @@ func (h *CachedDoctorHandler) UpdateDoctor(c *gin.Context) {
 	// Sanitize input
 	req.Name = utils.SanitizeString(req.Name)
 
-	// Update doctor model
+	// Update doctor model; pausing bookings has its own endpoint, so keep the current setting
 	updatedDoctor := &models.Doctor{
-		ID:          doctorID,
-		Name:        req.Name,
-		SpecialtyID: req.SpecialtyID,
-		IsActive:    *req.IsActive,
+		ID:                doctorID,
+		Name:              req.Name,
+		SpecialtyID:       req.SpecialtyID,
+		IsActive:          *req.IsActive,
+		AcceptingBookings: existingDoctor.AcceptingBookings,
 	}
 
 	// Update doctor in database
 	if err := h.doctorRepo.UpdateDoctor(updatedDoctor); err != nil {
 		h.logger.Error("Failed to update doctor", "doctorID", doctorID, "error", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
+		respondServerError(c, err, ErrorResponse{
 			Error:   "Database error",
 			Message: "Failed to update doctor",
 		})
@@ -244,6 +258,9 @@ func (h *CachedDoctorHandler) DeleteDoctor(c *gin.Context) {
 	existingDoctor, err := h.doctorRepo.GetDoctorByID(doctorID)
 	if err != nil {
 		h.logger.Error("Failed to retrieve doctor for deletion", "doctorID", doctorID, "error", err)
+		if respondIfUnavailable(c, err) {
+			return
+		}
 		c.JSON(http.StatusNotFound, ErrorResponse{
 			Error:   "Doctor not found",
 			Message: "The requested doctor does not exist",
@@ -254,7 +271,7 @@ func (h *CachedDoctorHandler) DeleteDoctor(c *gin.Context) {
 	// Delete doctor from database
 	if err := h.doctorRepo.DeleteDoctor(doctorID); err != nil {
 		h.logger.Error("Failed to delete doctor", "doctorID", doctorID, "error", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
+		respondServerError(c, err, ErrorResponse{
 			Error:   "Database error",
 			Message: "Failed to delete doctor",
 		})
@@ -276,6 +293,140 @@ func (h *CachedDoctorHandler) DeleteDoctor(c *gin.Context) {
 	})
 }
 
+// BulkDoctorStatusRequest represents the request payload for activating or deactivating many doctors
+type BulkDoctorStatusRequest struct {
+	IDs      []uint `json:"ids" binding:"required,min=1,max=100,dive,min=1"`
+	IsActive *bool  `json:"is_active" binding:"required"`
+}
+
+// BulkUpdateDoctorStatus handles POST /doctors/bulk-status - activates or deactivates several
+// doctors in one transaction, invalidating each doctor and every affected specialty list
+func (h *CachedDoctorHandler) BulkUpdateDoctorStatus(c *gin.Context) {
+	var req BulkDoctorStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Invalid request payload", "error", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "Please check your request payload",
+			Details: h.parseValidationErrors(err),
+		})
+		return
+	}
+
+	doctors, err := h.doctorRepo.SetDoctorsActive(req.IDs, *req.IsActive)
+	if err != nil {
+		h.logger.Error("Failed to update doctor statuses", "ids", req.IDs, "error", err)
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Doctor not found",
+				Message: err.Error(),
+			})
+			return
+		}
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Database error",
+			Message: "Failed to update doctor statuses",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	invalidatedSpecialties := make(map[uint]bool)
+	for _, doctor := range doctors {
+		h.invalidateDoctorCache(ctx, doctor.ID)
+		if !invalidatedSpecialties[doctor.SpecialtyID] {
+			invalidatedSpecialties[doctor.SpecialtyID] = true
+			h.invalidateSpecialtyListCache(ctx, doctor.SpecialtyID)
+		}
+	}
+
+	h.logger.Info("Doctor statuses updated successfully", "count", len(doctors), "isActive", *req.IsActive)
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Doctor statuses updated successfully",
+		Data: map[string]interface{}{
+			"updated":   len(doctors),
+			"ids":       req.IDs,
+			"is_active": *req.IsActive,
+		},
+	})
+}
+
+// AcceptingBookingsRequest represents the request payload for pausing or resuming a doctor's bookings
+type AcceptingBookingsRequest struct {
+	AcceptingBookings *bool `json:"accepting_bookings" binding:"required"`
+}
+
+// SetAcceptingBookings handles PUT /doctors/:id/accepting-bookings - pauses or resumes new bookings
+// for a doctor without deactivating them; existing appointments are kept. Admins can change any
+// doctor, doctors only themselves.
+func (h *CachedDoctorHandler) SetAcceptingBookings(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		h.logger.Error("Invalid doctor ID", "id", idStr, "error", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid ID",
+			Message: "Doctor ID must be a valid number",
+		})
+		return
+	}
+
+	doctorID := uint(id)
+	if c.GetString("role") != "admin" && !isAssignedDoctor(c, doctorID) {
+		h.logger.Warn("Doctor booking status change refused", "doctorID", doctorID, "callerDoctorID", c.GetUint("doctor_id"))
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "Forbidden",
+			Message: "You can only pause or resume your own bookings",
+		})
+		return
+	}
+
+	var req AcceptingBookingsRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Invalid request payload", "error", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "Please check your request payload",
+			Details: h.parseValidationErrors(err),
+		})
+		return
+	}
+
+	doctor, err := h.doctorRepo.SetAcceptingBookings(doctorID, *req.AcceptingBookings)
+	if err != nil {
+		h.logger.Error("Failed to update doctor booking status", "doctorID", doctorID, "error", err)
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Doctor not found",
+				Message: "The requested doctor does not exist",
+			})
+			return
+		}
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Database error",
+			Message: "Failed to update doctor booking status",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	h.invalidateDoctorCache(ctx, doctorID)
+	h.invalidateSpecialtyListCache(ctx, doctor.SpecialtyID)
+
+	message := "Doctor bookings resumed"
+	if !doctor.AcceptingBookings {
+		message = "Doctor bookings paused"
+	}
+
+	h.logger.Info("Doctor booking status updated", "doctorID", doctorID, "acceptingBookings", doctor.AcceptingBookings)
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: message,
+		Data:    doctor,
+	})
+}
+
 // GetDoctor handles GET /doctors/:id - retrieves a doctor by ID with caching
 func (h *CachedDoctorHandler) GetDoctor(c *gin.Context) {
 	idStr := c.Param("id")
@@ -307,6 +458,9 @@ func (h *CachedDoctorHandler) GetDoctor(c *gin.Context) {
 	doctor, err := h.doctorRepo.GetDoctorByID(doctorID)
 	if err != nil {
 		h.logger.Error("Failed to retrieve doctor", "doctorID", doctorID, "error", err)
+		if respondIfUnavailable(c, err) {
+			return
+		}
 		c.JSON(http.StatusNotFound, ErrorResponse{
 			Error:   "Doctor not found",
 			Message: "The requested doctor does not exist",
@@ -326,6 +480,143 @@ func (h *CachedDoctorHandler) GetDoctor(c *gin.Context) {
 	})
 }
 
+// doctorSummaryCacheTTL keeps profile aggregates briefly, since they only move as appointments conclude
+const doctorSummaryCacheTTL = 5 * time.Minute
+
+// GetDoctorSummary handles GET /doctors/:id/summary - retrieves a doctor's profile aggregates with caching
+func (h *CachedDoctorHandler) GetDoctorSummary(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		h.logger.Error("Invalid doctor ID", "id", idStr, "error", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid ID",
+			Message: "Doctor ID must be a valid number",
+		})
+		return
+	}
+
+	doctorID := uint(id)
+	ctx := c.Request.Context()
+	cacheKey := fmt.Sprintf("doctor:%d:summary", doctorID)
+
+	var cachedSummary models.DoctorSummary
+	if err := h.cacheService.Get(ctx, cacheKey, &cachedSummary); err == nil {
+		h.logger.Debug("Doctor summary retrieved from cache", "doctorID", doctorID)
+		c.JSON(http.StatusOK, SuccessResponse{
+			Message: "Doctor summary retrieved successfully",
+			Data:    cachedSummary,
+		})
+		return
+	}
+
+	if _, err := h.doctorRepo.GetDoctorByID(doctorID); err != nil {
+		h.logger.Error("Failed to retrieve doctor", "doctorID", doctorID, "error", err)
+		if respondIfUnavailable(c, err) {
+			return
+		}
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Doctor not found",
+			Message: "The requested doctor does not exist",
+		})
+		return
+	}
+
+	summary, err := h.doctorRepo.GetDoctorSummary(doctorID)
+	if err != nil {
+		h.logger.Error("Failed to compute doctor summary", "doctorID", doctorID, "error", err)
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Database error",
+			Message: "Failed to retrieve doctor summary",
+		})
+		return
+	}
+
+	if err := h.cacheService.Set(ctx, cacheKey, summary, doctorSummaryCacheTTL); err != nil {
+		h.logger.Warn("Failed to cache doctor summary", "doctorID", doctorID, "error", err)
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Doctor summary retrieved successfully",
+		Data:    summary,
+	})
+}
+
+const (
+	// bookableSpecialtiesCacheKey holds the specialties that currently have active doctors
+	bookableSpecialtiesCacheKey = "specialties:bookable"
+	// bookableSpecialtiesCacheTTL bounds staleness from specialty changes, which don't invalidate the list
+	bookableSpecialtiesCacheTTL = 10 * time.Minute
+)
+
+// GetBookableSpecialties handles GET /specialties/bookable - lists specialties with active doctors, with caching
+func (h *CachedDoctorHandler) GetBookableSpecialties(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var cachedSpecialties []models.BookableSpecialty
+	if err := h.cacheService.Get(ctx, bookableSpecialtiesCacheKey, &cachedSpecialties); err == nil {
+		h.logger.Debug("Bookable specialties retrieved from cache")
+		c.JSON(http.StatusOK, SuccessResponse{
+			Message: "Bookable specialties retrieved successfully",
+			Data:    cachedSpecialties,
+		})
+		return
+	}
+
+	specialties, err := h.doctorRepo.GetBookableSpecialties()
+	if err != nil {
+		h.logger.Error("Failed to retrieve bookable specialties", "error", err)
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Database error",
+			Message: "Failed to retrieve specialties",
+		})
+		return
+	}
+
+	if err := h.cacheService.Set(ctx, bookableSpecialtiesCacheKey, specialties, bookableSpecialtiesCacheTTL); err != nil {
+		h.logger.Warn("Failed to cache bookable specialties", "error", err)
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Bookable specialties retrieved successfully",
+		Data:    specialties,
+	})
+}
+
+// GetSpecialtyByName handles GET /specialties/by-name - looks up a specialty by its name, ignoring case
+func (h *CachedDoctorHandler) GetSpecialtyByName(c *gin.Context) {
+	name := strings.TrimSpace(c.Query("name"))
+	if name == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid name",
+			Message: "name query parameter is required",
+		})
+		return
+	}
+
+	specialty, err := h.doctorRepo.GetSpecialtyByName(name)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Specialty not found",
+				Message: "No specialty has the requested name",
+			})
+			return
+		}
+		h.logger.Error("Failed to retrieve specialty by name", "name", name, "error", err)
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Database error",
+			Message: "Failed to retrieve specialty",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Specialty retrieved successfully",
+		Data:    specialty,
+	})
+}
+
 // GetAllDoctors handles GET /doctors - retrieves all doctors with caching and filtering
 func (h *CachedDoctorHandler) GetAllDoctors(c *gin.Context) {
 	// Parse query parameters
@@ -407,7 +698,7 @@ func (h *CachedDoctorHandler) GetAllDoctors(c *gin.Context) {
 	doctors, err := h.doctorRepo.GetAllDoctors()
 	if err != nil {
 		h.logger.Error("Failed to retrieve doctors", "error", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
+		respondServerError(c, err, ErrorResponse{
 			Error:   "Database error",
 			Message: "Failed to retrieve doctors",
 		})
@@ -429,7 +720,11 @@ func (h *CachedDoctorHandler) GetAllDoctors(c *gin.Context) {
 	})
 }
 
-// GetDoctorsBySpecialty handles GET /doctors/specialty/:id - retrieves doctors by specialty with caching
+// earliestAvailabilityCacheTTL keeps availability-sorted lists short-lived, since slots open and fill constantly
+const earliestAvailabilityCacheTTL = time.Minute
+
+// GetDoctorsBySpecialty handles GET /specialties/:id/doctors - retrieves doctors by specialty with caching.
+// With sort=earliest_availability, active doctors are ordered by their soonest open slot.
 func (h *CachedDoctorHandler) GetDoctorsBySpecialty(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
@@ -445,6 +740,19 @@ func (h *CachedDoctorHandler) GetDoctorsBySpecialty(c *gin.Context) {
 	specialtyID := uint(id)
 	ctx := c.Request.Context()
 
+	switch sort := c.Query("sort"); sort {
+	case "":
+	case "earliest_availability":
+		h.getDoctorsByEarliestAvailability(c, specialtyID)
+		return
+	default:
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid sort parameter",
+			Message: "sort must be earliest_availability",
+		})
+		return
+	}
+
 	// Try to get from cache first
 	cachedDoctors, err := h.cacheService.GetDoctorsBySpecialty(ctx, specialtyID)
 	if err == nil {
@@ -457,10 +765,10 @@ func (h *CachedDoctorHandler) GetDoctorsBySpecialty(c *gin.Context) {
 	}
 
 	// Cache miss, get from database
-	doctors, err := h.doctorRepo.GetAllDoctors()
+	doctors, err := h.doctorRepo.GetDoctorsBySpecialty(specialtyID)
 	if err != nil {
 		h.logger.Error("Failed to retrieve doctors by specialty", "specialtyID", specialtyID, "error", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
+		respondServerError(c, err, ErrorResponse{
 			Error:   "Database error",
 			Message: "Failed to retrieve doctors",
 		})
@@ -479,6 +787,42 @@ func (h *CachedDoctorHandler) GetDoctorsBySpecialty(c *gin.Context) {
 	})
 }
 
+// getDoctorsByEarliestAvailability responds with a specialty's doctors ordered by soonest open slot,
+// caching the list briefly
+func (h *CachedDoctorHandler) getDoctorsByEarliestAvailability(c *gin.Context, specialtyID uint) {
+	ctx := c.Request.Context()
+	cacheKey := fmt.Sprintf("doctors:specialty:%d:earliest", specialtyID)
+
+	var cachedDoctors []models.Doctor
+	if err := h.cacheService.Get(ctx, cacheKey, &cachedDoctors); err == nil {
+		h.logger.Debug("Doctors by earliest availability retrieved from cache", "specialtyID", specialtyID)
+		c.JSON(http.StatusOK, SuccessResponse{
+			Message: "Doctors retrieved successfully",
+			Data:    cachedDoctors,
+		})
+		return
+	}
+
+	doctors, err := h.doctorRepo.GetDoctorsBySpecialtyByEarliestAvailability(specialtyID, time.Now())
+	if err != nil {
+		h.logger.Error("Failed to retrieve doctors by earliest availability", "specialtyID", specialtyID, "error", err)
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Database error",
+			Message: "Failed to retrieve doctors",
+		})
+		return
+	}
+
+	if err := h.cacheService.Set(ctx, cacheKey, doctors, earliestAvailabilityCacheTTL); err != nil {
+		h.logger.Warn("Failed to cache doctors by earliest availability", "specialtyID", specialtyID, "error", err)
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Doctors retrieved successfully",
+		Data:    doctors,
+	})
+}
+
 // invalidateRelatedCaches invalidates caches related to doctor changes
 // invalidateSpecialtyListCache invalidates only the specialty-specific list cache
 // This is more granular than invalidating all doctor caches
@@ -488,6 +832,14 @@ func (h *CachedDoctorHandler) invalidateSpecialtyListCache(ctx context.Context,
 	if err := h.cacheService.Delete(ctx, specialtyCacheKey); err != nil {
 		h.logger.Warn("Failed to invalidate specialty list cache", "specialtyID", specialtyID, "cacheKey", specialtyCacheKey, "error", err)
 	}
+	if err := h.cacheService.Delete(ctx, specialtyCacheKey+":earliest"); err != nil {
+		h.logger.Warn("Failed to invalidate specialty availability cache", "specialtyID", specialtyID, "error", err)
+	}
+
+	// Doctor changes can add or remove a specialty's last active doctor
+	if err := h.cacheService.Delete(ctx, bookableSpecialtiesCacheKey); err != nil {
+		h.logger.Warn("Failed to invalidate bookable specialties cache", "error", err)
+	}
 
 	// Also invalidate the general doctors list cache
 	generalCacheKey := "doctors:all"
@@ -506,6 +858,9 @@ func (h *CachedDoctorHandler) invalidateDoctorCache(ctx context.Context, doctorI
 	} else {
 		h.logger.Debug("Successfully invalidated doctor cache", "doctorID", doctorID, "cacheKey", doctorCacheKey)
 	}
+	if err := h.cacheService.Delete(ctx, doctorCacheKey+":summary"); err != nil {
+		h.logger.Warn("Failed to invalidate doctor summary cache", "doctorID", doctorID, "error", err)
+	}
 }
 
 // parseValidationErrors converts validation errors to a map
@@ -541,7 +896,7 @@ func (h *CachedDoctorHandler) ClearCache(c *gin.Context) {
 	// For now, we'll flush the entire cache (not recommended for production)
 	if err := h.cacheService.Flush(ctx); err != nil {
 		h.logger.Error("Failed to clear cache", "error", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
+		respondServerError(c, err, ErrorResponse{
 			Error:   "Cache error",
 			Message: "Failed to clear cache",
 		})
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/repository/repotest"
+	"smart-doctor-booking-app/services"
+)
+
+// earliestAvailabilityRepository serves a fixed availability-ordered list and counts queries, since
+// the repository's query relies on Postgres NULLS LAST ordering and aggregate time columns the test
+// database does not support
+type earliestAvailabilityRepository struct {
+	repository.DoctorRepository
+	doctors []models.Doctor
+	calls   int
+}
+
+func (r *earliestAvailabilityRepository) GetDoctorsBySpecialtyByEarliestAvailability(specialtyID uint, now time.Time) ([]models.Doctor, error) {
+	r.calls++
+	return r.doctors, nil
+}
+
+// newTestDoctorHandler returns a cached doctor handler over repo with an in-memory cache
+func newTestDoctorHandler(repo repository.DoctorRepository) *CachedDoctorHandler {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	handler := NewDoctorHandlerWithCache(repo, services.NewMemoryCacheService(services.CacheConfig{DefaultTTL: time.Hour}, logger))
+	handler.logger = logger
+	return handler
+}
+
+func TestGetDoctorsBySpecialtySortsByEarliestAvailability(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tomorrow := time.Now().Add(24 * time.Hour).Truncate(time.Hour).UTC()
+	soon := tomorrow.Add(-20 * time.Hour)
+	repo := &earliestAvailabilityRepository{doctors: []models.Doctor{
+		{ID: 2, Name: "Dr. Soon", SpecialtyID: 1, IsActive: true, EarliestAvailable: &soon},
+		{ID: 1, Name: "Dr. Later", SpecialtyID: 1, IsActive: true, EarliestAvailable: &tomorrow},
+		{ID: 3, Name: "Dr. Booked", SpecialtyID: 1, IsActive: true},
+	}}
+	handler := newTestDoctorHandler(repo)
+
+	router := gin.New()
+	router.GET("/specialties/:id/doctors", handler.GetDoctorsBySpecialty)
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/specialties/1/doctors"+query, nil))
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		w := get("?sort=earliest_availability")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var body struct {
+			Data []models.Doctor `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(body.Data) != 3 {
+			t.Fatalf("expected 3 doctors, got %d", len(body.Data))
+		}
+		for j, id := range []uint{2, 1, 3} {
+			if body.Data[j].ID != id {
+				t.Errorf("expected doctor %d at position %d, got %d", id, j, body.Data[j].ID)
+			}
+		}
+		if body.Data[0].EarliestAvailable == nil || !body.Data[0].EarliestAvailable.Equal(soon) {
+			t.Errorf("expected the first doctor's earliest opening at %v, got %v", soon, body.Data[0].EarliestAvailable)
+		}
+		if body.Data[2].EarliestAvailable != nil {
+			t.Errorf("expected no earliest opening for a fully booked doctor, got %v", body.Data[2].EarliestAvailable)
+		}
+	}
+	if repo.calls != 1 {
+		t.Errorf("expected the second request to be served from the cache, got %d queries", repo.calls)
+	}
+
+	if w := get("?sort=rating"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected an unknown sort to be rejected with 400, got %d", w.Code)
+	}
+}
+
+// unreachableDoctorRepository fails every lookup as if the database connection dropped
+type unreachableDoctorRepository struct {
+	repository.DoctorRepository
+}
+
+func (r *unreachableDoctorRepository) GetDoctorByID(id uint) (*models.Doctor, error) {
+	return nil, fmt.Errorf("failed to get doctor: %w", driver.ErrBadConn)
+}
+
+func TestGetDoctorReportsOutageAsServiceUnavailable(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name       string
+		repo       repository.DoctorRepository
+		wantStatus int
+		wantRetry  string
+	}{
+		{"connection error", &unreachableDoctorRepository{}, http.StatusServiceUnavailable, "5"},
+		{"missing doctor", repository.NewDoctorRepository(repotest.Open(t)), http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/doctors/:id", newTestDoctorHandler(tt.repo).GetDoctor)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/doctors/42", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.wantRetry {
+				t.Errorf("expected Retry-After %q, got %q", tt.wantRetry, got)
+			}
+		})
+	}
+}
+
+func TestBulkUpdateDoctorStatusDeactivatesAndInvalidates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := repotest.Open(t)
+	repotest.MustCreate(t, db,
+		&models.Specialty{ID: 1, Name: "General Practice"},
+		&models.Specialty{ID: 2, Name: "Cardiology"},
+		&models.Doctor{ID: 1, Name: "Dr. One", SpecialtyID: 1, IsActive: true},
+		&models.Doctor{ID: 2, Name: "Dr. Two", SpecialtyID: 2, IsActive: true},
+		&models.Doctor{ID: 3, Name: "Dr. Three", SpecialtyID: 1, IsActive: true},
+	)
+	handler := newTestDoctorHandler(repository.NewDoctorRepository(db))
+
+	ctx := context.Background()
+	cached := []string{"doctor:1", "doctor:2", "doctor:3", "doctors:specialty:1", "doctors:specialty:2", "doctors:specialty:1:earliest", "doctors:all"}
+	for _, key := range cached {
+		if err := handler.cacheService.Set(ctx, key, "stale", time.Hour); err != nil {
+			t.Fatalf("Set returned error: %v", err)
+		}
+	}
+
+	router := gin.New()
+	router.POST("/doctors/bulk-status", handler.BulkUpdateDoctorStatus)
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/doctors/bulk-status", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := post(`{"ids": [1, 2], "is_active": false}`); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var doctors []models.Doctor
+	if err := db.Order("id").Find(&doctors).Error; err != nil {
+		t.Fatalf("failed to load doctors: %v", err)
+	}
+	for _, doctor := range doctors {
+		if want := doctor.ID == 3; doctor.IsActive != want {
+			t.Errorf("expected doctor %d active=%v, got %v", doctor.ID, want, doctor.IsActive)
+		}
+	}
+
+	for _, key := range cached {
+		want := key == "doctor:3"
+		if got := handler.cacheService.Exists(ctx, key); got != want {
+			t.Errorf("expected cache key %s present=%v, got %v", key, want, got)
+		}
+	}
+
+	// An unknown ID fails the whole batch and leaves the rest untouched
+	if w := post(`{"ids": [3, 99], "is_active": false}`); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown doctor, got %d", w.Code)
+	}
+	var third models.Doctor
+	if err := db.First(&third, 3).Error; err != nil {
+		t.Fatalf("failed to load doctor 3: %v", err)
+	}
+	if !third.IsActive {
+		t.Error("expected doctor 3 to stay active after a failed batch")
+	}
+}
+
+func TestSetAcceptingBookingsOnlyForOwnDoctorOrAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := repotest.Open(t)
+	repotest.MustCreate(t, db,
+		&models.Specialty{ID: 1, Name: "General Practice"},
+		&models.Doctor{ID: 1, Name: "Dr. One", SpecialtyID: 1, IsActive: true},
+	)
+	handler := newTestDoctorHandler(repository.NewDoctorRepository(db))
+
+	put := func(caller gin.HandlerFunc, body string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.PUT("/doctors/:id/accepting-bookings", caller, handler.SetAcceptingBookings)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/doctors/1/accepting-bookings", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+	accepting := func() bool {
+		var doctor models.Doctor
+		if err := db.First(&doctor, 1).Error; err != nil {
+			t.Fatalf("failed to load doctor: %v", err)
+		}
+		return doctor.AcceptingBookings
+	}
+
+	if w := put(withDoctor(22, 2), `{"accepting_bookings": false}`); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for another doctor, got %d", w.Code)
+	}
+	if !accepting() {
+		t.Fatal("expected the refused request to leave bookings open")
+	}
+
+	if w := put(withDoctor(21, 1), `{"accepting_bookings": false}`); w.Code != http.StatusOK {
+		t.Fatalf("expected the doctor to pause their own bookings, got %d: %s", w.Code, w.Body.String())
+	}
+	if accepting() {
+		t.Error("expected bookings paused")
+	}
+	if w := put(withUser(1, "admin"), `{"accepting_bookings": true}`); w.Code != http.StatusOK {
+		t.Fatalf("expected an admin to resume bookings, got %d: %s", w.Code, w.Body.String())
+	}
+	if !accepting() {
+		t.Error("expected bookings resumed")
+	}
+}
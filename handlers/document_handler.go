@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"smart-doctor-booking-app/services"
+	"smart-doctor-booking-app/utils"
+)
+
+// DocumentHandler handles printable appointment document requests
+type DocumentHandler struct {
+	schedulingService services.SchedulingService
+	documentService   services.DocumentService
+}
+
+// NewDocumentHandler creates a new document handler
+func NewDocumentHandler(schedulingService services.SchedulingService, documentService services.DocumentService) *DocumentHandler {
+	return &DocumentHandler{
+		schedulingService: schedulingService,
+		documentService:   documentService,
+	}
+}
+
+// GetAppointmentPDF handles GET /api/v1/appointments/:id/pdf
+// @Summary Download an appointment confirmation
+// @Description Generate a one-page PDF confirmation for an appointment owned by the current user
+// @Tags appointments
+// @Produce application/pdf
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Appointment ID"
+// @Success 200 {file} file
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/appointments/{id}/pdf [get]
+func (h *DocumentHandler) GetAppointmentPDF(c *gin.Context) {
+	appointment, ok := authorizeAppointment(c, h.schedulingService)
+	if !ok {
+		return
+	}
+
+	isOwner := appointment.UserID == c.GetUint("user_id")
+	patientName := fmt.Sprintf("Patient #%d", appointment.UserID)
+	if isOwner && c.GetString("username") != "" {
+		patientName = c.GetString("username")
+	}
+
+	document, err := h.documentService.AppointmentConfirmationPDF(appointment, patientName)
+	if err != nil {
+		utils.LogError(err, "Failed to generate appointment PDF", map[string]interface{}{
+			"appointment_id": appointment.ID,
+			"user_id":        c.GetUint("user_id"),
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to generate PDF",
+			Message: "Unable to generate appointment confirmation. Please try again.",
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=\"appointment-%d.pdf\"", appointment.ID))
+	c.Data(http.StatusOK, "application/pdf", document)
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"smart-doctor-booking-app/utils"
+)
+
+// RetryAfterSeconds is how long clients are asked to wait before retrying when a dependency is down
+const RetryAfterSeconds = 5
+
+// respondServerError writes a 503 with a Retry-After header when err is a transient database or
+// cache outage, and a 500 with the given response otherwise
+func respondServerError(c *gin.Context, err error, response ErrorResponse) {
+	if respondIfUnavailable(c, err) {
+		return
+	}
+	c.JSON(http.StatusInternalServerError, response)
+}
+
+// respondIfUnavailable writes a 503 with a Retry-After header and returns true when err is a
+// transient dependency failure
+func respondIfUnavailable(c *gin.Context, err error) bool {
+	if !utils.IsTransientError(err) {
+		return false
+	}
+
+	c.Header("Retry-After", strconv.Itoa(RetryAfterSeconds))
+	c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+		Error:   "Service temporarily unavailable",
+		Message: "A backing service is unreachable. Please retry shortly.",
+	})
+	return true
+}
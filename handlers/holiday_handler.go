@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/services"
+	"smart-doctor-booking-app/utils"
+)
+
+// HolidayHandler handles holiday administration HTTP requests
+type HolidayHandler struct {
+	holidayRepo       repository.HolidayRepository
+	doctorRepo        repository.DoctorRepository
+	schedulingService services.SchedulingService
+}
+
+// NewHolidayHandler creates a new holiday handler. schedulingService may be nil, in which case
+// cached availability is left to expire on its own when holidays change.
+func NewHolidayHandler(holidayRepo repository.HolidayRepository, doctorRepo repository.DoctorRepository, schedulingService services.SchedulingService) *HolidayHandler {
+	return &HolidayHandler{
+		holidayRepo:       holidayRepo,
+		doctorRepo:        doctorRepo,
+		schedulingService: schedulingService,
+	}
+}
+
+// HolidayRequest represents the request body for creating or updating a holiday
+type HolidayRequest struct {
+	Date     string `json:"date" binding:"required"` // YYYY-MM-DD
+	Name     string `json:"name" binding:"required,min=2,max=255"`
+	DoctorID *uint  `json:"doctor_id"` // Omit to close the whole clinic
+}
+
+// HolidayResponse represents a single holiday
+type HolidayResponse struct {
+	Success bool            `json:"success"`
+	Message string          `json:"message"`
+	Holiday *models.Holiday `json:"holiday"`
+}
+
+// HolidaysResponse represents a list of holidays
+type HolidaysResponse struct {
+	Success  bool             `json:"success"`
+	Holidays []models.Holiday `json:"holidays"`
+}
+
+// GetHolidays handles GET /api/v1/admin/holidays
+// @Summary List holidays
+// @Description List clinic-wide and doctor-specific holidays in a date range (default: the next year)
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date (YYYY-MM-DD)"
+// @Success 200 {object} HolidaysResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/admin/holidays [get]
+func (h *HolidayHandler) GetHolidays(c *gin.Context) {
+	from := time.Now()
+	to := from.AddDate(1, 0, 0)
+
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid from date",
+				Message: "Please use YYYY-MM-DD format",
+			})
+			return
+		}
+		from = parsed
+	}
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid to date",
+				Message: "Please use YYYY-MM-DD format",
+			})
+			return
+		}
+		to = parsed
+	}
+
+	holidays, err := h.holidayRepo.GetHolidays(from, to)
+	if err != nil {
+		utils.LogError(err, "Failed to get holidays", nil)
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get holidays",
+			Message: "Unable to retrieve holidays. Please try again.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, HolidaysResponse{
+		Success:  true,
+		Holidays: holidays,
+	})
+}
+
+// CreateHoliday handles POST /api/v1/admin/holidays
+// @Summary Create a holiday
+// @Description Close bookings on a date, clinic-wide or for one doctor. Slot generation skips the date and bookings on it are rejected.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body HolidayRequest true "Holiday details"
+// @Success 201 {object} HolidayResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/admin/holidays [post]
+func (h *HolidayHandler) CreateHoliday(c *gin.Context) {
+	holiday, ok := bindHoliday(c)
+	if !ok {
+		return
+	}
+
+	if err := h.holidayRepo.CreateHoliday(holiday); err != nil {
+		utils.LogError(err, "Failed to create holiday", map[string]interface{}{
+			"date": holiday.Date,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to create holiday",
+			Message: "Unable to create the holiday. Please try again.",
+		})
+		return
+	}
+	h.invalidateAvailability(holiday)
+
+	c.JSON(http.StatusCreated, HolidayResponse{
+		Success: true,
+		Message: "Holiday created successfully",
+		Holiday: holiday,
+	})
+}
+
+// UpdateHoliday handles PUT /api/v1/admin/holidays/:id
+// @Summary Update a holiday
+// @Description Change a holiday's date, name or doctor
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Holiday ID"
+// @Param request body HolidayRequest true "Holiday details"
+// @Success 200 {object} HolidayResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/admin/holidays/{id} [put]
+func (h *HolidayHandler) UpdateHoliday(c *gin.Context) {
+	holidayID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid holiday ID",
+			Message: "Holiday ID must be a valid number",
+		})
+		return
+	}
+
+	existing, err := h.holidayRepo.GetHolidayByID(uint(holidayID))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Holiday not found",
+				Message: "The requested holiday does not exist",
+			})
+			return
+		}
+		utils.LogError(err, "Failed to get holiday", map[string]interface{}{
+			"holiday_id": holidayID,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to update holiday",
+			Message: "Unable to update the holiday. Please try again.",
+		})
+		return
+	}
+
+	holiday, ok := bindHoliday(c)
+	if !ok {
+		return
+	}
+	previous := *existing
+	existing.Date = holiday.Date
+	existing.Name = holiday.Name
+	existing.DoctorID = holiday.DoctorID
+
+	if err := h.holidayRepo.UpdateHoliday(existing); err != nil {
+		utils.LogError(err, "Failed to update holiday", map[string]interface{}{
+			"holiday_id": holidayID,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to update holiday",
+			Message: "Unable to update the holiday. Please try again.",
+		})
+		return
+	}
+	h.invalidateAvailability(&previous)
+	h.invalidateAvailability(existing)
+
+	c.JSON(http.StatusOK, HolidayResponse{
+		Success: true,
+		Message: "Holiday updated successfully",
+		Holiday: existing,
+	})
+}
+
+// DeleteHoliday handles DELETE /api/v1/admin/holidays/:id
+// @Summary Delete a holiday
+// @Description Reopen bookings on a holiday's date
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Holiday ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/admin/holidays/{id} [delete]
+func (h *HolidayHandler) DeleteHoliday(c *gin.Context) {
+	holidayID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid holiday ID",
+			Message: "Holiday ID must be a valid number",
+		})
+		return
+	}
+
+	holiday, err := h.holidayRepo.GetHolidayByID(uint(holidayID))
+	if err == nil {
+		err = h.holidayRepo.DeleteHoliday(holiday.ID)
+	}
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Holiday not found",
+				Message: "The requested holiday does not exist",
+			})
+			return
+		}
+		utils.LogError(err, "Failed to delete holiday", map[string]interface{}{
+			"holiday_id": holidayID,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to delete holiday",
+			Message: "Unable to delete the holiday. Please try again.",
+		})
+		return
+	}
+	h.invalidateAvailability(holiday)
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Message: "Holiday deleted successfully",
+	})
+}
+
+// invalidateAvailability drops the cached availability covering the holiday's date, for its doctor
+// or, for a clinic-wide holiday, for every doctor
+func (h *HolidayHandler) invalidateAvailability(holiday *models.Holiday) {
+	if h.schedulingService == nil {
+		return
+	}
+
+	from, to := holiday.Date, holiday.Date.AddDate(0, 0, 1)
+	if !holiday.AppliesToAll() {
+		h.schedulingService.InvalidateDoctorAvailability(*holiday.DoctorID, from, to)
+		return
+	}
+
+	doctors, err := h.doctorRepo.GetAllDoctors()
+	if err != nil {
+		utils.LogError(err, "Failed to list doctors to invalidate holiday availability", map[string]interface{}{
+			"holiday_id": holiday.ID,
+		})
+		return
+	}
+	for _, doctor := range doctors {
+		h.schedulingService.InvalidateDoctorAvailability(doctor.ID, from, to)
+	}
+}
+
+// bindHoliday parses a HolidayRequest body, writing a 400 and returning false when it is invalid
+func bindHoliday(c *gin.Context) (*models.Holiday, bool) {
+	var request HolidayRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+
+	date, err := time.Parse("2006-01-02", request.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid date format",
+			Message: "Please use YYYY-MM-DD format",
+		})
+		return nil, false
+	}
+
+	return &models.Holiday{
+		Date:     date,
+		Name:     request.Name,
+		DoctorID: request.DoctorID,
+	}, true
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/repository/repotest"
+	"smart-doctor-booking-app/services"
+)
+
+// availabilityInvalidation is one call to InvalidateDoctorAvailability
+type availabilityInvalidation struct {
+	doctorID uint
+	from, to time.Time
+}
+
+// invalidationRecordingService records the availability invalidations it is asked for
+type invalidationRecordingService struct {
+	services.SchedulingService
+	invalidations []availabilityInvalidation
+}
+
+func (s *invalidationRecordingService) InvalidateDoctorAvailability(doctorID uint, from, to time.Time) {
+	s.invalidations = append(s.invalidations, availabilityInvalidation{doctorID: doctorID, from: from, to: to})
+}
+
+func TestHolidayChangesInvalidateCachedAvailability(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := repotest.Open(t)
+	repotest.MustCreate(t, db,
+		&models.Specialty{ID: 1, Name: "General Practice"},
+		&models.Doctor{ID: 1, Name: "Doctor One", SpecialtyID: 1, IsActive: true},
+		&models.Doctor{ID: 2, Name: "Doctor Two", SpecialtyID: 1, IsActive: true},
+	)
+	service := &invalidationRecordingService{}
+	handler := NewHolidayHandler(repository.NewHolidayRepository(db), repository.NewDoctorRepository(db), service)
+
+	router := gin.New()
+	router.POST("/admin/holidays", handler.CreateHoliday)
+	router.DELETE("/admin/holidays/:id", handler.DeleteHoliday)
+	day := repotest.Day(2)
+
+	// expectInvalidated checks that exactly doctorIDs had the holiday's day invalidated, then resets
+	expectInvalidated := func(step string, doctorIDs ...uint) {
+		t.Helper()
+		if len(service.invalidations) != len(doctorIDs) {
+			t.Fatalf("%s: expected invalidations for doctors %v, got %+v", step, doctorIDs, service.invalidations)
+		}
+		for i, invalidation := range service.invalidations {
+			if invalidation.doctorID != doctorIDs[i] || !invalidation.from.Equal(day) || !invalidation.to.Equal(day.AddDate(0, 0, 1)) {
+				t.Errorf("%s: expected doctor %d invalidated for %s, got %+v", step, doctorIDs[i], day.Format("2006-01-02"), invalidation)
+			}
+		}
+		service.invalidations = nil
+	}
+	create := func(body string) *models.Holiday {
+		t.Helper()
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/holidays", strings.NewReader(body)))
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+		var response HolidayResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return response.Holiday
+	}
+
+	doctorHoliday := create(`{"date":"2031-03-05","name":"Training day","doctor_id":2}`)
+	expectInvalidated("doctor holiday", 2)
+
+	create(`{"date":"2031-03-05","name":"Clinic closed"}`)
+	expectInvalidated("clinic-wide holiday", 1, 2)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/admin/holidays/%d", doctorHoliday.ID), nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	expectInvalidated("deleted holiday", 2)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/holidays/999", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a missing holiday, got %d: %s", w.Code, w.Body.String())
+	}
+	expectInvalidated("missing holiday")
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/utils"
+)
+
+// LocationHandler handles clinic location HTTP requests
+type LocationHandler struct {
+	locationRepo repository.LocationRepository
+}
+
+// NewLocationHandler creates a new location handler
+func NewLocationHandler(locationRepo repository.LocationRepository) *LocationHandler {
+	return &LocationHandler{
+		locationRepo: locationRepo,
+	}
+}
+
+// LocationRequest represents the request body for creating a location
+type LocationRequest struct {
+	Name    string `json:"name" binding:"required,min=2,max=255"`
+	Address string `json:"address"`
+}
+
+// LocationResponse represents a single location
+type LocationResponse struct {
+	Success  bool             `json:"success"`
+	Message  string           `json:"message"`
+	Location *models.Location `json:"location"`
+}
+
+// LocationsResponse represents a list of locations
+type LocationsResponse struct {
+	Success   bool              `json:"success"`
+	Locations []models.Location `json:"locations"`
+}
+
+// GetLocations handles GET /api/v1/locations
+// @Summary List clinic locations
+// @Description List the active clinic locations, for filtering availability and choosing where to book
+// @Tags locations
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} LocationsResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/locations [get]
+func (h *LocationHandler) GetLocations(c *gin.Context) {
+	locations, err := h.locationRepo.GetActiveLocations()
+	if err != nil {
+		utils.LogError(err, "Failed to get locations", nil)
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get locations",
+			Message: "Unable to retrieve locations. Please try again.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, LocationsResponse{
+		Success:   true,
+		Locations: locations,
+	})
+}
+
+// CreateLocation handles POST /api/v1/admin/locations
+// @Summary Create a clinic location
+// @Description Add a site where doctors can see patients. Assign it to doctors to have their generated slots held there.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body LocationRequest true "Location details"
+// @Success 201 {object} LocationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/admin/locations [post]
+func (h *LocationHandler) CreateLocation(c *gin.Context) {
+	var request LocationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	location := &models.Location{
+		Name:     request.Name,
+		Address:  request.Address,
+		IsActive: true,
+	}
+	if err := h.locationRepo.CreateLocation(location); err != nil {
+		utils.LogError(err, "Failed to create location", map[string]interface{}{
+			"name": request.Name,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to create location",
+			Message: "Unable to create the location. Please try again.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, LocationResponse{
+		Success:  true,
+		Message:  "Location created successfully",
+		Location: location,
+	})
+}
@@ -0,0 +1,1517 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/services"
+	"smart-doctor-booking-app/utils"
+)
+
+// ScheduleHandler handles doctor schedule and time slot HTTP requests
+type ScheduleHandler struct {
+	schedulingService services.SchedulingService
+}
+
+// NewScheduleHandler creates a new schedule handler
+func NewScheduleHandler(schedulingService services.SchedulingService) *ScheduleHandler {
+	return &ScheduleHandler{
+		schedulingService: schedulingService,
+	}
+}
+
+// SlotsResponse represents a list of time slots
+type SlotsResponse struct {
+	Success bool              `json:"success"`
+	Message string            `json:"message"`
+	Slots   []models.TimeSlot `json:"slots"`
+	Total   int               `json:"total"`
+}
+
+// SlotDeletionResponse reports how many slots were deleted from a doctor's date range
+type SlotDeletionResponse struct {
+	Success  bool   `json:"success"`
+	Message  string `json:"message"`
+	DoctorID uint   `json:"doctor_id"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Deleted  int64  `json:"deleted"`
+}
+
+// LocatedAvailabilityResponse represents a doctor's availability merged across locations
+type LocatedAvailabilityResponse struct {
+	Success      bool                        `json:"success"`
+	Message      string                      `json:"message"`
+	Availability *models.LocatedAvailability `json:"availability"`
+}
+
+// ScheduleGridResponse represents a doctor's weekly schedule template as a seven-day grid
+type ScheduleGridResponse struct {
+	Success  bool                     `json:"success"`
+	Message  string                   `json:"message"`
+	DoctorID uint                     `json:"doctor_id"`
+	Days     []models.ScheduleGridDay `json:"days"`
+}
+
+// ScheduleValidationResponse represents the problems found in a doctor's schedule template
+type ScheduleValidationResponse struct {
+	Success  bool                   `json:"success"`
+	Message  string                 `json:"message"`
+	DoctorID uint                   `json:"doctor_id"`
+	Valid    bool                   `json:"valid"`
+	Issues   []models.ScheduleIssue `json:"issues"`
+}
+
+// ScheduleDiagnosticsResponse represents the mismatches found between a doctor's appointments,
+// slots and schedule
+type ScheduleDiagnosticsResponse struct {
+	Success       bool                         `json:"success"`
+	Message       string                       `json:"message"`
+	DoctorID      uint                         `json:"doctor_id"`
+	From          string                       `json:"from"`
+	To            string                       `json:"to"`
+	Consistent    bool                         `json:"consistent"`
+	Discrepancies []models.ScheduleDiscrepancy `json:"discrepancies"`
+}
+
+// DoctorCalendarResponse represents per-day appointment counts for a month
+type DoctorCalendarResponse struct {
+	Success  bool                      `json:"success"`
+	Message  string                    `json:"message"`
+	DoctorID uint                      `json:"doctor_id"`
+	Month    string                    `json:"month"`
+	Timezone string                    `json:"timezone"`
+	Days     []models.CalendarDayCount `json:"days"`
+}
+
+// BlockedPeriodsResponse represents the blocked periods and breaks in a date range
+type BlockedPeriodsResponse struct {
+	Success  bool                   `json:"success"`
+	Message  string                 `json:"message"`
+	DoctorID uint                   `json:"doctor_id"`
+	Periods  []models.BlockedPeriod `json:"periods"`
+	Total    int                    `json:"total"`
+}
+
+// BookableWindowsResponse represents the windows that fit a requested duration, per day
+type BookableWindowsResponse struct {
+	Success  bool                        `json:"success"`
+	Message  string                      `json:"message"`
+	DoctorID uint                        `json:"doctor_id"`
+	Duration int                         `json:"duration"`
+	Days     []models.DayBookableWindows `json:"days"`
+}
+
+// TimeOffResponse represents a doctor's upcoming time off
+type TimeOffResponse struct {
+	Success  bool                   `json:"success"`
+	Message  string                 `json:"message"`
+	DoctorID uint                   `json:"doctor_id"`
+	Periods  []models.TimeOffPeriod `json:"periods"`
+	Total    int                    `json:"total"`
+}
+
+// WaitEstimateResponse represents a walk-in wait estimate
+type WaitEstimateResponse struct {
+	Success  bool                 `json:"success"`
+	Message  string               `json:"message"`
+	Estimate *models.WaitEstimate `json:"estimate"`
+}
+
+// DurationInsightsResponse represents a doctor's appointment duration analysis
+type DurationInsightsResponse struct {
+	Success  bool                     `json:"success"`
+	Message  string                   `json:"message"`
+	Insights *models.DurationInsights `json:"insights"`
+}
+
+// ShiftAppointmentsRequest represents the request body for moving a window of appointments
+type ShiftAppointmentsRequest struct {
+	Date          string      `json:"date" binding:"required"` // YYYY-MM-DD
+	OffsetMinutes int         `json:"offset_minutes" binding:"required"`
+	Window        ShiftWindow `json:"window" binding:"required"`
+}
+
+// ShiftWindow is the time range whose appointments are shifted
+type ShiftWindow struct {
+	Start string `json:"start" binding:"required"` // HH:MM
+	End   string `json:"end" binding:"required"`   // HH:MM
+}
+
+// ShiftAppointmentsResponse summarises a bulk shift
+type ShiftAppointmentsResponse struct {
+	Success bool                 `json:"success"`
+	Message string               `json:"message"`
+	Shifted int                  `json:"shifted"`
+	Failed  int                  `json:"failed"`
+	Results []models.ShiftResult `json:"results"`
+}
+
+// GenerateSlotsRequest represents the request body for generating a week of slots
+type GenerateSlotsRequest struct {
+	StartDate string `json:"start_date" binding:"required"` // YYYY-MM-DD
+}
+
+// GenerateSlotsResponse reports the outcome of weekly slot generation per day
+type GenerateSlotsResponse struct {
+	Success bool                         `json:"success"`
+	Message string                       `json:"message"`
+	Days    []models.DayGenerationResult `json:"days"`
+}
+
+// ForecastGenerateRequest represents the request body for demand-based slot generation
+type ForecastGenerateRequest struct {
+	StartDate     string `json:"start_date" binding:"required"`                   // YYYY-MM-DD
+	Days          int    `json:"days" binding:"omitempty,min=1,max=28"`           // Defaults to 7
+	LookbackWeeks int    `json:"lookback_weeks" binding:"omitempty,min=1,max=26"` // Defaults to 8
+}
+
+// ForecastGenerateResponse lists the windows generated from the demand forecast
+type ForecastGenerateResponse struct {
+	Success      bool                    `json:"success"`
+	Message      string                  `json:"message"`
+	SlotsCreated int                     `json:"slots_created"`
+	Windows      []models.ForecastWindow `json:"windows"`
+}
+
+// AvailabilityOverrideRequest represents the request body for adding extra hours on a single date
+type AvailabilityOverrideRequest struct {
+	Date         string `json:"date" binding:"required"`       // YYYY-MM-DD
+	StartTime    string `json:"start_time" binding:"required"` // HH:MM
+	EndTime      string `json:"end_time" binding:"required"`   // HH:MM
+	SlotDuration int    `json:"slot_duration" binding:"required,min=15,max=180"`
+}
+
+// GetDoctorSlots handles GET /api/v1/doctors/:id/slots
+// @Summary Get a doctor's time slots for a date
+// @Description Get all time slots for a doctor on a date, optionally filtered by status. Booked slots include their appointment.
+// @Tags schedule
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Doctor ID"
+// @Param date query string true "Date (YYYY-MM-DD)"
+// @Param status query string false "Slot status (AVAILABLE, BOOKED, BLOCKED, BREAK)"
+// @Success 200 {object} SlotsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/doctors/{id}/slots [get]
+func (h *ScheduleHandler) GetDoctorSlots(c *gin.Context) {
+	doctorID, ok := authorizeDoctor(c)
+	if !ok {
+		return
+	}
+
+	date, ok := parseRequiredDate(c, "date")
+	if !ok {
+		return
+	}
+
+	status := models.SlotStatus(strings.ToUpper(c.Query("status")))
+	if status != "" && !status.IsValid() {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid status",
+			Message: "Status must be one of AVAILABLE, BOOKED, BLOCKED, BREAK",
+		})
+		return
+	}
+
+	slots, err := h.schedulingService.GetDoctorSlots(doctorID, date, status)
+	if err != nil {
+		utils.LogError(err, "Failed to get doctor slots", map[string]interface{}{
+			"doctor_id": doctorID,
+			"date":      date,
+			"status":    status,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get slots",
+			Message: "Unable to retrieve time slots. Please try again.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SlotsResponse{
+		Success: true,
+		Message: "Time slots retrieved successfully",
+		Slots:   slots,
+		Total:   len(slots),
+	})
+}
+
+// PreviewTimeSlots handles GET /api/v1/doctors/:id/slots/preview
+// @Summary Preview the slots generation would create
+// @Description Compute the slots the doctor's schedule and breaks would produce for a date without saving them. Slots overlapping a break are shown as BLOCKED; holidays and days off have no slots.
+// @Tags schedule
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Doctor ID"
+// @Param date query string true "Date (YYYY-MM-DD)"
+// @Success 200 {object} SlotsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/doctors/{id}/slots/preview [get]
+func (h *ScheduleHandler) PreviewTimeSlots(c *gin.Context) {
+	doctorID, ok := authorizeDoctor(c)
+	if !ok {
+		return
+	}
+
+	date, ok := parseRequiredDate(c, "date")
+	if !ok {
+		return
+	}
+
+	slots, err := h.schedulingService.PreviewTimeSlots(doctorID, date)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Schedule not found",
+				Message: "The doctor has no weekly schedule to generate slots from",
+			})
+			return
+		}
+		utils.LogError(err, "Failed to preview time slots", map[string]interface{}{
+			"doctor_id": doctorID,
+			"date":      date,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to preview slots",
+			Message: "Unable to preview time slots. Please try again.",
+		})
+		return
+	}
+	if slots == nil {
+		slots = []models.TimeSlot{}
+	}
+
+	c.JSON(http.StatusOK, SlotsResponse{
+		Success: true,
+		Message: "Time slot preview generated successfully",
+		Slots:   slots,
+		Total:   len(slots),
+	})
+}
+
+// GetScheduleGrid handles GET /api/v1/doctors/:id/schedule/grid
+// @Summary Get a doctor's weekly schedule grid
+// @Description Get the weekly schedule template as seven days with working hours, slot duration and recurring breaks. Days without hours are closed.
+// @Tags schedule
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Doctor ID"
+// @Success 200 {object} ScheduleGridResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/doctors/{id}/schedule/grid [get]
+func (h *ScheduleHandler) GetScheduleGrid(c *gin.Context) {
+	doctorID, ok := authorizeDoctor(c)
+	if !ok {
+		return
+	}
+
+	days, err := h.schedulingService.GetScheduleGrid(doctorID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Schedule not found",
+				Message: "No schedule is configured for this doctor",
+			})
+			return
+		}
+
+		utils.LogError(err, "Failed to get schedule grid", map[string]interface{}{
+			"doctor_id": doctorID,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get schedule",
+			Message: "Unable to retrieve schedule. Please try again.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ScheduleGridResponse{
+		Success:  true,
+		Message:  "Schedule retrieved successfully",
+		DoctorID: doctorID,
+		Days:     days,
+	})
+}
+
+// ValidateSchedule handles GET /api/v1/doctors/:id/schedule/validate
+// @Summary Check a doctor's schedule for gaps and overlaps
+// @Description Validate the weekly schedule template and recurring breaks: working hours must form a valid range, the slot duration must divide the working window evenly, and breaks must lie within working hours without overlapping
+// @Tags schedule
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Doctor ID"
+// @Success 200 {object} ScheduleValidationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/doctors/{id}/schedule/validate [get]
+func (h *ScheduleHandler) ValidateSchedule(c *gin.Context) {
+	doctorID, ok := authorizeDoctor(c)
+	if !ok {
+		return
+	}
+
+	issues, err := h.schedulingService.ValidateSchedule(doctorID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Schedule not found",
+				Message: "No schedule is configured for this doctor",
+			})
+			return
+		}
+
+		utils.LogError(err, "Failed to validate schedule", map[string]interface{}{
+			"doctor_id": doctorID,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to validate schedule",
+			Message: "Unable to validate schedule. Please try again.",
+		})
+		return
+	}
+
+	message := "Schedule has no issues"
+	if len(issues) > 0 {
+		message = fmt.Sprintf("Schedule has %d issue(s)", len(issues))
+	}
+
+	c.JSON(http.StatusOK, ScheduleValidationResponse{
+		Success:  true,
+		Message:  message,
+		DoctorID: doctorID,
+		Valid:    len(issues) == 0,
+		Issues:   issues,
+	})
+}
+
+// GetScheduleDiagnostics handles GET /api/v1/doctors/:id/diagnostics
+// @Summary Find inconsistencies between a doctor's appointments and slots
+// @Description Cross-check the doctor's scheduled and confirmed appointments from from to to (inclusive) against their time slots and weekly schedule. Reports appointments no booked slot holds, booked slots holding no active appointment, and appointments outside working hours.
+// @Tags schedule
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Doctor ID"
+// @Param from query string true "Start date (YYYY-MM-DD)"
+// @Param to query string true "End date (YYYY-MM-DD), at most 92 days after from"
+// @Success 200 {object} ScheduleDiagnosticsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/doctors/{id}/diagnostics [get]
+func (h *ScheduleHandler) GetScheduleDiagnostics(c *gin.Context) {
+	doctorID, ok := parseDoctorID(c)
+	if !ok {
+		return
+	}
+
+	from, ok := parseRequiredDate(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseRequiredDate(c, "to")
+	if !ok {
+		return
+	}
+	if to.Before(from) || to.Sub(from) > 92*24*time.Hour {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid date range",
+			Message: "to must be on or after from and at most 92 days later",
+		})
+		return
+	}
+
+	discrepancies, err := h.schedulingService.GetScheduleDiscrepancies(doctorID, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Schedule not found",
+				Message: "No schedule is configured for this doctor",
+			})
+			return
+		}
+
+		utils.LogError(err, "Failed to run schedule diagnostics", map[string]interface{}{
+			"doctor_id": doctorID,
+			"from":      from,
+			"to":        to,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to run diagnostics",
+			Message: "Unable to check the doctor's appointments and slots. Please try again.",
+		})
+		return
+	}
+
+	message := "No discrepancies found"
+	if len(discrepancies) > 0 {
+		message = fmt.Sprintf("Found %d discrepancy(ies)", len(discrepancies))
+	}
+
+	c.JSON(http.StatusOK, ScheduleDiagnosticsResponse{
+		Success:       true,
+		Message:       message,
+		DoctorID:      doctorID,
+		From:          from.Format("2006-01-02"),
+		To:            to.Format("2006-01-02"),
+		Consistent:    len(discrepancies) == 0,
+		Discrepancies: discrepancies,
+	})
+}
+
+// GetDoctorCalendar handles GET /api/v1/doctors/:id/calendar
+// @Summary Get a doctor's monthly appointment heatmap
+// @Description Get the number of active appointments per day for a month. Days are computed in the requested timezone.
+// @Tags schedule
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Doctor ID"
+// @Param month query string true "Month (YYYY-MM)"
+// @Param tz query string false "IANA timezone (default UTC)"
+// @Success 200 {object} DoctorCalendarResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/doctors/{id}/calendar [get]
+func (h *ScheduleHandler) GetDoctorCalendar(c *gin.Context) {
+	doctorID, ok := parseDoctorID(c)
+	if !ok {
+		return
+	}
+
+	loc, err := time.LoadLocation(c.DefaultQuery("tz", "UTC"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid timezone",
+			Message: "tz must be an IANA timezone such as Europe/London",
+		})
+		return
+	}
+
+	month, err := time.ParseInLocation("2006-01", c.Query("month"), loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid month format",
+			Message: "Please provide month in YYYY-MM format",
+		})
+		return
+	}
+
+	days, err := h.schedulingService.GetDoctorCalendar(doctorID, month)
+	if err != nil {
+		utils.LogError(err, "Failed to get doctor calendar", map[string]interface{}{
+			"doctor_id": doctorID,
+			"month":     month.Format("2006-01"),
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get calendar",
+			Message: "Unable to retrieve calendar. Please try again.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, DoctorCalendarResponse{
+		Success:  true,
+		Message:  "Calendar retrieved successfully",
+		DoctorID: doctorID,
+		Month:    month.Format("2006-01"),
+		Timezone: loc.String(),
+		Days:     days,
+	})
+}
+
+// GetScheduleICS handles GET /api/v1/doctors/:id/schedule.ics
+// @Summary Download a doctor's weekly schedule as iCalendar
+// @Description Export working hours as weekly recurring events, with recurring breaks as separate events
+// @Tags schedule
+// @Produce text/calendar
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Doctor ID"
+// @Success 200 {file} file
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/doctors/{id}/schedule.ics [get]
+func (h *ScheduleHandler) GetScheduleICS(c *gin.Context) {
+	doctorID, ok := authorizeDoctor(c)
+	if !ok {
+		return
+	}
+
+	days, err := h.schedulingService.GetScheduleGrid(doctorID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Schedule not found",
+				Message: "No schedule is configured for this doctor",
+			})
+			return
+		}
+
+		utils.LogError(err, "Failed to get schedule for ICS export", map[string]interface{}{
+			"doctor_id": doctorID,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get schedule",
+			Message: "Unable to retrieve schedule. Please try again.",
+		})
+		return
+	}
+
+	calendar := utils.NewICSCalendar(fmt.Sprintf("Doctor %d working hours", doctorID))
+	today := time.Now()
+	for _, day := range days {
+		if !day.IsOpen {
+			continue
+		}
+
+		// Recurrence starts at the next occurrence of this weekday
+		offset := (int(day.Day.Weekday()) - int(today.Weekday()) + 7) % 7
+		date := today.AddDate(0, 0, offset)
+		rrule := "FREQ=WEEKLY;BYDAY=" + utils.ICSWeekday(day.Day.Weekday())
+
+		start, startErr := combineDateAndClock(date, day.StartTime)
+		end, endErr := combineDateAndClock(date, day.EndTime)
+		if startErr != nil || endErr != nil {
+			continue
+		}
+
+		calendar.AddEvent(utils.ICSEvent{
+			UID:         fmt.Sprintf("doctor-%d-hours-%s@smart-doctor-booking", doctorID, strings.ToLower(string(day.Day))),
+			Summary:     "Working hours",
+			Description: fmt.Sprintf("Slot duration: %d minutes", day.SlotDuration),
+			Start:       start,
+			End:         end,
+			Floating:    true,
+			RRule:       rrule,
+		})
+
+		for i, dayBreak := range day.Breaks {
+			breakStart, startErr := combineDateAndClock(date, dayBreak.StartTime)
+			breakEnd, endErr := combineDateAndClock(date, dayBreak.EndTime)
+			if startErr != nil || endErr != nil {
+				continue
+			}
+
+			summary := "Break"
+			if dayBreak.Reason != "" {
+				summary = "Break: " + dayBreak.Reason
+			}
+			calendar.AddEvent(utils.ICSEvent{
+				UID:      fmt.Sprintf("doctor-%d-break-%s-%d@smart-doctor-booking", doctorID, strings.ToLower(string(day.Day)), i),
+				Summary:  summary,
+				Start:    breakStart,
+				End:      breakEnd,
+				Floating: true,
+				RRule:    rrule,
+			})
+		}
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"doctor-%d-schedule.ics\"", doctorID))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(calendar.String()))
+}
+
+// GenerateWeeklySlots handles POST /api/v1/doctors/:id/slots/generate
+// @Summary Generate a week of time slots
+// @Description Generate slots for seven days from start_date using the doctor's schedule. Days that fail do not stop the others; each day's outcome is returned.
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Doctor ID"
+// @Param request body GenerateSlotsRequest true "Generation details"
+// @Success 200 {object} GenerateSlotsResponse
+// @Failure 207 {object} GenerateSlotsResponse "Some days failed"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Generation already running for one of the days"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/doctors/{id}/slots/generate [post]
+func (h *ScheduleHandler) GenerateWeeklySlots(c *gin.Context) {
+	doctorID, ok := authorizeDoctor(c)
+	if !ok {
+		return
+	}
+
+	var request GenerateSlotsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	startDate, err := time.Parse("2006-01-02", request.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid date format",
+			Message: "Please use YYYY-MM-DD format",
+		})
+		return
+	}
+
+	days, err := h.schedulingService.GenerateWeeklySlots(doctorID, startDate)
+	if err != nil {
+		if errors.Is(err, services.ErrGenerationInProgress) {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "Generation in progress",
+				Message: err.Error(),
+			})
+			return
+		}
+
+		var generationErr *repository.WeeklyGenerationError
+		if !errors.As(err, &generationErr) {
+			utils.LogError(err, "Failed to generate weekly slots", map[string]interface{}{
+				"doctor_id":  doctorID,
+				"start_date": request.StartDate,
+			})
+			respondServerError(c, err, ErrorResponse{
+				Error:   "Failed to generate slots",
+				Message: "Unable to generate time slots. Please try again.",
+			})
+			return
+		}
+
+		status := http.StatusMultiStatus
+		if len(generationErr.Failures) == len(days) {
+			status = http.StatusUnprocessableEntity
+		}
+		c.JSON(status, GenerateSlotsResponse{
+			Success: false,
+			Message: generationErr.Error(),
+			Days:    days,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GenerateSlotsResponse{
+		Success: true,
+		Message: "Time slots generated successfully",
+		Days:    days,
+	})
+}
+
+// ForecastGenerateSlots handles POST /api/v1/doctors/:id/slots/forecast-generate
+// @Summary Generate slots from the demand forecast
+// @Description Add slots within the doctor's working hours sized by recent booking density per weekday and hour: shorter, more numerous slots in busy hours and longer, fewer slots in quiet ones. Existing slots are kept, breaks and holidays are skipped.
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Doctor ID"
+// @Param request body ForecastGenerateRequest true "Generation details"
+// @Success 201 {object} ForecastGenerateResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Generation already running for one of the days"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/doctors/{id}/slots/forecast-generate [post]
+func (h *ScheduleHandler) ForecastGenerateSlots(c *gin.Context) {
+	doctorID, ok := authorizeDoctor(c)
+	if !ok {
+		return
+	}
+
+	var request ForecastGenerateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	startDate, err := time.Parse("2006-01-02", request.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid date format",
+			Message: "Please use YYYY-MM-DD format",
+		})
+		return
+	}
+
+	days := request.Days
+	if days == 0 {
+		days = 7
+	}
+	lookbackWeeks := request.LookbackWeeks
+	if lookbackWeeks == 0 {
+		lookbackWeeks = 8
+	}
+
+	windows, err := h.schedulingService.ForecastGenerateSlots(doctorID, startDate, days, lookbackWeeks)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrGenerationInProgress):
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "Generation in progress",
+				Message: err.Error(),
+			})
+		case errors.Is(err, utils.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid request",
+				Message: err.Error(),
+			})
+		case strings.Contains(err.Error(), "not found"):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Schedule not found",
+				Message: "The doctor has no schedule to generate slots from",
+			})
+		default:
+			utils.LogError(err, "Failed to generate forecast slots", map[string]interface{}{
+				"doctor_id":  doctorID,
+				"start_date": request.StartDate,
+			})
+			respondServerError(c, err, ErrorResponse{
+				Error:   "Failed to generate slots",
+				Message: "Unable to generate time slots. Please try again.",
+			})
+		}
+		return
+	}
+
+	created := 0
+	for _, window := range windows {
+		created += window.SlotsCreated
+	}
+	if windows == nil {
+		windows = []models.ForecastWindow{}
+	}
+
+	c.JSON(http.StatusCreated, ForecastGenerateResponse{
+		Success:      true,
+		Message:      fmt.Sprintf("Generated %d slots from the demand forecast", created),
+		SlotsCreated: created,
+		Windows:      windows,
+	})
+}
+
+// GetBlockedPeriods handles GET /api/v1/doctors/:id/blocks
+// @Summary List a doctor's blocked periods
+// @Description Get blocked and break slots plus one-off and recurring breaks between from and to (inclusive), with their reasons
+// @Tags schedule
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Doctor ID"
+// @Param from query string true "Start date (YYYY-MM-DD)"
+// @Param to query string true "End date (YYYY-MM-DD), at most 31 days after from"
+// @Success 200 {object} BlockedPeriodsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/doctors/{id}/blocks [get]
+func (h *ScheduleHandler) GetBlockedPeriods(c *gin.Context) {
+	doctorID, ok := authorizeDoctor(c)
+	if !ok {
+		return
+	}
+
+	from, ok := parseRequiredDate(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseRequiredDate(c, "to")
+	if !ok {
+		return
+	}
+	if to.Before(from) || to.Sub(from) > 31*24*time.Hour {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid date range",
+			Message: "to must be on or after from and at most 31 days later",
+		})
+		return
+	}
+
+	periods, err := h.schedulingService.GetBlockedPeriods(doctorID, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		utils.LogError(err, "Failed to get blocked periods", map[string]interface{}{
+			"doctor_id": doctorID,
+			"from":      from,
+			"to":        to,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get blocks",
+			Message: "Unable to retrieve blocked periods. Please try again.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, BlockedPeriodsResponse{
+		Success:  true,
+		Message:  "Blocked periods retrieved successfully",
+		DoctorID: doctorID,
+		Periods:  periods,
+		Total:    len(periods),
+	})
+}
+
+// GetDoctorTimeOff handles GET /api/v1/doctors/:id/time-off
+// @Summary List a doctor's upcoming time off
+// @Description Get the periods a doctor is away that have not yet ended: runs of blocked slots, merged across consecutive days when they share a reason, and doctor-specific or clinic-wide holidays
+// @Tags schedule
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Doctor ID"
+// @Param days query int false "How many days ahead to look (default 90, max 365)"
+// @Success 200 {object} TimeOffResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/doctors/{id}/time-off [get]
+func (h *ScheduleHandler) GetDoctorTimeOff(c *gin.Context) {
+	doctorID, ok := parseDoctorID(c)
+	if !ok {
+		return
+	}
+
+	days := 90
+	if value := c.Query("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 365 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid days",
+				Message: "days must be a number between 1 and 365",
+			})
+			return
+		}
+		days = parsed
+	}
+
+	periods, err := h.schedulingService.GetDoctorTimeOff(doctorID, time.Now(), days)
+	if err != nil {
+		utils.LogError(err, "Failed to get doctor time off", map[string]interface{}{
+			"doctor_id": doctorID,
+			"days":      days,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get time off",
+			Message: "Unable to retrieve the doctor's time off. Please try again.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, TimeOffResponse{
+		Success:  true,
+		Message:  "Doctor time off retrieved successfully",
+		DoctorID: doctorID,
+		Periods:  periods,
+		Total:    len(periods),
+	})
+}
+
+// GetWaitEstimate handles GET /api/v1/doctors/:id/wait-estimate
+// @Summary Estimate a walk-in wait
+// @Description Estimate how long a walk-in patient would wait to see the doctor, from the scheduled and confirmed appointments still ahead today and their average duration
+// @Tags schedule
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Doctor ID"
+// @Success 200 {object} WaitEstimateResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/doctors/{id}/wait-estimate [get]
+func (h *ScheduleHandler) GetWaitEstimate(c *gin.Context) {
+	doctorID, ok := parseDoctorID(c)
+	if !ok {
+		return
+	}
+
+	estimate, err := h.schedulingService.GetWaitEstimate(doctorID, time.Now())
+	if err != nil {
+		utils.LogError(err, "Failed to estimate wait", map[string]interface{}{
+			"doctor_id": doctorID,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to estimate wait",
+			Message: "Unable to estimate the wait. Please try again.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, WaitEstimateResponse{
+		Success:  true,
+		Message:  "Wait estimate calculated successfully",
+		Estimate: estimate,
+	})
+}
+
+// GetDurationInsights handles GET /api/v1/doctors/:id/duration-insights
+// @Summary Analyze appointment durations
+// @Description Get the distribution, average, median and 90th percentile of the doctor's completed appointment durations, with a suggested slot duration (the median rounded up to 5 minutes)
+// @Tags schedule
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Doctor ID"
+// @Success 200 {object} DurationInsightsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/doctors/{id}/duration-insights [get]
+func (h *ScheduleHandler) GetDurationInsights(c *gin.Context) {
+	doctorID, ok := authorizeDoctor(c)
+	if !ok {
+		return
+	}
+
+	insights, err := h.schedulingService.GetDurationInsights(doctorID)
+	if err != nil {
+		utils.LogError(err, "Failed to get duration insights", map[string]interface{}{
+			"doctor_id": doctorID,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get duration insights",
+			Message: "Unable to analyze appointment durations. Please try again.",
+		})
+		return
+	}
+
+	message := "Duration insights calculated successfully"
+	if insights.SampleSize == 0 {
+		message = "No completed appointments to analyze yet"
+	}
+
+	c.JSON(http.StatusOK, DurationInsightsResponse{
+		Success:  true,
+		Message:  message,
+		Insights: insights,
+	})
+}
+
+// DoctorReliabilityResponse lists a doctor's monthly cancellation and no-show rates
+type DoctorReliabilityResponse struct {
+	Success  bool                            `json:"success"`
+	DoctorID uint                            `json:"doctor_id"`
+	From     string                          `json:"from"`
+	To       string                          `json:"to"`
+	Months   []models.DoctorReliabilityMonth `json:"months"`
+}
+
+// GetDoctorReliability handles GET /api/v1/doctors/:id/reliability
+// @Summary Get a doctor's cancellation and no-show rates per month
+// @Description Get, for every calendar month from from to to, how many of the doctor's appointments were booked, cancelled and missed, with the rates as percentages. Months without appointments are included with zero counts. Defaults to the last 180 days.
+// @Tags schedule
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Doctor ID"
+// @Param from query string false "Start date (YYYY-MM-DD), inclusive"
+// @Param to query string false "End date (YYYY-MM-DD), inclusive"
+// @Success 200 {object} DoctorReliabilityResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/doctors/{id}/reliability [get]
+func (h *ScheduleHandler) GetDoctorReliability(c *gin.Context) {
+	doctorID, ok := authorizeDoctor(c)
+	if !ok {
+		return
+	}
+
+	from, to, ok := parseDateRange(c, 180)
+	if !ok {
+		return
+	}
+
+	months, err := h.schedulingService.GetDoctorReliability(doctorID, from, to)
+	if err != nil {
+		utils.LogError(err, "Failed to get doctor reliability", map[string]interface{}{
+			"doctor_id": doctorID,
+			"from":      from,
+			"to":        to,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get reliability",
+			Message: "Unable to calculate cancellation and no-show rates. Please try again.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, DoctorReliabilityResponse{
+		Success:  true,
+		DoctorID: doctorID,
+		From:     from.Format("2006-01-02"),
+		To:       to.Format("2006-01-02"),
+		Months:   months,
+	})
+}
+
+// GetAllLocationsAvailability handles GET /api/v1/doctors/:id/availability/all-locations
+// @Summary Get a doctor's availability across all locations
+// @Description Return the doctor's available slots for the date at every location they work from, merged into one list ordered by start time with each slot labelled with its location
+// @Tags schedule
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Doctor ID"
+// @Param date query string true "Date (YYYY-MM-DD)"
+// @Success 200 {object} LocatedAvailabilityResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/doctors/{id}/availability/all-locations [get]
+func (h *ScheduleHandler) GetAllLocationsAvailability(c *gin.Context) {
+	doctorID, ok := parseDoctorID(c)
+	if !ok {
+		return
+	}
+
+	date, ok := parseRequiredDate(c, "date")
+	if !ok {
+		return
+	}
+
+	availability, err := h.schedulingService.GetDoctorAvailabilityAllLocations(doctorID, date)
+	if err != nil {
+		utils.LogError(err, "Failed to get availability across locations", map[string]interface{}{
+			"doctor_id": doctorID,
+			"date":      date,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get availability",
+			Message: "Unable to retrieve doctor availability. Please try again.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, LocatedAvailabilityResponse{
+		Success:      true,
+		Message:      "Doctor availability retrieved successfully",
+		Availability: availability,
+	})
+}
+
+// GetBookableWindows handles GET /api/v1/doctors/:id/bookable-windows
+// @Summary Find windows that fit an appointment length
+// @Description For each day from from to to (inclusive), merge back-to-back available slots and return the windows at least duration minutes long
+// @Tags schedule
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Doctor ID"
+// @Param from query string true "Start date (YYYY-MM-DD)"
+// @Param to query string true "End date (YYYY-MM-DD), at most 31 days after from"
+// @Param duration query int true "Appointment length in minutes (15-180)"
+// @Success 200 {object} BookableWindowsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/doctors/{id}/bookable-windows [get]
+func (h *ScheduleHandler) GetBookableWindows(c *gin.Context) {
+	doctorID, ok := parseDoctorID(c)
+	if !ok {
+		return
+	}
+
+	from, ok := parseRequiredDate(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseRequiredDate(c, "to")
+	if !ok {
+		return
+	}
+	if to.Before(from) || to.Sub(from) > 31*24*time.Hour {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid date range",
+			Message: "to must be on or after from and at most 31 days later",
+		})
+		return
+	}
+
+	duration, err := strconv.Atoi(c.Query("duration"))
+	if err != nil || duration < 15 || duration > 180 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid duration",
+			Message: "duration must be a number of minutes between 15 and 180",
+		})
+		return
+	}
+
+	days, err := h.schedulingService.GetBookableWindows(doctorID, from, to, duration)
+	if err != nil {
+		utils.LogError(err, "Failed to get bookable windows", map[string]interface{}{
+			"doctor_id": doctorID,
+			"from":      from,
+			"to":        to,
+			"duration":  duration,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get bookable windows",
+			Message: "Unable to retrieve bookable windows. Please try again.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, BookableWindowsResponse{
+		Success:  true,
+		Message:  "Bookable windows retrieved successfully",
+		DoctorID: doctorID,
+		Duration: duration,
+		Days:     days,
+	})
+}
+
+// ShiftAppointments handles POST /api/v1/doctors/:id/shift
+// @Summary Shift a window of appointments
+// @Description Move every active appointment starting inside the window by offset_minutes. Each appointment is checked for conflicts and moved on its own; failures are reported per appointment. Admins, and doctors for their own appointments, only.
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Doctor ID"
+// @Param request body ShiftAppointmentsRequest true "Shift details"
+// @Success 200 {object} ShiftAppointmentsResponse
+// @Failure 207 {object} ShiftAppointmentsResponse "Some appointments could not be moved"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/doctors/{id}/shift [post]
+func (h *ScheduleHandler) ShiftAppointments(c *gin.Context) {
+	doctorID, ok := authorizeDoctor(c)
+	if !ok {
+		return
+	}
+
+	var request ShiftAppointmentsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", request.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid date format",
+			Message: "Please use YYYY-MM-DD format",
+		})
+		return
+	}
+
+	windowStart, startErr := combineDateAndClock(date, request.Window.Start)
+	windowEnd, endErr := combineDateAndClock(date, request.Window.End)
+	if startErr != nil || endErr != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid window format",
+			Message: "Please use HH:MM format for window start and end",
+		})
+		return
+	}
+	if !windowEnd.After(windowStart) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid time range",
+			Message: "Window end must be after window start",
+		})
+		return
+	}
+
+	results, err := h.schedulingService.ShiftAppointments(c.Request.Context(), doctorID, windowStart, windowEnd, time.Duration(request.OffsetMinutes)*time.Minute)
+	if err != nil {
+		utils.LogError(err, "Failed to shift appointments", map[string]interface{}{
+			"doctor_id":      doctorID,
+			"date":           request.Date,
+			"offset_minutes": request.OffsetMinutes,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to shift appointments",
+			Message: "Unable to shift appointments. Please try again.",
+		})
+		return
+	}
+
+	response := ShiftAppointmentsResponse{
+		Success: true,
+		Message: "Appointments shifted successfully",
+		Results: results,
+	}
+	for _, result := range results {
+		if result.Success {
+			response.Shifted++
+		} else {
+			response.Failed++
+		}
+	}
+
+	status := http.StatusOK
+	if response.Failed > 0 {
+		status = http.StatusMultiStatus
+		response.Success = false
+		response.Message = "Some appointments could not be shifted"
+	}
+	c.JSON(status, response)
+}
+
+// AddAvailabilityOverride handles POST /api/v1/doctors/:id/availability-override
+// @Summary Add extra availability for a single date
+// @Description Generate extra slots for a date independent of the weekly schedule, skipping times already covered by existing slots
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Doctor ID"
+// @Param override body AvailabilityOverrideRequest true "Override details"
+// @Success 201 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/doctors/{id}/availability-override [post]
+func (h *ScheduleHandler) AddAvailabilityOverride(c *gin.Context) {
+	doctorID, ok := authorizeDoctor(c)
+	if !ok {
+		return
+	}
+
+	var request AvailabilityOverrideRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", request.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid date format",
+			Message: "Please use YYYY-MM-DD format",
+		})
+		return
+	}
+
+	startTime, err := time.Parse("15:04", request.StartTime)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid start time format",
+			Message: "Please use HH:MM format",
+		})
+		return
+	}
+
+	endTime, err := time.Parse("15:04", request.EndTime)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid end time format",
+			Message: "Please use HH:MM format",
+		})
+		return
+	}
+
+	start := time.Date(date.Year(), date.Month(), date.Day(), startTime.Hour(), startTime.Minute(), 0, 0, date.Location())
+	end := time.Date(date.Year(), date.Month(), date.Day(), endTime.Hour(), endTime.Minute(), 0, 0, date.Location())
+	if !end.After(start) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid time range",
+			Message: "End time must be after start time",
+		})
+		return
+	}
+
+	created, err := h.schedulingService.AddAvailabilityOverride(doctorID, start, end, time.Duration(request.SlotDuration)*time.Minute)
+	if err != nil {
+		utils.LogError(err, "Failed to add availability override", map[string]interface{}{
+			"doctor_id":  doctorID,
+			"date":       request.Date,
+			"start_time": request.StartTime,
+			"end_time":   request.EndTime,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to add availability",
+			Message: "Unable to add availability override. Please try again.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Message: "Availability override added successfully",
+		Data: gin.H{
+			"doctor_id":     doctorID,
+			"date":          request.Date,
+			"slots_created": created,
+		},
+	})
+}
+
+// combineDateAndClock returns the date at the given HH:MM clock time
+func combineDateAndClock(date time.Time, clock string) (time.Time, error) {
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), parsed.Hour(), parsed.Minute(), 0, 0, date.Location()), nil
+}
+
+// parseDoctorID parses the :id path parameter, writing a 400 response on failure
+func parseDoctorID(c *gin.Context) (uint, bool) {
+	doctorID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid doctor ID",
+			Message: "Doctor ID must be a valid number",
+		})
+		return 0, false
+	}
+	return uint(doctorID), true
+}
+
+// parseRequiredDate parses a required YYYY-MM-DD query parameter, writing a 400 response on failure
+func parseRequiredDate(c *gin.Context, name string) (time.Time, bool) {
+	dateStr := c.Query(name)
+	if dateStr == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Missing " + name + " parameter",
+			Message: "Please provide " + name + " in YYYY-MM-DD format",
+		})
+		return time.Time{}, false
+	}
+
+	date, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid " + name + " format",
+			Message: "Please use YYYY-MM-DD format",
+		})
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// GetOrphanedSlots handles GET /api/v1/admin/slots/orphans
+// @Summary Find orphaned booked slots
+// @Description List BOOKED slots that no appointment holds: the slot has no appointment, or its appointment was deleted or cancelled
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} SlotsResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/admin/slots/orphans [get]
+func (h *ScheduleHandler) GetOrphanedSlots(c *gin.Context) {
+	slots, err := h.schedulingService.GetOrphanedSlots()
+	if err != nil {
+		utils.LogError(err, "Failed to get orphaned slots", nil)
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get orphaned slots",
+			Message: "Unable to check for orphaned slots. Please try again.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SlotsResponse{
+		Success: true,
+		Message: "Orphaned slots retrieved successfully",
+		Slots:   slots,
+		Total:   len(slots),
+	})
+}
+
+// DeleteSlots handles DELETE /api/v1/doctors/:id/slots
+// @Summary Bulk-delete a doctor's unbooked slots
+// @Description Delete the doctor's AVAILABLE and BLOCKED slots from from to to (inclusive), e.g. to clean up mistakenly generated slots. Booked slots and breaks are never deleted. Admins only.
+// @Tags schedule
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Doctor ID"
+// @Param from query string true "Start date (YYYY-MM-DD)"
+// @Param to query string true "End date (YYYY-MM-DD), at most 92 days after from"
+// @Success 200 {object} SlotDeletionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/doctors/{id}/slots [delete]
+func (h *ScheduleHandler) DeleteSlots(c *gin.Context) {
+	doctorID, ok := parseDoctorID(c)
+	if !ok {
+		return
+	}
+
+	from, ok := parseRequiredDate(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseRequiredDate(c, "to")
+	if !ok {
+		return
+	}
+	if to.Before(from) || to.Sub(from) > 92*24*time.Hour {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid date range",
+			Message: "to must be on or after from and at most 92 days later",
+		})
+		return
+	}
+
+	deleted, err := h.schedulingService.DeleteUnbookedSlots(doctorID, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		utils.LogError(err, "Failed to delete time slots", map[string]interface{}{
+			"doctor_id": doctorID,
+			"from":      from,
+			"to":        to,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to delete slots",
+			Message: "Unable to delete time slots. Please try again.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SlotDeletionResponse{
+		Success:  true,
+		Message:  fmt.Sprintf("Deleted %d unbooked slots", deleted),
+		DoctorID: doctorID,
+		From:     from.Format("2006-01-02"),
+		To:       to.Format("2006-01-02"),
+		Deleted:  deleted,
+	})
+}
+
+// ReclaimOrphanedSlots handles POST /api/v1/admin/slots/orphans/reclaim
+// @Summary Reclaim orphaned booked slots
+// @Description Make every orphaned BOOKED slot available again and return the slots reclaimed
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} SlotsResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/admin/slots/orphans/reclaim [post]
+func (h *ScheduleHandler) ReclaimOrphanedSlots(c *gin.Context) {
+	slots, err := h.schedulingService.ReclaimOrphanedSlots()
+	if err != nil {
+		utils.LogError(err, "Failed to reclaim orphaned slots", nil)
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to reclaim orphaned slots",
+			Message: "Unable to reclaim orphaned slots. Please try again.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SlotsResponse{
+		Success: true,
+		Message: fmt.Sprintf("Reclaimed %d orphaned slots", len(slots)),
+		Slots:   slots,
+		Total:   len(slots),
+	})
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository/repotest"
+	"smart-doctor-booking-app/services"
+)
+
+// scheduleGridService serves a fixed schedule grid
+type scheduleGridService struct {
+	services.SchedulingService
+	grid []models.ScheduleGridDay
+}
+
+func (s *scheduleGridService) GetScheduleGrid(doctorID uint) ([]models.ScheduleGridDay, error) {
+	return s.grid, nil
+}
+
+func TestGetScheduleICSEmitsWeeklyRecurringWorkingHours(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	schedule := &models.DoctorSchedule{
+		SlotDuration: 30 * time.Minute,
+		Tuesday:      models.WorkingHours{StartTime: "09:00", EndTime: "17:00"},
+	}
+	handler := NewScheduleHandler(&scheduleGridService{grid: models.BuildScheduleGrid(schedule, nil)})
+
+	router := gin.New()
+	router.GET("/doctors/:id/schedule.ics", withDoctor(27, 7), handler.GetScheduleICS)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/doctors/7/schedule.ics", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if contentType := w.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/calendar") {
+		t.Errorf("expected a text/calendar response, got %q", contentType)
+	}
+
+	body := w.Body.String()
+	if count := strings.Count(body, "BEGIN:VEVENT"); count != 1 {
+		t.Fatalf("expected one event for the single working day, got %d:\n%s", count, body)
+	}
+	for _, want := range []string{
+		"RRULE:FREQ=WEEKLY;BYDAY=TU\r\n",
+		"UID:doctor-7-hours-tuesday@smart-doctor-booking\r\n",
+		"T090000\r\n",
+		"T170000\r\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected the calendar to contain %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestStaffScheduleRoutesRefuseOtherDoctors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	schedule := &models.DoctorSchedule{
+		SlotDuration: 30 * time.Minute,
+		Monday:       models.WorkingHours{StartTime: "09:00", EndTime: "12:00"},
+	}
+	handler := NewScheduleHandler(&scheduleGridService{grid: models.BuildScheduleGrid(schedule, nil)})
+
+	routes := []struct {
+		method, path string
+		handle       gin.HandlerFunc
+	}{
+		{http.MethodGet, "/schedule/grid", handler.GetScheduleGrid},
+		{http.MethodGet, "/slots", handler.GetDoctorSlots},
+		{http.MethodPost, "/slots/generate", handler.GenerateWeeklySlots},
+		{http.MethodPost, "/availability-override", handler.AddAvailabilityOverride},
+		{http.MethodGet, "/blocks", handler.GetBlockedPeriods},
+		{http.MethodPost, "/shift", handler.ShiftAppointments},
+	}
+	for _, route := range routes {
+		router := gin.New()
+		router.Handle(route.method, "/doctors/:id"+route.path, withDoctor(28, 8), route.handle)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(route.method, "/doctors/7"+route.path, strings.NewReader("{}")))
+		if w.Code != http.StatusForbidden {
+			t.Errorf("%s %s: expected 403 for another doctor, got %d", route.method, route.path, w.Code)
+		}
+	}
+
+	grid := func(caller gin.HandlerFunc) int {
+		router := gin.New()
+		router.GET("/doctors/:id/schedule/grid", caller, handler.GetScheduleGrid)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/doctors/7/schedule/grid", nil))
+		return w.Code
+	}
+	if code := grid(withDoctor(27, 7)); code != http.StatusOK {
+		t.Errorf("expected 200 for the doctor's own schedule, got %d", code)
+	}
+	if code := grid(withUser(1, "admin")); code != http.StatusOK {
+		t.Errorf("expected 200 for an admin, got %d", code)
+	}
+}
+
+func TestDeleteSlotsKeepsBookedSlots(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := repotest.Open(t)
+	day := repotest.Day(0)
+	appointment := repotest.Appointment(5, 1, day.Add(10*time.Hour), 30, models.StatusScheduled)
+	repotest.MustCreate(t, db,
+		&models.Specialty{ID: 1, Name: "General Practice"},
+		&models.Doctor{ID: 1, Name: "Doctor", SpecialtyID: 1, IsActive: true},
+		&models.Doctor{ID: 2, Name: "Other Doctor", SpecialtyID: 1, IsActive: true},
+		appointment,
+	)
+	booked := repotest.Slot(1, day, 10, 0, 30, models.SlotBooked)
+	booked.AppointmentID = &appointment.ID
+	beyond := repotest.Slot(1, repotest.Day(2), 9, 0, 30, models.SlotAvailable)
+	otherDoctor := repotest.Slot(2, day, 9, 0, 30, models.SlotAvailable)
+	repotest.MustCreate(t, db,
+		repotest.Slot(1, day, 9, 0, 30, models.SlotAvailable),
+		repotest.Slot(1, day, 9, 30, 30, models.SlotBlocked),
+		booked,
+		repotest.Slot(1, repotest.Day(1), 9, 0, 30, models.SlotAvailable),
+		beyond,
+		otherDoctor,
+	)
+	handler := NewScheduleHandler(newTestSchedulingService(db))
+
+	router := gin.New()
+	router.DELETE("/doctors/:id/slots", handler.DeleteSlots)
+	remove := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/doctors/1/slots"+query, nil))
+		return w
+	}
+
+	w := remove("?from=2031-03-03&to=2031-03-04")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body SlotDeletionResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.Deleted != 3 {
+		t.Errorf("expected 3 unbooked slots deleted, got %d", body.Deleted)
+	}
+
+	var remaining []models.TimeSlot
+	if err := db.Order("id").Find(&remaining).Error; err != nil {
+		t.Fatalf("failed to load slots: %v", err)
+	}
+	want := []uint{booked.ID, beyond.ID, otherDoctor.ID}
+	if len(remaining) != len(want) {
+		t.Fatalf("expected %d slots left, got %d", len(want), len(remaining))
+	}
+	for i, id := range want {
+		if remaining[i].ID != id {
+			t.Errorf("expected slot %d to survive, got %d", id, remaining[i].ID)
+		}
+	}
+
+	if w := remove("?from=2031-03-04&to=2031-03-03"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected a reversed range to be rejected with 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/services"
+	"smart-doctor-booking-app/utils"
+)
+
+// StatsHandler handles reporting HTTP requests
+type StatsHandler struct {
+	schedulingService services.SchedulingService
+}
+
+// NewStatsHandler creates a new stats handler
+func NewStatsHandler(schedulingService services.SchedulingService) *StatsHandler {
+	return &StatsHandler{
+		schedulingService: schedulingService,
+	}
+}
+
+// SpecialtyStatsResponse represents appointment demand per specialty
+type SpecialtyStatsResponse struct {
+	Success     bool                    `json:"success"`
+	From        string                  `json:"from"`
+	To          string                  `json:"to"`
+	Specialties []models.SpecialtyStats `json:"specialties"`
+}
+
+// CancellationStatsResponse represents cancellation counts per reason code
+type CancellationStatsResponse struct {
+	Success bool                             `json:"success"`
+	From    string                           `json:"from"`
+	To      string                           `json:"to"`
+	Reasons []models.CancellationReasonStats `json:"reasons"`
+	Total   int                              `json:"total"`
+}
+
+// AISpecialtyStatsResponse compares AI-suggested specialties with the specialties actually booked
+type AISpecialtyStatsResponse struct {
+	Success     bool                           `json:"success"`
+	From        string                         `json:"from"`
+	To          string                         `json:"to"`
+	Comparisons []models.AISpecialtyComparison `json:"comparisons"`
+	Total       int                            `json:"total"`
+	Matched     int                            `json:"matched"`
+	MatchRate   float64                        `json:"match_rate"` // Share of classified bookings made with the suggested specialty
+}
+
+// GetSpecialtyStats handles GET /api/v1/stats/specialties
+// @Summary Get appointment counts per specialty
+// @Description Get booked, completed and cancelled appointment counts grouped by specialty. Defaults to the last 30 days.
+// @Tags stats
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param from query string false "Start date (YYYY-MM-DD), inclusive"
+// @Param to query string false "End date (YYYY-MM-DD), inclusive"
+// @Success 200 {object} SpecialtyStatsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/stats/specialties [get]
+func (h *StatsHandler) GetSpecialtyStats(c *gin.Context) {
+	from, to, ok := parseDateRange(c, 30)
+	if !ok {
+		return
+	}
+
+	stats, err := h.schedulingService.GetSpecialtyStats(from, to.AddDate(0, 0, 1))
+	if err != nil {
+		utils.LogError(err, "Failed to get specialty stats", map[string]interface{}{
+			"from": from,
+			"to":   to,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get stats",
+			Message: "Unable to retrieve specialty statistics. Please try again.",
+		})
+		return
+	}
+
+	if stats == nil {
+		stats = []models.SpecialtyStats{}
+	}
+
+	c.JSON(http.StatusOK, SpecialtyStatsResponse{
+		Success:     true,
+		From:        from.Format("2006-01-02"),
+		To:          to.Format("2006-01-02"),
+		Specialties: stats,
+	})
+}
+
+// GetCancellationStats handles GET /api/v1/stats/cancellations
+// @Summary Get cancellation counts per reason
+// @Description Get the number of cancellations grouped by reason code. Legacy free-text cancellations count as OTHER. Defaults to the last 30 days.
+// @Tags stats
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param from query string false "Start date (YYYY-MM-DD), inclusive"
+// @Param to query string false "End date (YYYY-MM-DD), inclusive"
+// @Success 200 {object} CancellationStatsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/stats/cancellations [get]
+func (h *StatsHandler) GetCancellationStats(c *gin.Context) {
+	from, to, ok := parseDateRange(c, 30)
+	if !ok {
+		return
+	}
+
+	stats, err := h.schedulingService.GetCancellationStats(from, to.AddDate(0, 0, 1))
+	if err != nil {
+		utils.LogError(err, "Failed to get cancellation stats", map[string]interface{}{
+			"from": from,
+			"to":   to,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get stats",
+			Message: "Unable to retrieve cancellation statistics. Please try again.",
+		})
+		return
+	}
+
+	total := 0
+	for _, stat := range stats {
+		total += stat.Count
+	}
+	if stats == nil {
+		stats = []models.CancellationReasonStats{}
+	}
+
+	c.JSON(http.StatusOK, CancellationStatsResponse{
+		Success: true,
+		From:    from.Format("2006-01-02"),
+		To:      to.Format("2006-01-02"),
+		Reasons: stats,
+		Total:   total,
+	})
+}
+
+// GetAISpecialtyStats handles GET /api/v1/stats/ai-specialty
+// @Summary Compare AI-suggested and booked specialties
+// @Description Count symptom-based bookings by the specialty the AI suggested and the specialty of the doctor booked, with the average AI confidence. Defaults to the last 30 days.
+// @Tags stats
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param from query string false "Start date (YYYY-MM-DD), inclusive"
+// @Param to query string false "End date (YYYY-MM-DD), inclusive"
+// @Success 200 {object} AISpecialtyStatsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/stats/ai-specialty [get]
+func (h *StatsHandler) GetAISpecialtyStats(c *gin.Context) {
+	from, to, ok := parseDateRange(c, 30)
+	if !ok {
+		return
+	}
+
+	comparisons, err := h.schedulingService.GetAISpecialtyComparison(from, to.AddDate(0, 0, 1))
+	if err != nil {
+		utils.LogError(err, "Failed to get AI specialty stats", map[string]interface{}{
+			"from": from,
+			"to":   to,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get stats",
+			Message: "Unable to retrieve AI specialty statistics. Please try again.",
+		})
+		return
+	}
+
+	total, matched := 0, 0
+	for _, comparison := range comparisons {
+		total += comparison.Count
+		if comparison.Matched {
+			matched += comparison.Count
+		}
+	}
+	matchRate := 0.0
+	if total > 0 {
+		matchRate = math.Round(float64(matched)/float64(total)*100) / 100
+	}
+	if comparisons == nil {
+		comparisons = []models.AISpecialtyComparison{}
+	}
+
+	c.JSON(http.StatusOK, AISpecialtyStatsResponse{
+		Success:     true,
+		From:        from.Format("2006-01-02"),
+		To:          to.Format("2006-01-02"),
+		Comparisons: comparisons,
+		Total:       total,
+		Matched:     matched,
+		MatchRate:   matchRate,
+	})
+}
+
+// parseDateRange parses optional inclusive from/to YYYY-MM-DD query parameters, defaulting to
+// the last defaultDays days. It writes a 400 response on failure.
+func parseDateRange(c *gin.Context, defaultDays int) (time.Time, time.Time, bool) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	from := today.AddDate(0, 0, -defaultDays)
+	to := today
+
+	for name, target := range map[string]*time.Time{"from": &from, "to": &to} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid " + name + " format",
+				Message: "Please use YYYY-MM-DD format",
+			})
+			return time.Time{}, time.Time{}, false
+		}
+		*target = parsed
+	}
+
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid date range",
+			Message: "to must not be before from",
+		})
+		return time.Time{}, time.Time{}, false
+	}
+
+	return from, to, true
+}
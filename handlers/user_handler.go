@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/utils"
+)
+
+// UserHandler handles user profile HTTP requests
+type UserHandler struct {
+	userRepo            repository.UserRepository
+	notificationLogRepo repository.NotificationLogRepository
+}
+
+// NewUserHandler creates a new user handler
+func NewUserHandler(userRepo repository.UserRepository, notificationLogRepo repository.NotificationLogRepository) *UserHandler {
+	return &UserHandler{
+		userRepo:            userRepo,
+		notificationLogRepo: notificationLogRepo,
+	}
+}
+
+// ProfileResponse represents the authenticated user's profile
+type ProfileResponse struct {
+	Success bool         `json:"success"`
+	User    *models.User `json:"user"`
+}
+
+// ReminderHistoryResponse lists the reminders sent to the authenticated user
+type ReminderHistoryResponse struct {
+	Success   bool                     `json:"success"`
+	From      string                   `json:"from"`
+	To        string                   `json:"to"`
+	Reminders []models.NotificationLog `json:"reminders"`
+	Total     int                      `json:"total"`
+}
+
+// UpdatePreferencesRequest represents the request body for updating notification preferences
+type UpdatePreferencesRequest struct {
+	SMS             *bool  `json:"sms" binding:"required"`
+	Email           *bool  `json:"email" binding:"required"`
+	Push            *bool  `json:"push" binding:"required"`
+	QuietHoursStart string `json:"quiet_hours_start"` // HH:MM
+	QuietHoursEnd   string `json:"quiet_hours_end"`   // HH:MM
+	Language        string `json:"language"`          // Optional, e.g. "en" or "fr"; messages fall back to English
+}
+
+// GetMe handles GET /api/v1/auth/me
+// @Summary Get the current user's profile
+// @Description Get the authenticated user's profile and notification preferences
+// @Tags auth
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} ProfileResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/auth/me [get]
+func (h *UserHandler) GetMe(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, ProfileResponse{
+		Success: true,
+		User:    user,
+	})
+}
+
+// GetMyReminders handles GET /api/v1/users/me/reminders
+// @Summary Get the reminders sent to the current user
+// @Description Get every appointment reminder delivery attempt for the authenticated user's appointments, with its channel and whether it was sent or failed, newest first. Defaults to the last 90 days.
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param from query string false "Start date (YYYY-MM-DD), inclusive"
+// @Param to query string false "End date (YYYY-MM-DD), inclusive"
+// @Success 200 {object} ReminderHistoryResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/users/me/reminders [get]
+func (h *UserHandler) GetMyReminders(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User authentication required",
+		})
+		return
+	}
+
+	from, to, ok := parseDateRange(c, 90)
+	if !ok {
+		return
+	}
+
+	reminders, err := h.notificationLogRepo.GetUserLogs(userID.(uint), models.NotificationReminder, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		utils.LogError(err, "Failed to get reminder history", map[string]interface{}{
+			"user_id": userID,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get reminders",
+			Message: "Unable to retrieve your reminder history. Please try again.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ReminderHistoryResponse{
+		Success:   true,
+		From:      from.Format("2006-01-02"),
+		To:        to.Format("2006-01-02"),
+		Reminders: reminders,
+		Total:     len(reminders),
+	})
+}
+
+// UpdatePreferences handles PUT /api/v1/auth/me/preferences
+// @Summary Update the current user's notification preferences
+// @Description Choose which channels may be used for notifications, an optional quiet hours window and the language messages are written in
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param preferences body UpdatePreferencesRequest true "Notification preferences"
+// @Success 200 {object} ProfileResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/auth/me/preferences [put]
+func (h *UserHandler) UpdatePreferences(c *gin.Context) {
+	var request UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	preferences := models.NotificationPreferences{
+		SMS:             *request.SMS,
+		Email:           *request.Email,
+		Push:            *request.Push,
+		QuietHoursStart: request.QuietHoursStart,
+		QuietHoursEnd:   request.QuietHoursEnd,
+		Language:        request.Language,
+	}
+	if err := preferences.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid preferences",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.userRepo.UpdateNotificationPreferences(user.ID, preferences); err != nil {
+		utils.LogError(err, "Failed to update notification preferences", map[string]interface{}{
+			"user_id": user.ID,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to update preferences",
+			Message: "Unable to save notification preferences. Please try again.",
+		})
+		return
+	}
+
+	user.NotificationPreferences = preferences
+	c.JSON(http.StatusOK, ProfileResponse{
+		Success: true,
+		User:    user,
+	})
+}
+
+// currentUser loads the user identified by the token, writing an error response on failure
+func (h *UserHandler) currentUser(c *gin.Context) (*models.User, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User authentication required",
+		})
+		return nil, false
+	}
+
+	user, err := h.userRepo.GetUserByID(userID.(uint))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "User not found",
+				Message: "No profile exists for the authenticated user",
+			})
+			return nil, false
+		}
+
+		utils.LogError(err, "Failed to get user profile", map[string]interface{}{
+			"user_id": userID,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to get profile",
+			Message: "Unable to retrieve profile. Please try again.",
+		})
+		return nil, false
+	}
+
+	return user, true
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/repository/repotest"
+)
+
+// withUser authenticates every request as userID with role, as the auth middleware would
+func withUser(userID uint, role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Set("role", role)
+		c.Next()
+	}
+}
+
+// withDoctor sets the context values the auth middleware sets for a doctor account acting for doctorID
+func withDoctor(userID, doctorID uint) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("doctor_id", doctorID)
+		withUser(userID, "doctor")(c)
+	}
+}
+
+func TestGetMeReturnsProfileWithoutSensitiveFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := repotest.Open(t)
+	repotest.MustCreate(t, db,
+		&models.User{ID: 1, Username: "other", Name: "Other Patient", Email: "other@example.com", PasswordHash: "hash-1", Role: "user"},
+		&models.User{ID: 2, Username: "grace", Name: "Grace Hopper", Email: "grace@example.com", Phone: "+14155552671",
+			PasswordHash: "$2a$10$secrethash", Role: "user"},
+	)
+	handler := NewUserHandler(repository.NewUserRepository(db), repository.NewNotificationLogRepository(db))
+
+	router := gin.New()
+	router.GET("/auth/me", withUser(2, "user"), handler.GetMe)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/me", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "secrethash") || strings.Contains(w.Body.String(), "password") {
+		t.Errorf("expected the password hash to be omitted, got %s", w.Body.String())
+	}
+
+	var body struct {
+		User map[string]interface{} `json:"user"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.User["id"] != float64(2) || body.User["email"] != "grace@example.com" || body.User["phone"] != "+14155552671" {
+		t.Errorf("expected Grace's profile, got %v", body.User)
+	}
+	if _, ok := body.User["notification_preferences"]; !ok {
+		t.Errorf("expected notification preferences in the profile, got %v", body.User)
+	}
+	for _, field := range []string{"password_hash", "PasswordHash", "deleted_at"} {
+		if _, ok := body.User[field]; ok {
+			t.Errorf("expected %s to be omitted from the profile", field)
+		}
+	}
+}
+
+func TestGetMyRemindersListsOnlyRemindersInRange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := repotest.Open(t)
+	at := func(month time.Month, day int) time.Time { return time.Date(2031, month, day, 8, 0, 0, 0, time.UTC) }
+	logs := []*models.NotificationLog{
+		{AppointmentID: 1, UserID: 2, Kind: models.NotificationReminder, Channel: models.ReminderSMS, Status: models.NotificationSent, CreatedAt: at(time.January, 10)},
+		{AppointmentID: 2, UserID: 2, Kind: models.NotificationReminder, Channel: models.ReminderEmail, Status: models.NotificationFailed, CreatedAt: at(time.January, 31)},
+		{AppointmentID: 2, UserID: 2, Kind: models.NotificationConfirmation, Channel: models.ReminderSMS, Status: models.NotificationSent, CreatedAt: at(time.January, 15)},
+		{AppointmentID: 3, UserID: 2, Kind: models.NotificationReminder, Channel: models.ReminderSMS, Status: models.NotificationSent, CreatedAt: time.Date(2030, time.December, 31, 8, 0, 0, 0, time.UTC)},
+		{AppointmentID: 4, UserID: 2, Kind: models.NotificationReminder, Channel: models.ReminderSMS, Status: models.NotificationSent, CreatedAt: at(time.February, 1)},
+		{AppointmentID: 5, UserID: 1, Kind: models.NotificationReminder, Channel: models.ReminderSMS, Status: models.NotificationSent, CreatedAt: at(time.January, 12)},
+	}
+	for _, log := range logs {
+		repotest.MustCreate(t, db, log)
+	}
+	handler := NewUserHandler(repository.NewUserRepository(db), repository.NewNotificationLogRepository(db))
+
+	router := gin.New()
+	router.GET("/users/me/reminders", withUser(2, "user"), handler.GetMyReminders)
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/me/reminders"+query, nil))
+		return w
+	}
+
+	w := get("?from=2031-01-01&to=2031-01-31")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body ReminderHistoryResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.Total != 2 || len(body.Reminders) != 2 {
+		t.Fatalf("expected the 2 reminders sent to the user in January, got %d", body.Total)
+	}
+	if body.Reminders[0].AppointmentID != 2 || body.Reminders[1].AppointmentID != 1 {
+		t.Errorf("expected the reminders newest first, got appointments %d and %d", body.Reminders[0].AppointmentID, body.Reminders[1].AppointmentID)
+	}
+	if body.Reminders[0].Status != models.NotificationFailed {
+		t.Errorf("expected the failed reminder to be reported as failed, got %s", body.Reminders[0].Status)
+	}
+
+	if w := get("?from=2031-02-01&to=2031-01-01"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected a reversed range to be rejected with 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/services"
+	"smart-doctor-booking-app/utils"
+)
+
+// WaitlistHandler handles waitlist HTTP requests
+type WaitlistHandler struct {
+	waitlistService services.WaitlistService
+}
+
+// NewWaitlistHandler creates a new waitlist handler
+func NewWaitlistHandler(waitlistService services.WaitlistService) *WaitlistHandler {
+	return &WaitlistHandler{
+		waitlistService: waitlistService,
+	}
+}
+
+// JoinWaitlistRequest represents the request body for joining a doctor's waitlist
+type JoinWaitlistRequest struct {
+	DoctorID      uint   `json:"doctor_id" binding:"required"`
+	PreferredDate string `json:"preferred_date"` // Optional YYYY-MM-DD
+	Duration      int    `json:"duration" binding:"omitempty,min=15,max=180"`
+}
+
+// WaitlistEntryResponse represents a waitlist entry
+type WaitlistEntryResponse struct {
+	Success bool                  `json:"success"`
+	Message string                `json:"message"`
+	Entry   *models.WaitlistEntry `json:"entry"`
+}
+
+// JoinWaitlist handles POST /api/v1/waitlist
+// @Summary Join a doctor's waitlist
+// @Description Queue for the next opening with a doctor. When a slot frees up, the first patient in the queue is offered it for a limited time.
+// @Tags waitlist
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body JoinWaitlistRequest true "Waitlist details"
+// @Success 201 {object} WaitlistEntryResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse "Waitlist feature disabled"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/waitlist [post]
+func (h *WaitlistHandler) JoinWaitlist(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User authentication required",
+		})
+		return
+	}
+
+	var request JoinWaitlistRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	entry := &models.WaitlistEntry{
+		UserID:   userID.(uint),
+		DoctorID: request.DoctorID,
+		Duration: request.Duration,
+	}
+	if entry.Duration == 0 {
+		entry.Duration = 30
+	}
+	if request.PreferredDate != "" {
+		date, err := time.Parse("2006-01-02", request.PreferredDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid date format",
+				Message: "Please use YYYY-MM-DD format",
+			})
+			return
+		}
+		entry.PreferredDate = &date
+	}
+
+	if err := h.waitlistService.JoinWaitlist(entry); err != nil {
+		utils.LogError(err, "Failed to join waitlist", map[string]interface{}{
+			"user_id":   entry.UserID,
+			"doctor_id": entry.DoctorID,
+		})
+		respondServerError(c, err, ErrorResponse{
+			Error:   "Failed to join waitlist",
+			Message: "Unable to join the waitlist. Please try again.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, WaitlistEntryResponse{
+		Success: true,
+		Message: "Added to waitlist",
+		Entry:   entry,
+	})
+}
+
+// BookEntry handles POST /api/v1/waitlist/:id/book
+// @Summary Book a waitlist entry's offered slot
+// @Description Turn the patient's waitlist entry into an appointment for the slot currently offered to it. The slot is booked with the same checks as any other booking, and the conflict check, booking and removal from the waitlist happen atomically; if the slot was taken in the meantime the patient keeps their place in the queue.
+// @Tags waitlist
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Waitlist entry ID"
+// @Success 201 {object} BookingResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 410 {object} ErrorResponse
+// @Router /api/v1/waitlist/{id}/book [post]
+func (h *WaitlistHandler) BookEntry(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User authentication required",
+		})
+		return
+	}
+
+	entryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid waitlist entry ID",
+			Message: "Waitlist entry ID must be a valid number",
+		})
+		return
+	}
+
+	appointment, err := h.waitlistService.BookEntry(c.Request.Context(), uint(entryID), userID.(uint))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrOfferExpired):
+			c.JSON(http.StatusGone, ErrorResponse{
+				Error:   "No open offer",
+				Message: "This waitlist entry has no open offer to book",
+			})
+		case errors.Is(err, services.ErrEntryNotOwned):
+			c.JSON(http.StatusForbidden, ErrorResponse{
+				Error:   "Forbidden",
+				Message: "This waitlist entry belongs to another patient",
+			})
+		case strings.Contains(err.Error(), "not found"):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Waitlist entry not found",
+				Message: "No waitlist entry matches this ID",
+			})
+		default:
+			utils.LogError(err, "Failed to book waitlist entry", map[string]interface{}{
+				"entry_id": entryID,
+				"user_id":  userID,
+			})
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "Booking failed",
+				Message: "The offered slot is no longer available. You keep your place on the waitlist.",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, BookingResponse{
+		Success:     true,
+		Message:     "Waitlist entry booked",
+		Appointment: appointment,
+	})
+}
+
+// AcceptOffer handles POST /api/v1/waitlist/offers/:token/accept
+// @Summary Accept a waitlist offer
+// @Description Book the slot offered to the patient, provided the offer has not expired
+// @Tags waitlist
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param token path string true "Offer token"
+// @Success 201 {object} BookingResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 410 {object} ErrorResponse
+// @Router /api/v1/waitlist/offers/{token}/accept [post]
+func (h *WaitlistHandler) AcceptOffer(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User authentication required",
+		})
+		return
+	}
+
+	appointment, err := h.waitlistService.AcceptOffer(c.Request.Context(), c.Param("token"), userID.(uint))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrOfferExpired):
+			c.JSON(http.StatusGone, ErrorResponse{
+				Error:   "Offer expired",
+				Message: "This offer has expired or was already used",
+			})
+		case errors.Is(err, services.ErrOfferNotOwned):
+			c.JSON(http.StatusForbidden, ErrorResponse{
+				Error:   "Forbidden",
+				Message: "This offer was made to another patient",
+			})
+		case strings.Contains(err.Error(), "not found"):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Offer not found",
+				Message: "No waitlist offer matches this token",
+			})
+		default:
+			utils.LogError(err, "Failed to accept waitlist offer", map[string]interface{}{
+				"user_id": userID,
+			})
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "Booking failed",
+				Message: "The offered slot is no longer available. You keep your place on the waitlist.",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, BookingResponse{
+		Success:     true,
+		Message:     "Waitlist offer accepted and appointment booked",
+		Appointment: appointment,
+	})
+}
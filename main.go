@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"os"
 
 	"smart-doctor-booking-app/config"
@@ -32,6 +33,16 @@ func main() {
 		"operation": "database_connection",
 	})
 
+	// Verify dependencies and configuration before serving
+	report := config.SelfCheck(db.DB, config.SelfCheckOptionsFromEnv())
+	report.Log()
+	if report.Fatal() {
+		utils.LogFatal(errors.New("startup self-check failed"), "Refusing to start with fatal misconfiguration", logrus.Fields{
+			"component": "main",
+			"operation": "self_check",
+		})
+	}
+
 	// Setup routes
 	router := routes.SetupRoutes(db.DB)
 
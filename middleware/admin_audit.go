@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/utils"
+)
+
+// AdminAudit records successful write operations (POST, PUT, PATCH, DELETE) made by admins.
+// It must run after AuthMiddleware so the caller's role and user ID are available.
+func AdminAudit(auditRepo repository.AdminAuditRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			return
+		}
+		if c.GetString("role") != "admin" || c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+
+		targets := make([]string, 0, len(c.Params))
+		for _, param := range c.Params {
+			targets = append(targets, param.Key+"="+param.Value)
+		}
+
+		audit := &models.AdminAudit{
+			AdminID:    c.GetUint("user_id"),
+			Action:     c.Request.Method + " " + c.FullPath(),
+			Target:     strings.Join(targets, ","),
+			StatusCode: c.Writer.Status(),
+			RequestID:  GetRequestID(c),
+		}
+		if err := auditRepo.CreateAudit(audit); err != nil {
+			utils.LogError(err, "Failed to record admin audit", map[string]interface{}{
+				"admin_id": audit.AdminID,
+				"action":   audit.Action,
+				"target":   audit.Target,
+			})
+		}
+	}
+}
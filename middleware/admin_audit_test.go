@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/repository/repotest"
+)
+
+func TestAdminAuditRecordsDoctorDeletion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := repotest.Open(t)
+	auditRepo := repository.NewAdminAuditRepository(db)
+
+	router := gin.New()
+	router.Use(RequestIDMiddleware(), func(c *gin.Context) {
+		c.Set("user_id", uint(9))
+		c.Set("role", c.GetHeader("X-Test-Role"))
+		c.Next()
+	}, AdminAudit(auditRepo))
+	router.DELETE("/api/v1/doctors/:id", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.GET("/api/v1/doctors/:id", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	send := func(method, role string) {
+		req := httptest.NewRequest(method, "/api/v1/doctors/42", nil)
+		req.Header.Set("X-Test-Role", role)
+		req.Header.Set(RequestIDHeader, "req-audit")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	send(http.MethodDelete, "admin")
+	// Neither reads nor writes by other roles are audited
+	send(http.MethodGet, "admin")
+	send(http.MethodDelete, "doctor")
+
+	audits, total, err := auditRepo.GetAudits(10, 0)
+	if err != nil {
+		t.Fatalf("GetAudits returned error: %v", err)
+	}
+	if total != 1 || len(audits) != 1 {
+		t.Fatalf("expected a single audit entry, got %d", total)
+	}
+
+	audit := audits[0]
+	if audit.AdminID != 9 || audit.Action != "DELETE /api/v1/doctors/:id" || audit.Target != "id=42" {
+		t.Errorf("unexpected audit entry: %+v", audit)
+	}
+	if audit.StatusCode != http.StatusOK || audit.RequestID != "req-audit" {
+		t.Errorf("expected status 200 and request ID req-audit, got %d and %q", audit.StatusCode, audit.RequestID)
+	}
+}
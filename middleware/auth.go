@@ -10,11 +10,19 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+const (
+	// defaultJWTAccessTTL is how long tokens stay valid when JWT_ACCESS_TTL is unset
+	defaultJWTAccessTTL = 24 * time.Hour
+	// defaultJWTIssuer is the issuer claim used when JWT_ISSUER is unset
+	defaultJWTIssuer = "smart-doctor-booking-app"
+)
+
 // Claims represents the JWT claims
 type Claims struct {
 	UserID   uint   `json:"user_id"`
 	Username string `json:"username"`
 	Role     string `json:"role"`
+	DoctorID uint   `json:"doctor_id,omitempty"` // Doctor record a doctor account acts for; 0 for other roles
 	jwt.RegisteredClaims
 }
 
@@ -56,7 +64,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		// Parse and validate token
 		token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 			return []byte(jwtSecret), nil
-		})
+		}, jwtParserOptions()...)
 
 		if err != nil || !token.Valid {
 			c.JSON(http.StatusUnauthorized, gin.H{
@@ -80,13 +88,41 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("role", claims.Role)
+		c.Set("doctor_id", claims.DoctorID)
+		bindRequestLogger(c)
 
 		c.Next()
 	}
 }
 
-// GenerateToken creates a new JWT token
-func GenerateToken(userID uint, username, role string) (string, error) {
+// jwtAccessTTL returns how long issued tokens stay valid, from JWT_ACCESS_TTL (e.g. "30m")
+func jwtAccessTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("JWT_ACCESS_TTL")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return defaultJWTAccessTTL
+}
+
+// jwtIssuer returns the issuer claim set on and required of every token, from JWT_ISSUER
+func jwtIssuer() string {
+	if issuer := os.Getenv("JWT_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return defaultJWTIssuer
+}
+
+// jwtParserOptions requires tokens to carry our issuer and, when JWT_AUDIENCE is set, that audience
+func jwtParserOptions() []jwt.ParserOption {
+	options := []jwt.ParserOption{jwt.WithIssuer(jwtIssuer())}
+	if audience := os.Getenv("JWT_AUDIENCE"); audience != "" {
+		options = append(options, jwt.WithAudience(audience))
+	}
+	return options
+}
+
+// GenerateToken creates a new JWT token. doctorID is the doctor record a doctor account acts for,
+// or 0 for other roles.
+func GenerateToken(userID uint, username, role string, doctorID uint) (string, error) {
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
 		return "", jwt.ErrInvalidKey
@@ -97,12 +133,17 @@ func GenerateToken(userID uint, username, role string) (string, error) {
 		UserID:   userID,
 		Username: username,
 		Role:     role,
+		DoctorID: doctorID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			Issuer:    jwtIssuer(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(jwtAccessTTL())),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
+	if audience := os.Getenv("JWT_AUDIENCE"); audience != "" {
+		claims.Audience = jwt.ClaimStrings{audience}
+	}
 
 	// Create token
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -132,13 +173,15 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 			if jwtSecret != "" {
 				token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 					return []byte(jwtSecret), nil
-				})
+				}, jwtParserOptions()...)
 
 				if err == nil && token.Valid {
 					if claims, ok := token.Claims.(*Claims); ok {
 						c.Set("user_id", claims.UserID)
 						c.Set("username", claims.Username)
 						c.Set("role", claims.Role)
+						c.Set("doctor_id", claims.DoctorID)
+						bindRequestLogger(c)
 					}
 				}
 			}
@@ -147,3 +190,29 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RequireRole restricts access to users whose role (set by AuthMiddleware) is one of the allowed roles
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, exists := c.Get("role")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Authorization required",
+			})
+			c.Abort()
+			return
+		}
+
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Insufficient permissions",
+		})
+		c.Abort()
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestAuthMiddlewareRequiresIssuerAndAudience(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const secret = "auth-test-secret-0123456789abcdef"
+	t.Setenv("JWT_SECRET", secret)
+
+	issue := func(issuer, audience string) string {
+		t.Helper()
+		t.Setenv("JWT_ISSUER", issuer)
+		t.Setenv("JWT_AUDIENCE", audience)
+		token, err := GenerateToken(42, "grace", "user", 0)
+		if err != nil {
+			t.Fatalf("GenerateToken returned error: %v", err)
+		}
+		return token
+	}
+	// A token signed with the right secret but no issuer claim at all
+	unissued, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+		UserID: 42, Username: "grace", Role: "user",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{"our issuer and audience", issue("clinic-a", "mobile"), http.StatusOK},
+		{"other issuer", issue("clinic-b", "mobile"), http.StatusUnauthorized},
+		{"other audience", issue("clinic-a", "web"), http.StatusUnauthorized},
+		{"no issuer", unissued, http.StatusUnauthorized},
+	}
+
+	t.Setenv("JWT_ISSUER", "clinic-a")
+	t.Setenv("JWT_AUDIENCE", "mobile")
+	router := gin.New()
+	router.GET("/private", AuthMiddleware(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/private", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestGenerateTokenUsesConfiguredTTL(t *testing.T) {
+	t.Setenv("JWT_SECRET", "auth-test-secret-0123456789abcdef")
+	t.Setenv("JWT_ACCESS_TTL", "30m")
+
+	tokenString, err := GenerateToken(42, "grace", "user", 0)
+	if err != nil {
+		t.Fatalf("GenerateToken returned error: %v", err)
+	}
+	claims := &Claims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, claims); err != nil {
+		t.Fatalf("failed to parse token: %v", err)
+	}
+
+	if claims.Issuer != defaultJWTIssuer {
+		t.Errorf("expected the default issuer %q, got %q", defaultJWTIssuer, claims.Issuer)
+	}
+	if ttl := time.Until(claims.ExpiresAt.Time); ttl > 30*time.Minute || ttl < 29*time.Minute {
+		t.Errorf("expected the token to expire in 30 minutes, got %v", ttl)
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"smart-doctor-booking-app/services"
+)
+
+// RequireFeature hides a route behind a feature flag, responding 404 while the flag is off
+func RequireFeature(flags services.FeatureFlags, flag services.FeatureFlag) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !flags.IsEnabled(c.Request.Context(), flag) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Feature disabled",
+				"message": "This feature is not currently available",
+				"feature": flag,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"smart-doctor-booking-app/services"
+)
+
+func TestRequireFeatureHidesDisabledEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	flags := services.NewFeatureFlags(services.DefaultFeatureFlagsConfig(), nil)
+
+	router := gin.New()
+	router.GET("/waitlist", RequireFeature(flags, services.FlagWaitlist), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	get := func() int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/waitlist", nil))
+		return w.Code
+	}
+
+	if code := get(); code != http.StatusNotFound {
+		t.Errorf("expected 404 while the flag is off by default, got %d", code)
+	}
+
+	if err := flags.SetEnabled(context.Background(), services.FlagWaitlist, true); err != nil {
+		t.Fatalf("SetEnabled returned error: %v", err)
+	}
+	if code := get(); code != http.StatusOK {
+		t.Errorf("expected 200 once the flag is on, got %d", code)
+	}
+
+	if err := flags.SetEnabled(context.Background(), services.FlagWaitlist, false); err != nil {
+		t.Fatalf("SetEnabled returned error: %v", err)
+	}
+	if code := get(); code != http.StatusNotFound {
+		t.Errorf("expected 404 after turning the flag off again, got %d", code)
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Recovery recovers from panics in downstream handlers, logs them with the request ID and
+// stack trace through the structured logger, and returns a consistent JSON error response
+func Recovery(logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				requestID := GetRequestID(c)
+
+				logger.WithError(fmt.Errorf("panic: %v", r)).WithFields(logrus.Fields{
+					"request_id": requestID,
+					"method":     c.Request.Method,
+					"path":       c.Request.URL.Path,
+					"stack":      string(debug.Stack()),
+				}).Error("Panic recovered while handling request")
+
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"error":      "Internal Server Error",
+					"message":    "An unexpected error occurred",
+					"request_id": requestID,
+				})
+			}
+		}()
+
+		c.Next()
+	}
+}
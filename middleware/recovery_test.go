@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func TestRecoveryReturnsStructuredErrorWithRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	router := gin.New()
+	router.Use(RequestIDMiddleware(), Recovery(logger))
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set(RequestIDHeader, "req-123")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d", w.Code)
+	}
+
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("expected a JSON body, got %q: %v", w.Body.String(), err)
+	}
+	if body["error"] != "Internal Server Error" || body["message"] == "" {
+		t.Errorf("unexpected error body %v", body)
+	}
+	if body["request_id"] != "req-123" {
+		t.Errorf("expected request_id req-123, got %q", body["request_id"])
+	}
+	if got := w.Header().Get(RequestIDHeader); got != "req-123" {
+		t.Errorf("expected the request ID header to be kept, got %q", got)
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"smart-doctor-booking-app/utils"
+)
+
+// RequestLoggerMiddleware stores a logger bound to the request ID in the request's context.Context.
+// The auth middlewares rebind it with the user_id once the caller is authenticated, so handlers and
+// services logging through utils.LoggerFrom get both fields without passing them by hand.
+// It must run after RequestIDMiddleware.
+func RequestLoggerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bindRequestLogger(c)
+		c.Next()
+	}
+}
+
+// bindRequestLogger stores a logger carrying the request_id and, when authenticated, the user_id
+func bindRequestLogger(c *gin.Context) {
+	fields := logrus.Fields{}
+	if requestID := GetRequestID(c); requestID != "" {
+		fields["request_id"] = requestID
+	}
+	if userID, exists := c.Get("user_id"); exists {
+		fields["user_id"] = userID
+	}
+
+	entry := logrus.NewEntry(utils.GetLogger()).WithFields(fields)
+	c.Request = c.Request.WithContext(utils.ContextWithLogger(c.Request.Context(), entry))
+}
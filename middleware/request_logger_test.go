@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+
+	"smart-doctor-booking-app/utils"
+)
+
+func TestRequestLoggerCarriesUserAndRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", "request-logger-test-secret-0123456789")
+	token, err := GenerateToken(42, "grace", "user", 0)
+	if err != nil {
+		t.Fatalf("GenerateToken returned error: %v", err)
+	}
+
+	logger := utils.GetLogger()
+	hooks := make(logrus.LevelHooks)
+	for level, levelHooks := range logger.Hooks {
+		hooks[level] = append(hooks[level], levelHooks...)
+	}
+	defer logger.ReplaceHooks(hooks)
+	hook := &test.Hook{}
+	logger.AddHook(hook)
+
+	// Neither handler passes user_id or request_id itself
+	logs := func(c *gin.Context) {
+		utils.LogInfoContext(c.Request.Context(), "handled", logrus.Fields{"appointment_id": 7})
+		c.Status(http.StatusOK)
+	}
+	router := gin.New()
+	router.Use(RequestIDMiddleware(), RequestLoggerMiddleware())
+	router.GET("/private", AuthMiddleware(), logs)
+	router.GET("/public", logs)
+
+	tests := []struct {
+		path     string
+		token    string
+		wantUser interface{}
+	}{
+		{"/private", token, uint(42)},
+		{"/public", "", nil},
+	}
+	for _, tt := range tests {
+		hook.Reset()
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Header.Set(RequestIDHeader, "req-"+tt.path[1:])
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", tt.path, w.Code)
+		}
+
+		entry := hook.LastEntry()
+		if entry == nil || entry.Message != "handled" {
+			t.Fatalf("%s: expected the handler's log entry, got %+v", tt.path, entry)
+		}
+		if entry.Data["request_id"] != "req-"+tt.path[1:] || entry.Data["appointment_id"] != 7 {
+			t.Errorf("%s: expected the request ID and handler fields, got %v", tt.path, entry.Data)
+		}
+		if got := entry.Data["user_id"]; got != tt.wantUser {
+			t.Errorf("%s: expected user_id %v, got %v", tt.path, tt.wantUser, got)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// AdminAudit records a write operation performed by an administrator
+type AdminAudit struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	AdminID    uint      `json:"admin_id" gorm:"not null;index"`
+	Action     string    `json:"action" gorm:"type:varchar(120);not null"` // e.g. "DELETE /api/v1/doctors/:id"
+	Target     string    `json:"target" gorm:"type:varchar(255)"`          // Path parameters, e.g. "id=42"
+	StatusCode int       `json:"status_code"`
+	RequestID  string    `json:"request_id" gorm:"type:varchar(64)"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
+
+// TableName specifies the table name for the AdminAudit model
+func (AdminAudit) TableName() string {
+	return "admin_audits"
+}
@@ -1,6 +1,10 @@
 package models
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -16,6 +20,8 @@ const (
 	StatusNoShow      AppointmentStatus = "NO_SHOW"
 	StatusRescheduled AppointmentStatus = "RESCHEDULED"
 	StatusConfirmed   AppointmentStatus = "CONFIRMED"
+	// StatusPendingPayment holds the slot until the patient pays the required deposit
+	StatusPendingPayment AppointmentStatus = "PENDING_PAYMENT"
 )
 
 // AppointmentType represents the type of appointment
@@ -28,6 +34,26 @@ const (
 	TypeEmergency    AppointmentType = "EMERGENCY"
 )
 
+// appointmentTypeDurations holds the shortest and longest duration in minutes allowed for each type
+var appointmentTypeDurations = map[AppointmentType][2]int{
+	TypeConsultation: {15, 60},
+	TypeFollowUp:     {15, 30},
+	TypeCheckup:      {30, 60},
+	TypeEmergency:    {15, 180},
+}
+
+// IsValid reports whether the type is one of the known appointment types
+func (t AppointmentType) IsValid() bool {
+	_, ok := appointmentTypeDurations[t]
+	return ok
+}
+
+// DurationLimits returns the shortest and longest duration in minutes allowed for the type
+func (t AppointmentType) DurationLimits() (minMinutes, maxMinutes int) {
+	limits := appointmentTypeDurations[t]
+	return limits[0], limits[1]
+}
+
 // ReminderType represents the type of reminder
 type ReminderType string
 
@@ -35,8 +61,47 @@ const (
 	ReminderSMS   ReminderType = "SMS"
 	ReminderEmail ReminderType = "EMAIL"
 	ReminderPush  ReminderType = "PUSH"
+	ReminderVoice ReminderType = "VOICE"
+)
+
+// IsValid reports whether the type is one of the known reminder types
+func (t ReminderType) IsValid() bool {
+	switch t {
+	case ReminderSMS, ReminderEmail, ReminderPush, ReminderVoice:
+		return true
+	}
+	return false
+}
+
+// CancellationReason is the coded reason an appointment was cancelled
+type CancellationReason string
+
+const (
+	CancellationPatientRequest    CancellationReason = "PATIENT_REQUEST"
+	CancellationDoctorUnavailable CancellationReason = "DOCTOR_UNAVAILABLE"
+	CancellationClinicClosure     CancellationReason = "CLINIC_CLOSURE"
+	CancellationNoShow            CancellationReason = "NO_SHOW"
+	CancellationOther             CancellationReason = "OTHER"
 )
 
+// IsValid reports whether the reason is one of the known cancellation reason codes
+func (r CancellationReason) IsValid() bool {
+	switch r {
+	case CancellationPatientRequest, CancellationDoctorUnavailable, CancellationClinicClosure, CancellationNoShow, CancellationOther:
+		return true
+	}
+	return false
+}
+
+// CancellationReasonFromText maps legacy free-text reasons to a reason code. Text that already
+// names a code (in any case) maps to that code; anything else maps to OTHER.
+func CancellationReasonFromText(text string) CancellationReason {
+	if reason := CancellationReason(strings.ToUpper(strings.TrimSpace(text))); reason.IsValid() {
+		return reason
+	}
+	return CancellationOther
+}
+
 // Appointment represents an appointment in the system
 type Appointment struct {
 	ID              uint              `json:"id" gorm:"primaryKey"`
@@ -65,16 +130,35 @@ type Appointment struct {
 	ReminderTime    int          `json:"reminder_time" gorm:"default:60"` // Minutes before appointment
 	ReminderSent    bool         `json:"reminder_sent" gorm:"default:false"`
 	ReminderSentAt  *time.Time   `json:"reminder_sent_at"`
+	ContactPhone    string       `json:"contact_phone,omitempty" gorm:"type:varchar(16)"` // E.164, used for SMS and voice reminders
+
+	// Clinic labels such as "first-visit" or "insurance-pending"
+	Tags []string `json:"tags" gorm:"type:jsonb;serializer:json"`
+
+	// Symptom-based booking: what the patient described and how the AI classified it
+	Symptom       string   `json:"symptom,omitempty" gorm:"type:text"`
+	AISpecialtyID *uint    `json:"ai_specialty_id,omitempty" gorm:"index"`
+	AIConfidence  *float64 `json:"ai_confidence,omitempty"`
+
+	// Location where the appointment takes place
+	LocationID *uint     `json:"location_id" gorm:"index"`
+	Location   *Location `json:"location,omitempty" gorm:"foreignKey:LocationID"`
 
 	// Confirmation
 	ConfirmationRequired bool       `json:"confirmation_required" gorm:"default:false"`
 	ConfirmedAt          *time.Time `json:"confirmed_at"`
 	ConfirmedBy          string     `json:"confirmed_by" gorm:"type:varchar(20)"` // 'PATIENT' or 'DOCTOR'
 
+	// Deposit hold
+	DepositRequired bool       `json:"deposit_required" gorm:"default:false"`
+	HoldExpiresAt   *time.Time `json:"hold_expires_at,omitempty"` // Pending bookings are released after this time
+	DepositPaidAt   *time.Time `json:"deposit_paid_at,omitempty"`
+
 	// Cancellation
-	CancelledAt        *time.Time `json:"cancelled_at"`
-	CancelledBy        string     `json:"cancelled_by" gorm:"type:varchar(20)"` // 'PATIENT' or 'DOCTOR'
-	CancellationReason string     `json:"cancellation_reason" gorm:"type:text"`
+	CancelledAt        *time.Time         `json:"cancelled_at"`
+	CancelledBy        string             `json:"cancelled_by" gorm:"type:varchar(20)"` // 'PATIENT' or 'DOCTOR'
+	CancellationCode   CancellationReason `json:"cancellation_code,omitempty" gorm:"type:varchar(30);index"`
+	CancellationReason string             `json:"cancellation_reason" gorm:"type:text"` // Optional free-text detail
 
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
@@ -91,3 +175,288 @@ type Appointment struct {
 func (Appointment) TableName() string {
 	return "appointments"
 }
+
+// MaxAppointmentTags is the most tags one appointment can carry
+const MaxAppointmentTags = 10
+
+// ErrInvalidTag is returned for a tag that is empty, too long or not made of letters, digits and hyphens
+var ErrInvalidTag = errors.New("tags must be 1-32 letters, digits or hyphens")
+
+// NormalizeTags lowercases and trims tags, dropping duplicates while keeping their order
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || len(tag) > 32 || strings.Trim(tag, "abcdefghijklmnopqrstuvwxyz0123456789-") != "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidTag, tag)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	if len(normalized) > MaxAppointmentTags {
+		return nil, fmt.Errorf("at most %d tags per appointment", MaxAppointmentTags)
+	}
+	return normalized, nil
+}
+
+// HasTag reports whether the appointment carries the tag, ignoring case
+func (a *Appointment) HasTag(tag string) bool {
+	for _, t := range a.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterAppointmentsByTag keeps the appointments carrying the tag; an empty tag keeps them all
+func FilterAppointmentsByTag(appointments []Appointment, tag string) []Appointment {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return appointments
+	}
+
+	filtered := make([]Appointment, 0, len(appointments))
+	for i := range appointments {
+		if appointments[i].HasTag(tag) {
+			filtered = append(filtered, appointments[i])
+		}
+	}
+	return filtered
+}
+
+// AppointmentChange is an appointment as returned by the sync API, flagged when it has been deleted
+type AppointmentChange struct {
+	Appointment
+	Deleted   bool      `json:"deleted"`
+	ChangedAt time.Time `json:"changed_at"` // Latest of updated_at and the deletion time
+}
+
+// NewAppointmentChange wraps an appointment loaded with soft-deleted rows included
+func NewAppointmentChange(appointment Appointment) AppointmentChange {
+	change := AppointmentChange{Appointment: appointment, ChangedAt: appointment.UpdatedAt}
+	if appointment.DeletedAt.Valid {
+		change.Deleted = true
+		if appointment.DeletedAt.Time.After(change.ChangedAt) {
+			change.ChangedAt = appointment.DeletedAt.Time
+		}
+	}
+	return change
+}
+
+// ReminderStatus represents where a scheduled reminder stands
+type ReminderStatus string
+
+const (
+	ReminderPending   ReminderStatus = "PENDING"
+	ReminderDelivered ReminderStatus = "SENT"
+	ReminderDisabled  ReminderStatus = "DISABLED"
+	// ReminderCancelled marks a reminder that will not be sent because the appointment is no longer active
+	ReminderCancelled ReminderStatus = "CANCELLED"
+)
+
+// ScheduledReminder is one reminder planned for an appointment
+type ScheduledReminder struct {
+	OffsetMinutes int            `json:"offset_minutes"` // Minutes before the appointment
+	Channel       ReminderType   `json:"channel"`
+	ScheduledFor  time.Time      `json:"scheduled_for"`
+	Status        ReminderStatus `json:"status"`
+	SentAt        *time.Time     `json:"sent_at,omitempty"`
+}
+
+// ReminderSchedule returns the reminders planned for the appointment with their delivery status
+func (a *Appointment) ReminderSchedule() []ScheduledReminder {
+	reminder := ScheduledReminder{
+		OffsetMinutes: a.ReminderTime,
+		Channel:       a.ReminderType,
+		ScheduledFor:  a.AppointmentTime.Add(-time.Duration(a.ReminderTime) * time.Minute),
+		Status:        ReminderPending,
+		SentAt:        a.ReminderSentAt,
+	}
+
+	switch {
+	case a.ReminderSent:
+		reminder.Status = ReminderDelivered
+	case !a.ReminderEnabled:
+		reminder.Status = ReminderDisabled
+	case a.Status == StatusCancelled || a.Status == StatusRescheduled || a.Status == StatusCompleted || a.Status == StatusNoShow:
+		reminder.Status = ReminderCancelled
+	}
+
+	return []ScheduledReminder{reminder}
+}
+
+// ErrLocationMismatch is returned when booking at a location the doctor is not working from at that time
+var ErrLocationMismatch = errors.New("doctor is not at the requested location at this time")
+
+// ErrDoctorNotAcceptingBookings is returned when booking a doctor who has paused new bookings
+var ErrDoctorNotAcceptingBookings = errors.New("doctor is not accepting new bookings at the moment")
+
+// ErrEndTimeMismatch is returned when an appointment's end time is not its start time plus its duration
+var ErrEndTimeMismatch = errors.New("end time must equal appointment time plus duration")
+
+// CheckEndTime fills in a missing EndTime from AppointmentTime and Duration and rejects one that
+// disagrees with them, so the three fields can never be stored out of step
+func (a *Appointment) CheckEndTime() error {
+	if a.Duration <= 0 {
+		return errors.New("duration must be positive")
+	}
+
+	expected := a.AppointmentTime.Add(time.Duration(a.Duration) * time.Minute)
+	if a.EndTime.IsZero() {
+		a.EndTime = expected
+		return nil
+	}
+	if !a.EndTime.Equal(expected) {
+		return ErrEndTimeMismatch
+	}
+	return nil
+}
+
+// AttendanceStats summarises a patient's attendance history
+type AttendanceStats struct {
+	UserID        uint    `json:"user_id"`
+	Completed     int     `json:"completed"`
+	NoShows       int     `json:"no_shows"`
+	LateCancelled int     `json:"late_cancelled"`
+	Reliability   float64 `json:"reliability"`   // Percentage of tracked appointments attended
+	NoShowRate    float64 `json:"no_show_rate"`  // Percentage of tracked appointments missed
+	TotalTracked  int     `json:"total_tracked"` // Completed + no-shows + late cancellations
+}
+
+// ComputeRates fills in the reliability and no-show percentages from the counts.
+// A patient with no tracked appointments is treated as fully reliable.
+func (s *AttendanceStats) ComputeRates() {
+	s.TotalTracked = s.Completed + s.NoShows + s.LateCancelled
+	if s.TotalTracked == 0 {
+		s.Reliability = 100
+		s.NoShowRate = 0
+		return
+	}
+	s.Reliability = math.Round(float64(s.Completed)/float64(s.TotalTracked)*10000) / 100
+	s.NoShowRate = math.Round(float64(s.NoShows)/float64(s.TotalTracked)*10000) / 100
+}
+
+// DoctorReliabilityMonth counts how a doctor's appointments in one month ended
+type DoctorReliabilityMonth struct {
+	Month            string  `json:"month"` // YYYY-MM
+	Total            int     `json:"total"` // Appointments booked for the month, excluding rescheduled ones
+	Cancelled        int     `json:"cancelled"`
+	NoShows          int     `json:"no_shows"`
+	CancellationRate float64 `json:"cancellation_rate"` // Percentage of the month's appointments cancelled
+	NoShowRate       float64 `json:"no_show_rate"`      // Percentage of the month's appointments missed
+}
+
+// ComputeRates fills in the cancellation and no-show percentages from the counts.
+// A month without appointments has zero rates.
+func (m *DoctorReliabilityMonth) ComputeRates() {
+	if m.Total == 0 {
+		m.CancellationRate = 0
+		m.NoShowRate = 0
+		return
+	}
+	m.CancellationRate = math.Round(float64(m.Cancelled)/float64(m.Total)*10000) / 100
+	m.NoShowRate = math.Round(float64(m.NoShows)/float64(m.Total)*10000) / 100
+}
+
+// CalendarDayCount is the number of active appointments a doctor has on one calendar day
+type CalendarDayCount struct {
+	Date  string `json:"date"` // YYYY-MM-DD in the requested timezone
+	Count int    `json:"count"`
+}
+
+// CancellationReasonStats counts cancellations with a given reason code
+type CancellationReasonStats struct {
+	Reason CancellationReason `json:"reason"`
+	Count  int                `json:"count"`
+}
+
+// ShiftResult reports the outcome of moving one appointment during a bulk shift
+type ShiftResult struct {
+	AppointmentID    uint      `json:"appointment_id"`
+	NewAppointmentID uint      `json:"new_appointment_id,omitempty"` // Differs from AppointmentID when rescheduling creates a new record
+	OldStart         time.Time `json:"old_start"`
+	NewStart         time.Time `json:"new_start"`
+	Success          bool      `json:"success"`
+	Error            string    `json:"error,omitempty"`
+}
+
+// ConfirmationResult reports the outcome of confirming one appointment in a batch
+type ConfirmationResult struct {
+	AppointmentID uint              `json:"appointment_id"`
+	Success       bool              `json:"success"`
+	Status        AppointmentStatus `json:"status,omitempty"` // Status after the attempt, when the appointment exists
+	Error         string            `json:"error,omitempty"`
+}
+
+// DurationCount is how many appointments lasted a given number of minutes
+type DurationCount struct {
+	Duration int `json:"duration"` // Minutes
+	Count    int `json:"count"`
+}
+
+// DurationInsights summarizes how long a doctor's completed appointments lasted, to help tune
+// the slot duration of their schedule
+type DurationInsights struct {
+	DoctorID              uint            `json:"doctor_id"`
+	SampleSize            int             `json:"sample_size"`
+	Average               float64         `json:"average"`
+	Median                float64         `json:"median"`
+	Percentile90          float64         `json:"percentile_90"`
+	Distribution          []DurationCount `json:"distribution"`
+	SuggestedSlotDuration *int            `json:"suggested_slot_duration"` // Null when there is no history
+}
+
+// HourlyDemand counts a doctor's bookings starting in one clock hour of one weekday
+type HourlyDemand struct {
+	Weekday time.Weekday `json:"weekday"`
+	Hour    int          `json:"hour"` // 0-23
+	Count   int          `json:"count"`
+}
+
+// AISpecialtyComparison counts symptom-based bookings by the specialty the AI suggested and the
+// specialty of the doctor actually booked
+type AISpecialtyComparison struct {
+	AISpecialtyID       uint    `json:"ai_specialty_id"`
+	AISpecialtyName     string  `json:"ai_specialty_name"`
+	BookedSpecialtyID   uint    `json:"booked_specialty_id"`
+	BookedSpecialtyName string  `json:"booked_specialty_name"`
+	Count               int     `json:"count"`
+	AverageConfidence   float64 `json:"average_confidence"`
+	Matched             bool    `json:"matched"` // The patient booked the suggested specialty
+}
+
+// DoctorDaySchedule is one doctor's appointments and slot usage on a date, as part of the
+// clinic-wide day view
+type DoctorDaySchedule struct {
+	Doctor         Doctor        `json:"doctor"`
+	Appointments   []Appointment `json:"appointments"`
+	AvailableSlots int           `json:"available_slots"`
+	BookedSlots    int           `json:"booked_slots"`
+	Utilization    float64       `json:"utilization"` // Share of the day's slots already booked, 0-1
+	NearlyFull     bool          `json:"nearly_full"`
+}
+
+// DueReminder is an appointment reminder that is about to fire
+type DueReminder struct {
+	AppointmentID   uint         `json:"appointment_id"`
+	UserID          uint         `json:"user_id"`
+	DoctorID        uint         `json:"doctor_id"`
+	AppointmentTime time.Time    `json:"appointment_time"`
+	ReminderType    ReminderType `json:"reminder_type"`
+	RemindAt        time.Time    `json:"remind_at"`
+	Overdue         bool         `json:"overdue"` // The reminder time has passed but it has not been sent yet
+}
+
+// WaitEstimate estimates how long a walk-in patient waits to see a doctor today
+type WaitEstimate struct {
+	DoctorID             uint      `json:"doctor_id"`
+	AppointmentsAhead    int       `json:"appointments_ahead"` // Booked appointments still to be seen, including one in progress
+	QueuePosition        int       `json:"queue_position"`     // Position a walk-in would take in the queue
+	AverageDuration      int       `json:"average_duration"`   // Minutes per appointment ahead
+	EstimatedWaitMinutes int       `json:"estimated_wait_minutes"`
+	EstimatedSeenAt      time.Time `json:"estimated_seen_at"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// AuditAction represents the kind of change recorded in an appointment's audit trail
+type AuditAction string
+
+const (
+	AuditRescheduled AuditAction = "RESCHEDULED"
+	AuditTypeChanged AuditAction = "TYPE_CHANGED"
+)
+
+// AppointmentAudit records a single change made to an appointment
+type AppointmentAudit struct {
+	ID            uint        `json:"id" gorm:"primaryKey"`
+	AppointmentID uint        `json:"appointment_id" gorm:"not null;index"`
+	Action        AuditAction `json:"action" gorm:"type:varchar(30);not null"`
+	OldValue      string      `json:"old_value" gorm:"type:text"`
+	NewValue      string      `json:"new_value" gorm:"type:text"`
+	ChangedBy     string      `json:"changed_by" gorm:"type:varchar(20)"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+// TableName specifies the table name for the AppointmentAudit model
+func (AppointmentAudit) TableName() string {
+	return "appointment_audits"
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAttendanceStatsComputeRates(t *testing.T) {
+	tests := []struct {
+		name                        string
+		completed, noShows, late    int
+		wantReliability, wantNoShow float64
+		wantTotal                   int
+	}{
+		{"seeded mix", 7, 2, 1, 70, 20, 10},
+		{"rounds to two decimals", 1, 2, 0, 33.33, 66.67, 3},
+		{"no history is fully reliable", 0, 0, 0, 100, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := AttendanceStats{Completed: tt.completed, NoShows: tt.noShows, LateCancelled: tt.late}
+			stats.ComputeRates()
+
+			if stats.TotalTracked != tt.wantTotal {
+				t.Errorf("expected %d tracked appointments, got %d", tt.wantTotal, stats.TotalTracked)
+			}
+			if stats.Reliability != tt.wantReliability {
+				t.Errorf("expected reliability %.2f, got %.2f", tt.wantReliability, stats.Reliability)
+			}
+			if stats.NoShowRate != tt.wantNoShow {
+				t.Errorf("expected no-show rate %.2f, got %.2f", tt.wantNoShow, stats.NoShowRate)
+			}
+		})
+	}
+}
+
+func TestCancellationReasonFromText(t *testing.T) {
+	tests := map[string]CancellationReason{
+		"PATIENT_REQUEST":    CancellationPatientRequest,
+		" clinic_closure ":   CancellationClinicClosure,
+		"no_show":            CancellationNoShow,
+		"Feeling better now": CancellationOther,
+		"":                   CancellationOther,
+		"DOCTOR_UNAVAILABLE": CancellationDoctorUnavailable,
+	}
+
+	for text, want := range tests {
+		if got := CancellationReasonFromText(text); got != want {
+			t.Errorf("CancellationReasonFromText(%q) = %s, want %s", text, got, want)
+		}
+		if !CancellationReasonFromText(text).IsValid() {
+			t.Errorf("CancellationReasonFromText(%q) returned an invalid code", text)
+		}
+	}
+	if CancellationReason("CHANGED_MIND").IsValid() {
+		t.Error("expected an unknown code to be invalid")
+	}
+}
+
+func TestCheckEndTime(t *testing.T) {
+	start := time.Date(2031, 3, 3, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		end      time.Time
+		duration int
+		wantEnd  time.Time
+		wantErr  error
+	}{
+		{"missing end is filled in", time.Time{}, 30, start.Add(30 * time.Minute), nil},
+		{"consistent end is kept", start.Add(45 * time.Minute), 45, start.Add(45 * time.Minute), nil},
+		{"end disagreeing with duration", start.Add(60 * time.Minute), 30, start.Add(60 * time.Minute), ErrEndTimeMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appointment := &Appointment{AppointmentTime: start, EndTime: tt.end, Duration: tt.duration}
+			if err := appointment.CheckEndTime(); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if !appointment.EndTime.Equal(tt.wantEnd) {
+				t.Errorf("expected end time %v, got %v", tt.wantEnd, appointment.EndTime)
+			}
+		})
+	}
+
+	if err := (&Appointment{AppointmentTime: start}).CheckEndTime(); err == nil {
+		t.Error("expected a zero duration to be rejected")
+	}
+}
@@ -1,26 +1,45 @@
-package models
-
-import (
-	"time"
-
-	"gorm.io/gorm"
-)
-
-// Doctor represents a doctor in the system
-type Doctor struct {
-	ID          uint           `json:"id" gorm:"primaryKey"`
-	Name        string         `json:"name" gorm:"not null;size:255" validate:"required,min=2,max=255"`
-	SpecialtyID uint           `json:"specialty_id" gorm:"not null" validate:"required,min=1"`
-	IsActive    bool           `json:"is_active" gorm:"default:true"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
-
-	// Relationships
-	Specialty Specialty `json:"specialty,omitempty" gorm:"foreignKey:SpecialtyID"`
-}
-
-// TableName specifies the table name for the Doctor model
-func (Doctor) TableName() string {
-	return "doctors"
-}
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Doctor represents a doctor in the system
+type Doctor struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Name        string         `json:"name" gorm:"not null;size:255" validate:"required,min=2,max=255"`
+	SpecialtyID uint           `json:"specialty_id" gorm:"not null" validate:"required,min=1"`
+	IsActive    bool           `json:"is_active" gorm:"default:true"`
+	LocationID  *uint          `json:"location_id" gorm:"index"` // Primary location, stamped on generated slots
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// AcceptingBookings is false while new bookings are paused; existing appointments are unaffected
+	AcceptingBookings bool `json:"accepting_bookings" gorm:"default:true"`
+
+	// Relationships
+	Specialty Specialty `json:"specialty,omitempty" gorm:"foreignKey:SpecialtyID"`
+	Location  *Location `json:"location,omitempty" gorm:"foreignKey:LocationID"`
+
+	// Computed by availability-sorted queries; never stored
+	EarliestAvailable *time.Time `json:"earliest_available,omitempty" gorm:"->;-:migration"`
+}
+
+// TableName specifies the table name for the Doctor model
+func (Doctor) TableName() string {
+	return "doctors"
+}
+
+// DoctorSummary holds the aggregates shown on a doctor's profile page
+type DoctorSummary struct {
+	DoctorID       uint     `json:"doctor_id"`
+	PatientsSeen   int      `json:"patients_seen"` // Distinct patients with a completed appointment
+	Completed      int      `json:"completed"`
+	NoShows        int      `json:"no_shows"`
+	CompletionRate float64  `json:"completion_rate"` // Percentage of concluded appointments that were completed
+	AverageRating  *float64 `json:"average_rating"`  // Mean review rating, 1-5 to two decimals; null without reviews
+	ReviewCount    int      `json:"review_count"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// Holiday is a date on which bookings are closed, either clinic-wide or for a single doctor
+type Holiday struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Date      time.Time `json:"date" gorm:"type:date;not null;index"`
+	Name      string    `json:"name" gorm:"size:255;not null"`
+	DoctorID  *uint     `json:"doctor_id" gorm:"index"` // Nil closes the whole clinic
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for the Holiday model
+func (Holiday) TableName() string {
+	return "holidays"
+}
+
+// AppliesToAll reports whether the holiday closes the whole clinic rather than one doctor
+func (h *Holiday) AppliesToAll() bool {
+	return h.DoctorID == nil
+}
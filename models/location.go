@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Location is a clinic site where doctors see patients
+type Location struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	Name      string         `json:"name" gorm:"not null;size:255;uniqueIndex" validate:"required,min=2,max=255"`
+	Address   string         `json:"address" gorm:"type:text"`
+	IsActive  bool           `json:"is_active" gorm:"default:true"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TableName specifies the table name for the Location model
+func (Location) TableName() string {
+	return "locations"
+}
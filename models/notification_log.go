@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+)
+
+// NotificationStatus represents the delivery outcome of a notification attempt
+type NotificationStatus string
+
+const (
+	NotificationSent   NotificationStatus = "SENT"
+	NotificationFailed NotificationStatus = "FAILED"
+)
+
+// NotificationKind represents what a notification was about
+type NotificationKind string
+
+const (
+	NotificationReminder       NotificationKind = "REMINDER"
+	NotificationConfirmation   NotificationKind = "CONFIRMATION"
+	NotificationCancellation   NotificationKind = "CANCELLATION"
+	NotificationReschedule     NotificationKind = "RESCHEDULE"
+	NotificationAutoReschedule NotificationKind = "AUTO_RESCHEDULE"
+	NotificationWaitlistOffer  NotificationKind = "WAITLIST_OFFER"
+)
+
+// NotificationLog records a single notification delivery attempt
+type NotificationLog struct {
+	ID            uint               `json:"id" gorm:"primaryKey"`
+	AppointmentID uint               `json:"appointment_id" gorm:"not null;index"` // 0 for waitlist offers, which have no appointment yet
+	OfferID       *uint              `json:"offer_id,omitempty" gorm:"index"`      // Set for waitlist offers
+	UserID        uint               `json:"user_id" gorm:"not null;index"`
+	Kind          NotificationKind   `json:"kind" gorm:"type:varchar(30);not null;index"`
+	Channel       ReminderType       `json:"channel" gorm:"type:varchar(10);not null"`
+	Status        NotificationStatus `json:"status" gorm:"type:varchar(10);not null;index"`
+	Escalated     bool               `json:"escalated" gorm:"default:false"`
+	Error         string             `json:"error,omitempty" gorm:"type:text"`
+	RequestID     string             `json:"request_id,omitempty" gorm:"type:varchar(64);index"` // Originating HTTP request; empty for scheduled sends
+	CreatedAt     time.Time          `json:"created_at" gorm:"index"`
+}
+
+// TableName specifies the table name for the NotificationLog model
+func (NotificationLog) TableName() string {
+	return "notification_logs"
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotReviewable is returned when reviewing an appointment that is not the patient's own completed appointment
+var ErrNotReviewable = errors.New("only your own completed appointments can be reviewed")
+
+// ErrAlreadyReviewed is returned when reviewing an appointment that already has a review
+var ErrAlreadyReviewed = errors.New("appointment has already been reviewed")
+
+// Review is a patient's rating of a completed appointment; each appointment has at most one
+type Review struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	AppointmentID uint      `json:"appointment_id" gorm:"not null;uniqueIndex"`
+	UserID        uint      `json:"user_id" gorm:"not null;index"`
+	DoctorID      uint      `json:"doctor_id" gorm:"not null;index"`
+	Rating        int       `json:"rating" gorm:"not null;check:chk_reviews_rating,rating BETWEEN 1 AND 5"`
+	Comment       string    `json:"comment" gorm:"type:text"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for the Review model
+func (Review) TableName() string {
+	return "reviews"
+}
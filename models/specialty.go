@@ -23,4 +23,22 @@ type Specialty struct {
 // TableName specifies the table name for the Specialty model
 func (Specialty) TableName() string {
 	return "specialties"
-}
\ No newline at end of file
+}
+
+// SpecialtyStats summarises appointment demand for a specialty over a period
+type SpecialtyStats struct {
+	SpecialtyID   uint   `json:"specialty_id"`
+	SpecialtyName string `json:"specialty_name"`
+	Total         int    `json:"total"`
+	Booked        int    `json:"booked"` // Scheduled or confirmed
+	Completed     int    `json:"completed"`
+	Cancelled     int    `json:"cancelled"`
+}
+
+// BookableSpecialty is a specialty with at least one active doctor available for booking
+type BookableSpecialty struct {
+	SpecialtyID   uint   `json:"specialty_id"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	ActiveDoctors int    `json:"active_doctors"`
+}
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"fmt"
+	"math"
+	"sort"
 	"time"
 
 	"gorm.io/gorm"
@@ -19,6 +22,26 @@ const (
 	Sunday    DayOfWeek = "SUNDAY"
 )
 
+// Weekday converts the day to a time.Weekday
+func (d DayOfWeek) Weekday() time.Weekday {
+	switch d {
+	case Monday:
+		return time.Monday
+	case Tuesday:
+		return time.Tuesday
+	case Wednesday:
+		return time.Wednesday
+	case Thursday:
+		return time.Thursday
+	case Friday:
+		return time.Friday
+	case Saturday:
+		return time.Saturday
+	default:
+		return time.Sunday
+	}
+}
+
 // SlotStatus represents the status of a time slot
 type SlotStatus string
 
@@ -29,7 +52,54 @@ const (
 	SlotBreak     SlotStatus = "BREAK"
 )
 
-// WorkingHours defines the start and end time for a working day.
+// IsValid reports whether the slot status is one of the known statuses
+func (s SlotStatus) IsValid() bool {
+	switch s {
+	case SlotAvailable, SlotBooked, SlotBlocked, SlotBreak:
+		return true
+	}
+	return false
+}
+
+// slotTransitions lists the statuses each slot status may move to
+var slotTransitions = map[SlotStatus][]SlotStatus{
+	SlotAvailable: {SlotBooked, SlotBlocked, SlotBreak},
+	SlotBooked:    {SlotAvailable},
+	SlotBlocked:   {SlotAvailable, SlotBreak},
+	SlotBreak:     {SlotAvailable, SlotBlocked},
+}
+
+// SlotTransitionError reports an illegal time slot status transition
+type SlotTransitionError struct {
+	From   SlotStatus
+	To     SlotStatus
+	Reason string
+}
+
+// Error implements the error interface
+func (e *SlotTransitionError) Error() string {
+	return fmt.Sprintf("illegal slot transition from %s to %s: %s", e.From, e.To, e.Reason)
+}
+
+// ValidateTransition checks whether a slot may move from s to the target status.
+// Moving to the current status is always allowed so updates are idempotent.
+func (s SlotStatus) ValidateTransition(to SlotStatus) error {
+	if !to.IsValid() {
+		return &SlotTransitionError{From: s, To: to, Reason: "unknown status"}
+	}
+	if s == to {
+		return nil
+	}
+	for _, allowed := range slotTransitions[s] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return &SlotTransitionError{From: s, To: to, Reason: "transition not allowed"}
+}
+
+// WorkingHours defines the start and end time for a working day. Each weekday of a
+// DoctorSchedule is stored in its own jsonb column as {"start_time":..,"end_time":..}.
 type WorkingHours struct {
 	StartTime string `json:"start_time"`
 	EndTime   string `json:"end_time"`
@@ -37,17 +107,21 @@ type WorkingHours struct {
 
 // DoctorSchedule represents a doctor's weekly schedule template.
 // This struct will be used to generate individual time slots.
+//
+// Migration note: doctor_schedules and doctor_breaks were previously missing from AutoMigrate,
+// so deployments gain both tables on the next start. There is no data to carry over; schedules
+// created before then could not have been persisted.
 type DoctorSchedule struct {
 	ID           uint           `json:"id" gorm:"primaryKey"`
 	DoctorID     uint           `json:"doctor_id" gorm:"not null;index" validate:"required,min=1"`
 	SlotDuration time.Duration  `json:"slot_duration" gorm:"not null" validate:"required"`
-	Monday       WorkingHours   `json:"monday"`
-	Tuesday      WorkingHours   `json:"tuesday"`
-	Wednesday    WorkingHours   `json:"wednesday"`
-	Thursday     WorkingHours   `json:"thursday"`
-	Friday       WorkingHours   `json:"friday"`
-	Saturday     WorkingHours   `json:"saturday"`
-	Sunday       WorkingHours   `json:"sunday"`
+	Monday       WorkingHours   `json:"monday" gorm:"type:jsonb;serializer:json"`
+	Tuesday      WorkingHours   `json:"tuesday" gorm:"type:jsonb;serializer:json"`
+	Wednesday    WorkingHours   `json:"wednesday" gorm:"type:jsonb;serializer:json"`
+	Thursday     WorkingHours   `json:"thursday" gorm:"type:jsonb;serializer:json"`
+	Friday       WorkingHours   `json:"friday" gorm:"type:jsonb;serializer:json"`
+	Saturday     WorkingHours   `json:"saturday" gorm:"type:jsonb;serializer:json"`
+	Sunday       WorkingHours   `json:"sunday" gorm:"type:jsonb;serializer:json"`
 	IsActive     bool           `json:"is_active" gorm:"default:true"`
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
@@ -61,6 +135,26 @@ func (DoctorSchedule) TableName() string {
 	return "doctor_schedules"
 }
 
+// WorkingHoursFor returns the working hours configured for the given weekday
+func (s *DoctorSchedule) WorkingHoursFor(weekday time.Weekday) WorkingHours {
+	switch weekday {
+	case time.Monday:
+		return s.Monday
+	case time.Tuesday:
+		return s.Tuesday
+	case time.Wednesday:
+		return s.Wednesday
+	case time.Thursday:
+		return s.Thursday
+	case time.Friday:
+		return s.Friday
+	case time.Saturday:
+		return s.Saturday
+	default:
+		return s.Sunday
+	}
+}
+
 // TimeSlot represents individual time slots for appointments
 type TimeSlot struct {
 	ID            uint           `json:"id" gorm:"primaryKey"`
@@ -73,6 +167,7 @@ type TimeSlot struct {
 	AppointmentID *uint          `json:"appointment_id" gorm:"index"` // Reference to booked appointment
 	Notes         string         `json:"notes" gorm:"type:text"`
 	IsRecurring   bool           `json:"is_recurring" gorm:"default:false"`
+	LocationID    *uint          `json:"location_id" gorm:"index"` // Where the slot is held; nil if the doctor has no location
 	CreatedAt     time.Time      `json:"created_at"`
 	UpdatedAt     time.Time      `json:"updated_at"`
 	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
@@ -80,6 +175,7 @@ type TimeSlot struct {
 	// Relationships
 	Doctor      Doctor       `json:"doctor,omitempty" gorm:"foreignKey:DoctorID"`
 	Appointment *Appointment `json:"appointment,omitempty" gorm:"foreignKey:AppointmentID"`
+	Location    *Location    `json:"location,omitempty" gorm:"foreignKey:LocationID"`
 }
 
 // TableName specifies the table name for the TimeSlot model
@@ -109,6 +205,146 @@ func (DoctorBreak) TableName() string {
 	return "doctor_breaks"
 }
 
+// BlockedPeriod is a stretch of a doctor's time that cannot be booked: a blocked or break slot,
+// or a break from the doctor's break list
+type BlockedPeriod struct {
+	Kind        SlotStatus `json:"kind"` // BLOCKED or BREAK
+	SlotID      *uint      `json:"slot_id,omitempty"`
+	BreakID     *uint      `json:"break_id,omitempty"`
+	StartTime   time.Time  `json:"start_time"`
+	EndTime     time.Time  `json:"end_time"`
+	Reason      string     `json:"reason,omitempty"`
+	IsRecurring bool       `json:"is_recurring"`
+}
+
+// BookableWindow is a run of back-to-back available slots long enough for the requested duration
+type BookableWindow struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Minutes   int       `json:"minutes"`
+}
+
+// DayBookableWindows lists the bookable windows on one day
+type DayBookableWindows struct {
+	Date    string           `json:"date"` // YYYY-MM-DD
+	Windows []BookableWindow `json:"windows"`
+}
+
+// MergeSlotWindows merges back-to-back and overlapping slots into continuous windows ordered by
+// start time, keeping those at least minLength long
+func MergeSlotWindows(slots []TimeSlot, minLength time.Duration) []BookableWindow {
+	sorted := make([]TimeSlot, len(slots))
+	copy(sorted, slots)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].StartTime.Before(sorted[j].StartTime)
+	})
+
+	windows := []BookableWindow{}
+	for i := 0; i < len(sorted); {
+		start, end := sorted[i].StartTime, sorted[i].EndTime
+		i++
+		for i < len(sorted) && !sorted[i].StartTime.After(end) {
+			if sorted[i].EndTime.After(end) {
+				end = sorted[i].EndTime
+			}
+			i++
+		}
+		if end.Sub(start) >= minLength {
+			windows = append(windows, BookableWindow{
+				StartTime: start,
+				EndTime:   end,
+				Minutes:   int(end.Sub(start).Minutes()),
+			})
+		}
+	}
+	return windows
+}
+
+// SlotChoice is an appointment time picked from a doctor's open slots
+type SlotChoice struct {
+	StartTime            time.Time `json:"start_time"`
+	EndTime              time.Time `json:"end_time"`
+	Duration             int       `json:"duration"`               // Minutes
+	MinutesFromPreferred int       `json:"minutes_from_preferred"` // To the nearest preferred time of day; 0 without preferences
+}
+
+// ScoreSlotStart scores an appointment starting at start by how many minutes it is from the
+// nearest preferred time of day, each an offset from midnight in start's timezone. Lower is
+// better, and every start scores 0 when there are no preferences.
+func ScoreSlotStart(start time.Time, preferred []time.Duration) int {
+	if len(preferred) == 0 {
+		return 0
+	}
+
+	clock := time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute
+	best := -1
+	for _, p := range preferred {
+		diff := clock - p
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff > 12*time.Hour {
+			diff = 24*time.Hour - diff
+		}
+		if minutes := int(diff.Minutes()); best < 0 || minutes < best {
+			best = minutes
+		}
+	}
+	return best
+}
+
+// BestSlot picks the best time for an appointment of duration among the open slots. Candidates
+// start where a slot starts, fit inside one merged window of back-to-back slots and lie within
+// [earliest, latest]. The candidate closest to a preferred time of day wins, ties going to the
+// earliest; preferred times are read in earliest's timezone. ok is false if nothing fits.
+func BestSlot(slots []TimeSlot, duration time.Duration, earliest, latest time.Time, preferred []time.Duration) (choice SlotChoice, ok bool) {
+	sorted := make([]TimeSlot, len(slots))
+	copy(sorted, slots)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].StartTime.Before(sorted[j].StartTime)
+	})
+	windows := MergeSlotWindows(sorted, duration)
+
+	w := 0
+	for _, slot := range sorted {
+		start, end := slot.StartTime, slot.StartTime.Add(duration)
+		for w < len(windows) && !windows[w].EndTime.After(start) {
+			w++
+		}
+		if w == len(windows) {
+			break
+		}
+		if start.Before(windows[w].StartTime) || end.After(windows[w].EndTime) {
+			continue
+		}
+		if start.Before(earliest) || end.After(latest) {
+			continue
+		}
+
+		score := ScoreSlotStart(start.In(earliest.Location()), preferred)
+		if !ok || score < choice.MinutesFromPreferred {
+			choice = SlotChoice{
+				StartTime:            start,
+				EndTime:              end,
+				Duration:             int(duration.Minutes()),
+				MinutesFromPreferred: score,
+			}
+			ok = true
+		}
+	}
+	return choice, ok
+}
+
+// TimeOffPeriod is a stretch of time a doctor is away: a run of blocked slots sharing a reason,
+// or a doctor-specific or clinic-wide holiday
+type TimeOffPeriod struct {
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+	Reason     string    `json:"reason,omitempty"`
+	HolidayID  *uint     `json:"holiday_id,omitempty"`
+	ClinicWide bool      `json:"clinic_wide"`
+}
+
 // AvailabilityRequest represents a request for checking doctor availability
 type AvailabilityRequest struct {
 	DoctorID  uint      `json:"doctor_id" validate:"required,min=1"`
@@ -125,4 +361,324 @@ type AvailabilityResponse struct {
 	AvailableSlots []TimeSlot `json:"available_slots"`
 	TotalSlots     int        `json:"total_slots"`
 	BookedSlots    int        `json:"booked_slots"`
+	Utilization    float64    `json:"utilization"` // Share of the day's slots already booked, 0-1
+	NearlyFull     bool       `json:"nearly_full"` // Utilization has reached the nearly-full threshold
+
+	// AcceptingBookings is false while the doctor has paused new bookings
+	AcceptingBookings bool `json:"accepting_bookings"`
+}
+
+// ApplyUtilization sets the utilization from the open and booked slot counts and flags the day
+// as nearly full once it reaches threshold (0-1). A day with no slots at all is not flagged.
+func (a *AvailabilityResponse) ApplyUtilization(threshold float64) {
+	capacity := a.TotalSlots + a.BookedSlots
+	if capacity == 0 {
+		a.Utilization = 0
+		a.NearlyFull = false
+		return
+	}
+
+	a.Utilization = math.Round(float64(a.BookedSlots)/float64(capacity)*100) / 100
+	a.NearlyFull = a.Utilization >= threshold
+}
+
+// LocatedSlot is an available slot labelled with the location it is held at
+type LocatedSlot struct {
+	ID           uint      `json:"id"`
+	StartTime    time.Time `json:"start_time"`
+	EndTime      time.Time `json:"end_time"`
+	Duration     int       `json:"duration"`
+	LocationID   *uint     `json:"location_id"`   // nil if the doctor has no location
+	LocationName string    `json:"location_name"` // Empty if the slot has no known location
+}
+
+// LocatedAvailability is a doctor's availability for a day merged across all their locations
+type LocatedAvailability struct {
+	DoctorID   uint          `json:"doctor_id"`
+	Date       time.Time     `json:"date"`
+	Slots      []LocatedSlot `json:"slots"`
+	TotalSlots int           `json:"total_slots"`
+
+	// AcceptingBookings is false while the doctor has paused new bookings
+	AcceptingBookings bool `json:"accepting_bookings"`
+}
+
+// LabelSlotLocations merges slots held at any location into one list ordered by start time, labelling
+// each with the name of its location in locations
+func LabelSlotLocations(slots []TimeSlot, locations []Location) []LocatedSlot {
+	names := make(map[uint]string, len(locations))
+	for _, location := range locations {
+		names[location.ID] = location.Name
+	}
+
+	located := make([]LocatedSlot, 0, len(slots))
+	for _, slot := range slots {
+		entry := LocatedSlot{
+			ID:         slot.ID,
+			StartTime:  slot.StartTime,
+			EndTime:    slot.EndTime,
+			Duration:   slot.Duration,
+			LocationID: slot.LocationID,
+		}
+		if slot.LocationID != nil {
+			entry.LocationName = names[*slot.LocationID]
+		}
+		located = append(located, entry)
+	}
+
+	sort.SliceStable(located, func(i, j int) bool {
+		return located[i].StartTime.Before(located[j].StartTime)
+	})
+	return located
+}
+
+// ForecastDemand classifies how busy a window has historically been
+type ForecastDemand string
+
+const (
+	ForecastDemandHigh   ForecastDemand = "HIGH"
+	ForecastDemandNormal ForecastDemand = "NORMAL"
+	ForecastDemandLow    ForecastDemand = "LOW"
+)
+
+// ForecastWindow is a stretch of working hours with the slot length chosen from its booking history
+type ForecastWindow struct {
+	StartTime       time.Time      `json:"start_time"`
+	EndTime         time.Time      `json:"end_time"`
+	SlotDuration    int            `json:"slot_duration"`    // Minutes
+	AverageBookings float64        `json:"average_bookings"` // Bookings per week in this window historically
+	Demand          ForecastDemand `json:"demand"`
+	SlotsCreated    int            `json:"slots_created"`
+}
+
+// DayGenerationResult reports the outcome of generating slots for a single day
+type DayGenerationResult struct {
+	Date    string    `json:"date"` // YYYY-MM-DD
+	Day     DayOfWeek `json:"day"`
+	Success bool      `json:"success"`
+	Error   string    `json:"error,omitempty"`
+	Holiday string    `json:"holiday,omitempty"` // Set when the day was skipped for a holiday
+}
+
+// ScheduleGridBreak represents a recurring break within a schedule grid day
+type ScheduleGridBreak struct {
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// ScheduleGridDay represents one day of a doctor's weekly schedule template
+type ScheduleGridDay struct {
+	Day          DayOfWeek           `json:"day"`
+	IsOpen       bool                `json:"is_open"`
+	StartTime    string              `json:"start_time,omitempty"`
+	EndTime      string              `json:"end_time,omitempty"`
+	SlotDuration int                 `json:"slot_duration"` // Duration in minutes
+	Breaks       []ScheduleGridBreak `json:"breaks"`
+}
+
+// scheduleGridDays lists the grid days in display order, Monday first
+var scheduleGridDays = []DayOfWeek{Monday, Tuesday, Wednesday, Thursday, Friday, Saturday, Sunday}
+
+// BuildScheduleGrid transforms a schedule template and its recurring breaks into a
+// seven-day grid. Days without both start and end times render as closed.
+func BuildScheduleGrid(schedule *DoctorSchedule, recurringBreaks []DoctorBreak) []ScheduleGridDay {
+	grid := make([]ScheduleGridDay, 0, len(scheduleGridDays))
+	for _, d := range scheduleGridDays {
+		hours := schedule.WorkingHoursFor(d.Weekday())
+		day := ScheduleGridDay{
+			Day:          d,
+			SlotDuration: int(schedule.SlotDuration.Minutes()),
+			Breaks:       []ScheduleGridBreak{},
+		}
+
+		if hours.StartTime != "" && hours.EndTime != "" {
+			day.IsOpen = true
+			day.StartTime = hours.StartTime
+			day.EndTime = hours.EndTime
+
+			for _, b := range recurringBreaks {
+				if b.Date.Weekday() != d.Weekday() {
+					continue
+				}
+				day.Breaks = append(day.Breaks, ScheduleGridBreak{
+					StartTime: b.StartTime.Format("15:04"),
+					EndTime:   b.EndTime.Format("15:04"),
+					Reason:    b.Reason,
+				})
+			}
+		}
+
+		grid = append(grid, day)
+	}
+	return grid
+}
+
+// ScheduleIssueCode identifies the kind of problem found in a schedule template
+type ScheduleIssueCode string
+
+const (
+	IssueInvalidHours      ScheduleIssueCode = "INVALID_HOURS"
+	IssueUnevenSlots       ScheduleIssueCode = "UNEVEN_SLOTS"
+	IssueInvalidBreak      ScheduleIssueCode = "INVALID_BREAK"
+	IssueBreakOutsideHours ScheduleIssueCode = "BREAK_OUTSIDE_HOURS"
+	IssueOverlappingBreaks ScheduleIssueCode = "OVERLAPPING_BREAKS"
+)
+
+// ScheduleIssue describes one problem found on a day of a schedule template
+type ScheduleIssue struct {
+	Day     DayOfWeek         `json:"day"`
+	Code    ScheduleIssueCode `json:"code"`
+	Message string            `json:"message"`
+}
+
+// ValidateScheduleGrid checks each open day of a schedule grid for unusable hours, a slot duration
+// that does not divide the working window evenly, and breaks that are malformed, fall outside the
+// working hours or overlap one another. A clean schedule yields no issues.
+func ValidateScheduleGrid(days []ScheduleGridDay) []ScheduleIssue {
+	issues := []ScheduleIssue{}
+	for _, day := range days {
+		if !day.IsOpen {
+			continue
+		}
+		report := func(code ScheduleIssueCode, format string, args ...interface{}) {
+			issues = append(issues, ScheduleIssue{Day: day.Day, Code: code, Message: fmt.Sprintf(format, args...)})
+		}
+
+		start, startErr := time.Parse("15:04", day.StartTime)
+		end, endErr := time.Parse("15:04", day.EndTime)
+		if startErr != nil || endErr != nil || !end.After(start) {
+			report(IssueInvalidHours, "working hours %s-%s are not a valid time range", day.StartTime, day.EndTime)
+			continue
+		}
+
+		window := int(end.Sub(start).Minutes())
+		if day.SlotDuration <= 0 {
+			report(IssueUnevenSlots, "slot duration must be positive")
+		} else if window%day.SlotDuration != 0 {
+			report(IssueUnevenSlots, "%d-minute slots leave %d minutes unused in the %d-minute working window",
+				day.SlotDuration, window%day.SlotDuration, window)
+		}
+
+		type interval struct{ start, end time.Time }
+		var breaks []interval
+		for _, b := range day.Breaks {
+			breakStart, startErr := time.Parse("15:04", b.StartTime)
+			breakEnd, endErr := time.Parse("15:04", b.EndTime)
+			if startErr != nil || endErr != nil || !breakEnd.After(breakStart) {
+				report(IssueInvalidBreak, "break %s-%s is not a valid time range", b.StartTime, b.EndTime)
+				continue
+			}
+			if breakStart.Before(start) || breakEnd.After(end) {
+				report(IssueBreakOutsideHours, "break %s-%s falls outside working hours %s-%s",
+					b.StartTime, b.EndTime, day.StartTime, day.EndTime)
+			}
+			breaks = append(breaks, interval{breakStart, breakEnd})
+		}
+
+		sort.Slice(breaks, func(i, j int) bool { return breaks[i].start.Before(breaks[j].start) })
+		for i := 1; i < len(breaks); i++ {
+			if breaks[i].start.Before(breaks[i-1].end) {
+				report(IssueOverlappingBreaks, "break %s-%s overlaps break %s-%s",
+					breaks[i].start.Format("15:04"), breaks[i].end.Format("15:04"),
+					breaks[i-1].start.Format("15:04"), breaks[i-1].end.Format("15:04"))
+			}
+		}
+	}
+	return issues
+}
+
+// DiscrepancyCode identifies the kind of mismatch found between a doctor's appointments and slots
+type DiscrepancyCode string
+
+const (
+	DiscrepancyAppointmentWithoutSlot DiscrepancyCode = "APPOINTMENT_WITHOUT_SLOT"
+	DiscrepancySlotWithoutAppointment DiscrepancyCode = "SLOT_WITHOUT_APPOINTMENT"
+	DiscrepancyOutsideWorkingHours    DiscrepancyCode = "OUTSIDE_WORKING_HOURS"
+)
+
+// ScheduleDiscrepancy describes one inconsistency between a doctor's appointments, slots and schedule
+type ScheduleDiscrepancy struct {
+	Code          DiscrepancyCode `json:"code"`
+	AppointmentID *uint           `json:"appointment_id,omitempty"`
+	SlotID        *uint           `json:"slot_id,omitempty"`
+	StartTime     time.Time       `json:"start_time"`
+	EndTime       time.Time       `json:"end_time"`
+	Message       string          `json:"message"`
+}
+
+// FindScheduleDiscrepancies cross-checks a doctor's active appointments against their time slots
+// and weekly schedule. It reports appointments no booked slot points at, booked slots that point
+// at no active appointment, and appointments outside the working hours of their day.
+// Discrepancies are ordered by start time.
+func FindScheduleDiscrepancies(schedule *DoctorSchedule, appointments []Appointment, slots []TimeSlot) []ScheduleDiscrepancy {
+	discrepancies := []ScheduleDiscrepancy{}
+
+	active := make(map[uint]bool, len(appointments))
+	for _, appointment := range appointments {
+		active[appointment.ID] = true
+	}
+
+	held := make(map[uint]bool)
+	for _, slot := range slots {
+		if slot.Status != SlotBooked {
+			continue
+		}
+		slotID := slot.ID
+		switch {
+		case slot.AppointmentID == nil:
+			discrepancies = append(discrepancies, ScheduleDiscrepancy{
+				Code: DiscrepancySlotWithoutAppointment, SlotID: &slotID,
+				StartTime: slot.StartTime, EndTime: slot.EndTime,
+				Message: "slot is booked but holds no appointment",
+			})
+		case !active[*slot.AppointmentID]:
+			discrepancies = append(discrepancies, ScheduleDiscrepancy{
+				Code: DiscrepancySlotWithoutAppointment, SlotID: &slotID, AppointmentID: slot.AppointmentID,
+				StartTime: slot.StartTime, EndTime: slot.EndTime,
+				Message: fmt.Sprintf("slot is booked for appointment %d, which is not an active appointment", *slot.AppointmentID),
+			})
+		default:
+			held[*slot.AppointmentID] = true
+		}
+	}
+
+	for _, appointment := range appointments {
+		appointmentID := appointment.ID
+		if !held[appointment.ID] {
+			discrepancies = append(discrepancies, ScheduleDiscrepancy{
+				Code: DiscrepancyAppointmentWithoutSlot, AppointmentID: &appointmentID,
+				StartTime: appointment.AppointmentTime, EndTime: appointment.EndTime,
+				Message: "appointment is not held by a booked slot",
+			})
+		}
+
+		start := appointment.AppointmentTime
+		hours := schedule.WorkingHoursFor(start.Weekday())
+		dayStart, startErr := time.Parse("15:04", hours.StartTime)
+		dayEnd, endErr := time.Parse("15:04", hours.EndTime)
+		if startErr != nil || endErr != nil {
+			discrepancies = append(discrepancies, ScheduleDiscrepancy{
+				Code: DiscrepancyOutsideWorkingHours, AppointmentID: &appointmentID,
+				StartTime: appointment.AppointmentTime, EndTime: appointment.EndTime,
+				Message: fmt.Sprintf("appointment falls on %s, when the doctor does not work", start.Weekday()),
+			})
+			continue
+		}
+		opens := time.Date(start.Year(), start.Month(), start.Day(), dayStart.Hour(), dayStart.Minute(), 0, 0, start.Location())
+		closes := time.Date(start.Year(), start.Month(), start.Day(), dayEnd.Hour(), dayEnd.Minute(), 0, 0, start.Location())
+		if start.Before(opens) || appointment.EndTime.After(closes) {
+			discrepancies = append(discrepancies, ScheduleDiscrepancy{
+				Code: DiscrepancyOutsideWorkingHours, AppointmentID: &appointmentID,
+				StartTime: appointment.AppointmentTime, EndTime: appointment.EndTime,
+				Message: fmt.Sprintf("appointment %s-%s falls outside working hours %s-%s",
+					start.Format("15:04"), appointment.EndTime.Format("15:04"), hours.StartTime, hours.EndTime),
+			})
+		}
+	}
+
+	sort.SliceStable(discrepancies, func(i, j int) bool {
+		return discrepancies[i].StartTime.Before(discrepancies[j].StartTime)
+	})
+	return discrepancies
 }
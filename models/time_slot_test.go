@@ -0,0 +1,199 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSlotStatusValidateTransition(t *testing.T) {
+	tests := []struct {
+		from, to SlotStatus
+		allowed  bool
+	}{
+		{SlotAvailable, SlotAvailable, true},
+		{SlotAvailable, SlotBooked, true},
+		{SlotAvailable, SlotBlocked, true},
+		{SlotAvailable, SlotBreak, true},
+		{SlotBooked, SlotAvailable, true},
+		{SlotBooked, SlotBooked, true},
+		{SlotBooked, SlotBlocked, false},
+		{SlotBooked, SlotBreak, false},
+		{SlotBlocked, SlotAvailable, true},
+		{SlotBlocked, SlotBreak, true},
+		{SlotBlocked, SlotBooked, false},
+		{SlotBreak, SlotAvailable, true},
+		{SlotBreak, SlotBlocked, true},
+		{SlotBreak, SlotBooked, false},
+		{SlotAvailable, SlotStatus("RESERVED"), false},
+	}
+
+	for _, tt := range tests {
+		err := tt.from.ValidateTransition(tt.to)
+		if tt.allowed {
+			if err != nil {
+				t.Errorf("%s -> %s: expected allowed, got %v", tt.from, tt.to, err)
+			}
+			continue
+		}
+
+		var transitionErr *SlotTransitionError
+		if !errors.As(err, &transitionErr) {
+			t.Errorf("%s -> %s: expected a SlotTransitionError, got %v", tt.from, tt.to, err)
+			continue
+		}
+		if transitionErr.From != tt.from || transitionErr.To != tt.to {
+			t.Errorf("%s -> %s: error reports %s -> %s", tt.from, tt.to, transitionErr.From, transitionErr.To)
+		}
+	}
+}
+
+func TestBuildScheduleGridRendersDaysWithoutHoursAsClosed(t *testing.T) {
+	schedule := &DoctorSchedule{
+		DoctorID:     1,
+		SlotDuration: 30 * time.Minute,
+		Monday:       WorkingHours{StartTime: "09:00", EndTime: "17:00"},
+		Wednesday:    WorkingHours{StartTime: "09:00", EndTime: "13:00"},
+		// A start without an end is not a working day
+		Friday: WorkingHours{StartTime: "09:00"},
+	}
+	monday := time.Date(2031, time.March, 3, 0, 0, 0, 0, time.UTC)
+	breaks := []DoctorBreak{{
+		Date:        monday,
+		StartTime:   monday.Add(12 * time.Hour),
+		EndTime:     monday.Add(13 * time.Hour),
+		Reason:      "Lunch",
+		IsRecurring: true,
+	}}
+
+	grid := BuildScheduleGrid(schedule, breaks)
+	if len(grid) != 7 {
+		t.Fatalf("expected 7 days, got %d", len(grid))
+	}
+
+	open := map[DayOfWeek]bool{Monday: true, Wednesday: true}
+	for i, day := range grid {
+		if day.Day != scheduleGridDays[i] {
+			t.Errorf("day %d: expected %s, got %s", i, scheduleGridDays[i], day.Day)
+		}
+		if day.IsOpen != open[day.Day] {
+			t.Errorf("%s: expected open=%v, got %v", day.Day, open[day.Day], day.IsOpen)
+		}
+		if day.SlotDuration != 30 {
+			t.Errorf("%s: expected a 30 minute slot duration, got %d", day.Day, day.SlotDuration)
+		}
+		if day.Breaks == nil {
+			t.Errorf("%s: expected an empty break list, got nil", day.Day)
+		}
+		if !day.IsOpen && (day.StartTime != "" || day.EndTime != "" || len(day.Breaks) != 0) {
+			t.Errorf("%s: expected a closed day without hours or breaks, got %+v", day.Day, day)
+		}
+	}
+
+	if len(grid[0].Breaks) != 1 || grid[0].Breaks[0].StartTime != "12:00" || grid[0].Breaks[0].EndTime != "13:00" {
+		t.Errorf("expected Monday's lunch break 12:00-13:00, got %+v", grid[0].Breaks)
+	}
+}
+
+func TestValidateScheduleGrid(t *testing.T) {
+	lunch := ScheduleGridBreak{StartTime: "12:00", EndTime: "13:00", Reason: "Lunch"}
+	tests := []struct {
+		name  string
+		day   ScheduleGridDay
+		codes []ScheduleIssueCode
+	}{
+		{
+			name: "clean",
+			day:  ScheduleGridDay{Day: Monday, IsOpen: true, StartTime: "09:00", EndTime: "17:00", SlotDuration: 30, Breaks: []ScheduleGridBreak{lunch}},
+		},
+		{
+			name: "closed day is skipped",
+			day:  ScheduleGridDay{Day: Sunday, StartTime: "bogus"},
+		},
+		{
+			name:  "break outside hours",
+			day:   ScheduleGridDay{Day: Tuesday, IsOpen: true, StartTime: "09:00", EndTime: "17:00", SlotDuration: 30, Breaks: []ScheduleGridBreak{{StartTime: "17:30", EndTime: "18:00"}}},
+			codes: []ScheduleIssueCode{IssueBreakOutsideHours},
+		},
+		{
+			name:  "overlapping breaks",
+			day:   ScheduleGridDay{Day: Wednesday, IsOpen: true, StartTime: "09:00", EndTime: "17:00", SlotDuration: 30, Breaks: []ScheduleGridBreak{lunch, {StartTime: "12:30", EndTime: "13:30"}}},
+			codes: []ScheduleIssueCode{IssueOverlappingBreaks},
+		},
+		{
+			name:  "uneven slots",
+			day:   ScheduleGridDay{Day: Thursday, IsOpen: true, StartTime: "09:00", EndTime: "10:00", SlotDuration: 45},
+			codes: []ScheduleIssueCode{IssueUnevenSlots},
+		},
+		{
+			name:  "hours backwards",
+			day:   ScheduleGridDay{Day: Friday, IsOpen: true, StartTime: "17:00", EndTime: "09:00", SlotDuration: 30},
+			codes: []ScheduleIssueCode{IssueInvalidHours},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := ValidateScheduleGrid([]ScheduleGridDay{tt.day})
+			if len(issues) != len(tt.codes) {
+				t.Fatalf("expected %d issue(s), got %+v", len(tt.codes), issues)
+			}
+			for i, issue := range issues {
+				if issue.Code != tt.codes[i] || issue.Day != tt.day.Day {
+					t.Errorf("expected %s on %s, got %+v", tt.codes[i], tt.day.Day, issue)
+				}
+			}
+		})
+	}
+}
+
+func TestBestSlotRespectsBoundsAndPrefersPreferredTimes(t *testing.T) {
+	day := time.Date(2031, time.March, 3, 0, 0, 0, 0, time.UTC)
+	at := func(hour, minute int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	var slots []TimeSlot
+	// Open 8:00-9:30, 11:00-12:00 and 14:00-15:00 in half-hour slots, listed out of order
+	for _, start := range []time.Time{at(14, 0), at(8, 0), at(11, 30), at(8, 30), at(9, 0), at(11, 0), at(14, 30)} {
+		slots = append(slots, TimeSlot{StartTime: start, EndTime: start.Add(30 * time.Minute), Duration: 30, Status: SlotAvailable})
+	}
+	clock := func(hour, minute int) time.Duration {
+		return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute
+	}
+
+	tests := []struct {
+		name             string
+		earliest, latest time.Time
+		preferred        []time.Duration
+		wantStart        time.Time
+		wantMinutes      int
+	}{
+		{"earliest fit without preferences", at(7, 0), at(18, 0), nil, at(8, 0), 0},
+		{"starts no earlier than earliest", at(8, 15), at(18, 0), nil, at(8, 30), 0},
+		{"closest to the preferred time", at(7, 0), at(18, 0), []time.Duration{clock(14, 10)}, at(14, 0), 10},
+		{"ends no later than latest", at(7, 0), at(14, 30), []time.Duration{clock(14, 10)}, at(11, 0), 190},
+		{"preferred time between windows", at(7, 0), at(18, 0), []time.Duration{clock(10, 45)}, at(11, 0), 15},
+		{"tie goes to the earliest", at(7, 0), at(18, 0), []time.Duration{clock(13, 50), clock(8, 40)}, at(8, 30), 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			choice, ok := BestSlot(slots, time.Hour, tt.earliest, tt.latest, tt.preferred)
+			if !ok {
+				t.Fatalf("expected a slot to fit")
+			}
+			if !choice.StartTime.Equal(tt.wantStart) || !choice.EndTime.Equal(tt.wantStart.Add(time.Hour)) {
+				t.Errorf("expected %s-%s, got %s-%s", tt.wantStart.Format("15:04"), tt.wantStart.Add(time.Hour).Format("15:04"),
+					choice.StartTime.Format("15:04"), choice.EndTime.Format("15:04"))
+			}
+			if choice.MinutesFromPreferred != tt.wantMinutes || choice.Duration != 60 {
+				t.Errorf("expected 60 minutes, %d from preferred, got %d minutes, %d from preferred", tt.wantMinutes, choice.Duration, choice.MinutesFromPreferred)
+			}
+		})
+	}
+
+	// Only half an hour of the last window is left from 14:30
+	if _, ok := BestSlot(slots, time.Hour, at(14, 30), at(18, 0), nil); ok {
+		t.Errorf("expected nothing to fit after 14:30")
+	}
+}
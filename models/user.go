@@ -0,0 +1,110 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NotificationPreferences holds how a user wants to be contacted
+type NotificationPreferences struct {
+	SMS             bool   `json:"sms" gorm:"column:pref_sms;default:true"`
+	Email           bool   `json:"email" gorm:"column:pref_email;default:true"`
+	Push            bool   `json:"push" gorm:"column:pref_push;default:true"`
+	QuietHoursStart string `json:"quiet_hours_start,omitempty" gorm:"column:quiet_hours_start;type:varchar(5)"` // HH:MM
+	QuietHoursEnd   string `json:"quiet_hours_end,omitempty" gorm:"column:quiet_hours_end;type:varchar(5)"`     // HH:MM
+	Language        string `json:"language,omitempty" gorm:"column:language;type:varchar(10)"`                  // e.g. "en" or "fr"; empty means English
+}
+
+// languageTagPattern matches a two-letter language code with an optional region, such as "fr-CA"
+var languageTagPattern = regexp.MustCompile(`^[a-zA-Z]{2}([-_][a-zA-Z]{2})?$`)
+
+// DefaultNotificationPreferences returns preferences with every channel enabled and no quiet hours
+func DefaultNotificationPreferences() NotificationPreferences {
+	return NotificationPreferences{SMS: true, Email: true, Push: true}
+}
+
+// Validate checks that quiet hours are either both unset or both valid HH:MM times, and that the
+// language, if set, looks like a language tag such as "fr" or "fr-CA"
+func (p NotificationPreferences) Validate() error {
+	if p.Language != "" && !languageTagPattern.MatchString(p.Language) {
+		return errors.New("language must be a language code such as en or fr-CA")
+	}
+	if (p.QuietHoursStart == "") != (p.QuietHoursEnd == "") {
+		return errors.New("quiet_hours_start and quiet_hours_end must be set together")
+	}
+	if p.QuietHoursStart == "" {
+		return nil
+	}
+	if _, err := time.Parse("15:04", p.QuietHoursStart); err != nil {
+		return errors.New("quiet_hours_start must be in HH:MM format")
+	}
+	if _, err := time.Parse("15:04", p.QuietHoursEnd); err != nil {
+		return errors.New("quiet_hours_end must be in HH:MM format")
+	}
+	return nil
+}
+
+// Allows reports whether the user accepts notifications over the given channel.
+// Voice calls are reserved for escalations and are not governed by a preference.
+func (p NotificationPreferences) Allows(channel ReminderType) bool {
+	switch channel {
+	case ReminderSMS:
+		return p.SMS
+	case ReminderEmail:
+		return p.Email
+	case ReminderPush:
+		return p.Push
+	default:
+		return true
+	}
+}
+
+// InQuietHours reports whether t falls within the quiet hours window, which may span midnight
+func (p NotificationPreferences) InQuietHours(t time.Time) bool {
+	if p.QuietHoursStart == "" || p.QuietHoursEnd == "" {
+		return false
+	}
+
+	start, err := time.Parse("15:04", p.QuietHoursStart)
+	if err != nil {
+		return false
+	}
+	end, err := time.Parse("15:04", p.QuietHoursEnd)
+	if err != nil {
+		return false
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	startMinute := start.Hour()*60 + start.Minute()
+	endMinute := end.Hour()*60 + end.Minute()
+
+	if startMinute <= endMinute {
+		return minute >= startMinute && minute < endMinute
+	}
+	return minute >= startMinute || minute < endMinute
+}
+
+// User represents an account in the system
+type User struct {
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	Username     string         `json:"username" gorm:"not null;size:50;uniqueIndex" validate:"required,min=3,max=50"`
+	Name         string         `json:"name" gorm:"size:255"`
+	Email        string         `json:"email" gorm:"size:255;uniqueIndex" validate:"omitempty,email"`
+	Phone        string         `json:"phone" gorm:"type:varchar(16)"` // E.164
+	Role         string         `json:"role" gorm:"type:varchar(20);not null;default:'user'"`
+	PasswordHash string         `json:"-" gorm:"not null"`
+	IsActive     bool           `json:"is_active" gorm:"default:true"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+
+	NotificationPreferences NotificationPreferences `json:"notification_preferences" gorm:"embedded"`
+}
+
+// TableName specifies the table name for the User model
+func (User) TableName() string {
+	return "users"
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WaitlistStatus represents where a waitlist entry is in the offer process
+type WaitlistStatus string
+
+const (
+	WaitlistWaiting WaitlistStatus = "WAITING"
+	WaitlistOffered WaitlistStatus = "OFFERED"
+	WaitlistBooked  WaitlistStatus = "BOOKED"
+	WaitlistExpired WaitlistStatus = "EXPIRED" // The patient let an offer lapse and left the queue
+)
+
+// OfferStatus represents the state of a waitlist offer
+type OfferStatus string
+
+const (
+	OfferPending  OfferStatus = "PENDING"
+	OfferAccepted OfferStatus = "ACCEPTED"
+	OfferExpired  OfferStatus = "EXPIRED"
+)
+
+// WaitlistEntry is a patient queued for the next opening with a doctor
+type WaitlistEntry struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	UserID        uint           `json:"user_id" gorm:"not null;index"`
+	DoctorID      uint           `json:"doctor_id" gorm:"not null;index"`
+	PreferredDate *time.Time     `json:"preferred_date,omitempty" gorm:"type:date"` // Only offer openings on this date when set
+	Duration      int            `json:"duration" gorm:"not null;default:30"`       // Minutes
+	Status        WaitlistStatus `json:"status" gorm:"type:varchar(20);default:'WAITING';index"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TableName specifies the table name for the WaitlistEntry model
+func (WaitlistEntry) TableName() string {
+	return "waitlist_entries"
+}
+
+// WaitlistOffer is a time-limited offer of an opened slot to a waitlisted patient
+type WaitlistOffer struct {
+	ID            uint        `json:"id" gorm:"primaryKey"`
+	Token         string      `json:"token" gorm:"type:varchar(64);not null;uniqueIndex"`
+	EntryID       uint        `json:"entry_id" gorm:"not null;index"`
+	UserID        uint        `json:"user_id" gorm:"not null"`
+	DoctorID      uint        `json:"doctor_id" gorm:"not null"`
+	StartTime     time.Time   `json:"start_time" gorm:"not null"`
+	EndTime       time.Time   `json:"end_time" gorm:"not null"`
+	ExpiresAt     time.Time   `json:"expires_at" gorm:"not null;index"`
+	Status        OfferStatus `json:"status" gorm:"type:varchar(20);default:'PENDING';index"`
+	AppointmentID *uint       `json:"appointment_id,omitempty"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+}
+
+// TableName specifies the table name for the WaitlistOffer model
+func (WaitlistOffer) TableName() string {
+	return "waitlist_offers"
+}
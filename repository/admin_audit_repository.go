@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"smart-doctor-booking-app/models"
+)
+
+// AdminAuditRepository interface defines the contract for admin audit data operations
+type AdminAuditRepository interface {
+	CreateAudit(audit *models.AdminAudit) error
+	GetAudits(limit, offset int) ([]models.AdminAudit, int64, error)
+}
+
+// adminAuditRepository implements AdminAuditRepository interface
+type adminAuditRepository struct {
+	db *gorm.DB
+}
+
+// NewAdminAuditRepository creates a new instance of AdminAuditRepository
+func NewAdminAuditRepository(db *gorm.DB) AdminAuditRepository {
+	return &adminAuditRepository{
+		db: db,
+	}
+}
+
+// CreateAudit saves an admin audit entry
+func (r *adminAuditRepository) CreateAudit(audit *models.AdminAudit) error {
+	if audit == nil {
+		return errors.New("admin audit cannot be nil")
+	}
+
+	if err := r.db.Create(audit).Error; err != nil {
+		return fmt.Errorf("failed to create admin audit: %w", err)
+	}
+
+	return nil
+}
+
+// GetAudits returns a page of admin audit entries, newest first, with the total count
+func (r *adminAuditRepository) GetAudits(limit, offset int) ([]models.AdminAudit, int64, error) {
+	var audits []models.AdminAudit
+	var total int64
+
+	if err := r.db.Model(&models.AdminAudit{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count admin audits: %w", err)
+	}
+
+	if err := r.db.Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&audits).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get admin audits: %w", err)
+	}
+
+	return audits, total, nil
+}
@@ -7,31 +7,71 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"smart-doctor-booking-app/models"
 	"smart-doctor-booking-app/utils"
 )
 
+// ErrAppointmentAlreadyCancelled is returned when cancelling an appointment that is already cancelled
+var ErrAppointmentAlreadyCancelled = errors.New("appointment is already cancelled")
+
+// BookingStep is extra work done inside a booking's transaction once the appointment is created.
+// Returning an error rolls the booking back with it.
+type BookingStep func(tx *gorm.DB, appointment *models.Appointment) error
+
 // AppointmentRepository interface defines the contract for appointment data operations
 type AppointmentRepository interface {
 	// Basic CRUD operations
-	GetUpcomingAppointments(userID int) ([]models.Appointment, error)
+	GetUpcomingAppointments(userID int, limit int) ([]models.Appointment, error)
+	GetUpcomingAppointmentsForUsers(userIDs []uint) (map[uint][]models.Appointment, error)
+	GetAppointmentChanges(userID *uint, since time.Time, limit int) ([]models.Appointment, error)
 	CreateAppointment(appointment *models.Appointment) error
 	GetAppointmentByID(id uint) (*models.Appointment, error)
 	GetAllAppointments() ([]models.Appointment, error)
 	UpdateAppointment(appointment *models.Appointment) error
 	DeleteAppointment(id uint) error
+	PurgeDeletedAppointments(deletedBefore time.Time) (int64, error)
 
 	// Smart scheduling operations
 	GetDoctorAvailability(doctorID uint, date time.Time) ([]models.TimeSlot, error)
 	GetDoctorAvailabilityRange(doctorID uint, startDate, endDate time.Time) (map[string][]models.TimeSlot, error)
 	CheckTimeSlotAvailability(doctorID uint, startTime, endTime time.Time) (bool, error)
-	BookTimeSlot(appointment *models.Appointment) error
-	CancelAppointment(appointmentID uint, cancelledBy, reason string) error
-	RescheduleAppointment(appointmentID uint, newStartTime, newEndTime time.Time) error
+	BookTimeSlot(appointment *models.Appointment, steps ...BookingStep) error
+	CancelAppointment(appointmentID uint, cancelledBy string, reason models.CancellationReason, detail string) error
+	ConfirmDepositHold(appointmentID uint, paidAt time.Time) error
+	ConfirmAppointment(appointmentID uint, confirmedBy string, confirmedAt time.Time) error
+	ReleaseExpiredHolds(now time.Time) ([]uint, error)
+	RescheduleAppointment(appointmentID uint, newStartTime, newEndTime time.Time) (uint, error)
+	RescheduleAppointmentInPlace(appointmentID uint, newStartTime, newEndTime time.Time) error
+	ChangeAppointmentType(appointmentID uint, newType models.AppointmentType, changedBy string) error
 	GetPatientAppointments(userID uint, status string) ([]models.Appointment, error)
+	GetReviewEligibleAppointments(userID uint) ([]models.Appointment, error)
+	CreateReview(review *models.Review) error
+	GetAppointmentHistory(doctorID, userID uint, before time.Time) ([]models.Appointment, error)
+	GetRescheduleChain(appointmentID uint) ([]models.Appointment, error)
 	GetDoctorAppointments(doctorID uint, date time.Time) ([]models.Appointment, error)
+	GetDoctorAppointmentsInRange(doctorID uint, from, to time.Time) ([]models.Appointment, error)
+	GetDoctorsAppointments(doctorIDs []uint, date time.Time) (map[uint][]models.Appointment, error)
+	CountDoctorsAppointments(doctorIDs []uint, date time.Time) (map[uint]int, error)
+	GetAcceptingBookings(doctorIDs []uint) (map[uint]bool, error)
 	DetectConflicts(doctorID uint, startTime, endTime time.Time, excludeAppointmentID *uint) ([]models.Appointment, error)
+	GetDueReminders(now time.Time) ([]models.Appointment, error)
+	GetRemindersDueBy(now, until time.Time) ([]models.Appointment, error)
+	CountActiveAppointments(userID uint, now time.Time) (int, error)
+	GetPatientActiveAppointmentsInRange(userID uint, from, to time.Time) ([]models.Appointment, error)
+	GetAttendanceStats(userID uint, lateCancellationWindow time.Duration) (*models.AttendanceStats, error)
+	GetSpecialtyStats(from, to time.Time) ([]models.SpecialtyStats, error)
+	GetAISpecialtyComparison(from, to time.Time) ([]models.AISpecialtyComparison, error)
+	SetAIClassification(appointmentID, specialtyID uint, confidence float64) error
+	GetDailyAppointmentCounts(doctorID uint, from, to time.Time, timezone string) ([]models.CalendarDayCount, error)
+	GetCancellationStats(from, to time.Time) ([]models.CancellationReasonStats, error)
+	GetDurationInsights(doctorID uint) (*models.DurationInsights, error)
+	GetHourlyDemand(doctorID uint, from, to time.Time) ([]models.HourlyDemand, error)
+	GetMonthlyReliability(doctorID uint, from, to time.Time) ([]models.DoctorReliabilityMonth, error)
+	MarkReminderSent(appointmentID uint, sentAt time.Time) error
+	UpdateReminderSettings(appointment *models.Appointment) error
+	UpdateAppointmentTags(appointmentID uint, tags []string) error
 	CreateTimeSlots(doctorID uint, date time.Time, startTime, endTime time.Time, duration int) error
 	GetTimeSlotsByDoctor(doctorID uint, date time.Time) ([]models.TimeSlot, error)
 	UpdateTimeSlotStatus(slotID uint, status models.SlotStatus, appointmentID *uint) error
@@ -50,17 +90,21 @@ func NewAppointmentRepository(db *gorm.DB) AppointmentRepository {
 }
 
 // GetUpcomingAppointments returns a slice of appointments with Status = 'SCHEDULED',
-// where AppointmentTime is after the current time, ordered ascending by AppointmentTime
-func (r *appointmentRepository) GetUpcomingAppointments(userID int) ([]models.Appointment, error) {
+// where AppointmentTime is after the current time, ordered ascending by AppointmentTime.
+// A positive limit returns only the soonest limit appointments.
+func (r *appointmentRepository) GetUpcomingAppointments(userID int, limit int) ([]models.Appointment, error) {
 	var appointments []models.Appointment
 	currentTime := time.Now()
 
 	// Query for upcoming scheduled appointments
-	result := r.db.Preload("Doctor").Preload("Doctor.Specialty").
+	query := r.db.Preload("Doctor").Preload("Doctor.Specialty").
 		Where("user_id = ? AND status = ? AND appointment_time > ?",
 			userID, models.StatusScheduled, currentTime).
-		Order("appointment_time ASC").
-		Find(&appointments)
+		Order("appointment_time ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	result := query.Find(&appointments)
 
 	if result.Error != nil {
 		return nil, result.Error
@@ -74,14 +118,64 @@ func (r *appointmentRepository) GetUpcomingAppointments(userID int) ([]models.Ap
 	return appointments, nil
 }
 
+// GetUpcomingAppointmentsForUsers returns the upcoming scheduled appointments of several patients
+// in one query, keyed by user ID. Patients without upcoming appointments map to an empty slice.
+func (r *appointmentRepository) GetUpcomingAppointmentsForUsers(userIDs []uint) (map[uint][]models.Appointment, error) {
+	byUser := make(map[uint][]models.Appointment, len(userIDs))
+	for _, userID := range userIDs {
+		byUser[userID] = []models.Appointment{}
+	}
+	if len(userIDs) == 0 {
+		return byUser, nil
+	}
+
+	var appointments []models.Appointment
+	if err := r.db.Preload("Doctor").Preload("Doctor.Specialty").
+		Where("user_id IN ? AND status = ? AND appointment_time > ?",
+			userIDs, models.StatusScheduled, time.Now()).
+		Order("user_id ASC, appointment_time ASC").
+		Find(&appointments).Error; err != nil {
+		return nil, fmt.Errorf("failed to get upcoming appointments: %w", err)
+	}
+
+	for _, appointment := range appointments {
+		byUser[appointment.UserID] = append(byUser[appointment.UserID], appointment)
+	}
+	return byUser, nil
+}
+
+// appointmentChangedAt is when an appointment last changed; soft deletion does not touch updated_at
+const appointmentChangedAt = "GREATEST(appointments.updated_at, COALESCE(appointments.deleted_at, appointments.updated_at))"
+
+// GetAppointmentChanges returns up to limit appointments, soft-deleted ones included, that changed
+// after since, oldest change first. A nil userID covers every patient.
+func (r *appointmentRepository) GetAppointmentChanges(userID *uint, since time.Time, limit int) ([]models.Appointment, error) {
+	query := r.db.Unscoped().Where(appointmentChangedAt+" > ?", since)
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+
+	var appointments []models.Appointment
+	if err := query.Order(appointmentChangedAt + " ASC, id ASC").
+		Limit(limit).
+		Find(&appointments).Error; err != nil {
+		return nil, fmt.Errorf("failed to get appointment changes: %w", err)
+	}
+
+	return appointments, nil
+}
+
 // CreateAppointment saves appointment to database
 func (r *appointmentRepository) CreateAppointment(appointment *models.Appointment) error {
 	if appointment == nil {
 		return gorm.ErrInvalidData
 	}
+	if err := appointment.CheckEndTime(); err != nil {
+		return err
+	}
 
 	if err := r.db.Create(appointment).Error; err != nil {
-		return err
+		return utils.WrapConstraintViolation(err)
 	}
 
 	return nil
@@ -147,6 +241,17 @@ func (r *appointmentRepository) DeleteAppointment(id uint) error {
 	return nil
 }
 
+// PurgeDeletedAppointments permanently removes appointments soft-deleted before the cutoff and returns how many were removed
+func (r *appointmentRepository) PurgeDeletedAppointments(deletedBefore time.Time) (int64, error) {
+	result := r.db.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).
+		Delete(&models.Appointment{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge deleted appointments: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
 // Smart Scheduling Methods
 
 // GetDoctorAvailability returns available time slots for a doctor on a specific date
@@ -196,8 +301,8 @@ func (r *appointmentRepository) CheckTimeSlotAvailability(doctorID uint, startTi
 
 	// Check for conflicting appointments
 	result := r.db.Model(&models.Appointment{}).
-		Where("doctor_id = ? AND status IN (?, ?) AND ((appointment_time < ? AND end_time > ?) OR (appointment_time < ? AND end_time > ?) OR (appointment_time >= ? AND end_time <= ?))",
-			doctorID, models.StatusScheduled, models.StatusConfirmed,
+		Where("doctor_id = ? AND status IN (?, ?, ?) AND ((appointment_time < ? AND end_time > ?) OR (appointment_time < ? AND end_time > ?) OR (appointment_time >= ? AND end_time <= ?))",
+			doctorID, models.StatusScheduled, models.StatusConfirmed, models.StatusPendingPayment,
 			endTime, startTime, // Overlaps at start
 			startTime, endTime, // Overlaps at end
 			startTime, endTime). // Completely within
@@ -210,132 +315,139 @@ func (r *appointmentRepository) CheckTimeSlotAvailability(doctorID uint, startTi
 	return count == 0, nil
 }
 
-// BookTimeSlot books a time slot with conflict detection and transaction support
-func (r *appointmentRepository) BookTimeSlot(appointment *models.Appointment) error {
+// BookTimeSlot books a time slot with conflict detection and transaction support. Any steps run
+// in the same transaction after the appointment is created; nil steps are skipped.
+func (r *appointmentRepository) BookTimeSlot(appointment *models.Appointment, steps ...BookingStep) error {
 	if appointment == nil {
 		return gorm.ErrInvalidData
 	}
 
-	// Begin transaction
-	tx := r.db.Begin()
-	if tx.Error != nil {
-		return fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	err := WithTransaction(r.db, func(tx *gorm.DB) error {
+		if err := r.bookTimeSlotInTx(tx, appointment); err != nil {
+			return err
+		}
+		for _, step := range steps {
+			if step == nil {
+				continue
+			}
+			if err := step(tx, appointment); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	// Ensure rollback on error
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-			// Log the panic instead of re-panicking
-			utils.LogError(fmt.Errorf("panic in CancelAppointment: %v", r), "Transaction panic recovered", nil)
-		}
-	}()
+	utils.LogInfo("Appointment booked successfully", map[string]interface{}{
+		"appointment_id":   appointment.ID,
+		"doctor_id":        appointment.DoctorID,
+		"user_id":          appointment.UserID,
+		"appointment_time": appointment.AppointmentTime,
+	})
+
+	return nil
+}
+
+// bookTimeSlotInTx checks the appointment for conflicts, creates it and marks its time slot booked
+// within tx. The caller owns the transaction and rolls it back on error.
+func (r *appointmentRepository) bookTimeSlotInTx(tx *gorm.DB, appointment *models.Appointment) error {
+	// Calculate end time if not provided, rejecting one that disagrees with the duration
+	if err := appointment.CheckEndTime(); err != nil {
+		return err
+	}
 
 	// Check for conflicts within transaction
 	conflicts, err := r.detectConflictsInTx(tx, appointment.DoctorID, appointment.AppointmentTime, appointment.EndTime, nil)
 	if err != nil {
-		tx.Rollback()
 		return fmt.Errorf("failed to check conflicts: %w", err)
 	}
 
 	if len(conflicts) > 0 {
-		tx.Rollback()
 		return errors.New("time slot is not available - conflicts detected")
 	}
 
-	// Calculate end time if not provided
-	if appointment.EndTime.IsZero() {
-		appointment.EndTime = appointment.AppointmentTime.Add(time.Duration(appointment.Duration) * time.Minute)
+	// Re-check the pause within the transaction, in case it was set after the caller's check
+	var doctor models.Doctor
+	if err := tx.Select("id", "accepting_bookings").Where("id = ?", appointment.DoctorID).Limit(1).Find(&doctor).Error; err != nil {
+		return fmt.Errorf("failed to get doctor: %w", err)
 	}
-
-	// Create appointment within transaction
-	if err := tx.Create(appointment).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to create appointment: %w", err)
+	if doctor.ID != 0 && !doctor.AcceptingBookings {
+		return models.ErrDoctorNotAcceptingBookings
 	}
 
-	// Update corresponding time slot status if exists
+	// Find the corresponding time slot, if one exists; the appointment takes place at its location
 	var timeSlot models.TimeSlot
 	result := tx.Where("doctor_id = ? AND date = ? AND start_time <= ? AND end_time >= ? AND status = ?",
 		appointment.DoctorID, appointment.AppointmentTime.Format("2006-01-02"),
 		appointment.AppointmentTime, appointment.EndTime, models.SlotAvailable).
 		First(&timeSlot)
 
+	if result.Error == nil && timeSlot.LocationID != nil {
+		if appointment.LocationID != nil && *appointment.LocationID != *timeSlot.LocationID {
+			return fmt.Errorf("%w: the doctor is at location %d at this time", models.ErrLocationMismatch, *timeSlot.LocationID)
+		}
+		appointment.LocationID = timeSlot.LocationID
+	}
+
+	// Create appointment within transaction
+	if err := tx.Create(appointment).Error; err != nil {
+		return fmt.Errorf("failed to create appointment: %w", utils.WrapConstraintViolation(err))
+	}
+
+	// Update corresponding time slot status if exists
 	if result.Error == nil {
-		// Update time slot status
 		timeSlot.Status = models.SlotBooked
 		timeSlot.AppointmentID = &appointment.ID
 		if err := tx.Save(&timeSlot).Error; err != nil {
-			tx.Rollback()
 			return fmt.Errorf("failed to update time slot: %w", err)
 		}
 	}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
-	utils.LogInfo("Appointment booked successfully", map[string]interface{}{
-		"appointment_id":   appointment.ID,
-		"doctor_id":        appointment.DoctorID,
-		"user_id":          appointment.UserID,
-		"appointment_time": appointment.AppointmentTime,
-	})
-
 	return nil
 }
 
 // CancelAppointment cancels an appointment and updates related time slots
-func (r *appointmentRepository) CancelAppointment(appointmentID uint, cancelledBy, reason string) error {
-	// Begin transaction
-	tx := r.db.Begin()
-	if tx.Error != nil {
-		return fmt.Errorf("failed to begin transaction: %w", tx.Error)
-	}
-
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-			// Log the panic instead of re-panicking
-			utils.LogError(fmt.Errorf("panic in transaction: %v", r), "Transaction panic recovered", nil)
+func (r *appointmentRepository) CancelAppointment(appointmentID uint, cancelledBy string, reason models.CancellationReason, detail string) error {
+	err := WithTransaction(r.db, func(tx *gorm.DB) error {
+		// Get appointment, locking the row so concurrent cancellations of it run one after the other
+		var appointment models.Appointment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&appointment, appointmentID).Error; err != nil {
+			return fmt.Errorf("appointment not found: %w", err)
 		}
-	}()
 
-	// Get appointment
-	var appointment models.Appointment
-	if err := tx.First(&appointment, appointmentID).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("appointment not found: %w", err)
-	}
+		if appointment.Status == models.StatusCancelled {
+			return ErrAppointmentAlreadyCancelled
+		}
 
-	// Update appointment status
-	now := time.Now()
-	appointment.Status = models.StatusCancelled
-	appointment.CancelledAt = &now
-	appointment.CancelledBy = cancelledBy
-	appointment.CancellationReason = reason
+		// Update appointment status
+		now := time.Now()
+		appointment.Status = models.StatusCancelled
+		appointment.CancelledAt = &now
+		appointment.CancelledBy = cancelledBy
+		appointment.CancellationCode = reason
+		appointment.CancellationReason = detail
 
-	if err := tx.Save(&appointment).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to update appointment: %w", err)
-	}
+		if err := tx.Save(&appointment).Error; err != nil {
+			return fmt.Errorf("failed to update appointment: %w", err)
+		}
 
-	// Free up the time slot
-	var timeSlot models.TimeSlot
-	result := tx.Where("appointment_id = ?", appointmentID).First(&timeSlot)
-	if result.Error == nil {
-		timeSlot.Status = models.SlotAvailable
-		timeSlot.AppointmentID = nil
-		if err := tx.Save(&timeSlot).Error; err != nil {
-			tx.Rollback()
-			return fmt.Errorf("failed to update time slot: %w", err)
+		// Free up the time slot
+		var timeSlot models.TimeSlot
+		result := tx.Where("appointment_id = ?", appointmentID).First(&timeSlot)
+		if result.Error == nil {
+			timeSlot.Status = models.SlotAvailable
+			timeSlot.AppointmentID = nil
+			if err := tx.Save(&timeSlot).Error; err != nil {
+				return fmt.Errorf("failed to update time slot: %w", err)
+			}
 		}
-	}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	utils.LogInfo("Appointment cancelled successfully", map[string]interface{}{
@@ -347,87 +459,165 @@ func (r *appointmentRepository) CancelAppointment(appointmentID uint, cancelledB
 	return nil
 }
 
-// RescheduleAppointment reschedules an appointment to a new time slot
-func (r *appointmentRepository) RescheduleAppointment(appointmentID uint, newStartTime, newEndTime time.Time) error {
-	// Begin transaction
-	tx := r.db.Begin()
-	if tx.Error != nil {
-		return fmt.Errorf("failed to begin transaction: %w", tx.Error)
+// ConfirmAppointment moves a scheduled appointment to CONFIRMED. The status check is part of the
+// update, so an appointment changed concurrently is left alone.
+func (r *appointmentRepository) ConfirmAppointment(appointmentID uint, confirmedBy string, confirmedAt time.Time) error {
+	result := r.db.Model(&models.Appointment{}).
+		Where("id = ? AND status = ?", appointmentID, models.StatusScheduled).
+		Updates(map[string]interface{}{
+			"status":       models.StatusConfirmed,
+			"confirmed_at": confirmedAt,
+			"confirmed_by": confirmedBy,
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to confirm appointment: %w", result.Error)
 	}
 
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-			// Log the panic instead of re-panicking
-			utils.LogError(fmt.Errorf("panic in RescheduleAppointment: %v", r), "Transaction panic recovered", nil)
-		}
-	}()
+	if result.RowsAffected == 0 {
+		return errors.New("appointment is no longer scheduled")
+	}
+
+	return nil
+}
 
-	// Get original appointment
-	var originalAppointment models.Appointment
-	if err := tx.First(&originalAppointment, appointmentID).Error; err != nil {
-		tx.Rollback()
+// ConfirmDepositHold commits a booking held pending payment once the deposit is paid
+func (r *appointmentRepository) ConfirmDepositHold(appointmentID uint, paidAt time.Time) error {
+	var appointment models.Appointment
+	if err := r.db.First(&appointment, appointmentID).Error; err != nil {
 		return fmt.Errorf("appointment not found: %w", err)
 	}
 
-	// Check for conflicts at new time
-	conflicts, err := r.detectConflictsInTx(tx, originalAppointment.DoctorID, newStartTime, newEndTime, &appointmentID)
-	if err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to check conflicts: %w", err)
+	if appointment.Status != models.StatusPendingPayment {
+		return errors.New("appointment is not awaiting a deposit")
 	}
 
-	if len(conflicts) > 0 {
-		tx.Rollback()
-		return errors.New("new time slot is not available - conflicts detected")
+	if appointment.HoldExpiresAt != nil && paidAt.After(*appointment.HoldExpiresAt) {
+		return errors.New("deposit hold has expired")
 	}
 
-	// Create new appointment
-	newAppointment := originalAppointment
-	newAppointment.ID = 0 // Reset ID for new record
-	newAppointment.AppointmentTime = newStartTime
-	newAppointment.EndTime = newEndTime
-	newAppointment.RescheduledFrom = &originalAppointment.ID
-	newAppointment.RescheduleCount = originalAppointment.RescheduleCount + 1
-	newAppointment.Status = models.StatusScheduled
+	result := r.db.Model(&models.Appointment{}).
+		Where("id = ? AND status = ?", appointmentID, models.StatusPendingPayment).
+		Updates(map[string]interface{}{
+			"status":          models.StatusScheduled,
+			"deposit_paid_at": paidAt,
+		})
 
-	if err := tx.Create(&newAppointment).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to create rescheduled appointment: %w", err)
+	if result.Error != nil {
+		return fmt.Errorf("failed to confirm deposit: %w", result.Error)
 	}
 
-	// Update original appointment
-	originalAppointment.Status = models.StatusRescheduled
-	originalAppointment.RescheduledTo = &newAppointment.ID
-	if err := tx.Save(&originalAppointment).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to update original appointment: %w", err)
+	if result.RowsAffected == 0 {
+		return errors.New("appointment is not awaiting a deposit")
 	}
 
-	// Update time slots
-	// Free old slot
-	var oldTimeSlot models.TimeSlot
-	result := tx.Where("appointment_id = ?", appointmentID).First(&oldTimeSlot)
-	if result.Error == nil {
-		oldTimeSlot.Status = models.SlotAvailable
-		oldTimeSlot.AppointmentID = nil
-		tx.Save(&oldTimeSlot)
+	return nil
+}
+
+// ReleaseExpiredHolds cancels bookings whose deposit hold expired before now, freeing their slots.
+// It returns the IDs of the released appointments.
+func (r *appointmentRepository) ReleaseExpiredHolds(now time.Time) ([]uint, error) {
+	var appointmentIDs []uint
+
+	result := r.db.Model(&models.Appointment{}).
+		Where("status = ? AND hold_expires_at IS NOT NULL AND hold_expires_at <= ?", models.StatusPendingPayment, now).
+		Pluck("id", &appointmentIDs)
+
+	if result.Error != nil {
+		return nil, result.Error
 	}
 
-	// Book new slot
-	var newTimeSlot models.TimeSlot
-	result = tx.Where("doctor_id = ? AND date = ? AND start_time <= ? AND end_time >= ? AND status = ?",
-		newAppointment.DoctorID, newStartTime.Format("2006-01-02"),
-		newStartTime, newEndTime, models.SlotAvailable).First(&newTimeSlot)
-	if result.Error == nil {
-		newTimeSlot.Status = models.SlotBooked
-		newTimeSlot.AppointmentID = &newAppointment.ID
-		tx.Save(&newTimeSlot)
+	var released []uint
+	for _, appointmentID := range appointmentIDs {
+		if err := r.CancelAppointment(appointmentID, "system", models.CancellationOther, "deposit hold expired"); err != nil {
+			if errors.Is(err, ErrAppointmentAlreadyCancelled) {
+				continue
+			}
+			utils.LogError(err, "Failed to release expired deposit hold", map[string]interface{}{
+				"appointment_id": appointmentID,
+			})
+			continue
+		}
+		released = append(released, appointmentID)
 	}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	return released, nil
+}
+
+// RescheduleAppointment reschedules an appointment to a new time slot by creating a new
+// appointment row and marking the original RESCHEDULED. It returns the new appointment's ID.
+func (r *appointmentRepository) RescheduleAppointment(appointmentID uint, newStartTime, newEndTime time.Time) (uint, error) {
+	var newAppointment models.Appointment
+	err := WithTransaction(r.db, func(tx *gorm.DB) error {
+		// Get original appointment
+		var originalAppointment models.Appointment
+		if err := tx.First(&originalAppointment, appointmentID).Error; err != nil {
+			return fmt.Errorf("appointment not found: %w", err)
+		}
+
+		// Check for conflicts at new time
+		conflicts, err := r.detectConflictsInTx(tx, originalAppointment.DoctorID, newStartTime, newEndTime, &appointmentID)
+		if err != nil {
+			return fmt.Errorf("failed to check conflicts: %w", err)
+		}
+
+		if len(conflicts) > 0 {
+			return errors.New("new time slot is not available - conflicts detected")
+		}
+
+		// Create new appointment
+		newAppointment = originalAppointment
+		newAppointment.ID = 0 // Reset ID for new record
+		newAppointment.AppointmentTime = newStartTime
+		newAppointment.EndTime = newEndTime
+		newAppointment.Duration = int(newEndTime.Sub(newStartTime).Minutes())
+		if err := newAppointment.CheckEndTime(); err != nil {
+			return err
+		}
+		newAppointment.RescheduledFrom = &originalAppointment.ID
+		newAppointment.RescheduleCount = originalAppointment.RescheduleCount + 1
+		newAppointment.Status = models.StatusScheduled
+		// The new time needs its own reminder and confirmation
+		newAppointment.ReminderSent = false
+		newAppointment.ReminderSentAt = nil
+		newAppointment.ConfirmedAt = nil
+
+		if err := tx.Create(&newAppointment).Error; err != nil {
+			return fmt.Errorf("failed to create rescheduled appointment: %w", utils.WrapConstraintViolation(err))
+		}
+
+		// Update original appointment
+		originalAppointment.Status = models.StatusRescheduled
+		originalAppointment.RescheduledTo = &newAppointment.ID
+		if err := tx.Save(&originalAppointment).Error; err != nil {
+			return fmt.Errorf("failed to update original appointment: %w", err)
+		}
+
+		// Update time slots
+		// Free old slot
+		var oldTimeSlot models.TimeSlot
+		result := tx.Where("appointment_id = ?", appointmentID).First(&oldTimeSlot)
+		if result.Error == nil {
+			oldTimeSlot.Status = models.SlotAvailable
+			oldTimeSlot.AppointmentID = nil
+			tx.Save(&oldTimeSlot)
+		}
+
+		// Book new slot
+		var newTimeSlot models.TimeSlot
+		result = tx.Where("doctor_id = ? AND date = ? AND start_time <= ? AND end_time >= ? AND status = ?",
+			newAppointment.DoctorID, newStartTime.Format("2006-01-02"),
+			newStartTime, newEndTime, models.SlotAvailable).First(&newTimeSlot)
+		if result.Error == nil {
+			newTimeSlot.Status = models.SlotBooked
+			newTimeSlot.AppointmentID = &newAppointment.ID
+			tx.Save(&newTimeSlot)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
 
 	utils.LogInfo("Appointment rescheduled successfully", map[string]interface{}{
@@ -437,6 +627,116 @@ func (r *appointmentRepository) RescheduleAppointment(appointmentID uint, newSta
 		"new_end_time":            newEndTime,
 	})
 
+	return newAppointment.ID, nil
+}
+
+// ChangeAppointmentType changes an appointment's type and records the previous type in the audit trail
+func (r *appointmentRepository) ChangeAppointmentType(appointmentID uint, newType models.AppointmentType, changedBy string) error {
+	return WithTransaction(r.db, func(tx *gorm.DB) error {
+		var appointment models.Appointment
+		if err := tx.First(&appointment, appointmentID).Error; err != nil {
+			return fmt.Errorf("appointment not found: %w", err)
+		}
+
+		audit := models.AppointmentAudit{
+			AppointmentID: appointment.ID,
+			Action:        models.AuditTypeChanged,
+			OldValue:      string(appointment.Type),
+			NewValue:      string(newType),
+			ChangedBy:     changedBy,
+		}
+		if err := tx.Create(&audit).Error; err != nil {
+			return fmt.Errorf("failed to record type change audit: %w", err)
+		}
+
+		if err := tx.Model(&appointment).Update("type", newType).Error; err != nil {
+			return fmt.Errorf("failed to update appointment type: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// RescheduleAppointmentInPlace moves an appointment to a new time on the same row,
+// keeping its ID stable and recording the previous time in the audit trail
+func (r *appointmentRepository) RescheduleAppointmentInPlace(appointmentID uint, newStartTime, newEndTime time.Time) error {
+	var appointment models.Appointment
+	err := WithTransaction(r.db, func(tx *gorm.DB) error {
+		// Get appointment
+		if err := tx.First(&appointment, appointmentID).Error; err != nil {
+			return fmt.Errorf("appointment not found: %w", err)
+		}
+
+		// Check for conflicts at new time, ignoring the appointment itself
+		conflicts, err := r.detectConflictsInTx(tx, appointment.DoctorID, newStartTime, newEndTime, &appointmentID)
+		if err != nil {
+			return fmt.Errorf("failed to check conflicts: %w", err)
+		}
+
+		if len(conflicts) > 0 {
+			return errors.New("new time slot is not available - conflicts detected")
+		}
+
+		// Record the change in the audit trail
+		audit := models.AppointmentAudit{
+			AppointmentID: appointment.ID,
+			Action:        models.AuditRescheduled,
+			OldValue:      appointment.AppointmentTime.Format(time.RFC3339) + "/" + appointment.EndTime.Format(time.RFC3339),
+			NewValue:      newStartTime.Format(time.RFC3339) + "/" + newEndTime.Format(time.RFC3339),
+		}
+		if err := tx.Create(&audit).Error; err != nil {
+			return fmt.Errorf("failed to record reschedule audit: %w", err)
+		}
+
+		// Update appointment times on the same row
+		appointment.AppointmentTime = newStartTime
+		appointment.EndTime = newEndTime
+		appointment.Duration = int(newEndTime.Sub(newStartTime).Minutes())
+		appointment.RescheduleCount++
+		appointment.ReminderSent = false
+		appointment.ReminderSentAt = nil
+
+		if err := tx.Save(&appointment).Error; err != nil {
+			return fmt.Errorf("failed to update appointment: %w", utils.WrapConstraintViolation(err))
+		}
+
+		// Free old slot
+		var oldTimeSlot models.TimeSlot
+		result := tx.Where("appointment_id = ?", appointmentID).First(&oldTimeSlot)
+		if result.Error == nil {
+			oldTimeSlot.Status = models.SlotAvailable
+			oldTimeSlot.AppointmentID = nil
+			if err := tx.Save(&oldTimeSlot).Error; err != nil {
+				return fmt.Errorf("failed to free old time slot: %w", err)
+			}
+		}
+
+		// Book new slot
+		var newTimeSlot models.TimeSlot
+		result = tx.Where("doctor_id = ? AND date = ? AND start_time <= ? AND end_time >= ? AND status = ?",
+			appointment.DoctorID, newStartTime.Format("2006-01-02"),
+			newStartTime, newEndTime, models.SlotAvailable).First(&newTimeSlot)
+		if result.Error == nil {
+			newTimeSlot.Status = models.SlotBooked
+			newTimeSlot.AppointmentID = &appointment.ID
+			if err := tx.Save(&newTimeSlot).Error; err != nil {
+				return fmt.Errorf("failed to book new time slot: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	utils.LogInfo("Appointment rescheduled in place successfully", map[string]interface{}{
+		"appointment_id":   appointmentID,
+		"new_start_time":   newStartTime,
+		"new_end_time":     newEndTime,
+		"reschedule_count": appointment.RescheduleCount,
+	})
+
 	return nil
 }
 
@@ -457,6 +757,128 @@ func (r *appointmentRepository) GetPatientAppointments(userID uint, status strin
 	return appointments, nil
 }
 
+// GetReviewEligibleAppointments returns the patient's completed appointments that have no review
+// yet, most recent first
+func (r *appointmentRepository) GetReviewEligibleAppointments(userID uint) ([]models.Appointment, error) {
+	var appointments []models.Appointment
+
+	result := r.db.Preload("Doctor").Preload("Doctor.Specialty").
+		Where("user_id = ? AND status = ?", userID, models.StatusCompleted).
+		Where("NOT EXISTS (SELECT 1 FROM reviews WHERE reviews.appointment_id = appointments.id)").
+		Order("appointment_time DESC").
+		Find(&appointments)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get review-eligible appointments: %w", result.Error)
+	}
+
+	return appointments, nil
+}
+
+// CreateReview saves a patient's review of one of their completed appointments, taking the doctor
+// from the appointment. It returns models.ErrNotReviewable for an appointment that is not the
+// patient's or not completed, and models.ErrAlreadyReviewed if it already has a review.
+func (r *appointmentRepository) CreateReview(review *models.Review) error {
+	if review == nil {
+		return errors.New("review cannot be nil")
+	}
+
+	return WithTransaction(r.db, func(tx *gorm.DB) error {
+		var appointment models.Appointment
+		if err := tx.First(&appointment, review.AppointmentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("appointment not found: %w", err)
+			}
+			return fmt.Errorf("failed to get appointment: %w", err)
+		}
+		if appointment.UserID != review.UserID || appointment.Status != models.StatusCompleted {
+			return models.ErrNotReviewable
+		}
+
+		var existing int64
+		if err := tx.Model(&models.Review{}).Where("appointment_id = ?", appointment.ID).Count(&existing).Error; err != nil {
+			return fmt.Errorf("failed to check existing review: %w", err)
+		}
+		if existing > 0 {
+			return models.ErrAlreadyReviewed
+		}
+
+		review.DoctorID = appointment.DoctorID
+		if err := tx.Create(review).Error; err != nil {
+			return fmt.Errorf("failed to create review: %w", utils.WrapConstraintViolation(err))
+		}
+
+		return nil
+	})
+}
+
+// GetAppointmentHistory returns a doctor and patient's appointments starting before the given time,
+// oldest first
+func (r *appointmentRepository) GetAppointmentHistory(doctorID, userID uint, before time.Time) ([]models.Appointment, error) {
+	var appointments []models.Appointment
+
+	result := r.db.Preload("Doctor").Preload("Doctor.Specialty").
+		Where("doctor_id = ? AND user_id = ? AND appointment_time < ?", doctorID, userID, before).
+		Order("appointment_time ASC").
+		Find(&appointments)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return appointments, nil
+}
+
+// maxRescheduleChainLength caps how many links GetRescheduleChain follows, guarding against
+// corrupt links that loop
+const maxRescheduleChainLength = 100
+
+// GetRescheduleChain follows RescheduledFrom back to the original booking and RescheduledTo
+// forward to the latest, returning the chain in that order
+func (r *appointmentRepository) GetRescheduleChain(appointmentID uint) ([]models.Appointment, error) {
+	var start models.Appointment
+	if err := r.db.First(&start, appointmentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("appointment not found")
+		}
+		return nil, err
+	}
+
+	seen := map[uint]bool{start.ID: true}
+	var earlier []models.Appointment
+	for previousID := start.RescheduledFrom; previousID != nil && !seen[*previousID] && len(seen) < maxRescheduleChainLength; {
+		var previous models.Appointment
+		if err := r.db.First(&previous, *previousID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				break
+			}
+			return nil, err
+		}
+		seen[previous.ID] = true
+		earlier = append(earlier, previous)
+		previousID = previous.RescheduledFrom
+	}
+
+	chain := make([]models.Appointment, 0, len(earlier)+1)
+	for i := len(earlier) - 1; i >= 0; i-- {
+		chain = append(chain, earlier[i])
+	}
+	chain = append(chain, start)
+
+	for nextID := start.RescheduledTo; nextID != nil && !seen[*nextID] && len(seen) < maxRescheduleChainLength; {
+		var next models.Appointment
+		if err := r.db.First(&next, *nextID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				break
+			}
+			return nil, err
+		}
+		seen[next.ID] = true
+		chain = append(chain, next)
+		nextID = next.RescheduledTo
+	}
+
+	return chain, nil
+}
+
 // GetDoctorAppointments returns appointments for a specific doctor on a specific date
 func (r *appointmentRepository) GetDoctorAppointments(doctorID uint, date time.Time) ([]models.Appointment, error) {
 	var appointments []models.Appointment
@@ -477,17 +899,442 @@ func (r *appointmentRepository) GetDoctorAppointments(doctorID uint, date time.T
 	return appointments, nil
 }
 
+// GetDoctorAppointmentsInRange returns a doctor's scheduled and confirmed appointments starting
+// in [from, to), ordered by time
+func (r *appointmentRepository) GetDoctorAppointmentsInRange(doctorID uint, from, to time.Time) ([]models.Appointment, error) {
+	var appointments []models.Appointment
+	if err := r.db.Where("doctor_id = ? AND appointment_time >= ? AND appointment_time < ? AND status IN ?",
+		doctorID, from, to, []models.AppointmentStatus{models.StatusScheduled, models.StatusConfirmed}).
+		Order("appointment_time ASC").
+		Find(&appointments).Error; err != nil {
+		return nil, fmt.Errorf("failed to get doctor appointments: %w", err)
+	}
+	return appointments, nil
+}
+
+// GetDoctorsAppointments returns the active appointments of several doctors on a date using a
+// single query, grouped by doctor ID
+func (r *appointmentRepository) GetDoctorsAppointments(doctorIDs []uint, date time.Time) (map[uint][]models.Appointment, error) {
+	var appointments []models.Appointment
+
+	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	endOfDay := startOfDay.Add(24 * time.Hour)
+
+	result := r.db.Where("doctor_id IN ? AND appointment_time >= ? AND appointment_time < ? AND status IN (?, ?)",
+		doctorIDs, startOfDay, endOfDay, models.StatusScheduled, models.StatusConfirmed).
+		Order("appointment_time ASC").
+		Find(&appointments)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	byDoctor := make(map[uint][]models.Appointment, len(doctorIDs))
+	for _, appointment := range appointments {
+		byDoctor[appointment.DoctorID] = append(byDoctor[appointment.DoctorID], appointment)
+	}
+
+	return byDoctor, nil
+}
+
+// GetAcceptingBookings returns whether each of the doctors is accepting new bookings. Doctors that
+// do not exist are left out of the map.
+func (r *appointmentRepository) GetAcceptingBookings(doctorIDs []uint) (map[uint]bool, error) {
+	var doctors []models.Doctor
+	if err := r.db.Select("id", "accepting_bookings").Where("id IN ?", doctorIDs).Find(&doctors).Error; err != nil {
+		return nil, fmt.Errorf("failed to get doctor booking status: %w", err)
+	}
+
+	accepting := make(map[uint]bool, len(doctors))
+	for _, doctor := range doctors {
+		accepting[doctor.ID] = doctor.AcceptingBookings
+	}
+	return accepting, nil
+}
+
+// CountDoctorsAppointments returns the number of active appointments per doctor on a specific date
+func (r *appointmentRepository) CountDoctorsAppointments(doctorIDs []uint, date time.Time) (map[uint]int, error) {
+	var rows []struct {
+		DoctorID uint
+		Count    int
+	}
+
+	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	endOfDay := startOfDay.Add(24 * time.Hour)
+
+	result := r.db.Model(&models.Appointment{}).
+		Select("doctor_id, COUNT(*) AS count").
+		Where("doctor_id IN ? AND appointment_time >= ? AND appointment_time < ? AND status IN (?, ?)",
+			doctorIDs, startOfDay, endOfDay, models.StatusScheduled, models.StatusConfirmed).
+		Group("doctor_id").
+		Scan(&rows)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	counts := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		counts[row.DoctorID] = row.Count
+	}
+
+	return counts, nil
+}
+
+// CountActiveAppointments counts a patient's upcoming appointments that still hold a slot
+func (r *appointmentRepository) CountActiveAppointments(userID uint, now time.Time) (int, error) {
+	var count int64
+
+	result := r.db.Model(&models.Appointment{}).
+		Where("user_id = ? AND appointment_time > ? AND status IN ?", userID, now,
+			[]models.AppointmentStatus{models.StatusScheduled, models.StatusConfirmed, models.StatusPendingPayment}).
+		Count(&count)
+
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return int(count), nil
+}
+
+// GetPatientActiveAppointmentsInRange returns a patient's appointments that still hold a slot and
+// overlap [from, to), with any doctor, ordered by time
+func (r *appointmentRepository) GetPatientActiveAppointmentsInRange(userID uint, from, to time.Time) ([]models.Appointment, error) {
+	var appointments []models.Appointment
+	if err := r.db.Where("user_id = ? AND appointment_time < ? AND end_time > ? AND status IN ?", userID, to, from,
+		[]models.AppointmentStatus{models.StatusScheduled, models.StatusConfirmed, models.StatusPendingPayment}).
+		Order("appointment_time ASC").
+		Find(&appointments).Error; err != nil {
+		return nil, fmt.Errorf("failed to get patient appointments: %w", err)
+	}
+	return appointments, nil
+}
+
+// GetAttendanceStats counts a patient's completed, no-show and late-cancelled appointments.
+// A cancellation is late when it happened within lateCancellationWindow of the appointment time.
+func (r *appointmentRepository) GetAttendanceStats(userID uint, lateCancellationWindow time.Duration) (*models.AttendanceStats, error) {
+	var row struct {
+		Completed     int
+		NoShows       int
+		LateCancelled int
+	}
+
+	result := r.db.Model(&models.Appointment{}).
+		Select(`COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0) AS completed,
+			COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0) AS no_shows,
+			COALESCE(SUM(CASE WHEN status = ? AND cancelled_at IS NOT NULL
+				AND cancelled_at > appointment_time - (? * INTERVAL '1 second') THEN 1 ELSE 0 END), 0) AS late_cancelled`,
+			models.StatusCompleted, models.StatusNoShow, models.StatusCancelled, int64(lateCancellationWindow.Seconds())).
+		Where("user_id = ?", userID).
+		Scan(&row)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	stats := &models.AttendanceStats{
+		UserID:        userID,
+		Completed:     row.Completed,
+		NoShows:       row.NoShows,
+		LateCancelled: row.LateCancelled,
+	}
+	stats.ComputeRates()
+
+	return stats, nil
+}
+
+// GetSpecialtyStats counts appointments in [from, to) grouped by the doctor's specialty
+func (r *appointmentRepository) GetSpecialtyStats(from, to time.Time) ([]models.SpecialtyStats, error) {
+	var stats []models.SpecialtyStats
+
+	result := r.db.Table("appointments").
+		Select(`specialties.id AS specialty_id, specialties.name AS specialty_name,
+			COUNT(*) AS total,
+			SUM(CASE WHEN appointments.status IN (?, ?) THEN 1 ELSE 0 END) AS booked,
+			SUM(CASE WHEN appointments.status = ? THEN 1 ELSE 0 END) AS completed,
+			SUM(CASE WHEN appointments.status = ? THEN 1 ELSE 0 END) AS cancelled`,
+			models.StatusScheduled, models.StatusConfirmed, models.StatusCompleted, models.StatusCancelled).
+		Joins("JOIN doctors ON doctors.id = appointments.doctor_id").
+		Joins("JOIN specialties ON specialties.id = doctors.specialty_id").
+		Where("appointments.deleted_at IS NULL AND appointments.appointment_time >= ? AND appointments.appointment_time < ?", from, to).
+		Group("specialties.id, specialties.name").
+		Order("total DESC, specialties.name ASC").
+		Scan(&stats)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return stats, nil
+}
+
+// GetAISpecialtyComparison groups the classified appointments in [from, to) by AI-suggested and
+// booked specialty
+func (r *appointmentRepository) GetAISpecialtyComparison(from, to time.Time) ([]models.AISpecialtyComparison, error) {
+	var comparison []models.AISpecialtyComparison
+
+	result := r.db.Table("appointments").
+		Select(`ai.id AS ai_specialty_id, ai.name AS ai_specialty_name,
+			booked.id AS booked_specialty_id, booked.name AS booked_specialty_name,
+			COUNT(*) AS count,
+			COALESCE(AVG(appointments.ai_confidence), 0) AS average_confidence,
+			ai.id = booked.id AS matched`).
+		Joins("JOIN doctors ON doctors.id = appointments.doctor_id").
+		Joins("JOIN specialties booked ON booked.id = doctors.specialty_id").
+		Joins("JOIN specialties ai ON ai.id = appointments.ai_specialty_id").
+		Where("appointments.deleted_at IS NULL AND appointments.appointment_time >= ? AND appointments.appointment_time < ?", from, to).
+		Group("ai.id, ai.name, booked.id, booked.name").
+		Order("count DESC, ai.name ASC, booked.name ASC").
+		Scan(&comparison)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return comparison, nil
+}
+
+// SetAIClassification stores the AI's specialty classification of an appointment's symptom
+func (r *appointmentRepository) SetAIClassification(appointmentID, specialtyID uint, confidence float64) error {
+	result := r.db.Model(&models.Appointment{}).
+		Where("id = ?", appointmentID).
+		Updates(map[string]interface{}{
+			"ai_specialty_id": specialtyID,
+			"ai_confidence":   confidence,
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to store AI classification: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("appointment not found")
+	}
+
+	return nil
+}
+
+// GetMonthlyReliability counts a doctor's appointments in [from, to) per calendar month, with how
+// many were cancelled or missed. Rescheduled appointments are left out, since their replacement counts.
+// Months without appointments are absent.
+func (r *appointmentRepository) GetMonthlyReliability(doctorID uint, from, to time.Time) ([]models.DoctorReliabilityMonth, error) {
+	var months []models.DoctorReliabilityMonth
+
+	result := r.db.Table("appointments").
+		Select(`TO_CHAR(appointment_time, 'YYYY-MM') AS month, COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE status = ?) AS cancelled,
+			COUNT(*) FILTER (WHERE status = ?) AS no_shows`, models.StatusCancelled, models.StatusNoShow).
+		Where("deleted_at IS NULL AND doctor_id = ? AND appointment_time >= ? AND appointment_time < ?", doctorID, from, to).
+		Where("status <> ?", models.StatusRescheduled).
+		Group("1").
+		Order("1").
+		Scan(&months)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return months, nil
+}
+
+// GetHourlyDemand counts a doctor's bookings in [from, to) by weekday and starting hour.
+// Cancelled and rescheduled bookings still count, since they show when patients wanted to come.
+func (r *appointmentRepository) GetHourlyDemand(doctorID uint, from, to time.Time) ([]models.HourlyDemand, error) {
+	var demand []models.HourlyDemand
+
+	result := r.db.Table("appointments").
+		Select("EXTRACT(DOW FROM appointment_time)::int AS weekday, EXTRACT(HOUR FROM appointment_time)::int AS hour, COUNT(*) AS count").
+		Where("deleted_at IS NULL AND doctor_id = ? AND appointment_time >= ? AND appointment_time < ?", doctorID, from, to).
+		Group("1, 2").
+		Order("1, 2").
+		Scan(&demand)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return demand, nil
+}
+
+// GetDurationInsights aggregates the durations of a doctor's completed appointments: their
+// distribution, average, median and 90th percentile
+func (r *appointmentRepository) GetDurationInsights(doctorID uint) (*models.DurationInsights, error) {
+	insights := &models.DurationInsights{DoctorID: doctorID, Distribution: []models.DurationCount{}}
+
+	var summary struct {
+		SampleSize   int
+		Average      float64
+		Median       float64
+		Percentile90 float64
+	}
+	if err := r.db.Table("appointments").
+		Select(`COUNT(*) AS sample_size,
+			COALESCE(AVG(duration), 0) AS average,
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY duration), 0) AS median,
+			COALESCE(percentile_cont(0.9) WITHIN GROUP (ORDER BY duration), 0) AS percentile90`).
+		Where("deleted_at IS NULL AND doctor_id = ? AND status = ?", doctorID, models.StatusCompleted).
+		Scan(&summary).Error; err != nil {
+		return nil, fmt.Errorf("failed to summarize appointment durations: %w", err)
+	}
+	insights.SampleSize = summary.SampleSize
+	insights.Average = summary.Average
+	insights.Median = summary.Median
+	insights.Percentile90 = summary.Percentile90
+
+	if err := r.db.Table("appointments").
+		Select("duration, COUNT(*) AS count").
+		Where("deleted_at IS NULL AND doctor_id = ? AND status = ?", doctorID, models.StatusCompleted).
+		Group("duration").
+		Order("duration ASC").
+		Scan(&insights.Distribution).Error; err != nil {
+		return nil, fmt.Errorf("failed to get appointment duration distribution: %w", err)
+	}
+
+	return insights, nil
+}
+
+// GetCancellationStats counts appointments cancelled in [from, to) grouped by reason code.
+// Cancellations recorded before reason codes existed are counted as OTHER.
+func (r *appointmentRepository) GetCancellationStats(from, to time.Time) ([]models.CancellationReasonStats, error) {
+	var stats []models.CancellationReasonStats
+
+	result := r.db.Table("appointments").
+		Select("COALESCE(NULLIF(cancellation_code, ''), ?) AS reason, COUNT(*) AS count", models.CancellationOther).
+		Where("deleted_at IS NULL AND status = ? AND cancelled_at >= ? AND cancelled_at < ?", models.StatusCancelled, from, to).
+		Group("1").
+		Order("count DESC").
+		Scan(&stats)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return stats, nil
+}
+
+// GetDailyAppointmentCounts counts a doctor's non-cancelled appointments in [from, to) grouped
+// by calendar day, where days are taken in the given IANA timezone
+func (r *appointmentRepository) GetDailyAppointmentCounts(doctorID uint, from, to time.Time, timezone string) ([]models.CalendarDayCount, error) {
+	var counts []models.CalendarDayCount
+
+	result := r.db.Table("appointments").
+		Select("TO_CHAR(appointment_time AT TIME ZONE ?, 'YYYY-MM-DD') AS date, COUNT(*) AS count", timezone).
+		Where("deleted_at IS NULL AND doctor_id = ? AND appointment_time >= ? AND appointment_time < ?", doctorID, from, to).
+		Where("status NOT IN ?", []models.AppointmentStatus{models.StatusCancelled, models.StatusRescheduled}).
+		Group("1").
+		Order("1").
+		Scan(&counts)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return counts, nil
+}
+
 // DetectConflicts detects scheduling conflicts for a doctor within a time range
 func (r *appointmentRepository) DetectConflicts(doctorID uint, startTime, endTime time.Time, excludeAppointmentID *uint) ([]models.Appointment, error) {
 	return r.detectConflictsInTx(r.db, doctorID, startTime, endTime, excludeAppointmentID)
 }
 
+// GetDueReminders returns active upcoming appointments whose reminder time has been reached
+// and whose reminder has not been sent yet
+func (r *appointmentRepository) GetDueReminders(now time.Time) ([]models.Appointment, error) {
+	return r.GetRemindersDueBy(now, now)
+}
+
+// GetRemindersDueBy returns active upcoming appointments whose unsent reminder is due by until,
+// including reminders that are already overdue. The reminder offset is applied in Go rather than
+// with database interval arithmetic so the query runs on any SQL backend.
+func (r *appointmentRepository) GetRemindersDueBy(now, until time.Time) ([]models.Appointment, error) {
+	var candidates []models.Appointment
+
+	result := r.db.Where("status IN (?, ?) AND reminder_enabled = ? AND reminder_sent = ? AND appointment_time > ?",
+		models.StatusScheduled, models.StatusConfirmed, true, false, now).
+		Order("appointment_time ASC").
+		Find(&candidates)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	appointments := make([]models.Appointment, 0, len(candidates))
+	for _, appointment := range candidates {
+		remindAt := appointment.AppointmentTime.Add(-time.Duration(appointment.ReminderTime) * time.Minute)
+		if !remindAt.After(until) {
+			appointments = append(appointments, appointment)
+		}
+	}
+
+	return appointments, nil
+}
+
+// UpdateAppointmentTags replaces an appointment's tags
+func (r *appointmentRepository) UpdateAppointmentTags(appointmentID uint, tags []string) error {
+	result := r.db.Model(&models.Appointment{ID: appointmentID}).
+		Select("tags").
+		Updates(&models.Appointment{Tags: tags})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to update appointment tags: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// UpdateReminderSettings saves an appointment's reminder channel, offset, enabled flag and
+// delivery state
+func (r *appointmentRepository) UpdateReminderSettings(appointment *models.Appointment) error {
+	result := r.db.Model(&models.Appointment{}).
+		Where("id = ?", appointment.ID).
+		Updates(map[string]interface{}{
+			"reminder_enabled": appointment.ReminderEnabled,
+			"reminder_type":    appointment.ReminderType,
+			"reminder_time":    appointment.ReminderTime,
+			"reminder_sent":    appointment.ReminderSent,
+			"reminder_sent_at": appointment.ReminderSentAt,
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to update reminder settings: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// MarkReminderSent records that an appointment's reminder has been delivered
+func (r *appointmentRepository) MarkReminderSent(appointmentID uint, sentAt time.Time) error {
+	result := r.db.Model(&models.Appointment{}).
+		Where("id = ?", appointmentID).
+		Updates(map[string]interface{}{
+			"reminder_sent":    true,
+			"reminder_sent_at": sentAt,
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to mark reminder sent: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
 // detectConflictsInTx is a helper method for conflict detection within a transaction
 func (r *appointmentRepository) detectConflictsInTx(tx *gorm.DB, doctorID uint, startTime, endTime time.Time, excludeAppointmentID *uint) ([]models.Appointment, error) {
 	var conflicts []models.Appointment
 
-	query := tx.Where("doctor_id = ? AND status IN (?, ?) AND ((appointment_time < ? AND end_time > ?) OR (appointment_time < ? AND end_time > ?) OR (appointment_time >= ? AND end_time <= ?))",
-		doctorID, models.StatusScheduled, models.StatusConfirmed,
+	query := tx.Where("doctor_id = ? AND status IN (?, ?, ?) AND ((appointment_time < ? AND end_time > ?) OR (appointment_time < ? AND end_time > ?) OR (appointment_time >= ? AND end_time <= ?))",
+		doctorID, models.StatusScheduled, models.StatusConfirmed, models.StatusPendingPayment,
 		endTime, startTime, // Overlaps at start
 		startTime, endTime, // Overlaps at end
 		startTime, endTime) // Completely within
@@ -553,7 +1400,8 @@ func (r *appointmentRepository) GetTimeSlotsByDoctor(doctorID uint, date time.Ti
 	return timeSlots, nil
 }
 
-// UpdateTimeSlotStatus updates the status of a time slot
+// UpdateTimeSlotStatus updates the status of a time slot, rejecting illegal transitions
+// with a *models.SlotTransitionError. Repeating the current status is a no-op.
 func (r *appointmentRepository) UpdateTimeSlotStatus(slotID uint, status models.SlotStatus, appointmentID *uint) error {
 	var timeSlot models.TimeSlot
 
@@ -561,6 +1409,34 @@ func (r *appointmentRepository) UpdateTimeSlotStatus(slotID uint, status models.
 		return fmt.Errorf("time slot not found: %w", err)
 	}
 
+	if err := timeSlot.Status.ValidateTransition(status); err != nil {
+		return err
+	}
+
+	if timeSlot.Status == status && sameAppointmentRef(timeSlot.AppointmentID, appointmentID) {
+		return nil
+	}
+
+	switch status {
+	case models.SlotBooked:
+		if appointmentID == nil {
+			return &models.SlotTransitionError{From: timeSlot.Status, To: status, Reason: "a booked slot must reference an appointment"}
+		}
+	case models.SlotAvailable, models.SlotBlocked, models.SlotBreak:
+		if timeSlot.Status == models.SlotBooked && timeSlot.AppointmentID != nil {
+			var activeCount int64
+			if err := r.db.Model(&models.Appointment{}).
+				Where("id = ? AND status IN ?", *timeSlot.AppointmentID, []models.AppointmentStatus{models.StatusScheduled, models.StatusConfirmed}).
+				Count(&activeCount).Error; err != nil {
+				return fmt.Errorf("failed to check slot appointment: %w", err)
+			}
+			if activeCount > 0 {
+				return &models.SlotTransitionError{From: timeSlot.Status, To: status, Reason: "an active appointment still references the slot"}
+			}
+		}
+		appointmentID = nil
+	}
+
 	timeSlot.Status = status
 	timeSlot.AppointmentID = appointmentID
 
@@ -570,3 +1446,11 @@ func (r *appointmentRepository) UpdateTimeSlotStatus(slotID uint, status models.
 
 	return nil
 }
+
+// sameAppointmentRef reports whether two optional appointment references point to the same appointment
+func sameAppointmentRef(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,335 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository/repotest"
+)
+
+func TestRescheduleAppointmentInPlaceKeepsID(t *testing.T) {
+	db := repotest.Open(t)
+	repo := NewAppointmentRepository(db)
+	day := repotest.Day(0)
+
+	appointment := repotest.Appointment(1, 1, day.Add(9*time.Hour), 30, models.StatusScheduled)
+	repotest.MustCreate(t, db, appointment)
+	oldSlot := repotest.Slot(1, day, 9, 0, 30, models.SlotBooked)
+	oldSlot.AppointmentID = &appointment.ID
+	newSlot := repotest.Slot(1, day, 14, 0, 30, models.SlotAvailable)
+	repotest.MustCreate(t, db, oldSlot, newSlot)
+
+	newStart := day.Add(14 * time.Hour)
+	if err := repo.RescheduleAppointmentInPlace(appointment.ID, newStart, newStart.Add(30*time.Minute)); err != nil {
+		t.Fatalf("RescheduleAppointmentInPlace returned error: %v", err)
+	}
+
+	var appointments []models.Appointment
+	if err := db.Find(&appointments).Error; err != nil {
+		t.Fatalf("failed to load appointments: %v", err)
+	}
+	if len(appointments) != 1 {
+		t.Fatalf("expected the reschedule to keep a single appointment row, got %d", len(appointments))
+	}
+	moved := appointments[0]
+	if moved.ID != appointment.ID {
+		t.Errorf("expected ID %d to be kept, got %d", appointment.ID, moved.ID)
+	}
+	if !moved.AppointmentTime.Equal(newStart) || moved.RescheduleCount != 1 || moved.Status != models.StatusScheduled {
+		t.Errorf("unexpected rescheduled appointment: time %v, count %d, status %s",
+			moved.AppointmentTime, moved.RescheduleCount, moved.Status)
+	}
+
+	var slots []models.TimeSlot
+	if err := db.Order("start_time ASC").Find(&slots).Error; err != nil {
+		t.Fatalf("failed to load slots: %v", err)
+	}
+	if slots[0].Status != models.SlotAvailable || slots[0].AppointmentID != nil {
+		t.Errorf("expected the old slot to be freed, got %s", slots[0].Status)
+	}
+	if slots[1].Status != models.SlotBooked || slots[1].AppointmentID == nil || *slots[1].AppointmentID != appointment.ID {
+		t.Errorf("expected the new slot to be booked for appointment %d", appointment.ID)
+	}
+
+	var audits int64
+	db.Model(&models.AppointmentAudit{}).Where("appointment_id = ? AND action = ?", appointment.ID, models.AuditRescheduled).Count(&audits)
+	if audits != 1 {
+		t.Errorf("expected one reschedule audit entry, got %d", audits)
+	}
+}
+
+func TestRescheduleAppointmentNeedsNewReminderAndConfirmation(t *testing.T) {
+	db := repotest.Open(t)
+	repo := NewAppointmentRepository(db)
+	day := repotest.Day(0)
+
+	sentAt := day.Add(8 * time.Hour)
+	appointment := repotest.Appointment(1, 1, day.Add(9*time.Hour), 30, models.StatusConfirmed)
+	appointment.ReminderSent = true
+	appointment.ReminderSentAt = &sentAt
+	appointment.ConfirmedAt = &sentAt
+	repotest.MustCreate(t, db, appointment)
+
+	newStart := repotest.Day(1).Add(9 * time.Hour)
+	newID, err := repo.RescheduleAppointment(appointment.ID, newStart, newStart.Add(30*time.Minute))
+	if err != nil {
+		t.Fatalf("RescheduleAppointment returned error: %v", err)
+	}
+
+	var moved models.Appointment
+	if err := db.First(&moved, newID).Error; err != nil {
+		t.Fatalf("failed to load rescheduled appointment: %v", err)
+	}
+	if moved.ReminderSent || moved.ReminderSentAt != nil {
+		t.Errorf("expected the new appointment's reminder to be unsent, got sent=%v at %v", moved.ReminderSent, moved.ReminderSentAt)
+	}
+	if moved.ConfirmedAt != nil || moved.Status != models.StatusScheduled {
+		t.Errorf("expected the new appointment to await confirmation, got %s confirmed at %v", moved.Status, moved.ConfirmedAt)
+	}
+
+	var original models.Appointment
+	if err := db.First(&original, appointment.ID).Error; err != nil {
+		t.Fatalf("failed to load original appointment: %v", err)
+	}
+	if !original.ReminderSent || original.Status != models.StatusRescheduled {
+		t.Errorf("expected the original to keep its sent reminder and be marked rescheduled, got sent=%v status %s", original.ReminderSent, original.Status)
+	}
+}
+
+func TestGetSpecialtyStatsGroupsBySpecialty(t *testing.T) {
+	db := repotest.Open(t)
+	repo := NewAppointmentRepository(db)
+	day := repotest.Day(0)
+
+	repotest.MustCreate(t, db,
+		&models.Specialty{ID: 1, Name: "Cardiology"},
+		&models.Specialty{ID: 2, Name: "Dermatology"},
+		&models.Doctor{ID: 1, Name: "Heart", SpecialtyID: 1, IsActive: true},
+		&models.Doctor{ID: 2, Name: "Heart Too", SpecialtyID: 1, IsActive: true},
+		&models.Doctor{ID: 3, Name: "Skin", SpecialtyID: 2, IsActive: true},
+		repotest.Appointment(1, 1, day.Add(9*time.Hour), 30, models.StatusScheduled),
+		repotest.Appointment(2, 1, day.Add(10*time.Hour), 30, models.StatusCompleted),
+		repotest.Appointment(3, 2, day.Add(9*time.Hour), 30, models.StatusConfirmed),
+		repotest.Appointment(4, 2, day.Add(11*time.Hour), 30, models.StatusCancelled),
+		repotest.Appointment(5, 3, day.Add(9*time.Hour), 30, models.StatusCompleted),
+		repotest.Appointment(6, 3, day.Add(10*time.Hour), 30, models.StatusNoShow),
+		// Outside the range
+		repotest.Appointment(7, 3, repotest.Day(7).Add(9*time.Hour), 30, models.StatusScheduled),
+	)
+
+	stats, err := repo.GetSpecialtyStats(day, repotest.Day(1))
+	if err != nil {
+		t.Fatalf("GetSpecialtyStats returned error: %v", err)
+	}
+
+	want := []models.SpecialtyStats{
+		{SpecialtyID: 1, SpecialtyName: "Cardiology", Total: 4, Booked: 2, Completed: 1, Cancelled: 1},
+		{SpecialtyID: 2, SpecialtyName: "Dermatology", Total: 2, Booked: 0, Completed: 1, Cancelled: 0},
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("expected %d specialties, got %+v", len(want), stats)
+	}
+	for i := range want {
+		if stats[i] != want[i] {
+			t.Errorf("row %d: expected %+v, got %+v", i, want[i], stats[i])
+		}
+	}
+}
+
+func TestCreateAppointmentRejectsInconsistentEndTime(t *testing.T) {
+	db := repotest.Open(t)
+	repo := NewAppointmentRepository(db)
+	start := repotest.Day(0).Add(9 * time.Hour)
+
+	inconsistent := repotest.Appointment(1, 1, start, 30, models.StatusScheduled)
+	inconsistent.EndTime = start.Add(time.Hour)
+	if err := repo.CreateAppointment(inconsistent); !errors.Is(err, models.ErrEndTimeMismatch) {
+		t.Fatalf("expected ErrEndTimeMismatch, got %v", err)
+	}
+	var count int64
+	if err := db.Model(&models.Appointment{}).Count(&count).Error; err != nil {
+		t.Fatalf("failed to count appointments: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected the inconsistent appointment not to be stored, got %d rows", count)
+	}
+
+	missing := repotest.Appointment(1, 1, start, 30, models.StatusScheduled)
+	missing.EndTime = time.Time{}
+	if err := repo.CreateAppointment(missing); err != nil {
+		t.Fatalf("CreateAppointment returned error: %v", err)
+	}
+	var stored models.Appointment
+	if err := db.First(&stored, missing.ID).Error; err != nil {
+		t.Fatalf("failed to load appointment: %v", err)
+	}
+	if !stored.EndTime.Equal(start.Add(30 * time.Minute)) {
+		t.Errorf("expected the end time corrected to %v, got %v", start.Add(30*time.Minute), stored.EndTime)
+	}
+}
+
+func TestGetAppointmentHistoryReturnsOnlyThePair(t *testing.T) {
+	db := repotest.Open(t)
+	repo := NewAppointmentRepository(db)
+	at := func(offset, hour int) time.Time { return repotest.Day(offset).Add(time.Duration(hour) * time.Hour) }
+	repotest.MustCreate(t, db,
+		&models.Specialty{ID: 1, Name: "General Practice"},
+		&models.Doctor{ID: 1, Name: "Dr. One", SpecialtyID: 1, IsActive: true},
+		&models.Doctor{ID: 2, Name: "Dr. Two", SpecialtyID: 1, IsActive: true},
+	)
+
+	later := repotest.Appointment(1, 1, at(1, 9), 30, models.StatusCompleted)
+	later.Notes = "Follow-up on bloods"
+	earlier := repotest.Appointment(1, 1, at(0, 9), 30, models.StatusCompleted)
+	earlier.Notes = "Initial visit"
+	repotest.MustCreate(t, db,
+		later,
+		earlier,
+		repotest.Appointment(1, 2, at(0, 10), 30, models.StatusCompleted), // same patient, other doctor
+		repotest.Appointment(2, 1, at(0, 11), 30, models.StatusCompleted), // same doctor, other patient
+		repotest.Appointment(1, 1, at(7, 9), 30, models.StatusScheduled),  // still upcoming
+	)
+
+	history, err := repo.GetAppointmentHistory(1, 1, at(2, 0))
+	if err != nil {
+		t.Fatalf("GetAppointmentHistory returned error: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("expected the pair's 2 past appointments, got %d", len(history))
+	}
+	if history[0].ID != earlier.ID || history[1].ID != later.ID {
+		t.Errorf("expected appointments %d then %d, got %d then %d", earlier.ID, later.ID, history[0].ID, history[1].ID)
+	}
+	if history[0].Notes != "Initial visit" || history[0].Doctor.Name != "Dr. One" {
+		t.Errorf("expected notes and doctor loaded, got %q and %q", history[0].Notes, history[0].Doctor.Name)
+	}
+}
+
+func TestGetRescheduleChainFollowsLinksInOrder(t *testing.T) {
+	db := repotest.Open(t)
+	repo := NewAppointmentRepository(db)
+
+	// Created latest first so the chain order can't come from ID order
+	var chain []*models.Appointment
+	for i := 3; i >= 0; i-- {
+		status := models.StatusRescheduled
+		if i == 3 {
+			status = models.StatusScheduled
+		}
+		appointment := repotest.Appointment(1, 1, repotest.Day(i).Add(9*time.Hour), 30, status)
+		repotest.MustCreate(t, db, appointment)
+		chain = append([]*models.Appointment{appointment}, chain...)
+	}
+	for i := 0; i < len(chain)-1; i++ {
+		if err := db.Model(chain[i]).Update("rescheduled_to", chain[i+1].ID).Error; err != nil {
+			t.Fatalf("failed to link appointment %d: %v", chain[i].ID, err)
+		}
+		if err := db.Model(chain[i+1]).Update("rescheduled_from", chain[i].ID).Error; err != nil {
+			t.Fatalf("failed to link appointment %d: %v", chain[i+1].ID, err)
+		}
+	}
+
+	for _, from := range []*models.Appointment{chain[0], chain[2], chain[3]} {
+		got, err := repo.GetRescheduleChain(from.ID)
+		if err != nil {
+			t.Fatalf("GetRescheduleChain returned error: %v", err)
+		}
+		if len(got) != len(chain) {
+			t.Fatalf("expected %d appointments from %d, got %d", len(chain), from.ID, len(got))
+		}
+		for i := range chain {
+			if got[i].ID != chain[i].ID {
+				t.Errorf("from %d: expected appointment %d at position %d, got %d", from.ID, chain[i].ID, i, got[i].ID)
+			}
+		}
+	}
+
+	if _, err := repo.GetRescheduleChain(999); err == nil {
+		t.Error("expected an error for an unknown appointment")
+	}
+}
+
+func TestGetUpcomingAppointmentsReturnsSoonestFirst(t *testing.T) {
+	db := repotest.Open(t)
+	repo := NewAppointmentRepository(db)
+	repotest.MustCreate(t, db,
+		&models.Specialty{ID: 1, Name: "General Practice"},
+		&models.Doctor{ID: 1, Name: "Doctor", SpecialtyID: 1, IsActive: true},
+	)
+	// Created out of order, with a past, a cancelled and another patient's appointment mixed in
+	for _, offset := range []int{3, 0, 4, 1, 2} {
+		repotest.MustCreate(t, db, repotest.Appointment(1, 1, repotest.Day(offset).Add(9*time.Hour), 30, models.StatusScheduled))
+	}
+	repotest.MustCreate(t, db,
+		repotest.Appointment(1, 1, time.Now().Add(-24*time.Hour), 30, models.StatusScheduled),
+		repotest.Appointment(1, 1, repotest.Day(0).Add(8*time.Hour), 30, models.StatusCancelled),
+		repotest.Appointment(2, 1, repotest.Day(0).Add(7*time.Hour), 30, models.StatusScheduled),
+	)
+
+	tests := []struct {
+		limit int
+		want  int
+	}{
+		{3, 3},
+		{1, 1},
+		{10, 5},
+		{0, 5},
+	}
+	for _, tt := range tests {
+		appointments, err := repo.GetUpcomingAppointments(1, tt.limit)
+		if err != nil {
+			t.Fatalf("limit %d: GetUpcomingAppointments returned error: %v", tt.limit, err)
+		}
+		if len(appointments) != tt.want {
+			t.Fatalf("limit %d: expected %d appointments, got %d", tt.limit, tt.want, len(appointments))
+		}
+		for i, appointment := range appointments {
+			if want := repotest.Day(i).Add(9 * time.Hour); !appointment.AppointmentTime.Equal(want) {
+				t.Errorf("limit %d: expected appointment %d at %v, got %v", tt.limit, i, want, appointment.AppointmentTime)
+			}
+		}
+	}
+}
+
+func TestGetRemindersDueByAppliesEachReminderOffset(t *testing.T) {
+	db := repotest.Open(t)
+	repo := NewAppointmentRepository(db)
+	now := repotest.Day(0).Add(8 * time.Hour)
+	until := now.Add(2 * time.Hour)
+	appointment := func(userID uint, in time.Duration, reminderTime int, status models.AppointmentStatus) *models.Appointment {
+		a := repotest.Appointment(userID, 1, now.Add(in), 30, status)
+		a.ReminderTime = reminderTime
+		return a
+	}
+
+	overdue := appointment(1, 30*time.Minute, 60, models.StatusConfirmed)
+	atCutoff := appointment(2, 3*time.Hour, 60, models.StatusScheduled)
+	sent := appointment(3, time.Hour, 30, models.StatusScheduled)
+	sent.ReminderSent = true
+	repotest.MustCreate(t, db,
+		overdue,
+		atCutoff,
+		sent,
+		appointment(4, 3*time.Hour, 59, models.StatusScheduled), // fires a minute after until
+		appointment(5, time.Hour, 30, models.StatusCancelled),
+		appointment(6, -time.Hour, 30, models.StatusScheduled), // already started
+	)
+
+	appointments, err := repo.GetRemindersDueBy(now, until)
+	if err != nil {
+		t.Fatalf("GetRemindersDueBy returned error: %v", err)
+	}
+	if len(appointments) != 2 || appointments[0].ID != overdue.ID || appointments[1].ID != atCutoff.ID {
+		t.Fatalf("expected appointments %d and %d in appointment order, got %+v", overdue.ID, atCutoff.ID, appointments)
+	}
+
+	due, err := repo.GetDueReminders(now)
+	if err != nil {
+		t.Fatalf("GetDueReminders returned error: %v", err)
+	}
+	if len(due) != 1 || due[0].ID != overdue.ID {
+		t.Errorf("expected only appointment %d to be due now, got %+v", overdue.ID, due)
+	}
+}
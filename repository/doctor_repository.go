@@ -3,8 +3,11 @@ package repository
 import (
 	"errors"
 	"fmt"
+	"math"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"smart-doctor-booking-app/models"
 )
@@ -31,8 +34,16 @@ type DoctorRepository interface {
 	GetDoctorByID(id uint) (*models.Doctor, error)
 	GetAllDoctors() ([]models.Doctor, error)
 	GetAllDoctorsPaginated(params PaginationParams) (*PaginatedResult, error)
+	GetDoctorsBySpecialty(specialtyID uint) ([]models.Doctor, error)
+	GetDoctorsBySpecialtyByEarliestAvailability(specialtyID uint, now time.Time) ([]models.Doctor, error)
+	GetDoctorSummary(doctorID uint) (*models.DoctorSummary, error)
+	GetBookableSpecialties() ([]models.BookableSpecialty, error)
+	GetSpecialtyByName(name string) (*models.Specialty, error)
 	UpdateDoctor(doctor *models.Doctor) error
+	SetDoctorsActive(ids []uint, isActive bool) ([]models.Doctor, error)
+	SetAcceptingBookings(id uint, accepting bool) (*models.Doctor, error)
 	DeleteDoctor(id uint) error
+	PurgeDeletedDoctors(deletedBefore time.Time) (int64, error)
 }
 
 // doctorRepository implements DoctorRepository interface
@@ -54,43 +65,23 @@ func (r *doctorRepository) CreateDoctor(doctor *models.Doctor) error {
 		return errors.New("doctor cannot be nil")
 	}
 
-	// Use transaction to ensure atomicity
-	tx := r.db.Begin()
-	if tx.Error != nil {
-		return fmt.Errorf("failed to begin transaction: %w", tx.Error)
-	}
-
-	// Ensure transaction is rolled back on error
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-			// Log the panic instead of re-panicking
-			fmt.Printf("Panic in CreateDoctor transaction: %v\n", r)
+	return WithTransaction(r.db, func(tx *gorm.DB) error {
+		// Check if specialty exists within transaction
+		var specialty models.Specialty
+		if err := tx.First(&specialty, doctor.SpecialtyID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("specialty not found")
+			}
+			return fmt.Errorf("failed to verify specialty: %w", err)
 		}
-	}()
 
-	// Check if specialty exists within transaction
-	var specialty models.Specialty
-	if err := tx.First(&specialty, doctor.SpecialtyID).Error; err != nil {
-		tx.Rollback()
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("specialty not found")
+		// Save doctor to database within transaction
+		if err := tx.Create(doctor).Error; err != nil {
+			return fmt.Errorf("failed to create doctor: %w", err)
 		}
-		return fmt.Errorf("failed to verify specialty: %w", err)
-	}
-
-	// Save doctor to database within transaction
-	if err := tx.Create(doctor).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to create doctor: %w", err)
-	}
-
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
 
-	return nil
+		return nil
+	})
 }
 
 // GetDoctorByID retrieves a doctor by ID
@@ -114,6 +105,118 @@ func (r *doctorRepository) GetAllDoctors() ([]models.Doctor, error) {
 	return doctors, nil
 }
 
+// GetDoctorsBySpecialty retrieves the doctors in a specialty, ordered by name
+func (r *doctorRepository) GetDoctorsBySpecialty(specialtyID uint) ([]models.Doctor, error) {
+	var doctors []models.Doctor
+	if err := r.db.Preload("Specialty").
+		Where("specialty_id = ?", specialtyID).
+		Order("name ASC").
+		Find(&doctors).Error; err != nil {
+		return nil, fmt.Errorf("failed to get doctors by specialty: %w", err)
+	}
+	return doctors, nil
+}
+
+// GetDoctorsBySpecialtyByEarliestAvailability retrieves the active doctors in a specialty with
+// their earliest future available slot, soonest first; doctors with no open slot come last
+func (r *doctorRepository) GetDoctorsBySpecialtyByEarliestAvailability(specialtyID uint, now time.Time) ([]models.Doctor, error) {
+	var doctors []models.Doctor
+
+	earliest := r.db.Model(&models.TimeSlot{}).
+		Select("doctor_id, MIN(start_time) AS earliest_available").
+		Where("status = ? AND start_time > ?", models.SlotAvailable, now).
+		Group("doctor_id")
+
+	if err := r.db.Preload("Specialty").
+		Select("doctors.*, earliest.earliest_available").
+		Joins("LEFT JOIN (?) AS earliest ON earliest.doctor_id = doctors.id", earliest).
+		Where("doctors.specialty_id = ? AND doctors.is_active = ?", specialtyID, true).
+		Order("earliest.earliest_available ASC NULLS LAST, doctors.name ASC").
+		Find(&doctors).Error; err != nil {
+		return nil, fmt.Errorf("failed to get doctors by earliest availability: %w", err)
+	}
+	return doctors, nil
+}
+
+// GetDoctorSummary computes a doctor's profile aggregates from their appointments and reviews.
+// Soft-deleted appointments, and reviews of them, are excluded; the completion rate counts completed
+// appointments against completed plus no-shows, since cancelled and upcoming appointments have no
+// attendance outcome.
+func (r *doctorRepository) GetDoctorSummary(doctorID uint) (*models.DoctorSummary, error) {
+	var row struct {
+		PatientsSeen int
+		Completed    int
+		NoShows      int
+	}
+
+	if err := r.db.Model(&models.Appointment{}).
+		Select(`COUNT(DISTINCT CASE WHEN status = ? THEN user_id END) AS patients_seen,
+			COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0) AS completed,
+			COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0) AS no_shows`,
+			models.StatusCompleted, models.StatusCompleted, models.StatusNoShow).
+		Where("doctor_id = ?", doctorID).
+		Scan(&row).Error; err != nil {
+		return nil, fmt.Errorf("failed to get doctor summary: %w", err)
+	}
+
+	summary := &models.DoctorSummary{
+		DoctorID:     doctorID,
+		PatientsSeen: row.PatientsSeen,
+		Completed:    row.Completed,
+		NoShows:      row.NoShows,
+	}
+	if concluded := row.Completed + row.NoShows; concluded > 0 {
+		summary.CompletionRate = float64(row.Completed) / float64(concluded) * 100
+	}
+
+	var ratings struct {
+		Reviews       int
+		AverageRating *float64
+	}
+	if err := r.db.Model(&models.Review{}).
+		Select("COUNT(reviews.id) AS reviews, AVG(reviews.rating) AS average_rating").
+		Joins("JOIN appointments ON appointments.id = reviews.appointment_id AND appointments.deleted_at IS NULL").
+		Where("reviews.doctor_id = ?", doctorID).
+		Scan(&ratings).Error; err != nil {
+		return nil, fmt.Errorf("failed to get doctor ratings: %w", err)
+	}
+	summary.ReviewCount = ratings.Reviews
+	if ratings.AverageRating != nil {
+		average := math.Round(*ratings.AverageRating*100) / 100
+		summary.AverageRating = &average
+	}
+
+	return summary, nil
+}
+
+// GetBookableSpecialties retrieves the active specialties that have at least one active,
+// non-deleted doctor, with the number of such doctors, ordered by name
+func (r *doctorRepository) GetBookableSpecialties() ([]models.BookableSpecialty, error) {
+	var specialties []models.BookableSpecialty
+	if err := r.db.Model(&models.Specialty{}).
+		Select("specialties.id AS specialty_id, specialties.name, specialties.description, COUNT(doctors.id) AS active_doctors").
+		Joins("JOIN doctors ON doctors.specialty_id = specialties.id AND doctors.is_active = ? AND doctors.deleted_at IS NULL", true).
+		Where("specialties.is_active = ?", true).
+		Group("specialties.id, specialties.name, specialties.description").
+		Order("specialties.name ASC").
+		Scan(&specialties).Error; err != nil {
+		return nil, fmt.Errorf("failed to get bookable specialties: %w", err)
+	}
+	return specialties, nil
+}
+
+// GetSpecialtyByName retrieves the specialty whose name matches name, ignoring case
+func (r *doctorRepository) GetSpecialtyByName(name string) (*models.Specialty, error) {
+	var specialty models.Specialty
+	if err := r.db.Where("LOWER(name) = LOWER(?)", name).First(&specialty).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("specialty not found")
+		}
+		return nil, fmt.Errorf("failed to get specialty: %w", err)
+	}
+	return &specialty, nil
+}
+
 // GetAllDoctorsPaginated retrieves doctors with pagination
 func (r *doctorRepository) GetAllDoctorsPaginated(params PaginationParams) (*PaginatedResult, error) {
 	// Set default values if not provided
@@ -163,55 +266,95 @@ func (r *doctorRepository) UpdateDoctor(doctor *models.Doctor) error {
 		return errors.New("doctor cannot be nil")
 	}
 
-	// Use transaction to ensure atomicity
+	return WithTransaction(r.db, func(tx *gorm.DB) error {
+		// Check if doctor exists before updating
+		var existingDoctor models.Doctor
+		if err := tx.First(&existingDoctor, doctor.ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("doctor not found")
+			}
+			return fmt.Errorf("failed to find doctor: %w", err)
+		}
+
+		// If specialty is being updated, verify it exists
+		if doctor.SpecialtyID != existingDoctor.SpecialtyID {
+			var specialty models.Specialty
+			if err := tx.First(&specialty, doctor.SpecialtyID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errors.New("specialty not found")
+				}
+				return fmt.Errorf("failed to verify specialty: %w", err)
+			}
+		}
+
+		// Update doctor within transaction
+		if err := tx.Save(doctor).Error; err != nil {
+			return fmt.Errorf("failed to update doctor: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// SetDoctorsActive sets is_active on every listed doctor in one transaction and returns the
+// updated doctors. Nothing is changed if any ID does not match a doctor.
+func (r *doctorRepository) SetDoctorsActive(ids []uint, isActive bool) ([]models.Doctor, error) {
+	if len(ids) == 0 {
+		return nil, errors.New("at least one doctor ID is required")
+	}
+
 	tx := r.db.Begin()
 	if tx.Error != nil {
-		return fmt.Errorf("failed to begin transaction: %w", tx.Error)
+		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
 	}
 
-	// Ensure transaction is rolled back on error
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-			// Log the panic instead of re-panicking
-			fmt.Printf("Panic in UpdateDoctor transaction: %v\n", r)
-		}
-	}()
-
-	// Check if doctor exists before updating
-	var existingDoctor models.Doctor
-	if err := tx.First(&existingDoctor, doctor.ID).Error; err != nil {
+	var doctors []models.Doctor
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", ids).Find(&doctors).Error; err != nil {
 		tx.Rollback()
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("doctor not found")
-		}
-		return fmt.Errorf("failed to find doctor: %w", err)
+		return nil, fmt.Errorf("failed to get doctors: %w", err)
 	}
 
-	// If specialty is being updated, verify it exists
-	if doctor.SpecialtyID != existingDoctor.SpecialtyID {
-		var specialty models.Specialty
-		if err := tx.First(&specialty, doctor.SpecialtyID).Error; err != nil {
-			tx.Rollback()
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return errors.New("specialty not found")
-			}
-			return fmt.Errorf("failed to verify specialty: %w", err)
+	found := make(map[uint]bool, len(doctors))
+	for _, doctor := range doctors {
+		found[doctor.ID] = true
+	}
+	var missing []uint
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
 		}
 	}
+	if len(missing) > 0 {
+		tx.Rollback()
+		return nil, fmt.Errorf("doctors not found: %v", missing)
+	}
 
-	// Update doctor within transaction
-	if err := tx.Save(doctor).Error; err != nil {
+	if err := tx.Model(&models.Doctor{}).Where("id IN ?", ids).Update("is_active", isActive).Error; err != nil {
 		tx.Rollback()
-		return fmt.Errorf("failed to update doctor: %w", err)
+		return nil, fmt.Errorf("failed to update doctor statuses: %w", err)
 	}
 
-	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return nil
+	for i := range doctors {
+		doctors[i].IsActive = isActive
+	}
+	return doctors, nil
+}
+
+// SetAcceptingBookings pauses or resumes new bookings for a doctor and returns the updated doctor
+func (r *doctorRepository) SetAcceptingBookings(id uint, accepting bool) (*models.Doctor, error) {
+	result := r.db.Model(&models.Doctor{}).Where("id = ?", id).Update("accepting_bookings", accepting)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to update doctor booking status: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("doctor not found")
+	}
+
+	return r.GetDoctorByID(id)
 }
 
 // DeleteDoctor soft deletes a doctor by ID
@@ -221,3 +364,14 @@ func (r *doctorRepository) DeleteDoctor(id uint) error {
 	}
 	return nil
 }
+
+// PurgeDeletedDoctors permanently removes doctors soft-deleted before the cutoff and returns how many were removed
+func (r *doctorRepository) PurgeDeletedDoctors(deletedBefore time.Time) (int64, error) {
+	result := r.db.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).
+		Delete(&models.Doctor{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge deleted doctors: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository/repotest"
+)
+
+func TestGetDoctorSummaryAggregatesAttendance(t *testing.T) {
+	db := repotest.Open(t)
+	repo := NewDoctorRepository(db)
+	day := repotest.Day(0)
+	at := func(hour int) time.Time { return day.Add(time.Duration(hour) * time.Hour) }
+
+	deleted := repotest.Appointment(5, 1, at(14), 30, models.StatusCompleted)
+	completed := []*models.Appointment{
+		repotest.Appointment(1, 1, at(9), 30, models.StatusCompleted),
+		repotest.Appointment(1, 1, at(10), 30, models.StatusCompleted),
+		repotest.Appointment(2, 1, at(11), 30, models.StatusCompleted),
+	}
+	otherDoctors := repotest.Appointment(6, 2, at(9), 30, models.StatusCompleted)
+	repotest.MustCreate(t, db,
+		completed[0], completed[1], completed[2],
+		repotest.Appointment(3, 1, at(12), 30, models.StatusNoShow),
+		repotest.Appointment(4, 1, at(13), 30, models.StatusCancelled),
+		deleted,
+		// Another doctor's patients are not counted
+		otherDoctors,
+	)
+	// Ratings of 5, 4 and 4 count; those of the deleted appointment and the other doctor do not
+	repotest.MustCreate(t, db,
+		&models.Review{AppointmentID: completed[0].ID, UserID: 1, DoctorID: 1, Rating: 5},
+		&models.Review{AppointmentID: completed[1].ID, UserID: 1, DoctorID: 1, Rating: 4},
+		&models.Review{AppointmentID: completed[2].ID, UserID: 2, DoctorID: 1, Rating: 4},
+		&models.Review{AppointmentID: deleted.ID, UserID: 5, DoctorID: 1, Rating: 1},
+		&models.Review{AppointmentID: otherDoctors.ID, UserID: 6, DoctorID: 2, Rating: 1},
+	)
+	if err := db.Delete(deleted).Error; err != nil {
+		t.Fatalf("failed to soft-delete appointment: %v", err)
+	}
+
+	summary, err := repo.GetDoctorSummary(1)
+	if err != nil {
+		t.Fatalf("GetDoctorSummary returned error: %v", err)
+	}
+	if summary.DoctorID != 1 || summary.PatientsSeen != 2 || summary.Completed != 3 || summary.NoShows != 1 {
+		t.Errorf("expected 2 patients seen, 3 completed and 1 no-show, got %+v", summary)
+	}
+	if summary.CompletionRate != 75 {
+		t.Errorf("expected a 75%% completion rate, got %v", summary.CompletionRate)
+	}
+	if summary.AverageRating == nil || *summary.AverageRating != 4.33 || summary.ReviewCount != 3 {
+		t.Errorf("expected a 4.33 average over 3 reviews, got %v over %d", summary.AverageRating, summary.ReviewCount)
+	}
+
+	empty, err := repo.GetDoctorSummary(3)
+	if err != nil {
+		t.Fatalf("GetDoctorSummary returned error: %v", err)
+	}
+	if empty.PatientsSeen != 0 || empty.CompletionRate != 0 || empty.AverageRating != nil || empty.ReviewCount != 0 {
+		t.Errorf("expected empty aggregates for a doctor without appointments, got %+v", empty)
+	}
+}
+
+func TestGetBookableSpecialtiesExcludesSpecialtiesWithoutActiveDoctors(t *testing.T) {
+	db := repotest.Open(t)
+	repo := NewDoctorRepository(db)
+	retired := &models.Doctor{ID: 4, Name: "Dr. Retired", SpecialtyID: 2, IsActive: true}
+	repotest.MustCreate(t, db,
+		&models.Specialty{ID: 1, Name: "Cardiology"},
+		&models.Specialty{ID: 2, Name: "Dermatology"},
+		&models.Doctor{ID: 1, Name: "Dr. Heart", SpecialtyID: 1, IsActive: true},
+		&models.Doctor{ID: 2, Name: "Dr. Pulse", SpecialtyID: 1, IsActive: true},
+		&models.Doctor{ID: 3, Name: "Dr. Away", SpecialtyID: 1, IsActive: true},
+		&models.Doctor{ID: 5, Name: "Dr. Leave", SpecialtyID: 2, IsActive: true},
+		retired,
+	)
+	// Active defaults to true on create, so deactivate afterwards
+	if err := db.Model(&models.Doctor{}).Where("id IN ?", []uint{3, 5}).Update("is_active", false).Error; err != nil {
+		t.Fatalf("failed to deactivate doctors: %v", err)
+	}
+	if err := db.Delete(retired).Error; err != nil {
+		t.Fatalf("failed to soft-delete doctor: %v", err)
+	}
+
+	specialties, err := repo.GetBookableSpecialties()
+	if err != nil {
+		t.Fatalf("GetBookableSpecialties returned error: %v", err)
+	}
+	if len(specialties) != 1 {
+		t.Fatalf("expected only Cardiology to be bookable, got %+v", specialties)
+	}
+	if specialties[0].SpecialtyID != 1 || specialties[0].ActiveDoctors != 2 {
+		t.Errorf("expected Cardiology with 2 active doctors, got %+v", specialties[0])
+	}
+}
+
+func TestGetSpecialtyByNameIgnoresCase(t *testing.T) {
+	db := repotest.Open(t)
+	repo := NewDoctorRepository(db)
+	repotest.MustCreate(t, db,
+		&models.Specialty{ID: 1, Name: "Cardiology"},
+		&models.Specialty{ID: 2, Name: "General Practice"},
+	)
+
+	tests := []struct {
+		name   string
+		wantID uint
+	}{
+		{"Cardiology", 1},
+		{"cardiology", 1},
+		{"GENERAL PRACTICE", 2},
+		{"Dermatology", 0},
+	}
+
+	for _, tt := range tests {
+		specialty, err := repo.GetSpecialtyByName(tt.name)
+		if tt.wantID == 0 {
+			if err == nil || !strings.Contains(err.Error(), "not found") {
+				t.Errorf("%q: expected a not found error, got %v", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: GetSpecialtyByName returned error: %v", tt.name, err)
+			continue
+		}
+		if specialty.ID != tt.wantID {
+			t.Errorf("%q: expected specialty %d, got %d", tt.name, tt.wantID, specialty.ID)
+		}
+	}
+}
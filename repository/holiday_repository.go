@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-doctor-booking-app/models"
+)
+
+// HolidayRepository interface defines the contract for holiday data operations
+type HolidayRepository interface {
+	CreateHoliday(holiday *models.Holiday) error
+	GetHolidayByID(id uint) (*models.Holiday, error)
+	GetHolidays(from, to time.Time) ([]models.Holiday, error)
+	UpdateHoliday(holiday *models.Holiday) error
+	DeleteHoliday(id uint) error
+}
+
+// holidayRepository implements HolidayRepository interface
+type holidayRepository struct {
+	db *gorm.DB
+}
+
+// NewHolidayRepository creates a new instance of HolidayRepository
+func NewHolidayRepository(db *gorm.DB) HolidayRepository {
+	return &holidayRepository{
+		db: db,
+	}
+}
+
+// CreateHoliday saves a new holiday
+func (r *holidayRepository) CreateHoliday(holiday *models.Holiday) error {
+	if holiday == nil {
+		return errors.New("holiday cannot be nil")
+	}
+
+	if err := r.db.Create(holiday).Error; err != nil {
+		return fmt.Errorf("failed to create holiday: %w", err)
+	}
+
+	return nil
+}
+
+// GetHolidayByID retrieves a holiday by ID
+func (r *holidayRepository) GetHolidayByID(id uint) (*models.Holiday, error) {
+	var holiday models.Holiday
+	if err := r.db.First(&holiday, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("holiday not found")
+		}
+		return nil, fmt.Errorf("failed to get holiday: %w", err)
+	}
+	return &holiday, nil
+}
+
+// GetHolidays retrieves the holidays between from and to inclusive, ordered by date
+func (r *holidayRepository) GetHolidays(from, to time.Time) ([]models.Holiday, error) {
+	var holidays []models.Holiday
+	if err := r.db.Where("date BETWEEN ? AND ?", from.Format("2006-01-02"), to.Format("2006-01-02")).
+		Order("date ASC").
+		Find(&holidays).Error; err != nil {
+		return nil, fmt.Errorf("failed to get holidays: %w", err)
+	}
+	return holidays, nil
+}
+
+// UpdateHoliday saves changes to an existing holiday
+func (r *holidayRepository) UpdateHoliday(holiday *models.Holiday) error {
+	if holiday == nil {
+		return errors.New("holiday cannot be nil")
+	}
+
+	if err := r.db.Save(holiday).Error; err != nil {
+		return fmt.Errorf("failed to update holiday: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteHoliday removes a holiday
+func (r *holidayRepository) DeleteHoliday(id uint) error {
+	result := r.db.Delete(&models.Holiday{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete holiday: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("holiday not found")
+	}
+	return nil
+}
+
+// findHoliday returns the clinic-wide or doctor-specific holiday falling on date's calendar day,
+// or nil if the doctor is working that day
+func findHoliday(db *gorm.DB, doctorID uint, date time.Time) (*models.Holiday, error) {
+	var holidays []models.Holiday
+	if err := db.Where("date = ? AND (doctor_id IS NULL OR doctor_id = ?)", date.Format("2006-01-02"), doctorID).
+		Order("doctor_id NULLS FIRST").
+		Limit(1).
+		Find(&holidays).Error; err != nil {
+		return nil, fmt.Errorf("failed to check holidays: %w", err)
+	}
+	if len(holidays) == 0 {
+		return nil, nil
+	}
+	return &holidays[0], nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"smart-doctor-booking-app/models"
+)
+
+// LocationRepository interface defines the contract for clinic location data operations
+type LocationRepository interface {
+	CreateLocation(location *models.Location) error
+	GetLocationByID(id uint) (*models.Location, error)
+	GetActiveLocations() ([]models.Location, error)
+	GetLocationsByIDs(ids []uint) ([]models.Location, error)
+}
+
+// locationRepository implements LocationRepository interface
+type locationRepository struct {
+	db *gorm.DB
+}
+
+// NewLocationRepository creates a new instance of LocationRepository
+func NewLocationRepository(db *gorm.DB) LocationRepository {
+	return &locationRepository{
+		db: db,
+	}
+}
+
+// CreateLocation saves a new clinic location
+func (r *locationRepository) CreateLocation(location *models.Location) error {
+	if location == nil {
+		return errors.New("location cannot be nil")
+	}
+
+	if err := r.db.Create(location).Error; err != nil {
+		return fmt.Errorf("failed to create location: %w", err)
+	}
+
+	return nil
+}
+
+// GetLocationByID retrieves a location by ID
+func (r *locationRepository) GetLocationByID(id uint) (*models.Location, error) {
+	var location models.Location
+	if err := r.db.First(&location, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("location not found")
+		}
+		return nil, fmt.Errorf("failed to get location: %w", err)
+	}
+	return &location, nil
+}
+
+// GetActiveLocations returns the locations open for booking, ordered by name
+func (r *locationRepository) GetActiveLocations() ([]models.Location, error) {
+	var locations []models.Location
+	if err := r.db.Where("is_active = ?", true).
+		Order("name ASC").
+		Find(&locations).Error; err != nil {
+		return nil, fmt.Errorf("failed to get locations: %w", err)
+	}
+	return locations, nil
+}
+
+// GetLocationsByIDs retrieves the locations with the given IDs, including inactive ones
+func (r *locationRepository) GetLocationsByIDs(ids []uint) ([]models.Location, error) {
+	var locations []models.Location
+	if len(ids) == 0 {
+		return locations, nil
+	}
+	if err := r.db.Where("id IN ?", ids).Find(&locations).Error; err != nil {
+		return nil, fmt.Errorf("failed to get locations: %w", err)
+	}
+	return locations, nil
+}
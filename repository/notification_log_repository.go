@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-doctor-booking-app/models"
+)
+
+// NotificationLogRepository interface defines the contract for notification log data operations
+type NotificationLogRepository interface {
+	CreateLog(log *models.NotificationLog) error
+	GetLogsByAppointment(appointmentID uint) ([]models.NotificationLog, error)
+	GetUserLogs(userID uint, kind models.NotificationKind, from, to time.Time) ([]models.NotificationLog, error)
+	ExportLogs(from, to time.Time, status models.NotificationStatus, batch func([]models.NotificationLog) error) error
+}
+
+// exportBatchSize is how many notification logs ExportLogs loads per query
+const exportBatchSize = 500
+
+// notificationLogRepository implements NotificationLogRepository interface
+type notificationLogRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationLogRepository creates a new instance of NotificationLogRepository
+func NewNotificationLogRepository(db *gorm.DB) NotificationLogRepository {
+	return &notificationLogRepository{
+		db: db,
+	}
+}
+
+// CreateLog saves a notification delivery attempt
+func (r *notificationLogRepository) CreateLog(log *models.NotificationLog) error {
+	if log == nil {
+		return errors.New("notification log cannot be nil")
+	}
+
+	if err := r.db.Create(log).Error; err != nil {
+		return fmt.Errorf("failed to create notification log: %w", err)
+	}
+
+	return nil
+}
+
+// GetLogsByAppointment returns the notification attempts for an appointment, oldest first
+func (r *notificationLogRepository) GetLogsByAppointment(appointmentID uint) ([]models.NotificationLog, error) {
+	var logs []models.NotificationLog
+
+	if err := r.db.Where("appointment_id = ?", appointmentID).
+		Order("created_at ASC").
+		Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get notification logs: %w", err)
+	}
+
+	return logs, nil
+}
+
+// GetUserLogs returns the notification attempts of the given kind sent to a user in [from, to),
+// newest first
+func (r *notificationLogRepository) GetUserLogs(userID uint, kind models.NotificationKind, from, to time.Time) ([]models.NotificationLog, error) {
+	var logs []models.NotificationLog
+
+	if err := r.db.Where("user_id = ? AND kind = ? AND created_at >= ? AND created_at < ?", userID, kind, from, to).
+		Order("created_at DESC").
+		Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get notification logs: %w", err)
+	}
+
+	return logs, nil
+}
+
+// ExportLogs walks the notification logs created in [from, to), oldest first, optionally only those
+// with the given status, handing them to batch a few hundred at a time so large exports are never
+// held in memory at once. An error from batch stops the export and is returned.
+func (r *notificationLogRepository) ExportLogs(from, to time.Time, status models.NotificationStatus, batch func([]models.NotificationLog) error) error {
+	query := r.db.Model(&models.NotificationLog{}).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Order("id ASC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var logs []models.NotificationLog
+	result := query.FindInBatches(&logs, exportBatchSize, func(_ *gorm.DB, _ int) error {
+		return batch(logs)
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to export notification logs: %w", result.Error)
+	}
+
+	return nil
+}
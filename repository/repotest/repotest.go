@@ -0,0 +1,107 @@
+// Package repotest provides an in-memory database with the application schema for tests of
+// repositories and the services built on them. It is only imported from tests.
+package repotest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"smart-doctor-booking-app/models"
+)
+
+// Open opens a private in-memory SQLite database with the full schema migrated. Queries that
+// compare date columns with "2006-01-02" strings rely on Postgres storing bare dates, so triggers
+// trim the date columns SQLite would otherwise store as full timestamps.
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get underlying sql.DB: %v", err)
+	}
+	// Every connection to a shared-cache memory database sees the same data; one avoids lock errors
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(
+		&models.Location{}, &models.Specialty{}, &models.Doctor{}, &models.Appointment{}, &models.TimeSlot{}, &models.DoctorSchedule{},
+		&models.DoctorBreak{}, &models.AppointmentAudit{}, &models.NotificationLog{},
+		&models.User{}, &models.AdminAudit{}, &models.WaitlistEntry{}, &models.WaitlistOffer{}, &models.Holiday{}, &models.Review{},
+	); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	dateColumns := map[string]string{
+		"time_slots":       "date",
+		"doctor_breaks":    "date",
+		"holidays":         "date",
+		"waitlist_entries": "preferred_date",
+	}
+	for table, column := range dateColumns {
+		for i, event := range []string{"INSERT", "UPDATE OF " + column} {
+			name := fmt.Sprintf("trim_%s_%s_%d", table, column, i)
+			trigger := fmt.Sprintf(`CREATE TRIGGER %s AFTER %s ON %s BEGIN
+				UPDATE %s SET %s = substr(NEW.%s, 1, 10) WHERE id = NEW.id AND length(NEW.%s) > 10;
+			END`, name, event, table, table, column, column, column)
+			if err := db.Exec(trigger).Error; err != nil {
+				t.Fatalf("failed to create %s: %v", name, err)
+			}
+		}
+	}
+
+	return db
+}
+
+// Day returns midnight UTC offset days after a fixed Monday far enough ahead that no test data
+// is in the past
+func Day(offset int) time.Time {
+	return time.Date(2031, time.March, 3, 0, 0, 0, 0, time.UTC).AddDate(0, 0, offset)
+}
+
+// MustCreate inserts each value or fails the test
+func MustCreate(t testing.TB, db *gorm.DB, values ...interface{}) {
+	t.Helper()
+	for _, value := range values {
+		if err := db.Create(value).Error; err != nil {
+			t.Fatalf("failed to create %T: %v", value, err)
+		}
+	}
+}
+
+// Slot returns a slot for doctorID starting at hour:minute on day and lasting duration minutes
+func Slot(doctorID uint, day time.Time, hour, minute, duration int, status models.SlotStatus) *models.TimeSlot {
+	start := day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	return &models.TimeSlot{
+		DoctorID:  doctorID,
+		Date:      day,
+		StartTime: start,
+		EndTime:   start.Add(time.Duration(duration) * time.Minute),
+		Duration:  duration,
+		Status:    status,
+	}
+}
+
+// Appointment returns a consultation for userID with doctorID starting at start
+func Appointment(userID, doctorID uint, start time.Time, duration int, status models.AppointmentStatus) *models.Appointment {
+	return &models.Appointment{
+		UserID:          userID,
+		DoctorID:        doctorID,
+		AppointmentTime: start,
+		EndTime:         start.Add(time.Duration(duration) * time.Minute),
+		Duration:        duration,
+		Status:          status,
+		Type:            models.TypeConsultation,
+	}
+}
@@ -3,12 +3,14 @@ package repository
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"smart-doctor-booking-app/models"
 	"smart-doctor-booking-app/utils"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // TimeSlotRepository interface defines methods for time slot management
@@ -29,20 +31,42 @@ type TimeSlotRepository interface {
 	GenerateTimeSlots(doctorID uint, date time.Time) error
 	GetAvailableSlots(doctorID uint, date time.Time) ([]models.TimeSlot, error)
 	GetAvailableSlotsRange(doctorID uint, startDate, endDate time.Time) (map[string][]models.TimeSlot, error)
+	GetSlotsByStatus(doctorID uint, date time.Time, status models.SlotStatus) ([]models.TimeSlot, error)
+	GetUnbookableSlots(doctorID uint, from, to time.Time) ([]models.TimeSlot, error)
+	GetSlotsInRange(doctorID uint, from, to time.Time) ([]models.TimeSlot, error)
+	GetAvailableSlotsForDoctors(doctorIDs []uint, date time.Time) (map[uint][]models.TimeSlot, error)
 	CheckSlotAvailability(doctorID uint, startTime, endTime time.Time) (bool, error)
+	GetFreeDoctorsInSpecialty(specialtyID uint, startTime, endTime time.Time) ([]models.Doctor, error)
+	GetHoliday(doctorID uint, date time.Time) (*models.Holiday, error)
+	GetHolidaysInRange(doctorID uint, from, to time.Time) ([]models.Holiday, error)
+	PreviewTimeSlots(doctorID uint, date time.Time) ([]models.TimeSlot, error)
 
 	// Break Management
 	CreateDoctorBreak(doctorBreak *models.DoctorBreak) error
 	GetDoctorBreaks(doctorID uint, date time.Time) ([]models.DoctorBreak, error)
+	GetRecurringBreaks(doctorID uint) ([]models.DoctorBreak, error)
+	GetDoctorBreaksInRange(doctorID uint, from, to time.Time) ([]models.DoctorBreak, error)
 	UpdateDoctorBreak(doctorBreak *models.DoctorBreak) error
 	DeleteDoctorBreak(id uint) error
 
 	// Bulk Operations
-	GenerateWeeklySlots(doctorID uint, startDate time.Time) error
+	GenerateWeeklySlots(doctorID uint, startDate time.Time) ([]models.DayGenerationResult, error)
+	CreateOverrideSlots(doctorID uint, startTime, endTime time.Time, slotDuration time.Duration) (int, error)
 	BlockTimeSlots(doctorID uint, startTime, endTime time.Time, reason string) error
 	UnblockTimeSlots(doctorID uint, startTime, endTime time.Time) error
+	DeleteUnbookedSlots(doctorID uint, from, to time.Time) (int64, error)
+
+	// Maintenance
+	GetOrphanedSlots() ([]models.TimeSlot, error)
+	ReclaimOrphanedSlots() ([]models.TimeSlot, error)
 }
 
+// orphanedSlotCondition matches booked slots with no appointment, or whose appointment was
+// deleted or cancelled
+const orphanedSlotCondition = "status = ? AND (appointment_id IS NULL OR NOT EXISTS (" +
+	"SELECT 1 FROM appointments WHERE appointments.id = time_slots.appointment_id " +
+	"AND appointments.deleted_at IS NULL AND appointments.status <> ?))"
+
 // timeSlotRepository implements TimeSlotRepository
 type timeSlotRepository struct {
 	db *gorm.DB
@@ -218,45 +242,92 @@ func (r *timeSlotRepository) DeleteTimeSlot(id uint) error {
 
 // GenerateTimeSlots generates time slots for a doctor on a specific date based on their schedule
 func (r *timeSlotRepository) GenerateTimeSlots(doctorID uint, date time.Time) error {
+	timeSlots, err := r.PreviewTimeSlots(doctorID, date)
+	if err != nil {
+		return err
+	}
+
+	// Batch create time slots
+	if len(timeSlots) > 0 {
+		result := r.db.Create(&timeSlots)
+		if result.Error != nil {
+			return fmt.Errorf("failed to create time slots: %w", result.Error)
+		}
+
+		utils.LogInfo("Time slots generated successfully", map[string]interface{}{
+			"doctor_id":   doctorID,
+			"date":        date.Format("2006-01-02"),
+			"slots_count": len(timeSlots),
+		})
+	}
+
+	return nil
+}
+
+// PreviewTimeSlots returns the slots GenerateTimeSlots would create for a doctor on a date,
+// without saving them. Holidays and days off yield no slots.
+func (r *timeSlotRepository) PreviewTimeSlots(doctorID uint, date time.Time) ([]models.TimeSlot, error) {
+	// Skip holidays
+	holiday, err := r.GetHoliday(doctorID, date)
+	if err != nil {
+		return nil, err
+	}
+	if holiday != nil {
+		return nil, nil
+	}
+
 	// Get doctor's schedule
 	schedule, err := r.GetDoctorSchedule(doctorID)
 	if err != nil {
-		return fmt.Errorf("failed to get doctor schedule: %w", err)
+		return nil, fmt.Errorf("failed to get doctor schedule: %w", err)
 	}
 
-	// Get day of week
-	var workingHours models.WorkingHours
-	switch date.Weekday() {
-	case time.Monday:
-		workingHours = schedule.Monday
-	case time.Tuesday:
-		workingHours = schedule.Tuesday
-	case time.Wednesday:
-		workingHours = schedule.Wednesday
-	case time.Thursday:
-		workingHours = schedule.Thursday
-	case time.Friday:
-		workingHours = schedule.Friday
-	case time.Saturday:
-		workingHours = schedule.Saturday
-	case time.Sunday:
-		workingHours = schedule.Sunday
+	// Get doctor breaks for this date
+	breaks, err := r.GetDoctorBreaks(doctorID, date)
+	if err != nil {
+		utils.LogError(err, "Failed to get doctor breaks", map[string]interface{}{
+			"doctor_id": doctorID,
+			"date":      date,
+		})
 	}
 
+	slots, err := buildTimeSlots(doctorID, date, schedule, breaks)
+	if err != nil {
+		return nil, err
+	}
+
+	// Slots are held at the doctor's primary location
+	var doctor models.Doctor
+	if err := r.db.Select("location_id").First(&doctor, doctorID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get doctor location: %w", err)
+	}
+	for i := range slots {
+		slots[i].LocationID = doctor.LocationID
+	}
+
+	return slots, nil
+}
+
+// buildTimeSlots lays out a day's slots from the doctor's working hours, blocking those that
+// overlap a break. It has no side effects, so previews and generation produce the same slots.
+func buildTimeSlots(doctorID uint, date time.Time, schedule *models.DoctorSchedule, breaks []models.DoctorBreak) ([]models.TimeSlot, error) {
+	// Get day of week
+	workingHours := schedule.WorkingHoursFor(date.Weekday())
+
 	// Check if doctor works on this day
 	if workingHours.StartTime == "" || workingHours.EndTime == "" {
-		return nil // Doctor doesn't work on this day
+		return nil, nil // Doctor doesn't work on this day
 	}
 
 	// Parse working hours
 	startTime, err := time.Parse("15:04", workingHours.StartTime)
 	if err != nil {
-		return fmt.Errorf("invalid start time format: %w", err)
+		return nil, fmt.Errorf("invalid start time format: %w", err)
 	}
 
 	endTime, err := time.Parse("15:04", workingHours.EndTime)
 	if err != nil {
-		return fmt.Errorf("invalid end time format: %w", err)
+		return nil, fmt.Errorf("invalid end time format: %w", err)
 	}
 
 	// Create time slots
@@ -280,15 +351,6 @@ func (r *timeSlotRepository) GenerateTimeSlots(doctorID uint, date time.Time) er
 		currentTime = slotEndTime
 	}
 
-	// Get doctor breaks for this date
-	breaks, err := r.GetDoctorBreaks(doctorID, date)
-	if err != nil {
-		utils.LogError(err, "Failed to get doctor breaks", map[string]interface{}{
-			"doctor_id": doctorID,
-			"date":      date,
-		})
-	}
-
 	// Mark slots during breaks as blocked
 	for i := range timeSlots {
 		for _, breakTime := range breaks {
@@ -299,21 +361,7 @@ func (r *timeSlotRepository) GenerateTimeSlots(doctorID uint, date time.Time) er
 		}
 	}
 
-	// Batch create time slots
-	if len(timeSlots) > 0 {
-		result := r.db.Create(&timeSlots)
-		if result.Error != nil {
-			return fmt.Errorf("failed to create time slots: %w", result.Error)
-		}
-
-		utils.LogInfo("Time slots generated successfully", map[string]interface{}{
-			"doctor_id":   doctorID,
-			"date":        date.Format("2006-01-02"),
-			"slots_count": len(timeSlots),
-		})
-	}
-
-	return nil
+	return timeSlots, nil
 }
 
 // GetAvailableSlots returns available time slots for a doctor on a specific date
@@ -355,6 +403,80 @@ func (r *timeSlotRepository) GetAvailableSlotsRange(doctorID uint, startDate, en
 	return availabilityMap, nil
 }
 
+// GetAvailableSlotsForDoctors returns available time slots for several doctors on a date
+// using a single query, grouped by doctor ID
+func (r *timeSlotRepository) GetAvailableSlotsForDoctors(doctorIDs []uint, date time.Time) (map[uint][]models.TimeSlot, error) {
+	var timeSlots []models.TimeSlot
+	slotsByDoctor := make(map[uint][]models.TimeSlot)
+
+	result := r.db.Where("doctor_id IN ? AND date = ? AND status = ?",
+		doctorIDs, date.Format("2006-01-02"), models.SlotAvailable).
+		Order("doctor_id ASC, start_time ASC").
+		Find(&timeSlots)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	// Group slots by doctor
+	for _, slot := range timeSlots {
+		slotsByDoctor[slot.DoctorID] = append(slotsByDoctor[slot.DoctorID], slot)
+	}
+
+	return slotsByDoctor, nil
+}
+
+// GetSlotsByStatus returns a doctor's time slots on a date, optionally filtered by status.
+// An empty status returns all slots. Booked slots have their appointment preloaded.
+func (r *timeSlotRepository) GetSlotsByStatus(doctorID uint, date time.Time, status models.SlotStatus) ([]models.TimeSlot, error) {
+	var timeSlots []models.TimeSlot
+
+	query := r.db.Preload("Appointment").
+		Where("doctor_id = ? AND date = ?", doctorID, date.Format("2006-01-02"))
+
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	result := query.Order("start_time ASC").Find(&timeSlots)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return timeSlots, nil
+}
+
+// GetUnbookableSlots returns a doctor's BLOCKED and BREAK slots starting in [from, to)
+func (r *timeSlotRepository) GetUnbookableSlots(doctorID uint, from, to time.Time) ([]models.TimeSlot, error) {
+	var timeSlots []models.TimeSlot
+
+	result := r.db.Where("doctor_id = ? AND status IN ? AND start_time >= ? AND start_time < ?",
+		doctorID, []models.SlotStatus{models.SlotBlocked, models.SlotBreak}, from, to).
+		Order("start_time ASC").
+		Find(&timeSlots)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return timeSlots, nil
+}
+
+// GetSlotsInRange returns all of a doctor's slots starting in [from, to), whatever their status
+func (r *timeSlotRepository) GetSlotsInRange(doctorID uint, from, to time.Time) ([]models.TimeSlot, error) {
+	var timeSlots []models.TimeSlot
+
+	result := r.db.Where("doctor_id = ? AND start_time >= ? AND start_time < ?", doctorID, from, to).
+		Order("start_time ASC").
+		Find(&timeSlots)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return timeSlots, nil
+}
+
 // CheckSlotAvailability checks if a time slot is available for booking
 func (r *timeSlotRepository) CheckSlotAvailability(doctorID uint, startTime, endTime time.Time) (bool, error) {
 	var count int64
@@ -372,6 +494,31 @@ func (r *timeSlotRepository) CheckSlotAvailability(doctorID uint, startTime, end
 	return count > 0, nil
 }
 
+// GetFreeDoctorsInSpecialty returns active doctors in a specialty who have an available slot
+// covering the window and no active appointment overlapping it, in a single query
+func (r *timeSlotRepository) GetFreeDoctorsInSpecialty(specialtyID uint, startTime, endTime time.Time) ([]models.Doctor, error) {
+	var doctors []models.Doctor
+
+	result := r.db.Preload("Specialty").
+		Where("specialty_id = ? AND is_active = ?", specialtyID, true).
+		Where(`EXISTS (SELECT 1 FROM time_slots
+			WHERE time_slots.doctor_id = doctors.id AND time_slots.deleted_at IS NULL
+			AND time_slots.date = ? AND time_slots.start_time <= ? AND time_slots.end_time >= ? AND time_slots.status = ?)`,
+			startTime.Format("2006-01-02"), startTime, endTime, models.SlotAvailable).
+		Where(`NOT EXISTS (SELECT 1 FROM appointments
+			WHERE appointments.doctor_id = doctors.id AND appointments.deleted_at IS NULL
+			AND appointments.status IN ? AND appointments.appointment_time < ? AND appointments.end_time > ?)`,
+			[]models.AppointmentStatus{models.StatusScheduled, models.StatusConfirmed, models.StatusPendingPayment}, endTime, startTime).
+		Order("name ASC").
+		Find(&doctors)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return doctors, nil
+}
+
 // Break Management
 
 // CreateDoctorBreak creates a new doctor break
@@ -409,6 +556,37 @@ func (r *timeSlotRepository) GetDoctorBreaks(doctorID uint, date time.Time) ([]m
 	return breaks, nil
 }
 
+// GetRecurringBreaks retrieves a doctor's recurring breaks; each recurs on the weekday of its date
+func (r *timeSlotRepository) GetRecurringBreaks(doctorID uint) ([]models.DoctorBreak, error) {
+	var breaks []models.DoctorBreak
+
+	result := r.db.Where("doctor_id = ? AND is_recurring = ?", doctorID, true).
+		Order("start_time ASC").
+		Find(&breaks)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return breaks, nil
+}
+
+// GetDoctorBreaksInRange retrieves a doctor's one-off breaks dated within [from, to)
+func (r *timeSlotRepository) GetDoctorBreaksInRange(doctorID uint, from, to time.Time) ([]models.DoctorBreak, error) {
+	var breaks []models.DoctorBreak
+
+	result := r.db.Where("doctor_id = ? AND is_recurring = ? AND date >= ? AND date < ?",
+		doctorID, false, from.Format("2006-01-02"), to.Format("2006-01-02")).
+		Order("start_time ASC").
+		Find(&breaks)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return breaks, nil
+}
+
 // UpdateDoctorBreak updates a doctor break
 func (r *timeSlotRepository) UpdateDoctorBreak(doctorBreak *models.DoctorBreak) error {
 	if doctorBreak == nil {
@@ -443,34 +621,269 @@ func (r *timeSlotRepository) DeleteDoctorBreak(id uint) error {
 
 // Bulk Operations
 
-// GenerateWeeklySlots generates time slots for a doctor for the entire week starting from startDate
-func (r *timeSlotRepository) GenerateWeeklySlots(doctorID uint, startDate time.Time) error {
-	// Generate slots for 7 days
+// WeeklyGenerationError reports the days that failed during weekly slot generation
+type WeeklyGenerationError struct {
+	Failures []models.DayGenerationResult
+}
+
+// Error implements the error interface, naming each failed day
+func (e *WeeklyGenerationError) Error() string {
+	parts := make([]string, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		parts = append(parts, fmt.Sprintf("%s (%s): %s", failure.Date, failure.Day, failure.Error))
+	}
+	return fmt.Sprintf("slot generation failed for %d day(s): %s", len(e.Failures), strings.Join(parts, "; "))
+}
+
+// GenerateWeeklySlots generates time slots for a doctor for the entire week starting from startDate.
+// The schedule, location, holidays and breaks are loaded once for the whole week and every day's
+// slots are saved in a single insert. A day that cannot be laid out does not stop the others; the
+// per-day outcome is always returned, and a *WeeklyGenerationError naming the failed days is
+// returned if any day failed.
+func (r *timeSlotRepository) GenerateWeeklySlots(doctorID uint, startDate time.Time) ([]models.DayGenerationResult, error) {
+	endDate := startDate.AddDate(0, 0, 7)
+	results := make([]models.DayGenerationResult, 0, 7)
 	for i := 0; i < 7; i++ {
 		currentDate := startDate.AddDate(0, 0, i)
-		if err := r.GenerateTimeSlots(doctorID, currentDate); err != nil {
+		results = append(results, models.DayGenerationResult{
+			Date:    currentDate.Format("2006-01-02"),
+			Day:     models.DayOfWeek(strings.ToUpper(currentDate.Weekday().String())),
+			Success: true,
+		})
+	}
+
+	week, err := r.loadGenerationWeek(doctorID, startDate, endDate)
+	if err != nil {
+		return r.failWeek(doctorID, startDate, results, err)
+	}
+
+	var timeSlots []models.TimeSlot
+	slotDays := make(map[int]bool, 7) // Indexes into results of the days contributing slots
+	for i := range results {
+		if holiday, ok := week.holidays[results[i].Date]; ok {
+			results[i].Holiday = holiday.Name
+			continue
+		}
+
+		currentDate := startDate.AddDate(0, 0, i)
+		daySlots, err := buildTimeSlots(doctorID, currentDate, week.schedule, week.breaks[results[i].Date])
+		if err != nil {
 			utils.LogError(err, "Failed to generate time slots for date", map[string]interface{}{
 				"doctor_id": doctorID,
-				"date":      currentDate.Format("2006-01-02"),
+				"date":      results[i].Date,
 			})
 			// Continue with other days even if one fails
+			results[i].Success = false
+			results[i].Error = err.Error()
+			continue
+		}
+
+		for j := range daySlots {
+			daySlots[j].LocationID = week.locationID
+		}
+		if len(daySlots) > 0 {
+			slotDays[i] = true
+		}
+		timeSlots = append(timeSlots, daySlots...)
+	}
+
+	// Batch create the whole week's time slots
+	if len(timeSlots) > 0 {
+		if err := r.db.Create(&timeSlots).Error; err != nil {
+			err = fmt.Errorf("failed to create time slots: %w", err)
+			for i := range results {
+				if slotDays[i] {
+					results[i].Success = false
+					results[i].Error = err.Error()
+				}
+			}
+		}
+	}
+
+	var failures []models.DayGenerationResult
+	for _, result := range results {
+		if !result.Success {
+			failures = append(failures, result)
 		}
 	}
 
 	utils.LogInfo("Weekly time slots generation completed", map[string]interface{}{
+		"doctor_id":   doctorID,
+		"start_date":  startDate.Format("2006-01-02"),
+		"slots_count": len(timeSlots),
+		"failed_days": len(failures),
+	})
+
+	if len(failures) > 0 {
+		return results, &WeeklyGenerationError{Failures: failures}
+	}
+
+	return results, nil
+}
+
+// generationWeek holds everything needed to lay out a doctor's slots for a week
+type generationWeek struct {
+	schedule   *models.DoctorSchedule
+	locationID *uint
+	holidays   map[string]models.Holiday       // Keyed by YYYY-MM-DD
+	breaks     map[string][]models.DoctorBreak // Keyed by YYYY-MM-DD
+}
+
+// loadGenerationWeek fetches the doctor's schedule, primary location, and the holidays and breaks
+// dated within [startDate, endDate), one query each
+func (r *timeSlotRepository) loadGenerationWeek(doctorID uint, startDate, endDate time.Time) (*generationWeek, error) {
+	schedule, err := r.GetDoctorSchedule(doctorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get doctor schedule: %w", err)
+	}
+
+	// Slots are held at the doctor's primary location
+	var doctor models.Doctor
+	if err := r.db.Select("location_id").First(&doctor, doctorID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get doctor location: %w", err)
+	}
+
+	holidays, err := r.GetHolidaysInRange(doctorID, startDate, endDate.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	week := &generationWeek{
+		schedule:   schedule,
+		locationID: doctor.LocationID,
+		holidays:   make(map[string]models.Holiday, len(holidays)),
+		breaks:     make(map[string][]models.DoctorBreak),
+	}
+
+	// Clinic-wide holidays take precedence over the doctor's own, as in GetHoliday
+	for _, holiday := range holidays {
+		date := holiday.Date.Format("2006-01-02")
+		if existing, ok := week.holidays[date]; !ok || (existing.DoctorID != nil && holiday.DoctorID == nil) {
+			week.holidays[date] = holiday
+		}
+	}
+
+	var breaks []models.DoctorBreak
+	if err := r.db.Where("doctor_id = ? AND date >= ? AND date < ?",
+		doctorID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02")).
+		Order("start_time ASC").
+		Find(&breaks).Error; err != nil {
+		utils.LogError(err, "Failed to get doctor breaks", map[string]interface{}{
+			"doctor_id":  doctorID,
+			"start_date": startDate,
+		})
+	}
+	for _, doctorBreak := range breaks {
+		date := doctorBreak.Date.Format("2006-01-02")
+		week.breaks[date] = append(week.breaks[date], doctorBreak)
+	}
+
+	return week, nil
+}
+
+// failWeek marks every day of the week as failed with err
+func (r *timeSlotRepository) failWeek(doctorID uint, startDate time.Time, results []models.DayGenerationResult, err error) ([]models.DayGenerationResult, error) {
+	utils.LogError(err, "Failed to generate weekly time slots", map[string]interface{}{
 		"doctor_id":  doctorID,
 		"start_date": startDate.Format("2006-01-02"),
 	})
 
-	return nil
+	for i := range results {
+		results[i].Success = false
+		results[i].Error = err.Error()
+	}
+	return results, &WeeklyGenerationError{Failures: results}
+}
+
+// GetHoliday returns the holiday closing the doctor's bookings on date's calendar day, or nil
+func (r *timeSlotRepository) GetHoliday(doctorID uint, date time.Time) (*models.Holiday, error) {
+	return findHoliday(r.db, doctorID, date)
 }
 
-// BlockTimeSlots blocks time slots within a time range
+// GetHolidaysInRange returns the clinic-wide and doctor-specific holidays between from and to
+// inclusive, ordered by date
+func (r *timeSlotRepository) GetHolidaysInRange(doctorID uint, from, to time.Time) ([]models.Holiday, error) {
+	var holidays []models.Holiday
+	if err := r.db.Where("date BETWEEN ? AND ? AND (doctor_id IS NULL OR doctor_id = ?)",
+		from.Format("2006-01-02"), to.Format("2006-01-02"), doctorID).
+		Order("date ASC").
+		Find(&holidays).Error; err != nil {
+		return nil, fmt.Errorf("failed to get holidays: %w", err)
+	}
+	return holidays, nil
+}
+
+// CreateOverrideSlots generates extra available slots for a single date independent of the weekly
+// schedule. Candidate slots overlapping an existing slot are skipped so the result merges with,
+// rather than duplicates, what is already there. It returns the number of slots created.
+func (r *timeSlotRepository) CreateOverrideSlots(doctorID uint, startTime, endTime time.Time, slotDuration time.Duration) (int, error) {
+	if slotDuration <= 0 {
+		return 0, errors.New("slot duration must be positive")
+	}
+	if !endTime.After(startTime) {
+		return 0, errors.New("end time must be after start time")
+	}
+
+	date := time.Date(startTime.Year(), startTime.Month(), startTime.Day(), 0, 0, 0, 0, startTime.Location())
+
+	// Get existing slots for the date to avoid duplicates
+	var existingSlots []models.TimeSlot
+	if err := r.db.Where("doctor_id = ? AND date = ?", doctorID, date.Format("2006-01-02")).
+		Order("start_time ASC").
+		Find(&existingSlots).Error; err != nil {
+		return 0, fmt.Errorf("failed to get existing slots: %w", err)
+	}
+
+	var timeSlots []models.TimeSlot
+	currentTime := startTime
+	for !currentTime.Add(slotDuration).After(endTime) {
+		slotEndTime := currentTime.Add(slotDuration)
+
+		overlaps := false
+		for _, existing := range existingSlots {
+			if currentTime.Before(existing.EndTime) && slotEndTime.After(existing.StartTime) {
+				overlaps = true
+				break
+			}
+		}
+
+		if !overlaps {
+			timeSlots = append(timeSlots, models.TimeSlot{
+				DoctorID:  doctorID,
+				Date:      date,
+				StartTime: currentTime,
+				EndTime:   slotEndTime,
+				Duration:  int(slotDuration.Minutes()),
+				Status:    models.SlotAvailable,
+				Notes:     "availability override",
+			})
+		}
+
+		currentTime = slotEndTime
+	}
+
+	// Batch create time slots
+	if len(timeSlots) > 0 {
+		if err := r.db.Create(&timeSlots).Error; err != nil {
+			return 0, fmt.Errorf("failed to create override slots: %w", err)
+		}
+	}
+
+	utils.LogInfo("Availability override slots created", map[string]interface{}{
+		"doctor_id":     doctorID,
+		"date":          date.Format("2006-01-02"),
+		"slots_created": len(timeSlots),
+	})
+
+	return len(timeSlots), nil
+}
+
+// BlockTimeSlots blocks available time slots within a time range, recording the reason in the slot notes
 func (r *timeSlotRepository) BlockTimeSlots(doctorID uint, startTime, endTime time.Time, reason string) error {
 	result := r.db.Model(&models.TimeSlot{}).
 		Where("doctor_id = ? AND start_time >= ? AND end_time <= ? AND status = ?",
 			doctorID, startTime, endTime, models.SlotAvailable).
-		Update("status", models.SlotBlocked)
+		Updates(map[string]interface{}{"status": models.SlotBlocked, "notes": reason})
 
 	if result.Error != nil {
 		return fmt.Errorf("failed to block time slots: %w", result.Error)
@@ -487,12 +900,12 @@ func (r *timeSlotRepository) BlockTimeSlots(doctorID uint, startTime, endTime ti
 	return nil
 }
 
-// UnblockTimeSlots unblocks time slots within a time range
+// UnblockTimeSlots unblocks time slots within a time range and clears their block reason
 func (r *timeSlotRepository) UnblockTimeSlots(doctorID uint, startTime, endTime time.Time) error {
 	result := r.db.Model(&models.TimeSlot{}).
 		Where("doctor_id = ? AND start_time >= ? AND end_time <= ? AND status = ?",
 			doctorID, startTime, endTime, models.SlotBlocked).
-		Update("status", models.SlotAvailable)
+		Updates(map[string]interface{}{"status": models.SlotAvailable, "notes": ""})
 
 	if result.Error != nil {
 		return fmt.Errorf("failed to unblock time slots: %w", result.Error)
@@ -507,3 +920,82 @@ func (r *timeSlotRepository) UnblockTimeSlots(doctorID uint, startTime, endTime
 
 	return nil
 }
+
+// DeleteUnbookedSlots deletes a doctor's AVAILABLE and BLOCKED slots starting in [from, to) and
+// returns how many were deleted. Booked slots and breaks are never touched.
+func (r *timeSlotRepository) DeleteUnbookedSlots(doctorID uint, from, to time.Time) (int64, error) {
+	result := r.db.Where("doctor_id = ? AND start_time >= ? AND start_time < ? AND status IN ? AND appointment_id IS NULL",
+		doctorID, from, to, []models.SlotStatus{models.SlotAvailable, models.SlotBlocked}).
+		Delete(&models.TimeSlot{})
+
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete time slots: %w", result.Error)
+	}
+
+	utils.LogInfo("Time slots deleted successfully", map[string]interface{}{
+		"doctor_id":     doctorID,
+		"from":          from,
+		"to":            to,
+		"deleted_slots": result.RowsAffected,
+	})
+
+	return result.RowsAffected, nil
+}
+
+// GetOrphanedSlots returns booked slots that no live appointment holds, ordered by start time
+func (r *timeSlotRepository) GetOrphanedSlots() ([]models.TimeSlot, error) {
+	var slots []models.TimeSlot
+	if err := r.db.Where(orphanedSlotCondition, models.SlotBooked, models.StatusCancelled).
+		Order("start_time ASC").
+		Find(&slots).Error; err != nil {
+		return nil, fmt.Errorf("failed to get orphaned slots: %w", err)
+	}
+	return slots, nil
+}
+
+// ReclaimOrphanedSlots makes every orphaned booked slot available again and returns the slots reclaimed
+func (r *timeSlotRepository) ReclaimOrphanedSlots() ([]models.TimeSlot, error) {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+
+	var slots []models.TimeSlot
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where(orphanedSlotCondition, models.SlotBooked, models.StatusCancelled).
+		Order("start_time ASC").
+		Find(&slots).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to get orphaned slots: %w", err)
+	}
+	if len(slots) == 0 {
+		tx.Rollback()
+		return slots, nil
+	}
+
+	ids := make([]uint, len(slots))
+	for i, slot := range slots {
+		ids[i] = slot.ID
+	}
+	if err := tx.Model(&models.TimeSlot{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{"status": models.SlotAvailable, "appointment_id": nil}).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to reclaim orphaned slots: %w", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	for i := range slots {
+		slots[i].Status = models.SlotAvailable
+		slots[i].AppointmentID = nil
+	}
+
+	utils.LogInfo("Orphaned time slots reclaimed", map[string]interface{}{
+		"reclaimed_slots": len(slots),
+	})
+
+	return slots, nil
+}
@@ -0,0 +1,396 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository/repotest"
+)
+
+func TestGetSlotsByStatus(t *testing.T) {
+	db := repotest.Open(t)
+	repo := NewTimeSlotRepository(db)
+	day := repotest.Day(0)
+
+	appointment := repotest.Appointment(1, 1, day.Add(10*time.Hour), 30, models.StatusScheduled)
+	repotest.MustCreate(t, db, appointment)
+
+	booked := repotest.Slot(1, day, 10, 0, 30, models.SlotBooked)
+	booked.AppointmentID = &appointment.ID
+	repotest.MustCreate(t, db,
+		repotest.Slot(1, day, 9, 0, 30, models.SlotAvailable),
+		repotest.Slot(1, day, 9, 30, 30, models.SlotBlocked),
+		booked,
+		repotest.Slot(1, day, 12, 0, 30, models.SlotBlocked),
+		repotest.Slot(1, repotest.Day(1), 9, 30, 30, models.SlotBlocked),
+		repotest.Slot(2, day, 9, 30, 30, models.SlotBlocked),
+	)
+
+	blocked, err := repo.GetSlotsByStatus(1, day, models.SlotBlocked)
+	if err != nil {
+		t.Fatalf("GetSlotsByStatus(BLOCKED) returned error: %v", err)
+	}
+	if len(blocked) != 2 {
+		t.Fatalf("expected 2 blocked slots, got %d", len(blocked))
+	}
+	for _, slot := range blocked {
+		if slot.Status != models.SlotBlocked || slot.DoctorID != 1 {
+			t.Errorf("unexpected slot %+v", slot)
+		}
+	}
+	if !blocked[0].StartTime.Before(blocked[1].StartTime) {
+		t.Error("expected slots ordered by start time")
+	}
+
+	bookedSlots, err := repo.GetSlotsByStatus(1, day, models.SlotBooked)
+	if err != nil {
+		t.Fatalf("GetSlotsByStatus(BOOKED) returned error: %v", err)
+	}
+	if len(bookedSlots) != 1 {
+		t.Fatalf("expected 1 booked slot, got %d", len(bookedSlots))
+	}
+	if bookedSlots[0].Appointment == nil || bookedSlots[0].Appointment.ID != appointment.ID {
+		t.Error("expected the booked slot to carry its appointment")
+	}
+
+	all, err := repo.GetSlotsByStatus(1, day, "")
+	if err != nil {
+		t.Fatalf("GetSlotsByStatus(all) returned error: %v", err)
+	}
+	if len(all) != 4 {
+		t.Errorf("expected 4 slots without a status filter, got %d", len(all))
+	}
+}
+
+func TestCreateOverrideSlotsAddsEveningToMorningDay(t *testing.T) {
+	db := repotest.Open(t)
+	repo := NewTimeSlotRepository(db)
+	day := repotest.Day(0)
+
+	for hour := 9; hour < 12; hour++ {
+		repotest.MustCreate(t, db,
+			repotest.Slot(1, day, hour, 0, 30, models.SlotAvailable),
+			repotest.Slot(1, day, hour, 30, 30, models.SlotAvailable),
+		)
+	}
+
+	created, err := repo.CreateOverrideSlots(1, day.Add(17*time.Hour), day.Add(19*time.Hour), 30*time.Minute)
+	if err != nil {
+		t.Fatalf("CreateOverrideSlots returned error: %v", err)
+	}
+	if created != 4 {
+		t.Errorf("expected 4 evening slots, got %d", created)
+	}
+
+	slots, err := repo.GetAvailableSlots(1, day)
+	if err != nil {
+		t.Fatalf("GetAvailableSlots returned error: %v", err)
+	}
+	if len(slots) != 10 {
+		t.Fatalf("expected 6 morning and 4 evening slots, got %d", len(slots))
+	}
+	if !slots[0].StartTime.Equal(day.Add(9*time.Hour)) || !slots[9].StartTime.Equal(day.Add(18*time.Hour+30*time.Minute)) {
+		t.Errorf("unexpected slot range %v to %v", slots[0].StartTime, slots[9].StartTime)
+	}
+
+	// An override overlapping the morning only fills the time that is still free
+	created, err = repo.CreateOverrideSlots(1, day.Add(11*time.Hour+30*time.Minute), day.Add(13*time.Hour), 30*time.Minute)
+	if err != nil {
+		t.Fatalf("CreateOverrideSlots returned error: %v", err)
+	}
+	if created != 2 {
+		t.Errorf("expected only the 2 free slots after the morning to be created, got %d", created)
+	}
+}
+
+// seedSchedule creates a doctor working the given hours, with 30 minute slots
+func seedSchedule(t *testing.T, db *gorm.DB, doctorID uint, schedule models.DoctorSchedule) {
+	t.Helper()
+	schedule.DoctorID = doctorID
+	schedule.SlotDuration = 30 * time.Minute
+	repotest.MustCreate(t, db,
+		&models.Doctor{ID: doctorID, Name: "Doctor", SpecialtyID: 1, IsActive: true},
+		&schedule,
+	)
+}
+
+// countSlots counts a doctor's slots on day
+func countSlots(t *testing.T, db *gorm.DB, doctorID uint, day time.Time) int64 {
+	t.Helper()
+	var count int64
+	if err := db.Model(&models.TimeSlot{}).Where("doctor_id = ? AND date = ?", doctorID, day.Format("2006-01-02")).
+		Count(&count).Error; err != nil {
+		t.Fatalf("failed to count slots: %v", err)
+	}
+	return count
+}
+
+func TestGenerateWeeklySlotsNamesFailedDay(t *testing.T) {
+	db := repotest.Open(t)
+	repo := NewTimeSlotRepository(db)
+	monday := repotest.Day(0)
+	seedSchedule(t, db, 1, models.DoctorSchedule{
+		Monday:    models.WorkingHours{StartTime: "09:00", EndTime: "10:00"},
+		Wednesday: models.WorkingHours{StartTime: "9am", EndTime: "10:00"},
+		Friday:    models.WorkingHours{StartTime: "09:00", EndTime: "11:00"},
+	})
+
+	results, err := repo.GenerateWeeklySlots(1, monday)
+
+	var weekErr *WeeklyGenerationError
+	if !errors.As(err, &weekErr) {
+		t.Fatalf("expected a WeeklyGenerationError, got %v", err)
+	}
+	wednesday := repotest.Day(2).Format("2006-01-02")
+	if len(weekErr.Failures) != 1 || weekErr.Failures[0].Date != wednesday {
+		t.Fatalf("expected only %s to fail, got %+v", wednesday, weekErr.Failures)
+	}
+	if !strings.Contains(err.Error(), wednesday) || !strings.Contains(err.Error(), string(models.Wednesday)) {
+		t.Errorf("expected the error to name %s (%s), got %q", wednesday, models.Wednesday, err.Error())
+	}
+
+	if len(results) != 7 {
+		t.Fatalf("expected an outcome for each of 7 days, got %d", len(results))
+	}
+	for i, result := range results {
+		if wantSuccess := i != 2; result.Success != wantSuccess {
+			t.Errorf("%s: expected success=%v, got %+v", result.Day, wantSuccess, result)
+		}
+	}
+
+	if got := countSlots(t, db, 1, monday); got != 2 {
+		t.Errorf("expected Monday's 2 slots despite Wednesday failing, got %d", got)
+	}
+	if got := countSlots(t, db, 1, repotest.Day(4)); got != 4 {
+		t.Errorf("expected Friday's 4 slots despite Wednesday failing, got %d", got)
+	}
+}
+
+func TestGetFreeDoctorsInSpecialtyReturnsOnlyFreeDoctor(t *testing.T) {
+	db := repotest.Open(t)
+	repo := NewTimeSlotRepository(db)
+	day := repotest.Day(0)
+
+	repotest.MustCreate(t, db,
+		&models.Specialty{ID: 1, Name: "Cardiology"},
+		&models.Specialty{ID: 2, Name: "Dermatology"},
+		&models.Doctor{ID: 1, Name: "Busy", SpecialtyID: 1, IsActive: true},
+		&models.Doctor{ID: 2, Name: "Free", SpecialtyID: 1, IsActive: true},
+		&models.Doctor{ID: 3, Name: "Other Specialty", SpecialtyID: 2, IsActive: true},
+		repotest.Slot(1, day, 9, 0, 30, models.SlotAvailable),
+		repotest.Slot(2, day, 9, 0, 30, models.SlotAvailable),
+		repotest.Slot(3, day, 9, 0, 30, models.SlotAvailable),
+		repotest.Appointment(1, 1, day.Add(9*time.Hour), 30, models.StatusScheduled),
+	)
+
+	doctors, err := repo.GetFreeDoctorsInSpecialty(1, day.Add(9*time.Hour), day.Add(9*time.Hour+30*time.Minute))
+	if err != nil {
+		t.Fatalf("GetFreeDoctorsInSpecialty returned error: %v", err)
+	}
+	if len(doctors) != 1 || doctors[0].ID != 2 {
+		t.Fatalf("expected only doctor 2 to be free, got %+v", doctors)
+	}
+	if doctors[0].Specialty.Name != "Cardiology" {
+		t.Errorf("expected the specialty to be loaded, got %q", doctors[0].Specialty.Name)
+	}
+}
+
+func TestGenerateWeeklySlotsSkipsHoliday(t *testing.T) {
+	db := repotest.Open(t)
+	repo := NewTimeSlotRepository(db)
+	monday := repotest.Day(0)
+	hours := models.WorkingHours{StartTime: "09:00", EndTime: "10:00"}
+	seedSchedule(t, db, 1, models.DoctorSchedule{Monday: hours, Tuesday: hours, Wednesday: hours})
+	repotest.MustCreate(t, db, &models.Holiday{Date: repotest.Day(1), Name: "Founders Day"})
+
+	results, err := repo.GenerateWeeklySlots(1, monday)
+	if err != nil {
+		t.Fatalf("GenerateWeeklySlots returned error: %v", err)
+	}
+	if len(results) != 7 {
+		t.Fatalf("expected an outcome for each of 7 days, got %d", len(results))
+	}
+	if !results[1].Success || results[1].Holiday != "Founders Day" {
+		t.Errorf("expected Tuesday skipped for Founders Day, got %+v", results[1])
+	}
+
+	if got := countSlots(t, db, 1, repotest.Day(1)); got != 0 {
+		t.Errorf("expected no slots on the holiday, got %d", got)
+	}
+	for _, offset := range []int{0, 2} {
+		if got := countSlots(t, db, 1, repotest.Day(offset)); got != 2 {
+			t.Errorf("expected 2 slots on %s, got %d", results[offset].Day, got)
+		}
+	}
+}
+
+func TestGenerateWeeklySlotsLoadsScheduleOnceAndInsertsOnce(t *testing.T) {
+	db := repotest.Open(t)
+	repo := NewTimeSlotRepository(db)
+	monday := repotest.Day(0)
+	hours := models.WorkingHours{StartTime: "09:00", EndTime: "10:00"}
+	seedSchedule(t, db, 1, models.DoctorSchedule{Monday: hours, Tuesday: hours, Wednesday: hours, Thursday: hours, Friday: hours})
+	wednesday := repotest.Day(2)
+	repotest.MustCreate(t, db, &models.DoctorBreak{
+		DoctorID: 1, Date: wednesday, StartTime: wednesday.Add(9 * time.Hour), EndTime: wednesday.Add(9*time.Hour + 30*time.Minute), Reason: "Staff meeting",
+	})
+
+	queries := make(map[string]int)
+	inserts := make(map[string]int)
+	if err := db.Callback().Query().After("gorm:query").Register("test:count_queries", func(tx *gorm.DB) {
+		queries[tx.Statement.Table]++
+	}); err != nil {
+		t.Fatalf("failed to register query callback: %v", err)
+	}
+	if err := db.Callback().Create().After("gorm:create").Register("test:count_inserts", func(tx *gorm.DB) {
+		inserts[tx.Statement.Table]++
+	}); err != nil {
+		t.Fatalf("failed to register create callback: %v", err)
+	}
+
+	if _, err := repo.GenerateWeeklySlots(1, monday); err != nil {
+		t.Fatalf("GenerateWeeklySlots returned error: %v", err)
+	}
+
+	if queries["doctor_schedules"] != 1 {
+		t.Errorf("expected the schedule fetched once, got %d queries", queries["doctor_schedules"])
+	}
+	if queries["doctor_breaks"] != 1 {
+		t.Errorf("expected the week's breaks fetched once, got %d queries", queries["doctor_breaks"])
+	}
+	if inserts["time_slots"] != 1 {
+		t.Errorf("expected a single insert for the week's slots, got %d", inserts["time_slots"])
+	}
+
+	for offset := 0; offset < 5; offset++ {
+		if got := countSlots(t, db, 1, repotest.Day(offset)); got != 2 {
+			t.Errorf("expected 2 slots on day %d, got %d", offset, got)
+		}
+	}
+	var blocked []models.TimeSlot
+	if err := db.Where("doctor_id = ? AND status = ?", 1, models.SlotBlocked).Find(&blocked).Error; err != nil {
+		t.Fatalf("failed to load blocked slots: %v", err)
+	}
+	if len(blocked) != 1 || !blocked[0].StartTime.Equal(wednesday.Add(9*time.Hour)) {
+		t.Errorf("expected only Wednesday's 9:00 slot blocked by the break, got %+v", blocked)
+	}
+}
+
+func TestPreviewTimeSlotsMatchesGeneration(t *testing.T) {
+	db := repotest.Open(t)
+	repo := NewTimeSlotRepository(db)
+	day := repotest.Day(0)
+	seedSchedule(t, db, 1, models.DoctorSchedule{Monday: models.WorkingHours{StartTime: "09:00", EndTime: "13:00"}})
+	repotest.MustCreate(t, db, &models.DoctorBreak{
+		DoctorID: 1, Date: day, StartTime: day.Add(11 * time.Hour), EndTime: day.Add(12 * time.Hour), Reason: "Lunch",
+	})
+
+	preview, err := repo.PreviewTimeSlots(1, day)
+	if err != nil {
+		t.Fatalf("PreviewTimeSlots returned error: %v", err)
+	}
+	if got := countSlots(t, db, 1, day); got != 0 {
+		t.Fatalf("expected the preview to save nothing, got %d slots", got)
+	}
+
+	if err := repo.GenerateTimeSlots(1, day); err != nil {
+		t.Fatalf("GenerateTimeSlots returned error: %v", err)
+	}
+	var generated []models.TimeSlot
+	if err := db.Where("doctor_id = ?", 1).Order("start_time").Find(&generated).Error; err != nil {
+		t.Fatalf("failed to load generated slots: %v", err)
+	}
+
+	if len(preview) != 8 || len(preview) != len(generated) {
+		t.Fatalf("expected 8 previewed and generated slots, got %d and %d", len(preview), len(generated))
+	}
+	blocked := 0
+	for i := range preview {
+		if !preview[i].StartTime.Equal(generated[i].StartTime) || !preview[i].EndTime.Equal(generated[i].EndTime) ||
+			preview[i].Status != generated[i].Status {
+			t.Errorf("slot %d: previewed %v-%v %s, generated %v-%v %s", i,
+				preview[i].StartTime, preview[i].EndTime, preview[i].Status,
+				generated[i].StartTime, generated[i].EndTime, generated[i].Status)
+		}
+		if preview[i].Status == models.SlotBlocked {
+			blocked++
+		}
+	}
+	if blocked != 2 {
+		t.Errorf("expected the 2 slots over lunch to be blocked, got %d", blocked)
+	}
+}
+
+func TestGetAndReclaimOrphanedSlots(t *testing.T) {
+	db := repotest.Open(t)
+	repo := NewTimeSlotRepository(db)
+	day := repotest.Day(0)
+	at := func(hour int) time.Time { return day.Add(time.Duration(hour) * time.Hour) }
+
+	live := repotest.Appointment(1, 1, at(9), 30, models.StatusScheduled)
+	cancelled := repotest.Appointment(2, 1, at(10), 30, models.StatusCancelled)
+	deleted := repotest.Appointment(3, 1, at(11), 30, models.StatusScheduled)
+	repotest.MustCreate(t, db, live, cancelled, deleted)
+	if err := db.Delete(deleted).Error; err != nil {
+		t.Fatalf("failed to delete appointment: %v", err)
+	}
+
+	missing := uint(999)
+	slot := func(hour int, status models.SlotStatus, appointmentID *uint) *models.TimeSlot {
+		s := repotest.Slot(1, day, hour, 0, 30, status)
+		s.AppointmentID = appointmentID
+		return s
+	}
+	repotest.MustCreate(t, db,
+		slot(9, models.SlotBooked, &live.ID),
+		slot(10, models.SlotBooked, &cancelled.ID),
+		slot(11, models.SlotBooked, &deleted.ID),
+		slot(12, models.SlotBooked, &missing),
+		slot(13, models.SlotBooked, nil),
+		slot(14, models.SlotAvailable, nil),
+	)
+	wantHours := []int{10, 11, 12, 13}
+
+	orphans, err := repo.GetOrphanedSlots()
+	if err != nil {
+		t.Fatalf("GetOrphanedSlots returned error: %v", err)
+	}
+	if len(orphans) != len(wantHours) {
+		t.Fatalf("expected %d orphaned slots, got %d", len(wantHours), len(orphans))
+	}
+	for i, orphan := range orphans {
+		if !orphan.StartTime.Equal(at(wantHours[i])) {
+			t.Errorf("expected an orphaned slot at %v, got %v", at(wantHours[i]), orphan.StartTime)
+		}
+	}
+
+	reclaimed, err := repo.ReclaimOrphanedSlots()
+	if err != nil {
+		t.Fatalf("ReclaimOrphanedSlots returned error: %v", err)
+	}
+	if len(reclaimed) != len(wantHours) {
+		t.Fatalf("expected %d reclaimed slots, got %d", len(wantHours), len(reclaimed))
+	}
+
+	var slots []models.TimeSlot
+	if err := db.Order("start_time").Find(&slots).Error; err != nil {
+		t.Fatalf("failed to load slots: %v", err)
+	}
+	if slots[0].Status != models.SlotBooked || slots[0].AppointmentID == nil || *slots[0].AppointmentID != live.ID {
+		t.Errorf("expected the live appointment's slot to stay booked, got %+v", slots[0])
+	}
+	for _, s := range slots[1:] {
+		if s.Status != models.SlotAvailable || s.AppointmentID != nil {
+			t.Errorf("expected the slot at %v available and unlinked, got %s linked to %v", s.StartTime, s.Status, s.AppointmentID)
+		}
+	}
+
+	if orphans, err := repo.GetOrphanedSlots(); err != nil || len(orphans) != 0 {
+		t.Errorf("expected no orphans after reclaiming, got %d (err %v)", len(orphans), err)
+	}
+}
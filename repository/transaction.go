@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"smart-doctor-booking-app/utils"
+)
+
+// WithTransaction runs fn inside a database transaction on db. The transaction is committed if
+// fn returns nil and rolled back if it returns an error or panics; a recovered panic is logged
+// and returned as an error.
+func WithTransaction(db *gorm.DB, fn func(tx *gorm.DB) error) (err error) {
+	tx := db.Begin()
+	if tx.Error != nil {
+		return fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			err = fmt.Errorf("panic in transaction: %v", r)
+			utils.LogError(err, "Transaction panic recovered", nil)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
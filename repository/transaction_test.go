@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository/repotest"
+)
+
+func TestWithTransactionCommitsOrRollsBack(t *testing.T) {
+	errAbort := errors.New("abort")
+	tests := []struct {
+		name      string
+		fn        func(tx *gorm.DB) error
+		wantErr   string
+		wantIs    error
+		wantSaved bool
+	}{
+		{"success", func(tx *gorm.DB) error { return nil }, "", nil, true},
+		{"returned error", func(tx *gorm.DB) error { return errAbort }, "abort", errAbort, false},
+		{"panic", func(tx *gorm.DB) error { panic("boom") }, "panic in transaction: boom", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := repotest.Open(t)
+			err := WithTransaction(db, func(tx *gorm.DB) error {
+				if err := tx.Create(&models.Specialty{ID: 1, Name: "Cardiology"}).Error; err != nil {
+					return err
+				}
+				return tt.fn(tx)
+			})
+
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("WithTransaction returned error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+			if tt.wantIs != nil && !errors.Is(err, tt.wantIs) {
+				t.Errorf("expected fn's error to be returned, got %v", err)
+			}
+
+			var count int64
+			if err := db.Model(&models.Specialty{}).Count(&count).Error; err != nil {
+				t.Fatalf("failed to count specialties: %v", err)
+			}
+			if saved := count == 1; saved != tt.wantSaved {
+				t.Errorf("expected the write saved=%v, got %d rows", tt.wantSaved, count)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"smart-doctor-booking-app/models"
+)
+
+// UserRepository interface defines the contract for user data operations
+type UserRepository interface {
+	GetUserByID(id uint) (*models.User, error)
+	UpdateNotificationPreferences(id uint, preferences models.NotificationPreferences) error
+}
+
+// userRepository implements UserRepository interface
+type userRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository creates a new instance of UserRepository
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{
+		db: db,
+	}
+}
+
+// GetUserByID retrieves a user by ID
+func (r *userRepository) GetUserByID(id uint) (*models.User, error) {
+	var user models.User
+
+	result := r.db.First(&user, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, result.Error
+	}
+
+	return &user, nil
+}
+
+// UpdateNotificationPreferences replaces a user's notification preferences
+func (r *userRepository) UpdateNotificationPreferences(id uint, preferences models.NotificationPreferences) error {
+	result := r.db.Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"pref_sms":          preferences.SMS,
+		"pref_email":        preferences.Email,
+		"pref_push":         preferences.Push,
+		"quiet_hours_start": preferences.QuietHoursStart,
+		"quiet_hours_end":   preferences.QuietHoursEnd,
+		"language":          preferences.Language,
+	})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to update notification preferences: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
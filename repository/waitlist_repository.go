@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"smart-doctor-booking-app/models"
+)
+
+// WaitlistRepository interface defines the contract for waitlist data operations
+type WaitlistRepository interface {
+	CreateEntry(entry *models.WaitlistEntry) error
+	GetEntryByID(id uint) (*models.WaitlistEntry, error)
+	GetPendingOffer(entryID uint, now time.Time) (*models.WaitlistOffer, error)
+	GetNextWaitingEntry(doctorID uint, startTime time.Time, maxDuration int) (*models.WaitlistEntry, error)
+	CreateOffer(offer *models.WaitlistOffer) error
+	GetOfferByToken(token string) (*models.WaitlistOffer, error)
+	ClaimOffer(offerID uint, now time.Time) (bool, error)
+	CompleteOfferStep(offer *models.WaitlistOffer, removeEntry bool) BookingStep
+	ReturnOfferToQueue(offer *models.WaitlistOffer) error
+	ExpireOffers(now time.Time) ([]models.WaitlistOffer, error)
+}
+
+// waitlistRepository implements WaitlistRepository interface
+type waitlistRepository struct {
+	db *gorm.DB
+}
+
+// NewWaitlistRepository creates a new instance of WaitlistRepository
+func NewWaitlistRepository(db *gorm.DB) WaitlistRepository {
+	return &waitlistRepository{
+		db: db,
+	}
+}
+
+// CreateEntry adds a patient to a doctor's waitlist
+func (r *waitlistRepository) CreateEntry(entry *models.WaitlistEntry) error {
+	if entry == nil {
+		return errors.New("waitlist entry cannot be nil")
+	}
+
+	if err := r.db.Create(entry).Error; err != nil {
+		return fmt.Errorf("failed to create waitlist entry: %w", err)
+	}
+
+	return nil
+}
+
+// GetEntryByID returns the waitlist entry with the given ID
+func (r *waitlistRepository) GetEntryByID(id uint) (*models.WaitlistEntry, error) {
+	var entry models.WaitlistEntry
+
+	if err := r.db.First(&entry, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("waitlist entry not found")
+		}
+		return nil, fmt.Errorf("failed to get waitlist entry: %w", err)
+	}
+
+	return &entry, nil
+}
+
+// GetPendingOffer returns the entry's pending, unexpired offer, or nil if it has none
+func (r *waitlistRepository) GetPendingOffer(entryID uint, now time.Time) (*models.WaitlistOffer, error) {
+	var offer models.WaitlistOffer
+
+	result := r.db.Where("entry_id = ? AND status = ? AND expires_at > ?", entryID, models.OfferPending, now).
+		Order("created_at DESC").
+		Limit(1).
+		Find(&offer)
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get waitlist offer: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	return &offer, nil
+}
+
+// GetNextWaitingEntry returns the longest-waiting entry for a doctor that fits an opening starting at
+// startTime and lasting at most maxDuration minutes, or nil if nobody is waiting
+func (r *waitlistRepository) GetNextWaitingEntry(doctorID uint, startTime time.Time, maxDuration int) (*models.WaitlistEntry, error) {
+	var entry models.WaitlistEntry
+
+	result := r.db.Where("doctor_id = ? AND status = ? AND duration <= ?", doctorID, models.WaitlistWaiting, maxDuration).
+		Where("preferred_date IS NULL OR preferred_date = ?", startTime.Format("2006-01-02")).
+		Order("created_at ASC").
+		Limit(1).
+		Find(&entry)
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get waitlist entry: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	return &entry, nil
+}
+
+// CreateOffer saves an offer and marks its entry as offered
+func (r *waitlistRepository) CreateOffer(offer *models.WaitlistOffer) error {
+	if offer == nil {
+		return errors.New("waitlist offer cannot be nil")
+	}
+
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+
+	if err := tx.Create(offer).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to create waitlist offer: %w", err)
+	}
+
+	if err := tx.Model(&models.WaitlistEntry{}).Where("id = ?", offer.EntryID).
+		Update("status", models.WaitlistOffered).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to update waitlist entry: %w", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetOfferByToken returns the offer with the given token
+func (r *waitlistRepository) GetOfferByToken(token string) (*models.WaitlistOffer, error) {
+	var offer models.WaitlistOffer
+
+	if err := r.db.Where("token = ?", token).First(&offer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("waitlist offer not found")
+		}
+		return nil, fmt.Errorf("failed to get waitlist offer: %w", err)
+	}
+
+	return &offer, nil
+}
+
+// ClaimOffer atomically moves a pending, unexpired offer to accepted. It reports false when the
+// offer was already accepted, expired, or claimed concurrently.
+func (r *waitlistRepository) ClaimOffer(offerID uint, now time.Time) (bool, error) {
+	result := r.db.Model(&models.WaitlistOffer{}).
+		Where("id = ? AND status = ? AND expires_at > ?", offerID, models.OfferPending, now).
+		Update("status", models.OfferAccepted)
+
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim waitlist offer: %w", result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// CompleteOfferStep returns a booking step that links a claimed offer to the appointment booked
+// for it and closes its entry within the booking's transaction, so the booking and the waitlist
+// change commit or roll back together. With removeEntry the entry is also removed from the waitlist.
+func (r *waitlistRepository) CompleteOfferStep(offer *models.WaitlistOffer, removeEntry bool) BookingStep {
+	return func(tx *gorm.DB, appointment *models.Appointment) error {
+		if err := tx.Model(&models.WaitlistOffer{}).Where("id = ?", offer.ID).
+			Update("appointment_id", appointment.ID).Error; err != nil {
+			return fmt.Errorf("failed to update waitlist offer: %w", err)
+		}
+
+		if err := tx.Model(&models.WaitlistEntry{}).Where("id = ?", offer.EntryID).
+			Update("status", models.WaitlistBooked).Error; err != nil {
+			return fmt.Errorf("failed to update waitlist entry: %w", err)
+		}
+
+		if removeEntry {
+			if err := tx.Delete(&models.WaitlistEntry{}, offer.EntryID).Error; err != nil {
+				return fmt.Errorf("failed to remove waitlist entry: %w", err)
+			}
+		}
+
+		return nil
+	}
+}
+
+// ReturnOfferToQueue expires a claimed offer that could not be booked and puts its patient back
+// in the queue at their original position
+func (r *waitlistRepository) ReturnOfferToQueue(offer *models.WaitlistOffer) error {
+	return r.updateOfferAndEntry(offer, map[string]interface{}{"status": models.OfferExpired}, models.WaitlistWaiting)
+}
+
+// updateOfferAndEntry applies offer updates and an entry status change in one transaction
+func (r *waitlistRepository) updateOfferAndEntry(offer *models.WaitlistOffer, offerUpdates map[string]interface{}, entryStatus models.WaitlistStatus) error {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+
+	if err := tx.Model(&models.WaitlistOffer{}).Where("id = ?", offer.ID).Updates(offerUpdates).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to update waitlist offer: %w", err)
+	}
+
+	if err := tx.Model(&models.WaitlistEntry{}).Where("id = ?", offer.EntryID).
+		Update("status", entryStatus).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to update waitlist entry: %w", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ExpireOffers expires every pending offer past its deadline, removes the lapsed patients from
+// the queue, and returns the expired offers so their slots can be offered onwards
+func (r *waitlistRepository) ExpireOffers(now time.Time) ([]models.WaitlistOffer, error) {
+	var offers []models.WaitlistOffer
+
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+
+	// Lock the offers so a concurrent acceptance cannot slip in between reading and expiring them
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("status = ? AND expires_at <= ?", models.OfferPending, now).
+		Find(&offers).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to get expired waitlist offers: %w", err)
+	}
+	if len(offers) == 0 {
+		tx.Rollback()
+		return nil, nil
+	}
+
+	offerIDs := make([]uint, len(offers))
+	entryIDs := make([]uint, len(offers))
+	for i, offer := range offers {
+		offerIDs[i] = offer.ID
+		entryIDs[i] = offer.EntryID
+	}
+
+	if err := tx.Model(&models.WaitlistOffer{}).Where("id IN ?", offerIDs).
+		Update("status", models.OfferExpired).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to expire waitlist offers: %w", err)
+	}
+
+	if err := tx.Model(&models.WaitlistEntry{}).Where("id IN ?", entryIDs).
+		Update("status", models.WaitlistExpired).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to expire waitlist entries: %w", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return offers, nil
+}
@@ -2,6 +2,7 @@ package routes
 
 // import neccessary dependencies and modules
 import (
+	"context"
 	"os"
 	"strconv"
 	"strings"
@@ -12,6 +13,7 @@ import (
 
 	"smart-doctor-booking-app/handlers"
 	"smart-doctor-booking-app/middleware"
+	"smart-doctor-booking-app/models"
 	"smart-doctor-booking-app/repository"
 	"smart-doctor-booking-app/services"
 	"smart-doctor-booking-app/utils"
@@ -19,11 +21,15 @@ import (
 
 // SetupRoutes configures all application routes with scalability improvements
 func SetupRoutes(db *gorm.DB) *gin.Engine {
-	// Create Gin router with default middleware (logger and recovery)
-	router := gin.Default()
+	// Initialize logger (use the global Logger instance, initializing it if needed)
+	logger := utils.GetLogger()
 
-	// Initialize logger (use the global Logger instance)
-	logger := utils.Logger
+	// Create Gin router with request logging, request IDs and structured panic recovery
+	router := gin.New()
+	router.Use(gin.Logger())
+	router.Use(middleware.RequestIDMiddleware())
+	router.Use(middleware.RequestLoggerMiddleware())
+	router.Use(middleware.Recovery(logger))
 
 	// Add response compression middleware
 	compressionConfig := middleware.DefaultCompressionConfig()
@@ -65,7 +71,8 @@ func SetupRoutes(db *gorm.DB) *gin.Engine {
 			c.Header("Access-Control-Allow-Origin", origin)
 		}
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
+		c.Header("Access-Control-Expose-Headers", "X-Request-ID")
 		c.Header("Access-Control-Allow-Credentials", "true")
 
 		if c.Request.Method == "OPTIONS" {
@@ -85,11 +92,17 @@ func SetupRoutes(db *gorm.DB) *gin.Engine {
 	})
 
 	// Initialize caching service
+	// An explicitly empty REDIS_ADDR selects the in-memory cache
+	redisAddr, ok := os.LookupEnv("REDIS_ADDR")
+	if !ok {
+		redisAddr = "localhost:6379"
+	}
 	cacheConfig := services.CacheConfig{
-		RedisAddr:     getEnvString("REDIS_ADDR", "localhost:6379"),
-		RedisPassword: getEnvString("REDIS_PASSWORD", ""),
-		RedisDB:       getEnvInt("REDIS_DB", 0),
-		DefaultTTL:    getEnvDuration("CACHE_DEFAULT_TTL", "15m"),
+		RedisAddr:        redisAddr,
+		RedisPassword:    getEnvString("REDIS_PASSWORD", ""),
+		RedisDB:          getEnvInt("REDIS_DB", 0),
+		DefaultTTL:       getEnvDuration("CACHE_DEFAULT_TTL", "15m"),
+		TTLJitterPercent: getEnvFloat("CACHE_TTL_JITTER_PERCENT", 10),
 	}
 	cacheService := services.NewCacheService(cacheConfig, logger)
 
@@ -97,15 +110,121 @@ func SetupRoutes(db *gorm.DB) *gin.Engine {
 	doctorRepo := repository.NewDoctorRepository(db)
 	appointmentRepo := repository.NewAppointmentRepository(db)
 	timeSlotRepo := repository.NewTimeSlotRepository(db)
+	notificationLogRepo := repository.NewNotificationLogRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	waitlistRepo := repository.NewWaitlistRepository(db)
+	adminAuditRepo := repository.NewAdminAuditRepository(db)
+	holidayRepo := repository.NewHolidayRepository(db)
+	locationRepo := repository.NewLocationRepository(db)
 
 	// Initialize services
-	notificationService := services.NewNotificationService()
-	schedulingService := services.NewSchedulingService(appointmentRepo, timeSlotRepo, notificationService)
+	featureFlagsConfig := services.DefaultFeatureFlagsConfig()
+	for _, flag := range services.KnownFeatureFlags {
+		featureFlagsConfig.Defaults[flag] = getEnvBool("FEATURE_"+strings.ToUpper(string(flag)), false)
+	}
+	var featureFlagStore services.CacheService
+	if getEnvBool("FEATURE_FLAGS_USE_REDIS", false) {
+		featureFlagStore = cacheService
+	}
+	featureFlags := services.NewFeatureFlags(featureFlagsConfig, featureFlagStore)
+	services.SetNotificationConcurrency(getEnvInt("NOTIFICATION_MAX_CONCURRENCY", services.DefaultNotificationConcurrency))
+	localizer := services.NewLocalizer()
+	notificationService := services.NewLocalizedNotificationService(userRepo, localizer)
+	schedulingConfig := services.DefaultSchedulingConfig()
+	schedulingConfig.RescheduleMode = services.RescheduleMode(getEnvString("RESCHEDULE_MODE", string(services.RescheduleNewRecord)))
+	schedulingConfig.LateCancellationWindow = getEnvDuration("LATE_CANCELLATION_WINDOW", "24h")
+	schedulingConfig.DepositNoShowThreshold = getEnvFloat("DEPOSIT_NO_SHOW_THRESHOLD", schedulingConfig.DepositNoShowThreshold)
+	schedulingConfig.DepositMinAppointments = getEnvInt("DEPOSIT_MIN_APPOINTMENTS", schedulingConfig.DepositMinAppointments)
+	schedulingConfig.DepositHoldDuration = getEnvDuration("DEPOSIT_HOLD_DURATION", "15m")
+	schedulingConfig.DefaultPhoneRegion = getEnvString("DEFAULT_PHONE_REGION", schedulingConfig.DefaultPhoneRegion)
+	schedulingConfig.MaxActiveAppointments = getEnvInt("MAX_ACTIVE_APPOINTMENTS", schedulingConfig.MaxActiveAppointments)
+	schedulingConfig.ConfirmationResendInterval = getEnvDuration("CONFIRMATION_RESEND_INTERVAL", "10m")
+	schedulingConfig.DefaultReminderType = models.ReminderType(getEnvString("DEFAULT_REMINDER_TYPE", string(schedulingConfig.DefaultReminderType)))
+	schedulingConfig.DefaultReminderTime = getEnvInt("DEFAULT_REMINDER_TIME", schedulingConfig.DefaultReminderTime)
+	schedulingConfig.TimeOfDayBands.AfternoonStart = getEnvDuration("TIME_OF_DAY_AFTERNOON_START", "12h")
+	schedulingConfig.TimeOfDayBands.EveningStart = getEnvDuration("TIME_OF_DAY_EVENING_START", "17h")
+	schedulingConfig.DoctorBookingRateLimit = getEnvInt("DOCTOR_BOOKING_RATE_LIMIT", schedulingConfig.DoctorBookingRateLimit)
+	schedulingConfig.NearlyFullThreshold = getEnvFloat("AVAILABILITY_NEARLY_FULL_THRESHOLD", schedulingConfig.NearlyFullThreshold)
+	schedulingConfig.PendingClassificationTTL = getEnvDuration("AI_CALLBACK_TTL", "24h")
+	if getEnvBool("AVAILABILITY_WARMER_ENABLED", false) {
+		schedulingConfig.AvailabilityCacheTTL = getEnvDuration("AVAILABILITY_CACHE_TTL", "10m")
+	}
+	schedulingService := services.NewSchedulingServiceWithConfig(appointmentRepo, timeSlotRepo, notificationService, cacheService, schedulingConfig)
+
+	// Offer freed slots to waitlisted patients and expire offers they don't accept in time
+	waitlistConfig := services.DefaultWaitlistConfig()
+	waitlistConfig.OfferTTL = getEnvDuration("WAITLIST_OFFER_TTL", "15m")
+	waitlistService := services.NewWaitlistService(waitlistRepo, schedulingService, notificationService, featureFlags, waitlistConfig)
+	schedulingService.AddSlotReleaseListener(waitlistService)
+	schedulingService.SetNotificationLog(notificationLogRepo)
+	waitlistService.SetNotificationLog(notificationLogRepo)
+	schedulingService.SetLocationRepository(locationRepo)
+	if aiServiceURL := getEnvString("AI_SERVICE_URL", ""); aiServiceURL != "" {
+		schedulingService.SetSpecialtyClassifier(services.NewAIService(aiServiceURL))
+	}
+	waitlistService.StartSweeper(context.Background(), getEnvDuration("WAITLIST_SWEEP_INTERVAL", "1m"))
+
+	// Precompute upcoming availability so patient requests are served from the cache
+	if schedulingConfig.AvailabilityCacheTTL > 0 {
+		availabilityWarmer := services.NewAvailabilityWarmer(doctorRepo, schedulingService, getEnvInt("AVAILABILITY_WARM_DAYS", 14))
+		availabilityWarmer.Start(context.Background(), getEnvDuration("AVAILABILITY_WARM_INTERVAL", "5m"))
+	}
+
+	// Release bookings whose deposit hold expired
+	if schedulingConfig.DepositNoShowThreshold > 0 {
+		go func() {
+			ticker := time.NewTicker(time.Minute)
+			defer ticker.Stop()
+			for range ticker.C {
+				if _, err := schedulingService.ReleaseExpiredHolds(); err != nil {
+					utils.LogError(err, "Failed to release expired deposit holds", nil)
+				}
+			}
+		}()
+	}
+
+	// Start the reminder dispatcher
+	if getEnvBool("REMINDER_DISPATCHER_ENABLED", true) {
+		escalationPolicy := services.DefaultEscalationPolicy()
+		if types := getEnvString("REMINDER_VOICE_ESCALATION_TYPES", ""); types != "" {
+			escalationPolicy.VoiceEscalationTypes = make(map[models.AppointmentType]bool)
+			for _, appointmentType := range strings.Split(types, ",") {
+				escalationPolicy.VoiceEscalationTypes[models.AppointmentType(strings.ToUpper(strings.TrimSpace(appointmentType)))] = true
+			}
+		}
+		reminderDispatcher := services.NewReminderDispatcher(appointmentRepo, notificationLogRepo, userRepo, cacheService, []services.NotificationChannel{
+			services.NewSMSChannel(schedulingConfig.DefaultPhoneRegion),
+			services.NewEmailChannel(),
+			services.NewPushChannel(),
+			services.NewVoiceChannel(schedulingConfig.DefaultPhoneRegion),
+		}, escalationPolicy)
+		reminderDispatcher.Start(context.Background(), getEnvDuration("REMINDER_DISPATCH_INTERVAL", "1m"))
+	}
 
 	// Initialize handlers with caching support
 	doctorHandler := handlers.NewDoctorHandlerWithCache(doctorRepo, cacheService)
-	authHandler := handlers.NewAuthHandler()
+	loginGuardConfig := services.DefaultLoginGuardConfig()
+	loginGuardConfig.MaxFailures = getEnvInt("LOGIN_MAX_FAILURES", loginGuardConfig.MaxFailures)
+	loginGuardConfig.BaseLockout = getEnvDuration("LOGIN_LOCKOUT_BASE", "1m")
+	loginGuardConfig.MaxLockout = getEnvDuration("LOGIN_LOCKOUT_MAX", "1h")
+	authHandler := handlers.NewAuthHandler(services.NewLoginGuard(cacheService, loginGuardConfig))
+	userHandler := handlers.NewUserHandler(userRepo, notificationLogRepo)
+	adminHandler := handlers.NewAdminHandler(featureFlags, adminAuditRepo, doctorRepo, appointmentRepo, notificationLogRepo)
+	statsHandler := handlers.NewStatsHandler(schedulingService)
+	aiHandler := handlers.NewAIHandler(schedulingService, getEnvString("AI_CALLBACK_SECRET", ""))
 	appointmentHandler := handlers.NewAppointmentHandler(schedulingService)
+	scheduleHandler := handlers.NewScheduleHandler(schedulingService)
+	waitlistHandler := handlers.NewWaitlistHandler(waitlistService)
+	holidayHandler := handlers.NewHolidayHandler(holidayRepo, doctorRepo, schedulingService)
+	locationHandler := handlers.NewLocationHandler(locationRepo)
+	clinicScheduleHandler := handlers.NewClinicScheduleHandler(doctorRepo, schedulingService)
+	configHandler := handlers.NewConfigHandler(services.NewPublicConfig(schedulingConfig, localizer))
+
+	documentConfig := services.DefaultDocumentConfig()
+	documentConfig.ClinicName = getEnvString("CLINIC_NAME", documentConfig.ClinicName)
+	documentConfig.ClinicAddress = getEnvString("CLINIC_ADDRESS", documentConfig.ClinicAddress)
+	documentConfig.ClinicPhone = getEnvString("CLINIC_PHONE", documentConfig.ClinicPhone)
+	documentHandler := handlers.NewDocumentHandler(schedulingService, services.NewDocumentService(documentConfig))
 
 	// API v1 routes
 	v1 := router.Group("/api/v1")
@@ -122,43 +241,216 @@ func SetupRoutes(db *gorm.DB) *gin.Engine {
 		}
 		c.JSON(200, gin.H{"status": "healthy", "cache": "connected"})
 	})
+
+	// Client configuration (public)
+	v1.GET("/config", configHandler.GetPublicConfig) // GET /api/v1/config
 	{
 		// Authentication routes (public)
 		auth := v1.Group("/auth")
 		{
-			auth.POST("/login", authHandler.Login)                                        // POST /api/v1/auth/login
-			auth.GET("/validate", middleware.AuthMiddleware(), authHandler.ValidateToken) // GET /api/v1/auth/validate
-			auth.POST("/logout", middleware.AuthMiddleware(), authHandler.Logout)         // POST /api/v1/auth/logout
+			auth.POST("/login", authHandler.Login)                                                  // POST /api/v1/auth/login
+			auth.GET("/validate", middleware.AuthMiddleware(), authHandler.ValidateToken)           // GET /api/v1/auth/validate
+			auth.POST("/logout", middleware.AuthMiddleware(), authHandler.Logout)                   // POST /api/v1/auth/logout
+			auth.GET("/me", middleware.AuthMiddleware(), userHandler.GetMe)                         // GET /api/v1/auth/me
+			auth.PUT("/me/preferences", middleware.AuthMiddleware(), userHandler.UpdatePreferences) // PUT /api/v1/auth/me/preferences
+		}
+
+		// AI service callbacks (authenticated by the shared AI_CALLBACK_SECRET, not a user token)
+		ai := v1.Group("/ai")
+		{
+			ai.POST("/callback", aiHandler.ClassificationCallback) // POST /api/v1/ai/callback
+		}
+
+		// Admin routes (admin only)
+		admin := v1.Group("/admin")
+		admin.Use(middleware.AuthMiddleware(), middleware.RequireRole("admin"), middleware.AdminAudit(adminAuditRepo))
+		{
+			admin.GET("/flags", adminHandler.GetFeatureFlags)         // GET /api/v1/admin/flags
+			admin.PUT("/flags/:name", adminHandler.UpdateFeatureFlag) // PUT /api/v1/admin/flags/:name
+			admin.GET("/audit", adminHandler.GetAuditLog)             // GET /api/v1/admin/audit
+
+			// Operations visibility
+			admin.GET("/reminders/due", adminHandler.GetDueReminders) // GET /api/v1/admin/reminders/due
+
+			// Compliance exports
+			admin.GET("/notifications/export", adminHandler.ExportNotificationLogs) // GET /api/v1/admin/notifications/export
+
+			// Maintenance
+			admin.POST("/purge", adminHandler.PurgeDeleted)                            // POST /api/v1/admin/purge
+			admin.GET("/slots/orphans", scheduleHandler.GetOrphanedSlots)              // GET /api/v1/admin/slots/orphans
+			admin.POST("/slots/orphans/reclaim", scheduleHandler.ReclaimOrphanedSlots) // POST /api/v1/admin/slots/orphans/reclaim
+
+			// Holidays
+			admin.GET("/holidays", holidayHandler.GetHolidays)          // GET /api/v1/admin/holidays
+			admin.POST("/holidays", holidayHandler.CreateHoliday)       // POST /api/v1/admin/holidays
+			admin.PUT("/holidays/:id", holidayHandler.UpdateHoliday)    // PUT /api/v1/admin/holidays/:id
+			admin.DELETE("/holidays/:id", holidayHandler.DeleteHoliday) // DELETE /api/v1/admin/holidays/:id
+
+			// Locations
+			admin.POST("/locations", locationHandler.CreateLocation) // POST /api/v1/admin/locations
+		}
+
+		// Location routes (protected)
+		locations := v1.Group("/locations")
+		locations.Use(middleware.AuthMiddleware())
+		{
+			locations.GET("", locationHandler.GetLocations) // GET /api/v1/locations
+		}
+
+		// Clinic-wide schedule routes (admin only)
+		schedule := v1.Group("/schedule")
+		schedule.Use(middleware.AuthMiddleware(), middleware.RequireRole("admin"))
+		{
+			schedule.GET("/day", clinicScheduleHandler.GetClinicDay) // GET /api/v1/schedule/day
 		}
 
 		// Doctor routes (protected)
 		doctors := v1.Group("/doctors")
-		doctors.Use(middleware.AuthMiddleware()) // Apply auth middleware to all doctor routes
+		doctors.Use(middleware.AuthMiddleware(), middleware.AdminAudit(adminAuditRepo)) // Apply auth middleware to all doctor routes and audit admin writes
 		{
 			doctors.POST("", doctorHandler.CreateDoctor)       // POST /api/v1/doctors
 			doctors.GET("/:id", doctorHandler.GetDoctor)       // GET /api/v1/doctors/:id
 			doctors.GET("", doctorHandler.GetAllDoctors)       // GET /api/v1/doctors
 			doctors.PUT("/:id", doctorHandler.UpdateDoctor)    // PUT /api/v1/doctors/:id
 			doctors.DELETE("/:id", doctorHandler.DeleteDoctor) // DELETE /api/v1/doctors/:id
+
+			// Bulk administration (admin only)
+			doctors.POST("/bulk-status", middleware.RequireRole("admin"), doctorHandler.BulkUpdateDoctorStatus) // POST /api/v1/doctors/bulk-status
+
+			// Appointment/slot consistency checks (admin only)
+			doctors.GET("/:id/diagnostics", middleware.RequireRole("admin"), scheduleHandler.GetScheduleDiagnostics) // GET /api/v1/doctors/:id/diagnostics
+
+			// Cleaning up mistakenly generated slots; booked slots are kept (admin only)
+			doctors.DELETE("/:id/slots", middleware.RequireRole("admin"), scheduleHandler.DeleteSlots) // DELETE /api/v1/doctors/:id/slots
+
+			// Profile and busyness overview
+			doctors.GET("/:id/calendar", scheduleHandler.GetDoctorCalendar) // GET /api/v1/doctors/:id/calendar
+			doctors.GET("/:id/summary", doctorHandler.GetDoctorSummary)     // GET /api/v1/doctors/:id/summary
+			doctors.GET("/:id/time-off", scheduleHandler.GetDoctorTimeOff)  // GET /api/v1/doctors/:id/time-off
+
+			// Finding a time that fits
+			doctors.GET("/:id/bookable-windows", scheduleHandler.GetBookableWindows)                    // GET /api/v1/doctors/:id/bookable-windows
+			doctors.GET("/:id/availability/all-locations", scheduleHandler.GetAllLocationsAvailability) // GET /api/v1/doctors/:id/availability/all-locations
+			doctors.GET("/:id/wait-estimate", scheduleHandler.GetWaitEstimate)                          // GET /api/v1/doctors/:id/wait-estimate
+
+			// Schedule and time slot management (admins, and doctors for their own schedule)
+			staff := doctors.Group("", middleware.RequireRole("doctor", "admin"))
+			staff.GET("/:id/slots", scheduleHandler.GetDoctorSlots)                           // GET /api/v1/doctors/:id/slots
+			staff.POST("/:id/slots/generate", scheduleHandler.GenerateWeeklySlots)            // POST /api/v1/doctors/:id/slots/generate
+			staff.GET("/:id/slots/preview", scheduleHandler.PreviewTimeSlots)                 // GET /api/v1/doctors/:id/slots/preview
+			staff.POST("/:id/slots/forecast-generate", scheduleHandler.ForecastGenerateSlots) // POST /api/v1/doctors/:id/slots/forecast-generate
+			staff.GET("/:id/schedule/grid", scheduleHandler.GetScheduleGrid)                  // GET /api/v1/doctors/:id/schedule/grid
+			staff.GET("/:id/schedule/validate", scheduleHandler.ValidateSchedule)             // GET /api/v1/doctors/:id/schedule/validate
+			staff.GET("/:id/duration-insights", scheduleHandler.GetDurationInsights)          // GET /api/v1/doctors/:id/duration-insights
+			staff.GET("/:id/reliability", scheduleHandler.GetDoctorReliability)               // GET /api/v1/doctors/:id/reliability
+			staff.GET("/:id/schedule.ics", scheduleHandler.GetScheduleICS)                    // GET /api/v1/doctors/:id/schedule.ics
+			staff.POST("/:id/availability-override", scheduleHandler.AddAvailabilityOverride) // POST /api/v1/doctors/:id/availability-override
+			staff.POST("/:id/shift", scheduleHandler.ShiftAppointments)                       // POST /api/v1/doctors/:id/shift
+			staff.GET("/:id/blocks", scheduleHandler.GetBlockedPeriods)                       // GET /api/v1/doctors/:id/blocks
+			staff.PUT("/:id/accepting-bookings", doctorHandler.SetAcceptingBookings)          // PUT /api/v1/doctors/:id/accepting-bookings
+		}
+
+		// Specialty routes (protected)
+		specialties := v1.Group("/specialties")
+		specialties.Use(middleware.AuthMiddleware())
+		{
+			specialties.GET("/bookable", doctorHandler.GetBookableSpecialties)   // GET /api/v1/specialties/bookable
+			specialties.GET("/by-name", doctorHandler.GetSpecialtyByName)        // GET /api/v1/specialties/by-name
+			specialties.GET("/:id/doctors", doctorHandler.GetDoctorsBySpecialty) // GET /api/v1/specialties/:id/doctors
+		}
+
+		// Reporting routes (admin only)
+		stats := v1.Group("/stats")
+		stats.Use(middleware.AuthMiddleware(), middleware.RequireRole("admin"))
+		{
+			stats.GET("/specialties", statsHandler.GetSpecialtyStats)      // GET /api/v1/stats/specialties
+			stats.GET("/cancellations", statsHandler.GetCancellationStats) // GET /api/v1/stats/cancellations
+			stats.GET("/ai-specialty", statsHandler.GetAISpecialtyStats)   // GET /api/v1/stats/ai-specialty
+		}
+
+		// User routes (doctor/admin)
+		users := v1.Group("/users")
+		users.Use(middleware.AuthMiddleware(), middleware.RequireRole("doctor", "admin"))
+		{
+			users.GET("/:id/no-show-stats", appointmentHandler.GetNoShowStats) // GET /api/v1/users/:id/no-show-stats
+		}
+
+		// The authenticated user's own account (any role)
+		me := v1.Group("/users/me")
+		me.Use(middleware.AuthMiddleware())
+		{
+			me.GET("/reminders", userHandler.GetMyReminders) // GET /api/v1/users/me/reminders
+		}
+
+		// Review routes (protected)
+		reviews := v1.Group("/reviews")
+		reviews.Use(middleware.AuthMiddleware())
+		{
+			reviews.POST("", appointmentHandler.SubmitReview)                          // POST /api/v1/reviews
+			reviews.GET("/eligible", appointmentHandler.GetReviewEligibleAppointments) // GET /api/v1/reviews/eligible
+		}
+
+		// Waitlist routes (protected, behind the waitlist feature flag)
+		waitlist := v1.Group("/waitlist")
+		waitlist.Use(middleware.AuthMiddleware(), middleware.RequireFeature(featureFlags, services.FlagWaitlist))
+		{
+			waitlist.POST("", waitlistHandler.JoinWaitlist)                     // POST /api/v1/waitlist
+			waitlist.POST("/offers/:token/accept", waitlistHandler.AcceptOffer) // POST /api/v1/waitlist/offers/:token/accept
+			waitlist.POST("/:id/book", waitlistHandler.BookEntry)               // POST /api/v1/waitlist/:id/book
 		}
 
 		// Appointment routes (protected)
 		appointments := v1.Group("/appointments")
-		appointments.Use(middleware.AuthMiddleware()) // Apply auth middleware to all appointment routes
+		appointments.Use(middleware.AuthMiddleware(), middleware.AdminAudit(adminAuditRepo)) // Apply auth middleware to all appointment routes and audit admin writes
 		{
 			// Core appointment management
 			appointments.POST("/book", appointmentHandler.BookAppointment)                // POST /api/v1/appointments/book
 			appointments.DELETE("/:id/cancel", appointmentHandler.CancelAppointment)      // DELETE /api/v1/appointments/:id/cancel
 			appointments.PUT("/:id/reschedule", appointmentHandler.RescheduleAppointment) // PUT /api/v1/appointments/:id/reschedule
+			appointments.GET("/:id", appointmentHandler.GetAppointment)                   // GET /api/v1/appointments/:id
+			appointments.GET("/:id/pdf", documentHandler.GetAppointmentPDF)               // GET /api/v1/appointments/:id/pdf
+			appointments.POST("/:id/deposit", appointmentHandler.ConfirmDeposit)          // POST /api/v1/appointments/:id/deposit
+
+			// Notifications
+			appointments.POST("/:id/resend-confirmation", appointmentHandler.ResendConfirmation) // POST /api/v1/appointments/:id/resend-confirmation
+
+			// Clinical changes by doctors and admins
+			appointments.PATCH("/:id/type", middleware.RequireRole("doctor", "admin"), appointmentHandler.ChangeAppointmentType) // PATCH /api/v1/appointments/:id/type
+			appointments.PATCH("/:id/tags", middleware.RequireRole("doctor", "admin"), appointmentHandler.SetAppointmentTags)    // PATCH /api/v1/appointments/:id/tags
+
+			// Morning review: confirm many appointments at once (doctor/admin)
+			appointments.POST("/confirm-batch", middleware.RequireRole("doctor", "admin"), appointmentHandler.ConfirmAppointments) // POST /api/v1/appointments/confirm-batch
+
+			// Incremental sync: own appointments for patients, everyone's for admins
+			appointments.GET("/changes", appointmentHandler.GetAppointmentChanges) // GET /api/v1/appointments/changes
+
+			// Per-appointment reminder override (ownership checked in the handler)
+			appointments.PATCH("/:id/reminder", appointmentHandler.OverrideReminder) // PATCH /api/v1/appointments/:id/reminder
+
+			// Re-run AI specialty classification of a symptom-based booking (admin)
+			appointments.POST("/:id/classify", middleware.RequireRole("admin"), appointmentHandler.ClassifyAppointment) // POST /api/v1/appointments/:id/classify
+
+			// Front-desk dashboard: several patients' upcoming appointments at once (doctor/admin)
+			appointments.POST("/upcoming/batch", middleware.RequireRole("doctor", "admin"), appointmentHandler.GetUpcomingAppointmentsBatch) // POST /api/v1/appointments/upcoming/batch
+
+			// Visit history for doctors and admins
+			appointments.GET("/history", middleware.RequireRole("doctor", "admin"), appointmentHandler.GetAppointmentHistory) // GET /api/v1/appointments/history
 
 			// Availability and viewing
-			appointments.GET("/availability", appointmentHandler.GetDoctorAvailability) // GET /api/v1/appointments/availability
-			appointments.GET("/patient", appointmentHandler.GetPatientAppointments)     // GET /api/v1/appointments/patient
-			appointments.GET("/upcoming", appointmentHandler.GetUpcomingAppointments)   // GET /api/v1/appointments/upcoming
-			appointments.GET("/doctor/:id", appointmentHandler.GetDoctorAppointments)   // GET /api/v1/appointments/doctor/:id
+			appointments.GET("/availability", appointmentHandler.GetDoctorAvailability)            // GET /api/v1/appointments/availability
+			appointments.GET("/availability/multi", appointmentHandler.GetMultiDoctorAvailability) // GET /api/v1/appointments/availability/multi
+			appointments.GET("/availability/stream", appointmentHandler.StreamDoctorAvailability)  // GET /api/v1/appointments/availability/stream
+			appointments.GET("/alternative-doctors", appointmentHandler.GetAlternativeDoctors)     // GET /api/v1/appointments/alternative-doctors
+			appointments.GET("/patient", appointmentHandler.GetPatientAppointments)                // GET /api/v1/appointments/patient
+			appointments.GET("/upcoming", appointmentHandler.GetUpcomingAppointments)              // GET /api/v1/appointments/upcoming
+			appointments.GET("/my.ics", appointmentHandler.GetMyAppointmentsICS)                   // GET /api/v1/appointments/my.ics
+			appointments.GET("/doctor/:id", appointmentHandler.GetDoctorAppointments)              // GET /api/v1/appointments/doctor/:id
+			appointments.GET("/:id/reminders", appointmentHandler.GetAppointmentReminders)         // GET /api/v1/appointments/:id/reminders
+			appointments.GET("/:id/chain", appointmentHandler.GetRescheduleChain)                  // GET /api/v1/appointments/:id/chain
 
 			// Utility endpoints
 			appointments.GET("/check-availability", appointmentHandler.CheckTimeSlotAvailability) // GET /api/v1/appointments/check-availability
+			appointments.POST("/best-slot", appointmentHandler.FindBestSlot)                      // POST /api/v1/appointments/best-slot
 		}
 	}
 
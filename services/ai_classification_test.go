@@ -0,0 +1,87 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository/repotest"
+)
+
+// fixedClassifier answers every symptom with the same specialty
+type fixedClassifier struct {
+	specialtyID int
+	confidence  float64
+}
+
+func (c *fixedClassifier) Classify(symptom string) (*ClassificationResponse, error) {
+	return &ClassificationResponse{SpecialtyID: c.specialtyID, Confidence: c.confidence}, nil
+}
+
+func TestSymptomBookingStoresClassification(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestSchedulingService(db, DefaultSchedulingConfig())
+	service.SetSpecialtyClassifier(&fixedClassifier{specialtyID: 2, confidence: 0.87})
+	day := repotest.Day(0)
+	seedDoctors(t, db, 1)
+	repotest.MustCreate(t, db, &models.Specialty{ID: 2, Name: "Cardiology"})
+	repotest.MustCreate(t, db, repotest.Slot(1, day, 9, 0, 30, models.SlotAvailable))
+
+	appointment, err := service.BookAppointment(&BookingRequest{
+		UserID: 1, DoctorID: 1, AppointmentTime: day.Add(9 * time.Hour), Duration: 30,
+		AppointmentType: models.TypeConsultation, Symptom: "chest pain when climbing stairs",
+	})
+	if err != nil {
+		t.Fatalf("BookAppointment returned error: %v", err)
+	}
+
+	// The classification is stored after booking returns
+	var stored models.Appointment
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(5 * time.Millisecond) {
+		if err := db.First(&stored, appointment.ID).Error; err != nil {
+			t.Fatalf("failed to load appointment: %v", err)
+		}
+		if stored.AISpecialtyID != nil || time.Now().After(deadline) {
+			break
+		}
+	}
+
+	if stored.Symptom != "chest pain when climbing stairs" {
+		t.Errorf("expected the symptom to be stored, got %q", stored.Symptom)
+	}
+	if stored.AISpecialtyID == nil || *stored.AISpecialtyID != 2 {
+		t.Errorf("expected AI specialty 2, got %v", stored.AISpecialtyID)
+	}
+	if stored.AIConfidence == nil || *stored.AIConfidence != 0.87 {
+		t.Errorf("expected AI confidence 0.87, got %v", stored.AIConfidence)
+	}
+}
+
+func TestClassifyAppointmentRequiresSymptomAndClassifier(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestSchedulingService(db, DefaultSchedulingConfig())
+	seedDoctors(t, db, 1)
+	appointment := repotest.Appointment(1, 1, repotest.Day(0).Add(9*time.Hour), 30, models.StatusScheduled)
+	repotest.MustCreate(t, db, appointment)
+
+	if _, err := service.ClassifyAppointment(appointment.ID); !errors.Is(err, ErrClassifierUnavailable) {
+		t.Errorf("expected ErrClassifierUnavailable without a classifier, got %v", err)
+	}
+
+	service.SetSpecialtyClassifier(&fixedClassifier{specialtyID: 1, confidence: 0.6})
+	if _, err := service.ClassifyAppointment(appointment.ID); err == nil {
+		t.Error("expected an appointment booked without a symptom to be rejected")
+	}
+
+	if err := db.Model(appointment).Update("symptom", "persistent cough").Error; err != nil {
+		t.Fatalf("failed to set symptom: %v", err)
+	}
+	classified, err := service.ClassifyAppointment(appointment.ID)
+	if err != nil {
+		t.Fatalf("ClassifyAppointment returned error: %v", err)
+	}
+	if classified.AISpecialtyID == nil || *classified.AISpecialtyID != 1 || classified.AIConfidence == nil || *classified.AIConfidence != 0.6 {
+		t.Errorf("expected specialty 1 at confidence 0.6, got %v at %v", classified.AISpecialtyID, classified.AIConfidence)
+	}
+}
@@ -30,11 +30,15 @@ type SymptomRequest struct {
 	Symptom string `json:"symptom"`
 }
 
-// ClassificationResponse represents the response from the AI service
+// ClassificationResponse represents the response from the AI service. For large batches the
+// service may answer asynchronously: it then returns only a request ID, Pending is set, and the
+// classification arrives later at the callback endpoint.
 type ClassificationResponse struct {
 	SpecialtyID int     `json:"specialty_id"`
 	Confidence  float64 `json:"confidence,omitempty"`
 	Message     string  `json:"message,omitempty"`
+	RequestID   string  `json:"request_id,omitempty"`
+	Pending     bool    `json:"-"`
 }
 
 // ErrorResponse represents an error response from the AI service
@@ -43,11 +47,26 @@ type ErrorResponse struct {
 	Message string `json:"message"`
 }
 
+// SpecialtyClassifier classifies a patient's symptom into a recommended specialty
+type SpecialtyClassifier interface {
+	Classify(symptom string) (*ClassificationResponse, error)
+}
+
 // SuggestSpecialty makes a POST request to the external Python AI service
 // to classify a symptom and return the recommended specialty_id
 func (s *AIService) SuggestSpecialty(symptom string) (int, error) {
+	classification, err := s.Classify(symptom)
+	if err != nil {
+		return 0, err
+	}
+	return classification.SpecialtyID, nil
+}
+
+// Classify asks the external Python AI service for the specialty recommended for a symptom,
+// along with its confidence
+func (s *AIService) Classify(symptom string) (*ClassificationResponse, error) {
 	if symptom == "" {
-		return 0, fmt.Errorf("symptom cannot be empty")
+		return nil, fmt.Errorf("symptom cannot be empty")
 	}
 
 	// Prepare request payload
@@ -58,14 +77,14 @@ func (s *AIService) SuggestSpecialty(symptom string) (int, error) {
 	// Marshal request to JSON
 	jsonData, err := json.Marshal(reqPayload)
 	if err != nil {
-		return 0, fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	// Create HTTP request
 	url := fmt.Sprintf("%s/api/classify", s.baseURL)
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return 0, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set headers
@@ -75,7 +94,7 @@ func (s *AIService) SuggestSpecialty(symptom string) (int, error) {
 	// Make the request
 	resp, err := s.client.Do(req)
 	if err != nil {
-		return 0, fmt.Errorf("failed to make request to AI service: %w", err)
+		return nil, fmt.Errorf("failed to make request to AI service: %w", err)
 	}
 
 	// Ensure response body is always closed, even in error scenarios
@@ -91,30 +110,43 @@ func (s *AIService) SuggestSpecialty(symptom string) (int, error) {
 	// Read response body with proper error handling
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return 0, fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// The service accepted the symptom and will post the classification to our callback
+	if resp.StatusCode == http.StatusAccepted {
+		var pendingResp ClassificationResponse
+		if err := json.Unmarshal(body, &pendingResp); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+		if pendingResp.RequestID == "" {
+			return nil, fmt.Errorf("AI service accepted the request without a request_id")
+		}
+		pendingResp.Pending = true
+		return &pendingResp, nil
 	}
 
 	// Handle non-200 status codes
 	if resp.StatusCode != http.StatusOK {
 		var errorResp ErrorResponse
 		if err := json.Unmarshal(body, &errorResp); err != nil {
-			return 0, fmt.Errorf("AI service returned status %d: %s", resp.StatusCode, string(body))
+			return nil, fmt.Errorf("AI service returned status %d: %s", resp.StatusCode, string(body))
 		}
-		return 0, fmt.Errorf("AI service error (%d): %s - %s", resp.StatusCode, errorResp.Error, errorResp.Message)
+		return nil, fmt.Errorf("AI service error (%d): %s - %s", resp.StatusCode, errorResp.Error, errorResp.Message)
 	}
 
 	// Parse successful response
 	var classificationResp ClassificationResponse
 	if err := json.Unmarshal(body, &classificationResp); err != nil {
-		return 0, fmt.Errorf("failed to parse response: %w", err)
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
 	// Validate specialty_id
 	if classificationResp.SpecialtyID <= 0 {
-		return 0, fmt.Errorf("invalid specialty_id received: %d", classificationResp.SpecialtyID)
+		return nil, fmt.Errorf("invalid specialty_id received: %d", classificationResp.SpecialtyID)
 	}
 
-	return classificationResp.SpecialtyID, nil
+	return &classificationResp, nil
 }
 
 // SuggestSpecialty is a convenience function that creates a default AIService
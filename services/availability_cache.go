@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/utils"
+)
+
+// availabilityWeek is the cached availability for one doctor and week, keyed by YYYY-MM-DD
+type availabilityWeek map[string]*models.AvailabilityResponse
+
+// availabilityWeekKey returns the cache key for a doctor's availability in the week starting weekStart
+func availabilityWeekKey(doctorID uint, weekStart time.Time) string {
+	return fmt.Sprintf("availability:doctor:%d:week:%s", doctorID, weekStart.Format("2006-01-02"))
+}
+
+// startOfWeek returns midnight UTC on the Monday of the week containing t
+func startOfWeek(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// availabilityCacheEnabled reports whether availability is read from and written to the cache
+func (s *schedulingService) availabilityCacheEnabled() bool {
+	return s.cacheService != nil && s.config.AvailabilityCacheTTL > 0
+}
+
+// cachedAvailability returns a day's availability from its cached week, if that week is warm
+func (s *schedulingService) cachedAvailability(doctorID uint, date time.Time) (*models.AvailabilityResponse, bool) {
+	if !s.availabilityCacheEnabled() {
+		return nil, false
+	}
+
+	var week availabilityWeek
+	if err := s.cacheService.Get(context.Background(), availabilityWeekKey(doctorID, startOfWeek(date)), &week); err != nil {
+		return nil, false
+	}
+	availability, ok := week[date.Format("2006-01-02")]
+	return availability, ok && availability != nil
+}
+
+// WarmDoctorAvailability computes and caches availability for every week overlapping the days days
+// starting at from. A week is only cached when every one of its days loads.
+func (s *schedulingService) WarmDoctorAvailability(doctorID uint, from time.Time, days int) error {
+	if !s.availabilityCacheEnabled() {
+		return nil
+	}
+
+	until := from.AddDate(0, 0, days)
+	for weekStart := startOfWeek(from); weekStart.Before(until); weekStart = weekStart.AddDate(0, 0, 7) {
+		week := make(availabilityWeek, 7)
+		for day := 0; day < 7; day++ {
+			date := weekStart.AddDate(0, 0, day)
+			availability, err := s.computeDoctorAvailability(doctorID, date)
+			if err != nil {
+				return fmt.Errorf("failed to compute availability for %s: %w", date.Format("2006-01-02"), err)
+			}
+			week[date.Format("2006-01-02")] = availability
+		}
+
+		if err := s.cacheService.Set(context.Background(), availabilityWeekKey(doctorID, weekStart), week, s.config.AvailabilityCacheTTL); err != nil {
+			return fmt.Errorf("failed to cache availability: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// InvalidateDoctorAvailability drops the doctor's cached availability for the weeks from..to touches,
+// for changes made outside the service such as a new holiday
+func (s *schedulingService) InvalidateDoctorAvailability(doctorID uint, from, to time.Time) {
+	s.invalidateAvailability(doctorID, from, to)
+}
+
+// invalidateAvailability drops the cached weeks a change between startTime and endTime may affect.
+// The range is widened by a day on each side so changes near midnight in any timezone are covered.
+func (s *schedulingService) invalidateAvailability(doctorID uint, startTime, endTime time.Time) {
+	if !s.availabilityCacheEnabled() {
+		return
+	}
+
+	ctx := context.Background()
+	last := startOfWeek(endTime.AddDate(0, 0, 1))
+	for weekStart := startOfWeek(startTime.AddDate(0, 0, -1)); !weekStart.After(last); weekStart = weekStart.AddDate(0, 0, 7) {
+		if err := s.cacheService.Delete(ctx, availabilityWeekKey(doctorID, weekStart)); err != nil {
+			utils.LogWarn("Failed to invalidate availability cache", map[string]interface{}{
+				"doctor_id":  doctorID,
+				"week_start": weekStart.Format("2006-01-02"),
+				"error":      err.Error(),
+			})
+		}
+	}
+}
+
+// AvailabilityWarmer periodically precomputes availability for every active doctor so patient
+// availability requests for the coming days are served from the cache
+type AvailabilityWarmer struct {
+	doctorRepo        repository.DoctorRepository
+	schedulingService SchedulingService
+	days              int
+}
+
+// NewAvailabilityWarmer creates a warmer covering the next days days
+func NewAvailabilityWarmer(doctorRepo repository.DoctorRepository, schedulingService SchedulingService, days int) *AvailabilityWarmer {
+	return &AvailabilityWarmer{
+		doctorRepo:        doctorRepo,
+		schedulingService: schedulingService,
+		days:              days,
+	}
+}
+
+// Start warms the cache immediately and then every interval until the context is cancelled
+func (w *AvailabilityWarmer) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		w.warm(time.Now())
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				w.warm(now)
+			}
+		}
+	}()
+}
+
+// warm runs one warm cycle and logs its outcome
+func (w *AvailabilityWarmer) warm(now time.Time) {
+	warmed, err := w.WarmOnce(now)
+	if err != nil {
+		utils.LogError(err, "Availability cache warm cycle failed", nil)
+		return
+	}
+	utils.LogInfo("Availability cache warmed", map[string]interface{}{
+		"doctors": warmed,
+		"days":    w.days,
+	})
+}
+
+// WarmOnce caches availability for every active doctor from now and returns how many doctors
+// were warmed. A doctor that fails is logged and skipped.
+func (w *AvailabilityWarmer) WarmOnce(now time.Time) (int, error) {
+	doctors, err := w.doctorRepo.GetAllDoctors()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get doctors: %w", err)
+	}
+
+	warmed := 0
+	for _, doctor := range doctors {
+		if !doctor.IsActive {
+			continue
+		}
+		if err := w.schedulingService.WarmDoctorAvailability(doctor.ID, now, w.days); err != nil {
+			utils.LogError(err, "Failed to warm doctor availability", map[string]interface{}{
+				"doctor_id": doctor.ID,
+			})
+			continue
+		}
+		warmed++
+	}
+
+	return warmed, nil
+}
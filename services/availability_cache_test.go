@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/repository/repotest"
+)
+
+func TestWarmOnceCachesAvailabilityForActiveDoctors(t *testing.T) {
+	db := repotest.Open(t)
+	cache := newTestCache()
+	config := DefaultSchedulingConfig()
+	config.AvailabilityCacheTTL = time.Hour
+	service := NewSchedulingServiceWithConfig(
+		repository.NewAppointmentRepository(db),
+		repository.NewTimeSlotRepository(db),
+		NewNotificationService(),
+		cache,
+		config,
+	)
+	day := repotest.Day(2)
+	seedDoctors(t, db, 1, 2)
+	if err := db.Model(&models.Doctor{}).Where("id = ?", 2).Update("is_active", false).Error; err != nil {
+		t.Fatalf("failed to deactivate doctor 2: %v", err)
+	}
+	repotest.MustCreate(t, db,
+		repotest.Slot(1, day, 9, 0, 30, models.SlotAvailable),
+		repotest.Slot(2, day, 9, 0, 30, models.SlotAvailable),
+	)
+
+	warmer := NewAvailabilityWarmer(repository.NewDoctorRepository(db), service, 7)
+	warmed, err := warmer.WarmOnce(day)
+	if err != nil {
+		t.Fatalf("WarmOnce returned error: %v", err)
+	}
+	if warmed != 1 {
+		t.Errorf("expected only the active doctor warmed, got %d", warmed)
+	}
+
+	ctx := context.Background()
+	var week availabilityWeek
+	if err := cache.Get(ctx, availabilityWeekKey(1, repotest.Day(0)), &week); err != nil {
+		t.Fatalf("expected doctor 1's week to be cached: %v", err)
+	}
+	if len(week) != 7 {
+		t.Errorf("expected all 7 days of the week cached, got %d", len(week))
+	}
+	if availability := week[day.Format("2006-01-02")]; availability == nil || len(availability.AvailableSlots) != 1 {
+		t.Errorf("expected the 9:00 slot in the cached day, got %+v", availability)
+	}
+	if cache.Exists(ctx, availabilityWeekKey(2, repotest.Day(0))) {
+		t.Errorf("expected no cached week for the inactive doctor")
+	}
+
+	// Booking the slot drops the cached week so the next read reflects it
+	if _, err := service.BookAppointment(&BookingRequest{
+		UserID: 1, DoctorID: 1, AppointmentTime: day.Add(9 * time.Hour), Duration: 30, AppointmentType: models.TypeConsultation,
+	}); err != nil {
+		t.Fatalf("BookAppointment returned error: %v", err)
+	}
+	if cache.Exists(ctx, availabilityWeekKey(1, repotest.Day(0))) {
+		t.Errorf("expected booking to invalidate the cached week")
+	}
+}
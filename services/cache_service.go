@@ -2,8 +2,11 @@ package services
 
 import (
 	"context"
+	cryptorand "crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math/rand/v2"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -29,6 +32,16 @@ type CacheService interface {
 	SetDoctorsBySpecialty(ctx context.Context, specialtyID uint, doctors []models.Doctor) error
 	GetDoctorsBySpecialty(ctx context.Context, specialtyID uint) ([]models.Doctor, error)
 	InvalidateDoctorCache(ctx context.Context, doctorID uint) error
+	SetAppointment(ctx context.Context, appointment *models.Appointment) error
+	GetAppointment(ctx context.Context, appointmentID uint) (*models.Appointment, error)
+	InvalidateAppointmentCache(ctx context.Context, appointmentID uint) error
+
+	// Distributed locks
+	AcquireLock(ctx context.Context, key string, ttl time.Duration) (token string, acquired bool, err error)
+	ReleaseLock(ctx context.Context, key, token string) error
+
+	// Distributed counters
+	IncrementCounter(ctx context.Context, key string, ttl time.Duration) (int64, error)
 
 	// Health check
 	HealthCheck(ctx context.Context) error
@@ -39,18 +52,28 @@ type cacheService struct {
 	redisClient *redis.Client
 	logger      *logrus.Logger
 	defaultTTL  time.Duration
+	ttlJitter   float64
 }
 
 // CacheConfig holds cache configuration
 type CacheConfig struct {
-	RedisAddr     string
+	RedisAddr     string // Empty selects the in-memory cache
 	RedisPassword string
 	RedisDB       int
 	DefaultTTL    time.Duration
+	// TTLJitterPercent randomly varies each entry's TTL by up to ±N% so keys written
+	// together do not all expire together; 0 disables jitter
+	TTLJitterPercent float64
 }
 
-// NewCacheService creates a new cache service instance
+// NewCacheService creates a new cache service instance. Without a Redis address it falls back to
+// an in-memory cache.
 func NewCacheService(config CacheConfig, logger *logrus.Logger) CacheService {
+	if config.RedisAddr == "" {
+		logger.Warn("REDIS_ADDR is empty; using an in-memory cache, which is not shared between instances")
+		return NewMemoryCacheService(config, logger)
+	}
+
 	rdb := redis.NewClient(&redis.Options{
 		Addr:     config.RedisAddr,
 		Password: config.RedisPassword,
@@ -61,6 +84,7 @@ func NewCacheService(config CacheConfig, logger *logrus.Logger) CacheService {
 		redisClient: rdb,
 		logger:      logger,
 		defaultTTL:  config.DefaultTTL,
+		ttlJitter:   config.TTLJitterPercent / 100,
 	}
 }
 
@@ -72,6 +96,7 @@ func (c *cacheService) Set(ctx context.Context, key string, value interface{}, e
 		return fmt.Errorf("failed to marshal cache value: %w", err)
 	}
 
+	expiration = c.jitteredTTL(expiration)
 	err = c.redisClient.Set(ctx, key, data, expiration).Err()
 	if err != nil {
 		c.logger.Error("Failed to set cache value", "key", key, "error", err)
@@ -82,6 +107,17 @@ func (c *cacheService) Set(ctx context.Context, key string, value interface{}, e
 	return nil
 }
 
+// jitteredTTL spreads an expiration uniformly within ±ttlJitter of its value.
+// Non-positive expirations (no expiry) are returned unchanged.
+func (c *cacheService) jitteredTTL(expiration time.Duration) time.Duration {
+	if expiration <= 0 || c.ttlJitter <= 0 {
+		return expiration
+	}
+
+	factor := 1 + c.ttlJitter*(2*rand.Float64()-1)
+	return time.Duration(float64(expiration) * factor)
+}
+
 // Get retrieves a value from cache
 func (c *cacheService) Get(ctx context.Context, key string, dest interface{}) error {
 	data, err := c.redisClient.Get(ctx, key).Result()
@@ -219,6 +255,77 @@ func (c *cacheService) InvalidateDoctorCache(ctx context.Context, doctorID uint)
 	return nil
 }
 
+// SetAppointment caches a single appointment
+func (c *cacheService) SetAppointment(ctx context.Context, appointment *models.Appointment) error {
+	key := fmt.Sprintf("appointment:%d", appointment.ID)
+	return c.Set(ctx, key, appointment, c.defaultTTL)
+}
+
+// GetAppointment retrieves a cached appointment
+func (c *cacheService) GetAppointment(ctx context.Context, appointmentID uint) (*models.Appointment, error) {
+	key := fmt.Sprintf("appointment:%d", appointmentID)
+	var appointment models.Appointment
+	err := c.Get(ctx, key, &appointment)
+	if err != nil {
+		return nil, err
+	}
+	return &appointment, nil
+}
+
+// InvalidateAppointmentCache removes a cached appointment
+func (c *cacheService) InvalidateAppointmentCache(ctx context.Context, appointmentID uint) error {
+	key := fmt.Sprintf("appointment:%d", appointmentID)
+	return c.Delete(ctx, key)
+}
+
+// releaseLockScript deletes a lock only while it still holds the caller's token, so a holder whose
+// lock expired cannot release a lock since taken by someone else
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// AcquireLock takes the lock at key for ttl with SET NX, returning the token needed to release it.
+// acquired is false when another holder has the lock.
+func (c *cacheService) AcquireLock(ctx context.Context, key string, ttl time.Duration) (string, bool, error) {
+	b := make([]byte, 16)
+	if _, err := cryptorand.Read(b); err != nil {
+		return "", false, fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	token := hex.EncodeToString(b)
+
+	acquired, err := c.redisClient.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		c.logger.Error("Failed to acquire lock", "key", key, "error", err)
+		return "", false, fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	return token, acquired, nil
+}
+
+// ReleaseLock releases the lock at key if it is still held with token
+func (c *cacheService) ReleaseLock(ctx context.Context, key, token string) error {
+	if err := releaseLockScript.Run(ctx, c.redisClient, []string{key}, token).Err(); err != nil {
+		c.logger.Error("Failed to release lock", "key", key, "error", err)
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+	return nil
+}
+
+// IncrementCounter atomically increments the counter at key and returns its new value. The key
+// expires ttl after it is first created, so counters keyed by a time window clean themselves up.
+func (c *cacheService) IncrementCounter(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	pipe := c.redisClient.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.ExpireNX(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		c.logger.Error("Failed to increment counter", "key", key, "error", err)
+		return 0, fmt.Errorf("failed to increment counter: %w", err)
+	}
+	return incr.Val(), nil
+}
+
 // HealthCheck verifies Redis connection
 func (c *cacheService) HealthCheck(ctx context.Context) error {
 	_, err := c.redisClient.Ping(ctx).Result()
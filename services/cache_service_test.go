@@ -0,0 +1,33 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJitteredTTLVariesWithinBand(t *testing.T) {
+	cache := &cacheService{ttlJitter: 0.1}
+	base := 10 * time.Minute
+	low, high := 9*time.Minute, 11*time.Minute
+
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 200; i++ {
+		ttl := cache.jitteredTTL(base)
+		if ttl < low || ttl > high {
+			t.Fatalf("TTL %v outside the ±10%% band [%v, %v]", ttl, low, high)
+		}
+		seen[ttl] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("expected the TTL to vary across sets, got %d distinct values", len(seen))
+	}
+}
+
+func TestJitteredTTLKeepsUnjitteredAndPersistentEntries(t *testing.T) {
+	if ttl := (&cacheService{}).jitteredTTL(time.Minute); ttl != time.Minute {
+		t.Errorf("expected no jitter when disabled, got %v", ttl)
+	}
+	if ttl := (&cacheService{ttlJitter: 0.1}).jitteredTTL(0); ttl != 0 {
+		t.Errorf("expected an entry without expiry to stay without expiry, got %v", ttl)
+	}
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/repository/repotest"
+)
+
+// alternativesRecorder passes on the alternatives offered with each cancellation, since the notice
+// is sent after the cancellation returns
+type alternativesRecorder struct {
+	NotificationService
+	alternatives chan []models.TimeSlot
+}
+
+func (s *alternativesRecorder) SendAppointmentCancellation(appointment *models.Appointment, reason string, alternatives []models.TimeSlot) error {
+	s.alternatives <- alternatives
+	return nil
+}
+
+func TestDoctorCancellationSuggestsAlternatives(t *testing.T) {
+	db := repotest.Open(t)
+	notifications := &alternativesRecorder{NotificationService: NewNotificationService(), alternatives: make(chan []models.TimeSlot, 1)}
+	service := NewSchedulingServiceWithConfig(
+		repository.NewAppointmentRepository(db),
+		repository.NewTimeSlotRepository(db),
+		notifications,
+		nil,
+		DefaultSchedulingConfig(),
+	)
+	day := repotest.Day(0)
+	seedDoctors(t, db, 1)
+	for hour := 9; hour <= 11; hour++ {
+		repotest.MustCreate(t, db, repotest.Slot(1, day, hour, 0, 30, models.SlotAvailable))
+	}
+
+	tests := []struct {
+		cancelledBy string
+		want        []int
+	}{
+		{"doctor", []int{10, 11}},
+		{"patient", nil},
+	}
+
+	for _, tt := range tests {
+		appointment := repotest.Appointment(1, 1, day.Add(9*time.Hour), 30, models.StatusScheduled)
+		repotest.MustCreate(t, db, appointment)
+		if err := service.CancelAppointment(context.Background(), appointment.ID, tt.cancelledBy, models.CancellationDoctorUnavailable, ""); err != nil {
+			t.Fatalf("CancelAppointment returned error: %v", err)
+		}
+
+		var alternatives []models.TimeSlot
+		select {
+		case alternatives = <-notifications.alternatives:
+		case <-time.After(time.Second):
+			t.Fatalf("expected a cancellation notice when cancelled by %s", tt.cancelledBy)
+		}
+		if len(alternatives) != len(tt.want) {
+			t.Fatalf("cancelled by %s: expected %d alternatives, got %d", tt.cancelledBy, len(tt.want), len(alternatives))
+		}
+		for i, hour := range tt.want {
+			if want := day.Add(time.Duration(hour) * time.Hour); !alternatives[i].StartTime.Equal(want) {
+				t.Errorf("cancelled by %s: expected alternative %d at %v, got %v", tt.cancelledBy, i, want, alternatives[i].StartTime)
+			}
+		}
+	}
+}
+
+func TestCancellationMessageListsSuggestedTimes(t *testing.T) {
+	hook := captureMessages(t)
+	day := repotest.Day(0)
+	appointment := &models.Appointment{ID: 7, UserID: 1, AppointmentTime: day.Add(9 * time.Hour)}
+	alternatives := []models.TimeSlot{
+		*repotest.Slot(1, day, 10, 0, 30, models.SlotAvailable),
+		*repotest.Slot(1, day, 14, 30, 30, models.SlotAvailable),
+	}
+
+	if err := NewNotificationService().SendAppointmentCancellation(appointment, "Doctor unavailable", alternatives); err != nil {
+		t.Fatalf("SendAppointmentCancellation returned error: %v", err)
+	}
+	entry := hook.LastEntry()
+	if entry == nil {
+		t.Fatal("expected the cancellation to be logged")
+	}
+	message, _ := entry.Data["message"].(string)
+	if want := "Suggested times: March 3, 2031 at 10:00 AM; March 3, 2031 at 2:30 PM."; !strings.HasSuffix(message, want) {
+		t.Errorf("expected the message to end with %q, got %q", want, message)
+	}
+
+	hook.Reset()
+	if err := NewNotificationService().SendAppointmentCancellation(appointment, "Doctor unavailable", nil); err != nil {
+		t.Fatalf("SendAppointmentCancellation returned error: %v", err)
+	}
+	if message, _ := hook.LastEntry().Data["message"].(string); strings.Contains(message, "Suggested times") {
+		t.Errorf("expected no suggestions without alternatives, got %q", message)
+	}
+}
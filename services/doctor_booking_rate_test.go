@@ -0,0 +1,68 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/sirupsen/logrus"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/repository/repotest"
+)
+
+func TestBookingAttemptsAreThrottledPerDoctor(t *testing.T) {
+	server := miniredis.RunT(t)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	cache := NewCacheService(CacheConfig{RedisAddr: server.Addr(), DefaultTTL: time.Hour}, logger)
+
+	db := repotest.Open(t)
+	config := DefaultSchedulingConfig()
+	config.DoctorBookingRateLimit = 3
+	service := NewSchedulingServiceWithConfig(
+		repository.NewAppointmentRepository(db),
+		repository.NewTimeSlotRepository(db),
+		NewNotificationService(),
+		cache,
+		config,
+	)
+	day := repotest.Day(0)
+	seedDoctors(t, db, 1, 2)
+	for hour := 9; hour < 14; hour++ {
+		repotest.MustCreate(t, db,
+			repotest.Slot(1, day, hour, 0, 30, models.SlotAvailable),
+			repotest.Slot(2, day, hour, 0, 30, models.SlotAvailable),
+		)
+	}
+	book := func(userID, doctorID uint, hour int) error {
+		_, err := service.BookAppointment(&BookingRequest{
+			UserID: userID, DoctorID: doctorID, AppointmentTime: day.Add(time.Duration(hour) * time.Hour), Duration: 30,
+			AppointmentType: models.TypeConsultation,
+		})
+		return err
+	}
+
+	// The window is one wall-clock second; start at the top of one so every attempt shares it
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+	for i := 0; i < config.DoctorBookingRateLimit; i++ {
+		if err := book(uint(i+1), 1, 9+i); err != nil {
+			t.Fatalf("booking %d returned error: %v", i, err)
+		}
+	}
+	if err := book(9, 1, 12); !errors.Is(err, ErrDoctorBookingRateLimited) {
+		t.Fatalf("expected the attempt over the limit to be throttled, got %v", err)
+	}
+	if err := book(9, 2, 12); err != nil {
+		t.Errorf("expected another doctor to stay bookable, got %v", err)
+	}
+
+	// Throttled attempts book nothing, and the limit resets with the next window
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+	if err := book(9, 1, 12); err != nil {
+		t.Errorf("expected booking to succeed in the next window, got %v", err)
+	}
+}
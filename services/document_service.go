@@ -0,0 +1,104 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/go-pdf/fpdf"
+	"smart-doctor-booking-app/models"
+)
+
+// DocumentConfig holds the clinic details printed on generated documents
+type DocumentConfig struct {
+	ClinicName    string
+	ClinicAddress string
+	ClinicPhone   string
+	Instructions  string
+}
+
+// DefaultDocumentConfig returns default document configuration
+func DefaultDocumentConfig() DocumentConfig {
+	return DocumentConfig{
+		ClinicName:   "Smart Doctor Clinic",
+		Instructions: "Please arrive 15 minutes before your appointment and bring a valid ID and any relevant medical records. To cancel or reschedule, use the app at least 24 hours in advance.",
+	}
+}
+
+// DocumentService generates printable documents for appointments
+type DocumentService interface {
+	AppointmentConfirmationPDF(appointment *models.Appointment, patientName string) ([]byte, error)
+}
+
+// documentService implements DocumentService
+type documentService struct {
+	config DocumentConfig
+}
+
+// NewDocumentService creates a new document service
+func NewDocumentService(config DocumentConfig) DocumentService {
+	return &documentService{config: config}
+}
+
+// AppointmentConfirmationPDF renders a one-page appointment confirmation
+func (s *documentService) AppointmentConfirmationPDF(appointment *models.Appointment, patientName string) ([]byte, error) {
+	if appointment == nil {
+		return nil, errors.New("appointment cannot be nil")
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle("Appointment Confirmation", false)
+	pdf.SetMargins(20, 20, 20)
+	pdf.AddPage()
+
+	// Clinic header
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(0, 10, s.config.ClinicName, "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	for _, line := range []string{s.config.ClinicAddress, s.config.ClinicPhone} {
+		if line != "" {
+			pdf.CellFormat(0, 5, line, "", 1, "L", false, 0, "")
+		}
+	}
+	pdf.Ln(8)
+
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 8, "Appointment Confirmation", "B", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	doctor := appointment.Doctor.Name
+	if appointment.Doctor.Specialty.Name != "" {
+		doctor = fmt.Sprintf("%s (%s)", doctor, appointment.Doctor.Specialty.Name)
+	}
+
+	rows := [][2]string{
+		{"Appointment ID", fmt.Sprintf("%d", appointment.ID)},
+		{"Patient", patientName},
+		{"Doctor", doctor},
+		{"Date", appointment.AppointmentTime.Format("Monday, January 2, 2006")},
+		{"Time", fmt.Sprintf("%s - %s", appointment.AppointmentTime.Format("3:04 PM"), appointment.EndTime.Format("3:04 PM"))},
+		{"Type", string(appointment.Type)},
+		{"Status", string(appointment.Status)},
+	}
+	for _, row := range rows {
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.CellFormat(45, 8, row[0], "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.CellFormat(0, 8, row[1], "", 1, "L", false, 0, "")
+	}
+
+	if s.config.Instructions != "" {
+		pdf.Ln(6)
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.CellFormat(0, 8, "Instructions", "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.MultiCell(0, 6, s.config.Instructions, "", "L", false)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render appointment PDF: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"smart-doctor-booking-app/models"
+)
+
+// pdfStream matches one content stream of a rendered PDF
+var pdfStream = regexp.MustCompile(`(?s)stream\r?\n(.*?)\r?\nendstream`)
+
+// pdfContent returns the inflated content streams of a PDF, where its text is drawn
+func pdfContent(t *testing.T, document []byte) string {
+	t.Helper()
+
+	var content strings.Builder
+	for _, match := range pdfStream.FindAllSubmatch(document, -1) {
+		reader, err := zlib.NewReader(bytes.NewReader(match[1]))
+		if err != nil {
+			// Uncompressed streams are drawn as is
+			content.Write(match[1])
+			continue
+		}
+		inflated, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("failed to inflate PDF stream: %v", err)
+		}
+		content.Write(inflated)
+	}
+	return content.String()
+}
+
+func TestAppointmentConfirmationPDFRendersAppointmentTime(t *testing.T) {
+	service := NewDocumentService(DefaultDocumentConfig())
+	start := time.Date(2031, time.March, 3, 10, 0, 0, 0, time.UTC)
+	appointment := &models.Appointment{
+		ID:              42,
+		AppointmentTime: start,
+		EndTime:         start.Add(30 * time.Minute),
+		Type:            models.TypeConsultation,
+		Status:          models.StatusScheduled,
+		Doctor:          models.Doctor{Name: "Dr. Ada Lovelace"},
+	}
+
+	document, err := service.AppointmentConfirmationPDF(appointment, "Grace Hopper")
+	if err != nil {
+		t.Fatalf("AppointmentConfirmationPDF returned error: %v", err)
+	}
+	if !bytes.HasPrefix(document, []byte("%PDF")) {
+		t.Fatalf("expected the document to start with %%PDF, got %q", document[:min(len(document), 8)])
+	}
+
+	content := pdfContent(t, document)
+	for _, want := range []string{"Monday, March 3, 2031", "10:00 AM - 10:30 AM", "Grace Hopper", "Dr. Ada Lovelace"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected the PDF to contain %q", want)
+		}
+	}
+}
+
+func TestAppointmentConfirmationPDFRejectsNilAppointment(t *testing.T) {
+	service := NewDocumentService(DefaultDocumentConfig())
+	if _, err := service.AppointmentConfirmationPDF(nil, "Grace Hopper"); err == nil {
+		t.Error("expected an error for a nil appointment")
+	}
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"smart-doctor-booking-app/utils"
+)
+
+// FeatureFlag identifies a feature that can be toggled at runtime
+type FeatureFlag string
+
+const (
+	FlagWaitlist      FeatureFlag = "waitlist"
+	FlagBookingHolds  FeatureFlag = "booking_holds"
+	FlagAISuggestions FeatureFlag = "ai_suggestions"
+)
+
+// KnownFeatureFlags lists every flag the application checks
+var KnownFeatureFlags = []FeatureFlag{
+	FlagWaitlist,
+	FlagBookingHolds,
+	FlagAISuggestions,
+}
+
+// IsKnown reports whether the flag is one the application checks
+func (f FeatureFlag) IsKnown() bool {
+	for _, known := range KnownFeatureFlags {
+		if f == known {
+			return true
+		}
+	}
+	return false
+}
+
+// FeatureFlags decides whether optional features are enabled
+type FeatureFlags interface {
+	IsEnabled(ctx context.Context, flag FeatureFlag) bool
+	SetEnabled(ctx context.Context, flag FeatureFlag, enabled bool) error
+	All(ctx context.Context) map[FeatureFlag]bool
+}
+
+// FeatureFlagsConfig holds feature flag configuration
+type FeatureFlagsConfig struct {
+	// Defaults holds the initial state of each flag; flags not listed are off
+	Defaults map[FeatureFlag]bool
+}
+
+// DefaultFeatureFlagsConfig returns configuration with every flag off
+func DefaultFeatureFlagsConfig() FeatureFlagsConfig {
+	return FeatureFlagsConfig{
+		Defaults: make(map[FeatureFlag]bool),
+	}
+}
+
+// featureFlags implements FeatureFlags with in-memory state, optionally shared through the cache
+type featureFlags struct {
+	mu    sync.RWMutex
+	flags map[FeatureFlag]bool
+	cache CacheService
+}
+
+// NewFeatureFlags creates feature flags from config. When cache is non-nil, toggles are
+// stored in Redis so every instance sees them; otherwise they are kept in memory only.
+func NewFeatureFlags(config FeatureFlagsConfig, cache CacheService) FeatureFlags {
+	flags := make(map[FeatureFlag]bool, len(KnownFeatureFlags))
+	for _, flag := range KnownFeatureFlags {
+		flags[flag] = config.Defaults[flag]
+	}
+
+	return &featureFlags{
+		flags: flags,
+		cache: cache,
+	}
+}
+
+// featureFlagKey returns the cache key for a flag
+func featureFlagKey(flag FeatureFlag) string {
+	return fmt.Sprintf("feature_flag:%s", flag)
+}
+
+// IsEnabled reports whether a flag is on. Unknown flags are always off.
+func (f *featureFlags) IsEnabled(ctx context.Context, flag FeatureFlag) bool {
+	if !flag.IsKnown() {
+		return false
+	}
+
+	if f.cache != nil {
+		var enabled bool
+		if err := f.cache.Get(ctx, featureFlagKey(flag), &enabled); err == nil {
+			return enabled
+		}
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.flags[flag]
+}
+
+// SetEnabled toggles a flag
+func (f *featureFlags) SetEnabled(ctx context.Context, flag FeatureFlag, enabled bool) error {
+	if !flag.IsKnown() {
+		return fmt.Errorf("unknown feature flag: %s", flag)
+	}
+
+	f.mu.Lock()
+	f.flags[flag] = enabled
+	f.mu.Unlock()
+
+	if f.cache != nil {
+		if err := f.cache.Set(ctx, featureFlagKey(flag), enabled, 0); err != nil {
+			return fmt.Errorf("failed to store feature flag: %w", err)
+		}
+	}
+
+	utils.LogInfo("Feature flag updated", map[string]interface{}{
+		"flag":    flag,
+		"enabled": enabled,
+	})
+
+	return nil
+}
+
+// All returns the current state of every known flag
+func (f *featureFlags) All(ctx context.Context) map[FeatureFlag]bool {
+	flags := make(map[FeatureFlag]bool, len(KnownFeatureFlags))
+	for _, flag := range KnownFeatureFlags {
+		flags[flag] = f.IsEnabled(ctx, flag)
+	}
+	return flags
+}
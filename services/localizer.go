@@ -0,0 +1,120 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Languages with a notification message catalog
+const (
+	LanguageEnglish = "en"
+	LanguageFrench  = "fr"
+)
+
+// MessageKey identifies a localized notification template
+type MessageKey string
+
+const (
+	MsgAppointmentConfirmation MessageKey = "appointment_confirmation"
+	MsgAppointmentReminder     MessageKey = "appointment_reminder"
+	MsgAppointmentCancellation MessageKey = "appointment_cancellation"
+	MsgAppointmentReschedule   MessageKey = "appointment_reschedule"
+	MsgAutoReschedule          MessageKey = "auto_reschedule"
+	MsgWaitlistOffer           MessageKey = "waitlist_offer"
+	MsgDueReminder             MessageKey = "due_reminder"
+	MsgDoctorNewAppointment    MessageKey = "doctor_new_appointment"
+	MsgDoctorCancellation      MessageKey = "doctor_cancellation"
+	MsgSuggestedTimes          MessageKey = "suggested_times"
+
+	// msgDateTimeLayout and msgClockLayout are the Go time layouts used for dates and times
+	msgDateTimeLayout MessageKey = "layout_date_time"
+	msgClockLayout    MessageKey = "layout_clock"
+)
+
+// defaultCatalogs holds the built-in notification templates. Templates are fmt format strings;
+// times are formatted with the language's layouts before being substituted.
+var defaultCatalogs = map[string]map[MessageKey]string{
+	LanguageEnglish: {
+		msgDateTimeLayout:          "January 2, 2006 at 3:04 PM",
+		msgClockLayout:             "3:04 PM",
+		MsgAppointmentConfirmation: "Appointment Confirmed: Your appointment with Dr. %s is scheduled for %s. Appointment ID: %d",
+		MsgAppointmentReminder:     "Appointment Reminder: You have an appointment with Dr. %s in %d minutes. Please arrive 15 minutes early. Appointment ID: %d",
+		MsgAppointmentCancellation: "Appointment Cancelled: Your appointment with Dr. %s scheduled for %s has been cancelled. Reason: %s. Please contact us to reschedule. Appointment ID: %d",
+		MsgAppointmentReschedule:   "Appointment Rescheduled: Your appointment with Dr. %s has been moved from %s to %s. New Appointment ID: %d",
+		MsgAutoReschedule:          "Automatic Reschedule: Due to a scheduling conflict, your appointment with Dr. %s has been automatically moved from %s to %s. If this time doesn't work, please contact us. Appointment ID: %d",
+		MsgWaitlistOffer:           "Slot Available: An appointment on %s has opened up. Accept it before %s using code %s.",
+		MsgDueReminder:             "Appointment Reminder: You have an appointment on %s. Please arrive 15 minutes early. Appointment ID: %d",
+		MsgDoctorNewAppointment:    "New Appointment: You have a new appointment scheduled for %s with Patient ID: %d. Appointment ID: %d",
+		MsgDoctorCancellation:      "Appointment Cancelled: The appointment scheduled for %s with Patient ID: %d has been cancelled. Reason: %s. Appointment ID: %d",
+		MsgSuggestedTimes:          "Suggested times: %s.",
+	},
+	LanguageFrench: {
+		msgDateTimeLayout:          "02/01/2006 à 15h04",
+		msgClockLayout:             "15h04",
+		MsgAppointmentConfirmation: "Rendez-vous confirmé : votre rendez-vous avec le Dr %s est prévu le %s. Numéro de rendez-vous : %d",
+		MsgAppointmentReminder:     "Rappel de rendez-vous : vous avez rendez-vous avec le Dr %s dans %d minutes. Merci d'arriver 15 minutes en avance. Numéro de rendez-vous : %d",
+		MsgAppointmentCancellation: "Rendez-vous annulé : votre rendez-vous avec le Dr %s prévu le %s a été annulé. Motif : %s. Contactez-nous pour le reprogrammer. Numéro de rendez-vous : %d",
+		MsgAppointmentReschedule:   "Rendez-vous déplacé : votre rendez-vous avec le Dr %s a été déplacé du %s au %s. Nouveau numéro de rendez-vous : %d",
+		MsgAutoReschedule:          "Report automatique : en raison d'un conflit d'agenda, votre rendez-vous avec le Dr %s a été déplacé du %s au %s. Si cet horaire ne vous convient pas, contactez-nous. Numéro de rendez-vous : %d",
+		MsgWaitlistOffer:           "Créneau disponible : un rendez-vous le %s vient de se libérer. Acceptez-le avant %s avec le code %s.",
+		MsgDueReminder:             "Rappel de rendez-vous : vous avez rendez-vous le %s. Merci d'arriver 15 minutes en avance. Numéro de rendez-vous : %d",
+		MsgSuggestedTimes:          "Créneaux proposés : %s.",
+	},
+}
+
+// Localizer renders notification templates in a recipient's language, falling back to English
+// for unknown languages and for keys a catalog does not translate
+type Localizer struct {
+	catalogs map[string]map[MessageKey]string
+}
+
+// NewLocalizer creates a localizer over the built-in English and French catalogs
+func NewLocalizer() *Localizer {
+	return &Localizer{catalogs: defaultCatalogs}
+}
+
+// Format renders the template for key in language with the given arguments
+func (l *Localizer) Format(language string, key MessageKey, args ...interface{}) string {
+	return fmt.Sprintf(l.template(language, key), args...)
+}
+
+// FormatTime formats a date and time the way language writes it
+func (l *Localizer) FormatTime(language string, t time.Time) string {
+	return t.Format(l.template(language, msgDateTimeLayout))
+}
+
+// FormatClock formats a time of day the way language writes it
+func (l *Localizer) FormatClock(language string, t time.Time) string {
+	return t.Format(l.template(language, msgClockLayout))
+}
+
+// Languages returns the languages that have a message catalog, sorted
+func (l *Localizer) Languages() []string {
+	languages := make([]string, 0, len(l.catalogs))
+	for language := range l.catalogs {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// template looks up key in the language's catalog, then in English
+func (l *Localizer) template(language string, key MessageKey) string {
+	if catalog, ok := l.catalogs[baseLanguage(language)]; ok {
+		if template, ok := catalog[key]; ok {
+			return template
+		}
+	}
+	return l.catalogs[LanguageEnglish][key]
+}
+
+// baseLanguage reduces a language tag such as "fr-CA" to its primary subtag
+func baseLanguage(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if i := strings.IndexAny(language, "-_"); i >= 0 {
+		language = language[:i]
+	}
+	return language
+}
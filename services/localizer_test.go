@@ -0,0 +1,73 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/repository/repotest"
+	"smart-doctor-booking-app/utils"
+)
+
+// captureMessages records every entry logged through the global logger until the test ends
+func captureMessages(t *testing.T) *test.Hook {
+	t.Helper()
+	logger := utils.GetLogger()
+	hooks := make(logrus.LevelHooks)
+	for level, levelHooks := range logger.Hooks {
+		hooks[level] = append(hooks[level], levelHooks...)
+	}
+	t.Cleanup(func() { logger.ReplaceHooks(hooks) })
+
+	hook := &test.Hook{}
+	logger.AddHook(hook)
+	return hook
+}
+
+func TestConfirmationUsesPatientLanguage(t *testing.T) {
+	db := repotest.Open(t)
+	french := &models.User{ID: 1, Username: "amelie", Email: "amelie@example.com", PasswordHash: "hash"}
+	french.NotificationPreferences.Language = "fr-CA"
+	repotest.MustCreate(t, db, french, &models.User{ID: 2, Username: "grace", Email: "grace@example.com", PasswordHash: "hash"})
+	service := NewLocalizedNotificationService(repository.NewUserRepository(db), NewLocalizer())
+	hook := captureMessages(t)
+
+	at := time.Date(2031, 3, 3, 14, 30, 0, 0, time.UTC)
+	tests := []struct {
+		userID uint
+		want   string
+	}{
+		{1, "Rendez-vous confirmé : votre rendez-vous avec le Dr Doctor Name est prévu le 03/03/2031 à 14h30. Numéro de rendez-vous : 7"},
+		{2, "Appointment Confirmed: Your appointment with Dr. Doctor Name is scheduled for March 3, 2031 at 2:30 PM. Appointment ID: 7"},
+	}
+
+	for _, tt := range tests {
+		hook.Reset()
+		if err := service.SendAppointmentConfirmation(&models.Appointment{ID: 7, UserID: tt.userID, AppointmentTime: at}); err != nil {
+			t.Fatalf("SendAppointmentConfirmation returned error: %v", err)
+		}
+		entry := hook.LastEntry()
+		if entry == nil {
+			t.Fatalf("expected a confirmation to be logged for user %d", tt.userID)
+		}
+		if got := entry.Data["message"]; got != tt.want {
+			t.Errorf("user %d: expected %q, got %q", tt.userID, tt.want, got)
+		}
+	}
+}
+
+func TestLocalizerFallsBackToEnglish(t *testing.T) {
+	localizer := NewLocalizer()
+	// The French catalog has no doctor templates
+	if got := localizer.Format(LanguageFrench, MsgDoctorCancellation, "today", 1, "ill", 2); !strings.HasPrefix(got, "Appointment Cancelled:") {
+		t.Errorf("expected a missing French key to fall back to English, got %q", got)
+	}
+	if got := localizer.Format("de", MsgAppointmentConfirmation, "Doctor Name", "today", 1); !strings.HasPrefix(got, "Appointment Confirmed:") {
+		t.Errorf("expected an unknown language to fall back to English, got %q", got)
+	}
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLoginGuardDoublesLockoutUpToMax(t *testing.T) {
+	ctx := context.Background()
+	guard := NewLoginGuard(newTestMemoryCache(), LoginGuardConfig{MaxFailures: 1, BaseLockout: time.Minute, MaxLockout: 3 * time.Minute})
+
+	if err := guard.RecordFailure(ctx, "Grace", "10.0.0.1"); err != nil {
+		t.Fatalf("expected the first failure to be allowed, got %v", err)
+	}
+	for _, want := range []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute} {
+		var locked *LoginLockedError
+		if err := guard.RecordFailure(ctx, "grace", "10.0.0.1"); !errors.As(err, &locked) {
+			t.Fatalf("expected a lockout, got %v", err)
+		} else if locked.RetryAfter != want {
+			t.Errorf("expected a %v lockout, got %v", want, locked.RetryAfter)
+		}
+	}
+	if err := guard.Check(ctx, "GRACE", "10.0.0.1"); err == nil {
+		t.Error("expected the username to be locked out whatever its case")
+	}
+
+	guard.Reset(ctx, "grace", "10.0.0.1")
+	if err := guard.Check(ctx, "grace", "10.0.0.1"); err != nil {
+		t.Errorf("expected no lockout after a reset, got %v", err)
+	}
+}
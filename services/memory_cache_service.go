@@ -0,0 +1,302 @@
+package services
+
+import (
+	"context"
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"smart-doctor-booking-app/models"
+)
+
+// memoryCacheSweepInterval is how often expired entries are evicted from the in-memory cache
+const memoryCacheSweepInterval = time.Minute
+
+// memoryEntry is a value held by the in-memory cache. A zero expiresAt never expires.
+type memoryEntry struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// memoryCacheService implements CacheService in process memory for local development and small
+// single-instance deployments without Redis. Locks and counters only hold within this process.
+type memoryCacheService struct {
+	mu         sync.Mutex
+	entries    map[string]memoryEntry
+	logger     *logrus.Logger
+	defaultTTL time.Duration
+	ttlJitter  float64
+}
+
+// NewMemoryCacheService creates an in-memory cache service that evicts expired entries in the background
+func NewMemoryCacheService(config CacheConfig, logger *logrus.Logger) CacheService {
+	c := &memoryCacheService{
+		entries:    make(map[string]memoryEntry),
+		logger:     logger,
+		defaultTTL: config.DefaultTTL,
+		ttlJitter:  config.TTLJitterPercent / 100,
+	}
+
+	go func() {
+		ticker := time.NewTicker(memoryCacheSweepInterval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			c.evictExpired(now)
+		}
+	}()
+
+	return c
+}
+
+// evictExpired drops every entry that has expired by now
+func (c *memoryCacheService) evictExpired(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		if entry.expired(now) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// lookup returns the live entry at key, evicting it if it has expired. The caller holds mu.
+func (c *memoryCacheService) lookup(key string) (memoryEntry, bool) {
+	entry, ok := c.entries[key]
+	if !ok {
+		return memoryEntry{}, false
+	}
+	if entry.expired(time.Now()) {
+		delete(c.entries, key)
+		return memoryEntry{}, false
+	}
+	return entry, true
+}
+
+// store saves data at key for expiration; a non-positive expiration never expires. The caller holds mu.
+func (c *memoryCacheService) store(key string, data []byte, expiration time.Duration) {
+	entry := memoryEntry{data: data}
+	if expiration > 0 {
+		entry.expiresAt = time.Now().Add(expiration)
+	}
+	c.entries[key] = entry
+}
+
+// jitteredTTL spreads an expiration uniformly within ±ttlJitter of its value, like the Redis cache
+func (c *memoryCacheService) jitteredTTL(expiration time.Duration) time.Duration {
+	if expiration <= 0 || c.ttlJitter <= 0 {
+		return expiration
+	}
+
+	factor := 1 + c.ttlJitter*(2*rand.Float64()-1)
+	return time.Duration(float64(expiration) * factor)
+}
+
+// Set stores a value in cache with expiration
+func (c *memoryCacheService) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		c.logger.Error("Failed to marshal cache value", "key", key, "error", err)
+		return fmt.Errorf("failed to marshal cache value: %w", err)
+	}
+
+	expiration = c.jitteredTTL(expiration)
+	c.mu.Lock()
+	c.store(key, data, expiration)
+	c.mu.Unlock()
+
+	c.logger.Debug("Cache value set successfully", "key", key, "expiration", expiration)
+	return nil
+}
+
+// Get retrieves a value from cache
+func (c *memoryCacheService) Get(ctx context.Context, key string, dest interface{}) error {
+	c.mu.Lock()
+	entry, ok := c.lookup(key)
+	c.mu.Unlock()
+	if !ok {
+		c.logger.Debug("Cache miss", "key", key)
+		return fmt.Errorf("cache miss for key: %s", key)
+	}
+
+	if err := json.Unmarshal(entry.data, dest); err != nil {
+		c.logger.Error("Failed to unmarshal cache value", "key", key, "error", err)
+		return fmt.Errorf("failed to unmarshal cache value: %w", err)
+	}
+
+	c.logger.Debug("Cache hit", "key", key)
+	return nil
+}
+
+// Delete removes a value from cache
+func (c *memoryCacheService) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	delete(c.entries, key)
+	c.mu.Unlock()
+
+	c.logger.Debug("Cache value deleted", "key", key)
+	return nil
+}
+
+// Exists checks if a key exists in cache
+func (c *memoryCacheService) Exists(ctx context.Context, key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.lookup(key)
+	return ok
+}
+
+// Flush clears all cache entries
+func (c *memoryCacheService) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	c.entries = make(map[string]memoryEntry)
+	c.mu.Unlock()
+
+	c.logger.Info("Cache flushed successfully")
+	return nil
+}
+
+// SetSpecialties caches all specialties
+func (c *memoryCacheService) SetSpecialties(ctx context.Context, specialties []models.Specialty) error {
+	return c.Set(ctx, "specialties:all", specialties, c.defaultTTL)
+}
+
+// GetSpecialties retrieves cached specialties
+func (c *memoryCacheService) GetSpecialties(ctx context.Context) ([]models.Specialty, error) {
+	var specialties []models.Specialty
+	if err := c.Get(ctx, "specialties:all", &specialties); err != nil {
+		return nil, err
+	}
+	return specialties, nil
+}
+
+// SetDoctor caches a doctor profile
+func (c *memoryCacheService) SetDoctor(ctx context.Context, doctor *models.Doctor) error {
+	return c.Set(ctx, fmt.Sprintf("doctor:%d", doctor.ID), doctor, c.defaultTTL)
+}
+
+// GetDoctor retrieves a cached doctor profile
+func (c *memoryCacheService) GetDoctor(ctx context.Context, doctorID uint) (*models.Doctor, error) {
+	var doctor models.Doctor
+	if err := c.Get(ctx, fmt.Sprintf("doctor:%d", doctorID), &doctor); err != nil {
+		return nil, err
+	}
+	return &doctor, nil
+}
+
+// SetDoctorsBySpecialty caches doctors by specialty
+func (c *memoryCacheService) SetDoctorsBySpecialty(ctx context.Context, specialtyID uint, doctors []models.Doctor) error {
+	return c.Set(ctx, fmt.Sprintf("doctors:specialty:%d", specialtyID), doctors, c.defaultTTL)
+}
+
+// GetDoctorsBySpecialty retrieves cached doctors by specialty
+func (c *memoryCacheService) GetDoctorsBySpecialty(ctx context.Context, specialtyID uint) ([]models.Doctor, error) {
+	var doctors []models.Doctor
+	if err := c.Get(ctx, fmt.Sprintf("doctors:specialty:%d", specialtyID), &doctors); err != nil {
+		return nil, err
+	}
+	return doctors, nil
+}
+
+// InvalidateDoctorCache removes the doctor's profile and every specialty-based doctor list
+func (c *memoryCacheService) InvalidateDoctorCache(ctx context.Context, doctorID uint) error {
+	c.mu.Lock()
+	delete(c.entries, fmt.Sprintf("doctor:%d", doctorID))
+	for key := range c.entries {
+		if strings.HasPrefix(key, "doctors:specialty:") {
+			delete(c.entries, key)
+		}
+	}
+	c.mu.Unlock()
+
+	c.logger.Info("Doctor cache invalidated", "doctorID", doctorID)
+	return nil
+}
+
+// SetAppointment caches a single appointment
+func (c *memoryCacheService) SetAppointment(ctx context.Context, appointment *models.Appointment) error {
+	return c.Set(ctx, fmt.Sprintf("appointment:%d", appointment.ID), appointment, c.defaultTTL)
+}
+
+// GetAppointment retrieves a cached appointment
+func (c *memoryCacheService) GetAppointment(ctx context.Context, appointmentID uint) (*models.Appointment, error) {
+	var appointment models.Appointment
+	if err := c.Get(ctx, fmt.Sprintf("appointment:%d", appointmentID), &appointment); err != nil {
+		return nil, err
+	}
+	return &appointment, nil
+}
+
+// InvalidateAppointmentCache removes a cached appointment
+func (c *memoryCacheService) InvalidateAppointmentCache(ctx context.Context, appointmentID uint) error {
+	return c.Delete(ctx, fmt.Sprintf("appointment:%d", appointmentID))
+}
+
+// AcquireLock takes the lock at key for ttl if no live holder has it, returning the token needed
+// to release it
+func (c *memoryCacheService) AcquireLock(ctx context.Context, key string, ttl time.Duration) (string, bool, error) {
+	b := make([]byte, 16)
+	if _, err := cryptorand.Read(b); err != nil {
+		return "", false, fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	token := hex.EncodeToString(b)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, held := c.lookup(key); held {
+		return token, false, nil
+	}
+	c.store(key, []byte(token), ttl)
+	return token, true, nil
+}
+
+// ReleaseLock releases the lock at key if it is still held with token
+func (c *memoryCacheService) ReleaseLock(ctx context.Context, key, token string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, held := c.lookup(key); held && string(entry.data) == token {
+		delete(c.entries, key)
+	}
+	return nil
+}
+
+// IncrementCounter increments the counter at key and returns its new value. The key expires ttl
+// after it is first created.
+func (c *memoryCacheService) IncrementCounter(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.lookup(key)
+	if !ok {
+		c.store(key, []byte("1"), ttl)
+		return 1, nil
+	}
+
+	count, err := strconv.ParseInt(string(entry.data), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment counter: value at %s is not an integer", key)
+	}
+	count++
+	entry.data = []byte(strconv.FormatInt(count, 10))
+	c.entries[key] = entry
+	return count, nil
+}
+
+// HealthCheck always succeeds; the in-memory cache has nothing to connect to
+func (c *memoryCacheService) HealthCheck(ctx context.Context) error {
+	return nil
+}
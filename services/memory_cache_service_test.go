@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"smart-doctor-booking-app/models"
+)
+
+// newTestMemoryCache returns an in-memory cache without TTL jitter that logs nowhere
+func newTestMemoryCache() *memoryCacheService {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewMemoryCacheService(CacheConfig{DefaultTTL: time.Hour}, logger).(*memoryCacheService)
+}
+
+func TestMemoryCacheSetGetDelete(t *testing.T) {
+	ctx := context.Background()
+	cache := newTestMemoryCache()
+
+	doctor := &models.Doctor{ID: 4, Name: "Dr. Osei", SpecialtyID: 2}
+	if err := cache.Set(ctx, "doctor:4", doctor, time.Minute); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+
+	var got models.Doctor
+	if err := cache.Get(ctx, "doctor:4", &got); err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if got.ID != 4 || got.Name != "Dr. Osei" || got.SpecialtyID != 2 {
+		t.Errorf("expected the stored doctor back, got %+v", got)
+	}
+	if !cache.Exists(ctx, "doctor:4") {
+		t.Error("expected the key to exist after Set")
+	}
+
+	if err := cache.Delete(ctx, "doctor:4"); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if err := cache.Get(ctx, "doctor:4", &got); err == nil {
+		t.Error("expected a miss after Delete")
+	}
+	if cache.Exists(ctx, "doctor:4") {
+		t.Error("expected the key to be gone after Delete")
+	}
+}
+
+func TestMemoryCacheExpiresEntries(t *testing.T) {
+	ctx := context.Background()
+	cache := newTestMemoryCache()
+
+	if err := cache.Set(ctx, "short", "value", 20*time.Millisecond); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+	if err := cache.Set(ctx, "forever", "value", 0); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	var value string
+	if err := cache.Get(ctx, "short", &value); err == nil {
+		t.Error("expected an expired entry to miss")
+	}
+	if err := cache.Get(ctx, "forever", &value); err != nil || value != "value" {
+		t.Errorf("expected an entry without expiry to stay, got %q (%v)", value, err)
+	}
+
+	// Expired entries nobody reads again are swept
+	if err := cache.Set(ctx, "unread", "value", 20*time.Millisecond); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+	cache.evictExpired(time.Now().Add(time.Second))
+	cache.mu.Lock()
+	_, unread := cache.entries["unread"]
+	remaining := len(cache.entries)
+	cache.mu.Unlock()
+	if unread || remaining != 1 {
+		t.Errorf("expected only the entry without expiry left after a sweep, got %d entries", remaining)
+	}
+}
+
+func TestMemoryCacheCountersAndLocks(t *testing.T) {
+	ctx := context.Background()
+	cache := newTestMemoryCache()
+
+	for want := int64(1); want <= 3; want++ {
+		count, err := cache.IncrementCounter(ctx, "attempts", time.Minute)
+		if err != nil {
+			t.Fatalf("IncrementCounter returned error: %v", err)
+		}
+		if count != want {
+			t.Errorf("expected count %d, got %d", want, count)
+		}
+	}
+
+	token, acquired, err := cache.AcquireLock(ctx, "lock:slots", time.Minute)
+	if err != nil || !acquired {
+		t.Fatalf("expected the lock to be acquired, got %v (%v)", acquired, err)
+	}
+	if _, acquired, _ := cache.AcquireLock(ctx, "lock:slots", time.Minute); acquired {
+		t.Error("expected a held lock not to be acquired again")
+	}
+	if err := cache.ReleaseLock(ctx, "lock:slots", "someone-else"); err != nil {
+		t.Fatalf("ReleaseLock returned error: %v", err)
+	}
+	if !cache.Exists(ctx, "lock:slots") {
+		t.Error("expected a release with the wrong token to keep the lock")
+	}
+	if err := cache.ReleaseLock(ctx, "lock:slots", token); err != nil {
+		t.Fatalf("ReleaseLock returned error: %v", err)
+	}
+	if _, acquired, _ := cache.AcquireLock(ctx, "lock:slots", time.Minute); !acquired {
+		t.Error("expected the lock to be free after its holder released it")
+	}
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"fmt"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/utils"
+)
+
+// NotificationChannel delivers a message to a patient over a single medium
+type NotificationChannel interface {
+	// Type returns the reminder type this channel delivers
+	Type() models.ReminderType
+	// Send delivers the message for the given appointment, returning an error on delivery failure
+	Send(appointment *models.Appointment, message string) error
+}
+
+// logChannel is a placeholder channel that logs messages instead of delivering them
+type logChannel struct {
+	channelType models.ReminderType
+	// phoneRegion is set for phone-based channels, which require a valid contact phone
+	phoneRegion string
+}
+
+// NewSMSChannel creates the SMS notification channel. Numbers without a country code
+// are interpreted using defaultRegion.
+func NewSMSChannel(defaultRegion string) NotificationChannel {
+	return &logChannel{channelType: models.ReminderSMS, phoneRegion: defaultRegion}
+}
+
+// NewEmailChannel creates the email notification channel
+func NewEmailChannel() NotificationChannel {
+	return &logChannel{channelType: models.ReminderEmail}
+}
+
+// NewPushChannel creates the push notification channel
+func NewPushChannel() NotificationChannel {
+	return &logChannel{channelType: models.ReminderPush}
+}
+
+// NewVoiceChannel creates the voice-call notification channel used for escalations
+func NewVoiceChannel(defaultRegion string) NotificationChannel {
+	return &logChannel{channelType: models.ReminderVoice, phoneRegion: defaultRegion}
+}
+
+// Type returns the reminder type this channel delivers
+func (ch *logChannel) Type() models.ReminderType {
+	return ch.channelType
+}
+
+// Send logs the message in place of delivering it
+func (ch *logChannel) Send(appointment *models.Appointment, message string) error {
+	if appointment == nil {
+		return fmt.Errorf("appointment cannot be nil")
+	}
+
+	fields := map[string]interface{}{
+		"patient_id":     appointment.UserID,
+		"appointment_id": appointment.ID,
+		"channel":        ch.channelType,
+		"message":        message,
+	}
+
+	if ch.phoneRegion != "" {
+		if appointment.ContactPhone == "" {
+			return fmt.Errorf("no contact phone for appointment %d", appointment.ID)
+		}
+		phone, err := utils.NormalizePhone(appointment.ContactPhone, ch.phoneRegion)
+		if err != nil {
+			return err
+		}
+		fields["phone"] = phone
+	}
+
+	utils.LogInfo(fmt.Sprintf("Sending %s to Patient", ch.channelType), fields)
+
+	// TODO: Integrate the real provider for this channel
+	// - SMS: Twilio, AWS SNS
+	// - Email: SendGrid, AWS SES
+	// - Push: Firebase Cloud Messaging
+	// - Voice: Twilio Programmable Voice
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/repository/repotest"
+	"smart-doctor-booking-app/utils"
+)
+
+// signallingLogRepository stores logs and signals each one, since confirmations are recorded after
+// booking returns
+type signallingLogRepository struct {
+	repository.NotificationLogRepository
+	created chan struct{}
+}
+
+func (r *signallingLogRepository) CreateLog(log *models.NotificationLog) error {
+	defer func() { r.created <- struct{}{} }()
+	return r.NotificationLogRepository.CreateLog(log)
+}
+
+func TestBookingConfirmationLogCarriesRequestID(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestSchedulingService(db, DefaultSchedulingConfig())
+	logRepo := &signallingLogRepository{NotificationLogRepository: repository.NewNotificationLogRepository(db), created: make(chan struct{}, 1)}
+	service.SetNotificationLog(logRepo)
+	day := repotest.Day(0)
+	seedDoctors(t, db, 1)
+	repotest.MustCreate(t, db, repotest.Slot(1, day, 9, 0, 30, models.SlotAvailable))
+
+	// The request is over before the confirmation goes out; its values must outlive it
+	ctx, cancel := context.WithCancel(utils.ContextWithRequestID(context.Background(), "req-booking-42"))
+	appointment, err := service.BookAppointment(&BookingRequest{
+		UserID: 1, DoctorID: 1, AppointmentTime: day.Add(9 * time.Hour), Duration: 30,
+		AppointmentType: models.TypeConsultation, Context: ctx,
+	})
+	cancel()
+	if err != nil {
+		t.Fatalf("BookAppointment returned error: %v", err)
+	}
+
+	select {
+	case <-logRepo.created:
+	case <-time.After(time.Second):
+		t.Fatal("expected the confirmation to be logged")
+	}
+
+	logs, err := logRepo.GetLogsByAppointment(appointment.ID)
+	if err != nil {
+		t.Fatalf("GetLogsByAppointment returned error: %v", err)
+	}
+	if len(logs) != 1 {
+		t.Fatalf("expected 1 notification log, got %d", len(logs))
+	}
+	if logs[0].RequestID != "req-booking-42" || logs[0].Kind != models.NotificationConfirmation || logs[0].Status != models.NotificationSent {
+		t.Errorf("expected a sent confirmation tagged req-booking-42, got %+v", logs[0])
+	}
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"sync"
+)
+
+// DefaultNotificationConcurrency is the default cap on outbound notifications in flight at once
+const DefaultNotificationConcurrency = 20
+
+// notificationSlots is a process-wide semaphore that every outbound notification send acquires,
+// so bursts of bookings cannot overwhelm SMS, email or push providers
+var (
+	notificationSlotsMu sync.RWMutex
+	notificationSlots   = make(chan struct{}, DefaultNotificationConcurrency)
+)
+
+// SetNotificationConcurrency sets how many outbound notifications may be sent at once.
+// It is meant to be called during startup; sends already in flight release their old slot.
+func SetNotificationConcurrency(limit int) {
+	if limit < 1 {
+		limit = 1
+	}
+
+	notificationSlotsMu.Lock()
+	notificationSlots = make(chan struct{}, limit)
+	notificationSlotsMu.Unlock()
+}
+
+// acquireNotificationSlot blocks until a send may proceed and returns the function that releases it
+func acquireNotificationSlot() func() {
+	notificationSlotsMu.RLock()
+	slots := notificationSlots
+	notificationSlotsMu.RUnlock()
+
+	slots <- struct{}{}
+	return func() { <-slots }
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/repository/repotest"
+)
+
+// slowNotificationService holds each confirmation until unblocked and records the most sends in
+// flight at once
+type slowNotificationService struct {
+	NotificationService
+	unblock     chan struct{}
+	inFlight    int32
+	maxInFlight int32
+	done        sync.WaitGroup
+}
+
+func (s *slowNotificationService) SendAppointmentConfirmation(appointment *models.Appointment) error {
+	defer s.done.Done()
+	current := atomic.AddInt32(&s.inFlight, 1)
+	defer atomic.AddInt32(&s.inFlight, -1)
+
+	for {
+		seen := atomic.LoadInt32(&s.maxInFlight)
+		if current <= seen || atomic.CompareAndSwapInt32(&s.maxInFlight, seen, current) {
+			break
+		}
+	}
+	<-s.unblock
+	time.Sleep(time.Millisecond)
+	return nil
+}
+
+func TestNotificationSendsStayWithinConcurrencyLimit(t *testing.T) {
+	const limit, bookings = 3, 16
+	SetNotificationConcurrency(limit)
+	defer SetNotificationConcurrency(DefaultNotificationConcurrency)
+
+	db := repotest.Open(t)
+	notifications := &slowNotificationService{NotificationService: NewNotificationService(), unblock: make(chan struct{})}
+	service := NewSchedulingServiceWithConfig(
+		repository.NewAppointmentRepository(db),
+		repository.NewTimeSlotRepository(db),
+		notifications,
+		nil,
+		DefaultSchedulingConfig(),
+	)
+	day := repotest.Day(0)
+	seedDoctors(t, db, 1)
+	for i := 0; i < bookings; i++ {
+		repotest.MustCreate(t, db, repotest.Slot(1, day, 8+i/2, (i%2)*30, 30, models.SlotAvailable))
+	}
+
+	notifications.done.Add(bookings)
+	for i := 0; i < bookings; i++ {
+		_, err := service.BookAppointment(&BookingRequest{
+			UserID: uint(i + 1), DoctorID: 1, AppointmentTime: day.Add(time.Duration(8*60+i*30) * time.Minute), Duration: 30,
+			AppointmentType: models.TypeConsultation,
+		})
+		if err != nil {
+			t.Fatalf("booking %d returned error: %v", i, err)
+		}
+	}
+	close(notifications.unblock)
+	notifications.done.Wait()
+
+	if peak := atomic.LoadInt32(&notifications.maxInFlight); peak > limit {
+		t.Errorf("expected at most %d notifications in flight, got %d", limit, peak)
+	} else if peak < 2 {
+		t.Errorf("expected notifications to be sent concurrently, got a peak of %d", peak)
+	}
+}
@@ -2,9 +2,11 @@ package services
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
 	"smart-doctor-booking-app/utils"
 )
 
@@ -13,9 +15,10 @@ type NotificationService interface {
 	// Appointment Notifications
 	SendAppointmentConfirmation(appointment *models.Appointment) error
 	SendAppointmentReminder(appointment *models.Appointment) error
-	SendAppointmentCancellation(appointment *models.Appointment, reason string) error
+	SendAppointmentCancellation(appointment *models.Appointment, reason string, alternatives []models.TimeSlot) error
 	SendAppointmentReschedule(oldAppointment, newAppointment *models.Appointment) error
 	SendAutoRescheduleNotification(appointment *models.Appointment, newTime time.Time) error
+	SendWaitlistOffer(offer *models.WaitlistOffer) error
 
 	// Doctor Notifications
 	SendDoctorAppointmentNotification(appointment *models.Appointment) error
@@ -37,11 +40,35 @@ type notificationService struct {
 	// - Email service client (SendGrid, AWS SES, etc.)
 	// - Push notification service (Firebase, etc.)
 	// - Database for notification logs
+	userRepo  repository.UserRepository
+	localizer *Localizer
 }
 
-// NewNotificationService creates a new notification service
+// NewNotificationService creates a new notification service that writes every message in English
 func NewNotificationService() NotificationService {
-	return &notificationService{}
+	return &notificationService{localizer: NewLocalizer()}
+}
+
+// NewLocalizedNotificationService creates a notification service that writes patient messages in
+// each patient's preferred language, looked up through userRepo
+func NewLocalizedNotificationService(userRepo repository.UserRepository, localizer *Localizer) NotificationService {
+	return &notificationService{
+		userRepo:  userRepo,
+		localizer: localizer,
+	}
+}
+
+// languageFor returns the patient's preferred language, or English when it is unknown
+func (s *notificationService) languageFor(userID uint) string {
+	if s.userRepo == nil {
+		return LanguageEnglish
+	}
+
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil || user.NotificationPreferences.Language == "" {
+		return LanguageEnglish
+	}
+	return user.NotificationPreferences.Language
 }
 
 // Appointment Notifications
@@ -53,10 +80,10 @@ func (s *notificationService) SendAppointmentConfirmation(appointment *models.Ap
 	}
 
 	// Placeholder implementation - logs the notification
-	message := fmt.Sprintf(
-		"Appointment Confirmed: Your appointment with Dr. %s is scheduled for %s. Appointment ID: %d",
+	language := s.languageFor(appointment.UserID)
+	message := s.localizer.Format(language, MsgAppointmentConfirmation,
 		"Doctor Name", // In real implementation, fetch doctor name
-		appointment.AppointmentTime.Format("January 2, 2006 at 3:04 PM"),
+		s.localizer.FormatTime(language, appointment.AppointmentTime),
 		appointment.ID,
 	)
 
@@ -82,8 +109,7 @@ func (s *notificationService) SendAppointmentReminder(appointment *models.Appoin
 		return fmt.Errorf("appointment cannot be nil")
 	}
 
-	message := fmt.Sprintf(
-		"Appointment Reminder: You have an appointment with Dr. %s in %d minutes. Please arrive 15 minutes early. Appointment ID: %d",
+	message := s.localizer.Format(s.languageFor(appointment.UserID), MsgAppointmentReminder,
 		"Doctor Name", // In real implementation, fetch doctor name
 		appointment.ReminderTime,
 		appointment.ID,
@@ -113,25 +139,34 @@ func (s *notificationService) SendAppointmentReminder(appointment *models.Appoin
 	return nil
 }
 
-// SendAppointmentCancellation sends a cancellation notification to the patient
-func (s *notificationService) SendAppointmentCancellation(appointment *models.Appointment, reason string) error {
+// SendAppointmentCancellation sends a cancellation notification to the patient, listing any
+// alternative slots they could rebook
+func (s *notificationService) SendAppointmentCancellation(appointment *models.Appointment, reason string, alternatives []models.TimeSlot) error {
 	if appointment == nil {
 		return fmt.Errorf("appointment cannot be nil")
 	}
 
-	message := fmt.Sprintf(
-		"Appointment Cancelled: Your appointment with Dr. %s scheduled for %s has been cancelled. Reason: %s. Please contact us to reschedule. Appointment ID: %d",
+	language := s.languageFor(appointment.UserID)
+	message := s.localizer.Format(language, MsgAppointmentCancellation,
 		"Doctor Name", // In real implementation, fetch doctor name
-		appointment.AppointmentTime.Format("January 2, 2006 at 3:04 PM"),
+		s.localizer.FormatTime(language, appointment.AppointmentTime),
 		reason,
 		appointment.ID,
 	)
+	if len(alternatives) > 0 {
+		times := make([]string, len(alternatives))
+		for i, slot := range alternatives {
+			times[i] = s.localizer.FormatTime(language, slot.StartTime)
+		}
+		message += " " + s.localizer.Format(language, MsgSuggestedTimes, strings.Join(times, "; "))
+	}
 
 	utils.LogInfo("Sending SMS to Patient about Appointment Cancellation", map[string]interface{}{
 		"patient_id":        appointment.UserID,
 		"appointment_id":    appointment.ID,
 		"message":           message,
 		"reason":            reason,
+		"alternatives":      len(alternatives),
 		"notification_type": "appointment_cancellation",
 	})
 
@@ -147,11 +182,11 @@ func (s *notificationService) SendAppointmentReschedule(oldAppointment, newAppoi
 		return fmt.Errorf("appointments cannot be nil")
 	}
 
-	message := fmt.Sprintf(
-		"Appointment Rescheduled: Your appointment with Dr. %s has been moved from %s to %s. New Appointment ID: %d",
+	language := s.languageFor(newAppointment.UserID)
+	message := s.localizer.Format(language, MsgAppointmentReschedule,
 		"Doctor Name", // In real implementation, fetch doctor name
-		oldAppointment.AppointmentTime.Format("January 2, 2006 at 3:04 PM"),
-		newAppointment.AppointmentTime.Format("January 2, 2006 at 3:04 PM"),
+		s.localizer.FormatTime(language, oldAppointment.AppointmentTime),
+		s.localizer.FormatTime(language, newAppointment.AppointmentTime),
 		newAppointment.ID,
 	)
 
@@ -176,11 +211,11 @@ func (s *notificationService) SendAutoRescheduleNotification(appointment *models
 		return fmt.Errorf("appointment cannot be nil")
 	}
 
-	message := fmt.Sprintf(
-		"Automatic Reschedule: Due to a scheduling conflict, your appointment with Dr. %s has been automatically moved from %s to %s. If this time doesn't work, please contact us. Appointment ID: %d",
+	language := s.languageFor(appointment.UserID)
+	message := s.localizer.Format(language, MsgAutoReschedule,
 		"Doctor Name", // In real implementation, fetch doctor name
-		appointment.AppointmentTime.Format("January 2, 2006 at 3:04 PM"),
-		newTime.Format("January 2, 2006 at 3:04 PM"),
+		s.localizer.FormatTime(language, appointment.AppointmentTime),
+		s.localizer.FormatTime(language, newTime),
 		appointment.ID,
 	)
 
@@ -199,6 +234,33 @@ func (s *notificationService) SendAutoRescheduleNotification(appointment *models
 	return nil
 }
 
+// SendWaitlistOffer tells a waitlisted patient that a slot opened and how to claim it
+func (s *notificationService) SendWaitlistOffer(offer *models.WaitlistOffer) error {
+	if offer == nil {
+		return fmt.Errorf("waitlist offer cannot be nil")
+	}
+
+	language := s.languageFor(offer.UserID)
+	message := s.localizer.Format(language, MsgWaitlistOffer,
+		s.localizer.FormatTime(language, offer.StartTime),
+		s.localizer.FormatClock(language, offer.ExpiresAt),
+		offer.Token,
+	)
+
+	utils.LogInfo("Sending SMS to Patient about Waitlist Offer", map[string]interface{}{
+		"patient_id":        offer.UserID,
+		"doctor_id":         offer.DoctorID,
+		"offer_id":          offer.ID,
+		"message":           message,
+		"notification_type": "waitlist_offer",
+	})
+
+	// TODO: Implement actual waitlist offer notification
+	// Priority: High (the offer expires quickly)
+
+	return nil
+}
+
 // Doctor Notifications
 
 // SendDoctorAppointmentNotification sends a new appointment notification to the doctor
@@ -207,9 +269,9 @@ func (s *notificationService) SendDoctorAppointmentNotification(appointment *mod
 		return fmt.Errorf("appointment cannot be nil")
 	}
 
-	message := fmt.Sprintf(
-		"New Appointment: You have a new appointment scheduled for %s with Patient ID: %d. Appointment ID: %d",
-		appointment.AppointmentTime.Format("January 2, 2006 at 3:04 PM"),
+	// Doctors have no language preference, so their notifications stay in English
+	message := s.localizer.Format(LanguageEnglish, MsgDoctorNewAppointment,
+		s.localizer.FormatTime(LanguageEnglish, appointment.AppointmentTime),
 		appointment.UserID,
 		appointment.ID,
 	)
@@ -234,9 +296,8 @@ func (s *notificationService) SendDoctorCancellationNotification(appointment *mo
 		return fmt.Errorf("appointment cannot be nil")
 	}
 
-	message := fmt.Sprintf(
-		"Appointment Cancelled: The appointment scheduled for %s with Patient ID: %d has been cancelled. Reason: %s. Appointment ID: %d",
-		appointment.AppointmentTime.Format("January 2, 2006 at 3:04 PM"),
+	message := s.localizer.Format(LanguageEnglish, MsgDoctorCancellation,
+		s.localizer.FormatTime(LanguageEnglish, appointment.AppointmentTime),
 		appointment.UserID,
 		reason,
 		appointment.ID,
@@ -316,15 +377,15 @@ func (s *notificationService) ScheduleReminder(appointment *models.Appointment)
 	return nil
 }
 
-// CancelReminder cancels a scheduled reminder
+// CancelReminder cancels a scheduled reminder. Reminders delivered by the ReminderDispatcher need
+// nothing more: it only sends reminders of scheduled and confirmed appointments.
 func (s *notificationService) CancelReminder(appointmentID uint) error {
 	utils.LogInfo("Cancelling Appointment Reminder", map[string]interface{}{
 		"appointment_id":    appointmentID,
 		"notification_type": "cancel_reminder",
 	})
 
-	// TODO: Implement actual reminder cancellation
-	// Remove from scheduler or mark as cancelled in database
+	// TODO: Remove the reminder from an external scheduler once ScheduleReminder uses one
 
 	return nil
 }
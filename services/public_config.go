@@ -0,0 +1,59 @@
+package services
+
+import (
+	"smart-doctor-booking-app/models"
+)
+
+// Booking limits enforced by request validation, published so clients can build forms without hardcoding them
+const (
+	MinAppointmentDuration = 15   // minutes
+	MaxAppointmentDuration = 180  // minutes
+	MinReminderTime        = 5    // minutes before the appointment
+	MaxReminderTime        = 1440 // minutes before the appointment
+)
+
+// DurationLimits is the allowed appointment length range in minutes
+type DurationLimits struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// PublicConfig is the non-sensitive subset of configuration that clients need to build booking UIs.
+// It never carries credentials, secrets or connection settings.
+type PublicConfig struct {
+	Duration                         DurationLimits                            `json:"duration"`
+	AppointmentTypes                 map[models.AppointmentType]DurationLimits `json:"appointment_types"`
+	ReminderChannels                 []models.ReminderType                     `json:"reminder_channels"`
+	ReminderTime                     DurationLimits                            `json:"reminder_time"`
+	DefaultReminderType              models.ReminderType                       `json:"default_reminder_type"`
+	DefaultReminderTime              int                                       `json:"default_reminder_time"`
+	MaxActiveAppointments            int                                       `json:"max_active_appointments"` // 0 means unlimited
+	LateCancellationWindowMinutes    int                                       `json:"late_cancellation_window_minutes"`
+	MaxDoctorsPerAvailabilityRequest int                                       `json:"max_doctors_per_availability_request"`
+	Languages                        []string                                  `json:"languages"`
+}
+
+// NewPublicConfig builds the public configuration from the scheduling configuration
+func NewPublicConfig(config SchedulingConfig, localizer *Localizer) PublicConfig {
+	types := make(map[models.AppointmentType]DurationLimits)
+	for _, appointmentType := range []models.AppointmentType{
+		models.TypeConsultation, models.TypeFollowUp, models.TypeCheckup, models.TypeEmergency,
+	} {
+		minMinutes, maxMinutes := appointmentType.DurationLimits()
+		types[appointmentType] = DurationLimits{Min: minMinutes, Max: maxMinutes}
+	}
+
+	return PublicConfig{
+		Duration:         DurationLimits{Min: MinAppointmentDuration, Max: MaxAppointmentDuration},
+		AppointmentTypes: types,
+		// Voice calls are an escalation path, not a channel patients choose
+		ReminderChannels:                 []models.ReminderType{models.ReminderSMS, models.ReminderEmail, models.ReminderPush},
+		ReminderTime:                     DurationLimits{Min: MinReminderTime, Max: MaxReminderTime},
+		DefaultReminderType:              config.DefaultReminderType,
+		DefaultReminderTime:              config.DefaultReminderTime,
+		MaxActiveAppointments:            config.MaxActiveAppointments,
+		LateCancellationWindowMinutes:    int(config.LateCancellationWindow.Minutes()),
+		MaxDoctorsPerAvailabilityRequest: MaxDoctorsPerAvailabilityRequest,
+		Languages:                        localizer.Languages(),
+	}
+}
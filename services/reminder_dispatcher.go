@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/utils"
+)
+
+// EscalationPolicy decides which appointment types escalate to a voice call
+// when every regular reminder channel fails
+type EscalationPolicy struct {
+	VoiceEscalationTypes map[models.AppointmentType]bool
+}
+
+// DefaultEscalationPolicy escalates only emergency appointments
+func DefaultEscalationPolicy() EscalationPolicy {
+	return EscalationPolicy{
+		VoiceEscalationTypes: map[models.AppointmentType]bool{
+			models.TypeEmergency: true,
+		},
+	}
+}
+
+// ShouldEscalate reports whether reminders for this appointment type escalate to voice
+func (p EscalationPolicy) ShouldEscalate(appointmentType models.AppointmentType) bool {
+	return p.VoiceEscalationTypes[appointmentType]
+}
+
+// ReminderDispatcher periodically sends due appointment reminders, falling back across
+// channels and escalating to a voice call according to the escalation policy
+type ReminderDispatcher struct {
+	appointmentRepo repository.AppointmentRepository
+	logRepo         repository.NotificationLogRepository
+	userRepo        repository.UserRepository
+	cacheService    CacheService
+	channels        map[models.ReminderType]NotificationChannel
+	policy          EscalationPolicy
+	localizer       *Localizer
+}
+
+// NewReminderDispatcher creates a reminder dispatcher over the given channels.
+// userRepo may be nil, in which case every channel is allowed and quiet hours are not applied.
+// cacheService may be nil; when set, cached appointments are invalidated once their reminder is sent.
+func NewReminderDispatcher(
+	appointmentRepo repository.AppointmentRepository,
+	logRepo repository.NotificationLogRepository,
+	userRepo repository.UserRepository,
+	cacheService CacheService,
+	channels []NotificationChannel,
+	policy EscalationPolicy,
+) *ReminderDispatcher {
+	channelMap := make(map[models.ReminderType]NotificationChannel, len(channels))
+	for _, channel := range channels {
+		channelMap[channel.Type()] = channel
+	}
+
+	return &ReminderDispatcher{
+		appointmentRepo: appointmentRepo,
+		logRepo:         logRepo,
+		userRepo:        userRepo,
+		cacheService:    cacheService,
+		channels:        channelMap,
+		policy:          policy,
+		localizer:       NewLocalizer(),
+	}
+}
+
+// Start runs the dispatcher every interval until the context is cancelled
+func (d *ReminderDispatcher) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if _, err := d.DispatchDueReminders(now); err != nil {
+					utils.LogError(err, "Reminder dispatch cycle failed", nil)
+				}
+			}
+		}
+	}()
+}
+
+// DispatchDueReminders sends every reminder due at now and returns how many were delivered
+func (d *ReminderDispatcher) DispatchDueReminders(now time.Time) (int, error) {
+	appointments, err := d.appointmentRepo.GetDueReminders(now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get due reminders: %w", err)
+	}
+
+	delivered := 0
+	for i := range appointments {
+		appointment := &appointments[i]
+		if !d.stillDue(appointment) {
+			continue
+		}
+
+		preferences := d.preferencesFor(appointment.UserID)
+		if preferences.InQuietHours(now) {
+			// Leave the reminder pending; it is retried on the next cycle after quiet hours
+			continue
+		}
+
+		if err := d.dispatch(appointment, preferences); err != nil {
+			utils.LogError(err, "Failed to deliver appointment reminder", map[string]interface{}{
+				"appointment_id": appointment.ID,
+				"user_id":        appointment.UserID,
+			})
+			continue
+		}
+
+		if err := d.appointmentRepo.MarkReminderSent(appointment.ID, now); err != nil {
+			utils.LogError(err, "Failed to mark reminder as sent", map[string]interface{}{
+				"appointment_id": appointment.ID,
+			})
+			continue
+		}
+		if d.cacheService != nil {
+			_ = d.cacheService.InvalidateAppointmentCache(context.Background(), appointment.ID)
+		}
+		delivered++
+	}
+
+	return delivered, nil
+}
+
+// stillDue re-reads the appointment just before sending, so an appointment cancelled, moved or
+// already reminded since the cycle started is skipped
+func (d *ReminderDispatcher) stillDue(appointment *models.Appointment) bool {
+	current, err := d.appointmentRepo.GetAppointmentByID(appointment.ID)
+	if err != nil {
+		// Skipped reminders stay pending and are retried on the next cycle
+		utils.LogWarn("Skipping reminder for appointment that could not be reloaded", map[string]interface{}{
+			"appointment_id": appointment.ID,
+			"error":          err.Error(),
+		})
+		return false
+	}
+
+	switch current.Status {
+	case models.StatusScheduled, models.StatusConfirmed:
+	default:
+		return false
+	}
+	return current.ReminderEnabled && !current.ReminderSent
+}
+
+// preferencesFor returns the patient's notification preferences, defaulting to every channel
+// enabled when the patient has no stored profile
+func (d *ReminderDispatcher) preferencesFor(userID uint) models.NotificationPreferences {
+	if d.userRepo == nil {
+		return models.DefaultNotificationPreferences()
+	}
+
+	user, err := d.userRepo.GetUserByID(userID)
+	if err != nil {
+		return models.DefaultNotificationPreferences()
+	}
+	return user.NotificationPreferences
+}
+
+// dispatch tries the appointment's preferred channel, then SMS and email as fallbacks, skipping
+// channels the patient opted out of, and finally a voice call if the escalation policy allows it
+func (d *ReminderDispatcher) dispatch(appointment *models.Appointment, preferences models.NotificationPreferences) error {
+	message := d.localizer.Format(preferences.Language, MsgDueReminder,
+		d.localizer.FormatTime(preferences.Language, appointment.AppointmentTime),
+		appointment.ID,
+	)
+
+	for _, channelType := range d.channelOrder(appointment.ReminderType) {
+		if !preferences.Allows(channelType) {
+			continue
+		}
+		if d.send(appointment, channelType, message, false) {
+			return nil
+		}
+	}
+
+	if d.policy.ShouldEscalate(appointment.Type) {
+		utils.LogWarn("All reminder channels failed, escalating to voice call", map[string]interface{}{
+			"appointment_id":   appointment.ID,
+			"appointment_type": appointment.Type,
+		})
+		if d.send(appointment, models.ReminderVoice, message, true) {
+			return nil
+		}
+	}
+
+	return errors.New("all reminder channels failed")
+}
+
+// channelOrder returns the preferred channel followed by the SMS and email fallbacks
+func (d *ReminderDispatcher) channelOrder(preferred models.ReminderType) []models.ReminderType {
+	if preferred == "" || preferred == models.ReminderVoice {
+		preferred = models.ReminderSMS
+	}
+
+	order := []models.ReminderType{preferred}
+	for _, fallback := range []models.ReminderType{models.ReminderSMS, models.ReminderEmail} {
+		if fallback != preferred {
+			order = append(order, fallback)
+		}
+	}
+	return order
+}
+
+// send delivers over one channel and records the attempt in the notification log
+func (d *ReminderDispatcher) send(appointment *models.Appointment, channelType models.ReminderType, message string, escalated bool) bool {
+	channel, exists := d.channels[channelType]
+	if !exists {
+		return false
+	}
+
+	entry := &models.NotificationLog{
+		AppointmentID: appointment.ID,
+		UserID:        appointment.UserID,
+		Kind:          models.NotificationReminder,
+		Channel:       channelType,
+		Status:        models.NotificationSent,
+		Escalated:     escalated,
+	}
+
+	release := acquireNotificationSlot()
+	sendErr := channel.Send(appointment, message)
+	release()
+	if sendErr != nil {
+		entry.Status = models.NotificationFailed
+		entry.Error = sendErr.Error()
+	}
+
+	if err := d.logRepo.CreateLog(entry); err != nil {
+		utils.LogError(err, "Failed to record notification log", map[string]interface{}{
+			"appointment_id": appointment.ID,
+			"channel":        channelType,
+		})
+	}
+
+	return sendErr == nil
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/repository/repotest"
+	"smart-doctor-booking-app/utils"
+)
+
+// dueReminderRepository applies the due-reminder filter in Go, since the repository's query uses
+// Postgres interval arithmetic the test database does not support
+type dueReminderRepository struct {
+	repository.AppointmentRepository
+	db *gorm.DB
+}
+
+func (r *dueReminderRepository) GetDueReminders(now time.Time) ([]models.Appointment, error) {
+	var candidates []models.Appointment
+	if err := r.db.Where("status IN (?, ?) AND reminder_enabled = ? AND reminder_sent = ?",
+		models.StatusScheduled, models.StatusConfirmed, true, false).
+		Order("appointment_time ASC").Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	var due []models.Appointment
+	for _, appointment := range candidates {
+		remindAt := appointment.AppointmentTime.Add(-time.Duration(appointment.ReminderTime) * time.Minute)
+		if appointment.AppointmentTime.After(now) && !remindAt.After(now) {
+			due = append(due, appointment)
+		}
+	}
+	return due, nil
+}
+
+// fakeChannel records the appointments it was asked to deliver and fails when err is set
+type fakeChannel struct {
+	channelType models.ReminderType
+	err         error
+	sent        []uint
+}
+
+func (ch *fakeChannel) Type() models.ReminderType { return ch.channelType }
+
+func (ch *fakeChannel) Send(appointment *models.Appointment, message string) error {
+	ch.sent = append(ch.sent, appointment.ID)
+	return ch.err
+}
+
+// newTestReminderDispatcher returns a dispatcher over db without a user repository or cache
+func newTestReminderDispatcher(db *gorm.DB, channels ...NotificationChannel) *ReminderDispatcher {
+	return NewReminderDispatcher(
+		&dueReminderRepository{AppointmentRepository: repository.NewAppointmentRepository(db), db: db},
+		repository.NewNotificationLogRepository(db),
+		nil,
+		nil,
+		channels,
+		DefaultEscalationPolicy(),
+	)
+}
+
+func TestDispatchEscalatesToVoiceWhenSMSAndEmailFail(t *testing.T) {
+	db := repotest.Open(t)
+	sms := &fakeChannel{channelType: models.ReminderSMS, err: errors.New("sms gateway down")}
+	email := &fakeChannel{channelType: models.ReminderEmail, err: errors.New("smtp refused")}
+	voice := &fakeChannel{channelType: models.ReminderVoice}
+	dispatcher := newTestReminderDispatcher(db, sms, email, voice)
+
+	start := repotest.Day(0).Add(10 * time.Hour)
+	appointment := repotest.Appointment(1, 1, start, 30, models.StatusScheduled)
+	appointment.Type = models.TypeEmergency
+	appointment.ReminderEnabled = true
+	appointment.ReminderType = models.ReminderSMS
+	appointment.ReminderTime = 60
+	repotest.MustCreate(t, db, appointment)
+
+	delivered, err := dispatcher.DispatchDueReminders(start.Add(-30 * time.Minute))
+	if err != nil {
+		t.Fatalf("DispatchDueReminders returned error: %v", err)
+	}
+	if delivered != 1 {
+		t.Fatalf("expected 1 delivered reminder, got %d", delivered)
+	}
+	if len(sms.sent) != 1 || len(email.sent) != 1 || len(voice.sent) != 1 {
+		t.Errorf("expected one attempt per channel, got sms=%d email=%d voice=%d", len(sms.sent), len(email.sent), len(voice.sent))
+	}
+
+	logs, err := repository.NewNotificationLogRepository(db).GetLogsByAppointment(appointment.ID)
+	if err != nil {
+		t.Fatalf("GetLogsByAppointment returned error: %v", err)
+	}
+	if len(logs) != 3 {
+		t.Fatalf("expected 3 logged attempts, got %d", len(logs))
+	}
+	last := logs[2]
+	if last.Channel != models.ReminderVoice || !last.Escalated || last.Status != models.NotificationSent {
+		t.Errorf("expected a sent, escalated voice attempt last, got %s escalated=%v %s", last.Channel, last.Escalated, last.Status)
+	}
+	for _, entry := range logs[:2] {
+		if entry.Status != models.NotificationFailed {
+			t.Errorf("expected the %s attempt to be logged as failed, got %s", entry.Channel, entry.Status)
+		}
+	}
+}
+
+func TestDispatchDoesNotEscalateRoutineAppointments(t *testing.T) {
+	db := repotest.Open(t)
+	sms := &fakeChannel{channelType: models.ReminderSMS, err: errors.New("sms gateway down")}
+	email := &fakeChannel{channelType: models.ReminderEmail, err: errors.New("smtp refused")}
+	voice := &fakeChannel{channelType: models.ReminderVoice}
+	dispatcher := newTestReminderDispatcher(db, sms, email, voice)
+
+	start := repotest.Day(0).Add(10 * time.Hour)
+	appointment := repotest.Appointment(1, 1, start, 30, models.StatusScheduled)
+	appointment.ReminderEnabled = true
+	appointment.ReminderTime = 60
+	repotest.MustCreate(t, db, appointment)
+
+	delivered, err := dispatcher.DispatchDueReminders(start.Add(-30 * time.Minute))
+	if err != nil {
+		t.Fatalf("DispatchDueReminders returned error: %v", err)
+	}
+	if delivered != 0 || len(voice.sent) != 0 {
+		t.Errorf("expected no delivery and no voice call for a consultation, got %d delivered and %d calls", delivered, len(voice.sent))
+	}
+}
+
+func TestDispatchSkipsOptedOutChannel(t *testing.T) {
+	db := repotest.Open(t)
+	sms := &fakeChannel{channelType: models.ReminderSMS}
+	email := &fakeChannel{channelType: models.ReminderEmail}
+	userRepo := repository.NewUserRepository(db)
+	dispatcher := NewReminderDispatcher(
+		&dueReminderRepository{AppointmentRepository: repository.NewAppointmentRepository(db), db: db},
+		repository.NewNotificationLogRepository(db),
+		userRepo,
+		nil,
+		[]NotificationChannel{sms, email},
+		DefaultEscalationPolicy(),
+	)
+
+	repotest.MustCreate(t, db, &models.User{ID: 1, Username: "patient", PasswordHash: "hash"})
+	preferences := models.DefaultNotificationPreferences()
+	preferences.SMS = false
+	if err := userRepo.UpdateNotificationPreferences(1, preferences); err != nil {
+		t.Fatalf("UpdateNotificationPreferences returned error: %v", err)
+	}
+
+	start := repotest.Day(0).Add(10 * time.Hour)
+	appointment := repotest.Appointment(1, 1, start, 30, models.StatusScheduled)
+	appointment.ReminderEnabled = true
+	appointment.ReminderType = models.ReminderSMS
+	appointment.ReminderTime = 60
+	repotest.MustCreate(t, db, appointment)
+
+	delivered, err := dispatcher.DispatchDueReminders(start.Add(-30 * time.Minute))
+	if err != nil {
+		t.Fatalf("DispatchDueReminders returned error: %v", err)
+	}
+	if delivered != 1 {
+		t.Fatalf("expected 1 delivered reminder, got %d", delivered)
+	}
+	if len(sms.sent) != 0 {
+		t.Errorf("expected no SMS for a patient who opted out, got %d", len(sms.sent))
+	}
+	if len(email.sent) != 1 {
+		t.Errorf("expected the reminder by email instead, got %d", len(email.sent))
+	}
+}
+
+func TestOverriddenReminderIsDispatchedAtNewOffset(t *testing.T) {
+	db := repotest.Open(t)
+	sms := &fakeChannel{channelType: models.ReminderSMS}
+	email := &fakeChannel{channelType: models.ReminderEmail}
+	dispatcher := newTestReminderDispatcher(db, sms, email)
+	service := newTestSchedulingService(db, DefaultSchedulingConfig())
+
+	start := repotest.Day(0).Add(10 * time.Hour)
+	appointment := repotest.Appointment(1, 1, start, 30, models.StatusScheduled)
+	appointment.ReminderType = models.ReminderSMS
+	repotest.MustCreate(t, db, appointment)
+	ninetyMinutesBefore := start.Add(-90 * time.Minute)
+
+	if delivered, err := dispatcher.DispatchDueReminders(ninetyMinutesBefore); err != nil || delivered != 0 {
+		t.Fatalf("expected no reminder 90 minutes ahead with the default offset, got %d (%v)", delivered, err)
+	}
+
+	offset := 120
+	updated, err := service.OverrideReminder(appointment.ID, ReminderOverride{Type: models.ReminderEmail, OffsetMinutes: &offset})
+	if err != nil {
+		t.Fatalf("OverrideReminder returned error: %v", err)
+	}
+	if updated.ReminderTime != 120 || updated.ReminderType != models.ReminderEmail {
+		t.Errorf("expected an email reminder 120 minutes ahead, got %s %d minutes ahead", updated.ReminderType, updated.ReminderTime)
+	}
+
+	delivered, err := dispatcher.DispatchDueReminders(ninetyMinutesBefore)
+	if err != nil {
+		t.Fatalf("DispatchDueReminders returned error: %v", err)
+	}
+	if delivered != 1 || len(email.sent) != 1 || len(sms.sent) != 0 {
+		t.Errorf("expected the reminder sent by email at the new offset, got delivered=%d email=%d sms=%d", delivered, len(email.sent), len(sms.sent))
+	}
+
+	tooEarly := 2000
+	if _, err := service.OverrideReminder(appointment.ID, ReminderOverride{OffsetMinutes: &tooEarly}); !errors.Is(err, utils.ErrInvalidInput) {
+		t.Errorf("expected an out-of-range offset to be rejected, got %v", err)
+	}
+	if _, err := service.OverrideReminder(appointment.ID, ReminderOverride{Type: "FAX"}); !errors.Is(err, utils.ErrInvalidInput) {
+		t.Errorf("expected an unknown reminder type to be rejected, got %v", err)
+	}
+}
+
+// staleDueRepository returns the reminders that were due when the cycle started, as if the
+// appointment changed while the dispatcher was working through them
+type staleDueRepository struct {
+	repository.AppointmentRepository
+	due []models.Appointment
+}
+
+func (r *staleDueRepository) GetDueReminders(now time.Time) ([]models.Appointment, error) {
+	return r.due, nil
+}
+
+// reminderCancellationRecorder records the appointments whose reminders were cancelled
+type reminderCancellationRecorder struct {
+	NotificationService
+	cancelled []uint
+}
+
+func (n *reminderCancellationRecorder) CancelReminder(appointmentID uint) error {
+	n.cancelled = append(n.cancelled, appointmentID)
+	return nil
+}
+
+func TestCancelledAppointmentReminderIsNotDispatched(t *testing.T) {
+	db := repotest.Open(t)
+	sms := &fakeChannel{channelType: models.ReminderSMS}
+	notifications := &reminderCancellationRecorder{NotificationService: NewNotificationService()}
+	service := NewSchedulingServiceWithConfig(
+		repository.NewAppointmentRepository(db),
+		repository.NewTimeSlotRepository(db),
+		notifications,
+		nil,
+		DefaultSchedulingConfig(),
+	)
+	seedDoctors(t, db, 1)
+
+	start := repotest.Day(0).Add(10 * time.Hour)
+	cancelled := repotest.Appointment(1, 1, start, 30, models.StatusScheduled)
+	cancelled.ReminderType = models.ReminderSMS
+	kept := repotest.Appointment(2, 1, start.Add(time.Hour), 30, models.StatusScheduled)
+	kept.ReminderType = models.ReminderSMS
+	kept.ReminderTime = 120
+	repotest.MustCreate(t, db, cancelled, kept)
+	remindAt := start.Add(-30 * time.Minute)
+
+	// The cycle has already listed both reminders when the patient cancels
+	due, err := (&dueReminderRepository{AppointmentRepository: repository.NewAppointmentRepository(db), db: db}).GetDueReminders(remindAt)
+	if err != nil {
+		t.Fatalf("GetDueReminders returned error: %v", err)
+	}
+	if len(due) != 2 {
+		t.Fatalf("expected both reminders due, got %d", len(due))
+	}
+	if err := service.CancelAppointment(context.Background(), cancelled.ID, "patient", models.CancellationPatientRequest, ""); err != nil {
+		t.Fatalf("CancelAppointment returned error: %v", err)
+	}
+	if len(notifications.cancelled) != 1 || notifications.cancelled[0] != cancelled.ID {
+		t.Errorf("expected the reminder of appointment %d cancelled, got %v", cancelled.ID, notifications.cancelled)
+	}
+
+	dispatcher := NewReminderDispatcher(
+		&staleDueRepository{AppointmentRepository: repository.NewAppointmentRepository(db), due: due},
+		repository.NewNotificationLogRepository(db),
+		nil,
+		nil,
+		[]NotificationChannel{sms},
+		DefaultEscalationPolicy(),
+	)
+	delivered, err := dispatcher.DispatchDueReminders(remindAt)
+	if err != nil {
+		t.Fatalf("DispatchDueReminders returned error: %v", err)
+	}
+	if delivered != 1 || len(sms.sent) != 1 || sms.sent[0] != kept.ID {
+		t.Errorf("expected only appointment %d reminded, got delivered=%d sent=%v", kept.ID, delivered, sms.sent)
+	}
+
+	// Later cycles no longer find the cancelled appointment at all
+	if delivered, err := newTestReminderDispatcher(db, sms).DispatchDueReminders(remindAt); err != nil || delivered != 0 {
+		t.Errorf("expected nothing left to dispatch, got %d (%v)", delivered, err)
+	}
+}
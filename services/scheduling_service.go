@@ -1,8 +1,11 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"math"
+	"sort"
 	"time"
 
 	"smart-doctor-booking-app/models"
@@ -13,34 +16,241 @@ import (
 // SchedulingService interface defines methods for smart appointment scheduling
 type SchedulingService interface {
 	// Core Scheduling Operations
+	GetAppointment(appointmentID uint) (*models.Appointment, error)
 	BookAppointment(request *BookingRequest) (*models.Appointment, error)
-	CancelAppointment(appointmentID uint, cancelledBy, reason string) error
-	RescheduleAppointment(appointmentID uint, newStartTime, newEndTime time.Time) (*models.Appointment, error)
+	CancelAppointment(ctx context.Context, appointmentID uint, cancelledBy string, reason models.CancellationReason, detail string) error
+	ConfirmDeposit(ctx context.Context, appointmentID uint) (*models.Appointment, error)
+	ConfirmAppointment(appointmentID uint, confirmedBy string) (*models.Appointment, error)
+	ConfirmAppointments(appointmentIDs []uint, confirmedBy string) []models.ConfirmationResult
+	ResendConfirmation(ctx context.Context, appointmentID uint) error
+	ReleaseExpiredHolds() (int, error)
+	RescheduleAppointment(ctx context.Context, appointmentID uint, newStartTime, newEndTime time.Time) (*models.Appointment, error)
+	ShiftAppointments(ctx context.Context, doctorID uint, windowStart, windowEnd time.Time, offset time.Duration) ([]models.ShiftResult, error)
+	ChangeAppointmentType(appointmentID uint, newType models.AppointmentType, changedBy string) (*models.Appointment, error)
+	OverrideReminder(appointmentID uint, override ReminderOverride) (*models.Appointment, error)
+	SetAppointmentTags(appointmentID uint, tags []string) (*models.Appointment, error)
 
 	// Availability Management
 	GetDoctorAvailability(doctorID uint, date time.Time) (*models.AvailabilityResponse, error)
-	GetDoctorAvailabilityRange(doctorID uint, startDate, endDate time.Time) (map[string]*models.AvailabilityResponse, error)
+	GetDoctorAvailabilityRange(doctorID uint, startDate, endDate time.Time) (map[string]*models.AvailabilityResponse, []string, error)
+	GetMultiDoctorAvailability(doctorIDs []uint, date time.Time) (map[uint]*models.AvailabilityResponse, error)
 	CheckTimeSlotAvailability(doctorID uint, startTime, endTime time.Time) (bool, error)
+	GetAlternativeDoctors(specialtyID uint, startTime, endTime time.Time) ([]models.Doctor, error)
+	GetDoctorSlots(doctorID uint, date time.Time, status models.SlotStatus) ([]models.TimeSlot, error)
+	WarmDoctorAvailability(doctorID uint, from time.Time, days int) error
+	InvalidateDoctorAvailability(doctorID uint, from, to time.Time)
+	FilterByTimeOfDay(availability *models.AvailabilityResponse, timeOfDay TimeOfDay) *models.AvailabilityResponse
+	FilterByLocation(availability *models.AvailabilityResponse, locationID uint) *models.AvailabilityResponse
+	GetDoctorAvailabilityAllLocations(doctorID uint, date time.Time) (*models.LocatedAvailability, error)
+	GetPatientCommitments(userID uint, from, to time.Time) ([]models.Appointment, error)
+	FilterPatientConflicts(availability *models.AvailabilityResponse, commitments []models.Appointment) *models.AvailabilityResponse
 
 	// Patient Operations
 	GetPatientAppointments(userID uint, status string) ([]models.Appointment, error)
-	GetUpcomingAppointments(userID uint) ([]models.Appointment, error)
+	GetReviewEligibleAppointments(userID uint) ([]models.Appointment, error)
+	SubmitReview(review *models.Review) error
+	GetAppointmentHistory(doctorID, userID uint, before time.Time) ([]models.Appointment, error)
+	GetRescheduleChain(appointmentID uint) ([]models.Appointment, error)
+	GetUpcomingAppointments(userID uint, limit int) ([]models.Appointment, error)
+	GetUpcomingAppointmentsForUsers(userIDs []uint) (map[uint][]models.Appointment, error)
+	GetAppointmentChanges(userID *uint, since time.Time, limit int) ([]models.AppointmentChange, error)
+	GetAttendanceStats(userID uint) (*models.AttendanceStats, error)
+
+	// Reporting
+	GetSpecialtyStats(from, to time.Time) ([]models.SpecialtyStats, error)
+	GetAISpecialtyComparison(from, to time.Time) ([]models.AISpecialtyComparison, error)
+	ClassifyAppointment(appointmentID uint) (*models.Appointment, error)
+	ResolveClassification(requestID string, specialtyID uint, confidence float64) (*models.Appointment, error)
+	GetCancellationStats(from, to time.Time) ([]models.CancellationReasonStats, error)
 
 	// Doctor Operations
 	GetDoctorAppointments(doctorID uint, date time.Time) ([]models.Appointment, error)
+	GetClinicDaySchedule(doctors []models.Doctor, date time.Time) ([]models.DoctorDaySchedule, error)
 	GetDoctorSchedule(doctorID uint) (*models.DoctorSchedule, error)
 	UpdateDoctorSchedule(schedule *models.DoctorSchedule) error
+	GetScheduleGrid(doctorID uint) ([]models.ScheduleGridDay, error)
+	ValidateSchedule(doctorID uint) ([]models.ScheduleIssue, error)
+	GetScheduleDiscrepancies(doctorID uint, from, to time.Time) ([]models.ScheduleDiscrepancy, error)
+	GetBlockedPeriods(doctorID uint, from, to time.Time) ([]models.BlockedPeriod, error)
+	GetDoctorTimeOff(doctorID uint, now time.Time, days int) ([]models.TimeOffPeriod, error)
+	GetWaitEstimate(doctorID uint, now time.Time) (*models.WaitEstimate, error)
+	GetDurationInsights(doctorID uint) (*models.DurationInsights, error)
+	GetDoctorReliability(doctorID uint, from, to time.Time) ([]models.DoctorReliabilityMonth, error)
+	ForecastGenerateSlots(doctorID uint, startDate time.Time, days, lookbackWeeks int) ([]models.ForecastWindow, error)
+	GetBookableWindows(doctorID uint, startDate, endDate time.Time, duration int) ([]models.DayBookableWindows, error)
+	FindBestSlot(request *BestSlotRequest) (*models.SlotChoice, error)
+	GetDoctorCalendar(doctorID uint, month time.Time) ([]models.CalendarDayCount, error)
 
 	// Conflict Detection and Resolution
 	DetectConflicts(doctorID uint, startTime, endTime time.Time, excludeAppointmentID *uint) ([]models.Appointment, error)
 	SuggestAlternativeSlots(doctorID uint, preferredTime time.Time, duration int) ([]models.TimeSlot, error)
-	AutoRescheduleConflicts(doctorID uint, startTime, endTime time.Time) error
+	AutoRescheduleConflicts(ctx context.Context, doctorID uint, startTime, endTime time.Time) error
 
 	// Time Slot Management
 	GenerateTimeSlots(doctorID uint, date time.Time) error
-	GenerateWeeklySlots(doctorID uint, startDate time.Time) error
+	PreviewTimeSlots(doctorID uint, date time.Time) ([]models.TimeSlot, error)
+	GenerateWeeklySlots(doctorID uint, startDate time.Time) ([]models.DayGenerationResult, error)
+	AddAvailabilityOverride(doctorID uint, startTime, endTime time.Time, slotDuration time.Duration) (int, error)
 	BlockTimeSlots(doctorID uint, startTime, endTime time.Time, reason string) error
 	UnblockTimeSlots(doctorID uint, startTime, endTime time.Time) error
+	DeleteUnbookedSlots(doctorID uint, from, to time.Time) (int64, error)
+	GetOrphanedSlots() ([]models.TimeSlot, error)
+	ReclaimOrphanedSlots() ([]models.TimeSlot, error)
+
+	// Events
+	AddSlotReleaseListener(listener SlotReleaseListener)
+	SetNotificationLog(logRepo repository.NotificationLogRepository)
+	SetSpecialtyClassifier(classifier SpecialtyClassifier)
+	SetLocationRepository(locationRepo repository.LocationRepository)
+}
+
+// SlotReleaseListener is notified when a booked time becomes free again through a cancellation
+// or reschedule. SlotReleased runs on its own goroutine; ctx carries only the values of the
+// request that freed the time.
+type SlotReleaseListener interface {
+	SlotReleased(ctx context.Context, doctorID uint, startTime, endTime time.Time)
+}
+
+// MaxDoctorsPerAvailabilityRequest caps how many doctors can be compared in one availability request
+const MaxDoctorsPerAvailabilityRequest = 10
+
+// MaxBestSlotSearchDays caps how far apart a best-slot search's earliest and latest times can be
+const MaxBestSlotSearchDays = 31
+
+// RescheduleMode controls how appointments are rescheduled
+type RescheduleMode string
+
+const (
+	// RescheduleNewRecord creates a new appointment row and marks the original RESCHEDULED
+	RescheduleNewRecord RescheduleMode = "new_record"
+	// RescheduleInPlace updates the time on the existing appointment row, keeping its ID
+	RescheduleInPlace RescheduleMode = "in_place"
+)
+
+// SchedulingConfig holds scheduling behaviour configuration
+type SchedulingConfig struct {
+	RescheduleMode RescheduleMode
+	// LateCancellationWindow is how close to the appointment a cancellation counts against attendance
+	LateCancellationWindow time.Duration
+	// DepositNoShowThreshold is the no-show rate (percent) above which bookings require a deposit; 0 disables deposits
+	DepositNoShowThreshold float64
+	// DepositMinAppointments is how many tracked appointments a patient needs before the threshold applies
+	DepositMinAppointments int
+	// DepositHoldDuration is how long a booking awaiting a deposit holds its slot
+	DepositHoldDuration time.Duration
+	// DefaultPhoneRegion is the ISO region used to interpret phone numbers without a country code
+	DefaultPhoneRegion string
+	// MaxActiveAppointments caps a patient's upcoming active appointments; 0 disables the limit
+	MaxActiveAppointments int
+	// ConfirmationResendInterval is the minimum time between resent confirmations for one appointment
+	ConfirmationResendInterval time.Duration
+	// DefaultReminderType is the reminder channel used when a booking does not choose one
+	DefaultReminderType models.ReminderType
+	// DefaultReminderTime is how many minutes before the appointment the reminder goes out when a booking does not say
+	DefaultReminderTime int
+	// TimeOfDayBands sets the boundaries used to filter availability to morning, afternoon or evening
+	TimeOfDayBands TimeOfDayBands
+	// AvailabilityCacheTTL is how long warmed availability weeks stay cached; 0 disables availability caching
+	AvailabilityCacheTTL time.Duration
+	// NearlyFullThreshold is the booked share of a day's slots (0-1) at which availability is flagged nearly full
+	NearlyFullThreshold float64
+	// DoctorBookingRateLimit caps booking attempts per doctor per second across all instances; 0 disables the limit.
+	// It needs the cache service and is skipped without one.
+	DoctorBookingRateLimit int
+	// PendingClassificationTTL is how long an asynchronous AI classification waits for its callback
+	PendingClassificationTTL time.Duration
+}
+
+// DefaultSchedulingConfig returns default scheduling configuration
+func DefaultSchedulingConfig() SchedulingConfig {
+	return SchedulingConfig{
+		RescheduleMode:             RescheduleNewRecord,
+		LateCancellationWindow:     24 * time.Hour,
+		DepositNoShowThreshold:     0,
+		DepositMinAppointments:     3,
+		DepositHoldDuration:        15 * time.Minute,
+		DefaultPhoneRegion:         "US",
+		MaxActiveAppointments:      5,
+		ConfirmationResendInterval: 10 * time.Minute,
+		DefaultReminderType:        models.ReminderSMS,
+		DefaultReminderTime:        60,
+		TimeOfDayBands:             DefaultTimeOfDayBands(),
+		NearlyFullThreshold:        0.8,
+		DoctorBookingRateLimit:     10,
+		PendingClassificationTTL:   24 * time.Hour,
+	}
+}
+
+// ErrInvalidCancellationReason is returned when a cancellation uses an unknown reason code
+var ErrInvalidCancellationReason = errors.New("invalid cancellation reason")
+
+// ErrClinicClosed is returned when booking or moving an appointment onto a holiday
+var ErrClinicClosed = errors.New("bookings are closed on this date")
+
+// ErrTimeBlocked is returned when booking or moving an appointment onto a blocked period or break
+var ErrTimeBlocked = errors.New("the doctor is unavailable at this time")
+
+// ErrClassifierUnavailable is returned when the AI specialty classifier is not configured or fails
+var ErrClassifierUnavailable = errors.New("AI specialty classification is unavailable")
+
+// ErrClassificationPending is returned when the AI service will deliver a classification later
+// through the callback endpoint
+var ErrClassificationPending = errors.New("AI specialty classification is pending")
+
+// ErrUnknownClassificationRequest is returned when a classification callback names a request
+// that is not pending, because it was never made, already resolved or has expired
+var ErrUnknownClassificationRequest = errors.New("no pending classification for this request")
+
+// ErrNoSlotFits is returned when no open slot satisfies a best-slot search
+var ErrNoSlotFits = errors.New("no available slot fits the requested constraints")
+
+// ErrDoctorBookingRateLimited is returned when a doctor receives more booking attempts per second
+// than DoctorBookingRateLimit allows
+var ErrDoctorBookingRateLimited = errors.New("too many booking attempts for this doctor, please retry shortly")
+
+// ErrGenerationInProgress is returned when slots for the same doctor and date are already being generated
+var ErrGenerationInProgress = errors.New("slot generation already in progress")
+
+// slotGenerationLockTTL bounds how long a crashed generation can hold its doctor and date locked
+const slotGenerationLockTTL = 30 * time.Second
+
+var (
+	// ErrInvalidAppointmentType is returned when an appointment type is not one of the known types
+	ErrInvalidAppointmentType = errors.New("invalid appointment type")
+	// ErrTypeDurationMismatch is returned when a type change would need a different slot length than was booked
+	ErrTypeDurationMismatch = errors.New("booked duration is outside the new appointment type's limits")
+	// ErrAppointmentNotActive is returned when changing an appointment that is cancelled, completed or moved
+	ErrAppointmentNotActive = errors.New("appointment is no longer active")
+	// ErrAlreadyConfirmed is returned when confirming an appointment that is already confirmed
+	ErrAlreadyConfirmed = errors.New("appointment is already confirmed")
+)
+
+// ReminderOverride changes the reminder of a single appointment. Nil or empty fields keep the
+// appointment's current setting.
+type ReminderOverride struct {
+	Type          models.ReminderType
+	OffsetMinutes *int // Minutes before the appointment, MinReminderTime to MaxReminderTime
+	Enabled       *bool
+}
+
+// ActiveAppointmentLimitError is returned when a patient already holds the maximum number of active appointments
+type ActiveAppointmentLimitError struct {
+	Limit int
+}
+
+// Error implements the error interface
+func (e *ActiveAppointmentLimitError) Error() string {
+	return fmt.Sprintf("patient already has the maximum of %d active appointments", e.Limit)
+}
+
+// ResendThrottledError is returned when a confirmation is resent again too soon
+type ResendThrottledError struct {
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *ResendThrottledError) Error() string {
+	return fmt.Sprintf("confirmation was resent recently; try again in %s", e.RetryAfter.Round(time.Second))
 }
 
 // BookingRequest represents a request to book an appointment
@@ -53,6 +263,27 @@ type BookingRequest struct {
 	Notes           string                 `json:"notes"`
 	ReminderType    models.ReminderType    `json:"reminder_type"`
 	ReminderTime    int                    `json:"reminder_time"` // minutes before appointment
+	ContactPhone    string                 `json:"contact_phone"`
+	LocationID      *uint                  `json:"location_id"` // Optional; defaults to the location of the booked slot
+	Symptom         string                 `json:"symptom"`     // Optional; classified by the AI service after booking
+	Tags            []string               `json:"tags"`
+	// BypassLimits skips per-patient booking limits, for bookings made by admins
+	BypassLimits bool `json:"-"`
+	// InTransaction runs inside the booking's transaction once the appointment is created; an
+	// error from it rolls the booking back
+	InTransaction repository.BookingStep `json:"-"`
+	// Context carries request-scoped values, such as the request ID, into the confirmation sent
+	// after booking. Only its values are used; it may be nil.
+	Context context.Context `json:"-"`
+}
+
+// BestSlotRequest describes the constraints a patient puts on an appointment time
+type BestSlotRequest struct {
+	DoctorID       uint            `json:"doctor_id" validate:"required"`
+	Duration       int             `json:"duration" validate:"required,min=15,max=180"`
+	Earliest       time.Time       `json:"earliest" validate:"required"`
+	Latest         time.Time       `json:"latest" validate:"required"`
+	PreferredTimes []time.Duration `json:"preferred_times"` // Times of day as offsets from midnight in Earliest's timezone
 }
 
 // schedulingService implements SchedulingService
@@ -60,18 +291,46 @@ type schedulingService struct {
 	appointmentRepo repository.AppointmentRepository
 	timeSlotRepo    repository.TimeSlotRepository
 	notificationSvc NotificationService
+	cacheService    CacheService
+	config          SchedulingConfig
+
+	// releaseListeners are registered during setup, before the service handles requests
+	releaseListeners []SlotReleaseListener
+
+	// notificationLog records confirmations sent after booking; nil disables recording
+	notificationLog repository.NotificationLogRepository
+
+	// classifier classifies the symptoms of symptom-based bookings; nil disables classification
+	classifier SpecialtyClassifier
+
+	// locationRepo names the locations slots are held at; nil leaves slots unlabelled
+	locationRepo repository.LocationRepository
 }
 
-// NewSchedulingService creates a new scheduling service
+// NewSchedulingService creates a new scheduling service with default configuration
 func NewSchedulingService(
 	appointmentRepo repository.AppointmentRepository,
 	timeSlotRepo repository.TimeSlotRepository,
 	notificationSvc NotificationService,
+) SchedulingService {
+	return NewSchedulingServiceWithConfig(appointmentRepo, timeSlotRepo, notificationSvc, nil, DefaultSchedulingConfig())
+}
+
+// NewSchedulingServiceWithConfig creates a new scheduling service with the given configuration.
+// cacheService may be nil, in which case appointment reads are not cached.
+func NewSchedulingServiceWithConfig(
+	appointmentRepo repository.AppointmentRepository,
+	timeSlotRepo repository.TimeSlotRepository,
+	notificationSvc NotificationService,
+	cacheService CacheService,
+	config SchedulingConfig,
 ) SchedulingService {
 	return &schedulingService{
 		appointmentRepo: appointmentRepo,
 		timeSlotRepo:    timeSlotRepo,
 		notificationSvc: notificationSvc,
+		cacheService:    cacheService,
+		config:          config,
 	}
 }
 
@@ -88,9 +347,66 @@ func (s *schedulingService) BookAppointment(request *BookingRequest) (*models.Ap
 		return nil, errors.New("appointment time must be in the future")
 	}
 
+	if err := s.checkDoctorBookingRate(request.DoctorID); err != nil {
+		return nil, err
+	}
+
+	accepting, err := s.isAcceptingBookings(request.DoctorID)
+	if err != nil {
+		return nil, err
+	}
+	if !accepting {
+		return nil, models.ErrDoctorNotAcceptingBookings
+	}
+
+	if err := s.checkHoliday(request.DoctorID, request.AppointmentTime); err != nil {
+		return nil, err
+	}
+
+	// Enforce the per-patient active appointment limit
+	if s.config.MaxActiveAppointments > 0 && !request.BypassLimits {
+		active, err := s.appointmentRepo.CountActiveAppointments(request.UserID, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to count active appointments: %w", err)
+		}
+		if active >= s.config.MaxActiveAppointments {
+			return nil, &ActiveAppointmentLimitError{Limit: s.config.MaxActiveAppointments}
+		}
+	}
+
+	// Normalize the contact phone used for SMS and voice reminders
+	contactPhone := ""
+	if request.ContactPhone != "" {
+		normalized, err := utils.NormalizePhone(request.ContactPhone, s.config.DefaultPhoneRegion)
+		if err != nil {
+			return nil, err
+		}
+		contactPhone = normalized
+	}
+
+	tags, err := models.NormalizeTags(request.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrInvalidInput, err)
+	}
+
+	// Apply the clinic's reminder defaults to whatever the booking leaves out
+	reminderType := request.ReminderType
+	if reminderType == "" {
+		reminderType = s.config.DefaultReminderType
+	}
+	reminderTime := request.ReminderTime
+	if reminderTime == 0 {
+		reminderTime = s.config.DefaultReminderTime
+	}
+
 	// Calculate end time
 	endTime := request.AppointmentTime.Add(time.Duration(request.Duration) * time.Minute)
 
+	// Reject times the doctor has blocked off or set aside for a break
+	if err := s.checkBlocked(request.DoctorID, request.AppointmentTime, endTime); err != nil {
+		return nil, err
+	}
+
 	// Check for conflicts
 	conflicts, err := s.appointmentRepo.DetectConflicts(request.DoctorID, request.AppointmentTime, endTime, nil)
 	if err != nil {
@@ -109,236 +425,1799 @@ func (s *schedulingService) BookAppointment(request *BookingRequest) (*models.Ap
 	// Check time slot availability
 	available, err := s.timeSlotRepo.CheckSlotAvailability(request.DoctorID, request.AppointmentTime, endTime)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check slot availability: %w", err)
+		return nil, fmt.Errorf("failed to check slot availability: %w", err)
+	}
+
+	if !available {
+		return nil, errors.New("requested time slot is not available")
+	}
+
+	// Create appointment
+	appointment := &models.Appointment{
+		UserID:          request.UserID,
+		DoctorID:        request.DoctorID,
+		AppointmentTime: request.AppointmentTime,
+		EndTime:         endTime,
+		Duration:        request.Duration,
+		Type:            request.AppointmentType,
+		Status:          models.StatusScheduled,
+		Notes:           request.Notes,
+		ReminderType:    reminderType,
+		ReminderTime:    reminderTime,
+		ContactPhone:    contactPhone,
+		LocationID:      request.LocationID,
+		Symptom:         request.Symptom,
+		Tags:            tags,
+		CreatedAt:       time.Now(),
+	}
+
+	// Hold the booking pending a deposit for patients with poor attendance
+	depositRequired, err := s.requiresDeposit(request.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if depositRequired {
+		holdExpiresAt := time.Now().Add(s.config.DepositHoldDuration)
+		appointment.Status = models.StatusPendingPayment
+		appointment.DepositRequired = true
+		appointment.HoldExpiresAt = &holdExpiresAt
+	}
+
+	// Book the appointment
+	if err := s.appointmentRepo.BookTimeSlot(appointment, request.InTransaction); err != nil {
+		return nil, fmt.Errorf("failed to book appointment: %w", err)
+	}
+	s.invalidateAvailability(request.DoctorID, request.AppointmentTime, endTime)
+
+	if appointment.Symptom != "" && s.classifier != nil {
+		go func() {
+			if _, err := s.classify(appointment); err != nil && !errors.Is(err, ErrClassificationPending) {
+				utils.LogWarn("Failed to classify booking symptom", map[string]interface{}{
+					"appointment_id": appointment.ID,
+					"error":          err.Error(),
+				})
+			}
+		}()
+	}
+
+	if depositRequired {
+		utils.LogInfo("Appointment held pending deposit", map[string]interface{}{
+			"appointment_id":  appointment.ID,
+			"user_id":         request.UserID,
+			"hold_expires_at": appointment.HoldExpiresAt,
+		})
+		return appointment, nil
+	}
+
+	// Send confirmation notification. The request may finish first, so keep only its values.
+	ctx := context.Background()
+	if request.Context != nil {
+		ctx = context.WithoutCancel(request.Context)
+	}
+	go func() {
+		release := acquireNotificationSlot()
+		defer release()
+
+		err := s.notificationSvc.SendAppointmentConfirmation(appointment)
+		if err != nil {
+			utils.LogError(err, "Failed to send appointment confirmation", map[string]interface{}{
+				"appointment_id": appointment.ID,
+				"user_id":        appointment.UserID,
+				"request_id":     utils.RequestIDFromContext(ctx),
+			})
+		}
+		s.recordNotification(ctx, appointment, models.NotificationConfirmation, err)
+	}()
+
+	utils.LogInfo("Appointment booked successfully", map[string]interface{}{
+		"appointment_id":   appointment.ID,
+		"user_id":          request.UserID,
+		"doctor_id":        request.DoctorID,
+		"appointment_time": request.AppointmentTime,
+	})
+
+	return appointment, nil
+}
+
+// checkHoliday returns ErrClinicClosed when a holiday closes the doctor's bookings on the day of t
+func (s *schedulingService) checkHoliday(doctorID uint, t time.Time) error {
+	holiday, err := s.timeSlotRepo.GetHoliday(doctorID, t)
+	if err != nil {
+		return err
+	}
+	if holiday != nil {
+		return fmt.Errorf("%w: %s", ErrClinicClosed, holiday.Name)
+	}
+	return nil
+}
+
+// isAcceptingBookings reports whether the doctor is taking new bookings. A doctor that does not
+// exist is reported as accepting, leaving it to the booking itself to fail.
+func (s *schedulingService) isAcceptingBookings(doctorID uint) (bool, error) {
+	accepting, err := s.appointmentRepo.GetAcceptingBookings([]uint{doctorID})
+	if err != nil {
+		return false, err
+	}
+	if isAccepting, found := accepting[doctorID]; found && !isAccepting {
+		return false, nil
+	}
+	return true, nil
+}
+
+// checkDoctorBookingRate counts a booking attempt against the doctor's per-second window in Redis,
+// so the limit holds across instances, and returns ErrDoctorBookingRateLimited once the window is
+// full. Attempts are let through when the counter cannot be reached.
+func (s *schedulingService) checkDoctorBookingRate(doctorID uint) error {
+	if s.config.DoctorBookingRateLimit <= 0 || s.cacheService == nil {
+		return nil
+	}
+
+	key := fmt.Sprintf("ratelimit:booking:doctor:%d:%d", doctorID, time.Now().Unix())
+	attempts, err := s.cacheService.IncrementCounter(context.Background(), key, 2*time.Second)
+	if err != nil {
+		utils.LogWarn("Doctor booking rate limit unavailable, allowing attempt", map[string]interface{}{
+			"doctor_id": doctorID,
+			"error":     err.Error(),
+		})
+		return nil
+	}
+
+	if attempts > int64(s.config.DoctorBookingRateLimit) {
+		utils.LogWarn("Doctor booking rate limit exceeded", map[string]interface{}{
+			"doctor_id": doctorID,
+			"attempts":  attempts,
+		})
+		return ErrDoctorBookingRateLimited
+	}
+	return nil
+}
+
+// checkBlocked returns ErrTimeBlocked, naming the period and its reason, when [start, end)
+// overlaps one of the doctor's blocked slots, break slots or breaks
+func (s *schedulingService) checkBlocked(doctorID uint, start, end time.Time) error {
+	// Breaks are looked up by date with an exclusive upper bound, so cover every day the
+	// appointment touches through to the following midnight
+	dayStart := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	dayEnd := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, end.Location()).AddDate(0, 0, 1)
+	periods, err := s.GetBlockedPeriods(doctorID, dayStart, dayEnd)
+	if err != nil {
+		return err
+	}
+
+	for _, period := range periods {
+		if period.StartTime.Before(end) && period.EndTime.After(start) {
+			kind := "blocked period"
+			if period.Kind == models.SlotBreak {
+				kind = "break"
+			}
+			reason := ""
+			if period.Reason != "" {
+				reason = " (" + period.Reason + ")"
+			}
+			return fmt.Errorf("%w: overlaps a %s from %s to %s%s", ErrTimeBlocked, kind,
+				period.StartTime.Format("15:04"), period.EndTime.Format("15:04"), reason)
+		}
+	}
+	return nil
+}
+
+// requiresDeposit reports whether the patient's no-show rate exceeds the deposit threshold
+func (s *schedulingService) requiresDeposit(userID uint) (bool, error) {
+	if s.config.DepositNoShowThreshold <= 0 {
+		return false, nil
+	}
+
+	stats, err := s.appointmentRepo.GetAttendanceStats(userID, s.config.LateCancellationWindow)
+	if err != nil {
+		return false, fmt.Errorf("failed to get attendance stats: %w", err)
+	}
+
+	return stats.TotalTracked >= s.config.DepositMinAppointments && stats.NoShowRate > s.config.DepositNoShowThreshold, nil
+}
+
+// ConfirmDeposit commits a booking held pending payment and sends its confirmation
+func (s *schedulingService) ConfirmDeposit(ctx context.Context, appointmentID uint) (*models.Appointment, error) {
+	if appointmentID == 0 {
+		return nil, errors.New("appointment ID cannot be zero")
+	}
+
+	if err := s.appointmentRepo.ConfirmDepositHold(appointmentID, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to confirm deposit: %w", err)
+	}
+	s.invalidateAppointment(appointmentID)
+
+	appointment, err := s.appointmentRepo.GetAppointmentByID(appointmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get appointment: %w", err)
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		release := acquireNotificationSlot()
+		defer release()
+
+		err := s.notificationSvc.SendAppointmentConfirmation(appointment)
+		if err != nil {
+			utils.LogError(err, "Failed to send appointment confirmation", map[string]interface{}{
+				"appointment_id": appointment.ID,
+				"user_id":        appointment.UserID,
+				"request_id":     utils.RequestIDFromContext(ctx),
+			})
+		}
+		s.recordNotification(ctx, appointment, models.NotificationConfirmation, err)
+	}()
+
+	return appointment, nil
+}
+
+// ConfirmAppointment confirms a scheduled appointment on behalf of confirmedBy
+func (s *schedulingService) ConfirmAppointment(appointmentID uint, confirmedBy string) (*models.Appointment, error) {
+	if appointmentID == 0 {
+		return nil, errors.New("appointment ID cannot be zero")
+	}
+
+	appointment, err := s.appointmentRepo.GetAppointmentByID(appointmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch appointment.Status {
+	case models.StatusScheduled:
+	case models.StatusConfirmed:
+		return appointment, ErrAlreadyConfirmed
+	default:
+		return appointment, fmt.Errorf("%w: status is %s", ErrAppointmentNotActive, appointment.Status)
+	}
+
+	confirmedAt := time.Now()
+	if err := s.appointmentRepo.ConfirmAppointment(appointmentID, confirmedBy, confirmedAt); err != nil {
+		return appointment, err
+	}
+	s.invalidateAppointment(appointmentID)
+
+	appointment.Status = models.StatusConfirmed
+	appointment.ConfirmedAt = &confirmedAt
+	appointment.ConfirmedBy = confirmedBy
+	return appointment, nil
+}
+
+// ConfirmAppointments confirms each appointment independently, so one that cannot be confirmed
+// does not stop the rest, and returns the outcome for every ID in order
+func (s *schedulingService) ConfirmAppointments(appointmentIDs []uint, confirmedBy string) []models.ConfirmationResult {
+	results := make([]models.ConfirmationResult, 0, len(appointmentIDs))
+	confirmed := 0
+	for _, appointmentID := range appointmentIDs {
+		result := models.ConfirmationResult{AppointmentID: appointmentID}
+
+		appointment, err := s.ConfirmAppointment(appointmentID, confirmedBy)
+		if appointment != nil {
+			result.Status = appointment.Status
+		}
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Success = true
+			confirmed++
+		}
+		results = append(results, result)
+	}
+
+	utils.LogInfo("Appointments batch-confirmed", map[string]interface{}{
+		"requested":    len(appointmentIDs),
+		"confirmed":    confirmed,
+		"confirmed_by": confirmedBy,
+	})
+
+	return results
+}
+
+// ResendConfirmation sends an active appointment's confirmation again, at most once per
+// ConfirmationResendInterval per appointment. The resend is recorded under the request ID in ctx.
+func (s *schedulingService) ResendConfirmation(ctx context.Context, appointmentID uint) error {
+	if appointmentID == 0 {
+		return errors.New("appointment ID cannot be zero")
+	}
+
+	appointment, err := s.appointmentRepo.GetAppointmentByID(appointmentID)
+	if err != nil {
+		return err
+	}
+	if appointment.Status != models.StatusScheduled && appointment.Status != models.StatusConfirmed {
+		return fmt.Errorf("%w: status is %s", ErrAppointmentNotActive, appointment.Status)
+	}
+
+	if err := s.reserveResend(ctx, appointmentID); err != nil {
+		return err
+	}
+
+	release := acquireNotificationSlot()
+	defer release()
+
+	err = s.notificationSvc.SendAppointmentConfirmation(appointment)
+	s.recordNotification(ctx, appointment, models.NotificationConfirmation, err)
+	if err != nil {
+		return fmt.Errorf("failed to resend confirmation: %w", err)
+	}
+
+	utils.LogInfo("Appointment confirmation resent", map[string]interface{}{
+		"appointment_id": appointmentID,
+		"user_id":        appointment.UserID,
+		"request_id":     utils.RequestIDFromContext(ctx),
+	})
+
+	return nil
+}
+
+// reserveResend claims the appointment's next resend, returning a ResendThrottledError when the
+// previous one was less than ConfirmationResendInterval ago. The claim is a cache lock that expires
+// after the interval, so the limit holds across instances. Resends are let through when the cache
+// cannot be reached.
+func (s *schedulingService) reserveResend(ctx context.Context, appointmentID uint) error {
+	if s.cacheService == nil || s.config.ConfirmationResendInterval <= 0 {
+		return nil
+	}
+
+	key := fmt.Sprintf("throttle:resend-confirmation:appointment:%d", appointmentID)
+	_, acquired, err := s.cacheService.AcquireLock(ctx, key, s.config.ConfirmationResendInterval)
+	if err != nil {
+		utils.LogWarn("Confirmation resend throttle unavailable, allowing resend", map[string]interface{}{
+			"appointment_id": appointmentID,
+			"error":          err.Error(),
+		})
+		return nil
+	}
+
+	sentAtKey := key + ":sent-at"
+	if !acquired {
+		retryAfter := s.config.ConfirmationResendInterval
+		var sentAt time.Time
+		if err := s.cacheService.Get(ctx, sentAtKey, &sentAt); err == nil {
+			retryAfter -= time.Since(sentAt)
+		}
+		return &ResendThrottledError{RetryAfter: max(retryAfter, time.Second)}
+	}
+
+	if err := s.cacheService.Set(ctx, sentAtKey, time.Now(), s.config.ConfirmationResendInterval); err != nil {
+		utils.LogWarn("Failed to record confirmation resend time", map[string]interface{}{
+			"appointment_id": appointmentID,
+			"error":          err.Error(),
+		})
+	}
+	return nil
+}
+
+// ReleaseExpiredHolds cancels bookings whose deposit was not paid in time
+func (s *schedulingService) ReleaseExpiredHolds() (int, error) {
+	released, err := s.appointmentRepo.ReleaseExpiredHolds(time.Now())
+	for _, appointmentID := range released {
+		s.invalidateAppointment(appointmentID)
+		if s.availabilityCacheEnabled() {
+			if appointment, getErr := s.appointmentRepo.GetAppointmentByID(appointmentID); getErr == nil {
+				s.invalidateAvailability(appointment.DoctorID, appointment.AppointmentTime, appointment.EndTime)
+			}
+		}
+	}
+	return len(released), err
+}
+
+// GetAppointment retrieves an appointment with its doctor and specialty, using the
+// appointment:{id} cache entry when available
+func (s *schedulingService) GetAppointment(appointmentID uint) (*models.Appointment, error) {
+	if appointmentID == 0 {
+		return nil, errors.New("appointment ID cannot be zero")
+	}
+
+	ctx := context.Background()
+	if s.cacheService != nil {
+		if appointment, err := s.cacheService.GetAppointment(ctx, appointmentID); err == nil {
+			return appointment, nil
+		}
+	}
+
+	appointment, err := s.appointmentRepo.GetAppointmentByID(appointmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.cacheService != nil {
+		if err := s.cacheService.SetAppointment(ctx, appointment); err != nil {
+			utils.LogWarn("Failed to cache appointment", map[string]interface{}{
+				"appointment_id": appointmentID,
+				"error":          err.Error(),
+			})
+		}
+	}
+
+	return appointment, nil
+}
+
+// AddSlotReleaseListener registers a listener for freed appointment times
+func (s *schedulingService) AddSlotReleaseListener(listener SlotReleaseListener) {
+	s.releaseListeners = append(s.releaseListeners, listener)
+}
+
+// SetNotificationLog records booking confirmations in the notification log. It is called during
+// setup, before the service handles requests.
+func (s *schedulingService) SetNotificationLog(logRepo repository.NotificationLogRepository) {
+	s.notificationLog = logRepo
+}
+
+// SetSpecialtyClassifier classifies the symptoms of symptom-based bookings. It is called during
+// setup, before the service handles requests.
+func (s *schedulingService) SetSpecialtyClassifier(classifier SpecialtyClassifier) {
+	s.classifier = classifier
+}
+
+// SetLocationRepository labels merged availability with location names. It is called during
+// setup, before the service handles requests.
+func (s *schedulingService) SetLocationRepository(locationRepo repository.LocationRepository) {
+	s.locationRepo = locationRepo
+}
+
+// ClassifyAppointment re-runs the AI classification of an appointment's symptom and stores the result
+func (s *schedulingService) ClassifyAppointment(appointmentID uint) (*models.Appointment, error) {
+	if s.classifier == nil {
+		return nil, ErrClassifierUnavailable
+	}
+
+	appointment, err := s.appointmentRepo.GetAppointmentByID(appointmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get appointment: %w", err)
+	}
+	if appointment.Symptom == "" {
+		return nil, fmt.Errorf("%w: appointment %d was not booked by symptom", utils.ErrInvalidInput, appointmentID)
+	}
+
+	classification, err := s.classify(appointment)
+	if err != nil {
+		return nil, err
+	}
+
+	specialtyID := uint(classification.SpecialtyID)
+	appointment.AISpecialtyID = &specialtyID
+	appointment.AIConfidence = &classification.Confidence
+	s.invalidateAppointment(appointmentID)
+	return appointment, nil
+}
+
+// pendingClassification is the cached record of an asynchronous classification awaiting its callback
+type pendingClassification struct {
+	AppointmentID uint `json:"appointment_id"`
+}
+
+func pendingClassificationKey(requestID string) string {
+	return "ai:pending:" + requestID
+}
+
+// classify asks the classifier about the appointment's symptom and stores its answer. When the
+// classifier answers asynchronously, the request is remembered for the callback and
+// ErrClassificationPending is returned.
+func (s *schedulingService) classify(appointment *models.Appointment) (*ClassificationResponse, error) {
+	classification, err := s.classifier.Classify(appointment.Symptom)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrClassifierUnavailable, err)
+	}
+
+	if classification.Pending {
+		if s.cacheService == nil {
+			return nil, fmt.Errorf("%w: no cache to hold the pending request", ErrClassifierUnavailable)
+		}
+		pending := pendingClassification{AppointmentID: appointment.ID}
+		if err := s.cacheService.Set(context.Background(), pendingClassificationKey(classification.RequestID), pending, s.config.PendingClassificationTTL); err != nil {
+			return nil, fmt.Errorf("failed to store pending classification: %w", err)
+		}
+		return nil, fmt.Errorf("%w: request %s", ErrClassificationPending, classification.RequestID)
+	}
+
+	if err := s.appointmentRepo.SetAIClassification(appointment.ID, uint(classification.SpecialtyID), classification.Confidence); err != nil {
+		return nil, err
+	}
+	return classification, nil
+}
+
+// ResolveClassification stores the classification the AI service posted back for a pending
+// request on the appointment that is waiting for it
+func (s *schedulingService) ResolveClassification(requestID string, specialtyID uint, confidence float64) (*models.Appointment, error) {
+	if specialtyID == 0 {
+		return nil, fmt.Errorf("%w: specialty_id is required", utils.ErrInvalidInput)
+	}
+	if confidence < 0 || confidence > 1 {
+		return nil, fmt.Errorf("%w: confidence must be between 0 and 1", utils.ErrInvalidInput)
+	}
+	if s.cacheService == nil {
+		return nil, ErrUnknownClassificationRequest
+	}
+
+	ctx := context.Background()
+	key := pendingClassificationKey(requestID)
+	var pending pendingClassification
+	if err := s.cacheService.Get(ctx, key, &pending); err != nil {
+		return nil, ErrUnknownClassificationRequest
+	}
+
+	if err := s.appointmentRepo.SetAIClassification(pending.AppointmentID, specialtyID, confidence); err != nil {
+		return nil, err
+	}
+	if err := s.cacheService.Delete(ctx, key); err != nil {
+		utils.LogWarn("Failed to clear resolved classification request", map[string]interface{}{
+			"request_id": requestID,
+			"error":      err.Error(),
+		})
+	}
+	s.invalidateAppointment(pending.AppointmentID)
+
+	return s.appointmentRepo.GetAppointmentByID(pending.AppointmentID)
+}
+
+// GetAISpecialtyComparison compares AI-suggested and booked specialties for appointments in [from, to)
+func (s *schedulingService) GetAISpecialtyComparison(from, to time.Time) ([]models.AISpecialtyComparison, error) {
+	return s.appointmentRepo.GetAISpecialtyComparison(from, to)
+}
+
+// recordNotification stores a notification attempt about an appointment, tagged with the request
+// ID carried by ctx
+func (s *schedulingService) recordNotification(ctx context.Context, appointment *models.Appointment, kind models.NotificationKind, sendErr error) {
+	channel := appointment.ReminderType
+	if channel == "" {
+		channel = models.ReminderSMS
+	}
+	saveNotificationLog(ctx, s.notificationLog, &models.NotificationLog{
+		AppointmentID: appointment.ID,
+		UserID:        appointment.UserID,
+		Kind:          kind,
+		Channel:       channel,
+	}, sendErr)
+}
+
+// saveNotificationLog completes entry with the outcome of the send and the request ID carried by
+// ctx, then stores it. A nil log disables recording.
+func saveNotificationLog(ctx context.Context, log repository.NotificationLogRepository, entry *models.NotificationLog, sendErr error) {
+	if log == nil {
+		return
+	}
+
+	entry.Status = models.NotificationSent
+	entry.RequestID = utils.RequestIDFromContext(ctx)
+	if sendErr != nil {
+		entry.Status = models.NotificationFailed
+		entry.Error = sendErr.Error()
+	}
+
+	if err := log.CreateLog(entry); err != nil {
+		utils.LogError(err, "Failed to record notification log", map[string]interface{}{
+			"appointment_id": entry.AppointmentID,
+			"kind":           entry.Kind,
+			"request_id":     entry.RequestID,
+		})
+	}
+}
+
+// notifySlotReleased tells every registered listener that a booked time became free
+func (s *schedulingService) notifySlotReleased(ctx context.Context, doctorID uint, startTime, endTime time.Time) {
+	ctx = context.WithoutCancel(ctx)
+	for _, listener := range s.releaseListeners {
+		go listener.SlotReleased(ctx, doctorID, startTime, endTime)
+	}
+}
+
+// invalidateAppointment drops the cached copy of an appointment after it changes
+func (s *schedulingService) invalidateAppointment(appointmentID uint) {
+	if s.cacheService == nil {
+		return
+	}
+
+	if err := s.cacheService.InvalidateAppointmentCache(context.Background(), appointmentID); err != nil {
+		utils.LogWarn("Failed to invalidate appointment cache", map[string]interface{}{
+			"appointment_id": appointmentID,
+			"error":          err.Error(),
+		})
+	}
+}
+
+// CancelAppointment cancels an existing appointment with a reason code and optional free-text detail.
+// Cancelling an appointment that is already cancelled succeeds without touching slots or notifying again.
+// The notification is recorded under the request ID carried by ctx.
+func (s *schedulingService) CancelAppointment(ctx context.Context, appointmentID uint, cancelledBy string, reason models.CancellationReason, detail string) error {
+	if appointmentID == 0 {
+		return errors.New("appointment ID cannot be zero")
+	}
+	if !reason.IsValid() {
+		return fmt.Errorf("%w: %q", ErrInvalidCancellationReason, reason)
+	}
+
+	// Get appointment details for notification
+	appointment, err := s.appointmentRepo.GetAppointmentByID(appointmentID)
+	if err != nil {
+		return fmt.Errorf("failed to get appointment: %w", err)
+	}
+	if appointment.Status == models.StatusCancelled {
+		return nil
+	}
+
+	// Cancel the appointment
+	if err := s.appointmentRepo.CancelAppointment(appointmentID, cancelledBy, reason, detail); err != nil {
+		if errors.Is(err, repository.ErrAppointmentAlreadyCancelled) {
+			// A concurrent request cancelled it first and has already notified the patient
+			return nil
+		}
+		return fmt.Errorf("failed to cancel appointment: %w", err)
+	}
+	s.invalidateAppointment(appointmentID)
+	s.invalidateAvailability(appointment.DoctorID, appointment.AppointmentTime, appointment.EndTime)
+	s.notifySlotReleased(ctx, appointment.DoctorID, appointment.AppointmentTime, appointment.EndTime)
+
+	// The dispatcher no longer picks up the cancelled appointment; drop any reminder scheduled elsewhere
+	if err := s.notificationSvc.CancelReminder(appointmentID); err != nil {
+		utils.LogWarn("Failed to cancel appointment reminder", map[string]interface{}{
+			"appointment_id": appointmentID,
+			"error":          err.Error(),
+		})
+	}
+
+	// Send cancellation notification, preferring the free-text detail over the bare code
+	message := detail
+	if message == "" {
+		message = string(reason)
+	}
+	notifyCtx := context.WithoutCancel(ctx)
+	go func() {
+		release := acquireNotificationSlot()
+		defer release()
+
+		var alternatives []models.TimeSlot
+		if cancelledBy == "doctor" {
+			alternatives = s.cancellationAlternatives(appointment)
+		}
+		err := s.notificationSvc.SendAppointmentCancellation(appointment, message, alternatives)
+		if err != nil {
+			utils.LogError(err, "Failed to send cancellation notification", map[string]interface{}{
+				"appointment_id": appointmentID,
+				"cancelled_by":   cancelledBy,
+				"request_id":     utils.RequestIDFromContext(notifyCtx),
+			})
+		}
+		s.recordNotification(notifyCtx, appointment, models.NotificationCancellation, err)
+	}()
+
+	utils.LogInfo("Appointment cancelled successfully", map[string]interface{}{
+		"appointment_id": appointmentID,
+		"cancelled_by":   cancelledBy,
+		"reason":         reason,
+		"detail":         detail,
+	})
+
+	return nil
+}
+
+// maxCancellationAlternatives caps the replacement slots offered in a cancellation notice
+const maxCancellationAlternatives = 3
+
+// cancellationAlternatives suggests replacement slots for an appointment the doctor cancelled,
+// leaving out the cancelled time itself, which was just released
+func (s *schedulingService) cancellationAlternatives(appointment *models.Appointment) []models.TimeSlot {
+	suggestions, err := s.SuggestAlternativeSlots(appointment.DoctorID, appointment.AppointmentTime, appointment.Duration)
+	if err != nil {
+		utils.LogWarn("Failed to suggest alternatives for cancelled appointment", map[string]interface{}{
+			"appointment_id": appointment.ID,
+			"error":          err.Error(),
+		})
+		return nil
+	}
+
+	now := time.Now()
+	alternatives := make([]models.TimeSlot, 0, maxCancellationAlternatives)
+	for _, slot := range suggestions {
+		if !slot.StartTime.After(now) {
+			continue
+		}
+		if slot.StartTime.Before(appointment.EndTime) && slot.EndTime.After(appointment.AppointmentTime) {
+			continue
+		}
+		alternatives = append(alternatives, slot)
+		if len(alternatives) == maxCancellationAlternatives {
+			break
+		}
+	}
+	return alternatives
+}
+
+// RescheduleAppointment reschedules an existing appointment, recording the notification under the
+// request ID carried by ctx
+func (s *schedulingService) RescheduleAppointment(ctx context.Context, appointmentID uint, newStartTime, newEndTime time.Time) (*models.Appointment, error) {
+	if appointmentID == 0 {
+		return nil, errors.New("appointment ID cannot be zero")
+	}
+
+	// Validate new appointment time
+	if newStartTime.Before(time.Now()) {
+		return nil, errors.New("new appointment time must be in the future")
+	}
+
+	// The end must fall a whole number of minutes after the start so it agrees with the stored duration
+	proposed := models.Appointment{
+		AppointmentTime: newStartTime,
+		EndTime:         newEndTime,
+		Duration:        int(newEndTime.Sub(newStartTime) / time.Minute),
+	}
+	if err := proposed.CheckEndTime(); err != nil {
+		return nil, fmt.Errorf("invalid appointment times: %w", err)
+	}
+
+	// Get original appointment
+	originalAppointment, err := s.appointmentRepo.GetAppointmentByID(appointmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get original appointment: %w", err)
+	}
+
+	if err := s.checkHoliday(originalAppointment.DoctorID, newStartTime); err != nil {
+		return nil, err
+	}
+	if err := s.checkBlocked(originalAppointment.DoctorID, newStartTime, newEndTime); err != nil {
+		return nil, err
+	}
+
+	// Check for conflicts at new time
+	conflicts, err := s.appointmentRepo.DetectConflicts(originalAppointment.DoctorID, newStartTime, newEndTime, &appointmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check conflicts: %w", err)
+	}
+
+	if len(conflicts) > 0 {
+		return nil, errors.New("new time slot is not available - conflicts detected")
+	}
+
+	// Reschedule the appointment
+	newAppointmentID, err := s.rescheduleRecord(appointmentID, newStartTime, newEndTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reschedule appointment: %w", err)
+	}
+	s.invalidateAppointment(appointmentID)
+	s.invalidateAvailability(originalAppointment.DoctorID, originalAppointment.AppointmentTime, originalAppointment.EndTime)
+	s.invalidateAvailability(originalAppointment.DoctorID, newStartTime, newEndTime)
+	s.notifySlotReleased(ctx, originalAppointment.DoctorID, originalAppointment.AppointmentTime, originalAppointment.EndTime)
+
+	// Get the new appointment
+	newAppointment, err := s.appointmentRepo.GetAppointmentByID(newAppointmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rescheduled appointment: %w", err)
+	}
+
+	// Send reschedule notification
+	notifyCtx := context.WithoutCancel(ctx)
+	go func() {
+		release := acquireNotificationSlot()
+		defer release()
+
+		err := s.notificationSvc.SendAppointmentReschedule(originalAppointment, newAppointment)
+		if err != nil {
+			utils.LogError(err, "Failed to send reschedule notification", map[string]interface{}{
+				"appointment_id": appointmentID,
+				"new_start_time": newStartTime,
+				"request_id":     utils.RequestIDFromContext(notifyCtx),
+			})
+		}
+		s.recordNotification(notifyCtx, originalAppointment, models.NotificationReschedule, err)
+	}()
+
+	return newAppointment, nil
+}
+
+// rescheduleRecord moves an appointment to [newStartTime, newEndTime) as the configured
+// RescheduleMode dictates, returning the ID of the appointment that now holds the time
+func (s *schedulingService) rescheduleRecord(appointmentID uint, newStartTime, newEndTime time.Time) (uint, error) {
+	if s.config.RescheduleMode == RescheduleInPlace {
+		return appointmentID, s.appointmentRepo.RescheduleAppointmentInPlace(appointmentID, newStartTime, newEndTime)
+	}
+	return s.appointmentRepo.RescheduleAppointment(appointmentID, newStartTime, newEndTime)
+}
+
+// ShiftAppointments moves every active appointment a doctor has starting in [windowStart, windowEnd)
+// by offset. Each appointment is rescheduled in its own transaction with its own conflict check, so
+// one failure does not stop the rest; the per-appointment outcomes are returned.
+func (s *schedulingService) ShiftAppointments(ctx context.Context, doctorID uint, windowStart, windowEnd time.Time, offset time.Duration) ([]models.ShiftResult, error) {
+	if !windowEnd.After(windowStart) {
+		return nil, errors.New("window end must be after window start")
+	}
+	if offset == 0 {
+		return nil, errors.New("offset cannot be zero")
+	}
+
+	appointments, err := s.appointmentRepo.GetDoctorAppointments(doctorID, windowStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get doctor appointments: %w", err)
+	}
+
+	var inWindow []models.Appointment
+	for _, appointment := range appointments {
+		if !appointment.AppointmentTime.Before(windowStart) && appointment.AppointmentTime.Before(windowEnd) {
+			inWindow = append(inWindow, appointment)
+		}
+	}
+
+	// Move the appointment furthest in the direction of travel first, so an appointment never
+	// conflicts with a neighbour from the same window that has not been moved yet
+	if offset > 0 {
+		for i, j := 0, len(inWindow)-1; i < j; i, j = i+1, j-1 {
+			inWindow[i], inWindow[j] = inWindow[j], inWindow[i]
+		}
+	}
+
+	results := make([]models.ShiftResult, 0, len(inWindow))
+	for _, appointment := range inWindow {
+		result := models.ShiftResult{
+			AppointmentID: appointment.ID,
+			OldStart:      appointment.AppointmentTime,
+			NewStart:      appointment.AppointmentTime.Add(offset),
+		}
+
+		moved, err := s.RescheduleAppointment(ctx, appointment.ID, result.NewStart, appointment.EndTime.Add(offset))
+		if err != nil {
+			result.Error = err.Error()
+			utils.LogWarn("Failed to shift appointment", map[string]interface{}{
+				"appointment_id": appointment.ID,
+				"doctor_id":      doctorID,
+				"error":          err.Error(),
+			})
+		} else {
+			result.Success = true
+			result.NewAppointmentID = moved.ID
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// OverrideReminder changes the reminder settings of one active appointment. Moving the reminder to
+// a time that is still ahead re-arms it, so the dispatcher sends it again at the new time.
+func (s *schedulingService) OverrideReminder(appointmentID uint, override ReminderOverride) (*models.Appointment, error) {
+	if override.Type != "" && !override.Type.IsValid() {
+		return nil, fmt.Errorf("%w: unknown reminder type %q", utils.ErrInvalidInput, override.Type)
+	}
+	if override.OffsetMinutes != nil && (*override.OffsetMinutes < MinReminderTime || *override.OffsetMinutes > MaxReminderTime) {
+		return nil, fmt.Errorf("%w: offset_minutes must be between %d and %d", utils.ErrInvalidInput, MinReminderTime, MaxReminderTime)
+	}
+
+	appointment, err := s.appointmentRepo.GetAppointmentByID(appointmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch appointment.Status {
+	case models.StatusScheduled, models.StatusConfirmed, models.StatusPendingPayment:
+	default:
+		return nil, fmt.Errorf("%w: status is %s", ErrAppointmentNotActive, appointment.Status)
+	}
+
+	if override.Type != "" {
+		appointment.ReminderType = override.Type
+	}
+	if override.Enabled != nil {
+		appointment.ReminderEnabled = *override.Enabled
+	}
+	if override.OffsetMinutes != nil && *override.OffsetMinutes != appointment.ReminderTime {
+		appointment.ReminderTime = *override.OffsetMinutes
+		remindAt := appointment.AppointmentTime.Add(-time.Duration(appointment.ReminderTime) * time.Minute)
+		if remindAt.After(time.Now()) {
+			appointment.ReminderSent = false
+			appointment.ReminderSentAt = nil
+		}
+	}
+
+	if err := s.appointmentRepo.UpdateReminderSettings(appointment); err != nil {
+		return nil, err
+	}
+	s.invalidateAppointment(appointmentID)
+
+	utils.LogInfo("Appointment reminder overridden", map[string]interface{}{
+		"appointment_id": appointmentID,
+		"reminder_type":  appointment.ReminderType,
+		"reminder_time":  appointment.ReminderTime,
+		"enabled":        appointment.ReminderEnabled,
+	})
+
+	return appointment, nil
+}
+
+// SetAppointmentTags replaces an appointment's tags
+func (s *schedulingService) SetAppointmentTags(appointmentID uint, tags []string) (*models.Appointment, error) {
+	normalized, err := models.NormalizeTags(tags)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrInvalidInput, err)
+	}
+
+	appointment, err := s.appointmentRepo.GetAppointmentByID(appointmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.appointmentRepo.UpdateAppointmentTags(appointmentID, normalized); err != nil {
+		return nil, err
+	}
+	s.invalidateAppointment(appointmentID)
+
+	appointment.Tags = normalized
+	return appointment, nil
+}
+
+// ChangeAppointmentType changes the type of a booked appointment. The booked duration must fall
+// within the new type's limits, since changing type never moves or resizes the slot.
+func (s *schedulingService) ChangeAppointmentType(appointmentID uint, newType models.AppointmentType, changedBy string) (*models.Appointment, error) {
+	if appointmentID == 0 {
+		return nil, errors.New("appointment ID cannot be zero")
+	}
+	if !newType.IsValid() {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAppointmentType, newType)
+	}
+
+	appointment, err := s.appointmentRepo.GetAppointmentByID(appointmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch appointment.Status {
+	case models.StatusScheduled, models.StatusConfirmed, models.StatusPendingPayment:
+	default:
+		return nil, fmt.Errorf("%w: status is %s", ErrAppointmentNotActive, appointment.Status)
+	}
+
+	if appointment.Type == newType {
+		return appointment, nil
+	}
+
+	minMinutes, maxMinutes := newType.DurationLimits()
+	if appointment.Duration < minMinutes || appointment.Duration > maxMinutes {
+		return nil, fmt.Errorf("%w: %s appointments last %d-%d minutes, booked %d",
+			ErrTypeDurationMismatch, newType, minMinutes, maxMinutes, appointment.Duration)
+	}
+
+	if err := s.appointmentRepo.ChangeAppointmentType(appointmentID, newType, changedBy); err != nil {
+		return nil, err
+	}
+	s.invalidateAppointment(appointmentID)
+
+	utils.LogInfo("Appointment type changed", map[string]interface{}{
+		"appointment_id": appointmentID,
+		"old_type":       appointment.Type,
+		"new_type":       newType,
+		"changed_by":     changedBy,
+	})
+
+	appointment.Type = newType
+	return appointment, nil
+}
+
+// Availability Management
+
+// GetDoctorAvailability returns available time slots for a doctor on a specific date, served
+// from the warmed availability cache when the day's week is cached. Whether the doctor is
+// accepting bookings is always read fresh, so pausing takes effect without invalidating the cache.
+func (s *schedulingService) GetDoctorAvailability(doctorID uint, date time.Time) (*models.AvailabilityResponse, error) {
+	availability, ok := s.cachedAvailability(doctorID, date)
+	if !ok {
+		var err error
+		if availability, err = s.computeDoctorAvailability(doctorID, date); err != nil {
+			return nil, err
+		}
+	}
+
+	accepting, err := s.isAcceptingBookings(doctorID)
+	if err != nil {
+		return nil, err
+	}
+	availability.AcceptingBookings = accepting
+	return availability, nil
+}
+
+// computeDoctorAvailability loads a doctor's availability for a date from the database
+func (s *schedulingService) computeDoctorAvailability(doctorID uint, date time.Time) (*models.AvailabilityResponse, error) {
+	// Get available time slots
+	timeSlots, err := s.timeSlotRepo.GetAvailableSlots(doctorID, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get available slots: %w", err)
+	}
+
+	// Get doctor appointments for the day
+	appointments, err := s.appointmentRepo.GetDoctorAppointments(doctorID, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get doctor appointments: %w", err)
+	}
+
+	// Get doctor breaks (not used in current response structure)
+	_, err = s.timeSlotRepo.GetDoctorBreaks(doctorID, date)
+	if err != nil {
+		utils.LogError(err, "Failed to get doctor breaks", map[string]interface{}{
+			"doctor_id": doctorID,
+			"date":      date,
+		})
+		// Continue without breaks data
+	}
+
+	response := &models.AvailabilityResponse{
+		DoctorID:       doctorID,
+		Date:           date,
+		AvailableSlots: timeSlots,
+		TotalSlots:     len(timeSlots),
+		BookedSlots:    len(appointments),
+	}
+	response.ApplyUtilization(s.config.NearlyFullThreshold)
+
+	return response, nil
+}
+
+// FilterByLocation returns a copy of the availability keeping only slots held at the location
+func (s *schedulingService) FilterByLocation(availability *models.AvailabilityResponse, locationID uint) *models.AvailabilityResponse {
+	if availability == nil {
+		return nil
+	}
+
+	filtered := *availability
+	filtered.AvailableSlots = make([]models.TimeSlot, 0, len(availability.AvailableSlots))
+	for _, slot := range availability.AvailableSlots {
+		if slot.LocationID != nil && *slot.LocationID == locationID {
+			filtered.AvailableSlots = append(filtered.AvailableSlots, slot)
+		}
+	}
+	filtered.TotalSlots = len(filtered.AvailableSlots)
+	return &filtered
+}
+
+// GetDoctorAvailabilityAllLocations returns the doctor's available slots for a date across every
+// location they work from, ordered by start time and labelled with each slot's location
+func (s *schedulingService) GetDoctorAvailabilityAllLocations(doctorID uint, date time.Time) (*models.LocatedAvailability, error) {
+	availability, err := s.GetDoctorAvailability(doctorID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	var locations []models.Location
+	if s.locationRepo != nil {
+		seen := make(map[uint]bool)
+		var ids []uint
+		for _, slot := range availability.AvailableSlots {
+			if slot.LocationID != nil && !seen[*slot.LocationID] {
+				seen[*slot.LocationID] = true
+				ids = append(ids, *slot.LocationID)
+			}
+		}
+		if locations, err = s.locationRepo.GetLocationsByIDs(ids); err != nil {
+			return nil, err
+		}
+	}
+
+	slots := models.LabelSlotLocations(availability.AvailableSlots, locations)
+	return &models.LocatedAvailability{
+		DoctorID:          doctorID,
+		Date:              date,
+		Slots:             slots,
+		TotalSlots:        len(slots),
+		AcceptingBookings: availability.AcceptingBookings,
+	}, nil
+}
+
+// GetPatientCommitments returns the patient's appointments with any doctor that still hold a slot
+// and overlap [from, to)
+func (s *schedulingService) GetPatientCommitments(userID uint, from, to time.Time) ([]models.Appointment, error) {
+	return s.appointmentRepo.GetPatientActiveAppointmentsInRange(userID, from, to)
+}
+
+// FilterPatientConflicts returns a copy of the availability without slots that overlap one of the
+// patient's commitments, so a patient is not offered a time they are already booked elsewhere
+func (s *schedulingService) FilterPatientConflicts(availability *models.AvailabilityResponse, commitments []models.Appointment) *models.AvailabilityResponse {
+	if availability == nil {
+		return nil
+	}
+
+	filtered := *availability
+	filtered.AvailableSlots = make([]models.TimeSlot, 0, len(availability.AvailableSlots))
+	for _, slot := range availability.AvailableSlots {
+		clashes := false
+		for _, appointment := range commitments {
+			if slot.StartTime.Before(appointment.EndTime) && appointment.AppointmentTime.Before(slot.EndTime) {
+				clashes = true
+				break
+			}
+		}
+		if !clashes {
+			filtered.AvailableSlots = append(filtered.AvailableSlots, slot)
+		}
+	}
+	filtered.TotalSlots = len(filtered.AvailableSlots)
+	return &filtered
+}
+
+// GetDoctorAvailabilityRange returns available time slots for a doctor within a date range, along
+// with the dates whose availability could not be computed. It only fails when no date succeeds.
+func (s *schedulingService) GetDoctorAvailabilityRange(doctorID uint, startDate, endDate time.Time) (map[string]*models.AvailabilityResponse, []string, error) {
+	availabilityMap := make(map[string]*models.AvailabilityResponse)
+	var failedDates []string
+	var lastErr error
+
+	// Iterate through each date in the range
+	currentDate := startDate
+	for currentDate.Before(endDate) || currentDate.Equal(endDate) {
+		dateKey := currentDate.Format("2006-01-02")
+		availability, err := s.GetDoctorAvailability(doctorID, currentDate)
+		if err != nil {
+			utils.LogError(err, "Failed to get availability for date", map[string]interface{}{
+				"doctor_id": doctorID,
+				"date":      currentDate,
+			})
+			// Report the date and continue with the next one
+			failedDates = append(failedDates, dateKey)
+			lastErr = err
+			currentDate = currentDate.AddDate(0, 0, 1)
+			continue
+		}
+
+		availabilityMap[dateKey] = availability
+		currentDate = currentDate.AddDate(0, 0, 1)
+	}
+
+	if len(availabilityMap) == 0 && lastErr != nil {
+		return nil, failedDates, fmt.Errorf("failed to get availability for every date in range: %w", lastErr)
+	}
+
+	return availabilityMap, failedDates, nil
+}
+
+// GetBookableWindows returns, for each day from startDate to endDate inclusive, the windows of
+// back-to-back available slots that can fit an appointment of duration minutes. A fragmented day
+// can have plenty of free slots but few or no windows long enough.
+func (s *schedulingService) GetBookableWindows(doctorID uint, startDate, endDate time.Time, duration int) ([]models.DayBookableWindows, error) {
+	if duration <= 0 {
+		return nil, errors.New("duration must be positive")
+	}
+	minLength := time.Duration(duration) * time.Minute
+
+	var days []models.DayBookableWindows
+	for date := startDate; !date.After(endDate); date = date.AddDate(0, 0, 1) {
+		availability, err := s.GetDoctorAvailability(doctorID, date)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get availability for %s: %w", date.Format("2006-01-02"), err)
+		}
+
+		days = append(days, models.DayBookableWindows{
+			Date:    date.Format("2006-01-02"),
+			Windows: models.MergeSlotWindows(availability.AvailableSlots, minLength),
+		})
+	}
+
+	return days, nil
+}
+
+// FindBestSlot returns the open appointment time that best fits the patient's constraints: it
+// lies between Earliest and Latest (and not in the past) and is as close as possible to one of
+// the preferred times of day, the earliest such time winning ties. It returns ErrNoSlotFits when
+// nothing fits.
+func (s *schedulingService) FindBestSlot(request *BestSlotRequest) (*models.SlotChoice, error) {
+	if request == nil {
+		return nil, errors.New("best slot request cannot be nil")
+	}
+	if request.Duration <= 0 {
+		return nil, fmt.Errorf("%w: duration must be positive", utils.ErrInvalidInput)
+	}
+	if !request.Latest.After(request.Earliest) {
+		return nil, fmt.Errorf("%w: latest must be after earliest", utils.ErrInvalidInput)
+	}
+	if request.Latest.Sub(request.Earliest) > MaxBestSlotSearchDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: earliest and latest can be at most %d days apart", utils.ErrInvalidInput, MaxBestSlotSearchDays)
+	}
+
+	earliest := request.Earliest
+	if now := time.Now().In(earliest.Location()); earliest.Before(now) {
+		earliest = now
+	}
+
+	// Days are widened by one on each side so slots near midnight in any timezone are considered
+	from := request.Earliest.UTC()
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1)
+	to := request.Latest.UTC().AddDate(0, 0, 1)
+
+	var slots []models.TimeSlot
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		availability, err := s.GetDoctorAvailability(request.DoctorID, date)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get availability for %s: %w", date.Format("2006-01-02"), err)
+		}
+		if !availability.AcceptingBookings {
+			return nil, models.ErrDoctorNotAcceptingBookings
+		}
+		slots = append(slots, availability.AvailableSlots...)
+	}
+
+	choice, ok := models.BestSlot(slots, time.Duration(request.Duration)*time.Minute, earliest, request.Latest, request.PreferredTimes)
+	if !ok {
+		return nil, ErrNoSlotFits
+	}
+	return &choice, nil
+}
+
+// GetClinicDaySchedule returns every given doctor's appointments and slot utilization on a date,
+// loading appointments and open slots for all doctors in two batched queries
+func (s *schedulingService) GetClinicDaySchedule(doctors []models.Doctor, date time.Time) ([]models.DoctorDaySchedule, error) {
+	schedules := make([]models.DoctorDaySchedule, 0, len(doctors))
+	if len(doctors) == 0 {
+		return schedules, nil
+	}
+
+	doctorIDs := make([]uint, len(doctors))
+	for i, doctor := range doctors {
+		doctorIDs[i] = doctor.ID
+	}
+
+	appointmentsByDoctor, err := s.appointmentRepo.GetDoctorsAppointments(doctorIDs, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get doctor appointments: %w", err)
+	}
+
+	slotsByDoctor, err := s.timeSlotRepo.GetAvailableSlotsForDoctors(doctorIDs, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get available slots: %w", err)
+	}
+
+	for _, doctor := range doctors {
+		appointments := appointmentsByDoctor[doctor.ID]
+		if appointments == nil {
+			appointments = []models.Appointment{}
+		}
+
+		usage := models.AvailabilityResponse{
+			TotalSlots:  len(slotsByDoctor[doctor.ID]),
+			BookedSlots: len(appointments),
+		}
+		usage.ApplyUtilization(s.config.NearlyFullThreshold)
+
+		schedules = append(schedules, models.DoctorDaySchedule{
+			Doctor:         doctor,
+			Appointments:   appointments,
+			AvailableSlots: usage.TotalSlots,
+			BookedSlots:    usage.BookedSlots,
+			Utilization:    usage.Utilization,
+			NearlyFull:     usage.NearlyFull,
+		})
+	}
+
+	return schedules, nil
+}
+
+// GetMultiDoctorAvailability returns availability for several doctors on a date,
+// loading slots and appointment counts for all doctors in batched queries
+func (s *schedulingService) GetMultiDoctorAvailability(doctorIDs []uint, date time.Time) (map[uint]*models.AvailabilityResponse, error) {
+	if len(doctorIDs) == 0 {
+		return nil, errors.New("at least one doctor ID is required")
+	}
+	if len(doctorIDs) > MaxDoctorsPerAvailabilityRequest {
+		return nil, fmt.Errorf("cannot request availability for more than %d doctors", MaxDoctorsPerAvailabilityRequest)
+	}
+
+	slotsByDoctor, err := s.timeSlotRepo.GetAvailableSlotsForDoctors(doctorIDs, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get available slots: %w", err)
+	}
+
+	bookedByDoctor, err := s.appointmentRepo.CountDoctorsAppointments(doctorIDs, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get doctor appointments: %w", err)
+	}
+
+	acceptingByDoctor, err := s.appointmentRepo.GetAcceptingBookings(doctorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	availabilityMap := make(map[uint]*models.AvailabilityResponse, len(doctorIDs))
+	for _, doctorID := range doctorIDs {
+		slots := slotsByDoctor[doctorID]
+		accepting, found := acceptingByDoctor[doctorID]
+		availability := &models.AvailabilityResponse{
+			DoctorID:          doctorID,
+			Date:              date,
+			AvailableSlots:    slots,
+			TotalSlots:        len(slots),
+			BookedSlots:       bookedByDoctor[doctorID],
+			AcceptingBookings: accepting || !found,
+		}
+		availability.ApplyUtilization(s.config.NearlyFullThreshold)
+		availabilityMap[doctorID] = availability
+	}
+
+	return availabilityMap, nil
+}
+
+// CheckTimeSlotAvailability checks if a time slot is available for booking
+func (s *schedulingService) CheckTimeSlotAvailability(doctorID uint, startTime, endTime time.Time) (bool, error) {
+	return s.timeSlotRepo.CheckSlotAvailability(doctorID, startTime, endTime)
+}
+
+// GetDoctorSlots returns a doctor's time slots for a date, optionally filtered by status
+func (s *schedulingService) GetDoctorSlots(doctorID uint, date time.Time, status models.SlotStatus) ([]models.TimeSlot, error) {
+	if status != "" && !status.IsValid() {
+		return nil, fmt.Errorf("invalid slot status: %s", status)
+	}
+	return s.timeSlotRepo.GetSlotsByStatus(doctorID, date, status)
+}
+
+// Patient Operations
+
+// GetAttendanceStats returns a patient's no-show history and reliability score
+func (s *schedulingService) GetAttendanceStats(userID uint) (*models.AttendanceStats, error) {
+	if userID == 0 {
+		return nil, errors.New("user ID cannot be zero")
+	}
+
+	return s.appointmentRepo.GetAttendanceStats(userID, s.config.LateCancellationWindow)
+}
+
+// GetSpecialtyStats returns appointment counts per specialty for appointments in [from, to)
+func (s *schedulingService) GetSpecialtyStats(from, to time.Time) ([]models.SpecialtyStats, error) {
+	if !to.After(from) {
+		return nil, errors.New("end of range must be after start")
+	}
+
+	return s.appointmentRepo.GetSpecialtyStats(from, to)
+}
+
+// GetCancellationStats returns cancellation counts per reason code for cancellations in [from, to)
+func (s *schedulingService) GetCancellationStats(from, to time.Time) ([]models.CancellationReasonStats, error) {
+	if !to.After(from) {
+		return nil, errors.New("end of range must be after start")
+	}
+
+	return s.appointmentRepo.GetCancellationStats(from, to)
+}
+
+// GetAlternativeDoctors returns doctors in a specialty who are free for the whole window
+func (s *schedulingService) GetAlternativeDoctors(specialtyID uint, startTime, endTime time.Time) ([]models.Doctor, error) {
+	if specialtyID == 0 {
+		return nil, errors.New("specialty ID cannot be zero")
+	}
+	if !endTime.After(startTime) {
+		return nil, errors.New("end time must be after start time")
+	}
+
+	return s.timeSlotRepo.GetFreeDoctorsInSpecialty(specialtyID, startTime, endTime)
+}
+
+// GetPatientAppointments returns appointments for a specific patient
+func (s *schedulingService) GetPatientAppointments(userID uint, status string) ([]models.Appointment, error) {
+	return s.appointmentRepo.GetPatientAppointments(userID, status)
+}
+
+// GetReviewEligibleAppointments returns the patient's completed appointments that have not been
+// reviewed yet
+func (s *schedulingService) GetReviewEligibleAppointments(userID uint) ([]models.Appointment, error) {
+	return s.appointmentRepo.GetReviewEligibleAppointments(userID)
+}
+
+// SubmitReview records a patient's 1-5 rating of one of their completed appointments
+func (s *schedulingService) SubmitReview(review *models.Review) error {
+	if review == nil {
+		return errors.New("review cannot be nil")
+	}
+	if review.Rating < 1 || review.Rating > 5 {
+		return fmt.Errorf("%w: rating must be between 1 and 5", utils.ErrInvalidInput)
+	}
+
+	if err := s.appointmentRepo.CreateReview(review); err != nil {
+		return err
+	}
+
+	utils.LogInfo("Appointment reviewed", map[string]interface{}{
+		"review_id":      review.ID,
+		"appointment_id": review.AppointmentID,
+		"doctor_id":      review.DoctorID,
+		"rating":         review.Rating,
+	})
+
+	return nil
+}
+
+// GetAppointmentHistory returns the appointments between a doctor and a patient that started
+// before the given time, oldest first
+func (s *schedulingService) GetAppointmentHistory(doctorID, userID uint, before time.Time) ([]models.Appointment, error) {
+	return s.appointmentRepo.GetAppointmentHistory(doctorID, userID, before)
+}
+
+// GetRescheduleChain returns the chain of bookings the appointment belongs to, from the original
+// booking to the latest reschedule
+func (s *schedulingService) GetRescheduleChain(appointmentID uint) ([]models.Appointment, error) {
+	return s.appointmentRepo.GetRescheduleChain(appointmentID)
+}
+
+const (
+	// DefaultUpcomingAppointmentsLimit is how many upcoming appointments a patient gets when they set no limit
+	DefaultUpcomingAppointmentsLimit = 3
+	// MaxUpcomingAppointmentsLimit is the largest limit a patient can ask for
+	MaxUpcomingAppointmentsLimit = 50
+)
+
+// GetUpcomingAppointments returns a patient's soonest upcoming appointments, at most limit of them;
+// a zero limit returns them all
+func (s *schedulingService) GetUpcomingAppointments(userID uint, limit int) ([]models.Appointment, error) {
+	if limit < 0 || limit > MaxUpcomingAppointmentsLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", utils.ErrInvalidInput, MaxUpcomingAppointmentsLimit)
+	}
+	return s.appointmentRepo.GetUpcomingAppointments(int(userID), limit)
+}
+
+// MaxUpcomingBatchSize is the most patients whose upcoming appointments can be loaded at once
+const MaxUpcomingBatchSize = 50
+
+const (
+	// DefaultAppointmentChangesLimit is how many changes one sync request returns when it sets no limit
+	DefaultAppointmentChangesLimit = 100
+	// MaxAppointmentChangesLimit is the most changes one sync request can return
+	MaxAppointmentChangesLimit = 500
+)
+
+// GetAppointmentChanges returns the appointments of a patient, or of everyone when userID is nil,
+// that were created, updated or deleted after since, oldest change first
+func (s *schedulingService) GetAppointmentChanges(userID *uint, since time.Time, limit int) ([]models.AppointmentChange, error) {
+	if limit == 0 {
+		limit = DefaultAppointmentChangesLimit
+	}
+	if limit < 0 || limit > MaxAppointmentChangesLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", utils.ErrInvalidInput, MaxAppointmentChangesLimit)
+	}
+
+	appointments, err := s.appointmentRepo.GetAppointmentChanges(userID, since, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]models.AppointmentChange, 0, len(appointments))
+	for _, appointment := range appointments {
+		changes = append(changes, models.NewAppointmentChange(appointment))
+	}
+	return changes, nil
+}
+
+// GetUpcomingAppointmentsForUsers returns upcoming appointments for several patients at once,
+// keyed by patient ID
+func (s *schedulingService) GetUpcomingAppointmentsForUsers(userIDs []uint) (map[uint][]models.Appointment, error) {
+	if len(userIDs) > MaxUpcomingBatchSize {
+		return nil, fmt.Errorf("%w: at most %d patients per request", utils.ErrInvalidInput, MaxUpcomingBatchSize)
+	}
+	return s.appointmentRepo.GetUpcomingAppointmentsForUsers(userIDs)
+}
+
+// Doctor Operations
+
+// GetDoctorAppointments returns appointments for a specific doctor on a specific date
+func (s *schedulingService) GetDoctorAppointments(doctorID uint, date time.Time) ([]models.Appointment, error) {
+	return s.appointmentRepo.GetDoctorAppointments(doctorID, date)
+}
+
+// GetDoctorSchedule retrieves a doctor's schedule
+func (s *schedulingService) GetDoctorSchedule(doctorID uint) (*models.DoctorSchedule, error) {
+	return s.timeSlotRepo.GetDoctorSchedule(doctorID)
+}
+
+// UpdateDoctorSchedule updates a doctor's schedule
+func (s *schedulingService) UpdateDoctorSchedule(schedule *models.DoctorSchedule) error {
+	return s.timeSlotRepo.UpdateDoctorSchedule(schedule)
+}
+
+// GetBlockedPeriods returns every blocked or break slot and every break (one-off or recurring)
+// falling in [from, to), ordered by start time
+func (s *schedulingService) GetBlockedPeriods(doctorID uint, from, to time.Time) ([]models.BlockedPeriod, error) {
+	if !to.After(from) {
+		return nil, errors.New("end of range must be after start")
+	}
+
+	slots, err := s.timeSlotRepo.GetUnbookableSlots(doctorID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blocked slots: %w", err)
 	}
 
-	if !available {
-		return nil, errors.New("requested time slot is not available")
+	breaks, err := s.timeSlotRepo.GetDoctorBreaksInRange(doctorID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get breaks: %w", err)
 	}
 
-	// Create appointment
-	appointment := &models.Appointment{
-		UserID:          request.UserID,
-		DoctorID:        request.DoctorID,
-		AppointmentTime: request.AppointmentTime,
-		EndTime:         endTime,
-		Duration:        request.Duration,
-		Type:            request.AppointmentType,
-		Status:          models.StatusScheduled,
-		Notes:           request.Notes,
-		ReminderType:    request.ReminderType,
-		ReminderTime:    request.ReminderTime,
-		CreatedAt:       time.Now(),
+	recurringBreaks, err := s.timeSlotRepo.GetRecurringBreaks(doctorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recurring breaks: %w", err)
 	}
 
-	// Book the appointment
-	if err := s.appointmentRepo.BookTimeSlot(appointment); err != nil {
-		return nil, fmt.Errorf("failed to book appointment: %w", err)
+	periods := make([]models.BlockedPeriod, 0, len(slots)+len(breaks))
+	for i := range slots {
+		periods = append(periods, models.BlockedPeriod{
+			Kind:      slots[i].Status,
+			SlotID:    &slots[i].ID,
+			StartTime: slots[i].StartTime,
+			EndTime:   slots[i].EndTime,
+			Reason:    slots[i].Notes,
+		})
+	}
+	for i := range breaks {
+		periods = append(periods, models.BlockedPeriod{
+			Kind:      models.SlotBreak,
+			BreakID:   &breaks[i].ID,
+			StartTime: breaks[i].StartTime,
+			EndTime:   breaks[i].EndTime,
+			Reason:    breaks[i].Reason,
+		})
 	}
 
-	// Send confirmation notification
-	go func() {
-		if err := s.notificationSvc.SendAppointmentConfirmation(appointment); err != nil {
-			utils.LogError(err, "Failed to send appointment confirmation", map[string]interface{}{
-				"appointment_id": appointment.ID,
-				"user_id":        appointment.UserID,
-			})
+	// Each recurring break repeats on the weekday of its date, from that date onwards
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		for i := range recurringBreaks {
+			recurring := &recurringBreaks[i]
+			if recurring.Date.Weekday() != day.Weekday() || day.Before(recurring.Date) {
+				continue
+			}
+			periods = append(periods, breakPeriod(recurring, day))
 		}
-	}()
+	}
 
-	utils.LogInfo("Appointment booked successfully", map[string]interface{}{
-		"appointment_id":   appointment.ID,
-		"user_id":          request.UserID,
-		"doctor_id":        request.DoctorID,
-		"appointment_time": request.AppointmentTime,
+	sort.Slice(periods, func(i, j int) bool {
+		return periods[i].StartTime.Before(periods[j].StartTime)
 	})
 
-	return appointment, nil
+	return periods, nil
 }
 
-// CancelAppointment cancels an existing appointment
-func (s *schedulingService) CancelAppointment(appointmentID uint, cancelledBy, reason string) error {
-	if appointmentID == 0 {
-		return errors.New("appointment ID cannot be zero")
-	}
+// GetDoctorReliability returns a doctor's cancellation and no-show rates for every calendar month
+// from from's month through to's month, including months without appointments
+func (s *schedulingService) GetDoctorReliability(doctorID uint, from, to time.Time) ([]models.DoctorReliabilityMonth, error) {
+	firstMonth := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location())
+	end := time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, to.Location()).AddDate(0, 1, 0)
 
-	// Get appointment details for notification
-	appointment, err := s.appointmentRepo.GetAppointmentByID(appointmentID)
+	counts, err := s.appointmentRepo.GetMonthlyReliability(doctorID, firstMonth, end)
 	if err != nil {
-		return fmt.Errorf("failed to get appointment: %w", err)
+		return nil, fmt.Errorf("failed to count appointment outcomes: %w", err)
 	}
 
-	// Cancel the appointment
-	if err := s.appointmentRepo.CancelAppointment(appointmentID, cancelledBy, reason); err != nil {
-		return fmt.Errorf("failed to cancel appointment: %w", err)
+	countsByMonth := make(map[string]models.DoctorReliabilityMonth, len(counts))
+	for _, count := range counts {
+		countsByMonth[count.Month] = count
 	}
 
-	// Send cancellation notification
-	go func() {
-		if err := s.notificationSvc.SendAppointmentCancellation(appointment, reason); err != nil {
-			utils.LogError(err, "Failed to send cancellation notification", map[string]interface{}{
-				"appointment_id": appointmentID,
-				"cancelled_by":   cancelledBy,
-			})
+	var months []models.DoctorReliabilityMonth
+	for month := firstMonth; month.Before(end); month = month.AddDate(0, 1, 0) {
+		key := month.Format("2006-01")
+		bucket, ok := countsByMonth[key]
+		if !ok {
+			bucket = models.DoctorReliabilityMonth{Month: key}
 		}
-	}()
-
-	utils.LogInfo("Appointment cancelled successfully", map[string]interface{}{
-		"appointment_id": appointmentID,
-		"cancelled_by":   cancelledBy,
-		"reason":         reason,
-	})
+		bucket.ComputeRates()
+		months = append(months, bucket)
+	}
 
-	return nil
+	return months, nil
 }
 
-// RescheduleAppointment reschedules an existing appointment
-func (s *schedulingService) RescheduleAppointment(appointmentID uint, newStartTime, newEndTime time.Time) (*models.Appointment, error) {
-	if appointmentID == 0 {
-		return nil, errors.New("appointment ID cannot be zero")
+// GetDurationInsights analyzes a doctor's completed appointments and suggests a slot duration:
+// the median rounded up to the next 5 minutes, within the allowed appointment lengths
+func (s *schedulingService) GetDurationInsights(doctorID uint) (*models.DurationInsights, error) {
+	insights, err := s.appointmentRepo.GetDurationInsights(doctorID)
+	if err != nil {
+		return nil, err
 	}
-
-	// Validate new appointment time
-	if newStartTime.Before(time.Now()) {
-		return nil, errors.New("new appointment time must be in the future")
+	if insights.SampleSize == 0 {
+		return insights, nil
 	}
 
-	// Get original appointment
-	originalAppointment, err := s.appointmentRepo.GetAppointmentByID(appointmentID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get original appointment: %w", err)
+	suggested := int(math.Ceil(insights.Median/5)) * 5
+	if suggested < MinAppointmentDuration {
+		suggested = MinAppointmentDuration
+	}
+	if suggested > MaxAppointmentDuration {
+		suggested = MaxAppointmentDuration
 	}
+	insights.SuggestedSlotDuration = &suggested
+	return insights, nil
+}
 
-	// Check for conflicts at new time
-	conflicts, err := s.appointmentRepo.DetectConflicts(originalAppointment.DoctorID, newStartTime, newEndTime, &appointmentID)
+// defaultWalkInDuration is the appointment length assumed when nobody is ahead in the queue
+const defaultWalkInDuration = 30
+
+// GetWaitEstimate estimates a walk-in patient's wait from the doctor's scheduled and confirmed
+// appointments today that have not yet ended. An appointment in progress counts for its remaining
+// minutes; each one still to start counts for the average duration of the queue.
+func (s *schedulingService) GetWaitEstimate(doctorID uint, now time.Time) (*models.WaitEstimate, error) {
+	appointments, err := s.appointmentRepo.GetDoctorAppointments(doctorID, now)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check conflicts: %w", err)
+		return nil, fmt.Errorf("failed to get today's appointments: %w", err)
 	}
 
-	if len(conflicts) > 0 {
-		return nil, errors.New("new time slot is not available - conflicts detected")
+	var ahead []models.Appointment
+	totalDuration := 0
+	for _, appointment := range appointments {
+		if appointment.EndTime.After(now) {
+			ahead = append(ahead, appointment)
+			totalDuration += appointment.Duration
+		}
 	}
 
-	// Reschedule the appointment
-	rescheduleErr := s.appointmentRepo.RescheduleAppointment(appointmentID, newStartTime, newEndTime)
-	if rescheduleErr != nil {
-		return nil, fmt.Errorf("failed to reschedule appointment: %w", err)
+	estimate := &models.WaitEstimate{
+		DoctorID:          doctorID,
+		AppointmentsAhead: len(ahead),
+		QueuePosition:     len(ahead) + 1,
+		AverageDuration:   defaultWalkInDuration,
 	}
-
-	// Get the new appointment
-	newAppointment, err := s.appointmentRepo.GetAppointmentByID(appointmentID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get rescheduled appointment: %w", err)
+	if len(ahead) > 0 {
+		estimate.AverageDuration = totalDuration / len(ahead)
 	}
 
-	// Send reschedule notification
-	go func() {
-		if err := s.notificationSvc.SendAppointmentReschedule(originalAppointment, newAppointment); err != nil {
-			utils.LogError(err, "Failed to send reschedule notification", map[string]interface{}{
-				"appointment_id": appointmentID,
-				"new_start_time": newStartTime,
-			})
+	wait := time.Duration(0)
+	for _, appointment := range ahead {
+		if appointment.AppointmentTime.After(now) {
+			wait += time.Duration(estimate.AverageDuration) * time.Minute
+		} else {
+			wait += appointment.EndTime.Sub(now)
 		}
-	}()
+	}
+	estimate.EstimatedWaitMinutes = int(wait.Round(time.Minute).Minutes())
+	estimate.EstimatedSeenAt = now.Add(wait)
 
-	return newAppointment, nil
+	return estimate, nil
 }
 
-// Availability Management
+// GetDoctorTimeOff returns the doctor's time off that has not yet ended, looking days ahead of
+// now. Blocked slots with the same reason are merged into one period when they touch or continue
+// on the next calendar day, so a blocked week reads as a single range. Holidays cover whole days.
+func (s *schedulingService) GetDoctorTimeOff(doctorID uint, now time.Time, days int) ([]models.TimeOffPeriod, error) {
+	if days <= 0 {
+		return nil, errors.New("days must be positive")
+	}
 
-// GetDoctorAvailability returns available time slots for a doctor on a specific date
-func (s *schedulingService) GetDoctorAvailability(doctorID uint, date time.Time) (*models.AvailabilityResponse, error) {
-	// Get available time slots
-	timeSlots, err := s.timeSlotRepo.GetAvailableSlots(doctorID, date)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	until := today.AddDate(0, 0, days)
+
+	slots, err := s.timeSlotRepo.GetUnbookableSlots(doctorID, today, until)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get available slots: %w", err)
+		return nil, fmt.Errorf("failed to get blocked slots: %w", err)
 	}
 
-	// Get doctor appointments for the day
-	appointments, err := s.appointmentRepo.GetDoctorAppointments(doctorID, date)
+	holidays, err := s.timeSlotRepo.GetHolidaysInRange(doctorID, today, until.AddDate(0, 0, -1))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get doctor appointments: %w", err)
+		return nil, fmt.Errorf("failed to get holidays: %w", err)
 	}
 
-	// Get doctor breaks (not used in current response structure)
-	_, err = s.timeSlotRepo.GetDoctorBreaks(doctorID, date)
-	if err != nil {
-		utils.LogError(err, "Failed to get doctor breaks", map[string]interface{}{
-			"doctor_id": doctorID,
-			"date":      date,
+	periods := make([]models.TimeOffPeriod, 0, len(holidays))
+	var current *models.TimeOffPeriod
+	for _, slot := range slots {
+		if slot.Status != models.SlotBlocked {
+			continue
+		}
+		if current != nil && current.Reason == slot.Notes && continuesTimeOff(current.EndTime, slot.StartTime) {
+			if slot.EndTime.After(current.EndTime) {
+				current.EndTime = slot.EndTime
+			}
+			continue
+		}
+		periods = append(periods, models.TimeOffPeriod{
+			StartTime: slot.StartTime,
+			EndTime:   slot.EndTime,
+			Reason:    slot.Notes,
 		})
-		// Continue without breaks data
+		current = &periods[len(periods)-1]
 	}
 
-	response := &models.AvailabilityResponse{
-		DoctorID:       doctorID,
-		Date:           date,
-		AvailableSlots: timeSlots,
-		TotalSlots:     len(timeSlots),
-		BookedSlots:    len(appointments),
+	for i := range holidays {
+		holiday := &holidays[i]
+		start := time.Date(holiday.Date.Year(), holiday.Date.Month(), holiday.Date.Day(), 0, 0, 0, 0, now.Location())
+		periods = append(periods, models.TimeOffPeriod{
+			StartTime:  start,
+			EndTime:    start.AddDate(0, 0, 1),
+			Reason:     holiday.Name,
+			HolidayID:  &holiday.ID,
+			ClinicWide: holiday.AppliesToAll(),
+		})
 	}
 
-	return response, nil
-}
+	upcoming := periods[:0]
+	for _, period := range periods {
+		if period.EndTime.After(now) {
+			upcoming = append(upcoming, period)
+		}
+	}
 
-// GetDoctorAvailabilityRange returns available time slots for a doctor within a date range
-func (s *schedulingService) GetDoctorAvailabilityRange(doctorID uint, startDate, endDate time.Time) (map[string]*models.AvailabilityResponse, error) {
-	availabilityMap := make(map[string]*models.AvailabilityResponse)
+	sort.Slice(upcoming, func(i, j int) bool {
+		return upcoming[i].StartTime.Before(upcoming[j].StartTime)
+	})
 
-	// Iterate through each date in the range
-	currentDate := startDate
-	for currentDate.Before(endDate) || currentDate.Equal(endDate) {
-		availability, err := s.GetDoctorAvailability(doctorID, currentDate)
-		if err != nil {
-			utils.LogError(err, "Failed to get availability for date", map[string]interface{}{
-				"doctor_id": doctorID,
-				"date":      currentDate,
-			})
-			// Continue with next date
-			currentDate = currentDate.AddDate(0, 0, 1)
-			continue
-		}
+	return upcoming, nil
+}
 
-		dateKey := currentDate.Format("2006-01-02")
-		availabilityMap[dateKey] = availability
-		currentDate = currentDate.AddDate(0, 0, 1)
+// continuesTimeOff reports whether a blocked slot starting at next extends time off ending at end:
+// it touches or overlaps it, or starts on the following calendar day
+func continuesTimeOff(end, next time.Time) bool {
+	if !next.After(end) {
+		return true
 	}
-
-	return availabilityMap, nil
+	endDay := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, end.Location())
+	return next.Before(endDay.AddDate(0, 0, 2))
 }
 
-// CheckTimeSlotAvailability checks if a time slot is available for booking
-func (s *schedulingService) CheckTimeSlotAvailability(doctorID uint, startTime, endTime time.Time) (bool, error) {
-	return s.timeSlotRepo.CheckSlotAvailability(doctorID, startTime, endTime)
+// breakPeriod places a recurring break's clock times on the given day
+func breakPeriod(doctorBreak *models.DoctorBreak, day time.Time) models.BlockedPeriod {
+	at := func(clock time.Time) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, day.Location())
+	}
+
+	return models.BlockedPeriod{
+		Kind:        models.SlotBreak,
+		BreakID:     &doctorBreak.ID,
+		StartTime:   at(doctorBreak.StartTime),
+		EndTime:     at(doctorBreak.EndTime),
+		Reason:      doctorBreak.Reason,
+		IsRecurring: doctorBreak.IsRecurring,
+	}
 }
 
-// Patient Operations
+// GetScheduleGrid returns a doctor's weekly schedule template as a seven-day grid
+func (s *schedulingService) GetScheduleGrid(doctorID uint) ([]models.ScheduleGridDay, error) {
+	schedule, err := s.timeSlotRepo.GetDoctorSchedule(doctorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get doctor schedule: %w", err)
+	}
 
-// GetPatientAppointments returns appointments for a specific patient
-func (s *schedulingService) GetPatientAppointments(userID uint, status string) ([]models.Appointment, error) {
-	return s.appointmentRepo.GetPatientAppointments(userID, status)
+	breaks, err := s.timeSlotRepo.GetRecurringBreaks(doctorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recurring breaks: %w", err)
+	}
+
+	return models.BuildScheduleGrid(schedule, breaks), nil
 }
 
-// GetUpcomingAppointments returns upcoming appointments for a patient
-func (s *schedulingService) GetUpcomingAppointments(userID uint) ([]models.Appointment, error) {
-	return s.appointmentRepo.GetUpcomingAppointments(int(userID))
+// ValidateSchedule checks a doctor's weekly schedule template and recurring breaks for gaps and overlaps
+func (s *schedulingService) ValidateSchedule(doctorID uint) ([]models.ScheduleIssue, error) {
+	days, err := s.GetScheduleGrid(doctorID)
+	if err != nil {
+		return nil, err
+	}
+	return models.ValidateScheduleGrid(days), nil
 }
 
-// Doctor Operations
+// GetScheduleDiscrepancies cross-checks a doctor's active appointments starting in [from, to)
+// against their slots and weekly schedule
+func (s *schedulingService) GetScheduleDiscrepancies(doctorID uint, from, to time.Time) ([]models.ScheduleDiscrepancy, error) {
+	schedule, err := s.timeSlotRepo.GetDoctorSchedule(doctorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get doctor schedule: %w", err)
+	}
 
-// GetDoctorAppointments returns appointments for a specific doctor on a specific date
-func (s *schedulingService) GetDoctorAppointments(doctorID uint, date time.Time) ([]models.Appointment, error) {
-	return s.appointmentRepo.GetDoctorAppointments(doctorID, date)
-}
+	appointments, err := s.appointmentRepo.GetDoctorAppointmentsInRange(doctorID, from, to)
+	if err != nil {
+		return nil, err
+	}
 
-// GetDoctorSchedule retrieves a doctor's schedule
-func (s *schedulingService) GetDoctorSchedule(doctorID uint) (*models.DoctorSchedule, error) {
-	return s.timeSlotRepo.GetDoctorSchedule(doctorID)
+	slots, err := s.timeSlotRepo.GetSlotsInRange(doctorID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get time slots: %w", err)
+	}
+
+	return models.FindScheduleDiscrepancies(schedule, appointments, slots), nil
 }
 
-// UpdateDoctorSchedule updates a doctor's schedule
-func (s *schedulingService) UpdateDoctorSchedule(schedule *models.DoctorSchedule) error {
-	return s.timeSlotRepo.UpdateDoctorSchedule(schedule)
+// GetDoctorCalendar returns one appointment count per day of the month containing month.
+// Days are taken in month's location, and days without appointments are reported as zero.
+func (s *schedulingService) GetDoctorCalendar(doctorID uint, month time.Time) ([]models.CalendarDayCount, error) {
+	loc := month.Location()
+	from := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, loc)
+	to := from.AddDate(0, 1, 0)
+
+	counts, err := s.appointmentRepo.GetDailyAppointmentCounts(doctorID, from, to, loc.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to count appointments: %w", err)
+	}
+
+	countByDate := make(map[string]int, len(counts))
+	for _, count := range counts {
+		countByDate[count.Date] = count.Count
+	}
+
+	var days []models.CalendarDayCount
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		days = append(days, models.CalendarDayCount{Date: date, Count: countByDate[date]})
+	}
+
+	return days, nil
 }
 
 // Conflict Detection and Resolution
@@ -393,13 +2272,15 @@ func (s *schedulingService) SuggestAlternativeSlots(doctorID uint, preferredTime
 	return suggestions, nil
 }
 
-// AutoRescheduleConflicts automatically reschedules conflicting appointments
-func (s *schedulingService) AutoRescheduleConflicts(doctorID uint, startTime, endTime time.Time) error {
+// AutoRescheduleConflicts automatically reschedules conflicting appointments, recording each
+// notification under the request ID carried by ctx
+func (s *schedulingService) AutoRescheduleConflicts(ctx context.Context, doctorID uint, startTime, endTime time.Time) error {
 	// Get conflicting appointments
 	conflicts, err := s.appointmentRepo.DetectConflicts(doctorID, startTime, endTime, nil)
 	if err != nil {
 		return fmt.Errorf("failed to detect conflicts: %w", err)
 	}
+	notifyCtx := context.WithoutCancel(ctx)
 
 	for _, conflict := range conflicts {
 		// Find alternative slot for each conflict
@@ -417,21 +2298,30 @@ func (s *schedulingService) AutoRescheduleConflicts(doctorID uint, startTime, en
 		newEndTime := alternative.StartTime.Add(time.Duration(conflict.Duration) * time.Minute)
 
 		// Reschedule the appointment
-		if err := s.appointmentRepo.RescheduleAppointment(conflict.ID, alternative.StartTime, newEndTime); err != nil {
+		if _, err := s.rescheduleRecord(conflict.ID, alternative.StartTime, newEndTime); err != nil {
 			utils.LogError(err, "Failed to auto-reschedule appointment", map[string]interface{}{
 				"appointment_id": conflict.ID,
 				"new_start_time": alternative.StartTime,
 			})
 			continue
 		}
+		s.invalidateAppointment(conflict.ID)
+		s.invalidateAvailability(doctorID, conflict.AppointmentTime, conflict.EndTime)
+		s.invalidateAvailability(doctorID, alternative.StartTime, newEndTime)
 
 		// Send notification about auto-rescheduling
 		go func(appointment models.Appointment, newTime time.Time) {
-			if err := s.notificationSvc.SendAutoRescheduleNotification(&appointment, newTime); err != nil {
+			release := acquireNotificationSlot()
+			defer release()
+
+			err := s.notificationSvc.SendAutoRescheduleNotification(&appointment, newTime)
+			if err != nil {
 				utils.LogError(err, "Failed to send auto-reschedule notification", map[string]interface{}{
 					"appointment_id": appointment.ID,
+					"request_id":     utils.RequestIDFromContext(notifyCtx),
 				})
 			}
+			s.recordNotification(notifyCtx, &appointment, models.NotificationAutoReschedule, err)
 		}(conflict, alternative.StartTime)
 	}
 
@@ -440,22 +2330,182 @@ func (s *schedulingService) AutoRescheduleConflicts(doctorID uint, startTime, en
 
 // Time Slot Management
 
-// GenerateTimeSlots generates time slots for a doctor on a specific date
+// GenerateTimeSlots generates time slots for a doctor on a specific date. It fails fast with
+// ErrGenerationInProgress while another generation for the same doctor and date is running.
 func (s *schedulingService) GenerateTimeSlots(doctorID uint, date time.Time) error {
+	unlock, err := s.lockSlotGeneration(doctorID, date)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	defer s.invalidateAvailability(doctorID, date, date)
 	return s.timeSlotRepo.GenerateTimeSlots(doctorID, date)
 }
 
-// GenerateWeeklySlots generates time slots for a doctor for the entire week
-func (s *schedulingService) GenerateWeeklySlots(doctorID uint, startDate time.Time) error {
+// PreviewTimeSlots returns the slots that generating the date would create, without saving them
+func (s *schedulingService) PreviewTimeSlots(doctorID uint, date time.Time) ([]models.TimeSlot, error) {
+	return s.timeSlotRepo.PreviewTimeSlots(doctorID, date)
+}
+
+// GenerateWeeklySlots generates time slots for a doctor for the entire week, returning
+// the outcome for each day alongside an aggregated error if any day failed. Every day of the
+// week is locked first, so it fails fast with ErrGenerationInProgress if any day is being generated.
+func (s *schedulingService) GenerateWeeklySlots(doctorID uint, startDate time.Time) ([]models.DayGenerationResult, error) {
+	unlocks := make([]func(), 0, 7)
+	defer func() {
+		for _, unlock := range unlocks {
+			unlock()
+		}
+	}()
+	for i := 0; i < 7; i++ {
+		unlock, err := s.lockSlotGeneration(doctorID, startDate.AddDate(0, 0, i))
+		if err != nil {
+			return nil, err
+		}
+		unlocks = append(unlocks, unlock)
+	}
+
+	defer s.invalidateAvailability(doctorID, startDate, startDate.AddDate(0, 0, 7))
 	return s.timeSlotRepo.GenerateWeeklySlots(doctorID, startDate)
 }
 
+// ForecastGenerateSlots adds slots for days days from startDate, sized by the doctor's booking
+// history over the last lookbackWeeks weeks (see PlanForecastSlots). Slots already on the calendar
+// are kept and new slots never overlap them; holidays are skipped. Every day is locked first, so
+// it fails fast with ErrGenerationInProgress if any day is being generated.
+func (s *schedulingService) ForecastGenerateSlots(doctorID uint, startDate time.Time, days, lookbackWeeks int) ([]models.ForecastWindow, error) {
+	if days <= 0 || lookbackWeeks <= 0 {
+		return nil, fmt.Errorf("%w: days and lookback weeks must be positive", utils.ErrInvalidInput)
+	}
+
+	unlocks := make([]func(), 0, days)
+	defer func() {
+		for _, unlock := range unlocks {
+			unlock()
+		}
+	}()
+	for i := 0; i < days; i++ {
+		unlock, err := s.lockSlotGeneration(doctorID, startDate.AddDate(0, 0, i))
+		if err != nil {
+			return nil, err
+		}
+		unlocks = append(unlocks, unlock)
+	}
+
+	schedule, err := s.timeSlotRepo.GetDoctorSchedule(doctorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get doctor schedule: %w", err)
+	}
+
+	now := time.Now()
+	demand, err := s.appointmentRepo.GetHourlyDemand(doctorID, now.AddDate(0, 0, -7*lookbackWeeks), now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booking history: %w", err)
+	}
+
+	defer s.invalidateAvailability(doctorID, startDate, startDate.AddDate(0, 0, days))
+
+	var plan []models.ForecastWindow
+	for i := 0; i < days; i++ {
+		date := startDate.AddDate(0, 0, i)
+
+		holiday, err := s.timeSlotRepo.GetHoliday(doctorID, date)
+		if err != nil {
+			return plan, err
+		}
+		if holiday != nil {
+			continue
+		}
+
+		breaks, err := s.timeSlotRepo.GetDoctorBreaks(doctorID, date)
+		if err != nil {
+			return plan, fmt.Errorf("failed to get doctor breaks: %w", err)
+		}
+
+		for _, window := range PlanForecastSlots(date, schedule, demand, lookbackWeeks, breaks) {
+			created, err := s.timeSlotRepo.CreateOverrideSlots(doctorID, window.StartTime, window.EndTime, time.Duration(window.SlotDuration)*time.Minute)
+			if err != nil {
+				return plan, err
+			}
+			window.SlotsCreated = created
+			plan = append(plan, window)
+		}
+	}
+
+	return plan, nil
+}
+
+// lockSlotGeneration takes the distributed lock for generating a doctor's slots on date and
+// returns the function that releases it. Without a cache, or if Redis cannot be reached,
+// generation proceeds unlocked rather than being blocked by a cache outage.
+func (s *schedulingService) lockSlotGeneration(doctorID uint, date time.Time) (func(), error) {
+	if s.cacheService == nil {
+		return func() {}, nil
+	}
+
+	key := fmt.Sprintf("lock:slot-generation:doctor:%d:date:%s", doctorID, date.Format("2006-01-02"))
+	token, acquired, err := s.cacheService.AcquireLock(context.Background(), key, slotGenerationLockTTL)
+	if err != nil {
+		utils.LogWarn("Generating slots without a lock", map[string]interface{}{
+			"doctor_id": doctorID,
+			"date":      date.Format("2006-01-02"),
+			"error":     err.Error(),
+		})
+		return func() {}, nil
+	}
+	if !acquired {
+		return nil, fmt.Errorf("%w: doctor %d on %s", ErrGenerationInProgress, doctorID, date.Format("2006-01-02"))
+	}
+
+	return func() {
+		if err := s.cacheService.ReleaseLock(context.Background(), key, token); err != nil {
+			utils.LogWarn("Failed to release slot generation lock", map[string]interface{}{
+				"key":   key,
+				"error": err.Error(),
+			})
+		}
+	}, nil
+}
+
+// AddAvailabilityOverride adds extra slots for a single date outside the weekly schedule
+func (s *schedulingService) AddAvailabilityOverride(doctorID uint, startTime, endTime time.Time, slotDuration time.Duration) (int, error) {
+	defer s.invalidateAvailability(doctorID, startTime, endTime)
+	return s.timeSlotRepo.CreateOverrideSlots(doctorID, startTime, endTime, slotDuration)
+}
+
 // BlockTimeSlots blocks time slots within a time range
 func (s *schedulingService) BlockTimeSlots(doctorID uint, startTime, endTime time.Time, reason string) error {
+	defer s.invalidateAvailability(doctorID, startTime, endTime)
 	return s.timeSlotRepo.BlockTimeSlots(doctorID, startTime, endTime, reason)
 }
 
 // UnblockTimeSlots unblocks time slots within a time range
 func (s *schedulingService) UnblockTimeSlots(doctorID uint, startTime, endTime time.Time) error {
+	defer s.invalidateAvailability(doctorID, startTime, endTime)
 	return s.timeSlotRepo.UnblockTimeSlots(doctorID, startTime, endTime)
 }
+
+// DeleteUnbookedSlots deletes a doctor's available and blocked slots starting in [from, to),
+// leaving booked slots in place, and returns how many were deleted
+func (s *schedulingService) DeleteUnbookedSlots(doctorID uint, from, to time.Time) (int64, error) {
+	defer s.invalidateAvailability(doctorID, from, to)
+	return s.timeSlotRepo.DeleteUnbookedSlots(doctorID, from, to)
+}
+
+// GetOrphanedSlots returns booked slots whose appointment is missing, deleted or cancelled
+func (s *schedulingService) GetOrphanedSlots() ([]models.TimeSlot, error) {
+	return s.timeSlotRepo.GetOrphanedSlots()
+}
+
+// ReclaimOrphanedSlots frees orphaned booked slots so they can be booked again
+func (s *schedulingService) ReclaimOrphanedSlots() ([]models.TimeSlot, error) {
+	slots, err := s.timeSlotRepo.ReclaimOrphanedSlots()
+	if err != nil {
+		return nil, err
+	}
+	for _, slot := range slots {
+		s.invalidateAvailability(slot.DoctorID, slot.StartTime, slot.EndTime)
+	}
+	return slots, nil
+}
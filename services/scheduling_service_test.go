@@ -0,0 +1,1540 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/repository/repotest"
+	"smart-doctor-booking-app/utils"
+)
+
+// newTestSchedulingService returns a scheduling service over repositories backed by db, without a cache
+func newTestSchedulingService(db *gorm.DB, config SchedulingConfig) SchedulingService {
+	return NewSchedulingServiceWithConfig(
+		repository.NewAppointmentRepository(db),
+		repository.NewTimeSlotRepository(db),
+		NewNotificationService(),
+		nil,
+		config,
+	)
+}
+
+// seedDoctors creates a specialty and an active doctor for each ID
+func seedDoctors(t *testing.T, db *gorm.DB, doctorIDs ...uint) {
+	t.Helper()
+	repotest.MustCreate(t, db, &models.Specialty{ID: 1, Name: "General Practice"})
+	for _, id := range doctorIDs {
+		repotest.MustCreate(t, db, &models.Doctor{ID: id, Name: "Doctor", SpecialtyID: 1, IsActive: true})
+	}
+}
+
+func TestGetMultiDoctorAvailabilityMatchesIndividualCalls(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestSchedulingService(db, DefaultSchedulingConfig())
+	day := repotest.Day(0)
+	seedDoctors(t, db, 1, 2, 3)
+
+	repotest.MustCreate(t, db,
+		repotest.Slot(1, day, 9, 0, 30, models.SlotAvailable),
+		repotest.Slot(1, day, 9, 30, 30, models.SlotAvailable),
+		repotest.Slot(1, day, 10, 0, 30, models.SlotBooked),
+		repotest.Slot(2, day, 14, 0, 30, models.SlotAvailable),
+		repotest.Slot(2, repotest.Day(1), 9, 0, 30, models.SlotAvailable),
+		repotest.Appointment(10, 1, day.Add(10*time.Hour), 30, models.StatusScheduled),
+		repotest.Appointment(11, 1, day.Add(11*time.Hour), 30, models.StatusCancelled),
+		repotest.Appointment(12, 2, day.Add(15*time.Hour), 30, models.StatusConfirmed),
+	)
+
+	doctorIDs := []uint{1, 2, 3}
+	multi, err := service.GetMultiDoctorAvailability(doctorIDs, day)
+	if err != nil {
+		t.Fatalf("GetMultiDoctorAvailability returned error: %v", err)
+	}
+	if len(multi) != len(doctorIDs) {
+		t.Fatalf("expected availability for %d doctors, got %d", len(doctorIDs), len(multi))
+	}
+
+	for _, doctorID := range doctorIDs {
+		single, err := service.GetDoctorAvailability(doctorID, day)
+		if err != nil {
+			t.Fatalf("GetDoctorAvailability(%d) returned error: %v", doctorID, err)
+		}
+		got := multi[doctorID]
+		if got.TotalSlots != single.TotalSlots || got.BookedSlots != single.BookedSlots ||
+			got.AcceptingBookings != single.AcceptingBookings || got.NearlyFull != single.NearlyFull {
+			t.Errorf("doctor %d: multi %+v differs from individual %+v", doctorID, got, single)
+		}
+		if !reflect.DeepEqual(slotIDs(got.AvailableSlots), slotIDs(single.AvailableSlots)) {
+			t.Errorf("doctor %d: multi slots %v, individual slots %v",
+				doctorID, slotIDs(got.AvailableSlots), slotIDs(single.AvailableSlots))
+		}
+	}
+
+	if multi[1].TotalSlots != 2 || multi[1].BookedSlots != 1 {
+		t.Errorf("doctor 1: expected 2 open slots and 1 booking, got %d and %d", multi[1].TotalSlots, multi[1].BookedSlots)
+	}
+	if multi[3].TotalSlots != 0 {
+		t.Errorf("doctor 3: expected no open slots, got %d", multi[3].TotalSlots)
+	}
+}
+
+// slotIDs lists the IDs of slots in order
+func slotIDs(slots []models.TimeSlot) []uint {
+	ids := make([]uint, 0, len(slots))
+	for _, slot := range slots {
+		ids = append(ids, slot.ID)
+	}
+	return ids
+}
+
+func TestRescheduleInPlaceKeepsAppointmentID(t *testing.T) {
+	db := repotest.Open(t)
+	config := DefaultSchedulingConfig()
+	config.RescheduleMode = RescheduleInPlace
+	service := newTestSchedulingService(db, config)
+	day := repotest.Day(0)
+	seedDoctors(t, db, 1)
+
+	appointment := repotest.Appointment(1, 1, day.Add(9*time.Hour), 30, models.StatusScheduled)
+	repotest.MustCreate(t, db, appointment, repotest.Slot(1, day, 14, 0, 30, models.SlotAvailable))
+
+	newStart := day.Add(14 * time.Hour)
+	moved, err := service.RescheduleAppointment(context.Background(), appointment.ID, newStart, newStart.Add(30*time.Minute))
+	if err != nil {
+		t.Fatalf("RescheduleAppointment returned error: %v", err)
+	}
+	if moved.ID != appointment.ID {
+		t.Errorf("expected in-place reschedule to keep ID %d, got %d", appointment.ID, moved.ID)
+	}
+	if !moved.AppointmentTime.Equal(newStart) {
+		t.Errorf("expected the appointment at %v, got %v", newStart, moved.AppointmentTime)
+	}
+}
+
+func TestAutoRescheduleConflictsHonoursRescheduleMode(t *testing.T) {
+	db := repotest.Open(t)
+	config := DefaultSchedulingConfig()
+	config.RescheduleMode = RescheduleInPlace
+	service := newTestSchedulingService(db, config)
+	day := repotest.Day(0)
+	seedDoctors(t, db, 1)
+
+	appointment := repotest.Appointment(1, 1, day.Add(9*time.Hour), 30, models.StatusScheduled)
+	repotest.MustCreate(t, db, appointment, repotest.Slot(1, day, 15, 0, 30, models.SlotAvailable))
+
+	if err := service.AutoRescheduleConflicts(context.Background(), 1, day.Add(8*time.Hour), day.Add(12*time.Hour)); err != nil {
+		t.Fatalf("AutoRescheduleConflicts returned error: %v", err)
+	}
+
+	var appointments []models.Appointment
+	if err := db.Find(&appointments).Error; err != nil {
+		t.Fatalf("failed to load appointments: %v", err)
+	}
+	if len(appointments) != 1 || appointments[0].ID != appointment.ID {
+		t.Fatalf("expected the conflicting appointment to be moved in place, got %d rows", len(appointments))
+	}
+	if !appointments[0].AppointmentTime.Equal(day.Add(15 * time.Hour)) {
+		t.Errorf("expected the appointment moved to 15:00, got %v", appointments[0].AppointmentTime)
+	}
+}
+
+func TestRescheduleReturnsNewAppointment(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestSchedulingService(db, DefaultSchedulingConfig())
+	day := repotest.Day(0)
+	seedDoctors(t, db, 1)
+
+	original := repotest.Appointment(1, 1, day.Add(9*time.Hour), 30, models.StatusScheduled)
+	repotest.MustCreate(t, db, original, repotest.Slot(1, day, 14, 0, 30, models.SlotAvailable))
+
+	newStart := day.Add(14 * time.Hour)
+	moved, err := service.RescheduleAppointment(context.Background(), original.ID, newStart, newStart.Add(30*time.Minute))
+	if err != nil {
+		t.Fatalf("RescheduleAppointment returned error: %v", err)
+	}
+	if moved.ID == original.ID {
+		t.Errorf("expected a fresh appointment ID, got the original %d", original.ID)
+	}
+	if !moved.AppointmentTime.Equal(newStart) || !moved.EndTime.Equal(newStart.Add(30*time.Minute)) {
+		t.Errorf("expected the returned appointment at the new time, got %v-%v", moved.AppointmentTime, moved.EndTime)
+	}
+
+	var stored models.Appointment
+	if err := db.First(&stored, original.ID).Error; err != nil {
+		t.Fatalf("failed to load original appointment: %v", err)
+	}
+	if stored.Status != models.StatusRescheduled || stored.RescheduledTo == nil || *stored.RescheduledTo != moved.ID {
+		t.Errorf("expected the original to point at %d as RESCHEDULED, got %s", moved.ID, stored.Status)
+	}
+}
+
+// attendanceRepository serves fixed attendance stats, since the repository's query uses Postgres
+// interval arithmetic the test database does not support
+type attendanceRepository struct {
+	repository.AppointmentRepository
+	stats map[uint]models.AttendanceStats
+}
+
+func (r *attendanceRepository) GetAttendanceStats(userID uint, lateCancellationWindow time.Duration) (*models.AttendanceStats, error) {
+	stats := r.stats[userID]
+	stats.UserID = userID
+	stats.ComputeRates()
+	return &stats, nil
+}
+
+func TestBookAppointmentRequiresDepositForHighNoShowPatients(t *testing.T) {
+	db := repotest.Open(t)
+	config := DefaultSchedulingConfig()
+	config.DepositNoShowThreshold = 30
+	service := NewSchedulingServiceWithConfig(
+		&attendanceRepository{
+			AppointmentRepository: repository.NewAppointmentRepository(db),
+			stats: map[uint]models.AttendanceStats{
+				1: {Completed: 2, NoShows: 3},
+				2: {Completed: 9, NoShows: 1},
+			},
+		},
+		repository.NewTimeSlotRepository(db),
+		NewNotificationService(),
+		nil,
+		config,
+	)
+	day := repotest.Day(0)
+	seedDoctors(t, db, 1)
+	repotest.MustCreate(t, db,
+		repotest.Slot(1, day, 9, 0, 30, models.SlotAvailable),
+		repotest.Slot(1, day, 10, 0, 30, models.SlotAvailable),
+	)
+
+	unreliable, err := service.BookAppointment(&BookingRequest{
+		UserID: 1, DoctorID: 1, AppointmentTime: day.Add(9 * time.Hour), Duration: 30, AppointmentType: models.TypeConsultation,
+	})
+	if err != nil {
+		t.Fatalf("BookAppointment for the high no-show patient returned error: %v", err)
+	}
+	if !unreliable.DepositRequired || unreliable.Status != models.StatusPendingPayment || unreliable.HoldExpiresAt == nil {
+		t.Errorf("expected a deposit-required hold, got deposit_required=%v status=%s", unreliable.DepositRequired, unreliable.Status)
+	}
+
+	reliable, err := service.BookAppointment(&BookingRequest{
+		UserID: 2, DoctorID: 1, AppointmentTime: day.Add(10 * time.Hour), Duration: 30, AppointmentType: models.TypeConsultation,
+	})
+	if err != nil {
+		t.Fatalf("BookAppointment for the reliable patient returned error: %v", err)
+	}
+	if reliable.DepositRequired || reliable.Status != models.StatusScheduled {
+		t.Errorf("expected a scheduled booking without deposit, got deposit_required=%v status=%s", reliable.DepositRequired, reliable.Status)
+	}
+}
+
+// countingRepository counts appointment loads so tests can tell cache hits from database reads
+type countingRepository struct {
+	repository.AppointmentRepository
+	loads int
+}
+
+func (r *countingRepository) GetAppointmentByID(id uint) (*models.Appointment, error) {
+	r.loads++
+	return r.AppointmentRepository.GetAppointmentByID(id)
+}
+
+// newTestCache returns an in-memory cache service that logs nowhere
+func newTestCache() CacheService {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewMemoryCacheService(CacheConfig{DefaultTTL: time.Hour}, logger)
+}
+
+func TestGetAppointmentIsCachedUntilCancelled(t *testing.T) {
+	db := repotest.Open(t)
+	repo := &countingRepository{AppointmentRepository: repository.NewAppointmentRepository(db)}
+	service := NewSchedulingServiceWithConfig(repo, repository.NewTimeSlotRepository(db), NewNotificationService(), newTestCache(), DefaultSchedulingConfig())
+	seedDoctors(t, db, 1)
+
+	appointment := repotest.Appointment(1, 1, repotest.Day(0).Add(9*time.Hour), 30, models.StatusScheduled)
+	repotest.MustCreate(t, db, appointment)
+
+	for i := 0; i < 2; i++ {
+		if _, err := service.GetAppointment(appointment.ID); err != nil {
+			t.Fatalf("GetAppointment returned error: %v", err)
+		}
+	}
+	if repo.loads != 1 {
+		t.Fatalf("expected the second read to be served from the cache, got %d database loads", repo.loads)
+	}
+
+	if err := service.CancelAppointment(context.Background(), appointment.ID, "patient", models.CancellationPatientRequest, ""); err != nil {
+		t.Fatalf("CancelAppointment returned error: %v", err)
+	}
+
+	loadsBefore := repo.loads
+	cancelled, err := service.GetAppointment(appointment.ID)
+	if err != nil {
+		t.Fatalf("GetAppointment returned error: %v", err)
+	}
+	if repo.loads != loadsBefore+1 {
+		t.Errorf("expected the read after cancelling to go to the database")
+	}
+	if cancelled.Status != models.StatusCancelled {
+		t.Errorf("expected the cancelled status after invalidation, got %s", cancelled.Status)
+	}
+}
+
+func TestBookAppointmentEnforcesActiveAppointmentLimit(t *testing.T) {
+	db := repotest.Open(t)
+	config := DefaultSchedulingConfig()
+	config.MaxActiveAppointments = 2
+	service := newTestSchedulingService(db, config)
+	day := repotest.Day(0)
+	seedDoctors(t, db, 1)
+	for hour := 9; hour < 13; hour++ {
+		repotest.MustCreate(t, db, repotest.Slot(1, day, hour, 0, 30, models.SlotAvailable))
+	}
+
+	book := func(hour int, bypass bool) error {
+		_, err := service.BookAppointment(&BookingRequest{
+			UserID: 1, DoctorID: 1, AppointmentTime: day.Add(time.Duration(hour) * time.Hour), Duration: 30,
+			AppointmentType: models.TypeConsultation, BypassLimits: bypass,
+		})
+		return err
+	}
+
+	for hour := 9; hour < 11; hour++ {
+		if err := book(hour, false); err != nil {
+			t.Fatalf("booking %d:00 within the limit returned error: %v", hour, err)
+		}
+	}
+
+	var limitErr *ActiveAppointmentLimitError
+	if err := book(11, false); !errors.As(err, &limitErr) || limitErr.Limit != 2 {
+		t.Fatalf("expected an ActiveAppointmentLimitError with limit 2, got %v", err)
+	}
+
+	if err := book(12, true); err != nil {
+		t.Errorf("expected an admin booking to bypass the limit, got %v", err)
+	}
+}
+
+// calendarRepository groups appointment times by day in Go, since the repository's query uses
+// Postgres date formatting the test database does not support
+type calendarRepository struct {
+	repository.AppointmentRepository
+	times []time.Time
+}
+
+func (r *calendarRepository) GetDailyAppointmentCounts(doctorID uint, from, to time.Time, timezone string) ([]models.CalendarDayCount, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	countByDate := make(map[string]int)
+	for _, t := range r.times {
+		if !t.Before(from) && t.Before(to) {
+			countByDate[t.In(loc).Format("2006-01-02")]++
+		}
+	}
+
+	var counts []models.CalendarDayCount
+	for date, count := range countByDate {
+		counts = append(counts, models.CalendarDayCount{Date: date, Count: count})
+	}
+	return counts, nil
+}
+
+func TestGetDoctorCalendarMapsCountsToDays(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	repo := &calendarRepository{times: []time.Time{
+		time.Date(2031, time.February, 1, 4, 0, 0, 0, time.UTC),  // January 31 in New York
+		time.Date(2031, time.February, 1, 15, 0, 0, 0, time.UTC), // February 1
+		time.Date(2031, time.February, 1, 16, 0, 0, 0, time.UTC), // February 1
+		time.Date(2031, time.February, 14, 12, 0, 0, 0, time.UTC),
+		time.Date(2031, time.March, 1, 3, 0, 0, 0, time.UTC), // February 28 in New York
+		time.Date(2031, time.March, 1, 6, 0, 0, 0, time.UTC), // March 1 in New York
+	}}
+	service := NewSchedulingServiceWithConfig(repo, nil, NewNotificationService(), nil, DefaultSchedulingConfig())
+
+	days, err := service.GetDoctorCalendar(1, time.Date(2031, time.February, 1, 0, 0, 0, 0, newYork))
+	if err != nil {
+		t.Fatalf("GetDoctorCalendar returned error: %v", err)
+	}
+	if len(days) != 28 {
+		t.Fatalf("expected 28 days for February 2031, got %d", len(days))
+	}
+
+	want := map[string]int{"2031-02-01": 2, "2031-02-14": 1, "2031-02-28": 1}
+	for _, day := range days {
+		if day.Count != want[day.Date] {
+			t.Errorf("%s: expected %d appointments, got %d", day.Date, want[day.Date], day.Count)
+		}
+	}
+	if days[0].Date != "2031-02-01" || days[27].Date != "2031-02-28" {
+		t.Errorf("expected the month to run from 2031-02-01 to 2031-02-28, got %s to %s", days[0].Date, days[27].Date)
+	}
+}
+
+func TestCancelAppointmentValidatesReasonCode(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestSchedulingService(db, DefaultSchedulingConfig())
+	seedDoctors(t, db, 1)
+
+	appointment := repotest.Appointment(1, 1, repotest.Day(0).Add(9*time.Hour), 30, models.StatusScheduled)
+	repotest.MustCreate(t, db, appointment)
+
+	err := service.CancelAppointment(context.Background(), appointment.ID, "PATIENT", models.CancellationReason("CHANGED_MIND"), "")
+	if !errors.Is(err, ErrInvalidCancellationReason) {
+		t.Fatalf("expected ErrInvalidCancellationReason for an unknown code, got %v", err)
+	}
+	var stored models.Appointment
+	if err := db.First(&stored, appointment.ID).Error; err != nil {
+		t.Fatalf("failed to load appointment: %v", err)
+	}
+	if stored.Status != models.StatusScheduled {
+		t.Fatalf("expected an invalid reason to leave the appointment scheduled, got %s", stored.Status)
+	}
+
+	if err := service.CancelAppointment(context.Background(), appointment.ID, "DOCTOR", models.CancellationDoctorUnavailable, "Called away"); err != nil {
+		t.Fatalf("CancelAppointment with a valid code returned error: %v", err)
+	}
+	if err := db.First(&stored, appointment.ID).Error; err != nil {
+		t.Fatalf("failed to load appointment: %v", err)
+	}
+	if stored.Status != models.StatusCancelled || stored.CancellationCode != models.CancellationDoctorUnavailable ||
+		stored.CancellationReason != "Called away" {
+		t.Errorf("expected a DOCTOR_UNAVAILABLE cancellation with detail, got %s %s %q",
+			stored.Status, stored.CancellationCode, stored.CancellationReason)
+	}
+}
+
+func TestShiftAppointmentsReportsTheConflictingOne(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestSchedulingService(db, DefaultSchedulingConfig())
+	day := repotest.Day(0)
+	seedDoctors(t, db, 1)
+
+	var shifted []*models.Appointment
+	for i, minute := range []int{0, 30, 60} {
+		appointment := repotest.Appointment(uint(i+1), 1, day.Add(9*time.Hour+time.Duration(minute)*time.Minute), 30, models.StatusScheduled)
+		repotest.MustCreate(t, db, appointment)
+		slot := repotest.Slot(1, day, 9, minute, 30, models.SlotBooked)
+		slot.AppointmentID = &appointment.ID
+		repotest.MustCreate(t, db, slot)
+		shifted = append(shifted, appointment)
+	}
+	// Another patient holds 10:30, where the 9:30 appointment would land
+	blocker := repotest.Appointment(9, 1, day.Add(10*time.Hour+30*time.Minute), 30, models.StatusScheduled)
+	repotest.MustCreate(t, db, blocker)
+	blockerSlot := repotest.Slot(1, day, 10, 30, 30, models.SlotBooked)
+	blockerSlot.AppointmentID = &blocker.ID
+	repotest.MustCreate(t, db, blockerSlot, repotest.Slot(1, day, 11, 0, 30, models.SlotAvailable))
+
+	results, err := service.ShiftAppointments(context.Background(), 1, day.Add(9*time.Hour), day.Add(10*time.Hour+30*time.Minute), time.Hour)
+	if err != nil {
+		t.Fatalf("ShiftAppointments returned error: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected an outcome for each of the 3 appointments in the window, got %d", len(results))
+	}
+
+	outcomes := make(map[uint]models.ShiftResult, len(results))
+	for _, result := range results {
+		outcomes[result.AppointmentID] = result
+	}
+	if outcome := outcomes[shifted[1].ID]; outcome.Success || outcome.Error == "" {
+		t.Errorf("expected the 9:30 appointment to fail on the 10:30 conflict, got %+v", outcome)
+	}
+	for _, appointment := range []*models.Appointment{shifted[0], shifted[2]} {
+		outcome := outcomes[appointment.ID]
+		if !outcome.Success || !outcome.NewStart.Equal(appointment.AppointmentTime.Add(time.Hour)) {
+			t.Errorf("expected appointment %d to move an hour later, got %+v", appointment.ID, outcome)
+		}
+	}
+
+	var stored models.Appointment
+	if err := db.First(&stored, shifted[1].ID).Error; err != nil {
+		t.Fatalf("failed to load appointment: %v", err)
+	}
+	if stored.Status != models.StatusScheduled || !stored.AppointmentTime.Equal(shifted[1].AppointmentTime) {
+		t.Errorf("expected the conflicting appointment to stay in place, got %s at %v", stored.Status, stored.AppointmentTime)
+	}
+}
+
+func TestGetBlockedPeriodsReturnsBreaksAndBlockedSlots(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestSchedulingService(db, DefaultSchedulingConfig())
+	timeSlotRepo := repository.NewTimeSlotRepository(db)
+	day := repotest.Day(7)
+	seedDoctors(t, db, 1)
+
+	// A weekly lunch break set up the Monday before
+	lunchDay := repotest.Day(0)
+	repotest.MustCreate(t, db, &models.DoctorBreak{
+		DoctorID: 1, Date: lunchDay, StartTime: lunchDay.Add(12 * time.Hour), EndTime: lunchDay.Add(13 * time.Hour),
+		Reason: "Lunch", IsRecurring: true,
+	})
+	for minute := 0; minute < 120; minute += 30 {
+		repotest.MustCreate(t, db, repotest.Slot(1, day, 15+minute/60, minute%60, 30, models.SlotAvailable))
+	}
+	if err := timeSlotRepo.BlockTimeSlots(1, day.Add(15*time.Hour), day.Add(16*time.Hour), "Staff meeting"); err != nil {
+		t.Fatalf("BlockTimeSlots returned error: %v", err)
+	}
+
+	periods, err := service.GetBlockedPeriods(1, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("GetBlockedPeriods returned error: %v", err)
+	}
+	if len(periods) != 3 {
+		t.Fatalf("expected the lunch break and two blocked slots, got %d periods: %+v", len(periods), periods)
+	}
+
+	lunch := periods[0]
+	if lunch.Kind != models.SlotBreak || !lunch.IsRecurring || lunch.Reason != "Lunch" ||
+		!lunch.StartTime.Equal(day.Add(12*time.Hour)) || !lunch.EndTime.Equal(day.Add(13*time.Hour)) {
+		t.Errorf("expected the recurring lunch break from 12:00 to 13:00, got %+v", lunch)
+	}
+	for i, period := range periods[1:] {
+		start := day.Add(15*time.Hour + time.Duration(i)*30*time.Minute)
+		if period.Kind != models.SlotBlocked || period.SlotID == nil || period.Reason != "Staff meeting" || !period.StartTime.Equal(start) {
+			t.Errorf("expected a slot blocked for the staff meeting at %v, got %+v", start, period)
+		}
+	}
+}
+
+func TestChangeAppointmentTypeChecksBookedDuration(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestSchedulingService(db, DefaultSchedulingConfig())
+	appointment := repotest.Appointment(1, 1, repotest.Day(0).Add(9*time.Hour), 60, models.StatusScheduled)
+	repotest.MustCreate(t, db, appointment)
+
+	if _, err := service.ChangeAppointmentType(appointment.ID, models.TypeFollowUp, "doctor:1"); !errors.Is(err, ErrTypeDurationMismatch) {
+		t.Fatalf("expected a 60-minute follow-up to be rejected with ErrTypeDurationMismatch, got %v", err)
+	}
+
+	changed, err := service.ChangeAppointmentType(appointment.ID, models.TypeCheckup, "doctor:1")
+	if err != nil {
+		t.Fatalf("ChangeAppointmentType returned error: %v", err)
+	}
+	if changed.Type != models.TypeCheckup {
+		t.Errorf("expected the appointment to become a checkup, got %s", changed.Type)
+	}
+
+	var audits []models.AppointmentAudit
+	if err := db.Where("appointment_id = ?", appointment.ID).Find(&audits).Error; err != nil {
+		t.Fatalf("failed to load audits: %v", err)
+	}
+	if len(audits) != 1 {
+		t.Fatalf("expected only the accepted change audited, got %d entries", len(audits))
+	}
+	audit := audits[0]
+	if audit.Action != models.AuditTypeChanged || audit.OldValue != string(models.TypeConsultation) ||
+		audit.NewValue != string(models.TypeCheckup) || audit.ChangedBy != "doctor:1" {
+		t.Errorf("unexpected audit entry: %+v", audit)
+	}
+}
+
+// cancellationRecorder reports each cancellation notice it is asked to send
+type cancellationRecorder struct {
+	NotificationService
+	sent chan uint
+}
+
+func (n *cancellationRecorder) SendAppointmentCancellation(appointment *models.Appointment, reason string, alternatives []models.TimeSlot) error {
+	n.sent <- appointment.ID
+	return nil
+}
+
+func TestCancelAppointmentTwiceNotifiesOnce(t *testing.T) {
+	db := repotest.Open(t)
+	notifications := &cancellationRecorder{NotificationService: NewNotificationService(), sent: make(chan uint, 2)}
+	service := NewSchedulingServiceWithConfig(
+		repository.NewAppointmentRepository(db),
+		repository.NewTimeSlotRepository(db),
+		notifications,
+		nil,
+		DefaultSchedulingConfig(),
+	)
+	seedDoctors(t, db, 1)
+	appointment := repotest.Appointment(1, 1, repotest.Day(0).Add(9*time.Hour), 30, models.StatusScheduled)
+	repotest.MustCreate(t, db, appointment)
+
+	for i := 0; i < 2; i++ {
+		if err := service.CancelAppointment(context.Background(), appointment.ID, "PATIENT", models.CancellationPatientRequest, ""); err != nil {
+			t.Fatalf("cancellation %d returned error: %v", i+1, err)
+		}
+	}
+
+	select {
+	case id := <-notifications.sent:
+		if id != appointment.ID {
+			t.Errorf("expected a notice for appointment %d, got %d", appointment.ID, id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a cancellation notice")
+	}
+	select {
+	case <-notifications.sent:
+		t.Error("expected the second cancellation not to notify the patient again")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestBookAppointmentRejectsHoliday(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestSchedulingService(db, DefaultSchedulingConfig())
+	day := repotest.Day(0)
+	seedDoctors(t, db, 1, 2)
+	doctorID := uint(1)
+	repotest.MustCreate(t, db,
+		&models.Holiday{Date: day, Name: "Training day", DoctorID: &doctorID},
+		repotest.Slot(1, day, 9, 0, 30, models.SlotAvailable),
+		repotest.Slot(2, day, 9, 0, 30, models.SlotAvailable),
+	)
+
+	book := func(doctorID uint) error {
+		_, err := service.BookAppointment(&BookingRequest{
+			UserID: 1, DoctorID: doctorID, AppointmentTime: day.Add(9 * time.Hour), Duration: 30,
+			AppointmentType: models.TypeConsultation,
+		})
+		return err
+	}
+
+	if err := book(1); !errors.Is(err, ErrClinicClosed) {
+		t.Fatalf("expected ErrClinicClosed on the doctor's holiday, got %v", err)
+	}
+	if err := book(2); err != nil {
+		t.Errorf("expected another doctor to stay bookable, got %v", err)
+	}
+}
+
+// confirmationRecorder counts the confirmations sent for each appointment
+type confirmationRecorder struct {
+	NotificationService
+	sent map[uint]int
+}
+
+func (n *confirmationRecorder) SendAppointmentConfirmation(appointment *models.Appointment) error {
+	n.sent[appointment.ID]++
+	return nil
+}
+
+func TestResendConfirmationIsThrottled(t *testing.T) {
+	db := repotest.Open(t)
+	notifications := &confirmationRecorder{NotificationService: NewNotificationService(), sent: make(map[uint]int)}
+	config := DefaultSchedulingConfig()
+	service := NewSchedulingServiceWithConfig(
+		repository.NewAppointmentRepository(db),
+		repository.NewTimeSlotRepository(db),
+		notifications,
+		newTestCache(),
+		config,
+	)
+	first := repotest.Appointment(1, 1, repotest.Day(0).Add(9*time.Hour), 30, models.StatusScheduled)
+	second := repotest.Appointment(2, 1, repotest.Day(0).Add(10*time.Hour), 30, models.StatusScheduled)
+	repotest.MustCreate(t, db, first, second)
+
+	ctx := context.Background()
+	if err := service.ResendConfirmation(ctx, first.ID); err != nil {
+		t.Fatalf("ResendConfirmation returned error: %v", err)
+	}
+	if notifications.sent[first.ID] != 1 {
+		t.Fatalf("expected the confirmation resent once, got %d", notifications.sent[first.ID])
+	}
+
+	var throttled *ResendThrottledError
+	if err := service.ResendConfirmation(ctx, first.ID); !errors.As(err, &throttled) {
+		t.Fatalf("expected an immediate second resend to be throttled, got %v", err)
+	}
+	if throttled.RetryAfter <= 0 || throttled.RetryAfter > config.ConfirmationResendInterval {
+		t.Errorf("expected a retry delay within %v, got %v", config.ConfirmationResendInterval, throttled.RetryAfter)
+	}
+	if notifications.sent[first.ID] != 1 {
+		t.Errorf("expected the throttled resend not to be sent, got %d sends", notifications.sent[first.ID])
+	}
+
+	if err := service.ResendConfirmation(ctx, second.ID); err != nil || notifications.sent[second.ID] != 1 {
+		t.Errorf("expected another appointment's resend to go through, got %v and %d sends", err, notifications.sent[second.ID])
+	}
+}
+
+func TestGetDoctorTimeOffReturnsUpcomingRanges(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestSchedulingService(db, DefaultSchedulingConfig())
+	timeSlotRepo := repository.NewTimeSlotRepository(db)
+	seedDoctors(t, db, 1)
+	now := repotest.Day(1).Add(12 * time.Hour)
+
+	for _, offset := range []int{0, 1, 3, 4} {
+		day := repotest.Day(offset)
+		repotest.MustCreate(t, db,
+			repotest.Slot(1, day, 9, 0, 30, models.SlotAvailable),
+			repotest.Slot(1, day, 9, 30, 30, models.SlotAvailable),
+		)
+	}
+	block := func(from, to int, reason string) {
+		t.Helper()
+		if err := timeSlotRepo.BlockTimeSlots(1, repotest.Day(from), repotest.Day(to+1), reason); err != nil {
+			t.Fatalf("BlockTimeSlots returned error: %v", err)
+		}
+	}
+	// Last week's sick day and this morning's block are already over
+	block(0, 0, "Sick leave")
+	block(1, 1, "Paperwork")
+	block(3, 4, "Conference")
+	repotest.MustCreate(t, db, &models.Holiday{Date: repotest.Day(0), Name: "Past holiday"})
+
+	periods, err := service.GetDoctorTimeOff(1, now, 14)
+	if err != nil {
+		t.Fatalf("GetDoctorTimeOff returned error: %v", err)
+	}
+	if len(periods) != 1 {
+		t.Fatalf("expected only the upcoming conference, got %+v", periods)
+	}
+	conference := periods[0]
+	wantStart, wantEnd := repotest.Day(3).Add(9*time.Hour), repotest.Day(4).Add(10*time.Hour)
+	if conference.Reason != "Conference" || !conference.StartTime.Equal(wantStart) || !conference.EndTime.Equal(wantEnd) {
+		t.Errorf("expected the conference from %v to %v as one range, got %+v", wantStart, wantEnd, conference)
+	}
+}
+
+func TestGetReviewEligibleAppointmentsOnlyListsUnreviewedCompleted(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestSchedulingService(db, DefaultSchedulingConfig())
+	seedDoctors(t, db, 1)
+	at := func(hour int) time.Time { return repotest.Day(0).Add(time.Duration(hour) * time.Hour) }
+
+	completed := repotest.Appointment(1, 1, at(9), 30, models.StatusCompleted)
+	reviewed := repotest.Appointment(1, 1, at(8), 30, models.StatusCompleted)
+	scheduled := repotest.Appointment(1, 1, at(10), 30, models.StatusScheduled)
+	othersCompleted := repotest.Appointment(2, 1, at(13), 30, models.StatusCompleted)
+	repotest.MustCreate(t, db,
+		completed,
+		reviewed,
+		scheduled,
+		repotest.Appointment(1, 1, at(11), 30, models.StatusCancelled),
+		repotest.Appointment(1, 1, at(12), 30, models.StatusNoShow),
+		othersCompleted,
+	)
+	if err := service.SubmitReview(&models.Review{AppointmentID: reviewed.ID, UserID: 1, Rating: 4}); err != nil {
+		t.Fatalf("SubmitReview returned error: %v", err)
+	}
+
+	eligible, err := service.GetReviewEligibleAppointments(1)
+	if err != nil {
+		t.Fatalf("GetReviewEligibleAppointments returned error: %v", err)
+	}
+	if len(eligible) != 1 || eligible[0].ID != completed.ID {
+		t.Errorf("expected only the unreviewed completed appointment %d, got %+v", completed.ID, eligible)
+	}
+}
+
+func TestSubmitReviewOnlyForOwnCompletedAppointmentsOnce(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestSchedulingService(db, DefaultSchedulingConfig())
+	seedDoctors(t, db, 1)
+	at := func(hour int) time.Time { return repotest.Day(0).Add(time.Duration(hour) * time.Hour) }
+	completed := repotest.Appointment(1, 1, at(9), 30, models.StatusCompleted)
+	scheduled := repotest.Appointment(1, 1, at(10), 30, models.StatusScheduled)
+	repotest.MustCreate(t, db, completed, scheduled)
+
+	review := &models.Review{AppointmentID: completed.ID, UserID: 1, Rating: 5, Comment: "Thorough and kind"}
+	if err := service.SubmitReview(review); err != nil {
+		t.Fatalf("SubmitReview returned error: %v", err)
+	}
+	if review.ID == 0 || review.DoctorID != 1 {
+		t.Errorf("expected the review saved for doctor 1, got ID %d doctor %d", review.ID, review.DoctorID)
+	}
+
+	tests := []struct {
+		name    string
+		review  models.Review
+		wantErr error
+	}{
+		{"second review", models.Review{AppointmentID: completed.ID, UserID: 1, Rating: 3}, models.ErrAlreadyReviewed},
+		{"another patient's appointment", models.Review{AppointmentID: completed.ID, UserID: 2, Rating: 3}, models.ErrNotReviewable},
+		{"not completed", models.Review{AppointmentID: scheduled.ID, UserID: 1, Rating: 3}, models.ErrNotReviewable},
+		{"rating out of range", models.Review{AppointmentID: scheduled.ID, UserID: 1, Rating: 6}, utils.ErrInvalidInput},
+	}
+	for _, tt := range tests {
+		review := tt.review
+		if err := service.SubmitReview(&review); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestBookAppointmentAppliesClinicReminderDefaults(t *testing.T) {
+	db := repotest.Open(t)
+	config := DefaultSchedulingConfig()
+	config.DefaultReminderType = models.ReminderEmail
+	config.DefaultReminderTime = 120
+	service := newTestSchedulingService(db, config)
+	day := repotest.Day(0)
+	seedDoctors(t, db, 1)
+	repotest.MustCreate(t, db,
+		repotest.Slot(1, day, 9, 0, 30, models.SlotAvailable),
+		repotest.Slot(1, day, 10, 0, 30, models.SlotAvailable),
+	)
+
+	defaulted, err := service.BookAppointment(&BookingRequest{
+		UserID: 1, DoctorID: 1, AppointmentTime: day.Add(9 * time.Hour), Duration: 30, AppointmentType: models.TypeConsultation,
+	})
+	if err != nil {
+		t.Fatalf("BookAppointment returned error: %v", err)
+	}
+	chosen, err := service.BookAppointment(&BookingRequest{
+		UserID: 2, DoctorID: 1, AppointmentTime: day.Add(10 * time.Hour), Duration: 30, AppointmentType: models.TypeConsultation,
+		ReminderType: models.ReminderSMS, ReminderTime: 30,
+	})
+	if err != nil {
+		t.Fatalf("BookAppointment returned error: %v", err)
+	}
+
+	for _, tt := range []struct {
+		id       uint
+		wantType models.ReminderType
+		wantTime int
+	}{
+		{defaulted.ID, models.ReminderEmail, 120},
+		{chosen.ID, models.ReminderSMS, 30},
+	} {
+		var stored models.Appointment
+		if err := db.First(&stored, tt.id).Error; err != nil {
+			t.Fatalf("failed to load appointment: %v", err)
+		}
+		if stored.ReminderType != tt.wantType || stored.ReminderTime != tt.wantTime {
+			t.Errorf("appointment %d: expected a %s reminder %d minutes ahead, got %s %d",
+				tt.id, tt.wantType, tt.wantTime, stored.ReminderType, stored.ReminderTime)
+		}
+	}
+}
+
+func TestBookAppointmentRejectsLunchBreak(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestSchedulingService(db, DefaultSchedulingConfig())
+	day := repotest.Day(0)
+	seedDoctors(t, db, 1)
+	// A stale available slot left over from before the break was added
+	repotest.MustCreate(t, db,
+		&models.DoctorBreak{
+			DoctorID: 1, Date: day, StartTime: day.Add(12 * time.Hour), EndTime: day.Add(13 * time.Hour),
+			Reason: "Lunch",
+		},
+		repotest.Slot(1, day, 12, 30, 30, models.SlotAvailable),
+		repotest.Slot(1, day, 13, 0, 30, models.SlotAvailable),
+	)
+
+	book := func(hour, minute int) error {
+		_, err := service.BookAppointment(&BookingRequest{
+			UserID: 1, DoctorID: 1, AppointmentTime: day.Add(time.Duration(hour*60+minute) * time.Minute), Duration: 30,
+			AppointmentType: models.TypeConsultation,
+		})
+		return err
+	}
+
+	err := book(12, 30)
+	if !errors.Is(err, ErrTimeBlocked) {
+		t.Fatalf("expected a booking into the lunch break to be rejected with ErrTimeBlocked, got %v", err)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "break from 12:00 to 13:00") || !strings.Contains(msg, "Lunch") {
+		t.Errorf("expected the error to name the lunch break, got %q", msg)
+	}
+	if err := book(13, 0); err != nil {
+		t.Errorf("expected the slot after lunch to stay bookable, got %v", err)
+	}
+}
+
+func TestGetBookableWindowsMergesFragmentedSlots(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestSchedulingService(db, DefaultSchedulingConfig())
+	day := repotest.Day(0)
+	seedDoctors(t, db, 1)
+	// Seven free slots, but only two runs long enough for an hour
+	for _, start := range [][2]int{{9, 0}, {9, 30}, {10, 0}, {11, 0}, {13, 0}, {14, 0}, {14, 30}} {
+		repotest.MustCreate(t, db, repotest.Slot(1, day, start[0], start[1], 30, models.SlotAvailable))
+	}
+	repotest.MustCreate(t, db, repotest.Slot(1, day, 15, 0, 30, models.SlotBooked))
+
+	days, err := service.GetBookableWindows(1, day, day.AddDate(0, 0, 1), 60)
+	if err != nil {
+		t.Fatalf("GetBookableWindows returned error: %v", err)
+	}
+	if len(days) != 2 {
+		t.Fatalf("expected 2 days, got %d", len(days))
+	}
+
+	want := []models.BookableWindow{
+		{StartTime: day.Add(9 * time.Hour), EndTime: day.Add(10*time.Hour + 30*time.Minute), Minutes: 90},
+		{StartTime: day.Add(14 * time.Hour), EndTime: day.Add(15 * time.Hour), Minutes: 60},
+	}
+	if days[0].Date != day.Format("2006-01-02") || len(days[0].Windows) != len(want) {
+		t.Fatalf("expected %d windows on %s, got %+v", len(want), day.Format("2006-01-02"), days[0])
+	}
+	for i, window := range days[0].Windows {
+		if !window.StartTime.Equal(want[i].StartTime) || !window.EndTime.Equal(want[i].EndTime) || window.Minutes != want[i].Minutes {
+			t.Errorf("expected window %+v, got %+v", want[i], window)
+		}
+	}
+	if len(days[1].Windows) != 0 {
+		t.Errorf("expected no windows on a day without slots, got %+v", days[1].Windows)
+	}
+}
+
+func TestBookingAndAvailabilityByLocation(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestSchedulingService(db, DefaultSchedulingConfig())
+	day := repotest.Day(0)
+	seedDoctors(t, db, 1)
+	downtown, uptown := uint(1), uint(2)
+	repotest.MustCreate(t, db,
+		&models.Location{ID: downtown, Name: "Downtown"},
+		&models.Location{ID: uptown, Name: "Uptown"},
+	)
+	for hour, location := range map[int]uint{9: downtown, 10: uptown, 11: uptown} {
+		slot := repotest.Slot(1, day, hour, 0, 30, models.SlotAvailable)
+		slot.LocationID = &location
+		repotest.MustCreate(t, db, slot)
+	}
+
+	availability, err := service.GetDoctorAvailability(1, day)
+	if err != nil {
+		t.Fatalf("GetDoctorAvailability returned error: %v", err)
+	}
+	filtered := service.FilterByLocation(availability, uptown)
+	if filtered.TotalSlots != 2 || len(availability.AvailableSlots) != 3 {
+		t.Fatalf("expected 2 of 3 slots at uptown without changing the original, got %d of %d", filtered.TotalSlots, len(availability.AvailableSlots))
+	}
+	for _, slot := range filtered.AvailableSlots {
+		if *slot.LocationID != uptown {
+			t.Errorf("expected only uptown slots, got one at location %d", *slot.LocationID)
+		}
+	}
+
+	book := func(hour int, locationID *uint) (*models.Appointment, error) {
+		return service.BookAppointment(&BookingRequest{
+			UserID: 1, DoctorID: 1, AppointmentTime: day.Add(time.Duration(hour) * time.Hour), Duration: 30,
+			AppointmentType: models.TypeConsultation, LocationID: locationID,
+		})
+	}
+
+	if _, err := book(10, &downtown); !errors.Is(err, models.ErrLocationMismatch) {
+		t.Errorf("expected booking downtown while the doctor is uptown to fail with ErrLocationMismatch, got %v", err)
+	}
+	appointment, err := book(10, &uptown)
+	if err != nil {
+		t.Fatalf("BookAppointment returned error: %v", err)
+	}
+	if appointment.LocationID == nil || *appointment.LocationID != uptown {
+		t.Errorf("expected the appointment at uptown, got %v", appointment.LocationID)
+	}
+	appointment, err = book(9, nil)
+	if err != nil {
+		t.Fatalf("BookAppointment returned error: %v", err)
+	}
+	if appointment.LocationID == nil || *appointment.LocationID != downtown {
+		t.Errorf("expected the appointment to take the slot's downtown location, got %v", appointment.LocationID)
+	}
+}
+
+func TestGetWaitEstimateCountsQueueAhead(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestSchedulingService(db, DefaultSchedulingConfig())
+	day := repotest.Day(0)
+	at := func(hour, minute int) time.Time { return day.Add(time.Duration(hour*60+minute) * time.Minute) }
+	seedDoctors(t, db, 1, 2)
+	repotest.MustCreate(t, db,
+		repotest.Appointment(1, 1, at(9, 0), 30, models.StatusScheduled),   // already over
+		repotest.Appointment(2, 1, at(10, 0), 30, models.StatusScheduled),  // in progress, 20 minutes left
+		repotest.Appointment(3, 1, at(10, 30), 30, models.StatusConfirmed), // waiting
+		repotest.Appointment(4, 1, at(11, 0), 60, models.StatusScheduled),  // waiting
+		repotest.Appointment(5, 1, at(12, 0), 30, models.StatusCancelled),
+		repotest.Appointment(6, 2, at(10, 30), 30, models.StatusScheduled), // another doctor
+	)
+	now := at(10, 10)
+
+	estimate, err := service.GetWaitEstimate(1, now)
+	if err != nil {
+		t.Fatalf("GetWaitEstimate returned error: %v", err)
+	}
+	// 20 minutes left of the current appointment, then two at the 40-minute average
+	if estimate.AppointmentsAhead != 3 || estimate.QueuePosition != 4 || estimate.AverageDuration != 40 {
+		t.Errorf("expected 3 ahead at position 4 averaging 40 minutes, got %+v", estimate)
+	}
+	if estimate.EstimatedWaitMinutes != 100 || !estimate.EstimatedSeenAt.Equal(now.Add(100*time.Minute)) {
+		t.Errorf("expected a 100-minute wait, got %d minutes until %v", estimate.EstimatedWaitMinutes, estimate.EstimatedSeenAt)
+	}
+
+	idle, err := service.GetWaitEstimate(2, at(16, 0))
+	if err != nil {
+		t.Fatalf("GetWaitEstimate returned error: %v", err)
+	}
+	if idle.AppointmentsAhead != 0 || idle.QueuePosition != 1 || idle.EstimatedWaitMinutes != 0 {
+		t.Errorf("expected no wait once the day's appointments are over, got %+v", idle)
+	}
+}
+
+func TestConfirmAppointmentsReportsEachOutcome(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestSchedulingService(db, DefaultSchedulingConfig())
+	at := func(hour int) time.Time { return repotest.Day(0).Add(time.Duration(hour) * time.Hour) }
+	scheduled := repotest.Appointment(1, 1, at(9), 30, models.StatusScheduled)
+	confirmed := repotest.Appointment(2, 1, at(10), 30, models.StatusConfirmed)
+	cancelled := repotest.Appointment(3, 1, at(11), 30, models.StatusCancelled)
+	another := repotest.Appointment(4, 1, at(12), 30, models.StatusScheduled)
+	repotest.MustCreate(t, db, scheduled, confirmed, cancelled, another)
+
+	ids := []uint{scheduled.ID, confirmed.ID, 999, cancelled.ID, another.ID}
+	results := service.ConfirmAppointments(ids, "doctor:1")
+	if len(results) != len(ids) {
+		t.Fatalf("expected a result for each of %d IDs, got %d", len(ids), len(results))
+	}
+
+	want := []struct {
+		success bool
+		status  models.AppointmentStatus
+	}{
+		{true, models.StatusConfirmed},
+		{false, models.StatusConfirmed},
+		{false, ""},
+		{false, models.StatusCancelled},
+		{true, models.StatusConfirmed},
+	}
+	for i, result := range results {
+		if result.AppointmentID != ids[i] || result.Success != want[i].success || result.Status != want[i].status {
+			t.Errorf("result %d: expected appointment %d success=%v status %q, got %+v", i, ids[i], want[i].success, want[i].status, result)
+		}
+		if !result.Success && result.Error == "" {
+			t.Errorf("result %d: expected a reason for the failure", i)
+		}
+	}
+	if results[1].Error != ErrAlreadyConfirmed.Error() {
+		t.Errorf("expected the already-confirmed appointment to report %q, got %q", ErrAlreadyConfirmed, results[1].Error)
+	}
+
+	var stored models.Appointment
+	if err := db.First(&stored, scheduled.ID).Error; err != nil {
+		t.Fatalf("failed to load appointment: %v", err)
+	}
+	if stored.Status != models.StatusConfirmed || stored.ConfirmedBy != "doctor:1" || stored.ConfirmedAt == nil {
+		t.Errorf("expected the appointment confirmed by doctor:1, got status %s by %q at %v", stored.Status, stored.ConfirmedBy, stored.ConfirmedAt)
+	}
+}
+
+// durationSummaryRepository returns a fixed duration summary, since the repository's aggregate
+// uses Postgres percentile_cont, which the test database does not support
+type durationSummaryRepository struct {
+	repository.AppointmentRepository
+	insights models.DurationInsights
+}
+
+func (r *durationSummaryRepository) GetDurationInsights(doctorID uint) (*models.DurationInsights, error) {
+	insights := r.insights
+	return &insights, nil
+}
+
+func TestGetDurationInsightsSuggestsRoundedMedian(t *testing.T) {
+	tests := []struct {
+		name       string
+		sampleSize int
+		median     float64
+		want       int // 0 means no suggestion
+	}{
+		{"rounds up to 5 minutes", 12, 37, 40},
+		{"exact multiple", 8, 30, 30},
+		{"halfway median", 4, 22.5, 25},
+		{"raised to the minimum", 3, 7, MinAppointmentDuration},
+		{"capped at the maximum", 2, 200, MaxAppointmentDuration},
+		{"no history", 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &durationSummaryRepository{insights: models.DurationInsights{DoctorID: 1, SampleSize: tt.sampleSize, Median: tt.median}}
+			service := NewSchedulingServiceWithConfig(repo, nil, NewNotificationService(), nil, DefaultSchedulingConfig())
+
+			insights, err := service.GetDurationInsights(1)
+			if err != nil {
+				t.Fatalf("GetDurationInsights returned error: %v", err)
+			}
+			if tt.want == 0 {
+				if insights.SuggestedSlotDuration != nil {
+					t.Errorf("expected no suggestion without history, got %d", *insights.SuggestedSlotDuration)
+				}
+				return
+			}
+			if insights.SuggestedSlotDuration == nil || *insights.SuggestedSlotDuration != tt.want {
+				t.Errorf("expected a suggested %d minutes for a median of %v, got %v", tt.want, tt.median, insights.SuggestedSlotDuration)
+			}
+		})
+	}
+}
+
+func TestGetUpcomingAppointmentsForUsersMatchesIndividualLookups(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestSchedulingService(db, DefaultSchedulingConfig())
+	at := func(offset, hour int) time.Time { return repotest.Day(offset).Add(time.Duration(hour) * time.Hour) }
+	seedDoctors(t, db, 1, 2)
+	repotest.MustCreate(t, db,
+		repotest.Appointment(1, 2, at(1, 9), 30, models.StatusScheduled),
+		repotest.Appointment(1, 1, at(0, 9), 30, models.StatusScheduled),
+		repotest.Appointment(2, 1, at(0, 10), 30, models.StatusScheduled),
+		repotest.Appointment(2, 1, at(0, 11), 30, models.StatusCancelled),
+		repotest.Appointment(4, 1, at(0, 12), 30, models.StatusScheduled), // not requested
+	)
+
+	userIDs := []uint{1, 2, 3}
+	batch, err := service.GetUpcomingAppointmentsForUsers(userIDs)
+	if err != nil {
+		t.Fatalf("GetUpcomingAppointmentsForUsers returned error: %v", err)
+	}
+	if len(batch) != len(userIDs) {
+		t.Fatalf("expected an entry for each of %d patients, got %d", len(userIDs), len(batch))
+	}
+
+	for _, userID := range userIDs {
+		individual, err := service.GetUpcomingAppointments(userID, 0)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("GetUpcomingAppointments returned error: %v", err)
+		}
+		got, ok := batch[userID]
+		if !ok || got == nil {
+			t.Errorf("expected patient %d in the batch with a non-nil list", userID)
+			continue
+		}
+		if len(got) != len(individual) {
+			t.Errorf("patient %d: expected %d appointments, got %d", userID, len(individual), len(got))
+			continue
+		}
+		for i := range individual {
+			if got[i].ID != individual[i].ID || got[i].Doctor.Name != individual[i].Doctor.Name {
+				t.Errorf("patient %d: expected appointment %d at position %d, got %d", userID, individual[i].ID, i, got[i].ID)
+			}
+		}
+	}
+
+	tooMany := make([]uint, MaxUpcomingBatchSize+1)
+	for i := range tooMany {
+		tooMany[i] = uint(i + 1)
+	}
+	if _, err := service.GetUpcomingAppointmentsForUsers(tooMany); !errors.Is(err, utils.ErrInvalidInput) {
+		t.Errorf("expected an oversized batch to be rejected with ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestAvailabilityFlagsNearlyFullDay(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestSchedulingService(db, DefaultSchedulingConfig())
+	seedDoctors(t, db, 1)
+	busy, quiet := repotest.Day(0), repotest.Day(1)
+	for i := 0; i < 5; i++ {
+		busyStatus, quietStatus := models.SlotBooked, models.SlotAvailable
+		if i == 0 {
+			busyStatus, quietStatus = models.SlotAvailable, models.SlotBooked
+		}
+		for _, day := range []struct {
+			date   time.Time
+			status models.SlotStatus
+		}{{busy, busyStatus}, {quiet, quietStatus}} {
+			repotest.MustCreate(t, db, repotest.Slot(1, day.date, 9+i, 0, 30, day.status))
+			if day.status == models.SlotBooked {
+				repotest.MustCreate(t, db, repotest.Appointment(uint(i+1), 1, day.date.Add(time.Duration(9+i)*time.Hour), 30, models.StatusScheduled))
+			}
+		}
+	}
+
+	tests := []struct {
+		day         time.Time
+		utilization float64
+		nearlyFull  bool
+	}{
+		{busy, 0.8, true},
+		{quiet, 0.2, false},
+		{repotest.Day(2), 0, false},
+	}
+	for _, tt := range tests {
+		availability, err := service.GetDoctorAvailability(1, tt.day)
+		if err != nil {
+			t.Fatalf("GetDoctorAvailability returned error: %v", err)
+		}
+		if availability.Utilization != tt.utilization || availability.NearlyFull != tt.nearlyFull {
+			t.Errorf("%s: expected utilization %v nearly_full=%v, got %v and %v", tt.day.Format("2006-01-02"),
+				tt.utilization, tt.nearlyFull, availability.Utilization, availability.NearlyFull)
+		}
+	}
+}
+
+// failingDayTimeSlotRepository fails slot lookups for one date, as if that day's query timed out
+type failingDayTimeSlotRepository struct {
+	repository.TimeSlotRepository
+	failDay time.Time
+}
+
+func (r *failingDayTimeSlotRepository) GetAvailableSlots(doctorID uint, date time.Time) ([]models.TimeSlot, error) {
+	if date.Equal(r.failDay) {
+		return nil, errors.New("query timed out")
+	}
+	return r.TimeSlotRepository.GetAvailableSlots(doctorID, date)
+}
+
+func TestGetDoctorAvailabilityRangeReportsFailedDates(t *testing.T) {
+	db := repotest.Open(t)
+	service := NewSchedulingServiceWithConfig(
+		repository.NewAppointmentRepository(db),
+		&failingDayTimeSlotRepository{TimeSlotRepository: repository.NewTimeSlotRepository(db), failDay: repotest.Day(1)},
+		NewNotificationService(),
+		nil,
+		DefaultSchedulingConfig(),
+	)
+	seedDoctors(t, db, 1)
+	for offset := 0; offset < 3; offset++ {
+		repotest.MustCreate(t, db, repotest.Slot(1, repotest.Day(offset), 9, 0, 30, models.SlotAvailable))
+	}
+
+	availability, failed, err := service.GetDoctorAvailabilityRange(1, repotest.Day(0), repotest.Day(2))
+	if err != nil {
+		t.Fatalf("GetDoctorAvailabilityRange returned error: %v", err)
+	}
+	if len(failed) != 1 || failed[0] != "2031-03-04" {
+		t.Errorf("expected 2031-03-04 reported as failed, got %v", failed)
+	}
+	if len(availability) != 2 || availability["2031-03-03"] == nil || availability["2031-03-05"] == nil {
+		t.Errorf("expected availability for the other two dates, got %v", availability)
+	}
+
+	// A range where every date fails is an error
+	if _, failed, err := service.GetDoctorAvailabilityRange(1, repotest.Day(1), repotest.Day(1)); err == nil {
+		t.Error("expected an error when no date could be computed")
+	} else if len(failed) != 1 {
+		t.Errorf("expected the failed date reported alongside the error, got %v", failed)
+	}
+}
+
+// changedAppointmentRepository applies the change cursor in Go, since the repository's query uses
+// the Postgres GREATEST function the test database does not support
+type changedAppointmentRepository struct {
+	repository.AppointmentRepository
+	db *gorm.DB
+}
+
+func (r *changedAppointmentRepository) GetAppointmentChanges(userID *uint, since time.Time, limit int) ([]models.Appointment, error) {
+	query := r.db.Unscoped().Order("id ASC")
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	var all []models.Appointment
+	if err := query.Find(&all).Error; err != nil {
+		return nil, err
+	}
+
+	var changed []models.Appointment
+	for _, appointment := range all {
+		if models.NewAppointmentChange(appointment).ChangedAt.After(since) {
+			changed = append(changed, appointment)
+		}
+	}
+	sort.SliceStable(changed, func(i, j int) bool {
+		return models.NewAppointmentChange(changed[i]).ChangedAt.Before(models.NewAppointmentChange(changed[j]).ChangedAt)
+	})
+	if len(changed) > limit {
+		changed = changed[:limit]
+	}
+	return changed, nil
+}
+
+func TestGetAppointmentChangesReturnsOnlyRowsChangedAfterCursor(t *testing.T) {
+	db := repotest.Open(t)
+	service := NewSchedulingServiceWithConfig(
+		&changedAppointmentRepository{AppointmentRepository: repository.NewAppointmentRepository(db), db: db},
+		repository.NewTimeSlotRepository(db),
+		NewNotificationService(),
+		nil,
+		DefaultSchedulingConfig(),
+	)
+	seedDoctors(t, db, 1)
+	cursor := time.Date(2030, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	stale := repotest.Appointment(1, 1, repotest.Day(0).Add(9*time.Hour), 30, models.StatusScheduled)
+	stale.UpdatedAt = cursor.Add(-time.Hour)
+	updated := repotest.Appointment(1, 1, repotest.Day(0).Add(10*time.Hour), 30, models.StatusConfirmed)
+	updated.UpdatedAt = cursor.Add(2 * time.Hour)
+	deleted := repotest.Appointment(1, 1, repotest.Day(0).Add(11*time.Hour), 30, models.StatusScheduled)
+	deleted.UpdatedAt = cursor.Add(-2 * time.Hour)
+	deleted.DeletedAt = gorm.DeletedAt{Time: cursor.Add(time.Hour), Valid: true}
+	otherPatient := repotest.Appointment(2, 1, repotest.Day(0).Add(12*time.Hour), 30, models.StatusScheduled)
+	otherPatient.UpdatedAt = cursor.Add(3 * time.Hour)
+	// Creating stamps updated_at with the current time, so the change times are written afterwards
+	for _, appointment := range []*models.Appointment{stale, updated, deleted, otherPatient} {
+		changedAt := map[string]interface{}{"updated_at": appointment.UpdatedAt, "deleted_at": appointment.DeletedAt}
+		repotest.MustCreate(t, db, appointment)
+		if err := db.Unscoped().Model(appointment).UpdateColumns(changedAt).Error; err != nil {
+			t.Fatalf("failed to backdate appointment: %v", err)
+		}
+	}
+
+	patient := uint(1)
+	changes, err := service.GetAppointmentChanges(&patient, cursor, 0)
+	if err != nil {
+		t.Fatalf("GetAppointmentChanges returned error: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes after the cursor, got %d", len(changes))
+	}
+	if changes[0].ID != deleted.ID || !changes[0].Deleted || !changes[0].ChangedAt.Equal(cursor.Add(time.Hour)) {
+		t.Errorf("expected the deletion first, flagged and dated by its deletion, got %+v", changes[0])
+	}
+	if changes[1].ID != updated.ID || changes[1].Deleted {
+		t.Errorf("expected the update second and not flagged deleted, got %+v", changes[1])
+	}
+
+	all, err := service.GetAppointmentChanges(nil, cursor, 0)
+	if err != nil {
+		t.Fatalf("GetAppointmentChanges returned error: %v", err)
+	}
+	if len(all) != 3 || all[2].ID != otherPatient.ID {
+		t.Errorf("expected every patient's changes without a user filter, got %d", len(all))
+	}
+
+	if _, err := service.GetAppointmentChanges(nil, cursor, MaxAppointmentChangesLimit+1); !errors.Is(err, utils.ErrInvalidInput) {
+		t.Errorf("expected an oversized limit to be rejected, got %v", err)
+	}
+}
+
+// monthlyReliabilityRepository groups appointment outcomes by month in Go, since the repository's
+// query uses Postgres TO_CHAR and aggregate FILTER clauses the test database does not support
+type monthlyReliabilityRepository struct {
+	repository.AppointmentRepository
+	db *gorm.DB
+}
+
+func (r *monthlyReliabilityRepository) GetMonthlyReliability(doctorID uint, from, to time.Time) ([]models.DoctorReliabilityMonth, error) {
+	var appointments []models.Appointment
+	if err := r.db.Where("doctor_id = ? AND appointment_time >= ? AND appointment_time < ? AND status <> ?",
+		doctorID, from, to, models.StatusRescheduled).Order("appointment_time ASC").Find(&appointments).Error; err != nil {
+		return nil, err
+	}
+
+	var months []models.DoctorReliabilityMonth
+	for _, appointment := range appointments {
+		month := appointment.AppointmentTime.Format("2006-01")
+		if len(months) == 0 || months[len(months)-1].Month != month {
+			months = append(months, models.DoctorReliabilityMonth{Month: month})
+		}
+		bucket := &months[len(months)-1]
+		bucket.Total++
+		switch appointment.Status {
+		case models.StatusCancelled:
+			bucket.Cancelled++
+		case models.StatusNoShow:
+			bucket.NoShows++
+		}
+	}
+	return months, nil
+}
+
+func TestGetDoctorReliabilityRatesPerMonth(t *testing.T) {
+	db := repotest.Open(t)
+	service := NewSchedulingServiceWithConfig(
+		&monthlyReliabilityRepository{AppointmentRepository: repository.NewAppointmentRepository(db), db: db},
+		repository.NewTimeSlotRepository(db),
+		NewNotificationService(),
+		nil,
+		DefaultSchedulingConfig(),
+	)
+	seedDoctors(t, db, 1, 2)
+	january := time.Date(2031, time.January, 6, 9, 0, 0, 0, time.UTC)
+	march := time.Date(2031, time.March, 10, 9, 0, 0, 0, time.UTC)
+
+	for i, status := range []models.AppointmentStatus{
+		models.StatusCompleted, models.StatusCompleted, models.StatusCancelled, models.StatusNoShow, models.StatusRescheduled,
+	} {
+		repotest.MustCreate(t, db, repotest.Appointment(uint(i+1), 1, january.AddDate(0, 0, i), 30, status))
+	}
+	for i, status := range []models.AppointmentStatus{models.StatusCompleted, models.StatusCompleted, models.StatusCancelled} {
+		repotest.MustCreate(t, db, repotest.Appointment(uint(i+1), 1, march.AddDate(0, 0, i), 30, status))
+	}
+	// Another doctor's cancellations do not count
+	repotest.MustCreate(t, db, repotest.Appointment(9, 2, january, 30, models.StatusCancelled))
+
+	months, err := service.GetDoctorReliability(1, january, march)
+	if err != nil {
+		t.Fatalf("GetDoctorReliability returned error: %v", err)
+	}
+
+	want := []models.DoctorReliabilityMonth{
+		{Month: "2031-01", Total: 4, Cancelled: 1, NoShows: 1, CancellationRate: 25, NoShowRate: 25},
+		{Month: "2031-02"},
+		{Month: "2031-03", Total: 3, Cancelled: 1, CancellationRate: 33.33},
+	}
+	if !reflect.DeepEqual(months, want) {
+		t.Errorf("expected %+v, got %+v", want, months)
+	}
+}
+
+func TestGetScheduleDiscrepanciesReportsEachKind(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestSchedulingService(db, DefaultSchedulingConfig())
+	day := repotest.Day(0)
+	seedDoctors(t, db, 1)
+	repotest.MustCreate(t, db, &models.DoctorSchedule{
+		DoctorID:     1,
+		Monday:       models.WorkingHours{StartTime: "09:00", EndTime: "12:00"},
+		SlotDuration: 30 * time.Minute,
+	})
+
+	held := repotest.Appointment(1, 1, day.Add(9*time.Hour), 30, models.StatusScheduled)
+	unheld := repotest.Appointment(2, 1, day.Add(10*time.Hour), 30, models.StatusScheduled)
+	afterHours := repotest.Appointment(3, 1, day.Add(13*time.Hour), 30, models.StatusConfirmed)
+	cancelled := repotest.Appointment(4, 1, day.Add(11*time.Hour+30*time.Minute), 30, models.StatusCancelled)
+	repotest.MustCreate(t, db, held, unheld, afterHours, cancelled)
+
+	bookedFor := func(hour, minute int, appointment *models.Appointment) *models.TimeSlot {
+		slot := repotest.Slot(1, day, hour, minute, 30, models.SlotBooked)
+		if appointment != nil {
+			slot.AppointmentID = &appointment.ID
+		}
+		return slot
+	}
+	empty := bookedFor(11, 0, nil)
+	stale := bookedFor(11, 30, cancelled)
+	repotest.MustCreate(t, db, bookedFor(9, 0, held), empty, stale, bookedFor(13, 0, afterHours))
+
+	discrepancies, err := service.GetScheduleDiscrepancies(1, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("GetScheduleDiscrepancies returned error: %v", err)
+	}
+
+	tests := []struct {
+		code          models.DiscrepancyCode
+		appointmentID uint
+		slotID        uint
+	}{
+		{models.DiscrepancyAppointmentWithoutSlot, unheld.ID, 0},
+		{models.DiscrepancySlotWithoutAppointment, 0, empty.ID},
+		{models.DiscrepancySlotWithoutAppointment, cancelled.ID, stale.ID},
+		{models.DiscrepancyOutsideWorkingHours, afterHours.ID, 0},
+	}
+	if len(discrepancies) != len(tests) {
+		t.Fatalf("expected %d discrepancies, got %d: %+v", len(tests), len(discrepancies), discrepancies)
+	}
+	for i, tt := range tests {
+		got := discrepancies[i]
+		if got.Code != tt.code {
+			t.Errorf("discrepancy %d: expected %s, got %s", i, tt.code, got.Code)
+		}
+		if (tt.appointmentID == 0) != (got.AppointmentID == nil) || (got.AppointmentID != nil && *got.AppointmentID != tt.appointmentID) {
+			t.Errorf("discrepancy %d: expected appointment %d, got %v", i, tt.appointmentID, got.AppointmentID)
+		}
+		if (tt.slotID == 0) != (got.SlotID == nil) || (got.SlotID != nil && *got.SlotID != tt.slotID) {
+			t.Errorf("discrepancy %d: expected slot %d, got %v", i, tt.slotID, got.SlotID)
+		}
+	}
+}
+
+func TestGetDoctorAvailabilityAllLocationsLabelsEachSlot(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestSchedulingService(db, DefaultSchedulingConfig())
+	service.SetLocationRepository(repository.NewLocationRepository(db))
+	day := repotest.Day(0)
+	seedDoctors(t, db, 1)
+	north := &models.Location{ID: 1, Name: "North Clinic"}
+	south := &models.Location{ID: 2, Name: "South Clinic"}
+	repotest.MustCreate(t, db, north, south)
+
+	atLocation := func(slot *models.TimeSlot, locationID uint) *models.TimeSlot {
+		slot.LocationID = &locationID
+		return slot
+	}
+	// Mornings at the north clinic and afternoons at the south one, created out of order
+	repotest.MustCreate(t, db,
+		atLocation(repotest.Slot(1, day, 14, 0, 30, models.SlotAvailable), south.ID),
+		atLocation(repotest.Slot(1, day, 9, 0, 30, models.SlotAvailable), north.ID),
+		atLocation(repotest.Slot(1, day, 9, 30, 30, models.SlotBooked), north.ID),
+		atLocation(repotest.Slot(1, day, 10, 0, 30, models.SlotAvailable), north.ID),
+		atLocation(repotest.Slot(1, repotest.Day(1), 9, 0, 30, models.SlotAvailable), north.ID),
+	)
+
+	availability, err := service.GetDoctorAvailabilityAllLocations(1, day)
+	if err != nil {
+		t.Fatalf("GetDoctorAvailabilityAllLocations returned error: %v", err)
+	}
+
+	tests := []struct {
+		hour, minute int
+		location     string
+	}{
+		{9, 0, "North Clinic"},
+		{10, 0, "North Clinic"},
+		{14, 0, "South Clinic"},
+	}
+	if availability.TotalSlots != len(tests) || len(availability.Slots) != len(tests) {
+		t.Fatalf("expected %d available slots, got %d", len(tests), len(availability.Slots))
+	}
+	for i, tt := range tests {
+		slot := availability.Slots[i]
+		start := day.Add(time.Duration(tt.hour)*time.Hour + time.Duration(tt.minute)*time.Minute)
+		if !slot.StartTime.Equal(start) || slot.LocationName != tt.location {
+			t.Errorf("slot %d: expected %s at %s, got %s at %q", i, start.Format("15:04"), tt.location, slot.StartTime.Format("15:04"), slot.LocationName)
+		}
+	}
+}
+
+func TestPausedDoctorRejectsNewBookingsButAllowsCancellation(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestSchedulingService(db, DefaultSchedulingConfig())
+	day := repotest.Day(0)
+	seedDoctors(t, db, 1)
+	repotest.MustCreate(t, db,
+		repotest.Slot(1, day, 9, 0, 30, models.SlotAvailable),
+		repotest.Slot(1, day, 10, 0, 30, models.SlotAvailable),
+	)
+	book := func(hour int) (*models.Appointment, error) {
+		return service.BookAppointment(&BookingRequest{
+			UserID: 1, DoctorID: 1, AppointmentTime: day.Add(time.Duration(hour) * time.Hour), Duration: 30, AppointmentType: models.TypeConsultation,
+		})
+	}
+
+	existing, err := book(9)
+	if err != nil {
+		t.Fatalf("BookAppointment before pausing returned error: %v", err)
+	}
+	doctors := repository.NewDoctorRepository(db)
+	if _, err := doctors.SetAcceptingBookings(1, false); err != nil {
+		t.Fatalf("SetAcceptingBookings returned error: %v", err)
+	}
+
+	if _, err := book(10); !errors.Is(err, models.ErrDoctorNotAcceptingBookings) {
+		t.Fatalf("expected ErrDoctorNotAcceptingBookings while paused, got %v", err)
+	}
+	availability, err := service.GetDoctorAvailability(1, day)
+	if err != nil {
+		t.Fatalf("GetDoctorAvailability returned error: %v", err)
+	}
+	if availability.AcceptingBookings {
+		t.Errorf("expected availability to report bookings paused")
+	}
+
+	if err := service.CancelAppointment(context.Background(), existing.ID, "patient", models.CancellationPatientRequest, ""); err != nil {
+		t.Fatalf("expected an existing appointment to be cancellable while paused, got %v", err)
+	}
+
+	if _, err := doctors.SetAcceptingBookings(1, true); err != nil {
+		t.Fatalf("SetAcceptingBookings returned error: %v", err)
+	}
+	if _, err := book(10); err != nil {
+		t.Errorf("expected booking to succeed once resumed, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"time"
+
+	"smart-doctor-booking-app/models"
+)
+
+const (
+	// forecastHighDemandRatio is the share of a window's regular slots booked on average per week
+	// at or above which the window gets shorter, more numerous slots
+	forecastHighDemandRatio = 0.75
+	// forecastLowDemandRatio is the share below which the window gets longer, fewer slots
+	forecastLowDemandRatio = 0.25
+	// maxForecastSlotDuration caps how long quiet-hour slots grow
+	maxForecastSlotDuration = 60
+)
+
+// PlanForecastSlots splits a day's working hours into one-hour windows and picks each window's slot
+// length from its booking history: half the schedule's slot duration where the hour has been busy,
+// double where it has been quiet, and unchanged otherwise. demand holds lookbackWeeks of bookings
+// per weekday and hour. Windows overlapping a break are left out, and nothing is planned outside
+// working hours. It has no side effects, so the plan can be previewed and tested on its own.
+func PlanForecastSlots(date time.Time, schedule *models.DoctorSchedule, demand []models.HourlyDemand, lookbackWeeks int, breaks []models.DoctorBreak) []models.ForecastWindow {
+	workingHours := schedule.WorkingHoursFor(date.Weekday())
+	if workingHours.StartTime == "" || workingHours.EndTime == "" || lookbackWeeks <= 0 {
+		return nil
+	}
+
+	openAt, err := time.Parse("15:04", workingHours.StartTime)
+	if err != nil {
+		return nil
+	}
+	closeAt, err := time.Parse("15:04", workingHours.EndTime)
+	if err != nil {
+		return nil
+	}
+	dayStart := time.Date(date.Year(), date.Month(), date.Day(), openAt.Hour(), openAt.Minute(), 0, 0, date.Location())
+	dayEnd := time.Date(date.Year(), date.Month(), date.Day(), closeAt.Hour(), closeAt.Minute(), 0, 0, date.Location())
+
+	bookingsByHour := make(map[int]int)
+	for _, hour := range demand {
+		if hour.Weekday == date.Weekday() {
+			bookingsByHour[hour.Hour] += hour.Count
+		}
+	}
+
+	baseDuration := int(schedule.SlotDuration.Minutes())
+	if baseDuration <= 0 {
+		return nil
+	}
+
+	var windows []models.ForecastWindow
+	for start := dayStart; start.Before(dayEnd); {
+		end := time.Date(start.Year(), start.Month(), start.Day(), start.Hour()+1, 0, 0, 0, start.Location())
+		if end.After(dayEnd) {
+			end = dayEnd
+		}
+
+		window := models.ForecastWindow{
+			StartTime:       start,
+			EndTime:         end,
+			SlotDuration:    baseDuration,
+			AverageBookings: float64(bookingsByHour[start.Hour()]) / float64(lookbackWeeks),
+			Demand:          models.ForecastDemandNormal,
+		}
+		start = end
+
+		if overlapsBreak(window, breaks) {
+			continue
+		}
+
+		minutes := int(window.EndTime.Sub(window.StartTime).Minutes())
+		ratio := window.AverageBookings / (float64(minutes) / float64(baseDuration))
+		switch {
+		case ratio >= forecastHighDemandRatio:
+			window.Demand = models.ForecastDemandHigh
+			window.SlotDuration = max(baseDuration/2, MinAppointmentDuration)
+		case ratio < forecastLowDemandRatio:
+			window.Demand = models.ForecastDemandLow
+			window.SlotDuration = min(baseDuration*2, maxForecastSlotDuration)
+		}
+		if window.SlotDuration > minutes {
+			window.SlotDuration = min(baseDuration, minutes)
+		}
+
+		windows = append(windows, window)
+	}
+
+	return windows
+}
+
+// overlapsBreak reports whether the window overlaps any of the breaks
+func overlapsBreak(window models.ForecastWindow, breaks []models.DoctorBreak) bool {
+	for _, doctorBreak := range breaks {
+		if window.StartTime.Before(doctorBreak.EndTime) && window.EndTime.After(doctorBreak.StartTime) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/repository/repotest"
+)
+
+func TestPlanForecastSlotsDensifiesBusyHours(t *testing.T) {
+	monday := repotest.Day(0)
+	schedule := &models.DoctorSchedule{
+		Monday:       models.WorkingHours{StartTime: "09:00", EndTime: "12:00"},
+		SlotDuration: 30 * time.Minute,
+	}
+	// Four weeks of history: 9:00 is always full, 10:00 half booked, 11:00 quiet on Mondays
+	demand := []models.HourlyDemand{
+		{Weekday: time.Monday, Hour: 9, Count: 8},
+		{Weekday: time.Monday, Hour: 10, Count: 4},
+		{Weekday: time.Tuesday, Hour: 11, Count: 8},
+	}
+
+	windows := PlanForecastSlots(monday, schedule, demand, 4, nil)
+	tests := []struct {
+		hour     int
+		demand   models.ForecastDemand
+		duration int
+	}{
+		{9, models.ForecastDemandHigh, 15},
+		{10, models.ForecastDemandNormal, 30},
+		{11, models.ForecastDemandLow, 60},
+	}
+	if len(windows) != len(tests) {
+		t.Fatalf("expected %d windows, got %d", len(tests), len(windows))
+	}
+	for i, tt := range tests {
+		window := windows[i]
+		if !window.StartTime.Equal(monday.Add(time.Duration(tt.hour) * time.Hour)) {
+			t.Errorf("window %d: expected to start at %d:00, got %v", i, tt.hour, window.StartTime)
+		}
+		if window.Demand != tt.demand || window.SlotDuration != tt.duration {
+			t.Errorf("%d:00: expected %s demand with %d-minute slots, got %s with %d", tt.hour, tt.demand, tt.duration, window.Demand, window.SlotDuration)
+		}
+	}
+
+	// A break takes its hour out of the plan, and nothing is planned on a day off
+	lunch := models.DoctorBreak{StartTime: monday.Add(11 * time.Hour), EndTime: monday.Add(11*time.Hour + 30*time.Minute)}
+	if windows := PlanForecastSlots(monday, schedule, demand, 4, []models.DoctorBreak{lunch}); len(windows) != 2 {
+		t.Errorf("expected the hour with a break left out, got %d windows", len(windows))
+	}
+	if windows := PlanForecastSlots(repotest.Day(1), schedule, demand, 4, nil); len(windows) != 0 {
+		t.Errorf("expected no windows outside working hours, got %d", len(windows))
+	}
+}
+
+// hourlyDemandRepository serves fixed booking history, since the repository's query uses Postgres
+// EXTRACT casts the test database does not support
+type hourlyDemandRepository struct {
+	repository.AppointmentRepository
+	demand []models.HourlyDemand
+}
+
+func (r *hourlyDemandRepository) GetHourlyDemand(doctorID uint, from, to time.Time) ([]models.HourlyDemand, error) {
+	return r.demand, nil
+}
+
+func TestForecastGenerateSlotsOpensMoreSlotsInBusyHours(t *testing.T) {
+	db := repotest.Open(t)
+	appointments := &hourlyDemandRepository{
+		AppointmentRepository: repository.NewAppointmentRepository(db),
+		demand: []models.HourlyDemand{
+			{Weekday: time.Monday, Hour: 9, Count: 8},
+			{Weekday: time.Monday, Hour: 10, Count: 4},
+		},
+	}
+	service := NewSchedulingServiceWithConfig(
+		appointments,
+		repository.NewTimeSlotRepository(db),
+		NewNotificationService(),
+		nil,
+		DefaultSchedulingConfig(),
+	)
+	monday := repotest.Day(0)
+	seedDoctors(t, db, 1)
+	repotest.MustCreate(t, db, &models.DoctorSchedule{
+		DoctorID:     1,
+		Monday:       models.WorkingHours{StartTime: "09:00", EndTime: "11:00"},
+		SlotDuration: 30 * time.Minute,
+	})
+
+	plan, err := service.ForecastGenerateSlots(1, monday, 1, 4)
+	if err != nil {
+		t.Fatalf("ForecastGenerateSlots returned error: %v", err)
+	}
+	if len(plan) != 2 || plan[0].SlotsCreated != 4 || plan[1].SlotsCreated != 2 {
+		t.Fatalf("expected 4 slots in the busy hour and 2 in the normal one, got %+v", plan)
+	}
+
+	count := func(from, to time.Time) int64 {
+		var n int64
+		if err := db.Model(&models.TimeSlot{}).Where("doctor_id = ? AND start_time >= ? AND start_time < ?", 1, from, to).Count(&n).Error; err != nil {
+			t.Fatalf("failed to count slots: %v", err)
+		}
+		return n
+	}
+	if busy, normal := count(monday.Add(9*time.Hour), monday.Add(10*time.Hour)), count(monday.Add(10*time.Hour), monday.Add(11*time.Hour)); busy != 4 || normal != 2 {
+		t.Errorf("expected 4 slots from 9:00 and 2 from 10:00 on the calendar, got %d and %d", busy, normal)
+	}
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/sirupsen/logrus"
+
+	"smart-doctor-booking-app/repository"
+)
+
+// blockingSlotRepository holds each generation open until released, so a second generation can
+// be started while the first is still running
+type blockingSlotRepository struct {
+	repository.TimeSlotRepository
+	started chan struct{}
+	release chan struct{}
+}
+
+func (r *blockingSlotRepository) GenerateTimeSlots(doctorID uint, date time.Time) error {
+	r.started <- struct{}{}
+	<-r.release
+	return nil
+}
+
+func TestConcurrentSlotGenerationIsRejected(t *testing.T) {
+	server := miniredis.RunT(t)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	cache := NewCacheService(CacheConfig{RedisAddr: server.Addr(), DefaultTTL: time.Hour}, logger)
+
+	repo := &blockingSlotRepository{started: make(chan struct{}, 1), release: make(chan struct{})}
+	service := NewSchedulingServiceWithConfig(nil, repo, NewNotificationService(), cache, DefaultSchedulingConfig())
+	date := time.Date(2031, 3, 3, 0, 0, 0, 0, time.UTC)
+
+	first := make(chan error, 1)
+	go func() { first <- service.GenerateTimeSlots(1, date) }()
+	<-repo.started
+
+	if err := service.GenerateTimeSlots(1, date); !errors.Is(err, ErrGenerationInProgress) {
+		t.Fatalf("expected the concurrent generation to be rejected with ErrGenerationInProgress, got %v", err)
+	}
+
+	close(repo.release)
+	if err := <-first; err != nil {
+		t.Fatalf("first generation returned error: %v", err)
+	}
+
+	// The lock is released once the first generation finishes
+	if err := service.GenerateTimeSlots(1, date); err != nil {
+		t.Errorf("expected generation to proceed after the first finished, got %v", err)
+	}
+}
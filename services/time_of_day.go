@@ -0,0 +1,78 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"smart-doctor-booking-app/models"
+)
+
+// TimeOfDay names a part of the day that availability can be filtered to
+type TimeOfDay string
+
+const (
+	TimeOfDayMorning   TimeOfDay = "morning"
+	TimeOfDayAfternoon TimeOfDay = "afternoon"
+	TimeOfDayEvening   TimeOfDay = "evening"
+)
+
+// ErrInvalidTimeOfDay is returned when a part of the day is not morning, afternoon or evening
+var ErrInvalidTimeOfDay = errors.New("time of day must be morning, afternoon or evening")
+
+// ParseTimeOfDay parses a part of the day case-insensitively
+func ParseTimeOfDay(value string) (TimeOfDay, error) {
+	switch timeOfDay := TimeOfDay(strings.ToLower(strings.TrimSpace(value))); timeOfDay {
+	case TimeOfDayMorning, TimeOfDayAfternoon, TimeOfDayEvening:
+		return timeOfDay, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrInvalidTimeOfDay, value)
+}
+
+// TimeOfDayBands holds where the afternoon and evening begin, as offsets from midnight. Morning
+// runs from midnight to AfternoonStart and evening from EveningStart to midnight.
+type TimeOfDayBands struct {
+	AfternoonStart time.Duration
+	EveningStart   time.Duration
+}
+
+// DefaultTimeOfDayBands returns noon and 5 PM as the afternoon and evening boundaries
+func DefaultTimeOfDayBands() TimeOfDayBands {
+	return TimeOfDayBands{
+		AfternoonStart: 12 * time.Hour,
+		EveningStart:   17 * time.Hour,
+	}
+}
+
+// Contains reports whether t falls within the part of the day, read on t's own clock
+func (b TimeOfDayBands) Contains(timeOfDay TimeOfDay, t time.Time) bool {
+	sinceMidnight := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	switch timeOfDay {
+	case TimeOfDayMorning:
+		return sinceMidnight < b.AfternoonStart
+	case TimeOfDayAfternoon:
+		return sinceMidnight >= b.AfternoonStart && sinceMidnight < b.EveningStart
+	case TimeOfDayEvening:
+		return sinceMidnight >= b.EveningStart
+	}
+	return false
+}
+
+// FilterByTimeOfDay returns a copy of the availability keeping only slots that start in the part
+// of the day configured by SchedulingConfig.TimeOfDayBands
+func (s *schedulingService) FilterByTimeOfDay(availability *models.AvailabilityResponse, timeOfDay TimeOfDay) *models.AvailabilityResponse {
+	if availability == nil {
+		return nil
+	}
+
+	filtered := *availability
+	filtered.AvailableSlots = make([]models.TimeSlot, 0, len(availability.AvailableSlots))
+	for _, slot := range availability.AvailableSlots {
+		if s.config.TimeOfDayBands.Contains(timeOfDay, slot.StartTime) {
+			filtered.AvailableSlots = append(filtered.AvailableSlots, slot)
+		}
+	}
+	filtered.TotalSlots = len(filtered.AvailableSlots)
+	return &filtered
+}
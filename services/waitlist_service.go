@@ -0,0 +1,319 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/utils"
+)
+
+var (
+	// ErrOfferExpired is returned when a waitlist offer is accepted after its deadline or a second time
+	ErrOfferExpired = errors.New("waitlist offer is no longer available")
+	// ErrOfferNotOwned is returned when a patient tries to accept another patient's offer
+	ErrOfferNotOwned = errors.New("waitlist offer belongs to another patient")
+	// ErrEntryNotOwned is returned when a patient tries to book another patient's waitlist entry
+	ErrEntryNotOwned = errors.New("waitlist entry belongs to another patient")
+)
+
+// WaitlistService manages the waitlist and offers opened slots to waiting patients in order
+type WaitlistService interface {
+	SlotReleaseListener
+
+	JoinWaitlist(entry *models.WaitlistEntry) error
+	OfferSlot(ctx context.Context, doctorID uint, startTime, endTime time.Time) (*models.WaitlistOffer, error)
+	AcceptOffer(ctx context.Context, token string, userID uint) (*models.Appointment, error)
+	BookEntry(ctx context.Context, entryID, userID uint) (*models.Appointment, error)
+	ExpireOffers(now time.Time) (int, error)
+	StartSweeper(ctx context.Context, interval time.Duration)
+	SetNotificationLog(log repository.NotificationLogRepository)
+}
+
+// WaitlistConfig holds waitlist configuration
+type WaitlistConfig struct {
+	// OfferTTL is how long a patient has to accept an offered slot before it moves to the next patient
+	OfferTTL time.Duration
+}
+
+// DefaultWaitlistConfig returns default waitlist configuration
+func DefaultWaitlistConfig() WaitlistConfig {
+	return WaitlistConfig{
+		OfferTTL: 15 * time.Minute,
+	}
+}
+
+// waitlistService implements WaitlistService
+type waitlistService struct {
+	waitlistRepo      repository.WaitlistRepository
+	schedulingService SchedulingService
+	notificationSvc   NotificationService
+	featureFlags      FeatureFlags
+	config            WaitlistConfig
+
+	// notificationLog records the offers sent to patients; nil disables recording
+	notificationLog repository.NotificationLogRepository
+}
+
+// NewWaitlistService creates a new waitlist service. Opened slots are only offered while the
+// waitlist feature flag is enabled.
+func NewWaitlistService(
+	waitlistRepo repository.WaitlistRepository,
+	schedulingService SchedulingService,
+	notificationSvc NotificationService,
+	featureFlags FeatureFlags,
+	config WaitlistConfig,
+) WaitlistService {
+	return &waitlistService{
+		waitlistRepo:      waitlistRepo,
+		schedulingService: schedulingService,
+		notificationSvc:   notificationSvc,
+		featureFlags:      featureFlags,
+		config:            config,
+	}
+}
+
+// JoinWaitlist adds a patient to a doctor's waitlist
+func (s *waitlistService) JoinWaitlist(entry *models.WaitlistEntry) error {
+	if entry == nil {
+		return errors.New("waitlist entry cannot be nil")
+	}
+	if entry.UserID == 0 || entry.DoctorID == 0 {
+		return errors.New("user ID and doctor ID are required")
+	}
+
+	entry.Status = models.WaitlistWaiting
+	return s.waitlistRepo.CreateEntry(entry)
+}
+
+// SetNotificationLog sets where sent offers are recorded
+func (s *waitlistService) SetNotificationLog(log repository.NotificationLogRepository) {
+	s.notificationLog = log
+}
+
+// SlotReleased offers a freed appointment time to the next waiting patient
+func (s *waitlistService) SlotReleased(ctx context.Context, doctorID uint, startTime, endTime time.Time) {
+	if !s.featureFlags.IsEnabled(ctx, FlagWaitlist) {
+		return
+	}
+
+	if _, err := s.OfferSlot(ctx, doctorID, startTime, endTime); err != nil {
+		utils.LogError(err, "Failed to offer released slot to waitlist", map[string]interface{}{
+			"doctor_id":  doctorID,
+			"start_time": startTime,
+			"request_id": utils.RequestIDFromContext(ctx),
+		})
+	}
+}
+
+// OfferSlot offers the time to the longest-waiting patient it suits, returning nil when nobody
+// is waiting or the slot has already started. The offer is recorded under the request ID carried by ctx.
+func (s *waitlistService) OfferSlot(ctx context.Context, doctorID uint, startTime, endTime time.Time) (*models.WaitlistOffer, error) {
+	if !startTime.After(time.Now()) {
+		return nil, nil
+	}
+
+	entry, err := s.waitlistRepo.GetNextWaitingEntry(doctorID, startTime, int(endTime.Sub(startTime).Minutes()))
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	token, err := newOfferToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate offer token: %w", err)
+	}
+
+	offer := &models.WaitlistOffer{
+		Token:     token,
+		EntryID:   entry.ID,
+		UserID:    entry.UserID,
+		DoctorID:  doctorID,
+		StartTime: startTime,
+		EndTime:   startTime.Add(time.Duration(entry.Duration) * time.Minute),
+		ExpiresAt: time.Now().Add(s.config.OfferTTL),
+		Status:    models.OfferPending,
+	}
+	if err := s.waitlistRepo.CreateOffer(offer); err != nil {
+		return nil, err
+	}
+
+	notifyCtx := context.WithoutCancel(ctx)
+	offerID := offer.ID
+	go func() {
+		release := acquireNotificationSlot()
+		defer release()
+
+		err := s.notificationSvc.SendWaitlistOffer(offer)
+		if err != nil {
+			utils.LogError(err, "Failed to send waitlist offer", map[string]interface{}{
+				"offer_id":   offer.ID,
+				"user_id":    offer.UserID,
+				"request_id": utils.RequestIDFromContext(notifyCtx),
+			})
+		}
+		saveNotificationLog(notifyCtx, s.notificationLog, &models.NotificationLog{
+			OfferID: &offerID,
+			UserID:  offer.UserID,
+			Kind:    models.NotificationWaitlistOffer,
+			Channel: models.ReminderSMS,
+		}, err)
+	}()
+
+	utils.LogInfo("Waitlist offer created", map[string]interface{}{
+		"offer_id":   offer.ID,
+		"entry_id":   entry.ID,
+		"user_id":    entry.UserID,
+		"doctor_id":  doctorID,
+		"expires_at": offer.ExpiresAt,
+	})
+
+	return offer, nil
+}
+
+// AcceptOffer books the offered slot for the patient holding the token
+func (s *waitlistService) AcceptOffer(ctx context.Context, token string, userID uint) (*models.Appointment, error) {
+	offer, err := s.waitlistRepo.GetOfferByToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if offer.UserID != userID {
+		return nil, ErrOfferNotOwned
+	}
+
+	claimed, err := s.waitlistRepo.ClaimOffer(offer.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrOfferExpired
+	}
+
+	return s.bookClaimedOffer(ctx, offer, false)
+}
+
+// bookClaimedOffer books the slot of an offer the patient has claimed through the scheduling
+// service, so it gets the same checks and confirmation as any other booking. The offer is completed
+// in the booking's transaction, removing the entry from the waitlist with removeEntry. If booking
+// fails nothing is booked, the offer is expired and the patient goes back into the queue.
+func (s *waitlistService) bookClaimedOffer(ctx context.Context, offer *models.WaitlistOffer, removeEntry bool) (*models.Appointment, error) {
+	appointment, err := s.schedulingService.BookAppointment(&BookingRequest{
+		UserID:          offer.UserID,
+		DoctorID:        offer.DoctorID,
+		AppointmentTime: offer.StartTime,
+		Duration:        int(offer.EndTime.Sub(offer.StartTime).Minutes()),
+		AppointmentType: models.TypeConsultation,
+		Context:         ctx,
+		InTransaction:   s.waitlistRepo.CompleteOfferStep(offer, removeEntry),
+	})
+	if err != nil {
+		// The slot was taken by another route; keep the patient's place in the queue
+		if returnErr := s.waitlistRepo.ReturnOfferToQueue(offer); returnErr != nil {
+			utils.LogError(returnErr, "Failed to return waitlist offer to queue", map[string]interface{}{
+				"offer_id": offer.ID,
+			})
+		}
+		return nil, fmt.Errorf("failed to book offered slot: %w", err)
+	}
+
+	return appointment, nil
+}
+
+// BookEntry turns a waitlist entry with a pending offer into an appointment and removes the entry
+// from the waitlist. The slot is booked through the scheduling service like any other booking, and
+// the conflict check, booking and removal from the waitlist share one transaction; if the slot was
+// taken in the meantime the patient keeps their place in the queue.
+func (s *waitlistService) BookEntry(ctx context.Context, entryID, userID uint) (*models.Appointment, error) {
+	entry, err := s.waitlistRepo.GetEntryByID(entryID)
+	if err != nil {
+		return nil, err
+	}
+	if entry.UserID != userID {
+		return nil, ErrEntryNotOwned
+	}
+
+	now := time.Now()
+	offer, err := s.waitlistRepo.GetPendingOffer(entry.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if offer == nil {
+		return nil, ErrOfferExpired
+	}
+
+	claimed, err := s.waitlistRepo.ClaimOffer(offer.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrOfferExpired
+	}
+
+	appointment, err := s.bookClaimedOffer(ctx, offer, true)
+	if err != nil {
+		return nil, err
+	}
+
+	utils.LogInfo("Waitlist entry booked", map[string]interface{}{
+		"entry_id":       entry.ID,
+		"offer_id":       offer.ID,
+		"appointment_id": appointment.ID,
+		"user_id":        userID,
+	})
+
+	return appointment, nil
+}
+
+// ExpireOffers expires lapsed offers and passes each slot on to the next waiting patient.
+// It returns how many offers expired.
+func (s *waitlistService) ExpireOffers(now time.Time) (int, error) {
+	expired, err := s.waitlistRepo.ExpireOffers(now)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, offer := range expired {
+		if _, err := s.OfferSlot(context.Background(), offer.DoctorID, offer.StartTime, offer.EndTime); err != nil {
+			utils.LogError(err, "Failed to pass expired waitlist offer to next patient", map[string]interface{}{
+				"offer_id":  offer.ID,
+				"doctor_id": offer.DoctorID,
+			})
+		}
+	}
+
+	return len(expired), nil
+}
+
+// StartSweeper expires lapsed offers every interval until the context is cancelled
+func (s *waitlistService) StartSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if _, err := s.ExpireOffers(now); err != nil {
+					utils.LogError(err, "Waitlist offer sweep failed", nil)
+				}
+			}
+		}
+	}()
+}
+
+// newOfferToken generates a random 32-byte hex offer token
+func newOfferToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
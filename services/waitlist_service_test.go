@@ -0,0 +1,254 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-doctor-booking-app/models"
+	"smart-doctor-booking-app/repository"
+	"smart-doctor-booking-app/repository/repotest"
+)
+
+// newTestWaitlistService returns a waitlist service over db with the waitlist flag enabled
+func newTestWaitlistService(db *gorm.DB) WaitlistService {
+	flags := NewFeatureFlags(FeatureFlagsConfig{Defaults: map[FeatureFlag]bool{FlagWaitlist: true}}, nil)
+	return NewWaitlistService(
+		repository.NewWaitlistRepository(db),
+		newTestSchedulingService(db, DefaultSchedulingConfig()),
+		NewNotificationService(),
+		flags,
+		DefaultWaitlistConfig(),
+	)
+}
+
+// seedWaitlist adds a waiting entry for each user, longest-waiting first
+func seedWaitlist(t *testing.T, db *gorm.DB, doctorID uint, userIDs ...uint) []*models.WaitlistEntry {
+	t.Helper()
+	joined := time.Now().Add(-time.Hour)
+	var entries []*models.WaitlistEntry
+	for i, userID := range userIDs {
+		entry := &models.WaitlistEntry{
+			UserID:    userID,
+			DoctorID:  doctorID,
+			Duration:  30,
+			Status:    models.WaitlistWaiting,
+			CreatedAt: joined.Add(time.Duration(i) * time.Minute),
+		}
+		repotest.MustCreate(t, db, entry)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestAcceptOfferWithinWindowBooksSlot(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestWaitlistService(db)
+	day := repotest.Day(0)
+	seedDoctors(t, db, 1)
+	seedWaitlist(t, db, 1, 5)
+	repotest.MustCreate(t, db, repotest.Slot(1, day, 9, 0, 30, models.SlotAvailable))
+
+	start := day.Add(9 * time.Hour)
+	offer, err := service.OfferSlot(context.Background(), 1, start, start.Add(30*time.Minute))
+	if err != nil {
+		t.Fatalf("OfferSlot returned error: %v", err)
+	}
+	if offer == nil || offer.UserID != 5 {
+		t.Fatalf("expected the slot offered to user 5, got %+v", offer)
+	}
+
+	if _, err := service.AcceptOffer(context.Background(), offer.Token, 6); !errors.Is(err, ErrOfferNotOwned) {
+		t.Errorf("expected another patient's acceptance to be refused, got %v", err)
+	}
+
+	appointment, err := service.AcceptOffer(context.Background(), offer.Token, 5)
+	if err != nil {
+		t.Fatalf("AcceptOffer returned error: %v", err)
+	}
+	if appointment.UserID != 5 || !appointment.AppointmentTime.Equal(start) {
+		t.Errorf("expected user 5 booked at %v, got user %d at %v", start, appointment.UserID, appointment.AppointmentTime)
+	}
+
+	if _, err := service.AcceptOffer(context.Background(), offer.Token, 5); !errors.Is(err, ErrOfferExpired) {
+		t.Errorf("expected a second acceptance to be refused, got %v", err)
+	}
+}
+
+func TestExpiredOfferAdvancesToNextPatient(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestWaitlistService(db)
+	day := repotest.Day(0)
+	seedDoctors(t, db, 1)
+	seedWaitlist(t, db, 1, 5, 6)
+
+	start := day.Add(9 * time.Hour)
+	first, err := service.OfferSlot(context.Background(), 1, start, start.Add(30*time.Minute))
+	if err != nil {
+		t.Fatalf("OfferSlot returned error: %v", err)
+	}
+	if first == nil || first.UserID != 5 {
+		t.Fatalf("expected the slot offered to user 5 first, got %+v", first)
+	}
+
+	expired, err := service.ExpireOffers(time.Now().Add(DefaultWaitlistConfig().OfferTTL + time.Minute))
+	if err != nil {
+		t.Fatalf("ExpireOffers returned error: %v", err)
+	}
+	if expired != 1 {
+		t.Fatalf("expected 1 expired offer, got %d", expired)
+	}
+
+	var next models.WaitlistOffer
+	if err := db.Where("status = ?", models.OfferPending).First(&next).Error; err != nil {
+		t.Fatalf("expected a pending offer for the next patient: %v", err)
+	}
+	if next.UserID != 6 || !next.StartTime.Equal(start) {
+		t.Errorf("expected the slot offered to user 6, got user %d at %v", next.UserID, next.StartTime)
+	}
+
+	if _, err := service.AcceptOffer(context.Background(), first.Token, 5); !errors.Is(err, ErrOfferExpired) {
+		t.Errorf("expected the expired offer to be refused, got %v", err)
+	}
+}
+
+func TestBookEntryConvertsOfferToAppointment(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestWaitlistService(db)
+	day := repotest.Day(0)
+	seedDoctors(t, db, 1)
+	entry := seedWaitlist(t, db, 1, 5)[0]
+	repotest.MustCreate(t, db, repotest.Slot(1, day, 9, 0, 30, models.SlotAvailable))
+
+	start := day.Add(9 * time.Hour)
+	if _, err := service.OfferSlot(context.Background(), 1, start, start.Add(30*time.Minute)); err != nil {
+		t.Fatalf("OfferSlot returned error: %v", err)
+	}
+
+	if _, err := service.BookEntry(context.Background(), entry.ID, 6); !errors.Is(err, ErrEntryNotOwned) {
+		t.Errorf("expected booking another patient's entry to be refused, got %v", err)
+	}
+
+	appointment, err := service.BookEntry(context.Background(), entry.ID, 5)
+	if err != nil {
+		t.Fatalf("BookEntry returned error: %v", err)
+	}
+	if appointment.UserID != 5 || !appointment.AppointmentTime.Equal(start) {
+		t.Errorf("expected user 5 booked at %v, got user %d at %v", start, appointment.UserID, appointment.AppointmentTime)
+	}
+
+	var remaining int64
+	if err := db.Model(&models.WaitlistEntry{}).Where("id = ?", entry.ID).Count(&remaining).Error; err != nil {
+		t.Fatalf("failed to count waitlist entries: %v", err)
+	}
+	if remaining != 0 {
+		t.Error("expected the booked entry to be removed from the waitlist")
+	}
+	var offer models.WaitlistOffer
+	if err := db.Where("entry_id = ?", entry.ID).First(&offer).Error; err != nil {
+		t.Fatalf("failed to load offer: %v", err)
+	}
+	if offer.Status != models.OfferAccepted || offer.AppointmentID == nil || *offer.AppointmentID != appointment.ID {
+		t.Errorf("expected the offer accepted for appointment %d, got %+v", appointment.ID, offer)
+	}
+}
+
+func TestBookEntryKeepsPlaceWhenSlotTaken(t *testing.T) {
+	db := repotest.Open(t)
+	service := newTestWaitlistService(db)
+	day := repotest.Day(0)
+	seedDoctors(t, db, 1)
+	entry := seedWaitlist(t, db, 1, 5)[0]
+	repotest.MustCreate(t, db, repotest.Slot(1, day, 9, 0, 30, models.SlotAvailable))
+
+	start := day.Add(9 * time.Hour)
+	if _, err := service.OfferSlot(context.Background(), 1, start, start.Add(30*time.Minute)); err != nil {
+		t.Fatalf("OfferSlot returned error: %v", err)
+	}
+	// Another patient books the slot directly before the offer is taken up
+	if _, err := newTestSchedulingService(db, DefaultSchedulingConfig()).BookAppointment(&BookingRequest{
+		UserID: 7, DoctorID: 1, AppointmentTime: start, Duration: 30, AppointmentType: models.TypeConsultation,
+	}); err != nil {
+		t.Fatalf("BookAppointment returned error: %v", err)
+	}
+
+	if _, err := service.BookEntry(context.Background(), entry.ID, 5); err == nil {
+		t.Fatal("expected booking a taken slot to fail")
+	}
+
+	var stored models.WaitlistEntry
+	if err := db.First(&stored, entry.ID).Error; err != nil {
+		t.Fatalf("expected the entry to stay on the waitlist: %v", err)
+	}
+	if stored.Status != models.WaitlistWaiting {
+		t.Errorf("expected the patient back in the queue, got %s", stored.Status)
+	}
+	var appointments int64
+	if err := db.Model(&models.Appointment{}).Where("user_id = ?", 5).Count(&appointments).Error; err != nil {
+		t.Fatalf("failed to count appointments: %v", err)
+	}
+	if appointments != 0 {
+		t.Errorf("expected no appointment for the waitlisted patient, got %d", appointments)
+	}
+}
+
+// failingCompletionRepository fails the waitlist update made inside the booking's transaction
+type failingCompletionRepository struct {
+	repository.WaitlistRepository
+}
+
+func (r *failingCompletionRepository) CompleteOfferStep(offer *models.WaitlistOffer, removeEntry bool) repository.BookingStep {
+	return func(tx *gorm.DB, appointment *models.Appointment) error {
+		return errors.New("waitlist update failed")
+	}
+}
+
+func TestBookEntryRollsBackBookingWhenWaitlistUpdateFails(t *testing.T) {
+	db := repotest.Open(t)
+	flags := NewFeatureFlags(FeatureFlagsConfig{Defaults: map[FeatureFlag]bool{FlagWaitlist: true}}, nil)
+	service := NewWaitlistService(
+		&failingCompletionRepository{WaitlistRepository: repository.NewWaitlistRepository(db)},
+		newTestSchedulingService(db, DefaultSchedulingConfig()),
+		NewNotificationService(),
+		flags,
+		DefaultWaitlistConfig(),
+	)
+	day := repotest.Day(0)
+	seedDoctors(t, db, 1)
+	entry := seedWaitlist(t, db, 1, 5)[0]
+	repotest.MustCreate(t, db, repotest.Slot(1, day, 9, 0, 30, models.SlotAvailable))
+
+	start := day.Add(9 * time.Hour)
+	if _, err := service.OfferSlot(context.Background(), 1, start, start.Add(30*time.Minute)); err != nil {
+		t.Fatalf("OfferSlot returned error: %v", err)
+	}
+
+	if _, err := service.BookEntry(context.Background(), entry.ID, 5); err == nil {
+		t.Fatal("expected BookEntry to fail when the waitlist cannot be updated")
+	}
+
+	var appointments int64
+	if err := db.Model(&models.Appointment{}).Count(&appointments).Error; err != nil {
+		t.Fatalf("failed to count appointments: %v", err)
+	}
+	if appointments != 0 {
+		t.Errorf("expected the booking rolled back, got %d appointments", appointments)
+	}
+	var slot models.TimeSlot
+	if err := db.First(&slot).Error; err != nil {
+		t.Fatalf("failed to load slot: %v", err)
+	}
+	if slot.Status != models.SlotAvailable || slot.AppointmentID != nil {
+		t.Errorf("expected the slot to stay available, got %s", slot.Status)
+	}
+	var stored models.WaitlistEntry
+	if err := db.First(&stored, entry.ID).Error; err != nil {
+		t.Fatalf("expected the entry to stay on the waitlist: %v", err)
+	}
+	if stored.Status != models.WaitlistWaiting {
+		t.Errorf("expected the patient back in the queue, got %s", stored.Status)
+	}
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// ErrInvalidInput marks an error caused by bad caller input, whether caught by validation or by the
+// database rejecting a write for violating a CHECK or foreign key constraint. Handlers report it as 400.
+var ErrInvalidInput = errors.New("invalid input")
+
+// sqlStateError is implemented by database driver errors that carry a SQLSTATE code
+type sqlStateError interface {
+	SQLState() string
+}
+
+// IsTransientError reports whether err comes from a dependency (database or cache) being
+// temporarily unreachable, as opposed to a logic or validation failure. Callers can retry
+// transient errors after a short delay.
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	// Connection exceptions (08), operator intervention such as a shutdown (57P) and
+	// insufficient resources like too many connections (53)
+	var stateErr sqlStateError
+	if errors.As(err, &stateErr) {
+		state := stateErr.SQLState()
+		return strings.HasPrefix(state, "08") || strings.HasPrefix(state, "57P") || strings.HasPrefix(state, "53")
+	}
+
+	return false
+}
+
+// WrapConstraintViolation wraps a CHECK constraint violation (SQLSTATE 23514) or a reference to a
+// missing row (23503) in ErrInvalidInput so callers can report it as bad input; any other error is
+// returned unchanged
+func WrapConstraintViolation(err error) error {
+	var stateErr sqlStateError
+	if errors.As(err, &stateErr) && (stateErr.SQLState() == "23514" || stateErr.SQLState() == "23503") {
+		return fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	return err
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// stateError is a driver error carrying a SQLSTATE code, as the Postgres driver returns
+type stateError struct {
+	code string
+}
+
+func (e *stateError) Error() string    { return "pq: violates constraint (SQLSTATE " + e.code + ")" }
+func (e *stateError) SQLState() string { return e.code }
+
+func TestWrapConstraintViolation(t *testing.T) {
+	checkViolation := &stateError{code: "23514"}
+	tests := []struct {
+		name        string
+		err         error
+		wantInvalid bool
+	}{
+		{"check violation", checkViolation, true},
+		{"wrapped check violation", fmt.Errorf("failed to create appointment: %w", checkViolation), true},
+		{"unique violation", &stateError{code: "23505"}, false},
+		{"plain error", errors.New("connection reset"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WrapConstraintViolation(tt.err)
+			if errors.Is(got, ErrInvalidInput) != tt.wantInvalid {
+				t.Errorf("expected ErrInvalidInput=%v, got %v", tt.wantInvalid, got)
+			}
+			if !tt.wantInvalid && got != tt.err {
+				t.Errorf("expected the error returned unchanged, got %v", got)
+			}
+		})
+	}
+
+	if WrapConstraintViolation(nil) != nil {
+		t.Error("expected nil to stay nil")
+	}
+}
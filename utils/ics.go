@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ICSEvent represents a single VEVENT in an iCalendar feed
+type ICSEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time
+	// Floating renders times without a zone so they follow the calendar's local time
+	Floating bool
+	// RRule is an optional recurrence rule, e.g. "FREQ=WEEKLY;BYDAY=MO"
+	RRule string
+}
+
+// ICSCalendar builds an iCalendar (RFC 5545) document
+type ICSCalendar struct {
+	name   string
+	events []ICSEvent
+}
+
+// NewICSCalendar creates an empty calendar with the given display name
+func NewICSCalendar(name string) *ICSCalendar {
+	return &ICSCalendar{name: name}
+}
+
+// AddEvent appends an event to the calendar
+func (c *ICSCalendar) AddEvent(event ICSEvent) {
+	c.events = append(c.events, event)
+}
+
+// String renders the calendar with CRLF line endings
+func (c *ICSCalendar) String() string {
+	var b strings.Builder
+	writeICSLine(&b, "BEGIN:VCALENDAR")
+	writeICSLine(&b, "VERSION:2.0")
+	writeICSLine(&b, "PRODID:-//Smart Doctor Booking//EN")
+	writeICSLine(&b, "CALSCALE:GREGORIAN")
+	writeICSLine(&b, "METHOD:PUBLISH")
+	if c.name != "" {
+		writeICSLine(&b, "X-WR-CALNAME:"+escapeICSText(c.name))
+	}
+
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	for _, event := range c.events {
+		writeICSLine(&b, "BEGIN:VEVENT")
+		writeICSLine(&b, "UID:"+event.UID)
+		writeICSLine(&b, "DTSTAMP:"+stamp)
+		writeICSLine(&b, "DTSTART:"+formatICSTime(event.Start, event.Floating))
+		writeICSLine(&b, "DTEND:"+formatICSTime(event.End, event.Floating))
+		if event.RRule != "" {
+			writeICSLine(&b, "RRULE:"+event.RRule)
+		}
+		writeICSLine(&b, "SUMMARY:"+escapeICSText(event.Summary))
+		if event.Description != "" {
+			writeICSLine(&b, "DESCRIPTION:"+escapeICSText(event.Description))
+		}
+		if event.Location != "" {
+			writeICSLine(&b, "LOCATION:"+escapeICSText(event.Location))
+		}
+		writeICSLine(&b, "END:VEVENT")
+	}
+
+	writeICSLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+// ICSWeekday returns the RRULE BYDAY code for a weekday
+func ICSWeekday(day time.Weekday) string {
+	return [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}[day]
+}
+
+// formatICSTime formats a time as a UTC or floating iCalendar date-time
+func formatICSTime(t time.Time, floating bool) string {
+	if floating {
+		return t.Format("20060102T150405")
+	}
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escapeICSText escapes text values per RFC 5545
+func escapeICSText(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return replacer.Replace(value)
+}
+
+// writeICSLine writes a content line, folding it at 75 octets
+func writeICSLine(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		// Avoid splitting a multi-byte UTF-8 sequence
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		fmt.Fprintf(b, "%s\r\n ", line[:cut])
+		line = line[cut:]
+		// Continuation lines start with a space, which counts toward the limit
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
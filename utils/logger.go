@@ -1,43 +1,60 @@
 package utils
 
 import (
+	"context"
 	"os"
 	"strings"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 )
 
-// Logger is the global logger instance
+// Logger is the global logger instance. Prefer GetLogger, which guarantees initialization.
 var Logger *logrus.Logger
 
-// InitLogger initializes the global logger with configuration
+// loggerOnce guards initialization of the global Logger
+var loggerOnce sync.Once
+
+// InitLogger initializes the global logger with configuration.
+// It is safe to call concurrently and more than once; only the first call has effect.
 func InitLogger() {
-	Logger = logrus.New()
+	loggerOnce.Do(initLogger)
+}
+
+// GetLogger returns the global logger, initializing it on first use
+func GetLogger() *logrus.Logger {
+	loggerOnce.Do(initLogger)
+	return Logger
+}
+
+// initLogger builds and configures the global logger
+func initLogger() {
+	logger := logrus.New()
 
 	// Set log level based on environment
 	logLevel := strings.ToLower(os.Getenv("LOG_LEVEL"))
 	switch logLevel {
 	case "debug":
-		Logger.SetLevel(logrus.DebugLevel)
+		logger.SetLevel(logrus.DebugLevel)
 	case "info":
-		Logger.SetLevel(logrus.InfoLevel)
+		logger.SetLevel(logrus.InfoLevel)
 	case "warn", "warning":
-		Logger.SetLevel(logrus.WarnLevel)
+		logger.SetLevel(logrus.WarnLevel)
 	case "error":
-		Logger.SetLevel(logrus.ErrorLevel)
+		logger.SetLevel(logrus.ErrorLevel)
 	case "fatal":
-		Logger.SetLevel(logrus.FatalLevel)
+		logger.SetLevel(logrus.FatalLevel)
 	case "panic":
-		Logger.SetLevel(logrus.PanicLevel)
+		logger.SetLevel(logrus.PanicLevel)
 	default:
-		Logger.SetLevel(logrus.InfoLevel) // Default to info level
+		logger.SetLevel(logrus.InfoLevel) // Default to info level
 	}
 
 	// Set formatter based on environment
 	env := strings.ToLower(os.Getenv("ENVIRONMENT"))
 	if env == "production" || env == "prod" {
 		// Use JSON formatter for production
-		Logger.SetFormatter(&logrus.JSONFormatter{
+		logger.SetFormatter(&logrus.JSONFormatter{
 			TimestampFormat: "2006-01-02T15:04:05.000Z07:00",
 			FieldMap: logrus.FieldMap{
 				logrus.FieldKeyTime:  "timestamp",
@@ -47,7 +64,7 @@ func InitLogger() {
 		})
 	} else {
 		// Use text formatter for development
-		Logger.SetFormatter(&logrus.TextFormatter{
+		logger.SetFormatter(&logrus.TextFormatter{
 			FullTimestamp:   true,
 			TimestampFormat: "2006-01-02 15:04:05",
 			ForceColors:     true,
@@ -55,10 +72,10 @@ func InitLogger() {
 	}
 
 	// Set output to stdout
-	Logger.SetOutput(os.Stdout)
+	logger.SetOutput(os.Stdout)
 
 	// Add default fields
-	Logger = Logger.WithFields(logrus.Fields{
+	Logger = logger.WithFields(logrus.Fields{
 		"service": "smart-doctor-booking",
 		"version": "1.0.0",
 	}).Logger
@@ -66,11 +83,9 @@ func InitLogger() {
 
 // LogError logs an error with context
 func LogError(err error, message string, fields logrus.Fields) {
-	if Logger == nil {
-		InitLogger()
-	}
+	logger := GetLogger()
 
-	entry := Logger.WithError(err)
+	entry := logger.WithError(err)
 	if fields != nil {
 		entry = entry.WithFields(fields)
 	}
@@ -79,50 +94,60 @@ func LogError(err error, message string, fields logrus.Fields) {
 
 // LogInfo logs an info message with context
 func LogInfo(message string, fields logrus.Fields) {
-	if Logger == nil {
-		InitLogger()
-	}
+	logger := GetLogger()
 
 	if fields != nil {
-		Logger.WithFields(fields).Info(message)
+		logger.WithFields(fields).Info(message)
 	} else {
-		Logger.Info(message)
+		logger.Info(message)
 	}
 }
 
 // LogWarn logs a warning message with context
 func LogWarn(message string, fields logrus.Fields) {
-	if Logger == nil {
-		InitLogger()
-	}
+	logger := GetLogger()
 
 	if fields != nil {
-		Logger.WithFields(fields).Warn(message)
+		logger.WithFields(fields).Warn(message)
 	} else {
-		Logger.Warn(message)
+		logger.Warn(message)
 	}
 }
 
 // LogDebug logs a debug message with context
 func LogDebug(message string, fields logrus.Fields) {
-	if Logger == nil {
-		InitLogger()
-	}
+	logger := GetLogger()
 
 	if fields != nil {
-		Logger.WithFields(fields).Debug(message)
+		logger.WithFields(fields).Debug(message)
 	} else {
-		Logger.Debug(message)
+		logger.Debug(message)
+	}
+}
+
+// LogErrorContext logs an error with context through the request-scoped logger carried by ctx
+func LogErrorContext(ctx context.Context, err error, message string, fields logrus.Fields) {
+	entry := LoggerFrom(ctx).WithError(err)
+	if fields != nil {
+		entry = entry.WithFields(fields)
+	}
+	entry.Error(message)
+}
+
+// LogInfoContext logs an info message with context through the request-scoped logger carried by ctx
+func LogInfoContext(ctx context.Context, message string, fields logrus.Fields) {
+	entry := LoggerFrom(ctx)
+	if fields != nil {
+		entry = entry.WithFields(fields)
 	}
+	entry.Info(message)
 }
 
 // LogFatal logs a fatal message and exits
 func LogFatal(err error, message string, fields logrus.Fields) {
-	if Logger == nil {
-		InitLogger()
-	}
+	logger := GetLogger()
 
-	entry := Logger.WithError(err)
+	entry := logger.WithError(err)
 	if fields != nil {
 		entry = entry.WithFields(fields)
 	}
@@ -131,9 +156,7 @@ func LogFatal(err error, message string, fields logrus.Fields) {
 
 // LogHTTPRequest logs HTTP request details
 func LogHTTPRequest(method, path, userAgent, clientIP string, statusCode int, duration int64) {
-	if Logger == nil {
-		InitLogger()
-	}
+	logger := GetLogger()
 
 	fields := logrus.Fields{
 		"method":      method,
@@ -146,17 +169,15 @@ func LogHTTPRequest(method, path, userAgent, clientIP string, statusCode int, du
 	}
 
 	if statusCode >= 400 {
-		Logger.WithFields(fields).Warn("HTTP request completed with error")
+		logger.WithFields(fields).Warn("HTTP request completed with error")
 	} else {
-		Logger.WithFields(fields).Info("HTTP request completed")
+		logger.WithFields(fields).Info("HTTP request completed")
 	}
 }
 
 // LogDatabaseOperation logs database operation details
 func LogDatabaseOperation(operation, table string, duration int64, err error) {
-	if Logger == nil {
-		InitLogger()
-	}
+	logger := GetLogger()
 
 	fields := logrus.Fields{
 		"operation":   operation,
@@ -166,17 +187,15 @@ func LogDatabaseOperation(operation, table string, duration int64, err error) {
 	}
 
 	if err != nil {
-		Logger.WithFields(fields).WithError(err).Error("Database operation failed")
+		logger.WithFields(fields).WithError(err).Error("Database operation failed")
 	} else {
-		Logger.WithFields(fields).Debug("Database operation completed")
+		logger.WithFields(fields).Debug("Database operation completed")
 	}
 }
 
 // LogSecurityEvent logs security-related events
 func LogSecurityEvent(event, userID, clientIP, details string) {
-	if Logger == nil {
-		InitLogger()
-	}
+	logger := GetLogger()
 
 	fields := logrus.Fields{
 		"event":     event,
@@ -186,5 +205,5 @@ func LogSecurityEvent(event, userID, clientIP, details string) {
 		"type":      "security_event",
 	}
 
-	Logger.WithFields(fields).Warn("Security event detected")
+	logger.WithFields(fields).Warn("Security event detected")
 }
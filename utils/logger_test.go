@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"sync"
+	"testing"
+)
+
+// resetLogger forgets the global logger so a test can exercise first-use initialization
+func resetLogger(t *testing.T) {
+	t.Helper()
+	loggerOnce = sync.Once{}
+	Logger = nil
+}
+
+// Run with -race: every goroutine's first LogInfo races to initialize the logger
+func TestLogInfoConcurrentBeforeInit(t *testing.T) {
+	resetLogger(t)
+
+	const goroutines = 32
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			LogInfo("concurrent log before init", map[string]interface{}{"goroutine": i})
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	if Logger == nil {
+		t.Fatal("expected the first LogInfo to initialize the logger")
+	}
+}
+
+func TestInitLoggerKeepsFirstLogger(t *testing.T) {
+	resetLogger(t)
+
+	first := GetLogger()
+	InitLogger()
+	if GetLogger() != first {
+		t.Error("expected InitLogger after first use to keep the existing logger")
+	}
+}
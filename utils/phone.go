@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// phoneRegion describes how national numbers are written in a region
+type phoneRegion struct {
+	countryCode string
+	trunkPrefix string
+	minNational int
+	maxNational int
+}
+
+// phoneRegions lists the regions whose national number formats are understood.
+// Numbers written in international form (+ or 00 prefix) are accepted for any region.
+var phoneRegions = map[string]phoneRegion{
+	"US": {countryCode: "1", trunkPrefix: "1", minNational: 10, maxNational: 10},
+	"CA": {countryCode: "1", trunkPrefix: "1", minNational: 10, maxNational: 10},
+	"GB": {countryCode: "44", trunkPrefix: "0", minNational: 9, maxNational: 10},
+	"NG": {countryCode: "234", trunkPrefix: "0", minNational: 8, maxNational: 10},
+	"GH": {countryCode: "233", trunkPrefix: "0", minNational: 9, maxNational: 9},
+	"KE": {countryCode: "254", trunkPrefix: "0", minNational: 9, maxNational: 9},
+	"ZA": {countryCode: "27", trunkPrefix: "0", minNational: 9, maxNational: 9},
+	"IN": {countryCode: "91", trunkPrefix: "0", minNational: 10, maxNational: 10},
+	"DE": {countryCode: "49", trunkPrefix: "0", minNational: 6, maxNational: 11},
+	"FR": {countryCode: "33", trunkPrefix: "0", minNational: 9, maxNational: 9},
+	"AU": {countryCode: "61", trunkPrefix: "0", minNational: 9, maxNational: 9},
+}
+
+// ErrInvalidPhone is returned when a phone number cannot be normalized
+var ErrInvalidPhone = errors.New("invalid phone number")
+
+// NormalizePhone validates a phone number and formats it as E.164 (e.g. +14155552671).
+// Numbers without an international prefix are interpreted using defaultRegion (ISO 3166 alpha-2).
+func NormalizePhone(raw, defaultRegion string) (string, error) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return "", fmt.Errorf("%w: empty", ErrInvalidPhone)
+	}
+
+	international := strings.HasPrefix(trimmed, "+")
+	if international {
+		trimmed = trimmed[1:]
+	}
+
+	var digits strings.Builder
+	for _, r := range trimmed {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+			// Common separators are ignored
+		default:
+			return "", fmt.Errorf("%w: unexpected character %q", ErrInvalidPhone, r)
+		}
+	}
+	number := digits.String()
+
+	if !international && strings.HasPrefix(number, "00") {
+		international = true
+		number = number[2:]
+	}
+
+	if international {
+		// E.164 allows at most 15 digits including the country code
+		if len(number) < 8 || len(number) > 15 || number[0] == '0' {
+			return "", fmt.Errorf("%w: %s", ErrInvalidPhone, raw)
+		}
+		return "+" + number, nil
+	}
+
+	region, ok := phoneRegions[strings.ToUpper(defaultRegion)]
+	if !ok {
+		return "", fmt.Errorf("%w: unsupported region %q for national number", ErrInvalidPhone, defaultRegion)
+	}
+
+	// Drop the trunk prefix or an included country code, whichever is present
+	national := number
+	if len(national) > region.maxNational && strings.HasPrefix(national, region.countryCode) {
+		national = national[len(region.countryCode):]
+	} else if len(national) > region.maxNational && strings.HasPrefix(national, region.trunkPrefix) {
+		national = national[len(region.trunkPrefix):]
+	} else if region.trunkPrefix == "0" && strings.HasPrefix(national, "0") {
+		national = national[1:]
+	}
+
+	if len(national) < region.minNational || len(national) > region.maxNational || national[0] == '0' {
+		return "", fmt.Errorf("%w: %s", ErrInvalidPhone, raw)
+	}
+
+	return "+" + region.countryCode + national, nil
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizePhoneFormatsToE164(t *testing.T) {
+	tests := []struct {
+		region string
+		inputs []string
+		want   string
+	}{
+		{"US", []string{"(415) 555-2671", "415.555.2671", "1 415 555 2671", "+1 415-555-2671", "0014155552671"}, "+14155552671"},
+		{"GB", []string{"020 7946 0958", "+44 20 7946 0958", "0044 20 7946 0958", "44 20 7946 0958"}, "+442079460958"},
+		{"NG", []string{"0803 123 4567", "+234 803 123 4567", "234-803-123-4567"}, "+2348031234567"},
+		{"us", []string{"415 555 2671"}, "+14155552671"},
+	}
+
+	for _, tt := range tests {
+		for _, input := range tt.inputs {
+			got, err := NormalizePhone(input, tt.region)
+			if err != nil {
+				t.Errorf("NormalizePhone(%q, %q) returned error: %v", input, tt.region, err)
+				continue
+			}
+			if got != tt.want {
+				t.Errorf("NormalizePhone(%q, %q) = %q, want %q", input, tt.region, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestNormalizePhoneRejectsGarbage(t *testing.T) {
+	tests := []struct {
+		input, region string
+	}{
+		{"", "US"},
+		{"   ", "US"},
+		{"not a number", "US"},
+		{"415-555-CALL", "US"},
+		{"555-2671", "US"},
+		{"+1 415 555 2671 123456", "US"},
+		{"+0 415 555 2671", "US"},
+		{"+123", "US"},
+		{"0415 555 2671", "US"},
+		{"415 555 2671", "XX"},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizePhone(tt.input, tt.region)
+		if !errors.Is(err, ErrInvalidPhone) {
+			t.Errorf("NormalizePhone(%q, %q) = %q, %v; want ErrInvalidPhone", tt.input, tt.region, got, err)
+		}
+	}
+}
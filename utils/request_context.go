@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// requestIDKey is the context key under which the request ID is stored
+type requestIDKey struct{}
+
+// loggerKey is the context key under which the request-scoped logger is stored
+type loggerKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, or an empty string
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// ContextWithLogger returns a copy of ctx carrying a logger entry with request-scoped fields
+func ContextWithLogger(ctx context.Context, entry *logrus.Entry) context.Context {
+	return context.WithValue(ctx, loggerKey{}, entry)
+}
+
+// LoggerFrom returns the request-scoped logger carried by ctx, so every log line includes the
+// request's user_id and request_id without passing them by hand. Without one it falls back to
+// the global logger, tagged with the request ID when ctx carries it.
+func LoggerFrom(ctx context.Context) *logrus.Entry {
+	if ctx != nil {
+		if entry, ok := ctx.Value(loggerKey{}).(*logrus.Entry); ok {
+			return entry
+		}
+	}
+
+	entry := logrus.NewEntry(GetLogger())
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		entry = entry.WithField("request_id", requestID)
+	}
+	return entry
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TimeParseError is returned when an appointment time cannot be parsed, explaining what was wrong
+type TimeParseError struct {
+	Value  string
+	Reason string
+}
+
+// Error implements the error interface
+func (e *TimeParseError) Error() string {
+	return fmt.Sprintf("invalid time %q: %s", e.Value, e.Reason)
+}
+
+// zonelessTimeLayouts are ISO 8601 forms that parse but carry no timezone, so they are reported
+// as missing an offset rather than as malformed
+var zonelessTimeLayouts = []string{
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+}
+
+// ParseAppointmentTime parses an ISO 8601 (RFC 3339) time such as 2024-05-01T09:30:00Z or
+// 2024-05-01T10:30:00+01:00. Times without a Z or numeric offset are rejected, since it is
+// ambiguous which timezone they are in.
+func ParseAppointmentTime(value string) (time.Time, error) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return time.Time{}, &TimeParseError{Value: value, Reason: "a time is required"}
+	}
+
+	if parsed, err := time.Parse(time.RFC3339Nano, trimmed); err == nil {
+		return parsed, nil
+	}
+
+	for _, layout := range zonelessTimeLayouts {
+		if _, err := time.Parse(layout, trimmed); err == nil {
+			return time.Time{}, &TimeParseError{Value: value, Reason: "missing timezone; end the time with Z or an offset such as +01:00"}
+		}
+	}
+
+	return time.Time{}, &TimeParseError{Value: value, Reason: "expected ISO 8601 format YYYY-MM-DDTHH:MM:SSZ or YYYY-MM-DDTHH:MM:SS+01:00"}
+}
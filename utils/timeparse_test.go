@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAppointmentTimeAcceptsZAndOffset(t *testing.T) {
+	want := time.Date(2031, 3, 3, 9, 30, 0, 0, time.UTC)
+	for _, input := range []string{"2031-03-03T09:30:00Z", "2031-03-03T10:30:00+01:00", "2031-03-03T04:30:00-05:00", " 2031-03-03T09:30:00.000Z "} {
+		got, err := ParseAppointmentTime(input)
+		if err != nil {
+			t.Errorf("ParseAppointmentTime(%q) returned error: %v", input, err)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("ParseAppointmentTime(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestParseAppointmentTimeRejectsAmbiguousOrMalformed(t *testing.T) {
+	tests := []struct {
+		input  string
+		reason string
+	}{
+		{"2031-03-03T09:30:00", "missing timezone"},
+		{"2031-03-03T09:30", "missing timezone"},
+		{"", "a time is required"},
+		{"03/03/2031 09:30", "expected ISO 8601"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseAppointmentTime(tt.input)
+		var parseErr *TimeParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("ParseAppointmentTime(%q): expected a TimeParseError, got %v", tt.input, err)
+			continue
+		}
+		if parseErr.Value != tt.input || !strings.Contains(parseErr.Reason, tt.reason) {
+			t.Errorf("ParseAppointmentTime(%q): expected reason containing %q, got %+v", tt.input, tt.reason, parseErr)
+		}
+	}
+}